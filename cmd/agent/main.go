@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -12,11 +14,16 @@ import (
 
 	"github.com/gen2brain/malgo"
 	"github.com/joho/godotenv"
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/envconfig"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/admin"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 	llmProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/moderation"
 	sttProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
 	ttsProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/store"
 )
 
 const (
@@ -30,13 +37,22 @@ func main() {
 		log.Println("Note: No .env file found, using system environment variables")
 	}
 
-	groqKey := os.Getenv("GROQ_API_KEY")
-	openaiKey := os.Getenv("OPENAI_API_KEY")
-	anthropicKey := os.Getenv("ANTHROPIC_API_KEY")
-	googleKey := os.Getenv("GOOGLE_API_KEY")
-	deepgramKey := os.Getenv("DEEPGRAM_API_KEY")
-	assemblyKey := os.Getenv("ASSEMBLYAI_API_KEY")
-	lokutorKey := os.Getenv("LOKUTOR_API_KEY")
+	secretStore := envconfig.NewSecretProvider()
+	getSecret := func(name string) string {
+		v, err := secretStore.GetSecret(context.Background(), name)
+		if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			log.Printf("Warning: could not read %s from %s: %v", name, secretStore.Name(), err)
+		}
+		return v
+	}
+
+	groqKey := getSecret("GROQ_API_KEY")
+	openaiKey := getSecret("OPENAI_API_KEY")
+	anthropicKey := getSecret("ANTHROPIC_API_KEY")
+	googleKey := getSecret("GOOGLE_API_KEY")
+	deepgramKey := getSecret("DEEPGRAM_API_KEY")
+	assemblyKey := getSecret("ASSEMBLYAI_API_KEY")
+	lokutorKey := getSecret("LOKUTOR_API_KEY")
 
 	sttProviderName := os.Getenv("STT_PROVIDER")
 	if sttProviderName == "" {
@@ -57,22 +73,26 @@ func main() {
 	}
 
 	var stt orchestrator.STTProvider
+	var sttKeyName string
 	switch sttProviderName {
 	case "openai":
 		if openaiKey == "" {
 			log.Fatal("Error: OPENAI_API_KEY must be set for openai STT")
 		}
 		stt = sttProvider.NewOpenAISTT(openaiKey, "whisper-1")
+		sttKeyName = "OPENAI_API_KEY"
 	case "deepgram":
 		if deepgramKey == "" {
 			log.Fatal("Error: DEEPGRAM_API_KEY must be set for deepgram STT")
 		}
 		stt = sttProvider.NewDeepgramSTT(deepgramKey)
+		sttKeyName = "DEEPGRAM_API_KEY"
 	case "assemblyai":
 		if assemblyKey == "" {
 			log.Fatal("Error: ASSEMBLYAI_API_KEY must be set for assemblyai STT")
 		}
 		stt = sttProvider.NewAssemblyAISTT(assemblyKey)
+		sttKeyName = "ASSEMBLYAI_API_KEY"
 	case "groq":
 		fallthrough
 	default:
@@ -84,29 +104,35 @@ func main() {
 			groqModel = "whisper-large-v3"
 		}
 		stt = sttProvider.NewGroqSTT(groqKey, groqModel)
+		sttKeyName = "GROQ_API_KEY"
 	}
 
 	if s, ok := stt.(interface{ SetSampleRate(int) }); ok {
 		s.SetSampleRate(SampleRate)
 	}
+	envconfig.BindSecret(stt, secretStore, sttKeyName)
 
 	var llm orchestrator.LLMProvider
+	var llmKeyName string
 	switch llmProviderName {
 	case "openai":
 		if openaiKey == "" {
 			log.Fatal("Error: OPENAI_API_KEY must be set for openai LLM")
 		}
 		llm = llmProvider.NewOpenAILLM(openaiKey, "gpt-4o")
+		llmKeyName = "OPENAI_API_KEY"
 	case "anthropic":
 		if anthropicKey == "" {
 			log.Fatal("Error: ANTHROPIC_API_KEY must be set for anthropic LLM")
 		}
 		llm = llmProvider.NewAnthropicLLM(anthropicKey, "claude-3-5-sonnet-20241022")
+		llmKeyName = "ANTHROPIC_API_KEY"
 	case "google":
 		if googleKey == "" {
 			log.Fatal("Error: GOOGLE_API_KEY must be set for google LLM")
 		}
 		llm = llmProvider.NewGoogleLLM(googleKey, "gemini-1.5-flash")
+		llmKeyName = "GOOGLE_API_KEY"
 	case "groq":
 		fallthrough
 	default:
@@ -114,11 +140,36 @@ func main() {
 			log.Fatal("Error: GROQ_API_KEY must be set for groq LLM")
 		}
 		llm = llmProvider.NewGroqLLM(groqKey, "llama-3.3-70b-versatile")
+		llmKeyName = "GROQ_API_KEY"
 	}
+	envconfig.BindSecret(llm, secretStore, llmKeyName)
 
 	config := orchestrator.DefaultConfig()
 	config.Language = lang
 
+	if os.Getenv("GUARDRAIL") == "openai" {
+		if openaiKey == "" {
+			log.Fatal("Error: OPENAI_API_KEY must be set for openai moderation")
+		}
+		moderator := moderation.NewOpenAIModeration(openaiKey, "")
+		envconfig.BindSecret(moderator, secretStore, "OPENAI_API_KEY")
+		config.Guardrail = orchestrator.GuardrailConfig{
+			Provider: moderator,
+			Fallback: os.Getenv("GUARDRAIL_FALLBACK"),
+		}
+	}
+
+	if dir := os.Getenv("RECORDING_DIR"); dir != "" {
+		recordings, err := store.NewRecordingDir(dir)
+		if err != nil {
+			log.Fatalf("Error: recording dir: %v", err)
+		}
+		config.Recording.Storage = recordings
+	}
+	if artifacts := envconfig.OpenArtifactStorage(); artifacts != nil {
+		config.TurnArtifacts.Storage = artifacts
+	}
+
 	firstSpeaker := os.Getenv("FIRST_SPEAKER")
 	if firstSpeaker == "bot" {
 		config.FirstSpeaker = orchestrator.FirstSpeakerBot
@@ -131,7 +182,13 @@ func main() {
 	fmt.Println("Voice Agent Started! Listening to microphone...")
 	fmt.Println("Press Ctrl+C to exit")
 
-	tts := ttsProvider.NewLokutorTTS(lokutorKey)
+	// Empty values keep the api.lokutor.com defaults.
+	tts := ttsProvider.NewLokutorTTSWithOptions(lokutorKey, ttsProvider.LokutorOptions{
+		Host:   os.Getenv("LOKUTOR_HOST"),
+		Scheme: os.Getenv("LOKUTOR_SCHEME"),
+		Path:   os.Getenv("LOKUTOR_PATH"),
+	})
+	envconfig.BindSecret(tts, secretStore, "LOKUTOR_API_KEY")
 
 	vad := orchestrator.NewRMSVAD(config.BargeInVADThreshold, 800*time.Millisecond)
 	vad.SetMinConfirmed(2)
@@ -161,6 +218,20 @@ func main() {
 	stream.SetEchoSampleRates(SampleRate, SampleRate)
 	defer stream.Close()
 
+	if adminAddr := os.Getenv("ADMIN_ADDR"); adminAddr != "" {
+		adminToken := getSecret("ADMIN_TOKEN")
+		if adminToken == "" {
+			log.Fatal("Error: ADMIN_ADDR requires ADMIN_TOKEN")
+		}
+		adminServer := admin.NewServer(orch, adminToken)
+		go func() {
+			if err := adminServer.ListenAndServe(ctx, adminAddr); err != nil {
+				log.Printf("Admin server error: %v", err)
+			}
+		}()
+		fmt.Printf("Admin endpoint listening on %s\n", adminAddr)
+	}
+
 	mctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
 	if err != nil {
 		log.Fatal(err)
@@ -187,6 +258,17 @@ func main() {
 		}
 	}()
 
+	// INPUT_FILE plays a WAV file to the agent instead of the microphone.
+	inputFile := os.Getenv("INPUT_FILE")
+	if inputFile != "" {
+		go func() {
+			if err := feedWavFile(ctx, inputFile, stream.Write); err != nil {
+				fmt.Printf("\r\033[K❌ [INPUT] %v\n", err)
+			}
+		}()
+		fmt.Printf("Playing %s to the agent instead of the microphone\n", inputFile)
+	}
+
 	playedChan := make(chan []byte, 1024)
 	go func() {
 		for chunk := range playedChan {
@@ -196,7 +278,7 @@ func main() {
 	}()
 
 	onSamples := func(pOutput, pInput []byte, frameCount uint32) {
-		if pInput != nil {
+		if pInput != nil && inputFile == "" {
 			buf := chunkPool.Get().([]byte)
 			n := copy(buf, pInput)
 			select {
@@ -314,15 +396,6 @@ func main() {
 				fmt.Printf("\r\033[K⌛ [STT] Processing...\n")
 			case orchestrator.TranscriptFinal:
 				fmt.Printf("\r\033[K📝 [TRANSCRIPT] %s\n", event.Data.(string))
-				raw, proc := stream.ExportLastUserAudio()
-				if raw != nil {
-					ts := time.Now().Format("20060102-150405")
-					rawPath := fmt.Sprintf("/tmp/lokutor_user_raw_%s.wav", ts)
-					procPath := fmt.Sprintf("/tmp/lokutor_user_processed_%s.wav", ts)
-					_ = os.WriteFile(rawPath, audio.NewWavBuffer(raw, SampleRate), 0644)
-					_ = os.WriteFile(procPath, audio.NewWavBuffer(proc, SampleRate), 0644)
-					fmt.Printf("\r\033[K💾 Saved user audio: %s (raw), %s (processed)\n", rawPath, procPath)
-				}
 
 			case orchestrator.BotThinking:
 				fmt.Printf("\r\033[K🧠 [LLM] Thinking...\n")
@@ -361,4 +434,60 @@ func main() {
 	_ = device.Stop()
 	stream.Close()
 	time.Sleep(50 * time.Millisecond)
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := orch.FlushRecordings(flushCtx); err != nil {
+		fmt.Printf("Recording flush error: %v\n", err)
+	}
+	if dir := os.Getenv("RECORDING_DIR"); dir != "" {
+		fmt.Printf("Recording saved under %s\n", dir)
+	}
+}
+
+// feedWavFile writes a WAV file to write in real time, converted to the
+// agent's format, then keeps writing silence so its last utterance ends and
+// the agent can answer.
+func feedWavFile(ctx context.Context, path string, write func([]byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := audio.NewWavReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	format := r.Format()
+	conv := audio.NewConverter(format.SampleRate, format.Channels, SampleRate, Channels)
+
+	const frame = 20 * time.Millisecond
+	in := make([]byte, format.SampleRate*format.Channels*2*int(frame/time.Millisecond)/1000)
+	silence := make([]byte, SampleRate*Channels*2*int(frame/time.Millisecond)/1000)
+	ticker := time.NewTicker(frame)
+	defer ticker.Stop()
+	eof := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		chunk := silence
+		if !eof {
+			n, err := io.ReadFull(r, in)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return err
+			}
+			chunk = conv.Process(in[:n])
+			if err != nil {
+				eof = true
+				chunk = append(chunk, conv.Flush()...)
+			}
+		}
+		if len(chunk) > 0 {
+			if err := write(chunk); err != nil {
+				return err
+			}
+		}
+	}
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/eval"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	llmProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
+	sttProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
+)
+
+func main() {
+	audioDir := flag.String("audio", "", "directory of WAV fixtures with .txt reference transcripts")
+	promptsPath := flag.String("prompts", "", "prompt suite, one JSON prompt per line")
+	pricingPath := flag.String("pricing", "", "JSON pricing for cost estimates")
+	format := flag.String("format", "md", "report format: md or csv")
+	out := flag.String("out", "", "report file (default stdout)")
+	lang := flag.String("lang", "en", "language of the fixtures")
+	sttNames := flag.String("stt", "", "comma-separated STT providers (default: every one with an API key)")
+	llmNames := flag.String("llm", "", "comma-separated LLM providers (default: every one with an API key)")
+	flag.Parse()
+
+	if *audioDir == "" && *promptsPath == "" {
+		log.Fatal("Error: nothing to evaluate; set -audio and/or -prompts")
+	}
+	if err := godotenv.Load(); err != nil {
+		log.Println("Note: No .env file found, using system environment variables")
+	}
+
+	runner := &eval.Runner{
+		STT:      sttProviders(selected(*sttNames)),
+		LLM:      llmProviders(selected(*llmNames)),
+		Language: orchestrator.Language(*lang),
+	}
+	if *pricingPath != "" {
+		pricing, err := eval.LoadPricing(*pricingPath)
+		if err != nil {
+			log.Fatalf("Error: reading pricing: %v", err)
+		}
+		runner.Pricing = pricing
+	}
+
+	ctx := context.Background()
+	var results []eval.Result
+	if *audioDir != "" {
+		fixtures, err := eval.LoadFixtures(*audioDir)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if len(runner.STT) == 0 {
+			log.Fatal("Error: no STT provider has an API key set")
+		}
+		log.Printf("Transcribing %d fixtures with %d STT providers", len(fixtures), len(runner.STT))
+		results = append(results, runner.RunSTT(ctx, fixtures)...)
+	}
+	if *promptsPath != "" {
+		prompts, err := eval.LoadPrompts(*promptsPath)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if len(runner.LLM) == 0 {
+			log.Fatal("Error: no LLM provider has an API key set")
+		}
+		log.Printf("Running %d prompts on %d LLM providers", len(prompts), len(runner.LLM))
+		results = append(results, runner.RunLLM(ctx, prompts)...)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	var err error
+	switch *format {
+	case "csv":
+		err = eval.WriteCSV(w, results)
+	case "md":
+		err = eval.WriteMarkdown(w, results)
+	default:
+		log.Fatalf("Error: unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatalf("Error: writing report: %v", err)
+	}
+}
+
+// selected returns the providers named in list, or nil for all of them.
+func selected(list string) map[string]bool {
+	if list == "" {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		names[strings.TrimSpace(name)] = true
+	}
+	return names
+}
+
+func sttProviders(names map[string]bool) map[string]orchestrator.STTProvider {
+	providers := make(map[string]orchestrator.STTProvider)
+	add := func(name, keyName string, build func(key string) orchestrator.STTProvider) {
+		if names != nil && !names[name] {
+			return
+		}
+		if key := os.Getenv(keyName); key != "" {
+			providers[name] = build(key)
+		} else if names != nil {
+			log.Fatalf("Error: %s must be set for %s STT", keyName, name)
+		}
+	}
+	add("groq", "GROQ_API_KEY", func(key string) orchestrator.STTProvider {
+		model := os.Getenv("GROQ_STT_MODEL")
+		if model == "" {
+			model = "whisper-large-v3"
+		}
+		return sttProvider.NewGroqSTT(key, model)
+	})
+	add("openai", "OPENAI_API_KEY", func(key string) orchestrator.STTProvider {
+		return sttProvider.NewOpenAISTT(key, "whisper-1")
+	})
+	add("deepgram", "DEEPGRAM_API_KEY", func(key string) orchestrator.STTProvider {
+		return sttProvider.NewDeepgramSTT(key)
+	})
+	add("assemblyai", "ASSEMBLYAI_API_KEY", func(key string) orchestrator.STTProvider {
+		return sttProvider.NewAssemblyAISTT(key)
+	})
+	return providers
+}
+
+func llmProviders(names map[string]bool) map[string]orchestrator.LLMProvider {
+	providers := make(map[string]orchestrator.LLMProvider)
+	add := func(name, keyName string, build func(key string) orchestrator.LLMProvider) {
+		if names != nil && !names[name] {
+			return
+		}
+		if key := os.Getenv(keyName); key != "" {
+			providers[name] = build(key)
+		} else if names != nil {
+			log.Fatalf("Error: %s must be set for %s LLM", keyName, name)
+		}
+	}
+	add("groq", "GROQ_API_KEY", func(key string) orchestrator.LLMProvider {
+		return llmProvider.NewGroqLLM(key, "llama-3.3-70b-versatile")
+	})
+	add("openai", "OPENAI_API_KEY", func(key string) orchestrator.LLMProvider {
+		return llmProvider.NewOpenAILLM(key, "gpt-4o")
+	})
+	add("anthropic", "ANTHROPIC_API_KEY", func(key string) orchestrator.LLMProvider {
+		return llmProvider.NewAnthropicLLM(key, "claude-3-5-sonnet-20241022")
+	})
+	add("google", "GOOGLE_API_KEY", func(key string) orchestrator.LLMProvider {
+		return llmProvider.NewGoogleLLM(key, "gemini-1.5-flash")
+	})
+	return providers
+}
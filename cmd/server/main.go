@@ -0,0 +1,710 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/envconfig"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/admin"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	llmProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
+	sttProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
+	ttsProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/rest"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/server"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/sink"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/store"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/transport/sip"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/transport/twilio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/transport/webrtc"
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Note: No .env file found, using system environment variables")
+	}
+
+	secretStore = envconfig.NewSecretProvider()
+
+	lokutorKey := requireSecret("LOKUTOR_API_KEY")
+
+	var stt orchestrator.STTProvider
+	var sttKeyName string
+	switch os.Getenv("STT_PROVIDER") {
+	case "openai":
+		sttKeyName = "OPENAI_API_KEY"
+		stt = sttProvider.NewOpenAISTT(requireSecret(sttKeyName), "whisper-1")
+	case "deepgram":
+		sttKeyName = "DEEPGRAM_API_KEY"
+		stt = sttProvider.NewDeepgramSTT(requireSecret(sttKeyName))
+	case "assemblyai":
+		sttKeyName = "ASSEMBLYAI_API_KEY"
+		stt = sttProvider.NewAssemblyAISTT(requireSecret(sttKeyName))
+	default:
+		model := os.Getenv("GROQ_STT_MODEL")
+		if model == "" {
+			model = "whisper-large-v3"
+		}
+		sttKeyName = "GROQ_API_KEY"
+		stt = sttProvider.NewGroqSTT(requireSecret(sttKeyName), model)
+	}
+	envconfig.BindSecret(stt, secretStore, sttKeyName)
+
+	var llm orchestrator.LLMProvider
+	var llmKeyName string
+	switch os.Getenv("LLM_PROVIDER") {
+	case "openai":
+		llmKeyName = "OPENAI_API_KEY"
+		llm = llmProvider.NewOpenAILLM(requireSecret(llmKeyName), "gpt-4o")
+	case "anthropic":
+		llmKeyName = "ANTHROPIC_API_KEY"
+		llm = llmProvider.NewAnthropicLLM(requireSecret(llmKeyName), "claude-3-5-sonnet-20241022")
+	case "google":
+		llmKeyName = "GOOGLE_API_KEY"
+		llm = llmProvider.NewGoogleLLM(requireSecret(llmKeyName), "gemini-1.5-flash")
+	default:
+		llmKeyName = "GROQ_API_KEY"
+		llm = llmProvider.NewGroqLLM(requireSecret(llmKeyName), "llama-3.3-70b-versatile")
+	}
+	envconfig.BindSecret(llm, secretStore, llmKeyName)
+
+	config := orchestrator.DefaultConfig()
+	if lang := os.Getenv("AGENT_LANGUAGE"); lang != "" {
+		config.Language = orchestrator.Language(lang)
+	}
+	if rate := os.Getenv("SAMPLE_RATE"); rate != "" {
+		n, err := strconv.Atoi(rate)
+		if err != nil || n <= 0 {
+			log.Fatalf("Error: invalid SAMPLE_RATE: %q", rate)
+		}
+		config.SampleRate = n
+	}
+	if profile := os.Getenv("PROFILE"); profile != "" {
+		if _, ok := orchestrator.LookupProfile(profile); !ok {
+			log.Fatalf("Error: unknown PROFILE: %q", profile)
+		}
+		config.Profile = profile
+	}
+	if os.Getenv("FIRST_SPEAKER") == "user" {
+		config.FirstSpeaker = orchestrator.FirstSpeakerUser
+	}
+	if mode := os.Getenv("TURN_DETECTION"); mode != "" {
+		config.TurnDetection = orchestrator.TurnDetection(mode)
+	}
+	if os.Getenv("STT_PAUSE_DURING_BOT_SPEECH") == "true" {
+		config.STTGate.PauseDuringBotSpeech = true
+	}
+	if os.Getenv("BARGE_IN_TWO_STAGE") == "true" {
+		config.BargeIn.TwoStage = true
+	}
+	if os.Getenv("BARGE_IN_RESUME") == "true" {
+		config.BargeIn.ResumeAfterFalse = true
+	}
+	if os.Getenv("BARGE_IN_MERGE_CANCELED") == "true" {
+		config.BargeIn.MergeCanceled = true
+	}
+	if os.Getenv("ENDPOINTING") == "true" {
+		config.Endpointing.Enabled = true
+	}
+	if os.Getenv("AGC") == "true" {
+		config.AGC.Enabled = true
+	}
+	if window := os.Getenv("CONTINUATION_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			log.Fatalf("Error: invalid CONTINUATION_WINDOW: %v", err)
+		}
+		config.Continuation = orchestrator.ContinuationConfig{Enabled: true, Window: d}
+	}
+	if path := os.Getenv("FLOW_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Error: reading FLOW_FILE: %v", err)
+		}
+		if config.Flow, err = orchestrator.ParseFlow(data); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	if notice := os.Getenv("DISCLOSURE_CALL_START"); notice != "" {
+		config.Compliance.Disclosures = append(config.Compliance.Disclosures, orchestrator.Disclosure{ID: "call_start", Text: notice, At: orchestrator.DisclosureCallStart})
+	}
+	if os.Getenv("BACKCHANNEL") == "true" {
+		config.Backchannel.Enabled = true
+	}
+	if os.Getenv("HALLUCINATION_FILTER") == "true" {
+		config.HallucinationFilter.Enabled = true
+	}
+	if os.Getenv("TRANSCRIPT_TRUST") == "true" {
+		config.Trust.Enabled = true
+	}
+	if minTrust := os.Getenv("TRANSCRIPT_MIN_TRUST"); minTrust != "" {
+		v, err := strconv.ParseFloat(minTrust, 64)
+		if err != nil {
+			log.Fatalf("Error: invalid TRANSCRIPT_MIN_TRUST: %v", err)
+		}
+		config.Trust = orchestrator.TrustConfig{Enabled: true, MinScore: v}
+	}
+	if os.Getenv("END_CONVERSATION") == "true" {
+		config.EndConversation = orchestrator.EndConversationConfig{Enabled: true, LLMSignal: true}
+	}
+	if os.Getenv("TRANSLATION") == "true" {
+		config.Translation.Enabled = true
+	}
+	if os.Getenv("POWER_SAVE") == "true" {
+		config.PowerSave.Enabled = true
+	}
+	if os.Getenv("LANGUAGE_DETECTION") == "true" {
+		config.LanguageDetection.Enabled = true
+	}
+	if os.Getenv("COMMANDS") == "true" {
+		config.Commands.Enabled = true
+	}
+	if os.Getenv("PROSODY") == "true" {
+		config.Prosody.Enabled = true
+	}
+	if filler := os.Getenv("FILLER_TEXT"); filler != "" {
+		config.Filler = orchestrator.FillerConfig{Enabled: true, Text: filler}
+	}
+	if os.Getenv("LLM_AUDIO_INPUT") == "true" {
+		config.AudioInput.Enabled = true
+	}
+	if os.Getenv("LLM_SEED_TURNS") == "true" {
+		config.Determinism.Enabled = true
+	}
+	if os.Getenv("RESPONSE_STYLE") == "true" {
+		config.ResponseStyle.Enabled = true
+	}
+	if rate := os.Getenv("QA_SAMPLE_RATE"); rate != "" {
+		v, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			log.Fatalf("Error: invalid QA_SAMPLE_RATE: %v", err)
+		}
+		config.QA.SampleRate = v
+	}
+	if action := os.Getenv("ECHO_ACTION"); action != "" {
+		config.Echo.Action = orchestrator.EchoAction(action)
+	}
+	if strategy := os.Getenv("ECHO_STRATEGY"); strategy != "" {
+		config.Echo.Strategy = orchestrator.EchoStrategy(strategy)
+	}
+	if threshold := os.Getenv("ECHO_THRESHOLD"); threshold != "" {
+		v, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			log.Fatalf("Error: invalid ECHO_THRESHOLD: %v", err)
+		}
+		config.Echo.Threshold = v
+	}
+	if window := os.Getenv("ECHO_SEARCH_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			log.Fatalf("Error: invalid ECHO_SEARCH_WINDOW: %v", err)
+		}
+		config.Echo.SearchWindow = d
+		if d > 1500*time.Millisecond {
+			config.Echo.ReferenceBuffer = d + 500*time.Millisecond
+		}
+	}
+	if temperature := os.Getenv("STT_TEMPERATURE"); temperature != "" {
+		v, err := strconv.ParseFloat(temperature, 64)
+		if err != nil {
+			log.Fatalf("Error: invalid STT_TEMPERATURE: %v", err)
+		}
+		config.STTParams.Temperature = &v
+	}
+	if threshold := os.Getenv("STT_NO_SPEECH_THRESHOLD"); threshold != "" {
+		v, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			log.Fatalf("Error: invalid STT_NO_SPEECH_THRESHOLD: %v", err)
+		}
+		config.STTParams.NoSpeechThreshold = &v
+	}
+	if format := os.Getenv("STT_SMART_FORMAT"); format != "" {
+		v, err := strconv.ParseBool(format)
+		if err != nil {
+			log.Fatalf("Error: invalid STT_SMART_FORMAT: %v", err)
+		}
+		config.STTParams.SmartFormat = &v
+	}
+	if punctuate := os.Getenv("STT_PUNCTUATE"); punctuate != "" {
+		v, err := strconv.ParseBool(punctuate)
+		if err != nil {
+			log.Fatalf("Error: invalid STT_PUNCTUATE: %v", err)
+		}
+		config.STTParams.Punctuate = &v
+	}
+	if diarize := os.Getenv("STT_DIARIZE"); diarize != "" {
+		v, err := strconv.ParseBool(diarize)
+		if err != nil {
+			log.Fatalf("Error: invalid STT_DIARIZE: %v", err)
+		}
+		config.STTParams.Diarize = &v
+	}
+	if maxSessions := os.Getenv("MAX_SESSIONS"); maxSessions != "" {
+		n, err := strconv.Atoi(maxSessions)
+		if err != nil {
+			log.Fatalf("Error: invalid MAX_SESSIONS: %v", err)
+		}
+		config.Capacity.MaxSessions = n
+	}
+	if queueTimeout := os.Getenv("SESSION_QUEUE_TIMEOUT"); queueTimeout != "" {
+		d, err := time.ParseDuration(queueTimeout)
+		if err != nil {
+			log.Fatalf("Error: invalid SESSION_QUEUE_TIMEOUT: %v", err)
+		}
+		config.Capacity.QueueTimeout = d
+	}
+	if interval := os.Getenv("CHECKPOINT_EVENT_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Error: invalid CHECKPOINT_EVENT_INTERVAL: %v", err)
+		}
+		config.CheckpointEvents.Interval = d
+	}
+	if timeout := os.Getenv("IDLE_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("Error: invalid IDLE_TIMEOUT: %v", err)
+		}
+		config.Idle = orchestrator.IdleConfig{Enabled: true, Timeout: d, Prompt: os.Getenv("IDLE_PROMPT")}
+		if maxTimeouts := os.Getenv("IDLE_MAX_TIMEOUTS"); maxTimeouts != "" {
+			n, err := strconv.Atoi(maxTimeouts)
+			if err != nil {
+				log.Fatalf("Error: invalid IDLE_MAX_TIMEOUTS: %v", err)
+			}
+			config.Idle.MaxTimeouts = n
+		}
+	}
+	if artifacts := openArtifactStorage(); artifacts != nil {
+		config.TurnArtifacts.Storage = artifacts
+	}
+
+	if key := getSecret("ENCRYPTION_KEY"); key != "" {
+		raw, err := orchestrator.ParseEncryptionKey(key)
+		if err != nil {
+			log.Fatalf("Error: invalid ENCRYPTION_KEY: %v", err)
+		}
+		if config.Encryptor, err = orchestrator.NewAESGCMEncryptorFromKey(raw); err != nil {
+			log.Fatalf("Error: invalid ENCRYPTION_KEY: %v", err)
+		}
+		if os.Getenv("ENCRYPTION_REJECT_PLAINTEXT") == "true" {
+			config.Encryptor = orchestrator.RejectPlaintext(config.Encryptor)
+		}
+	}
+
+	retention := orchestrator.NewPurgeScheduler(retentionPolicy(), 0, nil)
+	sessionStore := openRetainedStore("", config.Encryptor, retention)
+	if os.Getenv("SESSION_CHECKPOINT") == "true" {
+		// Checkpoints of live sessions are kept apart from handoff
+		// snapshots, which clients can resume.
+		checkpoints := openRetainedStore("checkpoints", config.Encryptor, retention)
+		if checkpoints == nil {
+			log.Fatal("Error: SESSION_CHECKPOINT requires a session store")
+		}
+		config.Checkpoint.Store = checkpoints
+	}
+
+	if dir := os.Getenv("RECORDING_DIR"); dir != "" {
+		recordings, err := store.NewRecordingDir(dir)
+		if err != nil {
+			log.Fatalf("Error: recording dir: %v", err)
+		}
+		config.Recording.Storage = recordings
+		retention.Register(orchestrator.ArtifactRecording, recordings.Matching("*.wav"))
+		retention.Register(orchestrator.ArtifactTranscript, recordings.Matching("timeline.jsonl"))
+	} else if blobs := openBlobStore("recordings/"); blobs != nil {
+		config.Recording.Storage = blobs
+		if bucket, ok := blobs.(interface {
+			Matching(pattern string) orchestrator.Purgeable
+		}); ok {
+			retention.Register(orchestrator.ArtifactRecording, bucket.Matching("*.wav"))
+			retention.Register(orchestrator.ArtifactTranscript, bucket.Matching("timeline.jsonl"))
+		}
+	}
+	if capacity := os.Getenv("EVENT_BUFFER"); capacity != "" {
+		n, err := strconv.Atoi(capacity)
+		if err != nil {
+			log.Fatalf("Error: invalid EVENT_BUFFER: %v", err)
+		}
+		config.Events.Capacity = n
+	}
+	switch policy := orchestrator.Backpressure(os.Getenv("EVENT_OVERFLOW")); policy {
+	case "":
+	case orchestrator.DropNewest, orchestrator.DropOldest:
+		config.Events.Policy = policy
+	default:
+		log.Fatalf("Error: invalid EVENT_OVERFLOW: %q", policy)
+	}
+	config.Events.PrioritizeControl = os.Getenv("EVENT_PRIORITIZE_CONTROL") == "true"
+	if os.Getenv("TTS_CACHE") == "true" {
+		config.TTSCache = orchestrator.TTSCacheConfig{Enabled: true, Store: openTTSCacheStore()}
+	}
+	var webhook *sink.WebhookSink
+	if url := os.Getenv("WEBHOOK_URL"); url != "" {
+		webhook = sink.NewWebhookSink(url, sink.WebhookOptions{Secret: []byte(getSecret("WEBHOOK_SECRET"))})
+		config.EventSinks = append(config.EventSinks, webhook)
+	}
+
+	// A profile brings its own VAD.
+	var vad orchestrator.VADProvider
+	if config.Profile == "" {
+		rms := orchestrator.NewRMSVAD(config.BargeInVADThreshold, 800*time.Millisecond)
+		if config.SampleRate == 48000 {
+			rms = orchestrator.NewOpusVAD(config.BargeInVADThreshold, 800*time.Millisecond)
+		}
+		rms.SetMinConfirmed(2)
+		vad = rms
+	}
+	// Empty values keep the api.lokutor.com defaults.
+	tts := ttsProvider.NewLokutorTTSWithOptions(lokutorKey, ttsProvider.LokutorOptions{
+		Host:    os.Getenv("LOKUTOR_HOST"),
+		Scheme:  os.Getenv("LOKUTOR_SCHEME"),
+		Path:    os.Getenv("LOKUTOR_PATH"),
+		Visemes: os.Getenv("LOKUTOR_VISEMES") == "true",
+	})
+	envconfig.BindSecret(tts, secretStore, "LOKUTOR_API_KEY")
+	orch := orchestrator.NewWithVAD(stt, llm, tts, vad, config)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	retention.Start(ctx)
+	defer retention.Stop()
+
+	if adminAddr := os.Getenv("ADMIN_ADDR"); adminAddr != "" {
+		adminToken := getSecret("ADMIN_TOKEN")
+		if adminToken == "" {
+			log.Fatal("Error: ADMIN_ADDR requires ADMIN_TOKEN")
+		}
+		adminServer := admin.NewServer(orch, adminToken)
+		adminServer.SetRetention(retention)
+		go func() {
+			if err := adminServer.ListenAndServe(ctx, adminAddr); err != nil {
+				log.Printf("Admin server error: %v", err)
+			}
+		}()
+	}
+
+	opts := server.Options{
+		SystemPrompt:     os.Getenv("SYSTEM_PROMPT"),
+		InputSampleRate:  config.SampleRate,
+		OutputSampleRate: config.SampleRate,
+	}
+	// Anyone who can reach /ws may resume sessions from the store, so it
+	// takes API_TOKEN.
+	if token := getSecret("API_TOKEN"); token != "" {
+		opts.Authenticate = server.TokenAuth(token)
+		opts.Store = sessionStore
+	} else if sessionStore != nil {
+		log.Println("Note: API_TOKEN is not set; session resume and draining are off")
+	}
+	// Reconnecting by session_id alone continues a conversation, so this
+	// also takes API_TOKEN.
+	if ttl := os.Getenv("SESSION_RECONNECT_TTL"); ttl != "" && opts.Authenticate != nil {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			log.Fatalf("Error: invalid SESSION_RECONNECT_TTL: %v", err)
+		}
+		managerOpts := orchestrator.SessionManagerOptions{IdleTTL: d}
+		if max := os.Getenv("SESSION_RECONNECT_MAX"); max != "" {
+			n, err := strconv.Atoi(max)
+			if err != nil {
+				log.Fatalf("Error: invalid SESSION_RECONNECT_MAX: %v", err)
+			}
+			managerOpts.MaxSessions = n
+		}
+		manager := orchestrator.NewSessionManager(orch, managerOpts)
+		// Not stopped on shutdown: its streams are drained with the rest.
+		go manager.Run(context.Background())
+		opts.Sessions = manager
+	} else if ttl != "" {
+		log.Println("Note: API_TOKEN is not set; SESSION_RECONNECT_TTL is ignored")
+	}
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		locker := store.NewRedisLocker(store.RedisOptions{
+			Addr:     redisAddr,
+			Password: getSecret("REDIS_PASSWORD"),
+		})
+		defer locker.Close()
+		opts.Locker = locker
+		opts.InstanceID, _ = os.Hostname()
+	}
+	handler := server.NewHandler(orch, opts)
+
+	mux := http.NewServeMux()
+	mux.Handle("/ws", handler)
+	if audio.DefaultOpusCodec != nil {
+		// Admitted like /ws.
+		mux.Handle("/rtc", webrtc.NewHandler(orch, webrtc.Options{
+			Authenticate: opts.Authenticate,
+			SystemPrompt: os.Getenv("SYSTEM_PROMPT"),
+		}))
+	} else {
+		log.Println("Note: built without -tags opus; /rtc and codec=opus on /ws are unavailable")
+	}
+	// The REST API reads and writes whole conversations, so it is only
+	// served behind API_TOKEN, and keeps them apart from handoff snapshots.
+	if token := getSecret("API_TOKEN"); token != "" {
+		mux.Handle("/v1/", rest.NewServer(orch, rest.Options{
+			Token:        token,
+			SystemPrompt: os.Getenv("SYSTEM_PROMPT"),
+			Store:        openRetainedStore("rest", config.Encryptor, retention),
+		}))
+	}
+	mux.HandleFunc("/healthz", handler.Healthz)
+	mux.HandleFunc("/readyz", handler.Readyz)
+	if publicURL := os.Getenv("TWILIO_PUBLIC_URL"); publicURL != "" {
+		// Without the signature check anyone could place calls on /twilio.
+		mux.Handle("/twilio", twilio.NewHandler(orch, twilio.Options{
+			SystemPrompt: os.Getenv("SYSTEM_PROMPT"),
+			AuthToken:    requireSecret("TWILIO_AUTH_TOKEN"),
+			PublicURL:    publicURL,
+		}))
+	}
+
+	if sipAddr := os.Getenv("SIP_LISTEN_ADDR"); sipAddr != "" {
+		ua := sip.NewUserAgent(orch, sip.Options{
+			ListenAddr:   sipAddr,
+			Host:         os.Getenv("SIP_PUBLIC_HOST"),
+			Registrar:    os.Getenv("SIP_REGISTRAR"),
+			Username:     os.Getenv("SIP_USERNAME"),
+			Password:     getSecret("SIP_PASSWORD"),
+			Domain:       os.Getenv("SIP_DOMAIN"),
+			SystemPrompt: os.Getenv("SYSTEM_PROMPT"),
+		})
+		go func() {
+			if err := ua.ListenAndServe(ctx); err != nil {
+				log.Printf("SIP user agent error: %v", err)
+			}
+		}()
+	}
+
+	addr := os.Getenv("LISTEN_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if opts.Store != nil {
+			if err := handler.Drain(shutdownCtx); err != nil {
+				log.Printf("Drain error: %v", err)
+			}
+		}
+		if err := orch.FlushCheckpoints(shutdownCtx); err != nil {
+			log.Printf("Checkpoint flush error: %v", err)
+		}
+		if err := orch.FlushRecordings(shutdownCtx); err != nil {
+			log.Printf("Recording flush error: %v", err)
+		}
+		orch.Close()
+		if webhook != nil {
+			if err := webhook.Close(shutdownCtx); err != nil {
+				log.Printf("Webhook flush error: %v", err)
+			}
+		}
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	for _, p := range handler.Preflight(ctx) {
+		log.Printf("Provider %s (%s): %s %s", p.Kind, p.Name, p.Status, p.Error)
+	}
+
+	log.Printf("Voice server listening on %s", addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+// retentionPolicy reads how many days recordings, their transcripts and
+// session snapshots are kept from RETENTION_RECORDING_DAYS,
+// RETENTION_TRANSCRIPT_DAYS and RETENTION_SESSION_DAYS. Unset keeps them
+// forever.
+func retentionPolicy() orchestrator.RetentionPolicy {
+	var policy orchestrator.RetentionPolicy
+	for name, days := range map[string]*int{
+		"RETENTION_RECORDING_DAYS":  &policy.RecordingDays,
+		"RETENTION_TRANSCRIPT_DAYS": &policy.TranscriptDays,
+		"RETENTION_SESSION_DAYS":    &policy.SessionDays,
+	} {
+		if v := os.Getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				log.Fatalf("Error: invalid %s: %q", name, v)
+			}
+			*days = n
+		}
+	}
+	return policy
+}
+
+// openSessionStore picks the session store from the environment: a SQLite
+// database, Redis, or a directory of JSON files, in that order. A non-empty
+// namespace, such as "checkpoints", gets its own table, key prefix or
+// subdirectory.
+func openSessionStore(namespace string) orchestrator.SessionStore {
+	if path := os.Getenv("SESSION_STORE_SQLITE"); path != "" {
+		db, err := sql.Open("sqlite", path)
+		if err != nil {
+			log.Fatalf("Error: session store: %v", err)
+		}
+		table := "lokutor_sessions"
+		if namespace != "" {
+			table = "lokutor_" + namespace
+		}
+		s, err := store.NewSQLiteStoreTable(context.Background(), db, table)
+		if err != nil {
+			log.Fatalf("Error: session store: %v", err)
+		}
+		return s
+	}
+	if os.Getenv("SESSION_STORE_REDIS") == "true" {
+		opts := store.RedisStoreOptions{
+			RedisOptions: store.RedisOptions{
+				Addr:     requireEnv("REDIS_ADDR"),
+				Password: getSecret("REDIS_PASSWORD"),
+			},
+			TTL: 24 * time.Hour,
+		}
+		if namespace != "" {
+			opts.KeyPrefix = namespace + ":"
+		}
+		return store.NewRedisStore(opts)
+	}
+	if dir := os.Getenv("SESSION_STORE_DIR"); dir != "" {
+		if namespace != "" {
+			dir = filepath.Join(dir, namespace)
+		}
+		s, err := store.NewFileStore(dir)
+		if err != nil {
+			log.Fatalf("Error: session store: %v", err)
+		}
+		return s
+	}
+	return nil
+}
+
+// openRetainedStore opens the session store for namespace, seals it with enc
+// when set and registers it with retention, so every store the server writes
+// to is encrypted and purged alike. It returns nil when no store is
+// configured.
+func openRetainedStore(namespace string, enc orchestrator.Encryptor, retention *orchestrator.PurgeScheduler) orchestrator.SessionStore {
+	s := openSessionStore(namespace)
+	if s == nil {
+		return nil
+	}
+	if setter, ok := s.(orchestrator.EncryptorSetter); ok && enc != nil {
+		setter.SetEncryptor(enc)
+	}
+	if purgeable, ok := s.(orchestrator.Purgeable); ok {
+		retention.Register(orchestrator.ArtifactSession, purgeable)
+	}
+	return s
+}
+
+// openTTSCacheStore picks where cached speech is kept besides memory: a
+// directory (TTS_CACHE_DIR) or, with TTS_CACHE_REDIS=true, Redis. It returns
+// nil for a memory-only cache.
+func openTTSCacheStore() orchestrator.BlobStore {
+	if dir := os.Getenv("TTS_CACHE_DIR"); dir != "" {
+		d, err := store.NewRecordingDir(dir)
+		if err != nil {
+			log.Fatalf("Error: tts cache dir: %v", err)
+		}
+		return d
+	}
+	if os.Getenv("TTS_CACHE_REDIS") == "true" {
+		return store.NewRedisBlobStore(store.RedisBlobStoreOptions{
+			RedisOptions: store.RedisOptions{
+				Addr:     requireEnv("REDIS_ADDR"),
+				Password: getSecret("REDIS_PASSWORD"),
+			},
+			TTL: 7 * 24 * time.Hour,
+		})
+	}
+	return nil
+}
+
+// openArtifactStorage adds the blob store to the shared artifact storage
+// choices: with TURN_ARTIFACTS=true and no directory or bucket of their own,
+// artifacts go under BLOB_STORE's artifacts/ prefix.
+func openArtifactStorage() orchestrator.RecordingStorage {
+	if artifacts := envconfig.OpenArtifactStorage(); artifacts != nil {
+		return artifacts
+	}
+	if os.Getenv("TURN_ARTIFACTS") == "true" {
+		if blobs := openBlobStore("artifacts/"); blobs != nil {
+			return blobs
+		}
+		log.Fatal("Error: TURN_ARTIFACTS requires ARTIFACT_DIR or a blob store")
+	}
+	return nil
+}
+
+// openBlobStore opens the object store picked by BLOB_STORE (s3 or gcs) for
+// BLOB_BUCKET, with objects under BLOB_PREFIX and then prefix. It returns nil
+// when BLOB_STORE is not set.
+func openBlobStore(prefix string) orchestrator.BlobStore {
+	switch os.Getenv("BLOB_STORE") {
+	case "":
+		return nil
+	case "s3":
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		s := store.NewS3Storage(requireEnv("BLOB_BUCKET"), region)
+		s.SetPrefix(os.Getenv("BLOB_PREFIX") + prefix)
+		return s
+	case "gcs":
+		s := store.NewGCSStorage(requireEnv("BLOB_BUCKET"), requireSecret("GCS_ACCESS_TOKEN"))
+		s.SetPrefix(os.Getenv("BLOB_PREFIX") + prefix)
+		return s
+	default:
+		log.Fatalf("Error: unknown BLOB_STORE %q", os.Getenv("BLOB_STORE"))
+		return nil
+	}
+}
+
+// secretStore is where credentials and tokens are read from, so they can
+// live in a secrets backend rather than the environment.
+var secretStore secrets.Provider
+
+// getSecret returns the secret name, or "" when it is not set.
+func getSecret(name string) string {
+	v, err := secretStore.GetSecret(context.Background(), name)
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		log.Fatalf("Error: could not read %s from %s: %v", name, secretStore.Name(), err)
+	}
+	return v
+}
+
+func requireSecret(name string) string {
+	v := getSecret(name)
+	if v == "" {
+		log.Fatalf("Error: %s must be set.", name)
+	}
+	return v
+}
+
+func requireEnv(name string) string {
+	v := os.Getenv(name)
+	if v == "" {
+		log.Fatalf("Error: %s must be set.", name)
+	}
+	return v
+}
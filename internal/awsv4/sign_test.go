@@ -0,0 +1,56 @@
+package awsv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Matches the get-vanilla example from the AWS SigV4 test suite.
+func TestSignVanillaGet(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	req.Header.Set("X-Amz-Content-Sha256", hashHex(nil))
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	if err := Sign(req, creds, "us-east-1", "service", now); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request") {
+		t.Errorf("unexpected credential scope: %s", auth)
+	}
+	if !strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date") {
+		t.Errorf("unexpected signed headers: %s", auth)
+	}
+	if req.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+		t.Errorf("unexpected date header %s", req.Header.Get("X-Amz-Date"))
+	}
+}
+
+func TestSignOfficialVector(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	if err := sign(req, creds, "us-east-1", "service", now, hashHex(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(req.Header.Get("Authorization"), "Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31") {
+		t.Errorf("signature mismatch: %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestSignRequiresCredentials(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err := Sign(req, Credentials{}, "us-east-1", "s3", time.Now()); err == nil {
+		t.Error("expected error without credentials")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a b/c~"); got != "a%20b%2Fc~" {
+		t.Errorf("unexpected escape: %s", got)
+	}
+}
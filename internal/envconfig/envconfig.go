@@ -0,0 +1,95 @@
+// Package envconfig holds the setup cmd/agent and cmd/server both read from
+// the environment, so the two binaries configure it the same way.
+package envconfig
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/store"
+)
+
+// NewSecretProvider picks the secret backend from SECRETS_BACKEND (env, file,
+// vault or aws). Non-env backends fall back to the environment and are cached
+// so rotated keys are picked up without a restart.
+func NewSecretProvider() secrets.Provider {
+	env := secrets.NewEnvProvider("")
+
+	var backend secrets.Provider
+	switch os.Getenv("SECRETS_BACKEND") {
+	case "file":
+		dir := os.Getenv("SECRETS_DIR")
+		if dir == "" {
+			dir = "/run/secrets"
+		}
+		backend = secrets.NewFileProvider(dir)
+	case "vault":
+		backend = secrets.NewVaultProvider(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_MOUNT"), os.Getenv("VAULT_SECRET_PATH"))
+	case "aws":
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		backend = secrets.NewAWSSecretsManagerProvider(region)
+	default:
+		return env
+	}
+
+	ttl := 5 * time.Minute
+	if v, err := time.ParseDuration(os.Getenv("SECRETS_CACHE_TTL")); err == nil {
+		ttl = v
+	}
+	return secrets.NewCachedProvider(secrets.NewChainProvider(backend, env), ttl)
+}
+
+// BindSecret makes p read its key name from provider on every request, if p
+// supports it, so rotated keys take effect without a restart.
+func BindSecret(p interface{}, provider secrets.Provider, name string) {
+	if s, ok := p.(interface {
+		SetSecretProvider(secrets.Provider, string)
+	}); ok {
+		s.SetSecretProvider(provider, name)
+	}
+}
+
+// OpenArtifactStorage picks where turn audio is saved for debugging: a
+// bounded directory (ARTIFACT_DIR) or an S3 bucket (ARTIFACT_S3_BUCKET).
+// It returns nil when neither is set.
+func OpenArtifactStorage() orchestrator.RecordingStorage {
+	if dir := os.Getenv("ARTIFACT_DIR"); dir != "" {
+		opts := store.ArtifactDirOptions{MaxBytes: 512 << 20}
+		if mb := os.Getenv("ARTIFACT_MAX_MB"); mb != "" {
+			n, err := strconv.Atoi(mb)
+			if err != nil {
+				log.Fatalf("Error: invalid ARTIFACT_MAX_MB: %v", err)
+			}
+			opts.MaxBytes = int64(n) << 20
+		}
+		if age := os.Getenv("ARTIFACT_MAX_AGE"); age != "" {
+			d, err := time.ParseDuration(age)
+			if err != nil {
+				log.Fatalf("Error: invalid ARTIFACT_MAX_AGE: %v", err)
+			}
+			opts.MaxAge = d
+		}
+		d, err := store.NewArtifactDir(dir, opts)
+		if err != nil {
+			log.Fatalf("Error: artifact dir: %v", err)
+		}
+		return d
+	}
+	if bucket := os.Getenv("ARTIFACT_S3_BUCKET"); bucket != "" {
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		s := store.NewS3Storage(bucket, region)
+		s.SetPrefix(os.Getenv("ARTIFACT_S3_PREFIX"))
+		return s
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ErrNil is returned for a nil bulk or array reply.
+var ErrNil = errors.New("redis: nil reply")
+
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+type Options struct {
+	Addr        string
+	Password    string
+	DB          int
+	PoolSize    int
+	DialTimeout time.Duration
+	// CommandTimeout bounds commands whose context has no deadline, 5
+	// seconds by default.
+	CommandTimeout time.Duration
+}
+
+// Client is a minimal pooled Redis client covering the handful of commands the
+// orchestrator needs, so the library does not pull in a full driver.
+type Client struct {
+	opts Options
+	pool chan *conn
+}
+
+type conn struct {
+	nc net.Conn
+	rd *bufio.Reader
+}
+
+func NewClient(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 8
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.CommandTimeout <= 0 {
+		opts.CommandTimeout = 5 * time.Second
+	}
+	return &Client{opts: opts, pool: make(chan *conn, opts.PoolSize)}
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: c.opts.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{nc: nc, rd: bufio.NewReader(nc)}
+	if c.opts.Password != "" {
+		if _, err := cn.do(ctx, "AUTH", c.opts.Password); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := cn.do(ctx, "SELECT", strconv.Itoa(c.opts.DB)); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// Do runs one command and returns its reply: string, int64, []interface{} or
+// nil. Server errors are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.CommandTimeout)
+		defer cancel()
+	}
+
+	var cn *conn
+	select {
+	case cn = <-c.pool:
+	default:
+		var err error
+		if cn, err = c.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := cn.do(ctx, args...)
+	var redisErr Error
+	if err != nil && !errors.As(err, &redisErr) && !errors.Is(err, ErrNil) {
+		// The connection state is unknown after an I/O error.
+		cn.nc.Close()
+		return nil, err
+	}
+	select {
+	case c.pool <- cn:
+	default:
+		cn.nc.Close()
+	}
+	return reply, err
+}
+
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.nc.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (cn *conn) do(ctx context.Context, args ...string) (interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.nc.SetDeadline(deadline)
+	} else {
+		cn.nc.SetDeadline(time.Time{})
+	}
+	// Cancelling ctx interrupts a command stuck on a hung server. The conn
+	// is then discarded, since the reply may still arrive.
+	stop := context.AfterFunc(ctx, func() { cn.nc.SetDeadline(time.Now()) })
+	reply, err := cn.roundTrip(args)
+	var netErr net.Error
+	if !stop() || errors.As(err, &netErr) && netErr.Timeout() {
+		// Every deadline on the conn comes from ctx, which may not have
+		// noticed yet that it expired.
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return reply, err
+}
+
+func (cn *conn) roundTrip(args []string) (interface{}, error) {
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := cn.nc.Write(buf); err != nil {
+		return nil, err
+	}
+	return ReadReply(cn.rd)
+}
+
+func readLine(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// ReadReply parses one RESP2 value.
+func ReadReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := readLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		// An error element, e.g. from EXEC, is returned only after the
+		// rest of the array is read, so the conn stays in step.
+		items := make([]interface{}, n)
+		var firstErr error
+		for i := range items {
+			item, err := ReadReply(rd)
+			var redisErr Error
+			switch {
+			case err == nil, errors.Is(err, ErrNil):
+			case errors.As(err, &redisErr):
+				if firstErr == nil {
+					firstErr = err
+				}
+			default:
+				return nil, err
+			}
+			items[i] = item
+		}
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,161 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadReply(t *testing.T) {
+	rd := bufio.NewReader(strings.NewReader("+OK\r\n:42\r\n$5\r\nhello\r\n$-1\r\n*2\r\n$1\r\na\r\n:1\r\n-ERR boom\r\n"))
+
+	if v, _ := ReadReply(rd); v != "OK" {
+		t.Errorf("simple string: got %v", v)
+	}
+	if v, _ := ReadReply(rd); v != int64(42) {
+		t.Errorf("integer: got %v", v)
+	}
+	if v, _ := ReadReply(rd); v != "hello" {
+		t.Errorf("bulk string: got %v", v)
+	}
+	if _, err := ReadReply(rd); !errors.Is(err, ErrNil) {
+		t.Errorf("nil bulk: got %v", err)
+	}
+	v, _ := ReadReply(rd)
+	if arr, ok := v.([]interface{}); !ok || len(arr) != 2 || arr[0] != "a" || arr[1] != int64(1) {
+		t.Errorf("array: got %v", v)
+	}
+	if _, err := ReadReply(rd); err == nil || err.Error() != "redis: ERR boom" {
+		t.Errorf("error reply: got %v", err)
+	}
+}
+
+func TestReadReply_ErrorInsideArray(t *testing.T) {
+	rd := bufio.NewReader(strings.NewReader("*3\r\n+OK\r\n-ERR first\r\n$4\r\nrest\r\n+NEXT\r\n"))
+
+	var redisErr Error
+	if _, err := ReadReply(rd); !errors.As(err, &redisErr) || err.Error() != "redis: ERR first" {
+		t.Errorf("expected the element's error, got %v", err)
+	}
+	if v, err := ReadReply(rd); v != "NEXT" {
+		t.Errorf("expected the array to be read to its end, got %v (%v)", v, err)
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		rd := bufio.NewReader(c)
+		for {
+			cmd, err := ReadReply(rd)
+			if err != nil {
+				return
+			}
+			args := cmd.([]interface{})
+			switch args[0] {
+			case "AUTH":
+				c.Write([]byte("+OK\r\n"))
+			case "ECHO":
+				s := args[1].(string)
+				c.Write([]byte("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"))
+			default:
+				c.Write([]byte("-ERR unknown command\r\n"))
+			}
+		}
+	}()
+
+	client := NewClient(Options{Addr: ln.Addr().String(), Password: "pw", PoolSize: 1})
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		v, err := client.Do(context.Background(), "ECHO", "hi there")
+		if err != nil || v != "hi there" {
+			t.Fatalf("unexpected reply %v (%v)", v, err)
+		}
+	}
+	var redisErr Error
+	if _, err := client.Do(context.Background(), "NOPE"); !errors.As(err, &redisErr) {
+		t.Errorf("expected server error, got %v", err)
+	}
+	// The connection must still be usable after a server error.
+	if v, err := client.Do(context.Background(), "ECHO", "again"); err != nil || v != "again" {
+		t.Errorf("unexpected reply %v (%v)", v, err)
+	}
+}
+
+func TestClientDo_CancelOnHungServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// Accepts and never answers.
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		io.Copy(io.Discard, c)
+	}()
+
+	client := NewClient(Options{Addr: ln.Addr().String(), PoolSize: 1})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Do(ctx, "PING")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Do kept waiting after its context was cancelled")
+	}
+	if len(client.pool) != 0 {
+		t.Error("an interrupted conn must not go back to the pool")
+	}
+}
+
+func TestClientDo_DefaultTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		io.Copy(io.Discard, c)
+	}()
+
+	client := NewClient(Options{Addr: ln.Addr().String(), CommandTimeout: 50 * time.Millisecond})
+	defer client.Close()
+	if _, err := client.Do(context.Background(), "PING"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the command to time out, got %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Server exposes runtime introspection and control of an Orchestrator's live
+// streams. It is meant to be bound to an internal address.
+type Server struct {
+	orch          *orchestrator.Orchestrator
+	token         string
+	healthTimeout time.Duration
+	mux           *http.ServeMux
+	retention     *orchestrator.PurgeScheduler
+}
+
+// NewServer returns an admin server. Every request must carry
+// "Authorization: Bearer <token>"; with an empty token every request is
+// refused, so a missing setting can't leave the server open.
+func NewServer(orch *orchestrator.Orchestrator, token string) *Server {
+	s := &Server{
+		orch:          orch,
+		token:         token,
+		healthTimeout: 5 * time.Second,
+		mux:           http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /sessions", s.listSessions)
+	s.mux.HandleFunc("GET /sessions/{id}", s.getSession)
+	s.mux.HandleFunc("POST /sessions/{id}/interrupt", s.interruptSession)
+	s.mux.HandleFunc("POST /sessions/{id}/close", s.closeSession)
+	s.mux.HandleFunc("GET /sessions/{id}/monitor", s.monitorSession)
+	s.mux.HandleFunc("POST /sessions/{id}/whisper", s.whisperSession)
+	s.mux.HandleFunc("POST /sessions/{id}/takeover", s.takeOverSession)
+	s.mux.HandleFunc("POST /sessions/{id}/release", s.releaseSession)
+	s.mux.HandleFunc("GET /sessions/{id}/tuning", s.getTuning)
+	s.mux.HandleFunc("POST /sessions/{id}/tuning", s.tuneSession)
+	s.mux.HandleFunc("GET /providers", s.providerHealth)
+	s.mux.HandleFunc("GET /config", s.getConfig)
+	s.mux.HandleFunc("GET /capacity", s.getCapacity)
+	s.mux.HandleFunc("GET /qa", s.getQA)
+	s.mux.HandleFunc("DELETE /users/{id}", s.deleteUser)
+	return s
+}
+
+// SetRetention lets DELETE /users/{id} erase a user's stored data through
+// retention.
+func (s *Server) SetRetention(retention *orchestrator.PurgeScheduler) {
+	s.retention = retention
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	want := "Bearer " + s.token
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	streams := s.orch.Streams()
+	statuses := make([]orchestrator.StreamStatus, 0, len(streams))
+	for _, ms := range streams {
+		statuses = append(statuses, ms.Status())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": statuses})
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, ms.Status())
+}
+
+func (s *Server) interruptSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	ms.Interrupt()
+	writeJSON(w, http.StatusOK, ms.Status())
+}
+
+func (s *Server) closeSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	ms.Close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type supervisorRequest struct {
+	Supervisor string `json:"supervisor"`
+	Text       string `json:"text"`
+}
+
+func (s *Server) whisperSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	var req supervisorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ms.Whisper(req.Supervisor, req.Text); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) takeOverSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	var req supervisorRequest
+	if err := decodeOptional(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !ms.TakeOver(req.Supervisor) {
+		writeError(w, http.StatusConflict, "session is already taken over or closed")
+		return
+	}
+	writeJSON(w, http.StatusOK, ms.Status())
+}
+
+func (s *Server) releaseSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	var req supervisorRequest
+	if err := decodeOptional(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !ms.Release(req.Supervisor) {
+		writeError(w, http.StatusConflict, "session is not taken over")
+		return
+	}
+	writeJSON(w, http.StatusOK, ms.Status())
+}
+
+// decodeOptional decodes the JSON request body into v, if there is one.
+func decodeOptional(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) getTuning(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, ms.CurrentTuning())
+}
+
+func (s *Server) tuneSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	var tuning orchestrator.Tuning
+	if err := json.NewDecoder(r.Body).Decode(&tuning); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ms.Tune(tuning); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ms.CurrentTuning())
+}
+
+func (s *Server) providerHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.healthTimeout)
+	defer cancel()
+
+	results := s.orch.CheckProviders(ctx)
+	status := http.StatusOK
+	for _, h := range results {
+		if h.Status == "unhealthy" {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, status, map[string]interface{}{"providers": results})
+}
+
+func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := s.orch.GetConfig()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"providers":       s.orch.GetProviders(),
+		"language":        cfg.Language,
+		"voice":           cfg.VoiceStyle,
+		"sample_rate":     cfg.SampleRate,
+		"first_speaker":   cfg.FirstSpeaker,
+		"vad_threshold":   cfg.BargeInVADThreshold,
+		"require_consent": cfg.RequireConsent,
+	})
+}
+
+func (s *Server) getCapacity(w http.ResponseWriter, r *http.Request) {
+	var processing time.Duration
+	goroutines := 0
+	for _, ms := range s.orch.Streams() {
+		stats := ms.Status().Stats
+		processing += stats.ProcessingTime
+		goroutines += stats.Goroutines
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"capacity":           s.orch.Capacity(),
+		"processing_time":    processing,
+		"stream_goroutines":  goroutines,
+		"process_goroutines": runtime.NumGoroutine(),
+	})
+}
+
+func (s *Server) getQA(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"stats":   s.orch.QAStats(),
+		"flagged": s.orch.FlaggedTurns(),
+	})
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	if s.retention == nil {
+		writeError(w, http.StatusNotImplemented, "no retention configured")
+		return
+	}
+	n, err := s.retention.DeleteUser(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"deleted": n, "error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deleted": n})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,289 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return nil, nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return nil
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+func newTestServer(t *testing.T, token string) (*orchestrator.Orchestrator, *httptest.Server) {
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, orchestrator.Config{})
+	srv := httptest.NewServer(NewServer(orch, token))
+	t.Cleanup(srv.Close)
+	return orch, srv
+}
+
+// newAuthorizedServer returns a server that every request reaches with the
+// token, so tests of the endpoints can use plain http.Get.
+func newAuthorizedServer(t *testing.T) (*orchestrator.Orchestrator, *Server, *httptest.Server) {
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, orchestrator.Config{})
+	admin := NewServer(orch, "secret")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer secret")
+		admin.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return orch, admin, srv
+}
+
+func TestAdmin_ListAndClose(t *testing.T) {
+	orch, _, srv := newAuthorizedServer(t)
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("s1"))
+	defer stream.Close()
+
+	resp, err := http.Get(srv.URL + "/sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct {
+		Sessions []orchestrator.StreamStatus `json:"sessions"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Sessions) != 1 || list.Sessions[0].SessionID != "s1" || list.Sessions[0].State != orchestrator.StreamIdle {
+		t.Fatalf("unexpected sessions: %+v", list.Sessions)
+	}
+
+	resp, err = http.Post(srv.URL+"/sessions/s1/close", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if stream.State() != orchestrator.StreamClosed {
+		t.Errorf("expected stream to be closed")
+	}
+
+	resp, _ = http.Get(srv.URL + "/sessions/s1")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for closed session, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	_, open := newTestServer(t, "")
+	req, _ := http.NewRequest("GET", open.URL+"/providers", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	resp, _ := http.DefaultClient.Do(req)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a server without a token to refuse requests, got %d", resp.StatusCode)
+	}
+
+	_, srv := newTestServer(t, "secret")
+
+	resp, _ = http.Get(srv.URL + "/providers")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+
+	req, _ = http.NewRequest("GET", srv.URL+"/providers", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Providers []orchestrator.ProviderHealth `json:"providers"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if len(body.Providers) != 3 || body.Providers[1].Name != "stub-llm" {
+		t.Errorf("unexpected providers: %+v", body.Providers)
+	}
+}
+
+func TestAdmin_MonitorSession(t *testing.T) {
+	orch, srv := newTestServer(t, "secret")
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("s1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/sessions/s1/monitor"
+	conn, _, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer secret"}},
+	})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.CloseNow()
+
+	for stream.MonitorCount() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stream.Write([]byte{7, 7})
+
+	typ, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if typ != websocket.MessageBinary || data[0] != monitorUserAudioTag || len(data) != 3 {
+		t.Errorf("expected tagged caller audio, got %v %v", typ, data)
+	}
+
+	stream.Close()
+	for {
+		if _, _, err := conn.Read(ctx); err != nil {
+			if websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+				t.Errorf("expected normal closure, got %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestAdmin_WhisperAndTakeOver(t *testing.T) {
+	orch, _, srv := newAuthorizedServer(t)
+	session := orchestrator.NewConversationSession("s1")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	resp, err := http.Post(srv.URL+"/sessions/s1/whisper", "application/json", strings.NewReader(`{"supervisor":"alice","text":"be brief"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if len(session.GetContextCopy()) != 1 {
+		t.Errorf("expected guidance in session context")
+	}
+
+	resp, _ = http.Post(srv.URL+"/sessions/s1/takeover", "application/json", strings.NewReader(`{"supervisor":"alice"}`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !stream.IsSupervised() {
+		t.Fatalf("expected takeover, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(srv.URL+"/sessions/s1/takeover", "application/json", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 on second takeover, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(srv.URL+"/sessions/s1/release", "application/json", strings.NewReader(`{"supervisor":`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !stream.IsSupervised() {
+		t.Errorf("expected 400 on a malformed body, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(srv.URL+"/sessions/s1/release", "application/json", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || stream.IsSupervised() {
+		t.Errorf("expected release, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdmin_Tuning(t *testing.T) {
+	orch, _, srv := newAuthorizedServer(t)
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("s1"))
+	defer stream.Close()
+
+	resp, err := http.Post(srv.URL+"/sessions/s1/tuning", "application/json", strings.NewReader(`{"vad_threshold":0.03,"min_confirmed":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got orchestrator.Tuning
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if *got.VADThreshold != 0.03 || *got.MinConfirmed != 4 || got.EchoThreshold == nil {
+		t.Errorf("unexpected tuning %+v", got)
+	}
+
+	resp, _ = http.Post(srv.URL+"/sessions/s1/tuning", "application/json", strings.NewReader(`{"min_confirmed":0}`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid value, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Get(srv.URL + "/sessions/nope/tuning")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+type userArtifacts map[string]int
+
+func (u userArtifacts) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+func (u userArtifacts) DeleteUser(ctx context.Context, userID string) (int, error) {
+	n := u[userID]
+	delete(u, userID)
+	return n, nil
+}
+
+func TestAdmin_DeleteUser(t *testing.T) {
+	_, admin, srv := newAuthorizedServer(t)
+
+	del := func() (int, int) {
+		req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/users/u1", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Deleted int `json:"deleted"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Deleted
+	}
+	if status, _ := del(); status != http.StatusNotImplemented {
+		t.Errorf("expected 501 without retention, got %d", status)
+	}
+
+	retention := orchestrator.NewPurgeScheduler(orchestrator.RetentionPolicy{}, 0, nil)
+	retention.Register(orchestrator.ArtifactSession, userArtifacts{"u1": 2, "u2": 1})
+	retention.Register(orchestrator.ArtifactRecording, userArtifacts{"u1": 3})
+	admin.SetRetention(retention)
+	if status, n := del(); status != http.StatusOK || n != 5 {
+		t.Errorf("expected u1's 5 artifacts deleted, got %d (%d)", n, status)
+	}
+}
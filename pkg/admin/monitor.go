@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const (
+	monitorUserAudioTag byte = 0x01
+	monitorBotAudioTag  byte = 0x02
+)
+
+type monitorCommand struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// monitorSession streams a live session to a supervisor over a websocket.
+// Events are sent as JSON text messages; audio is sent as binary messages
+// whose first byte tags the direction (0x01 caller, 0x02 bot) followed by the
+// raw PCM.
+//
+// The supervisor may send JSON commands ({"type":"whisper","text":"..."},
+// {"type":"takeover"}, {"type":"release"}) and, while the session is taken
+// over, binary PCM that is relayed to the caller.
+func (s *Server) monitorSession(w http.ResponseWriter, r *http.Request) {
+	ms, ok := s.orch.Stream(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	supervisor := r.URL.Query().Get("supervisor")
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	frames, cancel := ms.Monitor(512)
+	defer cancel()
+
+	ctx, stop := context.WithCancel(r.Context())
+	defer stop()
+
+	go func() {
+		defer stop()
+		for {
+			typ, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			if typ == websocket.MessageBinary {
+				ms.WriteSupervisorAudio(data)
+				continue
+			}
+			var cmd monitorCommand
+			if err := json.Unmarshal(data, &cmd); err != nil {
+				continue
+			}
+			switch cmd.Type {
+			case "whisper":
+				ms.Whisper(supervisor, cmd.Text)
+			case "takeover":
+				ms.TakeOver(supervisor)
+			case "release":
+				ms.Release(supervisor)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-frames:
+			if !ok {
+				conn.Close(websocket.StatusNormalClosure, "session closed")
+				return
+			}
+			if err := writeMonitorFrame(ctx, conn, frame); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeMonitorFrame(ctx context.Context, conn *websocket.Conn, frame orchestrator.MonitorFrame) error {
+	switch frame.Kind {
+	case orchestrator.MonitorUserAudio, orchestrator.MonitorBotAudio:
+		tag := monitorUserAudioTag
+		if frame.Kind == orchestrator.MonitorBotAudio {
+			tag = monitorBotAudioTag
+		}
+		msg := make([]byte, 0, len(frame.Audio)+1)
+		msg = append(msg, tag)
+		msg = append(msg, frame.Audio...)
+		return conn.Write(ctx, websocket.MessageBinary, msg)
+	}
+
+	data, err := json.Marshal(frame.Event)
+	if err != nil {
+		return nil
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
@@ -0,0 +1,260 @@
+// Package aec cancels acoustic echo: the far-end audio played through a
+// speaker that leaks back into the microphone. Unlike muting, it subtracts
+// an estimate of the echo, so the near-end talker is still heard while the
+// far end plays.
+//
+// The canceller is a partitioned-block frequency-domain NLMS filter
+// (overlap-save) kept in two copies. The background filter always adapts;
+// the foreground filter produces the output and only takes the background's
+// weights while they cancel better. Speech from both ends at once (double
+// talk) throws the background filter off, but not the output, and the
+// background restarts from the foreground once it has diverged.
+package aec
+
+import (
+	"math/bits"
+	"math/cmplx"
+	"time"
+)
+
+const (
+	defaultBlockSize  = 256
+	defaultPartitions = 8
+	defaultStep       = 0.5
+
+	// backlog bounds the far-end audio queued ahead of the microphone.
+	backlog = 2 * time.Second
+	// silence is the far-end block energy below which nothing adapts.
+	silence = 1e-8
+	// smoothing weighs past blocks in the error energies the filters are
+	// compared by.
+	smoothing = 0.5
+)
+
+// Config tunes a Canceller; zero values take the defaults.
+type Config struct {
+	// BlockSize is the number of samples processed at once, rounded up to
+	// a power of two, 256 by default. The output lags the input by one
+	// block.
+	BlockSize int `json:"block_size,omitempty"`
+	// Partitions is the number of blocks the filter spans, 8 by default.
+	// BlockSize*Partitions samples bound the echo tail it can cancel.
+	Partitions int `json:"partitions,omitempty"`
+	// Step trades adaptation speed against misadjustment, between 0 and 1,
+	// 0.5 by default.
+	Step float64 `json:"step,omitempty"`
+	// Delay is the playback latency before the echo starts. The filter
+	// wastes no taps on it.
+	Delay time.Duration `json:"delay,omitempty"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.BlockSize <= 0 {
+		c.BlockSize = defaultBlockSize
+	}
+	c.BlockSize = 1 << bits.Len(uint(c.BlockSize-1))
+	if c.Partitions <= 0 {
+		c.Partitions = defaultPartitions
+	}
+	if c.Step <= 0 || c.Step > 1 {
+		c.Step = defaultStep
+	}
+	return c
+}
+
+// Canceller removes echo of the audio passed to Play from the audio passed
+// to Process. Samples are normalized to [-1, 1]. It keeps state across calls
+// and is not safe for concurrent use.
+type Canceller struct {
+	cfg   Config
+	rate  int
+	n     int
+	fft   *fft
+	delay int
+
+	far     []float64 // far-end samples not yet matched with near-end ones
+	maxFar  int
+	nearBlk []float64
+	farBlk  []float64
+	prevFar []float64
+	out     []float64 // processed samples not yet returned
+
+	x      [][]complex128 // far-end spectra, newest first
+	bg, fg [][]complex128 // filter weights per partition
+	buf    []complex128
+	grad   []complex128
+	y      []complex128
+	bgErr  []float64
+	fgErr  []float64
+
+	bgEnergy, fgEnergy float64
+}
+
+func New(sampleRate int, cfg Config) *Canceller {
+	cfg = cfg.withDefaults()
+	n, m := cfg.BlockSize, 2*cfg.BlockSize
+	c := &Canceller{
+		cfg:     cfg,
+		rate:    sampleRate,
+		n:       n,
+		fft:     newFFT(m),
+		delay:   int(cfg.Delay.Seconds() * float64(sampleRate)),
+		maxFar:  int(backlog.Seconds() * float64(sampleRate)),
+		prevFar: make([]float64, n),
+		out:     make([]float64, n),
+		buf:     make([]complex128, m),
+		grad:    make([]complex128, m),
+		y:       make([]complex128, m),
+		bgErr:   make([]float64, n),
+		fgErr:   make([]float64, n),
+	}
+	for range cfg.Partitions {
+		c.x = append(c.x, make([]complex128, m))
+		c.bg = append(c.bg, make([]complex128, m))
+		c.fg = append(c.fg, make([]complex128, m))
+	}
+	return c
+}
+
+// Play queues far-end audio, in the order it is played.
+func (c *Canceller) Play(far []float64) {
+	if len(c.far) == 0 {
+		c.far = make([]float64, c.delay, c.delay+len(far))
+	}
+	c.far = append(c.far, far...)
+	// The microphone stopped; don't let the backlog grow without bound.
+	if len(c.far) > c.maxFar {
+		c.far = c.far[len(c.far)-c.maxFar:]
+	}
+}
+
+// Flush drops queued far-end audio that will not be played, such as the
+// rest of an interrupted reply. What the filter learned about the echo path
+// is kept.
+func (c *Canceller) Flush() {
+	c.far = nil
+}
+
+// Reset forgets the echo path as well.
+func (c *Canceller) Reset() {
+	*c = *New(c.rate, c.cfg)
+}
+
+// Process returns near with the echo of the far-end audio removed, one
+// block later.
+func (c *Canceller) Process(near []float64) []float64 {
+	for _, d := range near {
+		x := 0.0
+		if len(c.far) > 0 {
+			x = c.far[0]
+			c.far = c.far[1:]
+		}
+		c.nearBlk = append(c.nearBlk, d)
+		c.farBlk = append(c.farBlk, x)
+		if len(c.nearBlk) == c.n {
+			c.processBlock()
+			c.nearBlk, c.farBlk = c.nearBlk[:0], c.farBlk[:0]
+		}
+	}
+	out := append([]float64(nil), c.out[:len(near)]...)
+	c.out = append(c.out[:0], c.out[len(near):]...)
+	return out
+}
+
+func (c *Canceller) processBlock() {
+	n := c.n
+
+	// The newest spectrum covers the previous block and this one.
+	newest := c.x[len(c.x)-1]
+	copy(c.x[1:], c.x[:len(c.x)-1])
+	c.x[0] = newest
+	farEnergy := 0.0
+	for i := 0; i < n; i++ {
+		newest[i] = complex(c.prevFar[i], 0)
+		newest[n+i] = complex(c.farBlk[i], 0)
+		farEnergy += c.farBlk[i] * c.farBlk[i]
+	}
+	c.fft.forward(newest)
+	copy(c.prevFar, c.farBlk)
+
+	nearEnergy := 0.0
+	for _, d := range c.nearBlk {
+		nearEnergy += d * d
+	}
+	bg := c.filter(c.bg, c.bgErr)
+	fg := c.filter(c.fg, c.fgErr)
+	c.bgEnergy = smoothing*c.bgEnergy + (1-smoothing)*bg
+	c.fgEnergy = smoothing*c.fgEnergy + (1-smoothing)*fg
+	c.out = append(c.out, c.fgErr...)
+
+	switch {
+	case c.bgEnergy < c.fgEnergy && bg < nearEnergy:
+		copyWeights(c.fg, c.bg)
+		c.fgEnergy = c.bgEnergy
+	case c.bgEnergy > 4*c.fgEnergy:
+		// Double talk made the background filter diverge.
+		copyWeights(c.bg, c.fg)
+		c.bgEnergy = c.fgEnergy
+	}
+	if farEnergy > silence {
+		c.adapt()
+	}
+}
+
+// filter writes the near-end block minus w's echo estimate to e and returns
+// its energy.
+func (c *Canceller) filter(w [][]complex128, e []float64) float64 {
+	for k := range c.y {
+		var sum complex128
+		for p := range w {
+			sum += w[p][k] * c.x[p][k]
+		}
+		c.y[k] = sum
+	}
+	c.fft.inverse(c.y)
+	energy := 0.0
+	for i, d := range c.nearBlk {
+		e[i] = d - real(c.y[c.n+i])
+		energy += e[i] * e[i]
+	}
+	return energy
+}
+
+// adapt takes a constrained NLMS step of the background filter, normalized
+// per frequency by the far-end power the filter spans.
+func (c *Canceller) adapt() {
+	n := c.n
+	for i := 0; i < n; i++ {
+		c.buf[i] = 0
+		c.buf[n+i] = complex(c.bgErr[i], 0)
+	}
+	c.fft.forward(c.buf)
+	reg := float64(2*n) * 1e-6
+	for k := range c.buf {
+		power := 0.0
+		for p := range c.x {
+			power += real(c.x[p][k])*real(c.x[p][k]) + imag(c.x[p][k])*imag(c.x[p][k])
+		}
+		c.buf[k] *= complex(c.cfg.Step/(power+reg), 0)
+	}
+	for p, w := range c.bg {
+		for k := range c.grad {
+			c.grad[k] = cmplx.Conj(c.x[p][k]) * c.buf[k]
+		}
+		// Keep the gradient causal and n taps long, as the partition is.
+		c.fft.inverse(c.grad)
+		for i := n; i < 2*n; i++ {
+			c.grad[i] = 0
+		}
+		c.fft.forward(c.grad)
+		for k := range w {
+			w[k] += c.grad[k]
+		}
+	}
+}
+
+func copyWeights(dst, src [][]complex128) {
+	for p := range dst {
+		copy(dst[p], src[p])
+	}
+}
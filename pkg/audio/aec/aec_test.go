@@ -0,0 +1,138 @@
+package aec
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func noise(samples int, amp float64, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]float64, samples)
+	for i := range out {
+		out[i] = amp * (2*r.Float64() - 1)
+	}
+	return out
+}
+
+func energy(samples []float64) float64 {
+	e := 0.0
+	for _, s := range samples {
+		e += s * s
+	}
+	return e
+}
+
+// room echoes far with a short decaying impulse response after delay
+// samples.
+func room(far []float64, delay int) []float64 {
+	taps := []float64{0.5, -0.3, 0.2, 0, 0.1, -0.05}
+	out := make([]float64, len(far))
+	for i := range out {
+		for k, h := range taps {
+			if j := i - delay - k; j >= 0 {
+				out[i] += h * far[j]
+			}
+		}
+	}
+	return out
+}
+
+func run(c *Canceller, far, near []float64, chunk int) []float64 {
+	var out []float64
+	for i := 0; i < len(near); i += chunk {
+		c.Play(far[i : i+chunk])
+		out = append(out, c.Process(near[i:i+chunk])...)
+	}
+	return out
+}
+
+func TestFFT_RoundTrip(t *testing.T) {
+	f := newFFT(16)
+	a := make([]complex128, 16)
+	a[1] = 1
+	f.forward(a)
+	for k, v := range a {
+		want := cmplx.Exp(complex(0, -2*math.Pi*float64(k)/16))
+		if cmplx.Abs(v-want) > 1e-9 {
+			t.Fatalf("bin %d = %v, want %v", k, v, want)
+		}
+	}
+	f.inverse(a)
+	for i, v := range a {
+		want := 0.0
+		if i == 1 {
+			want = 1
+		}
+		if cmplx.Abs(v-complex(want, 0)) > 1e-9 {
+			t.Fatalf("sample %d = %v after round trip", i, v)
+		}
+	}
+}
+
+func TestCanceller_CancelsEcho(t *testing.T) {
+	c := New(16000, Config{})
+	far := noise(32000, 0.3, 1)
+	near := room(far, 40)
+
+	out := run(c, far, near, 320)
+	last := len(out) - 3200
+	if in, res := energy(near[last-256:len(near)-256]), energy(out[last:]); res > in/1000 {
+		t.Errorf("residual echo %.1fdB below the input, want at least 30dB", 10*math.Log10(in/res))
+	}
+}
+
+func TestCanceller_KeepsDoubleTalk(t *testing.T) {
+	c := New(16000, Config{})
+	far := noise(48000, 0.3, 1)
+	echo := room(far, 40)
+	speech := noise(16000, 0.2, 2)
+
+	// Converge on echo alone, then the near end talks over it.
+	near := append([]float64(nil), echo...)
+	for i, s := range speech {
+		near[32000+i] += s
+	}
+	out := run(c, far, near, 320)
+
+	// The output lags by one block.
+	var diff, want float64
+	for i := 0; i < len(speech)-256; i++ {
+		d := out[32000+i+256] - speech[i]
+		diff += d * d
+		want += speech[i] * speech[i]
+	}
+	if diff > want/100 {
+		t.Errorf("near-end speech distorted: error %.1fdB below it, want at least 20dB", 10*math.Log10(want/diff))
+	}
+}
+
+func TestCanceller_PassesNearEndWithoutPlayback(t *testing.T) {
+	c := New(16000, Config{BlockSize: 100})
+	near := noise(1000, 0.3, 3)
+	out := c.Process(near)
+	if c.n != 128 {
+		t.Errorf("expected the block size to round up to 128, got %d", c.n)
+	}
+	for i := 128; i < len(near); i++ {
+		if math.Abs(out[i]-near[i-128]) > 1e-9 {
+			t.Fatalf("sample %d changed without playback", i)
+		}
+	}
+}
+
+func TestCanceller_Delay(t *testing.T) {
+	// 100ms of playback latency, beyond the filter's 128ms tail only with
+	// Delay set.
+	c := New(16000, Config{Partitions: 4, Delay: 100 * time.Millisecond})
+	far := noise(32000, 0.3, 1)
+	near := room(far, 1600)
+
+	out := run(c, far, near, 320)
+	last := len(out) - 3200
+	if in, res := energy(near[last-256:len(near)-256]), energy(out[last:]); res > in/1000 {
+		t.Errorf("residual echo %.1fdB below the input, want at least 30dB", 10*math.Log10(in/res))
+	}
+}
@@ -0,0 +1,62 @@
+package aec
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fft is an in-place radix-2 transform of a fixed power-of-two size.
+type fft struct {
+	n       int
+	twiddle []complex128 // e^(-2πik/n) for k < n/2
+	rev     []int
+}
+
+func newFFT(n int) *fft {
+	f := &fft{n: n, twiddle: make([]complex128, n/2), rev: make([]int, n)}
+	for k := range f.twiddle {
+		f.twiddle[k] = cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(n)))
+	}
+	shift := bits.UintSize - bits.Len(uint(n-1))
+	for i := range f.rev {
+		f.rev[i] = int(bits.Reverse(uint(i)) >> shift)
+	}
+	return f
+}
+
+// forward transforms a in place.
+func (f *fft) forward(a []complex128) {
+	f.transform(a, false)
+}
+
+// inverse transforms a back in place, scaled by 1/n.
+func (f *fft) inverse(a []complex128) {
+	f.transform(a, true)
+	scale := complex(1/float64(f.n), 0)
+	for i := range a {
+		a[i] *= scale
+	}
+}
+
+func (f *fft) transform(a []complex128, inverse bool) {
+	for i, j := range f.rev {
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for size := 2; size <= f.n; size <<= 1 {
+		half, step := size/2, f.n/size
+		for start := 0; start < f.n; start += size {
+			for k := 0; k < half; k++ {
+				w := f.twiddle[k*step]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				t := w * a[start+k+half]
+				a[start+k+half] = a[start+k] - t
+				a[start+k] += t
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package agc evens out the level of 16-bit mono PCM from a microphone, so
+// quiet or distant speakers reach a usable level and loud ones don't clip.
+package agc
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultTarget    = 0.1 // -20 dBFS
+	defaultMaxGain   = 10  // +20 dB
+	defaultMinGain   = 0.1
+	defaultNoiseGate = 0.003
+	defaultAttack    = 10 * time.Millisecond
+	defaultRelease   = 500 * time.Millisecond
+	defaultWindow    = 20 * time.Millisecond
+
+	// ceiling is the peak output the limiter allows.
+	ceiling = 0.98 * 32767
+)
+
+// Config tunes an AGC. Levels are RMS relative to full scale; zero values
+// take the defaults.
+type Config struct {
+	// Target is the level speech is brought to, 0.1 (-20 dBFS) by default.
+	Target float64 `json:"target,omitempty"`
+	// MaxGain and MinGain bound the gain, 10 (+20 dB) and 0.1 by default.
+	MaxGain float64 `json:"max_gain,omitempty"`
+	MinGain float64 `json:"min_gain,omitempty"`
+	// Below NoiseGate (0.003) the gain holds, so pauses and background
+	// noise are not pumped up to speech level.
+	NoiseGate float64 `json:"noise_gate,omitempty"`
+	// Attack is how fast the gain falls when speech gets louder, 10ms by
+	// default; Release how fast it recovers when it gets quieter, 500ms.
+	Attack  time.Duration `json:"attack,omitempty"`
+	Release time.Duration `json:"release,omitempty"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Target <= 0 {
+		c.Target = defaultTarget
+	}
+	if c.MaxGain <= 0 {
+		c.MaxGain = defaultMaxGain
+	}
+	if c.MinGain <= 0 {
+		c.MinGain = defaultMinGain
+	}
+	if c.NoiseGate <= 0 {
+		c.NoiseGate = defaultNoiseGate
+	}
+	if c.Attack <= 0 {
+		c.Attack = defaultAttack
+	}
+	if c.Release <= 0 {
+		c.Release = defaultRelease
+	}
+	return c
+}
+
+// AGC applies a smoothly varying gain to a stream. It keeps state across
+// calls and is not safe for concurrent use.
+type AGC struct {
+	cfg                     Config
+	target, gate            float64 // in sample units
+	window, attack, release float64 // smoothing coefficients per sample
+	power                   float64
+	gain                    float64
+}
+
+func New(sampleRate int, cfg Config) *AGC {
+	cfg = cfg.withDefaults()
+	coef := func(d time.Duration) float64 {
+		return 1 - math.Exp(-1/(d.Seconds()*float64(sampleRate)))
+	}
+	return &AGC{
+		cfg:     cfg,
+		target:  cfg.Target * 32768,
+		gate:    cfg.NoiseGate * 32768,
+		window:  coef(defaultWindow),
+		attack:  coef(cfg.Attack),
+		release: coef(cfg.Release),
+		gain:    1,
+	}
+}
+
+// Gain returns the gain applied to the last sample.
+func (a *AGC) Gain() float64 {
+	return a.gain
+}
+
+// Reset forgets the level heard so far and returns to unity gain.
+func (a *AGC) Reset() {
+	a.power = 0
+	a.gain = 1
+}
+
+// Process returns pcm with the gain applied, adapting it to the level.
+func (a *AGC) Process(pcm []byte) []byte {
+	return a.process(pcm, true)
+}
+
+// Apply returns pcm with the current gain applied, without adapting it to
+// pcm's level. Use it for audio that is not the speaker's, such as echo of
+// the bot, so it doesn't move the gain.
+func (a *AGC) Apply(pcm []byte) []byte {
+	return a.process(pcm, false)
+}
+
+func (a *AGC) process(pcm []byte, adapt bool) []byte {
+	out := make([]byte, len(pcm))
+	for i := 0; i+1 < len(pcm); i += 2 {
+		x := float64(int16(uint16(pcm[i]) | uint16(pcm[i+1])<<8))
+		if adapt {
+			a.power += a.window * (x*x - a.power)
+			if level := math.Sqrt(a.power); level >= a.gate {
+				want := min(max(a.target/level, a.cfg.MinGain), a.cfg.MaxGain)
+				if want < a.gain {
+					a.gain += a.attack * (want - a.gain)
+				} else {
+					a.gain += a.release * (want - a.gain)
+				}
+			}
+		}
+		// Limit peaks the smoothed gain is too slow for.
+		if peak := math.Abs(x) * a.gain; peak > ceiling {
+			a.gain = ceiling / math.Abs(x)
+		}
+		s := int16(math.Round(x * a.gain))
+		out[i] = byte(s)
+		out[i+1] = byte(s >> 8)
+	}
+	return out
+}
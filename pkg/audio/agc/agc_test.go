@@ -0,0 +1,89 @@
+package agc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func sine(amplitude float64, rate int, d time.Duration) []byte {
+	n := int(d.Seconds() * float64(rate))
+	pcm := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		s := int16(amplitude * math.Sin(2*math.Pi*300*float64(i)/float64(rate)))
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(s >> 8)
+	}
+	return pcm
+}
+
+// stats returns the RMS and peak of pcm relative to full scale.
+func stats(pcm []byte) (rms, peak float64) {
+	var sum float64
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := float64(int16(uint16(pcm[i])|uint16(pcm[i+1])<<8)) / 32768
+		sum += v * v
+		peak = max(peak, math.Abs(v))
+	}
+	return math.Sqrt(sum / float64(len(pcm)/2)), peak
+}
+
+// process feeds pcm in 20ms chunks and returns the last 200ms of output.
+func process(a *AGC, pcm []byte, rate int) []byte {
+	var out []byte
+	chunk := rate / 50 * 2
+	for i := 0; i < len(pcm); i += chunk {
+		out = append(out, a.Process(pcm[i:min(i+chunk, len(pcm))])...)
+	}
+	return out[len(out)-rate/5*2:]
+}
+
+func TestAGC_BoostsQuietSpeech(t *testing.T) {
+	a := New(16000, Config{})
+	quiet := sine(600, 16000, 3*time.Second) // RMS ~0.013
+	if rms, _ := stats(process(a, quiet, 16000)); rms < 0.08 || rms > 0.12 {
+		t.Errorf("quiet speech came out at RMS %.3f, expected about 0.1", rms)
+	}
+
+	a = New(16000, Config{MaxGain: 2})
+	if rms, _ := stats(process(a, quiet, 16000)); rms > 0.03 {
+		t.Errorf("MaxGain 2 let quiet speech reach RMS %.3f", rms)
+	}
+}
+
+func TestAGC_TamesLoudSpeech(t *testing.T) {
+	a := New(16000, Config{})
+	loud := sine(32000, 16000, time.Second)
+	var peak float64
+	for i := 0; i < len(loud); i += 640 {
+		_, p := stats(a.Process(loud[i : i+640]))
+		peak = max(peak, p)
+	}
+	if peak > 0.99 {
+		t.Errorf("loud speech clipped, peak %.3f", peak)
+	}
+	if rms, _ := stats(a.Process(loud[:3200])); rms > 0.12 {
+		t.Errorf("loud speech came out at RMS %.3f, expected about 0.1", rms)
+	}
+
+	// The gain recovers at the release rate, much slower than it fell.
+	a.Process(sine(3200, 16000, 50*time.Millisecond))
+	if a.Gain() > 0.5 {
+		t.Errorf("gain recovered to %.2f within 50ms", a.Gain())
+	}
+}
+
+func TestAGC_HoldsThroughNoise(t *testing.T) {
+	a := New(16000, Config{})
+	a.Process(sine(20, 16000, time.Second)) // RMS ~0.0004, under the gate
+	if a.Gain() != 1 {
+		t.Errorf("background noise moved the gain to %.2f", a.Gain())
+	}
+
+	process(a, sine(600, 16000, 2*time.Second), 16000)
+	g := a.Gain()
+	a.Apply(sine(2000, 16000, time.Second))
+	if a.Gain() != g {
+		t.Errorf("Apply adapted the gain from %.2f to %.2f", g, a.Gain())
+	}
+}
@@ -0,0 +1,58 @@
+package audio
+
+// AlawDecode converts G.711 A-law bytes to 16-bit little-endian PCM.
+func AlawDecode(alaw []byte) []byte {
+	pcm := make([]byte, len(alaw)*2)
+	for i, b := range alaw {
+		s := alawToLinear(b)
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(s >> 8)
+	}
+	return pcm
+}
+
+// AlawEncode converts 16-bit little-endian PCM to G.711 A-law bytes.
+func AlawEncode(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		s := int16(pcm[2*i]) | int16(pcm[2*i+1])<<8
+		out[i] = linearToAlaw(s)
+	}
+	return out
+}
+
+func alawToLinear(b byte) int16 {
+	b ^= 0x55
+	sample := int32(b&0x0F)<<4 + 8
+	if exponent := (b >> 4) & 0x07; exponent > 0 {
+		sample = (sample + 0x100) << (exponent - 1)
+	}
+	if b&0x80 == 0 {
+		return int16(-sample)
+	}
+	return int16(sample)
+}
+
+func linearToAlaw(s int16) byte {
+	sample := int32(s)
+	sign := byte(0x80)
+	if sample < 0 {
+		sample = -sample - 1
+		sign = 0
+	}
+	if sample > 32767 {
+		sample = 32767
+	}
+
+	var out byte
+	if sample < 256 {
+		out = byte(sample >> 4)
+	} else {
+		exponent := byte(1)
+		for v := sample >> 8; v > 1; v >>= 1 {
+			exponent++
+		}
+		out = exponent<<4 | byte(sample>>(exponent+3))&0x0F
+	}
+	return (out | sign) ^ 0x55
+}
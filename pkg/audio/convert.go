@@ -0,0 +1,64 @@
+package audio
+
+import "github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+
+// Converter converts a stream of 16-bit PCM between sample rates and channel
+// counts. Chunks may end mid-frame: the partial frame is held for the next
+// call. Channels are averaged down to mono before resampling, and mono is
+// copied to every output channel. Resampling holds back a millisecond or two
+// of audio until Flush.
+type Converter struct {
+	inChannels  int
+	outChannels int
+	rs          *resample.Resampler
+	carry       []byte
+}
+
+func NewConverter(inRate, inChannels, outRate, outChannels int) *Converter {
+	return &Converter{
+		inChannels:  max(inChannels, 1),
+		outChannels: max(outChannels, 1),
+		rs:          resample.NewResampler(inRate, outRate),
+	}
+}
+
+func (c *Converter) Process(pcm []byte) []byte {
+	if len(c.carry) > 0 {
+		pcm = append(c.carry, pcm...)
+		c.carry = nil
+	}
+	frame := 2 * c.inChannels
+	if rest := len(pcm) % frame; rest > 0 {
+		c.carry = append([]byte(nil), pcm[len(pcm)-rest:]...)
+		pcm = pcm[:len(pcm)-rest]
+	}
+	if len(pcm) == 0 {
+		return nil
+	}
+	return c.upmix(c.rs.Process(downmix(pcm, c.inChannels)))
+}
+
+// Flush returns the audio the resampler still holds at the end of a stream.
+func (c *Converter) Flush() []byte {
+	c.carry = nil
+	return c.upmix(c.rs.Flush())
+}
+
+func (c *Converter) upmix(mono []byte) []byte {
+	if c.outChannels == 1 || len(mono) == 0 {
+		return mono
+	}
+	out := make([]byte, 0, len(mono)*c.outChannels)
+	for i := 0; i+1 < len(mono); i += 2 {
+		for ch := 0; ch < c.outChannels; ch++ {
+			out = append(out, mono[i], mono[i+1])
+		}
+	}
+	return out
+}
+
+// Convert converts a complete 16-bit PCM buffer.
+func Convert(pcm []byte, inRate, inChannels, outRate, outChannels int) []byte {
+	c := NewConverter(inRate, inChannels, outRate, outChannels)
+	return append(c.Process(pcm), c.Flush()...)
+}
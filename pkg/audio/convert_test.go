@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+)
+
+func sine(freq float64, rate, n int) []byte {
+	pcm := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		s := int16(10000 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(s >> 8)
+	}
+	return pcm
+}
+
+func TestConverterChunkedMatchesWhole(t *testing.T) {
+	mono := sine(440, 24000, 2400)
+	stereo := make([]byte, 0, len(mono)*2)
+	for i := 0; i < len(mono); i += 2 {
+		stereo = append(stereo, mono[i], mono[i+1], mono[i], mono[i+1])
+	}
+	whole := Convert(stereo, 24000, 2, 16000, 1)
+	if !bytes.Equal(whole, resample.Resample(mono, 24000, 16000)) {
+		t.Error("stereo input was not downmixed to the mono conversion")
+	}
+
+	// Chunks that split frames and samples.
+	c := NewConverter(24000, 2, 16000, 1)
+	var chunked []byte
+	for i, n := 0, 0; i < len(stereo); i += n {
+		n = min(len(stereo)-i, 7+i%5)
+		chunked = append(chunked, c.Process(stereo[i:i+n])...)
+	}
+	chunked = append(chunked, c.Flush()...)
+	if !bytes.Equal(chunked, whole) {
+		t.Errorf("chunked conversion gave %d bytes that differ from the whole %d", len(chunked), len(whole))
+	}
+}
+
+func TestConverterUpmix(t *testing.T) {
+	out := Convert([]byte{1, 2, 3, 4}, 8000, 1, 8000, 2)
+	if want := []byte{1, 2, 1, 2, 3, 4, 3, 4}; !bytes.Equal(out, want) {
+		t.Errorf("got %v, want %v", out, want)
+	}
+	if out := Convert([]byte{1}, 8000, 1, 16000, 1); len(out) != 0 {
+		t.Errorf("a partial sample gave %v", out)
+	}
+}
@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedAudio is returned for compressed audio there is no decoder
+// for, such as Ogg Vorbis.
+var ErrUnsupportedAudio = errors.New("unsupported audio encoding")
+
+// StreamDecoder decodes compressed audio, such as a TTS response, written
+// in chunks of any size.
+type StreamDecoder interface {
+	// Write returns the 16-bit PCM decoded from what was written so far.
+	Write(data []byte) ([]byte, error)
+	// Format returns the PCM's sample rate and channels, once Write has
+	// returned some.
+	Format() (sampleRate, channels int)
+}
+
+// NewStreamDecoder returns a decoder for "mp3" or "ogg" (Ogg Opus), also
+// accepted as MIME types. MP3 needs DefaultMP3Codec and Ogg Opus
+// DefaultOpusCodec.
+func NewStreamDecoder(codec string) (StreamDecoder, error) {
+	switch strings.ToLower(strings.TrimSpace(codec)) {
+	case "mp3", "mpeg", "audio/mpeg", "audio/mp3":
+		if DefaultMP3Codec == nil {
+			return nil, ErrNoMP3Codec
+		}
+		return NewMP3Decoder(DefaultMP3Codec), nil
+	case "ogg", "opus", "audio/ogg", "audio/opus":
+		if DefaultOpusCodec == nil {
+			return nil, ErrNoOpusCodec
+		}
+		return NewOggOpusDecoder(DefaultOpusCodec), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedAudio, codec)
+}
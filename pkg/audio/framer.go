@@ -0,0 +1,45 @@
+package audio
+
+// Framer cuts a stream of PCM into fixed-size frames, carrying the remainder
+// over to the next call. Codecs like Opus only accept whole frames.
+type Framer struct {
+	size int
+	buf  []byte
+}
+
+func NewFramer(frameBytes int) *Framer {
+	return &Framer{size: frameBytes}
+}
+
+// Push appends pcm and returns every complete frame now available.
+func (f *Framer) Push(pcm []byte) [][]byte {
+	f.buf = append(f.buf, pcm...)
+	var frames [][]byte
+	for len(f.buf) >= f.size {
+		frame := make([]byte, f.size)
+		copy(frame, f.buf)
+		frames = append(frames, frame)
+		f.buf = f.buf[f.size:]
+	}
+	return frames
+}
+
+// Flush returns the pending remainder padded with silence to a full frame,
+// or nil when nothing is pending.
+func (f *Framer) Flush() []byte {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	frame := make([]byte, f.size)
+	copy(frame, f.buf)
+	f.buf = nil
+	return frame
+}
+
+func (f *Framer) Pending() int {
+	return len(f.buf)
+}
+
+func (f *Framer) Reset() {
+	f.buf = nil
+}
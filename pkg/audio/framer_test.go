@@ -0,0 +1,26 @@
+package audio
+
+import "testing"
+
+func TestFramer(t *testing.T) {
+	f := NewFramer(4)
+
+	if frames := f.Push([]byte{1, 2, 3}); len(frames) != 0 {
+		t.Fatalf("expected no complete frame, got %v", frames)
+	}
+	frames := f.Push([]byte{4, 5, 6, 7, 8, 9})
+	if len(frames) != 2 || frames[0][0] != 1 || frames[1][3] != 8 {
+		t.Fatalf("unexpected frames %v", frames)
+	}
+	if f.Pending() != 1 {
+		t.Errorf("expected 1 pending byte, got %d", f.Pending())
+	}
+
+	last := f.Flush()
+	if len(last) != 4 || last[0] != 9 || last[1] != 0 {
+		t.Errorf("expected remainder padded with silence, got %v", last)
+	}
+	if f.Flush() != nil {
+		t.Error("expected nothing pending after flush")
+	}
+}
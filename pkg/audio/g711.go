@@ -0,0 +1,60 @@
+package audio
+
+import "fmt"
+
+// G711SampleRate is the rate of G.711 audio, the native format of the
+// phone network.
+const G711SampleRate = 8000
+
+// Encoding names how a transport carries audio on the wire.
+type Encoding string
+
+const (
+	EncodingPCM   Encoding = "pcm" // 16-bit little-endian
+	EncodingMulaw Encoding = "mulaw"
+	EncodingAlaw  Encoding = "alaw"
+)
+
+// ParseEncoding accepts the encoding names and their common aliases, such
+// as "pcmu" and "audio/x-mulaw". An empty name is PCM.
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "", "pcm", "linear16", "s16le":
+		return EncodingPCM, nil
+	case "mulaw", "ulaw", "pcmu", "audio/x-mulaw":
+		return EncodingMulaw, nil
+	case "alaw", "pcma", "audio/x-alaw":
+		return EncodingAlaw, nil
+	}
+	return "", fmt.Errorf("unknown audio encoding %q", name)
+}
+
+// Encode converts 16-bit PCM to the encoding.
+func (e Encoding) Encode(pcm []byte) []byte {
+	switch e {
+	case EncodingMulaw:
+		return MulawEncode(pcm)
+	case EncodingAlaw:
+		return AlawEncode(pcm)
+	}
+	return pcm
+}
+
+// Decode converts audio in the encoding to 16-bit PCM.
+func (e Encoding) Decode(data []byte) []byte {
+	switch e {
+	case EncodingMulaw:
+		return MulawDecode(data)
+	case EncodingAlaw:
+		return AlawDecode(data)
+	}
+	return data
+}
+
+// SampleRate returns the rate the encoding fixes, or 0 if it has none.
+func (e Encoding) SampleRate() int {
+	if e == EncodingMulaw || e == EncodingAlaw {
+		return G711SampleRate
+	}
+	return 0
+}
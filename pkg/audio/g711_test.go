@@ -0,0 +1,21 @@
+package audio
+
+import "testing"
+
+func TestEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{"": EncodingPCM, "pcmu": EncodingMulaw, "audio/x-mulaw": EncodingMulaw, "PCMA": "", "alaw": EncodingAlaw} {
+		got, err := ParseEncoding(name)
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("%q: got %q, %v", name, got, err)
+		}
+	}
+	pcm := []byte{0, 0, 0xe8, 0x03}
+	for _, e := range []Encoding{EncodingMulaw, EncodingAlaw} {
+		if enc := e.Encode(pcm); len(enc) != 2 || len(e.Decode(enc)) != 4 || e.SampleRate() != 8000 {
+			t.Errorf("%s: unexpected round trip of %v", e, enc)
+		}
+	}
+	if out := EncodingPCM.Encode(pcm); &out[0] != &pcm[0] || EncodingPCM.SampleRate() != 0 {
+		t.Error("PCM should pass through")
+	}
+}
@@ -0,0 +1,18 @@
+package audio
+
+// ApplyGain returns a copy of 16-bit little-endian PCM scaled by gain.
+func ApplyGain(pcm []byte, gain float64) []byte {
+	out := make([]byte, len(pcm))
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := float64(int16(uint16(pcm[i])|uint16(pcm[i+1])<<8)) * gain
+		if v > 32767 {
+			v = 32767
+		} else if v < -32768 {
+			v = -32768
+		}
+		s := int16(v)
+		out[i] = byte(s)
+		out[i+1] = byte(s >> 8)
+	}
+	return out
+}
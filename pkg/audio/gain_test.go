@@ -0,0 +1,20 @@
+package audio
+
+import "testing"
+
+func TestApplyGain(t *testing.T) {
+	pcm := []byte{0x00, 0x40, 0x00, 0xc0} // 16384, -16384
+	sample := func(b []byte, i int) int16 { return int16(uint16(b[2*i]) | uint16(b[2*i+1])<<8) }
+
+	got := ApplyGain(pcm, 0.5)
+	if sample(got, 0) != 8192 || sample(got, 1) != -8192 {
+		t.Errorf("expected +/-8192, got %d %d", sample(got, 0), sample(got, 1))
+	}
+	if sample(pcm, 0) != 16384 {
+		t.Error("input must not be modified")
+	}
+	got = ApplyGain(pcm, 4)
+	if sample(got, 0) != 32767 || sample(got, 1) != -32768 {
+		t.Errorf("expected clipping, got %d %d", sample(got, 0), sample(got, 1))
+	}
+}
@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrNoMP3Codec is returned when MP3 is needed and the binary was built
+// without the "mp3" tag.
+var ErrNoMP3Codec = errors.New("no mp3 codec available: build with -tags mp3 or set a codec")
+
+// MP3FrameDecoder decodes one MP3 frame, header included, to 16-bit
+// little-endian PCM. Layer III frames borrow bits from earlier ones, so a
+// decoder is fed one stream's frames in order.
+type MP3FrameDecoder interface {
+	Decode(frame []byte) ([]byte, error)
+}
+
+// MP3Codec creates per-stream decoders. Like Opus, MP3 is decoded by a C
+// library, so the default is only set when building with the "mp3" tag,
+// which links libmpg123.
+type MP3Codec interface {
+	NewDecoder(sampleRate, channels int) (MP3FrameDecoder, error)
+}
+
+// DefaultMP3Codec is LibMPG123 when built with -tags mp3, otherwise nil.
+var DefaultMP3Codec MP3Codec
+
+var (
+	mp3SampleRates = [4][3]int{
+		{11025, 12000, 8000},  // MPEG 2.5
+		{},                    // reserved
+		{22050, 24000, 16000}, // MPEG 2
+		{44100, 48000, 32000}, // MPEG 1
+	}
+	// In kbps, by MPEG 1 or 2 and layer I, II or III.
+	mp3Bitrates = [2][3][15]int{
+		{
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		},
+		{
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		},
+	}
+)
+
+// mp3Frame is what a frame header says about the frame.
+type mp3Frame struct {
+	size       int
+	sampleRate int
+	channels   int
+}
+
+// parseMP3Header parses the 4-byte header at the start of b. Free-format
+// frames, whose size isn't in the header, are not supported.
+func parseMP3Header(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := int(b[1]>>3) & 3
+	layer := 4 - int(b[1]>>1)&3 // 1, 2 or 3; 4 is reserved
+	bitrateIndex := int(b[2] >> 4)
+	rateIndex := int(b[2]>>2) & 3
+	if version == 1 || layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+	mpeg1 := version == 3
+	table := 1
+	if mpeg1 {
+		table = 0
+	}
+	bitrate := mp3Bitrates[table][layer-1][bitrateIndex] * 1000
+	rate := mp3SampleRates[version][rateIndex]
+	padding := int(b[2]>>1) & 1
+
+	var size int
+	switch {
+	case layer == 1:
+		size = (12*bitrate/rate + padding) * 4
+	case layer == 3 && !mpeg1:
+		size = 72*bitrate/rate + padding
+	default:
+		size = 144*bitrate/rate + padding
+	}
+	channels := 2
+	if b[3]>>6 == 3 {
+		channels = 1
+	}
+	return mp3Frame{size: size, sampleRate: rate, channels: channels}, true
+}
+
+// MP3Decoder splits an MP3 stream into frames for an MP3Codec, skipping ID3
+// tags and anything else between frames.
+type MP3Decoder struct {
+	codec      MP3Codec
+	dec        MP3FrameDecoder
+	buf        []byte
+	skip       int // left of an ID3 tag
+	sampleRate int
+	channels   int
+}
+
+func NewMP3Decoder(codec MP3Codec) *MP3Decoder {
+	return &MP3Decoder{codec: codec}
+}
+
+func (d *MP3Decoder) Format() (int, int) {
+	return d.sampleRate, d.channels
+}
+
+func (d *MP3Decoder) Write(data []byte) ([]byte, error) {
+	d.buf = append(d.buf, data...)
+	var out []byte
+	for {
+		if d.skip > 0 {
+			n := min(d.skip, len(d.buf))
+			d.buf, d.skip = d.buf[n:], d.skip-n
+			if d.skip > 0 {
+				break
+			}
+		}
+		if n := min(len(d.buf), 3); string(d.buf[:n]) == "ID3"[:n] {
+			if len(d.buf) < 10 {
+				break
+			}
+			// The size is syncsafe: 7 bits per byte. A footer doubles the
+			// header.
+			size := int(d.buf[6])<<21 | int(d.buf[7])<<14 | int(d.buf[8])<<7 | int(d.buf[9])
+			d.skip = 10 + size
+			if d.buf[5]&0x10 != 0 {
+				d.skip += 10
+			}
+			continue
+		}
+		sync := bytes.IndexByte(d.buf, 0xFF)
+		if sync < 0 {
+			d.buf = d.buf[:0]
+			break
+		}
+		d.buf = d.buf[sync:]
+		if len(d.buf) < 4 {
+			break
+		}
+		frame, ok := parseMP3Header(d.buf)
+		if !ok {
+			d.buf = d.buf[1:]
+			continue
+		}
+		if len(d.buf) < frame.size {
+			break
+		}
+		if d.dec == nil {
+			dec, err := d.codec.NewDecoder(frame.sampleRate, frame.channels)
+			if err != nil {
+				return out, err
+			}
+			d.dec, d.sampleRate, d.channels = dec, frame.sampleRate, frame.channels
+		}
+		pcm, err := d.dec.Decode(d.buf[:frame.size])
+		if err != nil {
+			return out, err
+		}
+		out = append(out, pcm...)
+		d.buf = d.buf[frame.size:]
+	}
+	// Keep what is left from pinning the whole stream.
+	d.buf = append([]byte(nil), d.buf...)
+	return out, nil
+}
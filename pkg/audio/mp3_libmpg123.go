@@ -0,0 +1,89 @@
+//go:build mp3 && cgo
+
+package audio
+
+/*
+#cgo pkg-config: libmpg123
+#include <mpg123.h>
+
+// decode hides that mpg123_decode's buffers changed type across versions.
+static int decode(mpg123_handle *mh, const void *in, size_t insize, void *out, size_t outsize, size_t *done) {
+	return mpg123_decode(mh, in, insize, out, outsize, done);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// maxMP3Frame is the most PCM one frame decodes to: 1152 stereo samples.
+const maxMP3Frame = 1152 * 2 * 2
+
+var mpg123Init sync.Once
+
+func init() {
+	DefaultMP3Codec = LibMPG123{}
+}
+
+// LibMPG123 wraps the system libmpg123.
+type LibMPG123 struct{}
+
+func (LibMPG123) NewDecoder(sampleRate, channels int) (MP3FrameDecoder, error) {
+	mpg123Init.Do(func() { C.mpg123_init() })
+	var rc C.int
+	mh := C.mpg123_new(nil, &rc)
+	if mh == nil {
+		return nil, mpg123Error("new", rc)
+	}
+	d := &libmpg123Decoder{mh: mh}
+	runtime.SetFinalizer(d, func(d *libmpg123Decoder) { C.mpg123_delete(d.mh) })
+
+	// Pin the output to 16-bit at the stream's own rate and channels.
+	mode := C.int(C.MPG123_STEREO)
+	if channels == 1 {
+		mode = C.MPG123_MONO
+	}
+	C.mpg123_format_none(mh)
+	if rc := C.mpg123_format(mh, C.long(sampleRate), mode, C.MPG123_ENC_SIGNED_16); rc != C.MPG123_OK {
+		return nil, mpg123Error("format", rc)
+	}
+	if rc := C.mpg123_open_feed(mh); rc != C.MPG123_OK {
+		return nil, mpg123Error("open feed", rc)
+	}
+	return d, nil
+}
+
+type libmpg123Decoder struct {
+	mh  *C.mpg123_handle
+	out [maxMP3Frame]byte
+}
+
+func (d *libmpg123Decoder) Decode(frame []byte) ([]byte, error) {
+	var pcm []byte
+	var in unsafe.Pointer
+	if len(frame) > 0 {
+		in = unsafe.Pointer(&frame[0])
+	}
+	size := C.size_t(len(frame))
+	for {
+		var done C.size_t
+		rc := C.decode(d.mh, in, size, unsafe.Pointer(&d.out[0]), C.size_t(len(d.out)), &done)
+		pcm = append(pcm, d.out[:done]...)
+		in, size = nil, 0
+		switch rc {
+		case C.MPG123_OK, C.MPG123_NEW_FORMAT:
+			continue
+		case C.MPG123_NEED_MORE, C.MPG123_DONE:
+			return pcm, nil
+		}
+		return pcm, mpg123Error("decode", rc)
+	}
+}
+
+func mpg123Error(op string, rc C.int) error {
+	return fmt.Errorf("mpg123 %s: %s", op, C.GoString(C.mpg123_plain_strerror(rc)))
+}
@@ -0,0 +1,49 @@
+//go:build mp3 && cgo
+
+package audio
+
+import "testing"
+
+// silentMP3 returns n MPEG-1 Layer III frames of mono 44.1kHz silence at
+// 128kbps: a header and nothing but zeros, 417 bytes each.
+func silentMP3(n int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	var out []byte
+	for i := 0; i < n; i++ {
+		out = append(out, frame...)
+	}
+	return out
+}
+
+func TestLibMPG123_DecodesStream(t *testing.T) {
+	if _, ok := DefaultMP3Codec.(LibMPG123); !ok {
+		t.Fatalf("expected LibMPG123 as the default codec, got %T", DefaultMP3Codec)
+	}
+	dec, err := NewStreamDecoder("audio/mpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fed in uneven pieces, as it arrives from a TTS provider.
+	data := silentMP3(20)
+	var pcm []byte
+	for off := 0; off < len(data); off += 300 {
+		out, err := dec.Write(data[off:min(off+300, len(data))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcm = append(pcm, out...)
+	}
+	if rate, channels := dec.Format(); rate != 44100 || channels != 1 {
+		t.Errorf("expected 44.1kHz mono, got %dHz, %d channels", rate, channels)
+	}
+	if len(pcm) == 0 || len(pcm)%2 != 0 || len(pcm) > 20*1152*2 {
+		t.Fatalf("expected up to 20 frames of 16-bit samples, got %d bytes", len(pcm))
+	}
+	for i, b := range pcm {
+		if b != 0 {
+			t.Fatalf("expected silence, got %d at byte %d", b, i)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+)
+
+// frameCodec "decodes" each MP3 frame to its first four bytes and records
+// the format it was created for.
+type frameCodec struct {
+	rate, channels int
+	frames         int
+}
+
+func (c *frameCodec) NewDecoder(sampleRate, channels int) (MP3FrameDecoder, error) {
+	c.rate, c.channels = sampleRate, channels
+	return c, nil
+}
+
+func (c *frameCodec) Decode(frame []byte) ([]byte, error) {
+	c.frames++
+	return frame[:4], nil
+}
+
+// mp3Frame128k is a 128kbps MPEG 1 Layer III mono frame at 44.1kHz.
+func mp3Frame128k() []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	return frame
+}
+
+func TestParseMP3Header(t *testing.T) {
+	f, ok := parseMP3Header(mp3Frame128k())
+	if !ok || f != (mp3Frame{size: 417, sampleRate: 44100, channels: 1}) {
+		t.Errorf("MPEG 1 header = %+v, %v", f, ok)
+	}
+	// 64kbps MPEG 2 Layer III stereo at 24kHz, padded.
+	f, ok = parseMP3Header([]byte{0xFF, 0xF3, 0x86, 0x00})
+	if !ok || f != (mp3Frame{size: 193, sampleRate: 24000, channels: 2}) {
+		t.Errorf("MPEG 2 header = %+v, %v", f, ok)
+	}
+	if _, ok := parseMP3Header([]byte{0xFF, 0xFF, 0xFF, 0xFF}); ok {
+		t.Error("expected a bad bitrate to be rejected")
+	}
+}
+
+func TestMP3Decoder(t *testing.T) {
+	var stream []byte
+	stream = append(stream, "ID3\x04\x00\x00\x00\x00\x00\x05tags!"...)
+	stream = append(stream, 0x00, 0xFF, 0x12) // junk before the first frame
+	for range 3 {
+		stream = append(stream, mp3Frame128k()...)
+	}
+
+	codec := &frameCodec{}
+	d := NewMP3Decoder(codec)
+	var pcm []byte
+	for i := 0; i < len(stream); i += 100 {
+		out, err := d.Write(stream[i:min(i+100, len(stream))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcm = append(pcm, out...)
+	}
+	if codec.frames != 3 || !bytes.Equal(pcm, bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0xC0}, 3)) {
+		t.Errorf("decoded %d frames to %v", codec.frames, pcm)
+	}
+	if rate, channels := d.Format(); rate != 44100 || channels != 1 {
+		t.Errorf("format = %d, %d", rate, channels)
+	}
+}
+
+func TestNewStreamDecoder(t *testing.T) {
+	if _, err := NewStreamDecoder("flac"); err == nil {
+		t.Error("expected flac to be unsupported")
+	}
+	if DefaultMP3Codec == nil {
+		if _, err := NewStreamDecoder("audio/mpeg"); err != ErrNoMP3Codec {
+			t.Errorf("expected ErrNoMP3Codec, got %v", err)
+		}
+	}
+}
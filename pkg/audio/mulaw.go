@@ -0,0 +1,60 @@
+package audio
+
+const (
+	mulawBias = 0x84
+	mulawClip = 32635
+)
+
+// MulawDecode converts G.711 mu-law bytes to 16-bit little-endian PCM.
+func MulawDecode(ulaw []byte) []byte {
+	pcm := make([]byte, len(ulaw)*2)
+	for i, b := range ulaw {
+		s := mulawToLinear(b)
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(s >> 8)
+	}
+	return pcm
+}
+
+// MulawEncode converts 16-bit little-endian PCM to G.711 mu-law bytes.
+func MulawEncode(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		s := int16(pcm[2*i]) | int16(pcm[2*i+1])<<8
+		out[i] = linearToMulaw(s)
+	}
+	return out
+}
+
+func mulawToLinear(b byte) int16 {
+	b = ^b
+	sign := b & 0x80
+	exponent := (b >> 4) & 0x07
+	mantissa := b & 0x0F
+	sample := ((int32(mantissa) << 3) + mulawBias) << exponent
+	sample -= mulawBias
+	if sign != 0 {
+		return int16(-sample)
+	}
+	return int16(sample)
+}
+
+func linearToMulaw(s int16) byte {
+	sample := int32(s)
+	sign := byte(0)
+	if sample < 0 {
+		sample = -sample
+		sign = 0x80
+	}
+	if sample > mulawClip {
+		sample = mulawClip
+	}
+	sample += mulawBias
+
+	exponent := byte(7)
+	for mask := int32(0x4000); sample&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := byte(sample>>(exponent+3)) & 0x0F
+	return ^(sign | exponent<<4 | mantissa)
+}
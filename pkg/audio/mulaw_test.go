@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMulawRoundTrip(t *testing.T) {
+	for _, s := range []int16{0, 1, -1, 100, -100, 1000, -1000, 8000, -8000, 32000, -32000, 32767, -32768} {
+		pcm := []byte{byte(s), byte(s >> 8)}
+		decoded := MulawDecode(MulawEncode(pcm))
+		got := int16(decoded[0]) | int16(decoded[1])<<8
+
+		// mu-law keeps roughly 4 significant bits, so allow ~1/16 of the magnitude.
+		tolerance := math.Max(8, math.Abs(float64(s))/16)
+		if math.Abs(float64(got)-float64(s)) > tolerance {
+			t.Errorf("sample %d decoded to %d", s, got)
+		}
+	}
+}
+
+func TestMulawKnownValues(t *testing.T) {
+	if got := MulawEncode([]byte{0, 0}); got[0] != 0xFF {
+		t.Errorf("silence should encode to 0xFF, got %#x", got[0])
+	}
+	if got := MulawDecode([]byte{0xFF}); got[0] != 0 || got[1] != 0 {
+		t.Errorf("0xFF should decode to silence, got %v", got)
+	}
+}
+
+func TestAlawRoundTrip(t *testing.T) {
+	for _, s := range []int16{0, 1, -1, 100, -100, 1000, -1000, 8000, -8000, 32000, -32000, 32767, -32768} {
+		pcm := []byte{byte(s), byte(s >> 8)}
+		decoded := AlawDecode(AlawEncode(pcm))
+		got := int16(decoded[0]) | int16(decoded[1])<<8
+
+		tolerance := math.Max(16, math.Abs(float64(s))/16)
+		if math.Abs(float64(got)-float64(s)) > tolerance {
+			t.Errorf("sample %d decoded to %d", s, got)
+		}
+	}
+}
+
+func TestAlawKnownValues(t *testing.T) {
+	// 0xD5 is the A-law code for the smallest positive level (silence).
+	if got := AlawEncode([]byte{0, 0}); got[0] != 0xD5 {
+		t.Errorf("silence should encode to 0xD5, got %#x", got[0])
+	}
+	decoded := AlawDecode([]byte{0xD5})
+	if s := int16(decoded[0]) | int16(decoded[1])<<8; s != 8 {
+		t.Errorf("0xD5 should decode to 8, got %d", s)
+	}
+}
@@ -0,0 +1,117 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// oggOpusRate is the rate Ogg Opus is always decoded at.
+const oggOpusRate = 48000
+
+// OggOpusDecoder decodes an Ogg Opus stream, as TTS APIs return for "opus",
+// with an OpusCodec. Only the first logical stream is decoded, and only mono
+// or stereo. Ogg Vorbis is refused with ErrUnsupportedAudio.
+type OggOpusDecoder struct {
+	codec    OpusCodec
+	dec      OpusDecoder
+	buf      []byte
+	packet   []byte // continued on the next page
+	serial   uint32
+	started  bool
+	headers  int // OpusHead and OpusTags seen
+	channels int
+	preSkip  int // bytes still to drop from the start
+}
+
+func NewOggOpusDecoder(codec OpusCodec) *OggOpusDecoder {
+	return &OggOpusDecoder{codec: codec}
+}
+
+func (d *OggOpusDecoder) Format() (int, int) {
+	return oggOpusRate, d.channels
+}
+
+func (d *OggOpusDecoder) Write(data []byte) ([]byte, error) {
+	d.buf = append(d.buf, data...)
+	var out []byte
+	for {
+		if len(d.buf) < 27 {
+			break
+		}
+		if string(d.buf[:4]) != "OggS" {
+			return out, fmt.Errorf("%w: not an ogg stream", ErrUnsupportedAudio)
+		}
+		segments := int(d.buf[26])
+		if len(d.buf) < 27+segments {
+			break
+		}
+		lacing := d.buf[27 : 27+segments]
+		size := 27 + segments
+		for _, l := range lacing {
+			size += int(l)
+		}
+		if len(d.buf) < size {
+			break
+		}
+		serial := binary.LittleEndian.Uint32(d.buf[14:])
+		if !d.started {
+			d.serial, d.started = serial, true
+		}
+		if serial == d.serial {
+			body := d.buf[27+segments : size]
+			if d.buf[5]&1 == 0 {
+				// Not a continuation: a packet cut off by a lost page is
+				// dropped.
+				d.packet = d.packet[:0]
+			}
+			for _, l := range lacing {
+				d.packet = append(d.packet, body[:l]...)
+				body = body[l:]
+				if l < 255 {
+					pcm, err := d.handlePacket(d.packet)
+					if err != nil {
+						return out, err
+					}
+					out = append(out, pcm...)
+					d.packet = d.packet[:0]
+				}
+			}
+		}
+		d.buf = d.buf[size:]
+	}
+	d.buf = append([]byte(nil), d.buf...)
+	return out, nil
+}
+
+func (d *OggOpusDecoder) handlePacket(packet []byte) ([]byte, error) {
+	switch d.headers {
+	case 0:
+		if len(packet) < 19 || string(packet[:8]) != "OpusHead" {
+			return nil, fmt.Errorf("%w: ogg stream is not opus", ErrUnsupportedAudio)
+		}
+		channels := int(packet[9])
+		if channels < 1 || channels > 2 {
+			return nil, fmt.Errorf("%w: %d-channel opus", ErrUnsupportedAudio, channels)
+		}
+		dec, err := d.codec.NewDecoder(oggOpusRate, channels)
+		if err != nil {
+			return nil, err
+		}
+		d.dec, d.channels = dec, channels
+		d.preSkip = int(binary.LittleEndian.Uint16(packet[10:])) * channels * 2
+		d.headers++
+		return nil, nil
+	case 1:
+		d.headers++ // OpusTags
+		return nil, nil
+	}
+	pcm, err := d.dec.Decode(packet)
+	if err != nil {
+		return nil, err
+	}
+	if d.preSkip > 0 {
+		n := min(d.preSkip, len(pcm))
+		pcm, d.preSkip = pcm[n:], d.preSkip-n
+	}
+	return pcm, nil
+}
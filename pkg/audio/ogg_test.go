@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+type passthroughOpus struct{ channels int }
+
+func (c *passthroughOpus) NewEncoder(sampleRate, channels int) (OpusEncoder, error) {
+	return nil, errors.New("not needed")
+}
+
+func (c *passthroughOpus) NewDecoder(sampleRate, channels int) (OpusDecoder, error) {
+	c.channels = channels
+	return c, nil
+}
+
+func (c *passthroughOpus) Decode(packet []byte) ([]byte, error) {
+	return bytes.Clone(packet), nil
+}
+
+// oggPage builds a page from lacing values and the body they describe. The
+// CRC is left zero; it isn't checked.
+func oggPage(serial uint32, flags byte, lacing []byte, body []byte) []byte {
+	page := make([]byte, 27, 27+len(lacing)+len(body))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint32(page[14:], serial)
+	page[26] = byte(len(lacing))
+	return append(append(page, lacing...), body...)
+}
+
+func opusHead(channels byte, preSkip uint16) []byte {
+	head := []byte("OpusHead\x01")
+	head = append(head, channels)
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, 24000)
+	return append(head, 0, 0, 0)
+}
+
+func TestOggOpusDecoder(t *testing.T) {
+	head := opusHead(1, 2)
+	long := bytes.Repeat([]byte{7}, 300) // spans two pages
+	short := []byte{1, 2, 3, 4, 5, 6}
+
+	var stream []byte
+	stream = append(stream, oggPage(1, 0x02, []byte{byte(len(head))}, head)...)
+	stream = append(stream, oggPage(1, 0, []byte{8}, []byte("OpusTags"))...)
+	stream = append(stream, oggPage(9, 0x02, []byte{4}, []byte("junk"))...) // another stream
+	stream = append(stream, oggPage(1, 0, []byte{6, 255}, append(append([]byte(nil), short...), long[:255]...))...)
+	stream = append(stream, oggPage(1, 0x01, []byte{45}, long[255:])...)
+
+	codec := &passthroughOpus{}
+	d := NewOggOpusDecoder(codec)
+	var pcm []byte
+	for i := 0; i < len(stream); i += 50 {
+		out, err := d.Write(stream[i:min(i+50, len(stream))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcm = append(pcm, out...)
+	}
+
+	// The pre-skip drops two samples.
+	want := append(append([]byte(nil), short[4:]...), long...)
+	if !bytes.Equal(pcm, want) {
+		t.Errorf("decoded %d bytes, expected %d", len(pcm), len(want))
+	}
+	if rate, channels := d.Format(); rate != 48000 || channels != 1 || codec.channels != 1 {
+		t.Errorf("format = %d, %d", rate, channels)
+	}
+}
+
+func TestOggOpusDecoder_Vorbis(t *testing.T) {
+	d := NewOggOpusDecoder(&passthroughOpus{})
+	ident := []byte("\x01vorbis\x00\x00\x00\x00\x01\x80\xbb\x00\x00")
+	_, err := d.Write(oggPage(1, 0x02, []byte{byte(len(ident))}, ident))
+	if !errors.Is(err, ErrUnsupportedAudio) {
+		t.Errorf("expected ErrUnsupportedAudio for vorbis, got %v", err)
+	}
+}
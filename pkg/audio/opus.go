@@ -0,0 +1,29 @@
+package audio
+
+import "errors"
+
+// ErrNoOpusCodec is returned by transports that need Opus when no codec was
+// configured and the binary was built without the "opus" tag.
+var ErrNoOpusCodec = errors.New("no opus codec available: build with -tags opus or set a codec")
+
+// OpusEncoder encodes one frame of 16-bit little-endian PCM. Frames must be
+// 2.5, 5, 10, 20, 40 or 60ms long at the encoder's sample rate.
+type OpusEncoder interface {
+	Encode(pcm []byte) ([]byte, error)
+}
+
+// OpusDecoder decodes one Opus packet to 16-bit little-endian PCM.
+type OpusDecoder interface {
+	Decode(packet []byte) ([]byte, error)
+}
+
+// OpusCodec creates per-stream encoders and decoders. There is no pure Go
+// Opus implementation, so the default is only set when building with the
+// "opus" tag, which links libopus.
+type OpusCodec interface {
+	NewEncoder(sampleRate, channels int) (OpusEncoder, error)
+	NewDecoder(sampleRate, channels int) (OpusDecoder, error)
+}
+
+// DefaultOpusCodec is LibOpus when built with -tags opus, otherwise nil.
+var DefaultOpusCodec OpusCodec
@@ -0,0 +1,120 @@
+// Package opus streams 16-bit mono PCM at any sample rate through an
+// audio.OpusCodec, so transports can carry compressed audio to and from a
+// ManagedStream. Audio is resampled to the nearest rate Opus supports and
+// cut into 20ms frames.
+package opus
+
+import (
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+)
+
+const FrameDuration = 20 * time.Millisecond
+
+// codecRate returns rate if Opus supports it, and otherwise the next
+// supported rate up, so resampling never drops bandwidth.
+func codecRate(rate int) int {
+	for _, r := range []int{8000, 12000, 16000, 24000, 48000} {
+		if rate <= r {
+			return r
+		}
+	}
+	return 48000
+}
+
+// Encoder encodes a stream of PCM into 20ms Opus packets.
+type Encoder struct {
+	enc    audio.OpusEncoder
+	rs     *resample.Resampler
+	framer *audio.Framer
+}
+
+// NewEncoder returns an encoder for PCM at sampleRate. codec defaults to
+// audio.DefaultOpusCodec.
+func NewEncoder(codec audio.OpusCodec, sampleRate int) (*Encoder, error) {
+	if codec == nil {
+		codec = audio.DefaultOpusCodec
+	}
+	if codec == nil {
+		return nil, audio.ErrNoOpusCodec
+	}
+	rate := codecRate(sampleRate)
+	enc, err := codec.NewEncoder(rate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{
+		enc:    enc,
+		rs:     resample.NewResampler(sampleRate, rate),
+		framer: audio.NewFramer(rate / 50 * 2),
+	}, nil
+}
+
+// Encode returns a packet for every whole frame now available. The rest is
+// held for the next call or Flush.
+func (e *Encoder) Encode(pcm []byte) ([][]byte, error) {
+	return e.encode(e.framer.Push(e.rs.Process(pcm)))
+}
+
+// Flush encodes what is held back, padded with silence to a whole frame.
+// It is called at the end of each utterance.
+func (e *Encoder) Flush() ([][]byte, error) {
+	frames := e.framer.Push(e.rs.Flush())
+	if last := e.framer.Flush(); last != nil {
+		frames = append(frames, last)
+	}
+	return e.encode(frames)
+}
+
+// Reset drops what is held back, e.g. on barge-in.
+func (e *Encoder) Reset() {
+	e.rs.Reset()
+	e.framer.Reset()
+}
+
+func (e *Encoder) encode(frames [][]byte) ([][]byte, error) {
+	packets := make([][]byte, 0, len(frames))
+	for _, frame := range frames {
+		packet, err := e.enc.Encode(frame)
+		if err != nil {
+			return packets, err
+		}
+		// Encoders may reuse their output buffer.
+		packets = append(packets, append([]byte(nil), packet...))
+	}
+	return packets, nil
+}
+
+// Decoder decodes Opus packets to PCM at a given rate.
+type Decoder struct {
+	dec audio.OpusDecoder
+	rs  *resample.Resampler
+}
+
+// NewDecoder returns a decoder producing PCM at sampleRate. codec defaults
+// to audio.DefaultOpusCodec.
+func NewDecoder(codec audio.OpusCodec, sampleRate int) (*Decoder, error) {
+	if codec == nil {
+		codec = audio.DefaultOpusCodec
+	}
+	if codec == nil {
+		return nil, audio.ErrNoOpusCodec
+	}
+	rate := codecRate(sampleRate)
+	dec, err := codec.NewDecoder(rate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{dec: dec, rs: resample.NewResampler(rate, sampleRate)}, nil
+}
+
+// Decode decodes one packet.
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	pcm, err := d.dec.Decode(packet)
+	if err != nil {
+		return nil, err
+	}
+	return d.rs.Process(pcm), nil
+}
@@ -0,0 +1,103 @@
+package opus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// passthroughCodec "encodes" PCM as itself and records the codec rate.
+type passthroughCodec struct{ rate *int }
+
+func (c passthroughCodec) NewEncoder(sampleRate, channels int) (audio.OpusEncoder, error) {
+	*c.rate = sampleRate
+	return passthrough{}, nil
+}
+
+func (c passthroughCodec) NewDecoder(sampleRate, channels int) (audio.OpusDecoder, error) {
+	*c.rate = sampleRate
+	return passthrough{}, nil
+}
+
+type passthrough struct{}
+
+func (passthrough) Encode(pcm []byte) ([]byte, error)    { return pcm, nil }
+func (passthrough) Decode(packet []byte) ([]byte, error) { return packet, nil }
+
+func TestEncoder_FramesAndFlushes(t *testing.T) {
+	var rate int
+	enc, err := NewEncoder(passthroughCodec{&rate}, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 16000 {
+		t.Errorf("expected a 16kHz encoder, got %d", rate)
+	}
+	// 50ms: two frames now, the rest on Flush.
+	packets, _ := enc.Encode(make([]byte, 1600))
+	if len(packets) != 2 || len(packets[0]) != 640 {
+		t.Fatalf("expected two 20ms packets, got %d", len(packets))
+	}
+	if packets, _ = enc.Flush(); len(packets) != 1 || len(packets[0]) != 640 {
+		t.Errorf("expected one padded packet on flush, got %d", len(packets))
+	}
+	if packets, _ = enc.Flush(); len(packets) != 0 {
+		t.Errorf("a second flush gave %d packets", len(packets))
+	}
+
+	enc.Encode(make([]byte, 100))
+	enc.Reset()
+	if packets, _ = enc.Flush(); len(packets) != 0 {
+		t.Errorf("audio survived Reset: %d packets", len(packets))
+	}
+}
+
+func TestEncoder_Resamples(t *testing.T) {
+	var rate int
+	enc, _ := NewEncoder(passthroughCodec{&rate}, 44100)
+	if rate != 48000 {
+		t.Fatalf("44.1kHz should be encoded at 48kHz, got %d", rate)
+	}
+	packets, _ := enc.Encode(make([]byte, 44100*2))
+	flushed, _ := enc.Flush()
+	if n := len(packets) + len(flushed); n != 50 {
+		t.Errorf("expected 50 packets for a second, got %d", n)
+	}
+	for _, p := range append(packets, flushed...) {
+		if len(p) != 1920 {
+			t.Fatalf("got a %d-byte frame at 48kHz", len(p))
+		}
+	}
+}
+
+func TestDecoder_Resamples(t *testing.T) {
+	var rate int
+	dec, _ := NewDecoder(passthroughCodec{&rate}, 22050)
+	if rate != 24000 {
+		t.Fatalf("expected a 24kHz decoder, got %d", rate)
+	}
+	var n int
+	for range 50 {
+		pcm, err := dec.Decode(make([]byte, 960))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += len(pcm)
+	}
+	if n < 22000*2 || n > 22050*2 {
+		t.Errorf("got %d bytes for a second at 22.05kHz", n)
+	}
+}
+
+func TestNoCodec(t *testing.T) {
+	if audio.DefaultOpusCodec != nil {
+		t.Skip("built with a default codec")
+	}
+	if _, err := NewEncoder(nil, 16000); !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec, got %v", err)
+	}
+	if _, err := NewDecoder(nil, 16000); !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+//go:build opus && cgo
+
+package audio
+
+/*
+#cgo pkg-config: opus
+#include <opus.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// maxOpusPacket is the largest packet libopus produces for a single frame.
+const maxOpusPacket = 4000
+
+func init() {
+	DefaultOpusCodec = LibOpus{}
+}
+
+// LibOpus wraps the system libopus. Encoders are tuned for speech.
+type LibOpus struct{}
+
+func (LibOpus) NewEncoder(sampleRate, channels int) (OpusEncoder, error) {
+	// The state is allocated in Go so it is freed with the encoder.
+	mem := make([]byte, C.opus_encoder_get_size(C.int(channels)))
+	enc := (*C.OpusEncoder)(unsafe.Pointer(&mem[0]))
+	if rc := C.opus_encoder_init(enc, C.opus_int32(sampleRate), C.int(channels), C.OPUS_APPLICATION_VOIP); rc != C.OPUS_OK {
+		return nil, opusError("encoder init", rc)
+	}
+	return &libopusEncoder{mem: mem, channels: channels}, nil
+}
+
+func (LibOpus) NewDecoder(sampleRate, channels int) (OpusDecoder, error) {
+	mem := make([]byte, C.opus_decoder_get_size(C.int(channels)))
+	dec := (*C.OpusDecoder)(unsafe.Pointer(&mem[0]))
+	if rc := C.opus_decoder_init(dec, C.opus_int32(sampleRate), C.int(channels)); rc != C.OPUS_OK {
+		return nil, opusError("decoder init", rc)
+	}
+	// 120ms is the longest frame Opus allows.
+	return &libopusDecoder{mem: mem, channels: channels, pcm: make([]int16, sampleRate*120/1000*channels)}, nil
+}
+
+type libopusEncoder struct {
+	mem      []byte
+	channels int
+	out      [maxOpusPacket]byte
+}
+
+func (e *libopusEncoder) Encode(pcm []byte) ([]byte, error) {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return nil, nil
+	}
+	n := C.opus_encode(
+		(*C.OpusEncoder)(unsafe.Pointer(&e.mem[0])),
+		(*C.opus_int16)(unsafe.Pointer(&pcm[0])),
+		C.int(samples/e.channels),
+		(*C.uchar)(unsafe.Pointer(&e.out[0])),
+		C.opus_int32(len(e.out)),
+	)
+	if n < 0 {
+		return nil, opusError("encode", C.int(n))
+	}
+	return append([]byte(nil), e.out[:n]...), nil
+}
+
+type libopusDecoder struct {
+	mem      []byte
+	channels int
+	pcm      []int16
+}
+
+func (d *libopusDecoder) Decode(packet []byte) ([]byte, error) {
+	var data *C.uchar
+	if len(packet) > 0 {
+		data = (*C.uchar)(unsafe.Pointer(&packet[0]))
+	}
+	n := C.opus_decode(
+		(*C.OpusDecoder)(unsafe.Pointer(&d.mem[0])),
+		data,
+		C.opus_int32(len(packet)),
+		(*C.opus_int16)(unsafe.Pointer(&d.pcm[0])),
+		C.int(len(d.pcm)/d.channels),
+		0,
+	)
+	if n < 0 {
+		return nil, opusError("decode", n)
+	}
+	out := make([]byte, int(n)*d.channels*2)
+	for i, s := range d.pcm[:int(n)*d.channels] {
+		out[2*i] = byte(s)
+		out[2*i+1] = byte(s >> 8)
+	}
+	return out, nil
+}
+
+func opusError(op string, rc C.int) error {
+	return fmt.Errorf("opus %s: %s", op, C.GoString(C.opus_strerror(rc)))
+}
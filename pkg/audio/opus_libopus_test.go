@@ -0,0 +1,49 @@
+//go:build opus && cgo
+
+package audio
+
+import "testing"
+
+func TestLibOpus_RoundTrip(t *testing.T) {
+	if _, ok := DefaultOpusCodec.(LibOpus); !ok {
+		t.Fatalf("expected LibOpus as the default codec, got %T", DefaultOpusCodec)
+	}
+	enc, err := LibOpus{}.NewEncoder(48000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := LibOpus{}.NewDecoder(48000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 200ms of a tone in 20ms frames.
+	pcm := sine(440, 48000, 9600)
+	var loudest int16
+	for off := 0; off < len(pcm); off += 1920 {
+		packet, err := enc.Encode(pcm[off : off+1920])
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := dec.Decode(packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != 1920 {
+			t.Fatalf("expected a 20ms frame back, got %d bytes", len(out))
+		}
+		for i := 0; i+1 < len(out); i += 2 {
+			if s := int16(out[i]) | int16(out[i+1])<<8; s > loudest {
+				loudest = s
+			}
+		}
+	}
+	if loudest < 5000 {
+		t.Errorf("expected the tone to survive the round trip, peak %d", loudest)
+	}
+
+	// A lost packet is concealed rather than failing.
+	if out, err := dec.Decode(nil); err != nil || len(out) == 0 {
+		t.Errorf("expected concealment for a lost packet, got %d bytes, %v", len(out), err)
+	}
+}
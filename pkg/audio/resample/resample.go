@@ -0,0 +1,218 @@
+// Package resample converts 16-bit mono PCM between sample rates with a
+// Kaiser-windowed sinc filter. It keeps speech clean across large ratios,
+// such as 8kHz telephony to 44.1kHz or 48kHz devices to a 16kHz STT.
+package resample
+
+import "math"
+
+const (
+	// zeroCrossings is how many sinc zero crossings the filter spans on each
+	// side. More is sharper and slower.
+	zeroCrossings = 16
+	// tableResolution is the number of kernel values per zero crossing.
+	tableResolution = 256
+	// kaiserBeta trades transition width for stopband attenuation (~80dB).
+	kaiserBeta = 8.0
+	// rolloff puts the cutoff just below the lower Nyquist frequency so the
+	// transition band does not alias.
+	rolloff = 0.94
+)
+
+// Resampler converts a stream of 16-bit little-endian mono PCM from one rate
+// to another. It keeps state across calls, so chunk boundaries do not click
+// and chunks may end mid-sample. Output lags input by the filter's half
+// width, a millisecond or two; Flush drains it at the end of a stream.
+type Resampler struct {
+	from, to int
+
+	cutoff float64   // relative to the input Nyquist frequency
+	half   int       // filter half width in input samples
+	table  []float64 // kernel, tableResolution steps per zero crossing
+
+	buf  []float64 // input from sample base on
+	base int64
+	odd  []byte
+
+	// The next output is at input position idx + frac/to.
+	idx  int64
+	frac int64
+
+	in int64 // input samples seen
+}
+
+// NewResampler returns a resampler from one sample rate to another. Equal or
+// invalid rates pass audio through unchanged.
+func NewResampler(from, to int) *Resampler {
+	r := &Resampler{from: from, to: to}
+	if from <= 0 || to <= 0 || from == to {
+		return r
+	}
+	g := gcd(from, to)
+	r.from, r.to = from/g, to/g
+
+	r.cutoff = rolloff
+	if to < from {
+		r.cutoff = rolloff * float64(to) / float64(from)
+	}
+	r.half = int(math.Ceil(zeroCrossings / r.cutoff))
+
+	r.table = make([]float64, zeroCrossings*tableResolution+2)
+	for i := range r.table {
+		x := float64(i) / tableResolution // in zero crossings
+		w := 0.0
+		if x <= zeroCrossings {
+			w = kaiser(x / zeroCrossings)
+		}
+		r.table[i] = sinc(x) * w
+	}
+
+	// The stream starts in silence, so the first outputs can be computed.
+	r.buf = make([]float64, r.half)
+	r.base = -int64(r.half)
+	return r
+}
+
+func (r *Resampler) passthrough() bool {
+	return r.table == nil
+}
+
+// Process resamples the next chunk of the stream.
+func (r *Resampler) Process(pcm []byte) []byte {
+	if r.passthrough() {
+		return pcm
+	}
+	if len(r.odd) > 0 {
+		pcm = append(r.odd, pcm...)
+		r.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		r.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		r.buf = append(r.buf, float64(int16(pcm[i])|int16(pcm[i+1])<<8))
+	}
+	r.in += int64(len(pcm) / 2)
+	return r.drain(r.base + int64(len(r.buf)))
+}
+
+// Flush returns the output still held back by the filter, as if the stream
+// ended in silence, and resets the resampler for a new stream.
+func (r *Resampler) Flush() []byte {
+	if r.passthrough() {
+		return nil
+	}
+	r.odd = nil
+	r.buf = append(r.buf, make([]float64, r.half+1)...)
+	end := r.in
+	var out []byte
+	for r.idx < end {
+		s := r.sample()
+		out = append(out, byte(s), byte(s>>8))
+		r.advance()
+	}
+	r.Reset()
+	return out
+}
+
+// Reset discards the stream's state.
+func (r *Resampler) Reset() {
+	if r.passthrough() {
+		return
+	}
+	r.buf = make([]float64, r.half)
+	r.base = -int64(r.half)
+	r.odd = nil
+	r.idx, r.frac, r.in = 0, 0, 0
+}
+
+// drain emits every output whose filter window ends before avail.
+func (r *Resampler) drain(avail int64) []byte {
+	var out []byte
+	for r.idx+int64(r.half) < avail {
+		s := r.sample()
+		out = append(out, byte(s), byte(s>>8))
+		r.advance()
+	}
+	// Keep what the next output's window needs.
+	if drop := r.idx - int64(r.half) + 1 - r.base; drop > 0 {
+		drop = min(drop, int64(len(r.buf)))
+		r.buf = append(r.buf[:0], r.buf[drop:]...)
+		r.base += drop
+	}
+	return out
+}
+
+func (r *Resampler) advance() {
+	r.frac += int64(r.from)
+	r.idx += r.frac / int64(r.to)
+	r.frac %= int64(r.to)
+}
+
+// sample filters the input around the next output position.
+func (r *Resampler) sample() int16 {
+	t := float64(r.frac) / float64(r.to)
+	var acc float64
+	for k := -r.half + 1; k <= r.half; k++ {
+		i := r.idx + int64(k) - r.base
+		if i < 0 || i >= int64(len(r.buf)) {
+			continue
+		}
+		acc += r.buf[i] * r.kernel(float64(k)-t)
+	}
+	acc *= r.cutoff
+	return int16(math.Max(-32768, math.Min(32767, math.Round(acc))))
+}
+
+// kernel looks up the windowed sinc at x input samples from the center.
+func (r *Resampler) kernel(x float64) float64 {
+	pos := math.Abs(x) * r.cutoff * tableResolution
+	i := int(pos)
+	if i+1 >= len(r.table) {
+		return 0
+	}
+	f := pos - float64(i)
+	return r.table[i]*(1-f) + r.table[i+1]*f
+}
+
+// Resample converts a complete buffer of 16-bit mono PCM.
+func Resample(pcm []byte, from, to int) []byte {
+	r := NewResampler(from, to)
+	out := r.Process(pcm)
+	if r.passthrough() {
+		return out
+	}
+	return append(out, r.Flush()...)
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// kaiser is the Kaiser window at x in [-1, 1].
+func kaiser(x float64) float64 {
+	return bessel0(kaiserBeta*math.Sqrt(1-x*x)) / bessel0(kaiserBeta)
+}
+
+// bessel0 is the zeroth-order modified Bessel function of the first kind.
+func bessel0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
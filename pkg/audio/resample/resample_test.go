@@ -0,0 +1,126 @@
+package resample
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func sine(freq float64, rate, n int) []byte {
+	pcm := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		s := int16(10000 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(s >> 8)
+	}
+	return pcm
+}
+
+func samples(pcm []byte) []float64 {
+	out := make([]float64, len(pcm)/2)
+	for i := range out {
+		out[i] = float64(int16(pcm[2*i]) | int16(pcm[2*i+1])<<8)
+	}
+	return out
+}
+
+func rms(s []float64) float64 {
+	var sum float64
+	for _, v := range s {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(s)))
+}
+
+func TestResampleLength(t *testing.T) {
+	for _, c := range []struct{ from, to, want int }{
+		{8000, 44100, 44100},
+		{44100, 8000, 8000},
+		{48000, 16000, 16000},
+		{16000, 24000, 24000},
+		{44100, 48000, 48000},
+	} {
+		if n := len(Resample(make([]byte, c.from*2), c.from, c.to)) / 2; n != c.want {
+			t.Errorf("%d to %d: expected %d samples, got %d", c.from, c.to, c.want, n)
+		}
+	}
+}
+
+func TestResampleKeepsTone(t *testing.T) {
+	for _, c := range []struct{ from, to int }{{8000, 44100}, {44100, 16000}, {48000, 8000}} {
+		out := samples(Resample(sine(440, c.from, c.from), c.from, c.to))
+		want := samples(sine(440, c.to, c.to))
+		// Skip the edges, where the filter runs into silence.
+		lo, hi := c.to/20, len(want)-c.to/20
+		diff := make([]float64, 0, hi-lo)
+		for i := lo; i < hi; i++ {
+			diff = append(diff, out[i]-want[i])
+		}
+		if err := rms(diff) / rms(want[lo:hi]); err > 0.01 {
+			t.Errorf("%d to %d: relative error %.4f", c.from, c.to, err)
+		}
+	}
+}
+
+func TestResampleRemovesAliases(t *testing.T) {
+	// 6kHz is above 8kHz's Nyquist frequency and would fold down to 2kHz.
+	out := samples(Resample(sine(6000, 44100, 44100), 44100, 8000))
+	if level := rms(out[400:7600]); level > 10000/math.Sqrt2*0.001 {
+		t.Errorf("a 6kHz tone came through at RMS %.1f", level)
+	}
+}
+
+func TestResamplerChunkedMatchesWhole(t *testing.T) {
+	pcm := sine(440, 8000, 1600)
+	whole := Resample(pcm, 8000, 44100)
+
+	r := NewResampler(8000, 44100)
+	var chunked []byte
+	// Odd chunk lengths split samples across calls.
+	for i := 0; i < len(pcm); i += 321 {
+		chunked = append(chunked, r.Process(pcm[i:min(i+321, len(pcm))])...)
+	}
+	chunked = append(chunked, r.Flush()...)
+	if !bytes.Equal(chunked, whole) {
+		t.Fatalf("chunked output (%d bytes) differs from whole (%d bytes)", len(chunked), len(whole))
+	}
+
+	// Flush starts a new stream.
+	if again := append(r.Process(pcm), r.Flush()...); !bytes.Equal(again, whole) {
+		t.Error("the resampler kept state across Flush")
+	}
+}
+
+func TestResamplerPassthrough(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4}
+	if out := Resample(pcm, 16000, 16000); &out[0] != &pcm[0] {
+		t.Error("expected equal rates to pass through")
+	}
+	if out := NewResampler(16000, 16000).Flush(); out != nil {
+		t.Errorf("passthrough flushed %d bytes", len(out))
+	}
+}
+
+func FuzzResampler(f *testing.F) {
+	f.Add(sine(440, 16000, 100), uint16(16000), uint16(24000), 37)
+	f.Add(sine(440, 48000, 100), uint16(48000), uint16(8000), 1)
+	f.Add([]byte{0x01}, uint16(44100), uint16(16000), 0)
+
+	f.Fuzz(func(t *testing.T, pcm []byte, from, to uint16, split int) {
+		if from < 1000 || to < 1000 || from == to {
+			return
+		}
+		if split < 0 || split > len(pcm) {
+			split = len(pcm) / 2
+		}
+		r := NewResampler(int(from), int(to))
+		for _, chunk := range [][]byte{pcm[:split], pcm[split:]} {
+			if out := r.Process(chunk); len(out)%2 != 0 {
+				t.Fatalf("resampled to %d bytes", len(out))
+			}
+		}
+		if out := r.Flush(); len(out)%2 != 0 {
+			t.Fatalf("flushed %d bytes", len(out))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package audio
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 )
 
 
@@ -31,3 +32,31 @@ func NewWavBuffer(pcm []byte, sampleRate int) []byte {
 
 	return buf.Bytes()
 }
+
+var ErrUnsupportedWav = errors.New("unsupported wav: need 16-bit PCM")
+
+// DecodeWav returns the samples of a 16-bit PCM WAV file downmixed to mono,
+// and its sample rate. Chunks other than fmt and data are skipped.
+func DecodeWav(data []byte) ([]byte, int, error) {
+	pcm, f, err := ParseWav(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return downmix(pcm, f.Channels), f.SampleRate, nil
+}
+
+func downmix(pcm []byte, channels int) []byte {
+	if channels == 1 {
+		return pcm[:len(pcm)&^1]
+	}
+	frame := 2 * channels
+	out := make([]byte, 0, len(pcm)/channels)
+	for i := 0; i+frame <= len(pcm); i += frame {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += int(int16(binary.LittleEndian.Uint16(pcm[i+2*c:])))
+		}
+		out = binary.LittleEndian.AppendUint16(out, uint16(int16(sum/channels)))
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// maxWavHeader bounds the chunks NewWavReader reads before the samples.
+const maxWavHeader = 1 << 20
+
+// WavFormat is the format of a WAV file's samples.
+type WavFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// IsWav reports whether data starts with a RIFF/WAVE header.
+func IsWav(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+// ParseWavHeader parses a 16-bit PCM WAV header up to its data chunk. It
+// returns the format, the offset of the samples and their length, or -1 when
+// the header leaves the length open (0xFFFFFFFF), as streaming encoders do.
+// A length past the end of a stream is read up to its end. It returns
+// io.ErrUnexpectedEOF if data ends before the data chunk starts.
+func ParseWavHeader(data []byte) (WavFormat, int, int, error) {
+	if len(data) < 12 {
+		if len(data) < 4 || string(data[0:4]) == "RIFF" {
+			return WavFormat{}, 0, 0, io.ErrUnexpectedEOF
+		}
+		return WavFormat{}, 0, 0, ErrUnsupportedWav
+	}
+	if !IsWav(data) {
+		return WavFormat{}, 0, 0, ErrUnsupportedWav
+	}
+	var f WavFormat
+	var format uint16
+	off := 12
+	for {
+		if off+8 > len(data) {
+			return WavFormat{}, 0, 0, io.ErrUnexpectedEOF
+		}
+		id := string(data[off : off+4])
+		size := binary.LittleEndian.Uint32(data[off+4:])
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return WavFormat{}, 0, 0, ErrUnsupportedWav
+			}
+			if off+8+16 > len(data) {
+				return WavFormat{}, 0, 0, io.ErrUnexpectedEOF
+			}
+			body := data[off+8:]
+			format = binary.LittleEndian.Uint16(body[0:])
+			f.Channels = int(binary.LittleEndian.Uint16(body[2:]))
+			f.SampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			f.BitsPerSample = int(binary.LittleEndian.Uint16(body[14:]))
+		case "data":
+			// WAVE_FORMAT_EXTENSIBLE (0xFFFE) is accepted as long as it is
+			// 16-bit; the subformat is practically always PCM.
+			if (format != 1 && format != 0xFFFE) || f.BitsPerSample != 16 || f.Channels == 0 || f.SampleRate == 0 {
+				return WavFormat{}, 0, 0, ErrUnsupportedWav
+			}
+			n := int(size)
+			if size == wavOpenSize || n < 0 {
+				n = -1
+			}
+			return f, off + 8, n, nil
+		}
+		// Chunks are padded to an even size.
+		next := int64(off) + 8 + int64(size) + int64(size%2)
+		if next > int64(len(data)) {
+			return WavFormat{}, 0, 0, io.ErrUnexpectedEOF
+		}
+		off = int(next)
+	}
+}
+
+// ParseWav returns the samples of a 16-bit PCM WAV file, channels
+// interleaved as stored, and their format. A file cut short returns the
+// samples it has.
+func ParseWav(data []byte) ([]byte, WavFormat, error) {
+	f, off, n, err := ParseWavHeader(data)
+	if err != nil {
+		return nil, WavFormat{}, ErrUnsupportedWav
+	}
+	pcm := data[off:]
+	if n >= 0 && n < len(pcm) {
+		pcm = pcm[:n]
+	}
+	return pcm, f, nil
+}
+
+// WavReader reads the samples of a 16-bit PCM WAV stream, such as a file or
+// an HTTP response, without holding it in memory.
+type WavReader struct {
+	r         io.Reader
+	format    WavFormat
+	buf       []byte // samples read along with the header
+	remaining int    // or -1 up to EOF
+}
+
+// NewWavReader reads the header from r, up to the first sample.
+func NewWavReader(r io.Reader) (*WavReader, error) {
+	var head []byte
+	chunk := make([]byte, 4096)
+	for {
+		n, err := r.Read(chunk)
+		head = append(head, chunk[:n]...)
+		f, off, size, perr := ParseWavHeader(head)
+		if perr == nil {
+			return &WavReader{r: r, format: f, buf: head[off:], remaining: size}, nil
+		}
+		if perr != io.ErrUnexpectedEOF || len(head) > maxWavHeader {
+			return nil, ErrUnsupportedWav
+		}
+		if err == io.EOF {
+			return nil, ErrUnsupportedWav
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (w *WavReader) Format() WavFormat {
+	return w.format
+}
+
+// Read reads samples, channels interleaved as stored. It returns io.EOF at
+// the end of the data chunk, or of the stream if that comes first.
+func (w *WavReader) Read(p []byte) (int, error) {
+	if w.remaining == 0 {
+		return 0, io.EOF
+	}
+	if w.remaining > 0 && len(p) > w.remaining {
+		p = p[:w.remaining]
+	}
+	var n int
+	var err error
+	if len(w.buf) > 0 {
+		n = copy(p, w.buf)
+		w.buf = w.buf[n:]
+	} else {
+		n, err = w.r.Read(p)
+	}
+	if w.remaining > 0 {
+		w.remaining -= n
+	}
+	return n, err
+}
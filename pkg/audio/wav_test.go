@@ -2,7 +2,12 @@ package audio
 
 import (
 	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/iotest"
 )
 
 func TestNewWavBuffer(t *testing.T) {
@@ -23,3 +28,177 @@ func TestNewWavBuffer(t *testing.T) {
 		t.Errorf("Expected length %d, got %d", expectedLen, len(wav))
 	}
 }
+
+func TestNewWavBuffer_48kHz(t *testing.T) {
+	wav := NewWavBuffer(make([]byte, 1920), 48000)
+	if rate := binary.LittleEndian.Uint32(wav[24:]); rate != 48000 {
+		t.Errorf("expected sample rate 48000, got %d", rate)
+	}
+	if byteRate := binary.LittleEndian.Uint32(wav[28:]); byteRate != 96000 {
+		t.Errorf("expected byte rate 96000, got %d", byteRate)
+	}
+	if _, rate, err := DecodeWav(wav); err != nil || rate != 48000 {
+		t.Errorf("round trip rate = %d, %v", rate, err)
+	}
+}
+
+func TestDecodeWav(t *testing.T) {
+	pcm := []byte{0x01, 0x02, 0x03, 0x04}
+	got, rate, err := DecodeWav(NewWavBuffer(pcm, 16000))
+	if err != nil || rate != 16000 || !bytes.Equal(got, pcm) {
+		t.Fatalf("round trip = %v, %d, %v", got, rate, err)
+	}
+
+	stereo := NewWavBuffer([]byte{0x10, 0x00, 0x30, 0x00}, 8000)
+	stereo[22] = 2 // channels
+	if got, _, err := DecodeWav(stereo); err != nil || !bytes.Equal(got, []byte{0x20, 0x00}) {
+		t.Errorf("stereo downmix = %v, %v", got, err)
+	}
+
+	if _, _, err := DecodeWav([]byte("not a wav file")); err != ErrUnsupportedWav {
+		t.Errorf("expected ErrUnsupportedWav, got %v", err)
+	}
+}
+
+func FuzzDecodeWav(f *testing.F) {
+	f.Add(NewWavBuffer([]byte{0x01, 0x02, 0x03, 0x04}, 16000))
+	stereo := NewWavBuffer([]byte{0x10, 0x00, 0x30, 0x00, 0x50}, 8000)
+	stereo[22] = 2
+	f.Add(stereo)
+	f.Add(NewWavBuffer(nil, 44100)[:40])
+	f.Add([]byte("RIFF\xff\xff\xff\xffWAVEfmt \xff\xff\xff\x7f"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pcm, rate, err := DecodeWav(data)
+		if err != nil {
+			return
+		}
+		if rate <= 0 || len(pcm)%2 != 0 {
+			t.Errorf("decoded %d bytes at %dHz", len(pcm), rate)
+		}
+	})
+}
+
+// streamingWav is a stereo WAV header as streaming encoders write it, with
+// the data length left open and a LIST chunk before the samples.
+func streamingWav(pcm []byte) []byte {
+	wav := NewWavBuffer(nil, 24000)
+	binary.LittleEndian.PutUint16(wav[22:], 2)
+	list := append([]byte("LIST\x03\x00\x00\x00abc"), 0) // padded
+	out := append(append(append([]byte(nil), wav[:36]...), list...), wav[36:]...)
+	binary.LittleEndian.PutUint32(out[len(out)-4:], 0xFFFFFFFF)
+	return append(out, pcm...)
+}
+
+func TestParseWav(t *testing.T) {
+	pcm := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	got, f, err := ParseWav(streamingWav(pcm))
+	if err != nil || !bytes.Equal(got, pcm) {
+		t.Fatalf("parse = %v, %v", got, err)
+	}
+	if f != (WavFormat{SampleRate: 24000, Channels: 2, BitsPerSample: 16}) {
+		t.Errorf("unexpected format %+v", f)
+	}
+
+	wav := streamingWav(nil)
+	if _, _, _, err := ParseWavHeader(wav[:len(wav)-2]); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for a partial header, got %v", err)
+	}
+	if IsWav(pcm) {
+		t.Error("expected raw PCM not to be a WAV file")
+	}
+}
+
+func TestWavReader(t *testing.T) {
+	pcm := make([]byte, 10000)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+	wav := append(NewWavBuffer(pcm, 16000), "junk"...)
+
+	r, err := NewWavReader(iotest.OneByteReader(bytes.NewReader(wav)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := r.Format(); f.SampleRate != 16000 || f.Channels != 1 {
+		t.Errorf("unexpected format %+v", f)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, pcm) {
+		t.Errorf("read %d bytes, %v; expected the data chunk only", len(got), err)
+	}
+
+	r, err = NewWavReader(bytes.NewReader(streamingWav(pcm)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(r); !bytes.Equal(got, pcm) {
+		t.Errorf("read %d bytes of an open-ended stream, expected %d", len(got), len(pcm))
+	}
+
+	// An empty data chunk ends the samples, even if more bytes follow.
+	empty := append(NewWavBuffer(nil, 16000), "junk"...)
+	if _, _, n, _ := ParseWavHeader(empty); n != 0 {
+		t.Errorf("expected an empty data chunk, got length %d", n)
+	}
+	r, err = NewWavReader(bytes.NewReader(empty))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(r); len(got) != 0 || err != nil {
+		t.Errorf("read %d bytes of an empty data chunk, %v", len(got), err)
+	}
+
+	// So does a stream that ends before its stated length.
+	r, err = NewWavReader(bytes.NewReader(wav[:44+100]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(r); !bytes.Equal(got, pcm[:100]) {
+		t.Errorf("read %d bytes of a short stream, expected 100", len(got))
+	}
+
+	if _, err := NewWavReader(bytes.NewReader(wav[:30])); err != ErrUnsupportedWav {
+		t.Errorf("expected ErrUnsupportedWav for a truncated header, got %v", err)
+	}
+}
+
+func TestWavWriter(t *testing.T) {
+	pcm := make([]byte, 10000)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := NewWavWriter(f, 16000)
+	for i := 0; i < len(pcm); i += 999 {
+		if _, err := w.Write(pcm[i:min(i+999, len(pcm))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(f.Name())
+	if err != nil || !bytes.Equal(got, NewWavBuffer(pcm, 16000)) {
+		t.Errorf("the patched file differs from NewWavBuffer's, %v", err)
+	}
+
+	// Without seeking, the sizes stay open.
+	var buf bytes.Buffer
+	w = NewWavWriter(&buf, 16000)
+	w.Write(pcm)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, format, err := ParseWav(buf.Bytes()); err != nil || format.SampleRate != 16000 || !bytes.Equal(got, pcm) {
+		t.Errorf("got %d bytes at %+v, %v", len(got), format, err)
+	}
+	if _, err := w.Write(pcm); err == nil {
+		t.Error("expected an error writing after Close")
+	}
+}
@@ -0,0 +1,111 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// wavOpenSize marks sizes a header leaves open, as streaming encoders do.
+const wavOpenSize = 0xFFFFFFFF
+
+// WavWriter writes 16-bit mono PCM as a WAV file as it comes, so long
+// recordings need not be held in memory like NewWavBuffer's. The header is
+// written first with open sizes, which ParseWavHeader and most players
+// accept, and patched with the real ones on Close when w is an
+// io.WriteSeeker such as a file.
+type WavWriter struct {
+	w      io.Writer
+	buf    *bufio.Writer
+	n      int64
+	err    error
+	closed bool
+}
+
+// NewWavWriter writes the header to w. Errors writing to w are returned by
+// Write and Close.
+func NewWavWriter(w io.Writer, sampleRate int) *WavWriter {
+	ww := &WavWriter{w: w, buf: bufio.NewWriter(w)}
+	_, ww.err = ww.buf.Write(wavHeader(sampleRate))
+	return ww
+}
+
+// wavHeader returns a header with open sizes.
+func wavHeader(sampleRate int) []byte {
+	h := make([]byte, 0, 44)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, wavOpenSize)
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, 1) // PCM
+	h = binary.LittleEndian.AppendUint16(h, 1) // mono
+	h = binary.LittleEndian.AppendUint32(h, uint32(sampleRate))
+	h = binary.LittleEndian.AppendUint32(h, uint32(sampleRate*2))
+	h = binary.LittleEndian.AppendUint16(h, 2)
+	h = binary.LittleEndian.AppendUint16(h, 16)
+	h = append(h, "data"...)
+	return binary.LittleEndian.AppendUint32(h, wavOpenSize)
+}
+
+func (ww *WavWriter) Write(pcm []byte) (int, error) {
+	if ww.err != nil {
+		return 0, ww.err
+	}
+	if ww.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := ww.buf.Write(pcm)
+	ww.n += int64(n)
+	ww.err = err
+	return n, err
+}
+
+// Len returns the number of sample bytes written.
+func (ww *WavWriter) Len() int64 {
+	return ww.n
+}
+
+// Close flushes the samples and, if w can seek, patches the header. It does
+// not close w. Files over 4GiB keep open sizes.
+func (ww *WavWriter) Close() error {
+	if ww.closed {
+		return ww.err
+	}
+	ww.closed = true
+	if ww.err != nil {
+		return ww.err
+	}
+	if ww.n%2 != 0 {
+		// Chunks are padded to an even size.
+		ww.buf.WriteByte(0)
+	}
+	if ww.err = ww.buf.Flush(); ww.err != nil {
+		return ww.err
+	}
+	ws, ok := ww.w.(io.WriteSeeker)
+	if !ok || ww.n >= wavOpenSize-36 {
+		return nil
+	}
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// Not seekable after all, like a pipe behind an *os.File.
+		return nil
+	}
+	start := end - 44 - ww.n - ww.n%2
+	if ww.err = ww.patch(ws, start+4, uint32(36+ww.n)); ww.err != nil {
+		return ww.err
+	}
+	if ww.err = ww.patch(ws, start+40, uint32(ww.n)); ww.err != nil {
+		return ww.err
+	}
+	_, ww.err = ws.Seek(end, io.SeekStart)
+	return ww.err
+}
+
+func (ww *WavWriter) patch(ws io.WriteSeeker, off int64, size uint32) error {
+	if _, err := ws.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := ws.Write(binary.LittleEndian.AppendUint32(nil, size))
+	return err
+}
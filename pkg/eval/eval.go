@@ -0,0 +1,220 @@
+// Package eval compares providers on fixed inputs: STT providers on audio
+// fixtures by word error rate, latency and cost, and LLM providers on a
+// prompt suite by latency, tokens and cost.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const (
+	KindSTT = "stt"
+	KindLLM = "llm"
+)
+
+// Fixture is an utterance and what was said in it.
+type Fixture struct {
+	Name       string
+	Audio      []byte
+	SampleRate int
+	Reference  string
+}
+
+// LoadFixtures reads every WAV file in dir whose reference transcript sits
+// next to it with a .txt extension, e.g. greeting.wav and greeting.txt.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wav"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var fixtures []Fixture
+	for _, path := range paths {
+		ref, err := os.ReadFile(strings.TrimSuffix(path, ".wav") + ".txt")
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pcm, rate, err := audio.DecodeWav(data)
+		if err != nil {
+			return nil, fmt.Errorf("eval: %s: %w", path, err)
+		}
+		fixtures = append(fixtures, Fixture{
+			Name:       strings.TrimSuffix(filepath.Base(path), ".wav"),
+			Audio:      pcm,
+			SampleRate: rate,
+			Reference:  strings.TrimSpace(string(ref)),
+		})
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("eval: no fixtures with references in %s", dir)
+	}
+	return fixtures, nil
+}
+
+// Prompt is one case of an LLM prompt suite.
+type Prompt struct {
+	Name   string `json:"name"`
+	System string `json:"system,omitempty"`
+	Prompt string `json:"prompt"`
+}
+
+// LoadPrompts reads a prompt suite, one JSON Prompt per line.
+func LoadPrompts(path string) ([]Prompt, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var prompts []Prompt
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var p Prompt
+		if err := json.Unmarshal([]byte(text), &p); err != nil {
+			return nil, fmt.Errorf("eval: %s:%d: %w", path, line, err)
+		}
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("prompt-%d", line)
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, scanner.Err()
+}
+
+// Pricing prices provider usage in US dollars. LLM prices are looked up by
+// model, then by provider label; STT prices by provider label, per minute of
+// audio.
+type Pricing struct {
+	LLM          map[string]orchestrator.ModelPricing `json:"llm,omitempty"`
+	STTPerMinute map[string]float64                   `json:"stt_per_minute,omitempty"`
+}
+
+// LoadPricing reads Pricing from a JSON file.
+func LoadPricing(path string) (Pricing, error) {
+	var p Pricing
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	return p, json.Unmarshal(data, &p)
+}
+
+// Result is one provider's run of one case. Edits and Words are the word
+// errors against the reference and its length, for STT.
+type Result struct {
+	Kind     string
+	Provider string
+	Case     string
+	Output   string
+	Latency  time.Duration
+	Edits    int
+	Words    int
+	Tokens   int
+	CostUSD  float64
+	Err      error
+}
+
+// WER is the result's word error rate.
+func (r Result) WER() float64 {
+	if r.Words == 0 {
+		return 0
+	}
+	return float64(r.Edits) / float64(r.Words)
+}
+
+// Runner runs cases against providers, keyed by the label they are reported
+// under. Cases run one at a time, so latencies don't compete.
+type Runner struct {
+	STT      map[string]orchestrator.STTProvider
+	LLM      map[string]orchestrator.LLMProvider
+	Language orchestrator.Language
+	Pricing  Pricing
+}
+
+// RunSTT transcribes every fixture with every STT provider.
+func (r *Runner) RunSTT(ctx context.Context, fixtures []Fixture) []Result {
+	var results []Result
+	for _, label := range sortedKeys(r.STT) {
+		provider := r.STT[label]
+		for _, f := range fixtures {
+			if s, ok := provider.(interface{ SetSampleRate(int) }); ok {
+				s.SetSampleRate(f.SampleRate)
+			}
+			start := time.Now()
+			text, err := provider.Transcribe(ctx, f.Audio, r.Language)
+			res := Result{Kind: KindSTT, Provider: label, Case: f.Name, Output: text, Latency: time.Since(start), Err: err}
+			if err == nil {
+				res.Edits, res.Words = wordErrors(f.Reference, text)
+				minutes := float64(len(f.Audio)/2) / float64(f.SampleRate) / 60
+				res.CostUSD = minutes * r.Pricing.STTPerMinute[label]
+			}
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// RunLLM completes every prompt with every LLM provider. Tokens and cost
+// are only known for providers that report usage.
+func (r *Runner) RunLLM(ctx context.Context, prompts []Prompt) []Result {
+	tracker := orchestrator.NewCostTracker(r.Pricing.LLM)
+	var results []Result
+	for _, label := range sortedKeys(r.LLM) {
+		provider := r.LLM[label]
+		for _, p := range prompts {
+			var messages []orchestrator.Message
+			if p.System != "" {
+				messages = append(messages, orchestrator.Message{Role: "system", Content: p.System})
+			}
+			messages = append(messages, orchestrator.Message{Role: "user", Content: p.Prompt})
+
+			start := time.Now()
+			var text string
+			var usage orchestrator.Usage
+			var err error
+			if u, ok := provider.(orchestrator.UsageLLMProvider); ok {
+				text, usage, err = u.CompleteWithUsage(ctx, messages, orchestrator.GenerationParams{})
+			} else {
+				text, err = provider.Complete(ctx, messages)
+			}
+			res := Result{Kind: KindLLM, Provider: label, Case: p.Name, Output: text, Latency: time.Since(start), Err: err}
+			if err == nil {
+				res.Tokens = usage.Total()
+				res.CostUSD = tracker.Estimate(label, usage)
+			}
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
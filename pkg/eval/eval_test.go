@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type fixedSTT struct {
+	text string
+	err  error
+	rate int
+}
+
+func (s *fixedSTT) Transcribe(ctx context.Context, pcm []byte, lang orchestrator.Language) (string, error) {
+	return s.text, s.err
+}
+func (s *fixedSTT) Name() string        { return "fixed" }
+func (s *fixedSTT) SetSampleRate(r int) { s.rate = r }
+
+type usageLLM struct{}
+
+func (usageLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "ok", nil
+}
+func (usageLLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	return "ok", nil
+}
+func (usageLLM) CompleteWithUsage(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	return "Sure, " + messages[len(messages)-1].Content, orchestrator.Usage{Model: "small", PromptTokens: 1000, CompletionTokens: 500}, nil
+}
+func (usageLLM) Name() string { return "usage" }
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "greeting.wav"), audio.NewWavBuffer(make([]byte, 16000), 8000), 0o644)
+	os.WriteFile(filepath.Join(dir, "greeting.txt"), []byte("Hello there\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "unlabelled.wav"), audio.NewWavBuffer(make([]byte, 160), 8000), 0o644)
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("expected only the labelled fixture, got %d", len(fixtures))
+	}
+	if f := fixtures[0]; f.Name != "greeting" || f.Reference != "Hello there" || f.SampleRate != 8000 || len(f.Audio) != 16000 {
+		t.Errorf("unexpected fixture %+v", f)
+	}
+
+	if _, err := LoadFixtures(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without fixtures")
+	}
+}
+
+func TestLoadPrompts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.jsonl")
+	os.WriteFile(path, []byte(`{"name":"hours","prompt":"When do you open?"}`+"\n\n"+`{"prompt":"Hi","system":"Be brief."}`+"\n"), 0o644)
+
+	prompts, err := LoadPrompts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 2 || prompts[0].Name != "hours" || prompts[1].Name != "prompt-3" || prompts[1].System != "Be brief." {
+		t.Errorf("unexpected prompts %+v", prompts)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	good := &fixedSTT{text: "turn of the lights"}
+	r := &Runner{
+		STT: map[string]orchestrator.STTProvider{"good": good, "broken": &fixedSTT{err: errors.New("boom")}},
+		LLM: map[string]orchestrator.LLMProvider{"small": usageLLM{}},
+		Pricing: Pricing{
+			LLM:          map[string]orchestrator.ModelPricing{"small": {PromptPerMillion: 1, CompletionPerMillion: 2}},
+			STTPerMinute: map[string]float64{"good": 0.006},
+		},
+	}
+	fixtures := []Fixture{{Name: "lights", Audio: make([]byte, 2*16000*60), SampleRate: 16000, Reference: "Turn on the lights."}}
+
+	results := r.RunSTT(context.Background(), fixtures)
+	if len(results) != 2 || results[0].Provider != "broken" || results[0].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if res := results[1]; res.WER() != 0.25 || res.CostUSD != 0.006 || good.rate != 16000 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	llm := r.RunLLM(context.Background(), []Prompt{{Name: "hi", Prompt: "hello"}})
+	if len(llm) != 1 || llm[0].Tokens != 1500 || llm[0].CostUSD != 0.002 || llm[0].Output != "Sure, hello" {
+		t.Errorf("unexpected LLM results %+v", llm)
+	}
+
+	summaries := Summarize(append(results, llm...))
+	if len(summaries) != 3 || summaries[0].Errors != 1 || summaries[1].WER != 0.25 {
+		t.Errorf("unexpected summaries %+v", summaries)
+	}
+
+	var md, csv bytes.Buffer
+	if err := WriteMarkdown(&md, append(results, llm...)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| good | 1 | 0 | 25.0% |") || !strings.Contains(md.String(), "| small | 1 | 0 | 1500 |") {
+		t.Errorf("unexpected markdown:\n%s", md.String())
+	}
+	if err := WriteCSV(&csv, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[1], "boom") {
+		t.Errorf("unexpected csv:\n%s", csv.String())
+	}
+}
@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Summary aggregates a provider's results. WER is over all its words, not a
+// mean of per-case rates; failed cases only count as Errors.
+type Summary struct {
+	Kind       string
+	Provider   string
+	Cases      int
+	Errors     int
+	WER        float64
+	P50Latency time.Duration
+	P95Latency time.Duration
+	Tokens     int
+	CostUSD    float64
+}
+
+// Summarize groups results by kind and provider, in the order they first
+// appear.
+func Summarize(results []Result) []Summary {
+	var order []string
+	groups := make(map[string][]Result)
+	for _, r := range results {
+		key := r.Kind + "/" + r.Provider
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, key := range order {
+		group := groups[key]
+		s := Summary{Kind: group[0].Kind, Provider: group[0].Provider, Cases: len(group)}
+		var latencies []time.Duration
+		var edits, words int
+		for _, r := range group {
+			if r.Err != nil {
+				s.Errors++
+				continue
+			}
+			latencies = append(latencies, r.Latency)
+			edits += r.Edits
+			words += r.Words
+			s.Tokens += r.Tokens
+			s.CostUSD += r.CostUSD
+		}
+		if words > 0 {
+			s.WER = float64(edits) / float64(words)
+		}
+		s.P50Latency = percentile(latencies, 0.5)
+		s.P95Latency = percentile(latencies, 0.95)
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func percentile(d []time.Duration, p float64) time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), d...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1)+0.5)]
+}
+
+// WriteCSV writes one row per result.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "provider", "case", "latency_ms", "wer", "tokens", "cost_usd", "error", "output"})
+	for _, r := range results {
+		wer, errText := "", ""
+		if r.Kind == KindSTT && r.Err == nil {
+			wer = strconv.FormatFloat(r.WER(), 'f', 4, 64)
+		}
+		if r.Err != nil {
+			errText = r.Err.Error()
+		}
+		cw.Write([]string{
+			r.Kind, r.Provider, r.Case,
+			strconv.FormatInt(r.Latency.Milliseconds(), 10),
+			wer,
+			strconv.Itoa(r.Tokens),
+			strconv.FormatFloat(r.CostUSD, 'f', 6, 64),
+			errText,
+			r.Output,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes a table comparing the providers of each kind.
+func WriteMarkdown(w io.Writer, results []Result) error {
+	summaries := Summarize(results)
+	for _, kind := range []string{KindSTT, KindLLM} {
+		var rows []Summary
+		for _, s := range summaries {
+			if s.Kind == kind {
+				rows = append(rows, s)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		if kind == KindSTT {
+			fmt.Fprintf(w, "## STT\n\n| Provider | Cases | Errors | WER | p50 latency | p95 latency | Cost (USD) |\n|---|---|---|---|---|---|---|\n")
+		} else {
+			fmt.Fprintf(w, "## LLM\n\n| Provider | Cases | Errors | Tokens | p50 latency | p95 latency | Cost (USD) |\n|---|---|---|---|---|---|---|\n")
+		}
+		for _, s := range rows {
+			metric := fmt.Sprintf("%.1f%%", s.WER*100)
+			if kind == KindLLM {
+				metric = strconv.Itoa(s.Tokens)
+			}
+			fmt.Fprintf(w, "| %s | %d | %d | %s | %dms | %dms | %.4f |\n",
+				s.Provider, s.Cases, s.Errors, metric, s.P50Latency.Milliseconds(), s.P95Latency.Milliseconds(), s.CostUSD)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package eval
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WER is the word error rate of hypothesis against reference: the
+// substitutions, deletions and insertions needed to turn one into the other,
+// per reference word. Case and punctuation are ignored.
+func WER(reference, hypothesis string) float64 {
+	edits, words := wordErrors(reference, hypothesis)
+	if words == 0 {
+		if edits == 0 {
+			return 0
+		}
+		return 1
+	}
+	return float64(edits) / float64(words)
+}
+
+// wordErrors returns the word edit distance between reference and
+// hypothesis, and the number of reference words.
+func wordErrors(reference, hypothesis string) (edits, words int) {
+	ref, hyp := normalizeWords(reference), normalizeWords(hypothesis)
+	prev := make([]int, len(hyp)+1)
+	cur := make([]int, len(hyp)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ref); i++ {
+		cur[0] = i
+		for j := 1; j <= len(hyp); j++ {
+			sub := prev[j-1]
+			if ref[i-1] != hyp[j-1] {
+				sub++
+			}
+			cur[j] = min(sub, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(hyp)], len(ref)
+}
+
+func normalizeWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
@@ -0,0 +1,23 @@
+package eval
+
+import "testing"
+
+func TestWER(t *testing.T) {
+	cases := []struct {
+		ref, hyp string
+		want     float64
+	}{
+		{"Hello there, how are you?", "hello there how are you", 0},
+		{"turn on the lights", "turn of the lights", 0.25},
+		{"turn on the lights", "turn on lights", 0.25},
+		{"turn on the lights", "please turn on the lights now", 0.5},
+		{"I can't hear you", "I cant hear you", 0.25},
+		{"", "", 0},
+		{"", "noise", 1},
+	}
+	for _, c := range cases {
+		if got := WER(c.ref, c.hyp); got != c.want {
+			t.Errorf("WER(%q, %q) = %v, want %v", c.ref, c.hyp, got, c.want)
+		}
+	}
+}
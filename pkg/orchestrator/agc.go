@@ -0,0 +1,35 @@
+package orchestrator
+
+import "github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/agc"
+
+// AGCConfig levels the caller's audio before the VAD and the STT, so quiet
+// or distant speakers still cross the VAD threshold and loud ones don't
+// clip. See agc.Config for the tuning; zero values take its defaults.
+type AGCConfig struct {
+	Enabled bool `json:"enabled"`
+	agc.Config
+}
+
+// levelInput applies automatic gain control to a chunk of caller audio.
+// Echo of the bot gets the current gain without moving it.
+func (ms *ManagedStream) levelInput(chunk []byte, isEcho bool) []byte {
+	if ms.orch == nil {
+		return chunk
+	}
+	cfg := ms.orch.GetConfig().AGC
+	if !cfg.Enabled {
+		return chunk
+	}
+	_, inputRate := ms.sampleRates()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.agc == nil || ms.agcRate != inputRate {
+		ms.agc = agc.New(inputRate, cfg.Config)
+		ms.agcRate = inputRate
+	}
+	if isEcho {
+		return ms.agc.Apply(chunk)
+	}
+	return ms.agc.Process(chunk)
+}
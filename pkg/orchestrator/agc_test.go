@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func agcStream(t *testing.T, enabled bool) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.BargeInVADThreshold = 0.05
+	cfg.AGC.Enabled = enabled
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.05, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("agc"))
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+func heard(ms *ManagedStream, chunk []byte, n int) bool {
+	for range n {
+		ms.doWrite(chunk)
+		if ms.State() == StreamListening {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAGC_QuietSpeaker(t *testing.T) {
+	quiet := toneChunk(600) // RMS 0.018, under the VAD threshold
+	if heard(agcStream(t, false), quiet, 200) {
+		t.Fatal("the quiet speaker was heard without AGC")
+	}
+	if !heard(agcStream(t, true), quiet, 200) {
+		t.Error("AGC did not bring the quiet speaker over the VAD threshold")
+	}
+}
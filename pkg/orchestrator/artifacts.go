@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// TurnArtifactsConfig saves the audio behind each final transcript as
+// "<session>/<turn>/user.wav" when Storage is set, for debugging
+// transcription. Audio is only saved while the session grants
+// ConsentDebugAudio and is sealed with Config.Encryptor when set.
+// Size and age limits are up to the storage, e.g. store.ArtifactDir.
+type TurnArtifactsConfig struct {
+	Storage RecordingStorage
+}
+
+// saveTurnAudio stores the caller audio of turnID in the background.
+// FlushRecordings waits for it.
+func (ms *ManagedStream) saveTurnAudio(turnID string) {
+	if ms.orch == nil || turnID == "" {
+		return
+	}
+	cfg := ms.orch.GetConfig()
+	if cfg.TurnArtifacts.Storage == nil {
+		return
+	}
+	pcm, _ := ms.ExportLastUserAudio()
+	if len(pcm) == 0 {
+		return
+	}
+	_, inputRate := ms.sampleRates()
+	wav := audio.NewWavBuffer(pcm, inputRate)
+	name := ms.session.ID + "/" + turnID + "/user.wav"
+
+	ms.orch.recordings.Add(1)
+	go func() {
+		defer ms.orch.recordings.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		data, err := SealIfConfigured(ctx, cfg.Encryptor, wav)
+		if err == nil {
+			err = cfg.TurnArtifacts.Storage.Put(ctx, name, data)
+		}
+		if err != nil {
+			ms.orch.logger.Error("saving turn audio failed", "sessionID", ms.session.ID, "turnID", turnID, "error", err)
+		}
+	}()
+}
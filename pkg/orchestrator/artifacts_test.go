@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+func newArtifactStream(t *testing.T, storage RecordingStorage) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.TurnArtifacts.Storage = storage
+	return newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "check my balance"}, &MockLLMProvider{completeResult: "Sure."})
+}
+
+func TestTurnArtifacts_SavesUserAudio(t *testing.T) {
+	storage := &memoryRecordings{}
+	stream := newArtifactStream(t, storage)
+	turnID := stream.beginTurn()
+	pcm := flatPCM(44100, 3000)
+
+	stream.runBatchPipeline(pcm)
+	waitForEvent(t, stream, TranscriptFinal)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := stream.orch.FlushRecordings(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	storage.mu.Lock()
+	wav, ok := storage.files[stream.session.ID+"/"+turnID+"/user.wav"]
+	storage.mu.Unlock()
+	if !ok {
+		t.Fatalf("expected the turn's audio to be saved, got %v", storage.files)
+	}
+	if got, rate, err := audio.DecodeWav(wav); err != nil || rate != 44100 || !bytes.Equal(got, pcm) {
+		t.Errorf("unexpected WAV: %d bytes at %dHz, %v", len(got), rate, err)
+	}
+}
+
+func TestTurnArtifacts_RequireConsent(t *testing.T) {
+	storage := &memoryRecordings{}
+	stream := newArtifactStream(t, storage)
+	stream.session.SetConsent(ConsentDebugAudio, false)
+
+	stream.runBatchPipeline(flatPCM(44100, 3000))
+	waitForEvent(t, stream, TranscriptFinal)
+	stream.orch.FlushRecordings(context.Background())
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if len(storage.files) != 0 {
+		t.Errorf("expected nothing saved without consent, got %d files", len(storage.files))
+	}
+}
@@ -0,0 +1,226 @@
+package orchestrator
+
+import (
+	"context"
+	"io"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// AudioFormat describes 16-bit little-endian PCM. TTS providers that return
+// compressed audio set Codec instead, "mp3" or "ogg" (Ogg Opus), and the
+// audio is decoded with audio.NewStreamDecoder; its rate and channels come
+// from the audio itself.
+type AudioFormat struct {
+	SampleRate int    `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+	Codec      string `json:"codec,omitempty"`
+}
+
+// AudioFormatProvider is implemented by providers fixed to one audio format,
+// such as an STT model that takes 16kHz audio or a TTS that streams 24kHz.
+// The orchestrator converts between it and the stream's format
+// (Config.SampleRate and Config.Channels). Providers that can follow the
+// stream's rate implement SampleRateSetter instead.
+type AudioFormatProvider interface {
+	AudioFormat() AudioFormat
+}
+
+// streamFormat is the format of the audio written to and emitted by streams.
+func (o *Orchestrator) streamFormat() AudioFormat {
+	cfg := o.GetConfig()
+	f := AudioFormat{SampleRate: cfg.SampleRate, Channels: cfg.Channels}
+	if f.SampleRate <= 0 {
+		f.SampleRate = DefaultConfig().SampleRate
+	}
+	if f.Channels <= 0 {
+		f.Channels = 1
+	}
+	return f
+}
+
+// providerFormat returns the format of a provider that needs its audio
+// converted from or to the stream's, and false when none is needed.
+func (o *Orchestrator) providerFormat(provider interface{}) (AudioFormat, bool) {
+	fp, ok := provider.(AudioFormatProvider)
+	if !ok {
+		return AudioFormat{}, false
+	}
+	f, stream := fp.AudioFormat(), o.streamFormat()
+	if f.SampleRate <= 0 {
+		f.SampleRate = stream.SampleRate
+	}
+	if f.Channels <= 0 {
+		f.Channels = 1
+	}
+	return f, f != stream
+}
+
+// sttInput converts a buffer of the caller's audio for the STT.
+func (o *Orchestrator) sttInput(pcm []byte) []byte {
+	f, ok := o.providerFormat(o.stt)
+	if !ok {
+		return pcm
+	}
+	s := o.streamFormat()
+	return audio.Convert(pcm, s.SampleRate, s.Channels, f.SampleRate, f.Channels)
+}
+
+// sttStream returns a channel converting the caller's audio for the
+// streaming STT reading from out. It closes out once closed itself.
+func (ms *ManagedStream) sttStream(ctx context.Context, out chan<- []byte) chan<- []byte {
+	f, ok := ms.orch.providerFormat(ms.orch.stt)
+	if !ok || out == nil {
+		return out
+	}
+	s := ms.orch.streamFormat()
+	conv := audio.NewConverter(s.SampleRate, s.Channels, f.SampleRate, f.Channels)
+	in := make(chan []byte, 100)
+	ms.spawn(func() {
+		defer close(out)
+		for {
+			var chunk []byte
+			select {
+			case c, ok := <-in:
+				if !ok {
+					if tail := conv.Flush(); len(tail) > 0 {
+						select {
+						case out <- tail:
+						case <-ctx.Done():
+						}
+					}
+					return
+				}
+				chunk = conv.Process(c)
+			case <-ctx.Done():
+				return
+			}
+			if len(chunk) == 0 {
+				continue
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return in
+}
+
+// convertTTS runs synth with the TTS's audio converted to the stream's
+// format on its way to onChunk. The converter's tail is passed on once synth
+// has finished.
+func (o *Orchestrator) convertTTS(onChunk func([]byte) error, synth func(onChunk func([]byte) error) error) error {
+	d, err := o.newTTSDecoder()
+	if err != nil {
+		return err
+	}
+	err = synth(func(chunk []byte) error {
+		pcm, err := d.process(chunk)
+		if err != nil || len(pcm) == 0 {
+			return err
+		}
+		return onChunk(pcm)
+	})
+	if err != nil {
+		return err
+	}
+	if tail := d.flush(); len(tail) > 0 {
+		return onChunk(tail)
+	}
+	return nil
+}
+
+// ttsAudio converts a buffer of the TTS's audio to the stream's format.
+func (o *Orchestrator) ttsAudio(data []byte) ([]byte, error) {
+	d, err := o.newTTSDecoder()
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := d.process(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(pcm, d.flush()...), nil
+}
+
+// ttsDecoder turns what the TTS sends into PCM in the stream's format. It
+// decodes compressed audio, and unwraps audio that starts with a WAV header
+// to convert it from the header's format.
+type ttsDecoder struct {
+	stream  AudioFormat
+	dec     audio.StreamDecoder
+	conv    *audio.Converter
+	head    []byte // the start of the audio, until it is known to be WAV or not
+	started bool
+}
+
+func (o *Orchestrator) newTTSDecoder() (*ttsDecoder, error) {
+	d := &ttsDecoder{stream: o.streamFormat()}
+	f, ok := o.providerFormat(o.tts)
+	switch {
+	case ok && f.Codec != "":
+		dec, err := audio.NewStreamDecoder(f.Codec)
+		if err != nil {
+			return nil, err
+		}
+		d.dec = dec
+	case ok:
+		d.convertFrom(f.SampleRate, f.Channels)
+	}
+	return d, nil
+}
+
+func (d *ttsDecoder) convertFrom(rate, channels int) {
+	d.conv = nil
+	if rate != d.stream.SampleRate || channels != d.stream.Channels {
+		d.conv = audio.NewConverter(rate, channels, d.stream.SampleRate, d.stream.Channels)
+	}
+}
+
+func (d *ttsDecoder) process(chunk []byte) ([]byte, error) {
+	switch {
+	case d.dec != nil:
+		pcm, err := d.dec.Write(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(pcm) > 0 && !d.started {
+			d.convertFrom(d.dec.Format())
+			d.started = true
+		}
+		chunk = pcm
+	case !d.started:
+		d.head = append(d.head, chunk...)
+		if n := min(len(d.head), 4); string(d.head[:n]) == "RIFF"[:n] && len(d.head) < 12 {
+			return nil, nil
+		}
+		if audio.IsWav(d.head) {
+			f, off, _, err := audio.ParseWavHeader(d.head)
+			if err == io.ErrUnexpectedEOF {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			d.convertFrom(f.SampleRate, f.Channels)
+			d.head = d.head[off:]
+		}
+		chunk, d.head, d.started = d.head, nil, true
+	}
+	if d.conv != nil {
+		chunk = d.conv.Process(chunk)
+	}
+	return chunk, nil
+}
+
+// flush returns what is held back at the end of the audio.
+func (d *ttsDecoder) flush() []byte {
+	tail := d.head
+	d.head = nil
+	if d.conv == nil {
+		return tail
+	}
+	return append(d.conv.Process(tail), d.conv.Flush()...)
+}
@@ -0,0 +1,258 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// narrowbandSTT takes 16kHz audio and records how much it gets.
+type narrowbandSTT struct {
+	MockSTTProvider
+	mu     sync.Mutex
+	got    int
+	stream chan []byte
+}
+
+func (s *narrowbandSTT) AudioFormat() AudioFormat {
+	return AudioFormat{SampleRate: 16000, Channels: 1}
+}
+
+func (s *narrowbandSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (string, error) {
+	s.mu.Lock()
+	s.got = len(audio)
+	s.mu.Unlock()
+	return "hello", nil
+}
+
+func (s *narrowbandSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	return s.stream, nil
+}
+
+// stereoTTS streams a second of 24kHz stereo.
+type stereoTTS struct {
+	MockTTSProvider
+}
+
+func (*stereoTTS) AudioFormat() AudioFormat {
+	return AudioFormat{SampleRate: 24000, Channels: 2}
+}
+
+func (*stereoTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	pcm := make([]byte, 24000*4)
+	for i := 0; i < len(pcm); i += 999 {
+		if err := onChunk(pcm[i:min(i+999, len(pcm))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*stereoTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	return make([]byte, 24000*4), nil
+}
+
+func near(got, want int) bool {
+	return got >= want-4 && got <= want+4
+}
+
+func TestAudioFormat_Conversion(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 48000
+	stt := &narrowbandSTT{}
+	o := New(stt, &MockLLMProvider{}, &stereoTTS{}, cfg)
+
+	if _, err := o.Transcribe(context.Background(), make([]byte, 48000*2), LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if !near(stt.got, 16000*2) {
+		t.Errorf("the STT got %d bytes of a second at 16kHz", stt.got)
+	}
+
+	var n int
+	err := o.SynthesizeStream(context.Background(), "hi", VoiceF1, LanguageEn, func(chunk []byte) error {
+		if len(chunk)%2 != 0 {
+			t.Errorf("got a %d-byte chunk", len(chunk))
+		}
+		n += len(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(n, 48000*2) {
+		t.Errorf("got %d bytes for a second of speech at 48kHz mono", n)
+	}
+	if pcm, _ := o.Synthesize(context.Background(), "hi", VoiceF1, LanguageEn); !near(len(pcm), 48000*2) {
+		t.Errorf("Synthesize gave %d bytes for a second at 48kHz mono", len(pcm))
+	}
+}
+
+func TestAudioFormat_StreamingSTT(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 48000
+	cfg.FirstSpeaker = FirstSpeakerUser
+	stt := &narrowbandSTT{stream: make(chan []byte, 100)}
+	orch := NewWithVAD(stt, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("narrowband"))
+	defer ms.Close()
+
+	ms.startStreamingSTT(stt)
+	ms.mu.Lock()
+	in := ms.sttChan
+	ms.mu.Unlock()
+	for range 10 {
+		in <- make([]byte, 960*2) // 20ms at 48kHz
+	}
+	ms.mu.Lock()
+	close(ms.sttChan)
+	ms.sttChan = nil
+	ms.mu.Unlock()
+
+	var got int
+	for {
+		select {
+		case chunk, ok := <-stt.stream:
+			if ok {
+				got += len(chunk)
+				continue
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the STT's channel was not closed")
+		}
+		break
+	}
+	if got != 10*320*2 {
+		t.Errorf("the STT got %d bytes of 200ms at 16kHz", got)
+	}
+}
+
+func TestAudioFormat_MatchingFormat(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	stt := &narrowbandSTT{}
+	o := New(stt, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	audio := make([]byte, 3201)
+	o.Transcribe(context.Background(), audio, LanguageEn)
+	if stt.got != len(audio) {
+		t.Errorf("audio in the STT's format was converted: %d bytes became %d", len(audio), stt.got)
+	}
+}
+
+// wavTTS answers with a WAV file holding a second of 16kHz stereo, streamed
+// in chunks smaller than its header.
+type wavTTS struct {
+	MockTTSProvider
+}
+
+func (*wavTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	wav := audio.NewWavBuffer(make([]byte, 16000*4), 16000)
+	wav[22] = 2 // channels
+	for i := 0; i < len(wav); i += 7 {
+		if err := onChunk(wav[i:min(i+7, len(wav))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*wavTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	wav := audio.NewWavBuffer(make([]byte, 16000*4), 16000)
+	wav[22] = 2
+	return wav, nil
+}
+
+func TestAudioFormat_WavTTS(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 48000
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &wavTTS{}, cfg)
+
+	var n int
+	err := o.SynthesizeStream(context.Background(), "hi", VoiceF1, LanguageEn, func(chunk []byte) error {
+		n += len(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(n, 48000*2) {
+		t.Errorf("got %d bytes for a second of speech at 48kHz mono", n)
+	}
+	if pcm, _ := o.Synthesize(context.Background(), "hi", VoiceF1, LanguageEn); !near(len(pcm), 48000*2) {
+		t.Errorf("Synthesize gave %d bytes for a second at 48kHz mono", len(pcm))
+	}
+}
+
+// oggTTS streams Ogg Opus, 250 packets that passthroughOpus decodes to
+// 2.5ms of 48kHz mono each.
+type oggTTS struct {
+	MockTTSProvider
+}
+
+func (*oggTTS) AudioFormat() AudioFormat {
+	return AudioFormat{Codec: "ogg"}
+}
+
+func (*oggTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	page := func(flags byte, packet []byte) []byte {
+		p := make([]byte, 27)
+		copy(p, "OggS")
+		p[5], p[26] = flags, 1
+		return append(append(p, byte(len(packet))), packet...)
+	}
+	head := []byte("OpusHead\x01\x01\x00\x00\x80\xbb\x00\x00\x00\x00\x00")
+	if err := onChunk(append(page(0x02, head), page(0, []byte("OpusTags"))...)); err != nil {
+		return err
+	}
+	for range 250 {
+		if err := onChunk(page(0, make([]byte, 192))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// passthroughOpus decodes any packet to 120 samples of silence.
+type passthroughOpus struct{}
+
+func (passthroughOpus) NewEncoder(sampleRate, channels int) (audio.OpusEncoder, error) {
+	return nil, audio.ErrNoOpusCodec
+}
+func (passthroughOpus) NewDecoder(sampleRate, channels int) (audio.OpusDecoder, error) {
+	return passthroughOpus{}, nil
+}
+func (passthroughOpus) Decode(packet []byte) ([]byte, error) {
+	return make([]byte, 240), nil
+}
+
+func TestAudioFormat_CompressedTTS(t *testing.T) {
+	defer func(codec audio.OpusCodec) { audio.DefaultOpusCodec = codec }(audio.DefaultOpusCodec)
+	audio.DefaultOpusCodec = passthroughOpus{}
+
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &oggTTS{}, cfg)
+
+	var n int
+	err := o.SynthesizeStream(context.Background(), "hi", VoiceF1, LanguageEn, func(chunk []byte) error {
+		n += len(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 250 packets of 120 samples at 48kHz make 30000 samples, 10000 at 16kHz.
+	if !near(n, 10000*2) {
+		t.Errorf("got %d bytes, expected %d", n, 10000*2)
+	}
+
+	audio.DefaultOpusCodec = nil
+	err = o.SynthesizeStream(context.Background(), "hi", VoiceF1, LanguageEn, func([]byte) error { return nil })
+	if !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec without a codec, got %v", err)
+	}
+}
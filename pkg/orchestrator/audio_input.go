@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// TurnAudio is the caller's speech for the turn being answered: 16-bit
+// little-endian mono PCM.
+type TurnAudio struct {
+	PCM        []byte
+	SampleRate int
+}
+
+func (a TurnAudio) Duration() time.Duration {
+	if a.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(len(a.PCM)/2) * time.Second / time.Duration(a.SampleRate)
+}
+
+// AudioLLMProvider is implemented by LLMs that can listen to the caller, so
+// tone and prosody inform the reply. The audio belongs to the last user
+// message, whose content is its transcript. AcceptsAudio reports whether the
+// configured model takes audio input at all.
+type AudioLLMProvider interface {
+	UsageLLMProvider
+	AcceptsAudio() bool
+	CompleteWithAudio(ctx context.Context, messages []Message, audio TurnAudio, params GenerationParams) (string, Usage, error)
+}
+
+// AudioInputConfig enables sending the user's audio to audio-capable LLMs
+// alongside the transcript. Turns longer than MaxDuration (30s) and LLMs
+// without audio support get the transcript only.
+type AudioInputConfig struct {
+	Enabled     bool
+	MaxDuration time.Duration
+}
+
+func (c AudioInputConfig) maxDuration() time.Duration {
+	if c.MaxDuration <= 0 {
+		return 30 * time.Second
+	}
+	return c.MaxDuration
+}
+
+// completeWithAudio sends audio along when llm can take it and falls back to
+// text otherwise.
+func (o *Orchestrator) completeWithAudio(ctx context.Context, llm LLMProvider, messages []Message, params GenerationParams, audio *TurnAudio) (string, Usage, error) {
+	if audio != nil && len(audio.PCM) > 0 {
+		if p, ok := llm.(AudioLLMProvider); ok && p.AcceptsAudio() && audio.Duration() <= o.GetConfig().AudioInput.maxDuration() {
+			return p.CompleteWithAudio(ctx, messages, *audio, params)
+		}
+	}
+	return o.completeWith(ctx, llm, messages, params)
+}
+
+// turnAudio returns the audio of the user's last utterance when
+// Config.AudioInput is enabled.
+func (ms *ManagedStream) turnAudio() *TurnAudio {
+	if ms.orch == nil {
+		return nil
+	}
+	cfg := ms.orch.GetConfig()
+	if !cfg.AudioInput.Enabled {
+		return nil
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.lastUserAudio) == 0 {
+		return nil
+	}
+	rate := ms.inputRate
+	if rate <= 0 {
+		rate = cfg.SampleRate
+	}
+	return &TurnAudio{PCM: append([]byte(nil), ms.lastUserAudio...), SampleRate: rate}
+}
@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type mockAudioLLM struct {
+	MockLLMProvider
+	accepts bool
+	audio   *TurnAudio
+}
+
+func (m *mockAudioLLM) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	return m.completeResult, nil
+}
+
+func (m *mockAudioLLM) CompleteWithUsage(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error) {
+	return m.completeResult, Usage{}, nil
+}
+
+func (m *mockAudioLLM) AcceptsAudio() bool { return m.accepts }
+
+func (m *mockAudioLLM) CompleteWithAudio(ctx context.Context, messages []Message, audio TurnAudio, params GenerationParams) (string, Usage, error) {
+	m.audio = &audio
+	return m.completeResult, Usage{}, nil
+}
+
+func audioInputStream(t *testing.T, llm LLMProvider, audio AudioInputConfig) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.AudioInput = audio
+	ms := newTestStream(t, cfg, &MockSTTProvider{}, llm)
+	ms.SetEchoSampleRates(24000, 16000)
+	ms.mu.Lock()
+	ms.lastUserAudio = make([]byte, 32000) // 1s at 16kHz
+	ms.mu.Unlock()
+	ms.session.AddMessage("user", "fine, I guess")
+	return ms
+}
+
+func TestManagedStream_SendsTurnAudio(t *testing.T) {
+	llm := &mockAudioLLM{MockLLMProvider: MockLLMProvider{completeResult: "ok"}, accepts: true}
+	ms := audioInputStream(t, llm, AudioInputConfig{Enabled: true})
+
+	ms.runLLMAndTTS(ms.ctx, "fine, I guess")
+	if llm.audio == nil {
+		t.Fatal("audio was not sent")
+	}
+	if llm.audio.SampleRate != 16000 || len(llm.audio.PCM) != 32000 || llm.audio.Duration() != time.Second {
+		t.Errorf("unexpected audio: rate %d, %d bytes", llm.audio.SampleRate, len(llm.audio.PCM))
+	}
+}
+
+func TestManagedStream_TurnAudioFallsBackToText(t *testing.T) {
+	cases := map[string]struct {
+		accepts bool
+		cfg     AudioInputConfig
+	}{
+		"disabled":         {accepts: true},
+		"model is text":    {cfg: AudioInputConfig{Enabled: true}},
+		"turn is too long": {accepts: true, cfg: AudioInputConfig{Enabled: true, MaxDuration: 500 * time.Millisecond}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			llm := &mockAudioLLM{MockLLMProvider: MockLLMProvider{completeResult: "ok"}, accepts: tc.accepts}
+			ms := audioInputStream(t, llm, tc.cfg)
+
+			ms.runLLMAndTTS(ms.ctx, "fine, I guess")
+			if llm.audio != nil {
+				t.Error("audio must not be sent")
+			}
+			if ms.session.LastAssistant != "ok" {
+				t.Errorf("text reply missing, got %q", ms.session.LastAssistant)
+			}
+		})
+	}
+}
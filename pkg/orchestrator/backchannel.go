@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const defaultBackchannelWords = 3
+
+// DefaultBackchannels are the acknowledgements BackchannelConfig recognizes
+// when Phrases is nil.
+var DefaultBackchannels = []string{
+	"mm-hmm", "mhm", "mm", "hmm", "uh-huh", "ok", "okay", "alright", "right",
+	"yeah", "yep", "yes", "sure", "cool", "great", "i see", "got it",
+}
+
+// BackchannelConfig recognizes short acknowledgements ("mm-hmm", "ok",
+// "right") the caller makes while the bot is talking. They neither interrupt
+// the bot nor go into the LLM context; a Backchannel event reports them
+// instead. A transcript of at most MaxWords words (3 by default) is one if it
+// matches Pattern or is made up of Phrases (DefaultBackchannels when nil).
+//
+// Without BargeIn.TwoStage the VAD cuts the bot off before the words are
+// known; set BargeIn.ResumeAfterFalse too, so the reply picks back up.
+type BackchannelConfig struct {
+	Enabled  bool
+	Phrases  []string
+	Pattern  *regexp.Regexp
+	MaxWords int
+}
+
+func (c BackchannelConfig) matches(transcript string) bool {
+	words := backchannelWords(transcript)
+	maxWords := c.MaxWords
+	if maxWords <= 0 {
+		maxWords = defaultBackchannelWords
+	}
+	if len(words) == 0 || len(words) > maxWords {
+		return false
+	}
+	if c.Pattern != nil && c.Pattern.MatchString(strings.TrimSpace(transcript)) {
+		return true
+	}
+
+	phrases := c.Phrases
+	if phrases == nil {
+		phrases = DefaultBackchannels
+	}
+	known := make(map[string]bool, len(phrases))
+	for _, p := range phrases {
+		known[strings.Join(backchannelWords(p), " ")] = true
+	}
+	if known[strings.Join(words, " ")] {
+		return true
+	}
+	// Repeated or combined acknowledgements: "yeah yeah", "ok, right".
+	for _, w := range words {
+		if !known[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// backchannelWords lowercases s and splits it on anything but letters,
+// digits and apostrophes, so "Mm-hmm." and "mm hmm" compare equal.
+func backchannelWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// isBackchannel reports whether transcript acknowledges the bot: the bot is
+// talking, or was just cut off by this utterance, and it is one of the
+// configured phrases.
+func (ms *ManagedStream) isBackchannel(transcript string) bool {
+	if ms.orch == nil {
+		return false
+	}
+	cfg := ms.orch.GetConfig().Backchannel
+	if !cfg.Enabled {
+		return false
+	}
+	ms.mu.Lock()
+	talking := ms.isSpeaking || ms.resumable != nil
+	ms.mu.Unlock()
+	return talking && cfg.matches(transcript)
+}
+
+// backchannel reports an acknowledgement instead of answering it, and lets
+// the bot carry on: a ducked reply is restored and a cut-off one resumed.
+func (ms *ManagedStream) backchannel(transcript, turnID string) {
+	ms.mu.Lock()
+	ms.stats.Backchannels++
+	ducked, seq := ms.ducked, ms.duckSeq
+	ms.mu.Unlock()
+
+	ms.emitForTurn(Backchannel, transcript, turnID)
+	if ducked {
+		ms.restoreDuck(seq)
+	}
+	ms.falseBargeIn()
+}
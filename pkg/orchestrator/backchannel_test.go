@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestBackchannelConfig_Matches(t *testing.T) {
+	cfg := BackchannelConfig{Enabled: true}
+	cases := map[string]bool{
+		"Mm-hmm.":          true,
+		"mm hmm":           true,
+		"Okay":             true,
+		"yeah yeah":        true,
+		"Ok, right.":       true,
+		"I see.":           true,
+		"":                 false,
+		"yeah but wait":    false,
+		"ok stop":          false,
+		"yes yes yes yes":  false,
+		"what's the price": false,
+	}
+	for transcript, want := range cases {
+		if got := cfg.matches(transcript); got != want {
+			t.Errorf("matches(%q) = %v, want %v", transcript, got, want)
+		}
+	}
+
+	custom := BackchannelConfig{Phrases: []string{"vale"}, Pattern: regexp.MustCompile(`(?i)^a+h+\W*$`)}
+	if !custom.matches("Vale.") || !custom.matches("aah") || custom.matches("okay") {
+		t.Error("expected custom phrases and pattern to replace the defaults")
+	}
+}
+
+func TestBackchannel_StreamingDoesNotInterrupt(t *testing.T) {
+	stt := &MockStreamingSTT{steps: []struct {
+		text    string
+		isFinal bool
+		delay   time.Duration
+	}{
+		{text: "mm", isFinal: false, delay: 20 * time.Millisecond},
+		{text: "Mm-hmm.", isFinal: true, delay: 20 * time.Millisecond},
+	}}
+	cfg := DefaultConfig()
+	cfg.Backchannel.Enabled = true
+	orch := NewWithVAD(stt, &MockLLMProvider{completeResult: "ok"}, &MockTTSProvider{}, NewRMSVAD(0.1, 50*time.Millisecond), cfg)
+	session := NewConversationSession("ack")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	stream.mu.Lock()
+	stream.isSpeaking = true
+	stream.mu.Unlock()
+	stream.startStreamingSTT(stt)
+
+	if ev := waitForEvent(t, stream, Backchannel); ev.Data != "Mm-hmm." {
+		t.Errorf("unexpected backchannel %v", ev.Data)
+	}
+	if !stream.isSpeaking {
+		t.Error("a backchannel must not interrupt the bot")
+	}
+	for _, m := range session.GetContextCopy() {
+		if m.Role == "user" {
+			t.Errorf("a backchannel must not reach the LLM context: %+v", m)
+		}
+	}
+	if n := stream.Status().Stats.Backchannels; n != 1 {
+		t.Errorf("expected 1 backchannel counted, got %d", n)
+	}
+}
+
+func TestBackchannel_BatchWhileIdleIsAnswered(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Backchannel.Enabled = true
+	stream := newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "Okay."}, &MockLLMProvider{completeResult: "Great."})
+
+	stream.mu.Lock()
+	stream.isSpeaking = true
+	stream.mu.Unlock()
+	stream.runBatchPipeline(make([]byte, 32000))
+	waitForEvent(t, stream, Backchannel)
+
+	// With the bot quiet, "okay" is an answer.
+	stream.mu.Lock()
+	stream.isSpeaking = false
+	stream.mu.Unlock()
+	stream.runBatchPipeline(make([]byte, 32000))
+	if ev := waitForEvent(t, stream, TranscriptFinal); ev.Data != "Okay." {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import "time"
+
+const (
+	defaultDuckGain      = 0.25
+	defaultConfirmWindow = 800 * time.Millisecond
+)
+
+// BargeInConfig enables two-stage barge-in. With TwoStage, speech the local
+// VAD detects while the bot is talking only emits AudioDucked, asking playback
+// to drop to DuckGain (0.25 by default); nothing is cancelled yet. The
+// interruption is committed once STT produces a transcript that passes
+// MinWordsToInterrupt and the noise filter. If none arrives within
+// ConfirmWindow (800ms by default) the volume is restored and AudioRestored
+// is emitted, so coughs and background voices cost a dip instead of a reply.
+//
+// ResumeAfterFalse picks a reply back up after a barge-in that was nothing:
+// if the caller's utterance yields no words, or only noise, within
+// ResumeWindow (5s by default) of the interruption, ResponseResumed is
+// emitted and the rest of the reply is spoken.
+//
+// MergeCanceled answers an utterance that barged in while the LLM was still
+// generating together with the question it cut off, as one user message,
+// instead of leaving that question unanswered.
+type BargeInConfig struct {
+	TwoStage      bool
+	DuckGain      float64
+	ConfirmWindow time.Duration
+
+	ResumeAfterFalse bool
+	ResumeWindow     time.Duration
+
+	MergeCanceled bool
+}
+
+// DuckInfo is the data of an AudioDucked event. Most of a reply is already
+// buffered for playback when the caller starts talking, so ducking happens
+// where it is played: scale unplayed audio by Gain until AudioRestored, or
+// drop it on Interrupted. The built-in transports do this themselves.
+type DuckInfo struct {
+	Gain float64 `json:"gain"`
+}
+
+func (c BargeInConfig) gain() float64 {
+	if c.DuckGain <= 0 || c.DuckGain > 1 {
+		return defaultDuckGain
+	}
+	return c.DuckGain
+}
+
+// armBargeIn ducks the bot instead of interrupting it. It reports false when
+// two-stage barge-in is off or the bot is idle, and the caller should
+// interrupt as usual.
+func (ms *ManagedStream) armBargeIn() bool {
+	if ms.orch == nil {
+		return false
+	}
+	cfg := ms.orch.GetConfig().BargeIn
+	if !cfg.TwoStage {
+		return false
+	}
+	window := cfg.ConfirmWindow
+	if window <= 0 {
+		window = defaultConfirmWindow
+	}
+
+	ms.mu.Lock()
+	active := ms.isSpeaking || ms.isThinking || time.Since(ms.lastAudioSentAt) < time.Second
+	if !active {
+		ms.mu.Unlock()
+		return false
+	}
+	if ms.ducked {
+		ms.mu.Unlock()
+		return true
+	}
+	ms.ducked = true
+	ms.duckSeq++
+	seq := ms.duckSeq
+	ms.duckTimer = time.AfterFunc(window, func() { ms.restoreDuck(seq) })
+	ms.stats.BargeInsArmed++
+	ms.mu.Unlock()
+
+	ms.emit(AudioDucked, DuckInfo{Gain: cfg.gain()})
+	return true
+}
+
+// restoreDuck ends an armed barge-in that STT never confirmed.
+func (ms *ManagedStream) restoreDuck(seq int) {
+	ms.mu.Lock()
+	if !ms.ducked || ms.duckSeq != seq {
+		ms.mu.Unlock()
+		return
+	}
+	ms.ducked = false
+	ms.duckTimer = nil
+	ms.stats.BargeInsRestored++
+	ms.mu.Unlock()
+
+	ms.emit(AudioRestored, nil)
+}
+
+// clearDuckLocked drops an armed barge-in once it is committed. Callers hold
+// ms.mu.
+func (ms *ManagedStream) clearDuckLocked() {
+	if ms.duckTimer != nil {
+		ms.duckTimer.Stop()
+		ms.duckTimer = nil
+	}
+	ms.ducked = false
+}
@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedSTT hands its transcript callback to the test.
+type scriptedSTT struct {
+	mu           sync.Mutex
+	onTranscript func(string, bool) error
+}
+
+func (s *scriptedSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (string, error) {
+	return "", nil
+}
+func (s *scriptedSTT) Name() string { return "scripted" }
+func (s *scriptedSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	s.mu.Lock()
+	s.onTranscript = onTranscript
+	s.mu.Unlock()
+	ch := make(chan []byte, 64)
+	go func() {
+		for range ch {
+		}
+	}()
+	return ch, nil
+}
+
+func (s *scriptedSTT) say(transcript string, final bool) {
+	s.mu.Lock()
+	fn := s.onTranscript
+	s.mu.Unlock()
+	fn(transcript, final)
+}
+
+func newBargeInStream(t *testing.T, stt STTProvider, window time.Duration) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.BargeIn = BargeInConfig{TwoStage: true, DuckGain: 0.2, ConfirmWindow: window}
+	orch := NewWithVAD(stt, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.01, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("barge"))
+	t.Cleanup(ms.Close)
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.lastAudioSentAt = time.Now()
+	ms.mu.Unlock()
+
+	for i := 0; i < 3; i++ {
+		ms.doWrite(toneChunk(6553))
+	}
+	return ms
+}
+
+func waitForEvent(t *testing.T, ms *ManagedStream, want EventType) OrchestratorEvent {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == want {
+				return ev
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestBargeIn_DucksThenRestores(t *testing.T) {
+	ms := newBargeInStream(t, &scriptedSTT{}, 50*time.Millisecond)
+
+	ev := waitForEvent(t, ms, AudioDucked)
+	if duck := ev.Data.(DuckInfo); duck.Gain != 0.2 {
+		t.Errorf("expected gain 0.2, got %v", duck.Gain)
+	}
+	if !ms.isSpeaking {
+		t.Fatal("local VAD alone must not interrupt the bot")
+	}
+
+	waitForEvent(t, ms, AudioRestored)
+	stats := ms.Status().Stats
+	if stats.BargeInsArmed != 1 || stats.BargeInsRestored != 1 || stats.Interruptions != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestBargeIn_STTCommits(t *testing.T) {
+	stt := &scriptedSTT{}
+	ms := newBargeInStream(t, stt, time.Second)
+	waitForEvent(t, ms, AudioDucked)
+
+	time.Sleep(120 * time.Millisecond) // long enough not to count as noise
+	stt.say("hold on", false)
+	waitForEvent(t, ms, Interrupted)
+
+	ms.mu.Lock()
+	ducked, speaking := ms.ducked, ms.isSpeaking
+	ms.mu.Unlock()
+	if ducked || speaking {
+		t.Errorf("expected a committed interruption, ducked=%v speaking=%v", ducked, speaking)
+	}
+	if ms.Status().Stats.BargeInsRestored != 0 {
+		t.Error("a confirmed barge-in must not be restored")
+	}
+}
+
+func TestBargeIn_DisabledInterruptsImmediately(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(&scriptedSTT{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.01, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("barge"))
+	defer ms.Close()
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.lastAudioSentAt = time.Now()
+	ms.mu.Unlock()
+	for i := 0; i < 3; i++ {
+		ms.doWrite(toneChunk(6553))
+	}
+	waitForEvent(t, ms, Interrupted)
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrBlobNotFound = errors.New("blob not found in store")
+
+// BlobStore holds named objects: recordings, turn artifacts and session
+// exports, so none of them has to live on local disk. Names are
+// slash-separated. store.RecordingDir, store.S3Storage and store.GCSStorage
+// implement it. Get returns ErrBlobNotFound for unknown names; Delete does
+// not fail for them.
+type BlobStore interface {
+	RecordingStorage
+	Get(ctx context.Context, name string) ([]byte, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// ExportTo stores the session's Export as name, sealed with encryptor when
+// set.
+func (s *ConversationSession) ExportTo(ctx context.Context, store RecordingStorage, name string, encryptor Encryptor) error {
+	data, err := s.Export()
+	if err != nil {
+		return err
+	}
+	if data, err = SealIfConfigured(ctx, encryptor, data); err != nil {
+		return err
+	}
+	return store.Put(ctx, name, data)
+}
+
+// ImportSessionFrom restores a session stored by ExportTo.
+func ImportSessionFrom(ctx context.Context, store BlobStore, name string, encryptor Encryptor) (*ConversationSession, error) {
+	data, err := store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = OpenIfConfigured(ctx, encryptor, data); err != nil {
+		return nil, err
+	}
+	return ImportSession(data)
+}
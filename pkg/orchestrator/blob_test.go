@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// memoryBlobs is a BlobStore in memory.
+type memoryBlobs struct{ memoryRecordings }
+
+func (m *memoryBlobs) Get(ctx context.Context, name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryBlobs) Delete(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, name)
+	return nil
+}
+
+func TestSession_ExportToBlobStore(t *testing.T) {
+	key := make([]byte, 32)
+	enc, err := NewAESGCMEncryptorFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := &memoryBlobs{}
+	ctx := context.Background()
+	s := NewConversationSession("call-1")
+	s.AddMessage("user", "hi")
+
+	if err := s.ExportTo(ctx, blobs, "exports/call-1.json", enc); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := blobs.Get(ctx, "exports/call-1.json"); !IsEncrypted(stored) {
+		t.Error("expected the export to be sealed")
+	}
+	got, err := ImportSessionFrom(ctx, blobs, "exports/call-1.json", enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "call-1" || len(got.Transcript()) != 1 {
+		t.Errorf("unexpected import: %+v", got.Snapshot())
+	}
+
+	if _, err := ImportSessionFrom(ctx, blobs, "exports/missing.json", enc); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+}
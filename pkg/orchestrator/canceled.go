@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// CanceledGeneration is the Data of a GenerationCanceled event: an LLM call
+// the caller barged in on before it finished. Partial is what the provider
+// returned so far, if anything. Usage is what the provider reported, or an
+// estimate of the prompt with Estimated set, since the prompt is billed
+// either way.
+type CanceledGeneration struct {
+	TurnID     string        `json:"turn_id"`
+	Transcript string        `json:"transcript"`
+	Partial    string        `json:"partial,omitempty"`
+	Usage      Usage         `json:"usage"`
+	Estimated  bool          `json:"estimated,omitempty"`
+	Elapsed    time.Duration `json:"elapsed"`
+}
+
+// canceledError carries what a canceled LLM call produced and cost. A
+// UsageLLMProvider may return the text and usage it had so far along with
+// the context's error.
+type canceledError struct {
+	err       error
+	partial   string
+	usage     Usage
+	estimated bool
+}
+
+func (e *canceledError) Error() string { return e.err.Error() }
+func (e *canceledError) Unwrap() error { return e.err }
+
+// canceledLLM records the usage of an LLM call canceled mid-generation as
+// spent, and wraps err with what the call produced.
+func (o *Orchestrator) canceledLLM(session *ConversationSession, llm LLMProvider, messages []Message, partial string, u Usage, err error) error {
+	ce := &canceledError{err: err, partial: partial, usage: u}
+	if u.Total() == 0 {
+		ce.usage.PromptTokens = EstimateTokens(messages)
+		ce.estimated = true
+	}
+	cost := 0.0
+	if tracker := o.CostTracker(); tracker != nil {
+		cost = tracker.recordCanceled(session.ID, llm.Name(), ce.usage)
+	}
+	session.recordCanceledUsage(ce.usage, cost)
+	return ce
+}
+
+// canceledGeneration reports a reply the caller barged in on while the LLM
+// was still generating it.
+func (ms *ManagedStream) canceledGeneration(err error, turnID, transcript string) {
+	var ce *canceledError
+	if !errors.As(err, &ce) {
+		return
+	}
+	ms.mu.Lock()
+	canceled := CanceledGeneration{
+		TurnID:     turnID,
+		Transcript: transcript,
+		Partial:    ce.partial,
+		Usage:      ce.usage,
+		Estimated:  ce.estimated,
+		Elapsed:    time.Since(ms.llmStartTime),
+	}
+	ms.stats.GenerationsCanceled++
+	if t, ok := ms.turns[turnID]; ok {
+		t.Canceled = &canceled
+	}
+	ms.mu.Unlock()
+	ms.emitForTurn(GenerationCanceled, canceled, turnID)
+}
+
+// mergeCanceled folds transcript into the user message whose reply was
+// canceled by this barge-in, with BargeIn.MergeCanceled, so "actually,
+// also…" is answered together with the question it amends. It returns the
+// merged text.
+func (ms *ManagedStream) mergeCanceled(transcript string) (string, bool) {
+	ms.mu.Lock()
+	unanswered := ms.unanswered
+	ms.unanswered = ""
+	ms.mu.Unlock()
+	if unanswered == "" || ms.orch == nil || !ms.orch.GetConfig().BargeIn.MergeCanceled {
+		return "", false
+	}
+	merged := strings.TrimSpace(unanswered) + " " + strings.TrimSpace(transcript)
+	if !ms.session.mergeLastUser(unanswered, transcript, merged) {
+		return "", false
+	}
+	return merged, true
+}
+
+// mergeLastUser replaces the last message, if it is the user's old one, with
+// merged. The transcript still records addition as said.
+func (s *ConversationSession) mergeLastUser(old, addition, merged string) bool {
+	s.mu.Lock()
+	n := len(s.Context)
+	if n == 0 || s.Context[n-1].Role != "user" || s.Context[n-1].Content != old {
+		s.mu.Unlock()
+		return false
+	}
+	s.Context[n-1].Content = merged
+	s.LastUser = merged
+	if !s.consentDenied[ConsentTranscript] {
+		s.appendTranscriptLocked(TranscriptEntry{Role: "user", Content: addition, At: time.Now(), Language: s.CurrentLanguage, Speaker: s.Context[n-1].Speaker})
+	}
+	s.mu.Unlock()
+	s.changed()
+	return true
+}
@@ -0,0 +1,146 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cancelableLLM keeps its first call running until it is canceled, then
+// returns "Let me" and, with usage, the tokens spent. Later calls answer.
+type cancelableLLM struct {
+	usage   bool
+	started chan struct{}
+
+	mu       sync.Mutex
+	calls    int
+	messages []Message // of the last call
+}
+
+func (l *cancelableLLM) CompleteWithUsage(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error) {
+	l.mu.Lock()
+	l.calls++
+	first := l.calls == 1
+	l.messages = messages
+	l.mu.Unlock()
+	if !first {
+		return "Sunny in both.", Usage{PromptTokens: 50, CompletionTokens: 4}, nil
+	}
+	close(l.started)
+	<-ctx.Done()
+	if !l.usage {
+		return "", Usage{}, ctx.Err()
+	}
+	return "Let me", Usage{PromptTokens: 40, CompletionTokens: 3}, ctx.Err()
+}
+
+func (l *cancelableLLM) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *cancelableLLM) Complete(ctx context.Context, messages []Message) (string, error) {
+	return l.CompleteWithParams(ctx, messages, GenerationParams{})
+}
+
+func (l *cancelableLLM) Name() string { return "cancelable" }
+
+func canceledStream(t *testing.T, llm *cancelableLLM, merge bool) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.BargeIn.MergeCanceled = merge
+	ms := newTestStream(t, cfg, &MockSTTProvider{}, llm)
+
+	if err := ms.InjectUserText("what's the weather in Paris?"); err != nil {
+		t.Fatal(err)
+	}
+	<-llm.started
+	if err := ms.InjectUserText("actually, also in London"); err != nil {
+		t.Fatal(err)
+	}
+	return ms
+}
+
+func TestCanceledGeneration_Accounting(t *testing.T) {
+	llm := &cancelableLLM{usage: true, started: make(chan struct{})}
+	ms := canceledStream(t, llm, false)
+
+	// The canceled call may report after the next reply is out.
+	var ev OrchestratorEvent
+	var responded bool
+	deadline := time.After(time.Second)
+	for ev.Type == "" || !responded {
+		select {
+		case e := <-ms.Events():
+			switch e.Type {
+			case GenerationCanceled:
+				ev = e
+			case BotResponse:
+				responded = true
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for the canceled generation and the reply")
+		}
+	}
+	canceled := ev.Data.(CanceledGeneration)
+	if canceled.Transcript != "what's the weather in Paris?" || canceled.Partial != "Let me" || canceled.Usage.Total() != 43 || canceled.Estimated {
+		t.Errorf("unexpected canceled generation %+v", canceled)
+	}
+	if turn, _ := ms.GetTurn(ev.TurnID); turn.Canceled == nil || turn.Canceled.Partial != "Let me" {
+		t.Errorf("expected the turn to be marked canceled, got %+v", turn)
+	}
+
+	usage := ms.session.GetUsage()
+	if usage.Turns != 1 || usage.CanceledTurns != 1 || usage.CanceledTokens != 43 || usage.TotalTokens != 97 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if stats := ms.Status().Stats; stats.GenerationsCanceled != 1 {
+		t.Errorf("expected one canceled generation, got %d", stats.GenerationsCanceled)
+	}
+
+	// Without merging, the question stays on its own.
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	var users int
+	for _, msg := range llm.messages {
+		if msg.Role == "user" {
+			users++
+		}
+	}
+	if users != 2 {
+		t.Errorf("expected both user messages, got %+v", llm.messages)
+	}
+}
+
+func TestCanceledGeneration_EstimatesPrompt(t *testing.T) {
+	llm := &cancelableLLM{started: make(chan struct{})}
+	ms := canceledStream(t, llm, false)
+
+	canceled := waitForEvent(t, ms, GenerationCanceled).Data.(CanceledGeneration)
+	if !canceled.Estimated || canceled.Usage.PromptTokens == 0 || canceled.Usage.CompletionTokens != 0 {
+		t.Errorf("expected an estimate of the prompt, got %+v", canceled)
+	}
+}
+
+func TestCanceledGeneration_Merge(t *testing.T) {
+	llm := &cancelableLLM{started: make(chan struct{})}
+	ms := canceledStream(t, llm, true)
+
+	response := waitForEvent(t, ms, BotResponse)
+	if response.Data != "Sunny in both." {
+		t.Fatalf("unexpected response %v", response.Data)
+	}
+	llm.mu.Lock()
+	last := llm.messages[len(llm.messages)-1]
+	llm.mu.Unlock()
+	if last.Role != "user" || last.Content != "what's the weather in Paris? actually, also in London" {
+		t.Errorf("expected the merged question, got %+v", last)
+	}
+	msgs := ms.session.GetContextCopy()
+	if len(msgs) != 2 || msgs[0].Content != last.Content {
+		t.Errorf("unexpected context %+v", msgs)
+	}
+	if transcript := ms.session.Transcript(); len(transcript) != 3 || transcript[1].Content != "actually, also in London" {
+		t.Errorf("expected the transcript to keep both utterances, got %+v", transcript)
+	}
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import "time"
+
+// CapacityConfig limits how many managed streams run at once. Streams over
+// the limit wait in a FIFO queue for up to QueueTimeout and are told so with
+// a ServerBusy event; audio written while queued is dropped.
+type CapacityConfig struct {
+	// MaxSessions is the number of concurrently admitted streams. Zero
+	// means unlimited.
+	MaxSessions int
+	// QueueTimeout is how long a stream waits for a slot. Zero rejects
+	// immediately when full.
+	QueueTimeout time.Duration
+	// MaxQueue bounds the number of waiting streams. Zero means unbounded.
+	MaxQueue int
+}
+
+type ServerBusyStatus string
+
+const (
+	ServerBusyQueued   ServerBusyStatus = "queued"
+	ServerBusyAdmitted ServerBusyStatus = "admitted"
+	ServerBusyRejected ServerBusyStatus = "rejected"
+)
+
+type ServerBusyInfo struct {
+	Status   ServerBusyStatus `json:"status"`
+	Position int              `json:"position,omitempty"`
+	Active   int              `json:"active"`
+	Max      int              `json:"max"`
+}
+
+type CapacityStatus struct {
+	MaxSessions int `json:"max_sessions"`
+	Active      int `json:"active"`
+	Queued      int `json:"queued"`
+}
+
+type slotWaiter struct {
+	priority Priority
+	granted  chan struct{}
+}
+
+// Capacity reports admitted and queued streams against the configured limit.
+func (o *Orchestrator) Capacity() CapacityStatus {
+	o.capMu.Lock()
+	defer o.capMu.Unlock()
+	return CapacityStatus{
+		MaxSessions: o.GetConfig().Capacity.MaxSessions,
+		Active:      o.capActive,
+		Queued:      len(o.capQueue),
+	}
+}
+
+// reserveSlot admits a stream immediately (nil waiter), queues it (waiter and
+// 1-based position) or fails with ErrServerBusy. Higher priorities queue
+// ahead of lower ones.
+func (o *Orchestrator) reserveSlot(priority Priority) (*slotWaiter, int, error) {
+	cfg := o.GetConfig().Capacity
+	o.capMu.Lock()
+	defer o.capMu.Unlock()
+
+	if cfg.MaxSessions <= 0 || (o.capActive < cfg.MaxSessions && len(o.capQueue) == 0) {
+		o.capActive++
+		return nil, 0, nil
+	}
+	if cfg.QueueTimeout <= 0 || (cfg.MaxQueue > 0 && len(o.capQueue) >= cfg.MaxQueue) {
+		return nil, 0, ErrServerBusy
+	}
+	w := &slotWaiter{priority: priority, granted: make(chan struct{})}
+	i := len(o.capQueue)
+	for i > 0 && o.capQueue[i-1].priority < priority {
+		i--
+	}
+	o.capQueue = append(o.capQueue, nil)
+	copy(o.capQueue[i+1:], o.capQueue[i:])
+	o.capQueue[i] = w
+	return w, i + 1, nil
+}
+
+// releaseSlot hands the slot to the oldest waiter, or frees it.
+func (o *Orchestrator) releaseSlot() {
+	o.capMu.Lock()
+	defer o.capMu.Unlock()
+	if len(o.capQueue) > 0 && o.capActive <= o.GetConfig().Capacity.MaxSessions {
+		w := o.capQueue[0]
+		o.capQueue = o.capQueue[1:]
+		close(w.granted)
+		return
+	}
+	if o.capActive > 0 {
+		o.capActive--
+	}
+}
+
+// abandonSlot removes w from the queue. It returns false when the slot was
+// granted in the meantime, in which case the caller owns it.
+func (o *Orchestrator) abandonSlot(w *slotWaiter) bool {
+	o.capMu.Lock()
+	defer o.capMu.Unlock()
+	for i, queued := range o.capQueue {
+		if queued == w {
+			o.capQueue = append(o.capQueue[:i], o.capQueue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Orchestrator) capacityInfo(status ServerBusyStatus, position int) ServerBusyInfo {
+	c := o.Capacity()
+	return ServerBusyInfo{Status: status, Position: position, Active: c.Active, Max: c.MaxSessions}
+}
+
+func (ms *ManagedStream) awaitSlot(w *slotWaiter, timeout time.Duration, greet bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.granted:
+	case <-timer.C:
+		if ms.orch.abandonSlot(w) {
+			ms.emit(ServerBusy, ms.orch.capacityInfo(ServerBusyRejected, 0))
+			ms.Close()
+			return
+		}
+	case <-ms.ctx.Done():
+		if !ms.orch.abandonSlot(w) {
+			ms.orch.releaseSlot()
+		}
+		return
+	}
+
+	ms.mu.Lock()
+	if ms.isClosed {
+		ms.mu.Unlock()
+		ms.orch.releaseSlot()
+		return
+	}
+	ms.queued = false
+	ms.holdsSlot = true
+	ms.mu.Unlock()
+
+	ms.emit(ServerBusy, ms.orch.capacityInfo(ServerBusyAdmitted, 0))
+	if greet {
+		ms.greet()
+	}
+}
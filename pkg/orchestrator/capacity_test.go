@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func capacityOrchestrator(capacity CapacityConfig) *Orchestrator {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Capacity = capacity
+	return NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 0), cfg)
+}
+
+func nextBusy(t *testing.T, stream *ManagedStream) ServerBusyInfo {
+	t.Helper()
+	select {
+	case ev, ok := <-stream.Events():
+		if !ok {
+			t.Fatal("events closed before SERVER_BUSY")
+		}
+		info, isBusy := ev.Data.(ServerBusyInfo)
+		if ev.Type != ServerBusy || !isBusy {
+			t.Fatalf("expected SERVER_BUSY, got %v", ev.Type)
+		}
+		return info
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for SERVER_BUSY")
+	}
+	return ServerBusyInfo{}
+}
+
+func TestCapacity_RejectsWhenFull(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 1})
+
+	first := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+	second := orch.NewManagedStream(context.Background(), NewConversationSession("b"))
+
+	if info := nextBusy(t, second); info.Status != ServerBusyRejected || info.Max != 1 || info.Active != 1 {
+		t.Errorf("unexpected busy info %+v", info)
+	}
+	if second.State() != StreamClosed {
+		t.Errorf("expected rejected stream to be closed, got %s", second.State())
+	}
+
+	first.Close()
+	if c := orch.Capacity(); c.Active != 0 {
+		t.Errorf("expected slot to be released, got %+v", c)
+	}
+	third := orch.NewManagedStream(context.Background(), NewConversationSession("c"))
+	defer third.Close()
+	if third.State() == StreamClosed {
+		t.Error("expected a freed slot to admit the next stream")
+	}
+}
+
+func TestCapacity_QueuesUntilSlotFrees(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 1, QueueTimeout: 5 * time.Second})
+
+	first := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+	second := orch.NewManagedStream(context.Background(), NewConversationSession("b"))
+	defer second.Close()
+
+	if info := nextBusy(t, second); info.Status != ServerBusyQueued || info.Position != 1 {
+		t.Errorf("expected queued at position 1, got %+v", info)
+	}
+	if second.State() != StreamQueued {
+		t.Errorf("expected queued state, got %s", second.State())
+	}
+
+	second.Write(make([]byte, 882))
+	deadline := time.Now().Add(time.Second)
+	for second.Status().Stats.AudioChunksDropped == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if second.Status().Stats.AudioChunksDropped != 1 {
+		t.Error("expected audio to be dropped while queued")
+	}
+
+	first.Close()
+	if info := nextBusy(t, second); info.Status != ServerBusyAdmitted {
+		t.Errorf("expected admission, got %+v", info)
+	}
+	if c := orch.Capacity(); c.Active != 1 || c.Queued != 0 {
+		t.Errorf("expected the slot to move to the queued stream, got %+v", c)
+	}
+}
+
+func TestCapacity_QueueTimeout(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 1, QueueTimeout: 30 * time.Millisecond})
+
+	first := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+	defer first.Close()
+	second := orch.NewManagedStream(context.Background(), NewConversationSession("b"))
+
+	nextBusy(t, second)
+	if info := nextBusy(t, second); info.Status != ServerBusyRejected {
+		t.Errorf("expected rejection after the queue timeout, got %+v", info)
+	}
+	if c := orch.Capacity(); c.Queued != 0 || c.Active != 1 {
+		t.Errorf("unexpected capacity %+v", c)
+	}
+}
+
+func TestCapacity_MaxQueue(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 1, QueueTimeout: time.Second, MaxQueue: 1})
+
+	first := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+	defer first.Close()
+	queued := orch.NewManagedStream(context.Background(), NewConversationSession("b"))
+	defer queued.Close()
+	overflow := orch.NewManagedStream(context.Background(), NewConversationSession("c"))
+
+	if info := nextBusy(t, overflow); info.Status != ServerBusyRejected {
+		t.Errorf("expected overflow to be rejected, got %+v", info)
+	}
+}
+
+func TestManagedStream_ResourceAccounting(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+
+	stream.Write(make([]byte, 882))
+	deadline := time.Now().Add(time.Second)
+	for stream.Status().Stats.ProcessingTime == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stats := stream.Status().Stats
+	if stats.ProcessingTime == 0 {
+		t.Error("expected processing time to be recorded")
+	}
+	if stats.Goroutines == 0 {
+		t.Error("expected the audio loop goroutine to be counted")
+	}
+
+	stream.Close()
+	deadline = time.Now().Add(time.Second)
+	for stream.Status().Stats.Goroutines != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := stream.Status().Stats.Goroutines; n != 0 {
+		t.Errorf("expected goroutines to exit after close, got %d", n)
+	}
+}
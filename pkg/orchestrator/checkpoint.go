@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckpointConfig makes conversations survive restarts. When Store is set,
+// sessions created by NewSessionWithDefaults (or handed to Checkpoint) are
+// saved to it in the background after every change to their context. Saves
+// for one session never overlap and bursts of changes collapse into one save.
+type CheckpointConfig struct {
+	Store SessionStore
+	// Timeout bounds each save, 5 seconds by default.
+	Timeout time.Duration
+	// MaxTranscript caps the transcript entries saved with each checkpoint,
+	// and kept in memory by sessions from NewSessionWithDefaults, to the
+	// most recent ones, 200 by default. Negative keeps them all.
+	MaxTranscript int
+}
+
+type checkpointState struct {
+	saving bool
+	dirty  bool
+}
+
+type checkpointer struct {
+	mu     sync.Mutex
+	states map[string]*checkpointState
+	wg     sync.WaitGroup
+}
+
+// Checkpoint saves session to Config.Checkpoint.Store after every change
+// from now on, starting with its current state.
+func (o *Orchestrator) Checkpoint(session *ConversationSession) {
+	session.mu.Lock()
+	session.onChange = o.scheduleCheckpoint
+	session.mu.Unlock()
+	o.scheduleCheckpoint(session)
+}
+
+func (o *Orchestrator) scheduleCheckpoint(session *ConversationSession) {
+	if o.GetConfig().Checkpoint.Store == nil {
+		return
+	}
+	c := &o.checkpoints
+	c.mu.Lock()
+	if c.states == nil {
+		c.states = make(map[string]*checkpointState)
+	}
+	st, ok := c.states[session.ID]
+	if !ok {
+		st = &checkpointState{}
+		c.states[session.ID] = st
+	}
+	if st.saving {
+		st.dirty = true
+		c.mu.Unlock()
+		return
+	}
+	st.saving = true
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go o.saveCheckpoints(session, st)
+}
+
+func (o *Orchestrator) saveCheckpoints(session *ConversationSession, st *checkpointState) {
+	c := &o.checkpoints
+	defer c.wg.Done()
+	for {
+		cfg := o.GetConfig().Checkpoint
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		if cfg.Store != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			maxTranscript := cfg.MaxTranscript
+			if maxTranscript == 0 {
+				maxTranscript = defaultMaxTranscript
+			}
+			if err := cfg.Store.Save(ctx, session.snapshot(maxTranscript)); err != nil {
+				o.logger.Warn("session checkpoint failed", "sessionID", session.ID, "error", err)
+			}
+			cancel()
+		}
+
+		c.mu.Lock()
+		if !st.dirty {
+			delete(c.states, session.ID)
+			c.mu.Unlock()
+			return
+		}
+		st.dirty = false
+		c.mu.Unlock()
+	}
+}
+
+// FlushCheckpoints waits for pending checkpoints, e.g. before shutdown.
+func (o *Orchestrator) FlushCheckpoints(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		o.checkpoints.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LoadSession restores a checkpointed session from Config.Checkpoint.Store
+// and keeps checkpointing it.
+func (o *Orchestrator) LoadSession(ctx context.Context, sessionID string) (*ConversationSession, error) {
+	store := o.GetConfig().Checkpoint.Store
+	if store == nil {
+		return nil, ErrSessionNotFound
+	}
+	snap, err := store.Load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session := RestoreSession(snap)
+	session.mu.Lock()
+	session.onChange = o.scheduleCheckpoint
+	session.mu.Unlock()
+	return session, nil
+}
+
+// changed notifies the session's checkpointer, if any. Callers must not hold
+// s.mu.
+func (s *ConversationSession) changed() {
+	s.mu.RLock()
+	onChange := s.onChange
+	s.mu.RUnlock()
+	if onChange != nil {
+		onChange(s)
+	}
+}
@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// CheckpointEventsConfig makes a ManagedStream emit Checkpoint events every
+// Interval while the conversation changes, so a client that reconnects can
+// rebuild its view from the latest one instead of the full history. Zero
+// disables them. Each carries the last MaxMessages (20 by default) messages.
+type CheckpointEventsConfig struct {
+	Interval    time.Duration
+	MaxMessages int
+}
+
+// ConversationState is the payload of a Checkpoint event. Seq increases with
+// every checkpoint of the stream; Omitted counts older messages left out.
+type ConversationState struct {
+	Seq      int            `json:"seq"`
+	TurnID   string         `json:"turn_id,omitempty"`
+	State    StreamState    `json:"state"`
+	Voice    Voice          `json:"voice"`
+	Language Language       `json:"language"`
+	Channel  Channel        `json:"channel"`
+	Messages []StateMessage `json:"messages"`
+	Omitted  int            `json:"omitted,omitempty"`
+}
+
+type StateMessage struct {
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	At      time.Time `json:"at,omitempty"`
+}
+
+// ConversationState returns the stream's current state, as carried by
+// Checkpoint events. Without ConsentTranscript it has no messages.
+func (ms *ManagedStream) ConversationState() ConversationState {
+	limit := 20
+	if ms.orch != nil && ms.orch.GetConfig().CheckpointEvents.MaxMessages > 0 {
+		limit = ms.orch.GetConfig().CheckpointEvents.MaxMessages
+	}
+
+	messages := ms.stateMessages()
+	state := ConversationState{
+		Voice:    ms.session.GetCurrentVoice(),
+		Language: ms.session.GetCurrentLanguage(),
+		Channel:  ms.session.Channel(),
+		Messages: messages,
+	}
+	if len(messages) > limit {
+		state.Omitted = len(messages) - limit
+		state.Messages = messages[state.Omitted:]
+	}
+	ms.mu.Lock()
+	state.Seq = ms.checkpointSeq
+	state.TurnID = ms.turnID
+	state.State = ms.stateLocked()
+	ms.mu.Unlock()
+	return state
+}
+
+// stateMessages lists the conversation for ConversationState. The transcript
+// has timestamps and survives compaction; the context is the fallback when
+// it is empty.
+func (ms *ManagedStream) stateMessages() []StateMessage {
+	if !ms.session.HasConsent(ConsentTranscript) {
+		return nil
+	}
+	var messages []StateMessage
+	if transcript := ms.session.Transcript(); len(transcript) > 0 {
+		for _, e := range transcript {
+			if e.Role == "user" || e.Role == "assistant" {
+				messages = append(messages, StateMessage{Role: e.Role, Content: e.Content, At: e.At})
+			}
+		}
+		return messages
+	}
+	for _, m := range ms.session.GetContextCopy() {
+		if m.Role == "user" || m.Role == "assistant" {
+			messages = append(messages, StateMessage{Role: m.Role, Content: m.Content})
+		}
+	}
+	return messages
+}
+
+// EmitCheckpoint emits a Checkpoint event now, e.g. when a client asks to
+// resync after reconnecting.
+func (ms *ManagedStream) EmitCheckpoint() {
+	ms.emitCheckpoint(true)
+}
+
+// emitCheckpoint emits the current state unless it is unchanged since the
+// last checkpoint and force is false.
+func (ms *ManagedStream) emitCheckpoint(force bool) {
+	state := ms.ConversationState()
+	state.Seq = 0
+	fingerprint, _ := json.Marshal(state)
+
+	ms.mu.Lock()
+	if !force && bytes.Equal(fingerprint, ms.lastCheckpoint) {
+		ms.mu.Unlock()
+		return
+	}
+	ms.lastCheckpoint = fingerprint
+	ms.checkpointSeq++
+	state.Seq = ms.checkpointSeq
+	ms.mu.Unlock()
+
+	ms.emit(Checkpoint, state)
+}
+
+func (ms *ManagedStream) runCheckpoints(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ms.ctx.Done():
+			return
+		case <-ticker.C:
+			ms.emitCheckpoint(false)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func checkpointStream(t *testing.T, events CheckpointEventsConfig) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.CheckpointEvents = events
+	return newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+}
+
+func TestCheckpointEvents_EmittedOnChange(t *testing.T) {
+	ms := checkpointStream(t, CheckpointEventsConfig{Interval: 20 * time.Millisecond, MaxMessages: 2})
+	ms.session.AddMessage("user", "hi")
+	ms.session.AddMessage("assistant", "hello")
+	ms.session.AddMessage("user", "what time is it")
+
+	state := waitForEvent(t, ms, Checkpoint).Data.(ConversationState)
+	if state.Seq != 1 || state.Omitted != 1 || len(state.Messages) != 2 {
+		t.Fatalf("unexpected checkpoint %+v", state)
+	}
+	if m := state.Messages[1]; m.Role != "user" || m.Content != "what time is it" || m.At.IsZero() {
+		t.Errorf("unexpected last message %+v", m)
+	}
+
+	// Nothing changed, so the next ticks stay quiet.
+	deadline := time.After(100 * time.Millisecond)
+	for quiet := true; quiet; {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == Checkpoint {
+				t.Fatalf("unchanged state re-emitted: %+v", ev.Data)
+			}
+		case <-deadline:
+			quiet = false
+		}
+	}
+
+	ms.session.AddMessage("assistant", "noon")
+	if state := waitForEvent(t, ms, Checkpoint).Data.(ConversationState); state.Seq != 2 || state.Messages[1].Content != "noon" {
+		t.Errorf("unexpected checkpoint %+v", state)
+	}
+}
+
+func TestCheckpointEvents_OnDemand(t *testing.T) {
+	ms := checkpointStream(t, CheckpointEventsConfig{})
+	ms.EmitCheckpoint()
+	ms.EmitCheckpoint()
+
+	waitForEvent(t, ms, Checkpoint)
+	state := waitForEvent(t, ms, Checkpoint).Data.(ConversationState)
+	if state.Seq != 2 || state.State != StreamIdle || state.Messages != nil {
+		t.Errorf("unexpected checkpoint %+v", state)
+	}
+}
+
+func TestCheckpointEvents_WithoutTranscriptConsent(t *testing.T) {
+	ms := checkpointStream(t, CheckpointEventsConfig{})
+	ms.session.AddMessage("user", "my card number is 4242")
+	ms.session.RevokeConsent(ConsentTranscript)
+
+	if state := ms.ConversationState(); state.Messages != nil || state.Omitted != 0 {
+		t.Errorf("expected no messages without transcript consent, got %+v", state)
+	}
+	ev := withoutTranscript(ms.session, OrchestratorEvent{Type: Checkpoint, Data: ms.ConversationState()})
+	if ev.Data != nil {
+		t.Errorf("expected the checkpoint payload to be redacted, got %+v", ev.Data)
+	}
+	ev = withoutTranscript(ms.session, OrchestratorEvent{Type: FactCheckFailed, Data: FactCheckInfo{Fallback: "Let me check."}})
+	if ev.Data != nil {
+		t.Errorf("expected the fact check payload to be redacted, got %+v", ev.Data)
+	}
+}
@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowStore counts saves and holds each one briefly so concurrent changes
+// pile up behind it.
+type slowStore struct {
+	*MemorySessionStore
+	mu    sync.Mutex
+	saves int
+}
+
+func (s *slowStore) Save(ctx context.Context, snap SessionSnapshot) error {
+	time.Sleep(10 * time.Millisecond)
+	s.mu.Lock()
+	s.saves++
+	s.mu.Unlock()
+	return s.MemorySessionStore.Save(ctx, snap)
+}
+
+func newCheckpointOrchestrator(store SessionStore) *Orchestrator {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Checkpoint.Store = store
+	return NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 0), cfg)
+}
+
+func TestCheckpoint_SavesAfterEachChange(t *testing.T) {
+	store := NewMemorySessionStore()
+	orch := newCheckpointOrchestrator(store)
+	ctx := context.Background()
+
+	session := orch.NewSessionWithDefaults("call-1")
+	orch.SetSystemPrompt(session, "be brief")
+	session.AddMessage("user", "hello")
+	if err := orch.FlushCheckpoints(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := store.Load(ctx, "call-1")
+	if err != nil {
+		t.Fatalf("expected a checkpoint, got %v", err)
+	}
+	if len(snap.Context) != 2 || snap.LastUser != "hello" {
+		t.Errorf("checkpoint is stale: %+v", snap)
+	}
+
+	session.ClearContext()
+	orch.FlushCheckpoints(ctx)
+	if snap, _ := store.Load(ctx, "call-1"); len(snap.Context) != 0 {
+		t.Errorf("expected cleared context to be checkpointed, got %+v", snap.Context)
+	}
+}
+
+func TestCheckpoint_CapsTranscript(t *testing.T) {
+	store := NewMemorySessionStore()
+	orch := newCheckpointOrchestrator(store)
+	cfg := orch.GetConfig()
+	cfg.Checkpoint.MaxTranscript = 2
+	orch.UpdateConfig(cfg)
+	ctx := context.Background()
+
+	session := orch.NewSessionWithDefaults("call-1")
+	for _, text := range []string{"one", "two", "three"} {
+		session.AddMessage("user", text)
+	}
+	orch.FlushCheckpoints(ctx)
+	snap, _ := store.Load(ctx, "call-1")
+	if len(snap.Transcript) != 2 || snap.Transcript[1].Content != "three" {
+		t.Errorf("expected the last two transcript entries, got %+v", snap.Transcript)
+	}
+	if len(session.Transcript()) != 2 {
+		t.Error("expected the session to keep the same cap in memory")
+	}
+}
+
+func TestCheckpoint_CoalescesBursts(t *testing.T) {
+	store := &slowStore{MemorySessionStore: NewMemorySessionStore()}
+	orch := newCheckpointOrchestrator(store)
+	ctx := context.Background()
+
+	session := orch.NewSessionWithDefaults("call-1")
+	for i := 0; i < 20; i++ {
+		session.AddMessage("user", "again")
+	}
+	session.AddMessage("user", "last")
+	orch.FlushCheckpoints(ctx)
+
+	store.mu.Lock()
+	saves := store.saves
+	store.mu.Unlock()
+	if saves > 3 {
+		t.Errorf("expected bursts to collapse into few saves, got %d", saves)
+	}
+	if snap, _ := store.Load(ctx, "call-1"); snap.LastUser != "last" {
+		t.Errorf("expected the final state to be saved, got %q", snap.LastUser)
+	}
+}
+
+func TestCheckpoint_LoadSessionAfterRestart(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+
+	before := newCheckpointOrchestrator(store)
+	session := before.NewSessionWithDefaults("call-1")
+	session.AddMessage("user", "remember me")
+	before.FlushCheckpoints(ctx)
+
+	after := newCheckpointOrchestrator(store)
+	restored, err := after.LoadSession(ctx, "call-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetContextCopy()[0].Content != "remember me" {
+		t.Errorf("unexpected context: %+v", restored.GetContextCopy())
+	}
+	restored.AddMessage("assistant", "I do")
+	after.FlushCheckpoints(ctx)
+	if snap, _ := store.Load(ctx, "call-1"); len(snap.Context) != 2 {
+		t.Errorf("expected restored session to keep checkpointing, got %+v", snap.Context)
+	}
+
+	if _, err := after.LoadSession(ctx, "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	m := NewSessionManager(after, SessionManagerOptions{SystemPrompt: "ignored on restore"})
+	got, err := m.GetOrCreate("call-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx := got.GetContextCopy(); len(ctx) != 2 || ctx[0].Content != "remember me" {
+		t.Errorf("expected SessionManager to restore the checkpoint, got %+v", ctx)
+	}
+}
+
+type sealingStore struct {
+	*MemorySessionStore
+	enc Encryptor
+}
+
+func (s *sealingStore) SetEncryptor(enc Encryptor) { s.enc = enc }
+
+func TestCheckpoint_StoreSealedWithConfigEncryptor(t *testing.T) {
+	enc, err := NewAESGCMEncryptorFromKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &sealingStore{MemorySessionStore: NewMemorySessionStore()}
+	cfg := DefaultConfig()
+	cfg.Checkpoint.Store = store
+	cfg.Encryptor = enc
+	New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	if store.enc != enc {
+		t.Error("expected checkpoints to be sealed with Config.Encryptor")
+	}
+}
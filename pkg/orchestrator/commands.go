@@ -0,0 +1,132 @@
+package orchestrator
+
+import "strings"
+
+// CommandAction is what a spoken command does.
+type CommandAction string
+
+const (
+	// CommandStop interrupts the bot without resuming it.
+	CommandStop CommandAction = "stop"
+	// CommandRepeat speaks the last reply again.
+	CommandRepeat CommandAction = "repeat"
+	// CommandEndCall ends the call right away.
+	CommandEndCall CommandAction = "end_call"
+)
+
+// CallEndCommand is the CallEndInfo reason of a CommandEndCall.
+const CallEndCommand = "command"
+
+// DefaultCommands are the phrases CommandsConfig uses when Phrases is nil.
+var DefaultCommands = map[string]CommandAction{
+	"stop": CommandStop, "cancel": CommandStop, "stop it": CommandStop, "be quiet": CommandStop,
+	"para": CommandStop, "detente": CommandStop, "cancela": CommandStop, "stopp": CommandStop, "arrête": CommandStop,
+	"repeat": CommandRepeat, "repeat that": CommandRepeat, "say that again": CommandRepeat, "come again": CommandRepeat,
+	"repite": CommandRepeat, "repítelo": CommandRepeat, "répète": CommandRepeat, "wiederholen": CommandRepeat,
+	"hang up": CommandEndCall, "end call": CommandEndCall, "end the call": CommandEndCall,
+	"cuelga": CommandEndCall, "raccroche": CommandEndCall, "auflegen": CommandEndCall,
+}
+
+// CommandsConfig acts on short spoken commands without asking the LLM: an
+// utterance made up of nothing but one of Phrases (DefaultCommands when nil),
+// ignoring case and punctuation. Stop commands act on partial transcripts
+// while the bot is talking, as soon as they are heard; everything else waits
+// for the final transcript. Commands are reported as CommandDetected and
+// never reach the LLM.
+type CommandsConfig struct {
+	Enabled bool
+	Phrases map[string]CommandAction
+}
+
+// CommandInfo is the data of a CommandDetected event.
+type CommandInfo struct {
+	Phrase string        `json:"phrase"`
+	Action CommandAction `json:"action"`
+}
+
+// match returns the command transcript consists of, if any.
+func (c CommandsConfig) match(transcript string) (CommandAction, bool) {
+	if !c.Enabled {
+		return "", false
+	}
+	phrases := c.Phrases
+	if phrases == nil {
+		phrases = DefaultCommands
+	}
+	said := strings.Join(backchannelWords(transcript), " ")
+	if said == "" {
+		return "", false
+	}
+	for phrase, action := range phrases {
+		if strings.Join(backchannelWords(phrase), " ") == said {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+// command handles transcript if it is a command and reports whether it was.
+// A stop is carried out on the first partial that holds it; anything else
+// waits for the final transcript.
+func (ms *ManagedStream) command(transcript string, isFinal bool, turnID string) bool {
+	if ms.orch == nil {
+		return false
+	}
+	action, ok := ms.orch.GetConfig().Commands.match(transcript)
+	if !ok {
+		return false
+	}
+	if !isFinal {
+		ms.mu.Lock()
+		talking := ms.isSpeaking || ms.isThinking
+		ms.mu.Unlock()
+		if action != CommandStop || !talking {
+			return false
+		}
+	}
+
+	ms.mu.Lock()
+	handled := ms.commandTurn == turnID
+	ms.commandTurn = turnID
+	ms.mu.Unlock()
+	if !handled {
+		ms.updateTurn(turnID, func(t *Turn) { t.Transcript = transcript })
+		ms.emitForTurn(CommandDetected, CommandInfo{Phrase: transcript, Action: action}, turnID)
+		ms.runCommand(action)
+	}
+	if !isFinal {
+		ms.emitForTurn(TranscriptPartial, transcript, turnID)
+	}
+	return true
+}
+
+func (ms *ManagedStream) runCommand(action CommandAction) {
+	switch action {
+	case CommandStop:
+		ms.internalInterrupt()
+		ms.mu.Lock()
+		ms.resumable = nil
+		ms.mu.Unlock()
+	case CommandRepeat:
+		if last := ms.lastReply(); last != "" {
+			ms.spawn(func() { ms.say(ms.ctx, last, false) })
+		}
+	case CommandEndCall:
+		ms.internalInterrupt()
+		ms.spawn(func() {
+			ms.emit(CallEnded, CallEndInfo{Reason: CallEndCommand})
+			ms.Close()
+		})
+	}
+}
+
+// lastReply returns the last assistant message in the session's context.
+func (ms *ManagedStream) lastReply() string {
+	messages := ms.session.GetContextCopy()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "assistant" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandsConfig_Match(t *testing.T) {
+	cfg := CommandsConfig{Enabled: true}
+	cases := []struct {
+		transcript string
+		want       CommandAction
+		ok         bool
+	}{
+		{"Stop!", CommandStop, true},
+		{"  cancel. ", CommandStop, true},
+		{"Say that again?", CommandRepeat, true},
+		{"Hang up.", CommandEndCall, true},
+		{"stop the car", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		got, ok := cfg.match(c.transcript)
+		if got != c.want || ok != c.ok {
+			t.Errorf("match(%q) = %q, %v, want %q, %v", c.transcript, got, ok, c.want, c.ok)
+		}
+	}
+
+	if _, ok := (CommandsConfig{}).match("stop"); ok {
+		t.Error("disabled commands must not match")
+	}
+	custom := CommandsConfig{Enabled: true, Phrases: map[string]CommandAction{"shush": CommandStop}}
+	if _, ok := custom.match("stop"); ok {
+		t.Error("custom phrases replace the defaults")
+	}
+	if got, _ := custom.match("Shush!"); got != CommandStop {
+		t.Errorf("expected a custom stop, got %q", got)
+	}
+}
+
+func newCommandStream(t *testing.T, heard string) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Commands = CommandsConfig{Enabled: true}
+	ms := newTestStream(t, cfg, &MockSTTProvider{transcribeResult: heard}, &MockLLMProvider{completeResult: "A new answer."})
+	ms.session.AddMessage("user", "what's the weather")
+	ms.session.AddMessage("assistant", "Sunny and warm.")
+	return ms
+}
+
+func TestCommands_RepeatSpeaksLastReply(t *testing.T) {
+	ms := newCommandStream(t, "Repeat that.")
+	speakTo(ms)
+
+	ev := waitForEvent(t, ms, CommandDetected)
+	if info := ev.Data.(CommandInfo); info.Action != CommandRepeat || info.Phrase != "Repeat that." {
+		t.Errorf("unexpected command %+v", info)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "Sunny and warm." {
+		t.Errorf("expected the last reply again, got %v", ev.Data)
+	}
+	if n := len(ms.session.GetContextCopy()); n != 2 {
+		t.Errorf("a command must not reach the context, got %d messages", n)
+	}
+}
+
+func TestCommands_HangUpEndsCall(t *testing.T) {
+	ms := newCommandStream(t, "hang up")
+	speakTo(ms)
+
+	if ev := waitForEvent(t, ms, CallEnded); ev.Data.(CallEndInfo).Reason != CallEndCommand {
+		t.Errorf("unexpected call end %+v", ev.Data)
+	}
+	waitForClose(t, ms)
+}
+
+func TestCommands_StopOnPartialWhileSpeaking(t *testing.T) {
+	ms := newCommandStream(t, "")
+	turnID := "turn-stop"
+
+	if ms.command("stop", false, turnID) {
+		t.Fatal("a partial stop must not fire while the bot is quiet")
+	}
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.mu.Unlock()
+	if !ms.command("stop", false, turnID) {
+		t.Fatal("expected the partial stop to be handled")
+	}
+	waitForEvent(t, ms, CommandDetected)
+	ms.mu.Lock()
+	speaking := ms.isSpeaking
+	ms.mu.Unlock()
+	if speaking {
+		t.Error("expected the bot to stop speaking")
+	}
+
+	if !ms.command("stop", true, turnID) {
+		t.Fatal("the final transcript of a handled stop must be swallowed too")
+	}
+	select {
+	case ev := <-ms.Events():
+		if ev.Type == CommandDetected {
+			t.Error("a command must be carried out once per turn")
+		}
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const summaryPrefix = "Summary of the earlier conversation: "
+
+const defaultCompactionPrompt = "Summarize the following conversation between a user and a voice assistant. " +
+	"Keep names, numbers, decisions and open questions. Reply with the summary only, in at most a few sentences."
+
+// CompactionConfig enables summarizing older turns once the context grows past
+// MaxMessages or an estimated MaxTokens. Zero values disable that trigger.
+type CompactionConfig struct {
+	MaxMessages int
+	MaxTokens   int
+	KeepRecent  int
+	Prompt      string
+}
+
+func (c CompactionConfig) Enabled() bool {
+	return c.MaxMessages > 0 || c.MaxTokens > 0
+}
+
+// imageTokens is what EstimateTokens charges per image, roughly a
+// low-detail image on current vision models.
+const imageTokens = 85
+
+// EstimateTokens uses the common four-characters-per-token approximation.
+func EstimateTokens(messages []Message) int {
+	chars := 0
+	for _, m := range messages {
+		chars += len(m.Content) + len(m.Role)
+		for _, p := range m.Parts {
+			if p.Type == PartImage {
+				chars += 4 * imageTokens
+			} else {
+				chars += len(p.Text)
+			}
+		}
+	}
+	return (chars + 3) / 4
+}
+
+func isSummaryMessage(m Message) bool {
+	return m.Role == "system" && strings.HasPrefix(m.Content, summaryPrefix)
+}
+
+func (c CompactionConfig) needsCompaction(messages []Message) bool {
+	if !c.Enabled() {
+		return false
+	}
+	turns := 0
+	for _, m := range messages {
+		if m.Role != "system" {
+			turns++
+		}
+	}
+	if c.MaxMessages > 0 && turns > c.MaxMessages {
+		return true
+	}
+	return c.MaxTokens > 0 && EstimateTokens(messages) > c.MaxTokens
+}
+
+// CompactContext summarizes all but the most recent turns into a single system
+// message. It reports whether the session context was rewritten.
+func (o *Orchestrator) CompactContext(ctx context.Context, session *ConversationSession) (bool, error) {
+	cfg := o.GetConfig().Compaction
+	snapshot := session.GetContextCopy()
+
+	keep := cfg.KeepRecent
+	if keep <= 0 {
+		keep = 4
+	}
+
+	var pinned, older []Message
+	recentStart := len(snapshot)
+	for i := len(snapshot) - 1; i >= 0 && len(snapshot)-i <= keep; i-- {
+		if snapshot[i].Role != "system" {
+			recentStart = i
+		}
+	}
+	for _, m := range snapshot[:recentStart] {
+		if m.Role == "system" && !isSummaryMessage(m) {
+			pinned = append(pinned, m)
+		} else {
+			older = append(older, m)
+		}
+	}
+	if len(older) < 2 {
+		return false, nil
+	}
+
+	var transcript strings.Builder
+	for _, m := range older {
+		role := m.Role
+		if isSummaryMessage(m) {
+			role = "previous summary"
+		}
+		fmt.Fprintf(&transcript, "%s: %s%s\n", role, strings.TrimPrefix(m.Content, summaryPrefix), describeParts(m.Parts))
+	}
+
+	prompt := cfg.Prompt
+	if prompt == "" {
+		prompt = defaultCompactionPrompt
+	}
+	summary, usage, err := o.complete(ctx, []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: transcript.String()},
+	}, GenerationParams{})
+	if err != nil {
+		return false, fmt.Errorf("context compaction failed: %w", err)
+	}
+	o.recordUsage(session, usage)
+
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return false, nil
+	}
+
+	compacted := make([]Message, 0, len(pinned)+1+len(snapshot)-recentStart)
+	compacted = append(compacted, pinned...)
+	compacted = append(compacted, Message{Role: "system", Content: summaryPrefix + summary})
+	compacted = append(compacted, snapshot[recentStart:]...)
+
+	if !session.replacePrefix(snapshot, compacted) {
+		o.logger.Debug("context changed during compaction, skipping", "sessionID", session.ID)
+		return false, nil
+	}
+
+	o.logger.Info("context compacted", "sessionID", session.ID, "summarized", len(older), "remaining", len(compacted))
+	return true, nil
+}
+
+func (o *Orchestrator) compactIfNeeded(ctx context.Context, session *ConversationSession) {
+	cfg := o.GetConfig().Compaction
+	if !cfg.needsCompaction(session.GetContextCopy()) {
+		return
+	}
+	if _, err := o.CompactContext(ctx, session); err != nil {
+		o.logger.Warn("falling back to uncompacted context", "sessionID", session.ID, "error", err)
+	}
+}
+
+// replacePrefix swaps the messages in old for replacement, keeping anything
+// appended since old was captured. It fails if the context was rewritten.
+func (s *ConversationSession) replacePrefix(old, replacement []Message) bool {
+	s.mu.Lock()
+	if len(s.Context) < len(old) {
+		s.mu.Unlock()
+		return false
+	}
+	for i := range old {
+		if !s.Context[i].equal(old[i]) {
+			s.mu.Unlock()
+			return false
+		}
+	}
+	tail := s.Context[len(old):]
+	next := make([]Message, 0, len(replacement)+len(tail))
+	next = append(next, replacement...)
+	next = append(next, tail...)
+	s.Context = next
+	s.mu.Unlock()
+
+	s.changed()
+	return true
+}
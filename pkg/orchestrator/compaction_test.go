@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type summarizingLLM struct {
+	mu    sync.Mutex
+	calls [][]Message
+}
+
+func (m *summarizingLLM) Complete(ctx context.Context, messages []Message) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, messages)
+	if len(messages) == 2 && messages[0].Content == defaultCompactionPrompt {
+		return "user asked about order 42", nil
+	}
+	return "reply", nil
+}
+
+func (m *summarizingLLM) Name() string { return "summarizing" }
+
+func TestEstimateTokens(t *testing.T) {
+	n := EstimateTokens([]Message{{Role: "user", Content: strings.Repeat("a", 36)}})
+	if n != 10 {
+		t.Errorf("expected 10 tokens, got %d", n)
+	}
+}
+
+func TestCompactionConfigTriggers(t *testing.T) {
+	msgs := []Message{
+		{Role: "system", Content: "prompt"},
+		{Role: "user", Content: "a"},
+		{Role: "assistant", Content: "b"},
+		{Role: "user", Content: "c"},
+	}
+	if (CompactionConfig{}).needsCompaction(msgs) {
+		t.Error("disabled config must not trigger")
+	}
+	if !(CompactionConfig{MaxMessages: 2}).needsCompaction(msgs) {
+		t.Error("expected message-count trigger")
+	}
+	if (CompactionConfig{MaxMessages: 3}).needsCompaction(msgs) {
+		t.Error("system messages must not count toward the turn budget")
+	}
+	if !(CompactionConfig{MaxTokens: 3}).needsCompaction(msgs) {
+		t.Error("expected token trigger")
+	}
+}
+
+func TestGenerateResponse_CompactsContext(t *testing.T) {
+	llm := &summarizingLLM{}
+	cfg := DefaultConfig()
+	cfg.Compaction = CompactionConfig{MaxMessages: 4, KeepRecent: 2}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, cfg)
+
+	session := orch.NewSessionWithDefaults("compact")
+	orch.SetSystemPrompt(session, "You are helpful.")
+	session.AddMessage("user", "my order is 42")
+	session.AddMessage("assistant", "noted")
+	session.AddMessage("user", "where is it")
+	session.AddMessage("assistant", "checking")
+	session.AddMessage("user", "thanks")
+
+	if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := session.GetContextCopy()
+	if len(ctx) != 4 {
+		t.Fatalf("expected pinned prompt, summary and 2 recent turns, got %d: %+v", len(ctx), ctx)
+	}
+	if ctx[0].Content != "You are helpful." {
+		t.Errorf("system prompt must be preserved, got %q", ctx[0].Content)
+	}
+	if !isSummaryMessage(ctx[1]) || !strings.Contains(ctx[1].Content, "order 42") {
+		t.Errorf("expected summary message, got %+v", ctx[1])
+	}
+	if ctx[2].Content != "checking" || ctx[3].Content != "thanks" {
+		t.Errorf("recent turns must be kept verbatim, got %+v", ctx[2:])
+	}
+
+	last := llm.calls[len(llm.calls)-1]
+	if len(last) != 4 {
+		t.Errorf("expected the response to use the compacted context, got %d messages", len(last))
+	}
+}
+
+func TestCompaction_SessionsAreNotTruncated(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxContextMessages = 4
+	cfg.Compaction = CompactionConfig{MaxMessages: 6}
+	orch := New(&MockSTTProvider{}, &summarizingLLM{}, &MockTTSProvider{}, cfg)
+
+	session := orch.NewSessionWithDefaults("compact")
+	for i := 0; i < 6; i++ {
+		session.AddMessage("user", "turn")
+	}
+	if n := len(session.GetContextCopy()); n != 6 {
+		t.Errorf("expected compaction, not truncation, to bound the context, got %d messages", n)
+	}
+	if restored := RestoreSession(session.Snapshot()); restored.MaxMessages != 0 {
+		t.Errorf("expected the uncapped session to stay uncapped, got %d", restored.MaxMessages)
+	}
+}
+
+func TestReplacePrefixKeepsAppendedMessages(t *testing.T) {
+	session := NewConversationSession("prefix")
+	session.AddMessage("user", "a")
+	session.AddMessage("assistant", "b")
+	snapshot := session.GetContextCopy()
+	session.AddMessage("user", "c")
+
+	if !session.replacePrefix(snapshot, []Message{{Role: "system", Content: summaryPrefix + "ab"}}) {
+		t.Fatal("expected prefix replacement to succeed")
+	}
+	ctx := session.GetContextCopy()
+	if len(ctx) != 2 || ctx[1].Content != "c" {
+		t.Errorf("expected appended message to survive, got %+v", ctx)
+	}
+
+	session.ClearContext()
+	if session.replacePrefix(snapshot, nil) {
+		t.Error("expected replacement to fail once the context was rewritten")
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+type ConsentScope string
+
+const (
+	ConsentRecording  ConsentScope = "recording"
+	ConsentDebugAudio ConsentScope = "debug_audio"
+	ConsentTranscript ConsentScope = "transcript"
+)
+
+var AllConsentScopes = []ConsentScope{ConsentRecording, ConsentDebugAudio, ConsentTranscript}
+
+type ConsentChange struct {
+	Scope   ConsentScope `json:"scope"`
+	Granted bool         `json:"granted"`
+}
+
+// HasConsent reports whether scope is currently allowed. Sessions allow every
+// scope unless it was revoked explicitly or Config.RequireConsent was set.
+func (s *ConversationSession) HasConsent(scope ConsentScope) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.consentDenied[scope]
+}
+
+func (s *ConversationSession) SetConsent(scope ConsentScope, granted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.consentDenied == nil {
+		s.consentDenied = make(map[ConsentScope]bool)
+	}
+	if granted {
+		delete(s.consentDenied, scope)
+	} else {
+		s.consentDenied[scope] = true
+		if scope == ConsentTranscript {
+			s.transcript = nil
+		}
+	}
+}
+
+func (s *ConversationSession) GrantConsent(scopes ...ConsentScope) {
+	for _, scope := range scopes {
+		s.SetConsent(scope, true)
+	}
+}
+
+func (s *ConversationSession) RevokeConsent(scopes ...ConsentScope) {
+	for _, scope := range scopes {
+		s.SetConsent(scope, false)
+	}
+}
+
+func (s *ConversationSession) SetDoNotRecord(doNotRecord bool) {
+	s.SetConsent(ConsentRecording, !doNotRecord)
+	s.SetConsent(ConsentDebugAudio, !doNotRecord)
+}
+
+func (s *ConversationSession) GetConsent() map[ConsentScope]bool {
+	out := make(map[ConsentScope]bool, len(AllConsentScopes))
+	for _, scope := range AllConsentScopes {
+		out[scope] = s.HasConsent(scope)
+	}
+	return out
+}
+
+func (ms *ManagedStream) SetConsent(scope ConsentScope, granted bool) {
+	if ms.session.HasConsent(scope) == granted {
+		return
+	}
+	ms.session.SetConsent(scope, granted)
+	ms.emit(ConsentChanged, ConsentChange{Scope: scope, Granted: granted})
+}
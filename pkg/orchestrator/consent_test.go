@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionConsentDefaults(t *testing.T) {
+	session := NewConversationSession("consent")
+	for _, scope := range AllConsentScopes {
+		if !session.HasConsent(scope) {
+			t.Errorf("expected %s allowed by default", scope)
+		}
+	}
+
+	session.SetDoNotRecord(true)
+	if session.HasConsent(ConsentRecording) || session.HasConsent(ConsentDebugAudio) {
+		t.Error("expected do-not-record to revoke recording and debug audio")
+	}
+	if !session.HasConsent(ConsentTranscript) {
+		t.Error("do-not-record should not affect transcript consent")
+	}
+
+	session.GrantConsent(ConsentRecording)
+	if !session.GetConsent()[ConsentRecording] {
+		t.Error("expected recording consent after grant")
+	}
+}
+
+func TestRequireConsentConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RequireConsent = true
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+
+	session := orch.NewSessionWithDefaults("regulated")
+	for scope, granted := range session.GetConsent() {
+		if granted {
+			t.Errorf("expected %s denied when consent is required", scope)
+		}
+	}
+}
+
+func TestManagedStream_ConsentGatesDebugExport(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	session := NewConversationSession("consent")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	stream.mu.Lock()
+	stream.lastUserAudio = []byte{1, 2, 3, 4}
+	stream.mu.Unlock()
+
+	stream.SetConsent(ConsentDebugAudio, false)
+
+	select {
+	case ev := <-stream.Events():
+		change, ok := ev.Data.(ConsentChange)
+		if ev.Type != ConsentChanged || !ok || change.Scope != ConsentDebugAudio || change.Granted {
+			t.Fatalf("unexpected event %v %+v", ev.Type, ev.Data)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("timed out waiting for ConsentChanged")
+	}
+
+	if raw, processed := stream.ExportLastUserAudio(); raw != nil || processed != nil {
+		t.Error("expected debug export to be blocked without consent")
+	}
+
+	stream.SetConsent(ConsentDebugAudio, true)
+	if raw, _ := stream.ExportLastUserAudio(); raw == nil {
+		t.Error("expected debug export after consent granted")
+	}
+}
@@ -0,0 +1,144 @@
+package orchestrator
+
+import "time"
+
+const defaultContinuationWindow = time.Second
+
+// ContinuationConfig merges an utterance into the one before it when the
+// caller starts again within Window (1s by default) of stopping, before the
+// reply has started. Whatever was under way for the first utterance,
+// transcription or the LLM, is canceled, and the audio of both is
+// transcribed and answered as one turn instead of two replies racing each
+// other. UtteranceContinued is emitted with what the first utterance was
+// transcribed as, if it got that far.
+type ContinuationConfig struct {
+	Enabled bool
+	Window  time.Duration
+}
+
+func (c ContinuationConfig) window() time.Duration {
+	if c.Window <= 0 {
+		return defaultContinuationWindow
+	}
+	return c.Window
+}
+
+// utterance is the caller's last utterance, kept while it can be continued.
+type utterance struct {
+	turnID     string
+	ended      time.Time
+	audio      []byte
+	gap        []byte // heard since it ended
+	transcript string // added to the context, once transcribed
+	continued  bool
+}
+
+func (ms *ManagedStream) continuation() ContinuationConfig {
+	if ms.orch == nil {
+		return ContinuationConfig{}
+	}
+	return ms.orch.GetConfig().Continuation
+}
+
+// rememberUtteranceLocked keeps the audio of an utterance that just ended.
+// It must be called with ms.mu held.
+func (ms *ManagedStream) rememberUtteranceLocked(audio []byte) {
+	if !ms.continuation().Enabled || len(audio) == 0 {
+		ms.utterance = nil
+		return
+	}
+	ms.utterance = &utterance{turnID: ms.turnID, ended: time.Now(), audio: append([]byte(nil), audio...)}
+}
+
+// recordGapLocked keeps audio heard after an utterance, for as long as it
+// can be continued. It must be called with ms.mu held.
+func (ms *ManagedStream) recordGapLocked(chunk []byte) {
+	u := ms.utterance
+	if u == nil || u.continued {
+		return
+	}
+	if time.Since(u.ended) > ms.continuation().window() {
+		ms.utterance = nil
+		return
+	}
+	u.gap = append(u.gap, chunk...)
+}
+
+// continueUtterance reports whether speech starting now continues the last
+// utterance. If so, its reply is canceled without an interruption, and the
+// new utterance is started with its audio ahead of the new speech.
+func (ms *ManagedStream) continueUtterance() bool {
+	ms.mu.Lock()
+	u := ms.utterance
+	ok := u != nil && !u.continued && ms.turnID == u.turnID &&
+		time.Since(u.ended) <= ms.continuation().window() &&
+		!ms.isSpeaking && !ms.lastAudioSentAt.After(u.ended)
+	if !ok {
+		ms.mu.Unlock()
+		return false
+	}
+	u.continued = true
+	ms.utterance = nil
+	ms.continued = u
+	responseCancel, ttsCancel := ms.responseCancel, ms.ttsCancel
+	ms.responseCancel, ms.ttsCancel = nil, nil
+	ms.isThinking = false
+	ms.unanswered = ""
+	ms.payloadGen++
+	ms.stats.Continuations++
+	ms.audioBuf.Reset()
+	ms.audioBuf.Write(u.audio)
+	ms.audioBuf.Write(u.gap)
+	ms.mu.Unlock()
+
+	if responseCancel != nil {
+		responseCancel()
+	}
+	if ttsCancel != nil {
+		ttsCancel()
+	}
+	ms.emitForTurn(UtteranceContinued, u.transcript, u.turnID)
+	ms.startUtterance(false)
+
+	ms.mu.Lock()
+	ms.lastUserAudio = append(append(ms.lastUserAudio, u.audio...), u.gap...)
+	ms.mu.Unlock()
+	return true
+}
+
+// continuedTranscript records transcript as what the last utterance of
+// turnID was heard as. If the turn continues an utterance already in the
+// context, transcript covers both and replaces it.
+func (ms *ManagedStream) continuedTranscript(transcript, turnID string) bool {
+	ms.mu.Lock()
+	if ms.utterance != nil && ms.utterance.turnID == turnID {
+		ms.utterance.transcript = transcript
+	}
+	c := ms.continued
+	if c == nil || c.turnID != turnID {
+		ms.mu.Unlock()
+		return false
+	}
+	ms.continued = nil
+	ms.mu.Unlock()
+	return c.transcript != "" && ms.session.replaceLastUser(c.transcript, transcript)
+}
+
+// replaceLastUser replaces the last message, if it is the user's old one,
+// and its transcript entry.
+func (s *ConversationSession) replaceLastUser(old, content string) bool {
+	s.mu.Lock()
+	n := len(s.Context)
+	if n == 0 || s.Context[n-1].Role != "user" || s.Context[n-1].Content != old {
+		s.mu.Unlock()
+		return false
+	}
+	s.Context[n-1].Content = content
+	s.LastUser = content
+	if t := len(s.transcript); t > 0 && s.transcript[t-1].Role == "user" && s.transcript[t-1].Content == old {
+		s.transcript[t-1].Content = content
+	}
+	s.mu.Unlock()
+	s.changed()
+	return true
+}
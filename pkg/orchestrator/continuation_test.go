@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// growingSTT transcribes each call as a longer sentence.
+type growingSTT struct {
+	MockSTTProvider
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *growingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls == 1 {
+		return "book a table", nil
+	}
+	return "book a table for two tonight", nil
+}
+
+func speak(ms *ManagedStream, d time.Duration) {
+	for range d / (10 * time.Millisecond) {
+		ms.doWrite(toneChunk(6553))
+	}
+	// Silence long enough for the VAD to hear the caller stop.
+	for range 8 {
+		ms.doWrite(make([]byte, 882))
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func continuationStream(t *testing.T, enabled bool) (*ManagedStream, *cancelableLLM) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Continuation = ContinuationConfig{Enabled: enabled}
+	llm := &cancelableLLM{started: make(chan struct{})}
+	orch := NewWithVAD(&growingSTT{}, llm, &MockTTSProvider{synthesizeResult: make([]byte, 64)}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("continuation"))
+	t.Cleanup(ms.Close)
+
+	speak(ms, 300*time.Millisecond)
+	select {
+	case <-llm.started:
+	case <-time.After(time.Second):
+		t.Fatal("the first utterance was not answered")
+	}
+	speak(ms, 300*time.Millisecond)
+	return ms, llm
+}
+
+func TestContinuation_MergesUtterances(t *testing.T) {
+	ms, llm := continuationStream(t, true)
+
+	var continued, responded bool
+	deadline := time.After(2 * time.Second)
+	for !responded {
+		select {
+		case ev := <-ms.Events():
+			switch ev.Type {
+			case UtteranceContinued:
+				continued = ev.Data == "book a table"
+			case Interrupted:
+				t.Error("a continuation must not interrupt")
+			case BotResponse:
+				responded = true
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for the reply")
+		}
+	}
+	if !continued {
+		t.Error("expected UTTERANCE_CONTINUED with the first transcript")
+	}
+
+	llm.mu.Lock()
+	var users []string
+	for _, msg := range llm.messages {
+		if msg.Role == "user" {
+			users = append(users, msg.Content)
+		}
+	}
+	llm.mu.Unlock()
+	if len(users) != 1 || users[0] != "book a table for two tonight" {
+		t.Errorf("expected one user message for both utterances, got %q", users)
+	}
+	if turns := ms.Turns(); len(turns) != 1 {
+		t.Errorf("expected a single turn, got %d", len(turns))
+	}
+	if n := ms.Status().Stats.Continuations; n != 1 {
+		t.Errorf("expected one continuation, got %d", n)
+	}
+}
+
+func TestContinuation_Disabled(t *testing.T) {
+	ms, _ := continuationStream(t, false)
+	waitForEvent(t, ms, Interrupted)
+	if n := ms.Status().Stats.Continuations; n != 0 {
+		t.Errorf("expected no continuations, got %d", n)
+	}
+}
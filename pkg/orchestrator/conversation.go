@@ -215,6 +215,7 @@ func (c *Conversation) GetLastAssistantMessage() string {
 
 
 func (c *Conversation) ClearContext() {
+	defer c.session.changed()
 	c.session.mu.Lock()
 	defer c.session.mu.Unlock()
 	
@@ -232,6 +233,7 @@ func (c *Conversation) ClearContext() {
 
 
 func (c *Conversation) Reset() {
+	defer c.session.changed()
 	c.session.mu.Lock()
 	defer c.session.mu.Unlock()
 	c.session.Context = []Message{}
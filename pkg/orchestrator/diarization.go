@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// DiarizingSTTProvider is implemented by streaming STT providers that tell
+// speakers apart, e.g. two people on a speakerphone. Each transcript comes
+// with the provider's label for its speaker ("0", "A", ...), or "" when it
+// doesn't know.
+type DiarizingSTTProvider interface {
+	StreamTranscribeSpeakers(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool, speaker string) error) (chan<- []byte, error)
+}
+
+// SpeakerChange is the data of a SpeakerChanged event.
+type SpeakerChange struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+}
+
+const speakersInstruction = "Several people are talking to you through the same microphone. Each of their messages starts with who is speaking, like \"user_1:\". Keep track of who said what and address them by name when it matters. Don't start your replies with a speaker label."
+
+// speaker returns the session's name for an STT speaker label: user_1,
+// user_2 and so on, in the order they first speak.
+func (s *ConversationSession) speaker(label string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name, ok := s.speakers[label]; ok {
+		return name
+	}
+	if s.speakers == nil {
+		s.speakers = make(map[string]string)
+	}
+	name := fmt.Sprintf("user_%d", len(s.speakers)+1)
+	s.speakers[label] = name
+	return name
+}
+
+// AddSpeakerMessage adds a user message said by speaker. With an empty
+// speaker it is AddMessage("user", content).
+func (s *ConversationSession) AddSpeakerMessage(speaker, content string) {
+	s.addMessage(Message{Role: "user", Content: content, Speaker: speaker})
+}
+
+// Speakers lists the speakers heard in the session's context, in the order
+// they first spoke.
+func (s *ConversationSession) Speakers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var speakers []string
+	for _, msg := range s.Context {
+		if msg.Speaker != "" && !slices.Contains(speakers, msg.Speaker) {
+			speakers = append(speakers, msg.Speaker)
+		}
+	}
+	return speakers
+}
+
+// addUserMessage adds a final transcript to the session, as said by the
+// speaker the STT labeled. It returns the text to answer, which includes the
+// question a barge-in canceled when the two were merged.
+func (ms *ManagedStream) addUserMessage(transcript, label, turnID string) string {
+	if ms.continuedTranscript(transcript, turnID) {
+		return transcript
+	}
+	if merged, ok := ms.mergeCanceled(transcript); ok {
+		return merged
+	}
+	if label == "" {
+		ms.session.AddMessage("user", transcript)
+		return transcript
+	}
+	speaker := ms.session.speaker(label)
+	ms.mu.Lock()
+	from := ms.lastSpeaker
+	ms.lastSpeaker = speaker
+	ms.mu.Unlock()
+
+	ms.updateTurn(turnID, func(t *Turn) { t.Speaker = speaker })
+	if speaker != from {
+		ms.emitForTurn(SpeakerChanged, SpeakerChange{From: from, To: speaker}, turnID)
+	}
+	ms.session.AddSpeakerMessage(speaker, transcript)
+	return transcript
+}
+
+// withSpeakers names the speaker of each user message once the context
+// holds more than one, so the LLM can tell them apart.
+func withSpeakers(messages []Message) []Message {
+	var speakers []string
+	for _, msg := range messages {
+		if msg.Speaker != "" && !slices.Contains(speakers, msg.Speaker) {
+			speakers = append(speakers, msg.Speaker)
+		}
+	}
+	if len(speakers) < 2 {
+		return messages
+	}
+	out := make([]Message, len(messages))
+	for i, msg := range messages {
+		if msg.Speaker != "" {
+			msg.Content = msg.Speaker + ": " + msg.Content
+		}
+		out[i] = msg
+	}
+	return withStyle(out, speakersInstruction)
+}
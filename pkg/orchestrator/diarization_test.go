@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// diarizedSTT attributes each utterance to the next of its speakers.
+type diarizedSTT struct {
+	MockSTTProvider
+	mu       sync.Mutex
+	speakers []string
+}
+
+func (s *diarizedSTT) TranscribeDetailed(ctx context.Context, audio []byte, lang Language) (*TranscriptionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	speaker := s.speakers[0]
+	s.speakers = s.speakers[1:]
+	return &TranscriptionResult{Text: "is the pool open", Speaker: speaker}, nil
+}
+
+// diarizingStreamSTT hands its transcript callback to the test.
+type diarizingStreamSTT struct {
+	MockSTTProvider
+	onTranscript chan func(transcript string, isFinal bool, speaker string) error
+}
+
+func (s *diarizingStreamSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	panic("diarizing providers are streamed with StreamTranscribeSpeakers")
+}
+
+func (s *diarizingStreamSTT) StreamTranscribeSpeakers(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool, speaker string) error) (chan<- []byte, error) {
+	s.onTranscript <- onTranscript
+	return make(chan []byte, 100), nil
+}
+
+func TestDiarization_NamesSpeakers(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	llm := &capturingLLM{}
+	stt := &diarizedSTT{speakers: []string{"0", "1", "0"}}
+	orch := NewWithVAD(stt, llm, &MockTTSProvider{}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("speakerphone"))
+	defer ms.Close()
+
+	var turns []string
+	for range 3 {
+		turns = append(turns, ms.beginTurn())
+		ms.runBatchPipeline(make([]byte, 32000))
+	}
+
+	var changes []SpeakerChange
+	for len(changes) < 3 {
+		changes = append(changes, waitForEvent(t, ms, SpeakerChanged).Data.(SpeakerChange))
+	}
+	want := []SpeakerChange{{To: "user_1"}, {From: "user_1", To: "user_2"}, {From: "user_2", To: "user_1"}}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: got %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if speakers := ms.session.Speakers(); len(speakers) != 2 || speakers[0] != "user_1" || speakers[1] != "user_2" {
+		t.Errorf("unexpected speakers %v", speakers)
+	}
+	if turn, _ := ms.GetTurn(turns[1]); turn.Speaker != "user_2" {
+		t.Errorf("expected the second turn to be user_2's, got %q", turn.Speaker)
+	}
+	if entries := ms.session.Transcript(); entries[0].Speaker != "user_1" || entries[2].Speaker != "user_2" {
+		t.Errorf("unexpected transcript %+v", entries)
+	}
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	var prompt []string
+	for _, msg := range llm.messages {
+		prompt = append(prompt, msg.Content)
+	}
+	joined := strings.Join(prompt, "\n")
+	for _, line := range []string{speakersInstruction, "user_1: is the pool open", "user_2: is the pool open"} {
+		if !strings.Contains(joined, line) {
+			t.Errorf("the LLM was not sent %q:\n%s", line, joined)
+		}
+	}
+}
+
+func TestDiarization_Streaming(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	stt := &diarizingStreamSTT{onTranscript: make(chan func(string, bool, string) error, 1)}
+	orch := NewWithVAD(stt, &MockLLMProvider{completeResult: "Until ten."}, &MockTTSProvider{}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("speakerphone"))
+	defer ms.Close()
+
+	ms.beginTurn()
+	ms.startStreamingSTT(stt)
+	onTranscript := <-stt.onTranscript
+	ms.mu.Lock()
+	ms.sttStartTime = time.Now().Add(-time.Second)
+	ms.mu.Unlock()
+	if err := onTranscript("is the gym open late", true, "A"); err != nil {
+		t.Fatal(err)
+	}
+
+	if change := waitForEvent(t, ms, SpeakerChanged).Data.(SpeakerChange); change.To != "user_1" {
+		t.Errorf("unexpected change %+v", change)
+	}
+	if msgs := ms.session.GetContextCopy(); len(msgs) == 0 || msgs[0].Speaker != "user_1" {
+		t.Errorf("unexpected context %+v", msgs)
+	}
+}
+
+func TestWithSpeakers_OneSpeaker(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are a concierge."},
+		{Role: "user", Content: "hi", Speaker: "user_1"},
+		{Role: "assistant", Content: "Hello!"},
+	}
+	if got := withSpeakers(messages); len(got) != 3 || got[1].Content != "hi" {
+		t.Errorf("a lone speaker should not be labeled: %+v", got)
+	}
+}
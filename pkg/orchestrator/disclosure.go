@@ -0,0 +1,184 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DisclosureCallStart is the point at the start of every call, before the
+// greeting.
+const DisclosureCallStart = "call_start"
+
+// ComplianceConfig lists the disclosures that must be spoken word for word,
+// such as recording notices and payment terms.
+type ComplianceConfig struct {
+	Disclosures []Disclosure
+}
+
+// Disclosure is a phrase that must be spoken at a point of the call. It is
+// never worded by the LLM: its audio is synthesized once, or taken from
+// Audio, and replayed exactly every time.
+type Disclosure struct {
+	ID   string
+	Text string
+	// At is DisclosureCallStart, the name of a Flow state, spoken once the
+	// conversation enters it, or any point the application passes to
+	// ManagedStream.Disclose, such as "payment".
+	At string
+	// Audio, when set, is the approved recording of Text as 16-bit PCM at
+	// Config.SampleRate, played instead of synthesizing it.
+	Audio []byte
+}
+
+// DisclosureStatus reports whether a disclosure was spoken to the caller.
+type DisclosureStatus struct {
+	ID        string    `json:"id"`
+	At        string    `json:"at"`
+	Fulfilled bool      `json:"fulfilled"`
+	SpokenAt  time.Time `json:"spoken_at,omitempty"`
+}
+
+func (c ComplianceConfig) at(point string) []Disclosure {
+	var out []Disclosure
+	for _, d := range c.Disclosures {
+		if d.At == point {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// disclosureCache holds synthesized disclosures by ttsCacheKey. Unlike the
+// TTS cache it is never evicted.
+type disclosureCache struct {
+	mu  sync.Mutex
+	pcm map[string][]byte
+}
+
+// disclosureSpeech returns the audio of text if it is a disclosure that was
+// recorded or synthesized before.
+func (o *Orchestrator) disclosureSpeech(text string, voice Voice, lang Language) ([]byte, bool) {
+	for _, d := range o.GetConfig().Compliance.Disclosures {
+		if d.Text == text && len(d.Audio) > 0 {
+			return d.Audio, true
+		}
+	}
+	o.disclosures.mu.Lock()
+	defer o.disclosures.mu.Unlock()
+	pcm, ok := o.disclosures.pcm[o.ttsCacheKey(text, voice, lang)]
+	return pcm, ok
+}
+
+// renderDisclosure synthesizes d in full unless its audio is known.
+func (o *Orchestrator) renderDisclosure(ctx context.Context, d Disclosure, voice Voice, lang Language) error {
+	if _, ok := o.disclosureSpeech(d.Text, voice, lang); ok {
+		return nil
+	}
+	var pcm []byte
+	err := o.SynthesizeStream(ctx, d.Text, voice, lang, func(chunk []byte) error {
+		pcm = append(pcm, chunk...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.disclosures.mu.Lock()
+	defer o.disclosures.mu.Unlock()
+	if o.disclosures.pcm == nil {
+		o.disclosures.pcm = make(map[string][]byte)
+	}
+	o.disclosures.pcm[o.ttsCacheKey(d.Text, voice, lang)] = pcm
+	return nil
+}
+
+// PrepareDisclosures synthesizes every disclosure in voice and lang ahead of
+// calls, so none waits on the TTS provider. Disclosures not prepared are
+// synthesized the first time they are due.
+func (o *Orchestrator) PrepareDisclosures(ctx context.Context, voice Voice, lang Language) error {
+	for _, d := range o.GetConfig().Compliance.Disclosures {
+		if err := o.renderDisclosure(ctx, d, voice, lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisclosureStatus reports every disclosure in Config.Compliance for the
+// session, in order.
+func (o *Orchestrator) DisclosureStatus(session *ConversationSession) []DisclosureStatus {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+	var out []DisclosureStatus
+	for _, d := range o.GetConfig().Compliance.Disclosures {
+		spoken, ok := session.disclosed[d.ID]
+		out = append(out, DisclosureStatus{ID: d.ID, At: d.At, Fulfilled: ok, SpokenAt: spoken})
+	}
+	return out
+}
+
+// Disclosed reports whether the disclosure with id was spoken in full.
+func (s *ConversationSession) Disclosed(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.disclosed[id]
+	return ok
+}
+
+func (s *ConversationSession) markDisclosed(id string, at time.Time) {
+	s.mu.Lock()
+	if s.disclosed == nil {
+		s.disclosed = make(map[string]time.Time)
+	}
+	s.disclosed[id] = at
+	s.mu.Unlock()
+	s.changed()
+}
+
+// Disclose speaks the disclosures due at point that the session has not
+// heard yet, each as its own turn added to the context, and emits
+// DisclosureSpoken for each. It returns nil once all of them are fulfilled;
+// a disclosure the caller interrupts stays unfulfilled and Disclose returns
+// ErrSpeechInterrupted, so the application can hold off, say, taking a
+// payment until it has been heard. Errors are those of Say, or of the TTS
+// provider when the disclosure could not be synthesized.
+func (ms *ManagedStream) Disclose(ctx context.Context, point string) error {
+	voice, lang := ms.session.GetCurrentVoice(), ms.session.OutputLanguage()
+	for _, d := range ms.orch.GetConfig().Compliance.at(point) {
+		if ms.session.Disclosed(d.ID) {
+			continue
+		}
+		if err := ms.orch.renderDisclosure(ctx, d, voice, lang); err != nil {
+			return err
+		}
+		if err := ms.say(ctx, d.Text, true); err != nil {
+			return err
+		}
+		now := time.Now()
+		ms.session.markDisclosed(d.ID, now)
+		ms.emit(DisclosureSpoken, DisclosureStatus{ID: d.ID, At: d.At, Fulfilled: true, SpokenAt: now})
+	}
+	return nil
+}
+
+// discloseAfterReply speaks the disclosures of the flow states a reply
+// entered, and retries the call start ones the caller cut short.
+func (ms *ManagedStream) discloseAfterReply(ctx context.Context, entered []string) {
+	for _, point := range slices.Concat([]string{DisclosureCallStart}, entered) {
+		if err := ms.Disclose(ctx, point); err != nil {
+			return
+		}
+	}
+}
+
+// playDisclosure emits the known audio of text, and reports false when text
+// is not a disclosure.
+func (ms *ManagedStream) playDisclosure(text string, voice Voice, lang Language, onChunk func([]byte) error) (bool, error) {
+	pcm, ok := ms.orch.disclosureSpeech(text, voice, lang)
+	if !ok {
+		return false, nil
+	}
+	return true, onChunk(bytes.Clone(pcm))
+}
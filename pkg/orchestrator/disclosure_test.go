@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+const recordingNotice = "This call is recorded for quality purposes."
+
+func TestDisclosure_SpokenAtCallStart(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Compliance.Disclosures = []Disclosure{{ID: "recording", Text: recordingNotice, At: DisclosureCallStart}}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Hi, how can I help?"}, &MockTTSProvider{synthesizeResult: make([]byte, 64)}, cfg)
+	session := NewConversationSession("disclose")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != recordingNotice {
+		t.Errorf("expected the notice before the greeting, got %q", ev.Data)
+	}
+	ev := waitForEvent(t, ms, DisclosureSpoken)
+	if status := ev.Data.(DisclosureStatus); status.ID != "recording" || !status.Fulfilled {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "Hi, how can I help?" {
+		t.Errorf("expected the greeting next, got %q", ev.Data)
+	}
+	if !session.Disclosed("recording") {
+		t.Error("expected the notice to be fulfilled")
+	}
+	if msgs := session.GetContextCopy(); len(msgs) == 0 || msgs[0].Content != recordingNotice {
+		t.Errorf("expected the notice in the context, got %+v", msgs)
+	}
+
+	restored := RestoreSession(session.Snapshot())
+	if status := orch.DisclosureStatus(restored); len(status) != 1 || !status[0].Fulfilled {
+		t.Errorf("expected the disclosure to survive a transfer, got %+v", status)
+	}
+}
+
+func TestDisclosure_PlaysExactAudio(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	approved := bytes.Repeat([]byte{1, 2}, 100)
+	cfg.Compliance.Disclosures = []Disclosure{
+		{ID: "terms", Text: "Your card will be charged today.", At: "payment", Audio: approved},
+		{ID: "refunds", Text: "Refunds take five days.", At: "payment"},
+	}
+	tts := &countingTTS{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+	session := NewConversationSession("disclose")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	if status := orch.DisclosureStatus(session); len(status) != 2 || status[0].Fulfilled || status[1].Fulfilled {
+		t.Fatalf("expected both disclosures pending, got %+v", status)
+	}
+	if err := orch.PrepareDisclosures(context.Background(), session.GetCurrentVoice(), session.OutputLanguage()); err != nil {
+		t.Fatal(err)
+	}
+	if tts.count() != 1 {
+		t.Errorf("expected only the unrecorded disclosure to be synthesized, got %d calls", tts.count())
+	}
+
+	if err := ms.Disclose(context.Background(), "payment"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, ms, AudioChunk); !bytes.Equal(ev.Data.([]byte), approved) {
+		t.Errorf("expected the approved recording, got %d bytes", len(ev.Data.([]byte)))
+	}
+	if tts.count() != 1 {
+		t.Errorf("expected prepared audio to be replayed, got %d calls", tts.count())
+	}
+	for _, status := range orch.DisclosureStatus(session) {
+		if !status.Fulfilled {
+			t.Errorf("expected %s to be fulfilled", status.ID)
+		}
+	}
+
+	turns := len(ms.Turns())
+	if err := ms.Disclose(context.Background(), "payment"); err != nil || len(ms.Turns()) != turns {
+		t.Errorf("expected fulfilled disclosures not to be repeated, got %v", err)
+	}
+}
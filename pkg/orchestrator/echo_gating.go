@@ -0,0 +1,175 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/aec"
+)
+
+type EchoAction string
+
+const (
+	EchoTag  EchoAction = "tag"
+	EchoDrop EchoAction = "drop"
+)
+
+// EchoConfig decides what happens to inbound chunks the echo suppressor
+// classifies as the bot's own voice. Echo never triggers barge-in. With
+// EchoTag (the default) it still reaches STT and is only counted, because a
+// false positive would otherwise swallow the start of a real user turn.
+// EchoDrop keeps it out of STT and the turn buffers.
+//
+// The remaining fields tune the echo suppressor; zero keeps the default.
+// Threshold is the correlation above which input counts as echo and takes
+// precedence over Config.EchoSuppressionThreshold. Input is only checked for
+// SilenceWindow (2s) after the bot last played audio. ReferenceBuffer (2s) is
+// how much played audio is kept, and SearchWindow (1.5s) how far back each
+// chunk is searched: raise both for devices with long playback latency, such
+// as Bluetooth headsets. EnvelopeMargin (0.05) and EnvelopeDecimation (8)
+// tune the loudness-envelope check that catches distorted echo.
+//
+// Strategy picks the EchoProcessor; NLMS and Watermark tune the processors
+// of the same name, and AEC the canceller of EchoStrategyAEC and
+// EchoSuppressor.RemoveEchoRealtime.
+type EchoConfig struct {
+	Strategy EchoStrategy
+	Action   EchoAction
+	// ClassificationEvents emits an AudioClassified event for every inbound
+	// chunk. Meant for tuning the echo threshold; it is one event per chunk.
+	ClassificationEvents bool
+
+	Threshold          float64
+	SilenceWindow      time.Duration
+	ReferenceBuffer    time.Duration
+	SearchWindow       time.Duration
+	EnvelopeMargin     float64
+	EnvelopeDecimation int
+
+	NLMS      NLMSOptions
+	Watermark WatermarkOptions
+	AEC       aec.Config
+}
+
+// AudioClass is the data of an AudioClassified event.
+type AudioClass struct {
+	Echo    bool    `json:"echo"`
+	Dropped bool    `json:"dropped"`
+	RMS     float64 `json:"rms"`
+	Bytes   int     `json:"bytes"`
+}
+
+// classifyChunk counts an inbound chunk's echo classification and reports
+// whether it must be dropped.
+func (ms *ManagedStream) classifyChunk(chunk []byte, isEcho bool) bool {
+	cfg := ms.EchoConfig()
+	drop := isEcho && cfg.Action == EchoDrop
+
+	ms.mu.Lock()
+	if isEcho {
+		ms.stats.EchoChunks++
+	}
+	if drop {
+		ms.stats.EchoChunksDropped++
+	}
+	ms.mu.Unlock()
+
+	if cfg.ClassificationEvents {
+		ms.emit(AudioClassified, AudioClass{
+			Echo:    isEcho,
+			Dropped: drop,
+			RMS:     pcmRMS(chunk),
+			Bytes:   len(chunk),
+		})
+	}
+	return drop
+}
+
+// EchoConfig returns the stream's echo settings: Config.Echo unless
+// SetEchoConfig replaced them.
+func (ms *ManagedStream) EchoConfig() EchoConfig {
+	ms.mu.Lock()
+	override := ms.echoConfig
+	ms.mu.Unlock()
+	if override != nil {
+		return *override
+	}
+	if ms.orch != nil {
+		return ms.orch.GetConfig().Echo
+	}
+	return DefaultConfig().Echo
+}
+
+// SetEchoConfig changes echo handling for this stream only, e.g. once a
+// client reports that it runs on a Bluetooth headset. Tuning fields left at
+// zero keep the suppressor's current value. A different Strategy replaces
+// the echo processor.
+func (ms *ManagedStream) SetEchoConfig(cfg EchoConfig) {
+	ms.mu.Lock()
+	ms.echoConfig = &cfg
+	ms.mu.Unlock()
+	if ms.echoSuppressor != nil {
+		ms.echoSuppressor.Configure(cfg)
+	}
+
+	strategy := cfg.Strategy
+	if strategy == "" {
+		strategy = EchoStrategyCorrelation
+	}
+	if current := ms.activeEcho(); current != nil && current.Name() == string(strategy) {
+		return
+	}
+	if strategy == EchoStrategyCorrelation && ms.echoSuppressor != nil {
+		ms.SetEchoProcessor(nil)
+		return
+	}
+	config := DefaultConfig()
+	if ms.orch != nil {
+		config = ms.orch.GetConfig()
+	}
+	config.Echo = cfg
+	ms.SetEchoProcessor(NewEchoProcessor(strategy, config))
+}
+
+// SetEchoThreshold changes the echo correlation threshold for this stream.
+func (ms *ManagedStream) SetEchoThreshold(threshold float64) {
+	cfg := ms.EchoConfig()
+	cfg.Threshold = threshold
+	ms.SetEchoConfig(cfg)
+}
+
+// activeEcho returns the stream's echo processor, or nil when echo handling
+// is off.
+func (ms *ManagedStream) activeEcho() EchoProcessor {
+	ms.mu.Lock()
+	p := ms.echoProcessor
+	ms.mu.Unlock()
+	if p != nil {
+		return p
+	}
+	if ms.echoSuppressor != nil {
+		return &CorrelationEchoProcessor{ms.echoSuppressor}
+	}
+	return nil
+}
+
+func (ms *ManagedStream) resetEcho() {
+	if echo := ms.activeEcho(); echo != nil {
+		echo.Reset()
+	}
+}
+
+// SetEchoProcessor replaces the stream's echo processor, e.g. with
+// NoEchoProcessor once a client reports hardware echo cancellation. nil
+// restores the correlation suppressor.
+func (ms *ManagedStream) SetEchoProcessor(p EchoProcessor) {
+	if p == nil && ms.echoSuppressor == nil {
+		p = NoEchoProcessor{}
+	}
+	ms.mu.Lock()
+	playbackRate, inputRate := ms.playbackRate, ms.inputRate
+	ms.echoProcessor = p
+	ms.mu.Unlock()
+	if s, ok := p.(echoRateSetter); ok && playbackRate > 0 && inputRate > 0 {
+		s.SetSampleRates(playbackRate, inputRate)
+	}
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"math"
+	"testing"
+)
+
+func speechLike(samples int) []byte {
+	pcm := make([]byte, samples*2)
+	for i := 0; i < samples; i++ {
+		t := float64(i) / 44100
+		v := int16(6000*math.Sin(2*math.Pi*220*t) + 3000*math.Sin(2*math.Pi*570*t))
+		pcm[2*i] = byte(v)
+		pcm[2*i+1] = byte(v >> 8)
+	}
+	return pcm
+}
+
+func echoStream(t *testing.T, echo EchoConfig) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.Echo = echo
+	ms := newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+
+	played := speechLike(8820)
+	ms.RecordPlayedOutput(played)
+	ms.NotifyAudioPlayed()
+	if !ms.echoSuppressor.IsEchoFast(played[:4410]) {
+		t.Fatal("echo suppressor does not classify the fixture as echo")
+	}
+	return ms
+}
+
+func TestManagedStream_EchoTaggedByDefault(t *testing.T) {
+	ms := echoStream(t, EchoConfig{ClassificationEvents: true})
+
+	chunk := speechLike(8820)[:4410]
+	ms.doWrite(chunk)
+
+	stats := ms.Status().Stats
+	if stats.EchoChunks != 1 || stats.EchoChunksDropped != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	ms.mu.Lock()
+	forwarded := len(ms.lastUserAudio)
+	ms.mu.Unlock()
+	if forwarded != len(chunk) {
+		t.Errorf("tagged echo must still be forwarded, got %d bytes", forwarded)
+	}
+
+	ev := <-ms.Events()
+	class, ok := ev.Data.(AudioClass)
+	if ev.Type != AudioClassified || !ok || !class.Echo || class.Dropped || class.Bytes != len(chunk) {
+		t.Errorf("unexpected classification %v %+v", ev.Type, ev.Data)
+	}
+}
+
+func TestManagedStream_EchoDropped(t *testing.T) {
+	ms := echoStream(t, EchoConfig{Action: EchoDrop})
+
+	ms.doWrite(speechLike(8820)[:4410])
+
+	if stats := ms.Status().Stats; stats.EchoChunks != 1 || stats.EchoChunksDropped != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.lastUserAudio) != 0 || ms.audioBuf.Len() != 0 {
+		t.Error("dropped echo must not reach the turn buffers")
+	}
+	select {
+	case ev := <-ms.events:
+		if ev.Type == AudioClassified {
+			t.Error("classification events are opt-in")
+		}
+	default:
+	}
+}
+
+func TestManagedStream_SetEchoConfig(t *testing.T) {
+	ms := echoStream(t, EchoConfig{})
+	ms.SetEchoConfig(EchoConfig{Action: EchoDrop, Threshold: 0.7})
+
+	if ms.EchoConfig().Action != EchoDrop {
+		t.Fatal("expected the per-stream config to win over Config.Echo")
+	}
+	if ms.orch.GetConfig().Echo.Action != "" {
+		t.Error("SetEchoConfig must not change the orchestrator's config")
+	}
+	if ms.echoSuppressor.echoThreshold != 0.7 {
+		t.Errorf("expected threshold 0.7, got %v", ms.echoSuppressor.echoThreshold)
+	}
+
+	ms.doWrite(speechLike(8820)[:4410])
+	if ms.Status().Stats.EchoChunksDropped != 1 {
+		t.Error("expected echo to be dropped after SetEchoConfig")
+	}
+
+	ms.SetEchoThreshold(0.95)
+	if cfg := ms.EchoConfig(); cfg.Threshold != 0.95 || cfg.Action != EchoDrop {
+		t.Errorf("SetEchoThreshold must keep the rest of the config, got %+v", cfg)
+	}
+}
@@ -0,0 +1,373 @@
+package orchestrator
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"time"
+)
+
+type EchoStrategy string
+
+const (
+	// EchoStrategyCorrelation compares input with recently played audio and
+	// tags matches as echo. It is the default.
+	EchoStrategyCorrelation EchoStrategy = "correlation"
+	// EchoStrategyNone skips echo processing, for clients with hardware AEC
+	// or headsets.
+	EchoStrategyNone EchoStrategy = "none"
+	// EchoStrategyNLMS subtracts an adaptive estimate of the echo from the
+	// input and forwards the cleaned audio.
+	EchoStrategyNLMS EchoStrategy = "nlms"
+	// EchoStrategyAEC cancels echo with a frequency-domain adaptive filter
+	// that keeps the caller's speech when both talk at once, and forwards
+	// the cleaned audio.
+	EchoStrategyAEC EchoStrategy = "aec"
+	// EchoStrategyWatermark mixes an inaudible pilot tone into the bot's
+	// audio and tags input that carries it as echo.
+	EchoStrategyWatermark EchoStrategy = "watermark"
+)
+
+// EchoProcessor handles the bot's own voice picked up by the caller's
+// microphone. ManagedStream feeds it played audio through RecordPlayed and
+// every inbound chunk through Process. history is the input preceding chunk
+// (up to 100ms). Process returns the audio to pass on, which cancellers may
+// have cleaned, and whether the chunk is echo. Reset is called when playback
+// is interrupted.
+//
+// A processor may also implement SetSampleRates(playbackRate, inputRate int),
+// PostProcess(input []byte) []byte for ExportLastUserAudio, and EchoMarker.
+type EchoProcessor interface {
+	Name() string
+	RecordPlayed(chunk []byte)
+	Process(chunk, history []byte) ([]byte, bool)
+	Reset()
+}
+
+// EchoMarker is implemented by processors that alter the bot's audio before
+// it is emitted.
+type EchoMarker interface {
+	Mark(chunk []byte) []byte
+}
+
+type echoRateSetter interface {
+	SetSampleRates(playbackRate, inputRate int)
+}
+
+type echoPostProcessor interface {
+	PostProcess(input []byte) []byte
+}
+
+// NewEchoProcessor builds the processor for strategy with cfg's tuning.
+// Unknown strategies fall back to correlation.
+func NewEchoProcessor(strategy EchoStrategy, cfg Config) EchoProcessor {
+	switch strategy {
+	case EchoStrategyNone:
+		return NoEchoProcessor{}
+	case EchoStrategyNLMS:
+		return NewNLMSEchoCanceller(cfg.SampleRate, cfg.SampleRate, cfg.Echo.NLMS)
+	case EchoStrategyWatermark:
+		return NewWatermarkEchoDetector(cfg.SampleRate, cfg.SampleRate, cfg.Echo.Watermark)
+	case EchoStrategyAEC:
+		return NewAECEchoProcessor(cfg)
+	default:
+		return &CorrelationEchoProcessor{NewEchoSuppressorWithConfig(cfg)}
+	}
+}
+
+type NoEchoProcessor struct{}
+
+func (NoEchoProcessor) Name() string                                 { return string(EchoStrategyNone) }
+func (NoEchoProcessor) RecordPlayed(chunk []byte)                    {}
+func (NoEchoProcessor) Process(chunk, history []byte) ([]byte, bool) { return chunk, false }
+func (NoEchoProcessor) Reset()                                       {}
+
+// CorrelationEchoProcessor adapts an EchoSuppressor.
+type CorrelationEchoProcessor struct {
+	*EchoSuppressor
+}
+
+func (c *CorrelationEchoProcessor) Name() string { return string(EchoStrategyCorrelation) }
+
+func (c *CorrelationEchoProcessor) RecordPlayed(chunk []byte) {
+	c.RecordPlayedAudio(chunk)
+}
+
+func (c *CorrelationEchoProcessor) Process(chunk, history []byte) ([]byte, bool) {
+	checkBuf := make([]byte, 0, len(history)+len(chunk))
+	checkBuf = append(checkBuf, history...)
+	checkBuf = append(checkBuf, chunk...)
+	return chunk, c.IsEchoFast(checkBuf)
+}
+
+func (c *CorrelationEchoProcessor) Reset() {
+	c.ClearEchoBuffer()
+}
+
+// AECEchoProcessor forwards input with the echo removed by an
+// EchoSuppressor's canceller (see RemoveEchoRealtime), tuned by
+// Config.Echo.AEC. It needs the played audio via RecordPlayedOutput. A chunk
+// counts as echo when cancellation removed at least three quarters of its
+// energy, so a caller talking over the bot still barges in.
+type AECEchoProcessor struct {
+	*EchoSuppressor
+}
+
+func NewAECEchoProcessor(cfg Config) *AECEchoProcessor {
+	es := NewEchoSuppressorWithConfig(cfg)
+	es.mu.Lock()
+	es.startAECLocked()
+	es.mu.Unlock()
+	return &AECEchoProcessor{es}
+}
+
+func (a *AECEchoProcessor) Name() string { return string(EchoStrategyAEC) }
+
+func (a *AECEchoProcessor) RecordPlayed(chunk []byte) {
+	a.RecordPlayedAudio(chunk)
+}
+
+func (a *AECEchoProcessor) Process(chunk, history []byte) ([]byte, bool) {
+	return a.cancelEcho(chunk)
+}
+
+// PostProcess returns input as is: it was cleaned on the way in.
+func (a *AECEchoProcessor) PostProcess(input []byte) []byte {
+	return bytes.Clone(input)
+}
+
+func (a *AECEchoProcessor) Reset() {
+	a.ClearEchoBuffer()
+}
+
+// NLMSOptions tune NLMSEchoCanceller. Taps (512) is the filter length in
+// input samples and bounds the echo tail it can model; Delay skips the
+// playback latency before the tail starts; Step (0.5) trades adaptation speed
+// against stability.
+type NLMSOptions struct {
+	Taps  int
+	Step  float64
+	Delay time.Duration
+}
+
+// NLMSEchoCanceller is a normalised least-mean-squares acoustic echo
+// canceller. It needs the played audio through RecordPlayedOutput, aligned
+// with the input it echoes into. A chunk counts as echo when cancellation
+// removed at least three quarters of its energy.
+type NLMSEchoCanceller struct {
+	mu           sync.Mutex
+	opts         NLMSOptions
+	weights      []float64
+	ref          []float64 // last len(weights) far-end samples, circular
+	pos          int
+	refEnergy    float64
+	far          []float64 // far-end samples not yet matched with input
+	playbackRate int
+	inputRate    int
+}
+
+func NewNLMSEchoCanceller(playbackRate, inputRate int, opts NLMSOptions) *NLMSEchoCanceller {
+	if opts.Taps <= 0 {
+		opts.Taps = 512
+	}
+	if opts.Step <= 0 || opts.Step >= 2 {
+		opts.Step = 0.5
+	}
+	if playbackRate <= 0 {
+		playbackRate = 44100
+	}
+	if inputRate <= 0 {
+		inputRate = playbackRate
+	}
+	return &NLMSEchoCanceller{
+		opts:         opts,
+		weights:      make([]float64, opts.Taps),
+		ref:          make([]float64, opts.Taps),
+		playbackRate: playbackRate,
+		inputRate:    inputRate,
+	}
+}
+
+func (n *NLMSEchoCanceller) Name() string { return string(EchoStrategyNLMS) }
+
+func (n *NLMSEchoCanceller) SetSampleRates(playbackRate, inputRate int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if playbackRate > 0 {
+		n.playbackRate = playbackRate
+	}
+	if inputRate > 0 {
+		n.inputRate = inputRate
+	}
+}
+
+func (n *NLMSEchoCanceller) RecordPlayed(chunk []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	samples := resample(bytesToSamples(chunk), n.playbackRate, n.inputRate)
+	if len(n.far) == 0 {
+		delay := int(n.opts.Delay.Seconds() * float64(n.inputRate))
+		n.far = make([]float64, delay, delay+len(samples))
+	}
+	n.far = append(n.far, samples...)
+	// Input stopped arriving; don't let the backlog grow without bound.
+	if max := 2 * n.inputRate; len(n.far) > max {
+		n.far = n.far[len(n.far)-max:]
+	}
+}
+
+func (n *NLMSEchoCanceller) Process(chunk, history []byte) ([]byte, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.far) == 0 && n.refEnergy < 1e-9 {
+		return chunk, false
+	}
+
+	in := bytesToSamples(chunk)
+	out := bytes.Clone(chunk) // keeps an odd trailing byte as it was
+	var inEnergy, outEnergy float64
+	taps := len(n.weights)
+	for i, d := range in {
+		x := 0.0
+		if len(n.far) > 0 {
+			x = n.far[0]
+			n.far = n.far[1:]
+		}
+		n.refEnergy += x*x - n.ref[n.pos]*n.ref[n.pos]
+		n.ref[n.pos] = x
+
+		// ref[pos] is the newest sample, ref[pos-k] the one k samples back.
+		y := 0.0
+		for k := 0; k < taps; k++ {
+			y += n.weights[k] * n.ref[(n.pos-k+taps)%taps]
+		}
+		e := d - y
+		if n.refEnergy > 1e-6 {
+			g := n.opts.Step * e / (n.refEnergy + 1e-6)
+			for k := 0; k < taps; k++ {
+				n.weights[k] += g * n.ref[(n.pos-k+taps)%taps]
+			}
+		}
+		n.pos = (n.pos + 1) % taps
+
+		inEnergy += d * d
+		outEnergy += e * e
+		v := int16(math.Max(-32768, math.Min(32767, e*32768)))
+		out[2*i] = byte(v)
+		out[2*i+1] = byte(uint16(v) >> 8)
+	}
+	return out, inEnergy > 1e-6 && outEnergy < inEnergy/4
+}
+
+func (n *NLMSEchoCanceller) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.far = nil
+}
+
+// WatermarkOptions tune WatermarkEchoDetector. The pilot tone is mixed into
+// the bot's audio at Frequency (18kHz) and Level (0.003, about -50dBFS);
+// input is echo when the tone is found above Threshold (Level/4) within
+// Window (2s) of the last marked audio.
+type WatermarkOptions struct {
+	Frequency float64
+	Level     float64
+	Threshold float64
+	Window    time.Duration
+}
+
+// WatermarkEchoDetector tags echo by a pilot tone it mixes into the bot's
+// audio, so it needs no copy of the played audio and is immune to playback
+// latency. Both sample rates must be high enough to carry the tone, which
+// rules out telephone audio; on such streams it detects nothing.
+type WatermarkEchoDetector struct {
+	mu           sync.Mutex
+	opts         WatermarkOptions
+	phase        float64
+	lastMarked   time.Time
+	playbackRate int
+	inputRate    int
+}
+
+func NewWatermarkEchoDetector(playbackRate, inputRate int, opts WatermarkOptions) *WatermarkEchoDetector {
+	if opts.Frequency <= 0 {
+		opts.Frequency = 18000
+	}
+	if opts.Level <= 0 {
+		opts.Level = 0.003
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = opts.Level / 4
+	}
+	if opts.Window <= 0 {
+		opts.Window = 2 * time.Second
+	}
+	if playbackRate <= 0 {
+		playbackRate = 44100
+	}
+	if inputRate <= 0 {
+		inputRate = playbackRate
+	}
+	return &WatermarkEchoDetector{opts: opts, playbackRate: playbackRate, inputRate: inputRate}
+}
+
+func (w *WatermarkEchoDetector) Name() string { return string(EchoStrategyWatermark) }
+
+func (w *WatermarkEchoDetector) SetSampleRates(playbackRate, inputRate int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if playbackRate > 0 {
+		w.playbackRate = playbackRate
+	}
+	if inputRate > 0 {
+		w.inputRate = inputRate
+	}
+}
+
+func (w *WatermarkEchoDetector) Mark(chunk []byte) []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.opts.Frequency >= float64(w.playbackRate)/2 {
+		return chunk
+	}
+	step := 2 * math.Pi * w.opts.Frequency / float64(w.playbackRate)
+	out := make([]byte, len(chunk))
+	for i := 0; i+1 < len(chunk); i += 2 {
+		s := float64(int16(uint16(chunk[i])|uint16(chunk[i+1])<<8))/32768 + w.opts.Level*math.Sin(w.phase)
+		w.phase = math.Mod(w.phase+step, 2*math.Pi)
+		v := int16(math.Max(-32768, math.Min(32767, s*32768)))
+		out[i] = byte(v)
+		out[i+1] = byte(uint16(v) >> 8)
+	}
+	w.lastMarked = time.Now()
+	return out
+}
+
+func (w *WatermarkEchoDetector) RecordPlayed(chunk []byte) {}
+
+func (w *WatermarkEchoDetector) Process(chunk, history []byte) ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if time.Since(w.lastMarked) > w.opts.Window || w.opts.Frequency >= float64(w.inputRate)/2 {
+		return chunk, false
+	}
+	return chunk, toneAmplitude(bytesToSamples(chunk), w.opts.Frequency, w.inputRate) > w.opts.Threshold
+}
+
+func (w *WatermarkEchoDetector) Reset() {}
+
+// toneAmplitude estimates the amplitude of freq in samples with the Goertzel
+// algorithm.
+func toneAmplitude(samples []float64, freq float64, rate int) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	coeff := 2 * math.Cos(2*math.Pi*freq/float64(rate))
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := x + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return 2 * math.Sqrt(math.Max(power, 0)) / float64(len(samples))
+}
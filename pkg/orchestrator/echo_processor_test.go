@@ -0,0 +1,202 @@
+package orchestrator
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func noise(samples int, amp float64, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]float64, samples)
+	for i := range out {
+		out[i] = amp * (2*r.Float64() - 1)
+	}
+	return out
+}
+
+func TestNLMSEchoCanceller_CancelsEcho(t *testing.T) {
+	nlms := NewNLMSEchoCanceller(16000, 16000, NLMSOptions{Taps: 64})
+	far := noise(16000, 0.3, 1)
+
+	// The room delays the bot's voice by 10 samples and halves it.
+	near := make([]float64, len(far))
+	for i := 10; i < len(far); i++ {
+		near[i] = 0.5 * far[i-10]
+	}
+
+	var lastIn, lastOut []byte
+	var isEcho bool
+	for i := 0; i < len(far); i += 320 {
+		nlms.RecordPlayed(samplesToBytes(far[i : i+320]))
+		lastIn = samplesToBytes(near[i : i+320])
+		lastOut, isEcho = nlms.Process(lastIn, nil)
+	}
+	if !isEcho {
+		t.Error("converged echo not classified as echo")
+	}
+	if in, out := pcmEnergy(lastIn), pcmEnergy(lastOut); out > in/100 {
+		t.Errorf("residual echo energy %.4f of input %.4f", out, in)
+	}
+}
+
+func TestNLMSEchoCanceller_KeepsNearEndSpeech(t *testing.T) {
+	nlms := NewNLMSEchoCanceller(16000, 16000, NLMSOptions{Taps: 64})
+	far := noise(8000, 0.3, 1)
+	near := noise(8000, 0.3, 2)
+
+	echoes := 0
+	var in, out float64
+	for i := 0; i < len(far); i += 320 {
+		nlms.RecordPlayed(samplesToBytes(far[i : i+320]))
+		chunk := samplesToBytes(near[i : i+320])
+		cleaned, isEcho := nlms.Process(chunk, nil)
+		if isEcho {
+			echoes++
+		}
+		in += pcmEnergy(chunk)
+		out += pcmEnergy(cleaned)
+	}
+	if echoes != 0 {
+		t.Errorf("%d chunks of uncorrelated speech classified as echo", echoes)
+	}
+	if out < in/2 {
+		t.Errorf("near-end speech attenuated to %.2f of its energy", out/in)
+	}
+}
+
+func TestNLMSEchoCanceller_PassesThroughWithoutPlayback(t *testing.T) {
+	nlms := NewNLMSEchoCanceller(16000, 16000, NLMSOptions{})
+	chunk := samplesToBytes(noise(320, 0.3, 3))
+	out, isEcho := nlms.Process(chunk, nil)
+	if isEcho || string(out) != string(chunk) {
+		t.Error("input must pass through untouched when nothing was played")
+	}
+}
+
+// roomEcho delays far by 10 samples and halves it, like the room in the
+// NLMS tests.
+func roomEcho(far []float64) []float64 {
+	near := make([]float64, len(far))
+	for i := 10; i < len(far); i++ {
+		near[i] = 0.5 * far[i-10]
+	}
+	return near
+}
+
+func TestAECEchoProcessor_CancelsEchoAndKeepsDoubleTalk(t *testing.T) {
+	a := NewEchoProcessor(EchoStrategyAEC, Config{SampleRate: 16000})
+	if a.Name() != "aec" {
+		t.Fatalf("processor = %q", a.Name())
+	}
+	far := noise(32000, 0.3, 1)
+	near := roomEcho(far)
+	speech := noise(8000, 0.3, 2)
+	for i, s := range speech {
+		near[24000+i] += s
+	}
+
+	var echoes, talk int
+	var in, out float64
+	for i := 0; i < len(far); i += 320 {
+		a.RecordPlayed(samplesToBytes(far[i : i+320]))
+		chunk := samplesToBytes(near[i : i+320])
+		cleaned, isEcho := a.Process(chunk, nil)
+		switch {
+		case i >= 16000 && i < 24000:
+			if isEcho {
+				echoes++
+			}
+			in += pcmEnergy(chunk)
+			out += pcmEnergy(cleaned)
+		case i >= 24320 && isEcho:
+			talk++
+		}
+	}
+	if echoes != 25 {
+		t.Errorf("%d of 25 converged echo chunks classified as echo", echoes)
+	}
+	if out > in/100 {
+		t.Errorf("residual echo energy %.4f of input %.4f", out, in)
+	}
+	if talk != 0 {
+		t.Errorf("%d chunks of the caller talking over the bot classified as echo", talk)
+	}
+}
+
+func TestWatermarkEchoDetector(t *testing.T) {
+	w := NewWatermarkEchoDetector(48000, 48000, WatermarkOptions{})
+	speech := generateSine(300, 100, 48000, 0.3)
+
+	marked := w.Mark(speech)
+	if _, isEcho := w.Process(marked[:1920], nil); !isEcho {
+		t.Error("marked audio not detected")
+	}
+	if _, isEcho := w.Process(speech[:1920], nil); isEcho {
+		t.Error("unmarked speech detected as echo")
+	}
+}
+
+func TestWatermarkEchoDetector_LowSampleRate(t *testing.T) {
+	w := NewWatermarkEchoDetector(16000, 16000, WatermarkOptions{})
+	speech := generateSine(300, 100, 16000, 0.3)
+	if marked := w.Mark(speech); string(marked) != string(speech) {
+		t.Error("tone above Nyquist must not be mixed in")
+	}
+	if _, isEcho := w.Process(speech, nil); isEcho {
+		t.Error("nothing can be detected below the tone's Nyquist rate")
+	}
+}
+
+func strategyStream(t *testing.T, strategy EchoStrategy) *ManagedStream {
+	cfg := DefaultConfig()
+	cfg.Echo.Strategy = strategy
+	return newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+}
+
+func TestManagedStream_EchoStrategyNone(t *testing.T) {
+	ms := strategyStream(t, EchoStrategyNone)
+	if name := ms.activeEcho().Name(); name != "none" {
+		t.Fatalf("processor = %q", name)
+	}
+
+	played := speechLike(8820)
+	ms.RecordPlayedOutput(played)
+	ms.NotifyAudioPlayed()
+	ms.doWrite(played[:4410])
+	if stats := ms.Status().Stats; stats.EchoChunks != 0 {
+		t.Errorf("no echo processing expected, got %d echo chunks", stats.EchoChunks)
+	}
+}
+
+func TestManagedStream_SetEchoConfigSwitchesStrategy(t *testing.T) {
+	ms := strategyStream(t, "")
+	if name := ms.activeEcho().Name(); name != "correlation" {
+		t.Fatalf("default processor = %q", name)
+	}
+
+	ms.SetEchoConfig(EchoConfig{Strategy: EchoStrategyNLMS})
+	nlms, ok := ms.activeEcho().(*NLMSEchoCanceller)
+	if !ok {
+		t.Fatalf("processor = %T", ms.activeEcho())
+	}
+	ms.SetEchoThreshold(0.9)
+	if ms.activeEcho() != nlms {
+		t.Error("changing tuning must keep the processor and its adapted state")
+	}
+
+	ms.SetEchoConfig(EchoConfig{})
+	if p, ok := ms.activeEcho().(*CorrelationEchoProcessor); !ok || p.EchoSuppressor != ms.echoSuppressor {
+		t.Errorf("processor = %T, want the stream's suppressor", ms.activeEcho())
+	}
+}
+
+func TestManagedStream_SetEchoProcessorAppliesSampleRates(t *testing.T) {
+	ms := strategyStream(t, "")
+	ms.SetEchoSampleRates(24000, 16000)
+
+	w := NewWatermarkEchoDetector(0, 0, WatermarkOptions{})
+	ms.SetEchoProcessor(w)
+	if w.playbackRate != 24000 || w.inputRate != 16000 {
+		t.Errorf("rates = %d/%d", w.playbackRate, w.inputRate)
+	}
+}
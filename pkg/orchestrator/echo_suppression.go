@@ -1,9 +1,12 @@
 package orchestrator
 
 import (
+	"bytes"
 	"math"
 	"sync"
 	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/aec"
 )
 
 type EchoSuppressor struct {
@@ -17,9 +20,18 @@ type EchoSuppressor struct {
 	lastTTSTime            time.Time
 	enabled                bool
 	recentPlaybackWindowMS int
+	bufferMS               int
+	searchWindowMS         int
+	envelopeMargin         float64
+	envelopeDecimation     int
 
 	playbackSampleRate int
 	inputSampleRate    int
+
+	// aec cancels echo for RemoveEchoRealtime. It is started by the first
+	// call, or by NewAECEchoProcessor, and fed played audio from then on.
+	aec       *aec.Canceller
+	aecConfig aec.Config
 }
 
 func (es *EchoSuppressor) getRecentSamplesInternal(limit int) []float64 {
@@ -102,9 +114,40 @@ func NewEchoSuppressorWithConfig(config Config) *EchoSuppressor {
 	if config.EchoSuppressionThreshold > 0 {
 		es.echoThreshold = config.EchoSuppressionThreshold
 	}
+	es.Configure(config.Echo)
 	return es
 }
 
+// Configure applies the tuning fields of cfg that are set; zero fields keep
+// their current value.
+func (es *EchoSuppressor) Configure(cfg EchoConfig) {
+	if cfg.Threshold > 0 {
+		es.SetThreshold(cfg.Threshold)
+	}
+	if cfg.SilenceWindow > 0 {
+		es.SetSilenceWindow(cfg.SilenceWindow)
+	}
+	if cfg.ReferenceBuffer > 0 {
+		es.SetReferenceBuffer(cfg.ReferenceBuffer)
+	}
+	if cfg.SearchWindow > 0 {
+		es.SetSearchWindow(cfg.SearchWindow)
+	}
+	if cfg.EnvelopeMargin != 0 || cfg.EnvelopeDecimation > 0 {
+		es.mu.Lock()
+		if cfg.EnvelopeMargin != 0 {
+			es.envelopeMargin = cfg.EnvelopeMargin
+		}
+		if cfg.EnvelopeDecimation > 0 {
+			es.envelopeDecimation = cfg.EnvelopeDecimation
+		}
+		es.mu.Unlock()
+	}
+	if cfg.AEC != (aec.Config{}) {
+		es.SetAECConfig(cfg.AEC)
+	}
+}
+
 func NewEchoSuppressorWithRates(playbackRate, inputRate int) *EchoSuppressor {
 	if playbackRate <= 0 {
 		playbackRate = 44100
@@ -119,6 +162,10 @@ func NewEchoSuppressorWithRates(playbackRate, inputRate int) *EchoSuppressor {
 		echoThreshold:          0.80,
 		echoSilenceMS:          2000,
 		recentPlaybackWindowMS: 2000,
+		bufferMS:               2000,
+		searchWindowMS:         1500,
+		envelopeMargin:         0.05,
+		envelopeDecimation:     8,
 		enabled:                true,
 		playbackSampleRate:     playbackRate,
 		inputSampleRate:        inputRate,
@@ -146,6 +193,9 @@ func (es *EchoSuppressor) RecordPlayedAudio(chunk []byte) {
 		}
 	}
 	es.lastTTSTime = time.Now()
+	if es.aec != nil {
+		es.aec.Play(resample(samples, es.playbackSampleRate, es.inputSampleRate))
+	}
 }
 
 func (es *EchoSuppressor) getSampleAt(i int) float64 {
@@ -177,7 +227,7 @@ func (es *EchoSuppressor) isEchoImpl(inputChunk []byte, fast bool) bool {
 	if fast {
 		// macOS CoreAudio and Bluetooth devices can have up to 800-1200ms of playback queue delay
 		// so we need at least a 1.5s search window to catch the echo.
-		maxWindow := int(float64(es.playbackSampleRate) * 1.5)
+		maxWindow := es.playbackSampleRate * es.searchWindowMS / 1000
 		if searchSize > maxWindow {
 			searchSize = maxWindow
 		}
@@ -194,8 +244,8 @@ func (es *EchoSuppressor) isEchoImpl(inputChunk []byte, fast bool) bool {
 		return true
 	}
 
-	envCorr := es.maxEnvelopeCorrelationRing(inputSamples, searchSize, 8)
-	return envCorr > threshold+0.05
+	envCorr := es.maxEnvelopeCorrelationRing(inputSamples, searchSize, es.envelopeDecimation)
+	return envCorr > threshold+es.envelopeMargin
 }
 
 func (es *EchoSuppressor) maxCorrelationRing(inputSamples []float64, searchSize int) float64 {
@@ -337,6 +387,8 @@ func (es *EchoSuppressor) maxEnvelopeCorrelationRing(inSamples []float64, search
 	return maxCorr
 }
 
+// bytesToSamples decodes 16-bit little-endian PCM. A trailing odd byte is
+// ignored.
 func bytesToSamples(data []byte) []float64 {
 	samples := make([]float64, 0, len(data)/2)
 	for i := 0; i < len(data)-1; i += 2 {
@@ -383,6 +435,9 @@ func (es *EchoSuppressor) ClearEchoBuffer() {
 	defer es.mu.Unlock()
 	es.writeIdx = 0
 	es.count = 0
+	if es.aec != nil {
+		es.aec.Flush()
+	}
 }
 
 func (es *EchoSuppressor) PostProcess(input []byte) []byte {
@@ -429,47 +484,58 @@ func (es *EchoSuppressor) PostProcess(input []byte) []byte {
 	return out
 }
 
+// RemoveEchoRealtime returns input with the echo of the audio passed to
+// RecordPlayedAudio subtracted by an adaptive canceller, so a caller talking
+// over the bot is kept rather than muted with it. Input must be consecutive
+// microphone audio; the output lags it by one block of the canceller
+// (256 samples by default), and played audio is only tracked from the first
+// call on.
 func (es *EchoSuppressor) RemoveEchoRealtime(input []byte) []byte {
+	out, _ := es.cancelEcho(input)
+	return out
+}
+
+// cancelEcho also reports whether input was mostly echo: the canceller
+// removed at least three quarters of its energy while the bot was playing.
+func (es *EchoSuppressor) cancelEcho(input []byte) ([]byte, bool) {
 	if !es.enabled || len(input) == 0 {
-		out := make([]byte, len(input))
-		copy(out, input)
-		return out
+		return bytes.Clone(input), false
 	}
 
 	es.mu.Lock()
 	defer es.mu.Unlock()
 
-	if time.Since(es.lastTTSTime) > time.Duration(es.echoSilenceMS)*time.Millisecond {
-		out := make([]byte, len(input))
-		copy(out, input)
-		return out
-	}
-
-	searchSize := es.count
-	threshold := es.echoThreshold
-
-	if searchSize == 0 {
-		out := make([]byte, len(input))
-		copy(out, input)
-		return out
-	}
+	if es.aec == nil {
+		es.startAECLocked()
+	}
+	in := bytesToSamples(input)
+	cleaned := es.aec.Process(in)
+	out := bytes.Clone(input) // keeps an odd trailing byte as it was
+	var inEnergy, outEnergy float64
+	for i, e := range cleaned {
+		inEnergy += in[i] * in[i]
+		outEnergy += e * e
+		v := int16(math.Max(-32768, math.Min(32767, e*32768)))
+		out[2*i] = byte(v)
+		out[2*i+1] = byte(uint16(v) >> 8)
+	}
+	playing := time.Since(es.lastTTSTime) <= time.Duration(es.echoSilenceMS)*time.Millisecond
+	return out, playing && inEnergy > 1e-6 && outEnergy < inEnergy/4
+}
 
-	inSamples := bytesToSamples(input)
-	if es.inputSampleRate != es.playbackSampleRate {
-		inSamples = resample(inSamples, es.inputSampleRate, es.playbackSampleRate)
-	}
-	maxCorr := es.maxCorrelationRing(inSamples, searchSize)
+func (es *EchoSuppressor) startAECLocked() {
+	es.aec = aec.New(es.inputSampleRate, es.aecConfig)
+}
 
-	if maxCorr < threshold {
-		envCorr := es.maxEnvelopeCorrelationRing(inSamples, searchSize, 8)
-		if envCorr < threshold+0.05 {
-			out := make([]byte, len(input))
-			copy(out, input)
-			return out
-		}
+// SetAECConfig tunes the canceller behind RemoveEchoRealtime. It restarts
+// it, so the echo path is learned anew.
+func (es *EchoSuppressor) SetAECConfig(cfg aec.Config) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.aecConfig = cfg
+	if es.aec != nil {
+		es.startAECLocked()
 	}
-
-	return make([]byte, len(input))
 }
 
 func (es *EchoSuppressor) SetThreshold(threshold float64) {
@@ -480,6 +546,12 @@ func (es *EchoSuppressor) SetThreshold(threshold float64) {
 	}
 }
 
+func (es *EchoSuppressor) Threshold() float64 {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return es.echoThreshold
+}
+
 func (es *EchoSuppressor) SetPlaybackSampleRate(rate int) {
 	es.mu.Lock()
 	defer es.mu.Unlock()
@@ -490,7 +562,14 @@ func (es *EchoSuppressor) SetPlaybackSampleRate(rate int) {
 		return
 	}
 	es.playbackSampleRate = rate
-	newMax := rate * 2
+	es.resizeLocked()
+	if es.aec != nil {
+		es.aec.Flush()
+	}
+}
+
+func (es *EchoSuppressor) resizeLocked() {
+	newMax := es.playbackSampleRate * es.bufferMS / 1000
 	if newMax != es.maxSamples {
 		es.playedSamples = make([]float64, newMax)
 		es.maxSamples = newMax
@@ -499,13 +578,62 @@ func (es *EchoSuppressor) SetPlaybackSampleRate(rate int) {
 	}
 }
 
+// SetSilenceWindow sets how long after the last played audio input is still
+// checked for echo.
+func (es *EchoSuppressor) SetSilenceWindow(d time.Duration) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if d > 0 {
+		es.echoSilenceMS = int(d.Milliseconds())
+	}
+}
+
+// SetReferenceBuffer sets how much played audio is kept to correlate against.
+// Resizing drops what was recorded so far.
+func (es *EchoSuppressor) SetReferenceBuffer(d time.Duration) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if d <= 0 {
+		return
+	}
+	es.bufferMS = int(d.Milliseconds())
+	es.resizeLocked()
+}
+
+// SetSearchWindow caps how far back the per-chunk check searches. It should
+// cover the device's playback latency and is limited by the reference buffer.
+func (es *EchoSuppressor) SetSearchWindow(d time.Duration) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if d > 0 {
+		es.searchWindowMS = int(d.Milliseconds())
+	}
+}
+
+// SetEnvelopeCorrelation tunes the fallback check that compares loudness
+// envelopes, which catches echo distorted by speakers and codecs. Input
+// counts as echo when the envelope correlation exceeds the threshold plus
+// margin; decimation is the number of samples per envelope point. A
+// decimation of zero keeps the current value.
+func (es *EchoSuppressor) SetEnvelopeCorrelation(margin float64, decimation int) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.envelopeMargin = margin
+	if decimation > 0 {
+		es.envelopeDecimation = decimation
+	}
+}
+
 func (es *EchoSuppressor) SetInputSampleRate(rate int) {
 	es.mu.Lock()
 	defer es.mu.Unlock()
-	if rate <= 0 {
+	if rate <= 0 || rate == es.inputSampleRate {
 		return
 	}
 	es.inputSampleRate = rate
+	if es.aec != nil {
+		es.startAECLocked()
+	}
 }
 
 func (es *EchoSuppressor) SetSampleRates(playbackRate, inputRate int) {
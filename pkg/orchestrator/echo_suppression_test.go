@@ -122,6 +122,12 @@ func TestEchoSuppressor_PostProcess(t *testing.T) {
 	t.Run("mismatch-44k-to-16k", func(t *testing.T) {
 		runPostProcessScenario(t, 44100, 16000)
 	})
+	t.Run("same-rate-48k", func(t *testing.T) {
+		runPostProcessScenario(t, 48000, 48000)
+	})
+	t.Run("mismatch-48k-to-16k", func(t *testing.T) {
+		runPostProcessScenario(t, 48000, 16000)
+	})
 }
 
 func TestEchoSuppressor_IsEchoCorrelation(t *testing.T) {
@@ -132,6 +138,8 @@ func TestEchoSuppressor_IsEchoCorrelation(t *testing.T) {
 	}{
 		{"same-rate", 44100, 44100},
 		{"mismatch-44k-to-16k", 44100, 16000},
+		{"same-rate-48k", 48000, 48000},
+		{"mismatch-48k-to-44k", 48000, 44100},
 	}
 
 	for _, sc := range scenarios {
@@ -193,3 +201,104 @@ func TestEchoSuppressor_SetSampleRates(t *testing.T) {
 		t.Fatalf("SetSampleRates did not update fields: got %d/%d", es.playbackSampleRate, es.inputSampleRate)
 	}
 }
+
+func TestEchoSuppressor_Configure(t *testing.T) {
+	es := NewEchoSuppressorWithConfig(Config{
+		SampleRate: 44100,
+		Echo: EchoConfig{
+			Threshold:       0.9,
+			SilenceWindow:   30 * time.Millisecond,
+			ReferenceBuffer: 4 * time.Second,
+			SearchWindow:    3 * time.Second,
+		},
+	})
+	if es.echoThreshold != 0.9 || es.maxSamples != 4*44100 || es.searchWindowMS != 3000 {
+		t.Fatalf("config not applied: threshold=%v maxSamples=%d search=%d", es.echoThreshold, es.maxSamples, es.searchWindowMS)
+	}
+	if es.envelopeMargin != 0.05 || es.envelopeDecimation != 8 {
+		t.Error("unset fields must keep their defaults")
+	}
+	es.Configure(EchoConfig{EnvelopeDecimation: 4})
+	if es.envelopeMargin != 0.05 || es.envelopeDecimation != 4 {
+		t.Errorf("setting the decimation must keep the margin, got margin=%v decimation=%d", es.envelopeMargin, es.envelopeDecimation)
+	}
+
+	played := generateSine(440, 200, 44100, 0.5)
+	es.RecordPlayedAudio(played)
+	if !es.IsEchoFast(played[:1764]) {
+		t.Fatal("expected played audio to be detected as echo")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if es.IsEchoFast(played[:1764]) {
+		t.Error("expected no echo check after the silence window")
+	}
+}
+
+func TestEchoSuppressor_RemoveEchoRealtimeSubtracts(t *testing.T) {
+	es := NewEchoSuppressorWithRates(16000, 16000)
+	user := generateSine(300, 100, 16000, 0.3)
+	if out := es.RemoveEchoRealtime(user); len(out) != len(user) {
+		t.Fatalf("output length %d, want %d", len(out), len(user))
+	}
+
+	// A tone echoed at half level while the caller talks over it: muting
+	// would silence the caller too.
+	played := generateSine(440, 1000, 16000, 0.6)
+	echo := generateSine(440, 1000, 16000, 0.3)
+	var in, out float64
+	for i := 0; i < len(played); i += 640 {
+		es.RecordPlayedAudio(played[i : i+640])
+		chunk := echo[i : i+640]
+		if i >= len(played)/2 {
+			chunk = mixPCM(chunk, generateSine(300, 20, 16000, 0.3))
+		}
+		cleaned := es.RemoveEchoRealtime(chunk)
+		if i >= len(played)/2 {
+			in += pcmEnergy(chunk)
+			out += pcmEnergy(cleaned)
+		}
+	}
+	if ratio := out / in; ratio < 0.3 || ratio > 0.7 {
+		t.Errorf("expected the echo removed and the caller kept, got %.2f of the energy", ratio)
+	}
+}
+
+func mixPCM(a, b []byte) []byte {
+	x, y := bytesToSamples(a), bytesToSamples(b)
+	for i := range x {
+		x[i] += y[i]
+	}
+	return samplesToBytes(x)
+}
+
+func FuzzBytesToSamples(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00, 0x80, 0xff})
+	f.Add(toneChunk(8000))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		samples := bytesToSamples(data)
+		if len(samples) != len(data)/2 {
+			t.Fatalf("%d bytes gave %d samples", len(data), len(samples))
+		}
+		for _, s := range samples {
+			if s < -1 || s >= 1 {
+				t.Fatalf("sample %v out of range", s)
+			}
+		}
+	})
+}
+
+func FuzzEchoSuppressor_IsEcho(f *testing.F) {
+	f.Add(toneChunk(8000), toneChunk(8000))
+	f.Add([]byte{0x01}, []byte{0x02, 0x03, 0x04})
+	f.Add([]byte{}, make([]byte, 4801))
+
+	f.Fuzz(func(t *testing.T, played, input []byte) {
+		es := NewEchoSuppressor()
+		es.SetSampleRates(24000, 16000)
+		es.RecordPlayedAudio(played)
+		es.IsEcho(input)
+		es.IsEchoFast(input)
+	})
+}
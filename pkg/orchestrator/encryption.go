@@ -0,0 +1,222 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotEncrypted = errors.New("data is not an encrypted envelope")
+	ErrUnknownKey   = errors.New("encryption key not found")
+)
+
+type Encryptor interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// KeyProvider hands out data keys. Implementations may be backed by a KMS;
+// CurrentKey is used for new writes and KeyByID lets old data be read after
+// rotation.
+type KeyProvider interface {
+	CurrentKey(ctx context.Context) (keyID string, key []byte, err error)
+	KeyByID(ctx context.Context, keyID string) ([]byte, error)
+}
+
+type StaticKeyProvider struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+func NewStaticKeyProvider(keyID string, key []byte) (*StaticKeyProvider, error) {
+	p := &StaticKeyProvider{keys: make(map[string][]byte)}
+	if err := p.Rotate(keyID, key); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Rotate makes key the current key while keeping older keys for decryption.
+func (p *StaticKeyProvider) Rotate(keyID string, key []byte) error {
+	if err := validateAESKey(key); err != nil {
+		return err
+	}
+	if keyID == "" || len(keyID) > 255 {
+		return fmt.Errorf("invalid key id %q", keyID)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[keyID] = append([]byte(nil), key...)
+	p.current = keyID
+	return nil
+}
+
+func (p *StaticKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current, p.keys[p.current], nil
+}
+
+func (p *StaticKeyProvider) KeyByID(ctx context.Context, keyID string) ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return key, nil
+}
+
+func validateAESKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+	return fmt.Errorf("invalid AES key length %d (must be 16, 24 or 32 bytes)", len(key))
+}
+
+// ParseEncryptionKey accepts a hex or base64 encoded AES key, as typically
+// supplied through configuration or environment variables.
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if key, err := hex.DecodeString(encoded); err == nil && validateAESKey(key) == nil {
+		return key, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be hex or base64: %w", err)
+	}
+	if err := validateAESKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+var envelopeMagic = []byte("LKE1")
+
+// AESGCMEncryptor writes envelopes of the form
+// magic | len(keyID) | keyID | nonce | ciphertext+tag.
+type AESGCMEncryptor struct {
+	keys KeyProvider
+}
+
+func NewAESGCMEncryptor(keys KeyProvider) *AESGCMEncryptor {
+	return &AESGCMEncryptor{keys: keys}
+}
+
+func NewAESGCMEncryptorFromKey(key []byte) (*AESGCMEncryptor, error) {
+	keys, err := NewStaticKeyProvider("default", key)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESGCMEncryptor(keys), nil
+}
+
+func (e *AESGCMEncryptor) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	keyID, key, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+1+len(keyID)+len(nonce))
+	header = append(header, envelopeMagic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = append(header, nonce...)
+
+	// The header is authenticated so the key id cannot be swapped.
+	return gcm.Seal(header, nonce, plaintext, header[:len(envelopeMagic)+1+len(keyID)]), nil
+}
+
+func (e *AESGCMEncryptor) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, ErrNotEncrypted
+	}
+	idLen := int(data[len(envelopeMagic)])
+	aadLen := len(envelopeMagic) + 1 + idLen
+	if len(data) < aadLen {
+		return nil, ErrNotEncrypted
+	}
+	keyID := string(data[len(envelopeMagic)+1 : aadLen])
+
+	key, err := e.keys.KeyByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aadLen+gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrNotEncrypted
+	}
+	nonce := data[aadLen : aadLen+gcm.NonceSize()]
+	return gcm.Open(nil, nonce, data[aadLen+gcm.NonceSize():], data[:aadLen])
+}
+
+func IsEncrypted(data []byte) bool {
+	return len(data) > len(envelopeMagic) && bytes.Equal(data[:len(envelopeMagic)], envelopeMagic)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealIfConfigured and OpenIfConfigured let storage backends treat a nil
+// Encryptor as plaintext passthrough.
+func SealIfConfigured(ctx context.Context, enc Encryptor, data []byte) ([]byte, error) {
+	if enc == nil {
+		return data, nil
+	}
+	return enc.Encrypt(ctx, data)
+}
+
+// Data that is not an envelope is returned as is, so records written before
+// encryption was enabled stay readable, unless enc came from RejectPlaintext.
+func OpenIfConfigured(ctx context.Context, enc Encryptor, data []byte) ([]byte, error) {
+	if enc == nil {
+		return data, nil
+	}
+	if !IsEncrypted(data) {
+		if _, strict := enc.(strictEncryptor); strict {
+			return nil, ErrNotEncrypted
+		}
+		return data, nil
+	}
+	return enc.Decrypt(ctx, data)
+}
+
+// RejectPlaintext wraps enc so OpenIfConfigured fails with ErrNotEncrypted
+// on data that is not an envelope. Use it once every stored record has been
+// rewritten encrypted, so plaintext planted in a store is not trusted.
+func RejectPlaintext(enc Encryptor) Encryptor {
+	return strictEncryptor{enc}
+}
+
+type strictEncryptor struct {
+	Encryptor
+}
@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestAESGCMEncryptorRoundTrip(t *testing.T) {
+	enc, err := NewAESGCMEncryptorFromKey(testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	plaintext := []byte("caller said their card number")
+
+	sealed, err := enc.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, plaintext) {
+		t.Fatal("expected opaque encrypted envelope")
+	}
+
+	opened, err := enc.Decrypt(ctx, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("round trip mismatch: %q", opened)
+	}
+
+	sealed[len(sealed)-1] ^= 0xFF
+	if _, err := enc.Decrypt(ctx, sealed); err == nil {
+		t.Error("expected tampered ciphertext to fail authentication")
+	}
+}
+
+func TestAESGCMEncryptorKeyRotation(t *testing.T) {
+	keys, err := NewStaticKeyProvider("k1", testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := NewAESGCMEncryptor(keys)
+	ctx := context.Background()
+
+	old, _ := enc.Encrypt(ctx, []byte("old"))
+	if err := keys.Rotate("k2", testKey(2)); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _ := enc.Encrypt(ctx, []byte("new"))
+
+	for _, data := range [][]byte{old, fresh} {
+		if _, err := enc.Decrypt(ctx, data); err != nil {
+			t.Errorf("expected decryption after rotation, got %v", err)
+		}
+	}
+
+	other := NewAESGCMEncryptor(&StaticKeyProvider{keys: map[string][]byte{}})
+	if _, err := other.Decrypt(ctx, fresh); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key, err := ParseEncryptionKey(hex.EncodeToString(testKey(7)))
+	if err != nil || len(key) != 32 {
+		t.Fatalf("expected 32-byte key from hex, got %d %v", len(key), err)
+	}
+	if _, err := ParseEncryptionKey("c2hvcnQ="); err == nil {
+		t.Error("expected short key to be rejected")
+	}
+}
+
+func TestSealIfConfigured(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("plain")
+
+	out, _ := SealIfConfigured(ctx, nil, data)
+	if !bytes.Equal(out, data) {
+		t.Error("nil encryptor must pass data through")
+	}
+
+	enc, _ := NewAESGCMEncryptorFromKey(testKey(3))
+	opened, err := OpenIfConfigured(ctx, enc, data)
+	if err != nil || !bytes.Equal(opened, data) {
+		t.Error("plaintext written before encryption was enabled must stay readable")
+	}
+}
+
+func TestRejectPlaintext(t *testing.T) {
+	ctx := context.Background()
+	inner, _ := NewAESGCMEncryptorFromKey(testKey(4))
+	enc := RejectPlaintext(inner)
+
+	if _, err := OpenIfConfigured(ctx, enc, []byte("plain")); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted for plaintext, got %v", err)
+	}
+	sealed, err := SealIfConfigured(ctx, enc, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := OpenIfConfigured(ctx, enc, sealed)
+	if err != nil || string(opened) != "secret" {
+		t.Errorf("expected envelopes to open, got %q, %v", opened, err)
+	}
+}
@@ -0,0 +1,182 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+const defaultEndCallMarker = "[END_CALL]"
+
+// maxFarewellWait bounds how long a stream waits for the farewell to play
+// before closing.
+const maxFarewellWait = 30 * time.Second
+
+// Reasons in CallEndInfo.
+const (
+	CallEndKeyword   = "keyword"
+	CallEndLLM       = "llm"
+	CallEndRequested = "requested"
+)
+
+// DefaultGoodbyes end the call when a transcript ends with one of them and
+// EndConversationConfig.Keywords is nil.
+var DefaultGoodbyes = []string{
+	"goodbye", "bye", "bye bye", "bye now", "good night", "have a nice day",
+	"have a good day", "talk to you later", "that's all", "that's all thanks",
+	"that's all thank you", "that's everything", "hang up", "you can hang up",
+}
+
+// EndConversationConfig lets a managed stream end the call once the
+// conversation is over: when the caller's transcript ends with one of
+// Keywords (DefaultGoodbyes when nil), or, with LLMSignal, when the LLM ends
+// its reply with Marker ("[END_CALL]" by default) as it is instructed to.
+// The agent's reply is spoken first; then CallEnded is emitted and the
+// stream closes. ManagedStream.EndConversation ends a call on request, e.g.
+// from an application's own end-call tool.
+type EndConversationConfig struct {
+	Enabled   bool
+	Keywords  []string
+	LLMSignal bool
+	Marker    string
+}
+
+// CallEndInfo is the data of a CallEnded event.
+type CallEndInfo struct {
+	Reason string `json:"reason"`
+}
+
+func (c EndConversationConfig) marker() string {
+	if c.Marker == "" {
+		return defaultEndCallMarker
+	}
+	return c.Marker
+}
+
+// instruction tells the LLM how to signal the end of the conversation.
+func (c EndConversationConfig) instruction() string {
+	if !c.Enabled || !c.LLMSignal {
+		return ""
+	}
+	return "When the conversation is over and you have said goodbye, end your reply with " + c.marker() + ". Never use it otherwise."
+}
+
+// takeMarker strips the end-of-call marker from response and reports whether
+// it was there.
+func (c EndConversationConfig) takeMarker(response string) (string, bool) {
+	if !c.Enabled || !c.LLMSignal || !strings.Contains(response, c.marker()) {
+		return response, false
+	}
+	return strings.TrimSpace(strings.ReplaceAll(response, c.marker(), "")), true
+}
+
+// isGoodbye reports whether transcript ends with one of the keywords.
+func (c EndConversationConfig) isGoodbye(transcript string) bool {
+	if !c.Enabled {
+		return false
+	}
+	keywords := c.Keywords
+	if keywords == nil {
+		keywords = DefaultGoodbyes
+	}
+	said := " " + strings.Join(backchannelWords(transcript), " ")
+	for _, k := range keywords {
+		if k := strings.Join(backchannelWords(k), " "); k != "" && strings.HasSuffix(said, " "+k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ended reports whether the LLM's last reply signalled the end of the
+// conversation (EndConversationConfig.LLMSignal).
+func (s *ConversationSession) Ended() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ended
+}
+
+func (s *ConversationSession) setEnded(ended bool) {
+	s.mu.Lock()
+	s.ended = ended
+	s.mu.Unlock()
+}
+
+// endReason returns why the turn that answered transcript ends the call, or "".
+func (ms *ManagedStream) endReason(transcript string) string {
+	cfg := ms.orch.GetConfig().EndConversation
+	switch {
+	case cfg.isGoodbye(transcript):
+		return CallEndKeyword
+	case cfg.Enabled && ms.session.Ended():
+		return CallEndLLM
+	}
+	return ""
+}
+
+// EndConversation ends the call once the bot is done talking: CallEnded is
+// emitted and the stream closes after the reply in progress, if any, has
+// played.
+func (ms *ManagedStream) EndConversation() {
+	ms.spawn(func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			ms.mu.Lock()
+			busy := ms.isSpeaking || ms.isThinking
+			ms.mu.Unlock()
+			if !busy {
+				break
+			}
+			select {
+			case <-ticker.C:
+			case <-ms.ctx.Done():
+				return
+			}
+		}
+		ms.endCall(CallEndRequested)
+	})
+}
+
+// endCall waits for the farewell to finish playing, then emits CallEnded and
+// closes the stream. It gives up if the caller interrupts in the meantime.
+func (ms *ManagedStream) endCall(reason string) {
+	ms.mu.Lock()
+	interruptions := ms.stats.Interruptions
+	ms.mu.Unlock()
+
+	if remaining := ms.unplayed(); remaining > 0 {
+		t := time.NewTimer(min(remaining, maxFarewellWait))
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ms.ctx.Done():
+			return
+		}
+	}
+
+	ms.mu.Lock()
+	interrupted := ms.stats.Interruptions != interruptions
+	ms.mu.Unlock()
+	if interrupted {
+		return
+	}
+	ms.emit(CallEnded, CallEndInfo{Reason: reason})
+	ms.Close()
+}
+
+// unplayed estimates how much of the last reply the caller has yet to hear,
+// from playback acks or assuming real-time playback.
+func (ms *ManagedStream) unplayed() time.Duration {
+	playbackRate, _ := ms.sampleRates()
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	s := ms.speech
+	if s.emitted == 0 || s.first.IsZero() {
+		return 0
+	}
+	total := time.Duration(s.emitted/2) * time.Second / time.Duration(playbackRate)
+	if p := &ms.playback; p.enabled && p.turnID == s.turnID {
+		return total - time.Duration(p.played/2)*time.Second/time.Duration(playbackRate)
+	}
+	return total - time.Since(s.first)
+}
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndConversation_IsGoodbye(t *testing.T) {
+	cfg := EndConversationConfig{Enabled: true}
+	cases := map[string]bool{
+		"Okay, thanks. Bye!":              true,
+		"That's all, thank you.":          true,
+		"Goodbye.":                        true,
+		"Say goodbye to my old plan":      false,
+		"Bye the way, what's my balance?": false,
+		"":                                false,
+	}
+	for transcript, want := range cases {
+		if got := cfg.isGoodbye(transcript); got != want {
+			t.Errorf("isGoodbye(%q) = %v, want %v", transcript, got, want)
+		}
+	}
+	if (EndConversationConfig{}).isGoodbye("Bye!") {
+		t.Error("expected no goodbyes when disabled")
+	}
+	custom := EndConversationConfig{Enabled: true, Keywords: []string{"over and out"}}
+	if custom.isGoodbye("Bye!") || !custom.isGoodbye("Over and out.") {
+		t.Error("expected custom keywords to replace the defaults")
+	}
+}
+
+func TestEndConversation_TakeMarker(t *testing.T) {
+	cfg := EndConversationConfig{Enabled: true, LLMSignal: true}
+	if got, ok := cfg.takeMarker("Have a great day! [END_CALL]"); !ok || got != "Have a great day!" {
+		t.Errorf("takeMarker = %q, %v", got, ok)
+	}
+	if got, ok := cfg.takeMarker("Anything else?"); ok || got != "Anything else?" {
+		t.Errorf("takeMarker = %q, %v", got, ok)
+	}
+	if _, ok := (EndConversationConfig{Enabled: true}).takeMarker("Bye [END_CALL]"); ok {
+		t.Error("expected the marker to be ignored without LLMSignal")
+	}
+	if (EndConversationConfig{Enabled: true}).instruction() != "" || cfg.instruction() == "" {
+		t.Error("expected an instruction only with LLMSignal")
+	}
+}
+
+func newEndingStream(t *testing.T, reply string) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.EndConversation = EndConversationConfig{Enabled: true, LLMSignal: true}
+	return newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{completeResult: reply})
+}
+
+func waitForClose(t *testing.T, ms *ManagedStream) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for ms.State() != StreamClosed {
+		select {
+		case <-deadline:
+			t.Fatal("expected the stream to close")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestEndConversation_LLMSignal(t *testing.T) {
+	stream := newEndingStream(t, "Glad I could help. Goodbye! [END_CALL]")
+	stream.InjectUserText("No, that's it for today.")
+
+	if ev := waitForEvent(t, stream, BotResponse); ev.Data != "Glad I could help. Goodbye!" {
+		t.Errorf("expected the marker to be stripped, got %q", ev.Data)
+	}
+	ev := waitForEvent(t, stream, CallEnded)
+	if info, ok := ev.Data.(CallEndInfo); !ok || info.Reason != CallEndLLM {
+		t.Errorf("unexpected call end %#v", ev.Data)
+	}
+	waitForClose(t, stream)
+	for _, m := range stream.session.GetContextCopy() {
+		if m.Role == "assistant" && m.Content != "Glad I could help. Goodbye!" {
+			t.Errorf("expected the marker to be kept out of the context, got %q", m.Content)
+		}
+	}
+}
+
+func TestEndConversation_Keyword(t *testing.T) {
+	stream := newEndingStream(t, "You're welcome, bye!")
+	stream.InjectUserText("Thanks, bye.")
+
+	ev := waitForEvent(t, stream, CallEnded)
+	if info, ok := ev.Data.(CallEndInfo); !ok || info.Reason != CallEndKeyword {
+		t.Errorf("unexpected call end %#v", ev.Data)
+	}
+	waitForClose(t, stream)
+}
+
+func TestEndConversation_ContinuesOtherwise(t *testing.T) {
+	stream := newEndingStream(t, "Sure, what's the order number?")
+	stream.InjectUserText("I'd like to return an order.")
+
+	waitForEvent(t, stream, BotResponse)
+	time.Sleep(100 * time.Millisecond)
+	if stream.State() == StreamClosed {
+		t.Fatal("expected the call to stay open")
+	}
+}
+
+func TestEndConversation_Requested(t *testing.T) {
+	stream := newEndingStream(t, "")
+	stream.EndConversation()
+
+	ev := waitForEvent(t, stream, CallEnded)
+	if info, ok := ev.Data.(CallEndInfo); !ok || info.Reason != CallEndRequested {
+		t.Errorf("unexpected call end %#v", ev.Data)
+	}
+	waitForClose(t, stream)
+}
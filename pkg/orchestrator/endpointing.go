@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const defaultEndpointHold = 1200 * time.Millisecond
+
+// EndpointingConfig holds a turn open when the caller pauses mid-thought.
+// When the VAD reports the end of speech, Detector judges the partial
+// transcript: if the caller seems to be going on ("I'd like to, um"), the
+// streaming STT session is kept open for up to MaxHold (1.2s by default) and
+// speech resuming in that time continues the same utterance. It needs a
+// StreamingSTTProvider for partial transcripts.
+type EndpointingConfig struct {
+	Enabled  bool
+	Detector EndpointDetector // HeuristicEndpointDetector by default
+	MaxHold  time.Duration
+}
+
+// EndpointDetector decides whether a transcript is a finished thought.
+type EndpointDetector interface {
+	IsComplete(ctx context.Context, transcript string) (bool, error)
+}
+
+// HeuristicEndpointDetector treats transcripts ending in a filler, a
+// conjunction or an unfinished clause as incomplete.
+type HeuristicEndpointDetector struct{}
+
+var trailingIncomplete = map[string]bool{
+	"um": true, "uh": true, "erm": true, "hmm": true, "like": true,
+	"and": true, "but": true, "or": true, "so": true, "because": true,
+	"then": true, "if": true, "that": true, "which": true, "with": true,
+	"the": true, "a": true, "an": true, "to": true, "of": true, "for": true,
+	"my": true, "your": true, "is": true, "was": true,
+}
+
+func (HeuristicEndpointDetector) IsComplete(ctx context.Context, transcript string) (bool, error) {
+	t := strings.TrimSpace(transcript)
+	if t == "" {
+		return true, nil
+	}
+	if strings.HasSuffix(t, ",") || strings.HasSuffix(t, "-") || strings.HasSuffix(t, "...") || strings.HasSuffix(t, "…") {
+		return false, nil
+	}
+	words := strings.Fields(strings.ToLower(t))
+	last := strings.TrimFunc(words[len(words)-1], func(r rune) bool { return !unicode.IsLetter(r) })
+	return !trailingIncomplete[last], nil
+}
+
+// LLMEndpointDetector asks a (fast) LLM whether the caller has finished.
+type LLMEndpointDetector struct {
+	LLM LLMProvider
+}
+
+func NewLLMEndpointDetector(llm LLMProvider) *LLMEndpointDetector {
+	return &LLMEndpointDetector{LLM: llm}
+}
+
+const endpointPrompt = "You decide whether a caller in a voice conversation has finished speaking. " +
+	"Given what they said so far, answer YES if it is a complete thought, or NO if they are likely to go on. " +
+	"Answer with YES or NO only."
+
+func (d *LLMEndpointDetector) IsComplete(ctx context.Context, transcript string) (bool, error) {
+	answer, err := d.LLM.Complete(ctx, []Message{
+		{Role: "system", Content: endpointPrompt},
+		{Role: "user", Content: transcript},
+	})
+	if err != nil {
+		return true, err
+	}
+	return !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "NO"), nil
+}
+
+// holdEndpoint finalizes the streaming STT session once the caller is done:
+// right away if the transcript so far is complete, otherwise after MaxHold
+// unless speech resumes first.
+func (ms *ManagedStream) holdEndpoint(transcript string) {
+	cfg := ms.endpointing()
+	maxHold := cfg.MaxHold
+	detector := cfg.Detector
+	if detector == nil {
+		detector = HeuristicEndpointDetector{}
+	}
+	deadline := time.Now().Add(maxHold)
+
+	ctx, cancel := context.WithCancel(ms.ctx)
+	ms.mu.Lock()
+	if ms.holdCancel != nil {
+		ms.holdCancel()
+	}
+	ms.holdCancel = cancel
+	sttChan := ms.sttChan
+	ms.mu.Unlock()
+
+	ms.spawn(func() {
+		defer cancel()
+		dctx, dcancel := context.WithDeadline(ctx, deadline)
+		complete, err := detector.IsComplete(dctx, transcript)
+		dcancel()
+		if err == nil && !complete {
+			t := time.NewTimer(time.Until(deadline))
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				// The caller went on, or the stream closed.
+				return
+			}
+		}
+
+		ms.mu.Lock()
+		defer ms.mu.Unlock()
+		if ctx.Err() != nil || ms.sttChan != sttChan {
+			return
+		}
+		ms.holdCancel = nil
+		ms.sttChan = nil
+		// Under ms.mu: doWrite sends to the channel with it held.
+		close(sttChan)
+	})
+}
+
+// continueHeldUtterance reports whether speech resumed during an endpoint
+// hold, in which case it belongs to the held utterance.
+func (ms *ManagedStream) continueHeldUtterance() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.holdCancel == nil {
+		return false
+	}
+	ms.holdCancel()
+	ms.holdCancel = nil
+	return true
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeuristicEndpointDetector(t *testing.T) {
+	cases := map[string]bool{
+		"I'd like to book a table.":   true,
+		"yes":                         true,
+		"":                            true,
+		"I'd like to":                 false,
+		"my order number is, um":      false,
+		"for two people and":          false,
+		"it's for tomorrow, I think,": false,
+		"wait...":                     false,
+	}
+	for transcript, want := range cases {
+		got, err := HeuristicEndpointDetector{}.IsComplete(context.Background(), transcript)
+		if err != nil || got != want {
+			t.Errorf("IsComplete(%q) = %v, %v; want %v", transcript, got, err, want)
+		}
+	}
+}
+
+func TestLLMEndpointDetector(t *testing.T) {
+	for answer, want := range map[string]bool{"NO": false, "Yes.": true} {
+		got, err := NewLLMEndpointDetector(&MockLLMProvider{completeResult: answer}).IsComplete(context.Background(), "so")
+		if err != nil || got != want {
+			t.Errorf("answer %q: got %v, %v", answer, got, err)
+		}
+	}
+}
+
+// partialSTT reports text as a partial on every chunk and as the final
+// transcript when the stream is closed.
+type partialSTT struct {
+	mu     sync.Mutex
+	text   string
+	opened int
+}
+
+func (p *partialSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (string, error) {
+	return "", nil
+}
+func (p *partialSTT) Name() string { return "partial" }
+func (p *partialSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	p.mu.Lock()
+	p.opened++
+	p.mu.Unlock()
+	ch := make(chan []byte, 64)
+	go func() {
+		for range ch {
+			onTranscript(p.current(), false)
+		}
+		onTranscript(p.current(), true)
+	}()
+	return ch, nil
+}
+
+func (p *partialSTT) current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.text
+}
+
+func (p *partialSTT) set(text string) {
+	p.mu.Lock()
+	p.text = text
+	p.mu.Unlock()
+}
+
+func TestEndpointing_HoldsMidThought(t *testing.T) {
+	stt := &partialSTT{text: "I'd like to"}
+	cfg := DefaultConfig()
+	cfg.Endpointing = EndpointingConfig{Enabled: true, MaxHold: 2 * time.Second}
+	ms := newTestStream(t, cfg, stt, &MockLLMProvider{completeResult: "Sure."})
+
+	speak := func() {
+		for i := 0; i < 5; i++ {
+			ms.doWrite(toneChunk(1000))
+		}
+		waitForEvent(t, ms, UserSpeaking)
+		ms.doWrite(make([]byte, 882))
+		time.Sleep(60 * time.Millisecond) // past the VAD silence limit
+		ms.doWrite(make([]byte, 882))
+		waitForEvent(t, ms, UserStopped)
+	}
+
+	speak()
+	// Mid-thought: the turn is held open, so speech continues it.
+	stt.set("I'd like to book a table.")
+	speak()
+	if ev := waitForEvent(t, ms, TranscriptFinal); ev.Data != "I'd like to book a table." {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	stt.mu.Lock()
+	defer stt.mu.Unlock()
+	if stt.opened != 1 {
+		t.Errorf("expected one STT session for the held utterance, got %d", stt.opened)
+	}
+}
+
+func TestEndpointing_MaxHoldEndsTurn(t *testing.T) {
+	stt := &partialSTT{text: "so I was thinking and"}
+	cfg := DefaultConfig()
+	cfg.Endpointing = EndpointingConfig{Enabled: true, MaxHold: 100 * time.Millisecond}
+	ms := newTestStream(t, cfg, stt, &MockLLMProvider{completeResult: "Go on."})
+
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	ms.doWrite(make([]byte, 882))
+	time.Sleep(60 * time.Millisecond)
+	ms.doWrite(make([]byte, 882))
+	waitForEvent(t, ms, UserStopped)
+	start := time.Now()
+	if ev := waitForEvent(t, ms, TranscriptFinal); ev.Data != "so I was thinking and" {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	if time.Since(start) < 80*time.Millisecond {
+		t.Error("an incomplete utterance must be held until MaxHold")
+	}
+}
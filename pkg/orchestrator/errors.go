@@ -1,6 +1,9 @@
 package orchestrator
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 
 var (
@@ -21,4 +24,60 @@ var (
 
 	
 	ErrContextCancelled = errors.New("operation cancelled by context")
+
+	
+	ErrGuardrailBlocked = errors.New("response blocked by guardrail")
+
+	
+	ErrNotSupervised = errors.New("session is not taken over by a supervisor")
+
+	
+	ErrEmptyWhisper = errors.New("supervisor guidance is empty")
+
+	
+	ErrServerBusy = errors.New("orchestrator is at session capacity")
+
+	
+	ErrSessionExists = errors.New("session already exists")
+
+	
+	ErrTooManySessions = errors.New("session manager is at its session limit")
+
+	
+	ErrSessionLive = errors.New("session already has a live stream")
+
+	
+	ErrUnsupportedExport = errors.New("unsupported session export version")
+
+	
+	ErrStreamClosed = errors.New("stream is closed")
+
+	
+	ErrSupervised = errors.New("session is taken over by a supervisor")
+
+	
+	ErrSpeechInterrupted = errors.New("speech was interrupted")
+
+	
+	ErrNotPushToTalk = errors.New("stream is not in push-to-talk mode")
+
+	
+	ErrNotEnrolled = errors.New("speaker has no enrolled voiceprint")
+
+	
+	ErrNoSpeakerAudio = errors.New("no caller audio to verify")
 )
+
+// ProviderError is returned by providers when their backend answers with an
+// HTTP error, so callers can act on the status without parsing messages.
+type ProviderError struct {
+	// Provider names the failing call, e.g. "openai llm".
+	Provider   string
+	StatusCode int
+	// Body is the decoded error response, if any.
+	Body interface{}
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s error (status %d): %v", e.Provider, e.StatusCode, e.Body)
+}
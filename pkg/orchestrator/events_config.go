@@ -0,0 +1,117 @@
+package orchestrator
+
+const defaultEventCapacity = 1024
+
+// EventsConfig sizes a stream's Events() channel and decides what is lost
+// when the consumer falls behind. Sending never blocks the stream.
+type EventsConfig struct {
+	Capacity int // 1024 by default
+	EventOverflow
+}
+
+// EventOverflow decides which event is dropped when Events() is full.
+type EventOverflow struct {
+	// Policy is DropNewest (the default) or DropOldest. Events() can't be
+	// closed, so Disconnect acts like DropNewest.
+	Policy Backpressure
+	// PrioritizeControl drops queued audio, oldest first, to make room for
+	// any other event, and drops audio rather than other events under
+	// DropOldest. A slow consumer then still sees turn and interruption
+	// events at the cost of gaps in the audio.
+	PrioritizeControl bool
+}
+
+func (c EventsConfig) capacity() int {
+	if c.Capacity <= 0 {
+		return defaultEventCapacity
+	}
+	return c.Capacity
+}
+
+// SetEventOverflow changes what this stream drops when its Events() channel
+// is full. The capacity is fixed when the stream is created.
+func (ms *ManagedStream) SetEventOverflow(o EventOverflow) {
+	ms.mu.Lock()
+	ms.eventOverflow = o
+	ms.mu.Unlock()
+}
+
+// sendEventLocked queues event on Events(), making room for it as the
+// stream's EventOverflow says. It must be called with ms.mu held.
+func (ms *ManagedStream) sendEventLocked(event OrchestratorEvent) {
+	select {
+	case ms.events <- event:
+		ms.stats.EventsEmitted++
+		return
+	case <-ms.ctx.Done():
+		return
+	default:
+	}
+	ms.stats.EventOverflows++
+
+	o := ms.eventOverflow
+	audio := event.Type == AudioChunk
+	switch {
+	case o.PrioritizeControl && (!audio || o.Policy == DropOldest):
+		if !ms.evictAudioLocked() {
+			// Nothing but control events queued.
+			ms.droppedEventLocked(event.Type)
+			return
+		}
+	case o.Policy == DropOldest:
+		select {
+		case old := <-ms.events:
+			ms.droppedEventLocked(old.Type)
+		default:
+		}
+	default:
+		ms.droppedEventLocked(event.Type)
+		return
+	}
+
+	select {
+	case ms.events <- event:
+		ms.stats.EventsEmitted++
+	default:
+		ms.droppedEventLocked(event.Type)
+	}
+}
+
+// evictAudioLocked drops the oldest queued AudioChunk and reports whether
+// there was one. The consumer may read concurrently; the order of what is
+// left does not change.
+func (ms *ManagedStream) evictAudioLocked() bool {
+	queued := make([]OrchestratorEvent, 0, len(ms.events))
+drain:
+	for {
+		select {
+		case ev := <-ms.events:
+			queued = append(queued, ev)
+		default:
+			break drain
+		}
+	}
+	evicted := false
+	for _, ev := range queued {
+		if !evicted && ev.Type == AudioChunk {
+			evicted = true
+			ms.droppedEventLocked(AudioChunk)
+			continue
+		}
+		select {
+		case ms.events <- ev:
+		default:
+			ms.droppedEventLocked(ev.Type)
+		}
+	}
+	return evicted
+}
+
+func (ms *ManagedStream) droppedEventLocked(t EventType) {
+	ms.stats.EventsDropped++
+	if t == AudioChunk {
+		ms.stats.AudioEventsDropped++
+	} else {
+		ms.stats.ControlEventsDropped++
+	}
+}
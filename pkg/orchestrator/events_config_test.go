@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"testing"
+)
+
+func eventsStream(t *testing.T, events EventsConfig) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Events = events
+	return newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+}
+
+func queued(ms *ManagedStream) []OrchestratorEvent {
+	var evs []OrchestratorEvent
+	for {
+		select {
+		case ev := <-ms.Events():
+			evs = append(evs, ev)
+		default:
+			return evs
+		}
+	}
+}
+
+func TestEvents_Overflow(t *testing.T) {
+	ms := eventsStream(t, EventsConfig{Capacity: 3})
+	if c := cap(ms.Events()); c != 3 {
+		t.Fatalf("expected capacity 3, got %d", c)
+	}
+	for i := range 4 {
+		ms.emit(TimerFired, i)
+	}
+	evs := queued(ms)
+	if len(evs) != 3 || evs[2].Data != 2 {
+		t.Errorf("DropNewest should keep the first three events, got %+v", evs)
+	}
+
+	ms.SetEventOverflow(EventOverflow{Policy: DropOldest})
+	for i := range 4 {
+		ms.emit(TimerFired, i)
+	}
+	evs = queued(ms)
+	if len(evs) != 3 || evs[0].Data != 1 || evs[2].Data != 3 {
+		t.Errorf("DropOldest should keep the last three events, got %+v", evs)
+	}
+
+	stats := ms.Status().Stats
+	if stats.EventOverflows != 2 || stats.EventsDropped != 2 || stats.ControlEventsDropped != 2 || stats.AudioEventsDropped != 0 {
+		t.Errorf("unexpected counters %+v", stats)
+	}
+}
+
+func TestEvents_PrioritizeControl(t *testing.T) {
+	ms := eventsStream(t, EventsConfig{Capacity: 3, EventOverflow: EventOverflow{PrioritizeControl: true}})
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.mu.Unlock()
+
+	ms.emit(TimerFired, "first")
+	ms.emit(AudioChunk, []byte{1, 1})
+	ms.emit(AudioChunk, []byte{2, 2})
+	// Full: the oldest audio makes way for control events, and new audio
+	// is dropped.
+	ms.emit(Interrupted, nil)
+	ms.emit(AudioChunk, []byte{3, 3})
+
+	evs := queued(ms)
+	if len(evs) != 3 || evs[0].Type != TimerFired || evs[1].Type != AudioChunk || evs[1].Data.([]byte)[0] != 2 || evs[2].Type != Interrupted {
+		t.Errorf("unexpected queue %+v", evs)
+	}
+
+	// With nothing but control events queued, new ones are dropped.
+	for range 4 {
+		ms.emit(TimerFired, nil)
+	}
+	if evs := queued(ms); len(evs) != 3 {
+		t.Errorf("expected a full queue, got %d events", len(evs))
+	}
+
+	stats := ms.Status().Stats
+	if stats.EventOverflows != 3 || stats.AudioEventsDropped != 2 || stats.ControlEventsDropped != 1 {
+		t.Errorf("unexpected counters %+v", stats)
+	}
+}
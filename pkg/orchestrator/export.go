@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const exportVersion = 1
+
+// TranscriptEntry is one message as it happened. Unlike Context, which is
+// trimmed and compacted for the LLM, the transcript keeps every message of
+// the session. Nothing is recorded while ConsentTranscript is revoked.
+type TranscriptEntry struct {
+	Role     string    `json:"role"`
+	Content  string    `json:"content"`
+	At       time.Time `json:"at"`
+	Voice    Voice     `json:"voice,omitempty"`
+	Language Language  `json:"language,omitempty"`
+	Speaker  string    `json:"speaker,omitempty"`
+	// Latency is set on replies spoken by a ManagedStream.
+	Latency     *LatencyBreakdown `json:"latency,omitempty"`
+	Interrupted bool              `json:"interrupted,omitempty"`
+	// Generation holds the LLM parameters, seed included, of a generated
+	// reply.
+	Generation *GenerationParams `json:"generation,omitempty"`
+}
+
+// SessionExport is the document produced by Export. Session holds everything
+// needed to resume the conversation, including the transcript.
+type SessionExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Session    SessionSnapshot `json:"session"`
+}
+
+// Transcript returns a copy of the session's transcript.
+func (s *ConversationSession) Transcript() []TranscriptEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]TranscriptEntry(nil), s.transcript...)
+}
+
+// Export returns the session as a versioned JSON document for analytics
+// pipelines or to resume it elsewhere with ImportSession.
+func (s *ConversationSession) Export() ([]byte, error) {
+	return json.Marshal(SessionExport{
+		Version:    exportVersion,
+		ExportedAt: time.Now(),
+		Session:    s.Snapshot(),
+	})
+}
+
+// ImportSession restores a session from a document produced by Export.
+func ImportSession(data []byte) (*ConversationSession, error) {
+	var doc SessionExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid session export: %w", err)
+	}
+	if doc.Version != exportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedExport, doc.Version)
+	}
+	if doc.Session.ID == "" {
+		return nil, fmt.Errorf("invalid session export: missing session id")
+	}
+	return RestoreSession(doc.Session), nil
+}
+
+// recordTurn attaches a spoken reply's latency to the last assistant entry.
+func (s *ConversationSession) recordTurn(latency LatencyBreakdown, interrupted bool) {
+	s.mu.Lock()
+	recorded := false
+	for i := len(s.transcript) - 1; i >= 0; i-- {
+		if s.transcript[i].Role == "assistant" {
+			s.transcript[i].Latency = &latency
+			s.transcript[i].Interrupted = interrupted
+			recorded = true
+			break
+		}
+	}
+	s.mu.Unlock()
+	if recorded {
+		s.changed()
+	}
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSession_ExportImport(t *testing.T) {
+	s := NewConversationSession("call-1")
+	s.CurrentVoice = VoiceM2
+	s.AddMessage("system", "be brief")
+	s.AddMessage("user", "hi")
+	s.AddMessage("assistant", "hello")
+	s.recordTurn(LatencyBreakdown{STT: 120, LLM: 300}, false)
+
+	data, err := s.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	json.Unmarshal(data, &doc)
+	if doc["version"] != float64(1) || doc["exported_at"] == nil {
+		t.Errorf("export is missing its envelope: %s", data)
+	}
+
+	got, err := ImportSession(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "call-1" || len(got.GetContextCopy()) != 3 || got.GetCurrentVoice() != VoiceM2 {
+		t.Errorf("unexpected import: %+v", got.Snapshot())
+	}
+	transcript := got.Transcript()
+	if len(transcript) != 3 || transcript[0].At.IsZero() {
+		t.Fatalf("unexpected transcript: %+v", transcript)
+	}
+	reply := transcript[2]
+	if reply.Voice != VoiceM2 || reply.Latency == nil || reply.Latency.LLM != 300 {
+		t.Errorf("expected reply metadata, got %+v", reply)
+	}
+	if transcript[1].Voice != "" || transcript[1].Latency != nil {
+		t.Errorf("user turns carry no voice or latency, got %+v", transcript[1])
+	}
+
+	if _, err := ImportSession([]byte(`{"version":2,"session":{"id":"x"}}`)); !errors.Is(err, ErrUnsupportedExport) {
+		t.Errorf("expected ErrUnsupportedExport, got %v", err)
+	}
+}
+
+func TestSession_TranscriptKeepsTrimmedMessages(t *testing.T) {
+	s := NewConversationSession("long")
+	s.MaxMessages = 2
+	for i := 0; i < 5; i++ {
+		s.AddMessage("user", "again")
+	}
+	if len(s.GetContextCopy()) != 2 || len(s.Transcript()) != 5 {
+		t.Errorf("context %d, transcript %d", len(s.GetContextCopy()), len(s.Transcript()))
+	}
+}
+
+func TestSession_TranscriptIsCapped(t *testing.T) {
+	s := NewConversationSession("long")
+	s.MaxTranscript = 3
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		s.AddMessage("user", text)
+	}
+	transcript := s.Transcript()
+	if len(transcript) != 3 || transcript[0].Content != "three" || transcript[2].Content != "five" {
+		t.Errorf("expected the last 3 entries, got %+v", transcript)
+	}
+
+	restored := RestoreSession(s.Snapshot())
+	restored.AddMessage("user", "six")
+	if got := restored.Transcript(); len(got) != 3 || got[2].Content != "six" {
+		t.Errorf("expected the cap to survive a restore, got %+v", got)
+	}
+
+	s = NewConversationSession("all")
+	s.MaxTranscript = -1
+	for i := 0; i < defaultMaxTranscript+1; i++ {
+		s.AddMessage("user", "again")
+	}
+	if len(s.Transcript()) != defaultMaxTranscript+1 {
+		t.Errorf("negative MaxTranscript keeps everything, got %d", len(s.Transcript()))
+	}
+}
+
+func TestSession_TranscriptHonoursConsent(t *testing.T) {
+	s := NewConversationSession("private")
+	s.AddMessage("user", "first")
+	s.RevokeConsent(ConsentTranscript)
+	if len(s.Transcript()) != 0 {
+		t.Error("revoking transcript consent must drop the transcript")
+	}
+	s.AddMessage("user", "secret")
+	if len(s.Transcript()) != 0 {
+		t.Error("nothing may be recorded without transcript consent")
+	}
+	if len(s.GetContextCopy()) != 2 {
+		t.Error("the LLM context is not affected by transcript consent")
+	}
+}
+
+func TestManagedStream_RecordsTurnLatency(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	tts := &MockTTSProvider{synthesizeResult: []byte{1, 2, 3, 4}}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "sure"}, tts, cfg)
+	session := NewConversationSession("latency")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	stream.mu.Lock()
+	stream.userSpeechEndTime = time.Now()
+	stream.mu.Unlock()
+	session.AddMessage("user", "can you help?")
+	stream.runLLMAndTTS(stream.ctx, "can you help?")
+
+	transcript := session.Transcript()
+	last := transcript[len(transcript)-1]
+	if last.Role != "assistant" || last.Latency == nil || last.Interrupted {
+		t.Errorf("expected latency on the spoken reply, got %+v", last)
+	}
+}
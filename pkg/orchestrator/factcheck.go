@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+const defaultFactCheckFallback = "Let me double-check that and get back to you."
+
+type ClaimKind string
+
+const (
+	ClaimDate         ClaimKind = "date"
+	ClaimPrice        ClaimKind = "price"
+	ClaimAvailability ClaimKind = "availability"
+)
+
+// Claim is a checkable statement found in a response. Text is the matched
+// span, Sentence the sentence around it.
+type Claim struct {
+	Kind     ClaimKind `json:"kind"`
+	Text     string    `json:"text"`
+	Sentence string    `json:"sentence"`
+}
+
+// FactSource verifies claims against the caller's own data, such as a price
+// list, a booking calendar or inventory. It returns the claims it could not
+// confirm; an empty result means the response may be spoken.
+type FactSource interface {
+	Verify(ctx context.Context, response string, claims []Claim) ([]Claim, error)
+	Name() string
+}
+
+// FactCheckConfig verifies responses that contain claims before they are
+// spoken. Responses without claims of the checked Kinds (all by default) skip
+// the source entirely. When a claim fails verification Fallback is spoken
+// instead. Errors from the source count as failed verification unless
+// FailOpen is set. Extract replaces ExtractClaims.
+type FactCheckConfig struct {
+	Source   FactSource
+	Kinds    []ClaimKind
+	Fallback string
+	FailOpen bool
+	Extract  func(text string) []Claim
+}
+
+type FactCheckInfo struct {
+	Source     string  `json:"source"`
+	Unverified []Claim `json:"unverified,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	Fallback   string  `json:"fallback"`
+}
+
+var claimPatterns = []struct {
+	kind    ClaimKind
+	pattern *regexp.Regexp
+}{
+	{ClaimPrice, regexp.MustCompile(`(?i)[$€£]\s?\d[\d,]*(?:\.\d+)?|\b\d[\d,]*(?:\.\d+)?\s?(?:dollars|euros|pounds|usd|eur|gbp)\b`)},
+	{ClaimDate, regexp.MustCompile(`(?i)\b(?:jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\.? \d{1,2}(?:st|nd|rd|th)?(?:,? \d{4})?\b|\b\d{1,2}(?:st|nd|rd|th)? of (?:january|february|march|april|may|june|july|august|september|october|november|december)\b|\b\d{4}-\d{2}-\d{2}\b|\b\d{1,2}/\d{1,2}(?:/\d{2,4})?\b|\b(?:mon|tues|wednes|thurs|fri|satur|sun)day\b|\b\d{1,2}(?::\d{2})? ?(?:am|pm)\b`)},
+	{ClaimAvailability, regexp.MustCompile(`(?i)\b(?:in stock|out of stock|sold out|fully booked|(?:un)?available|availability|open slots?|vacanc(?:y|ies))\b`)},
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?]+(?:\s+|$)`)
+
+// ExtractClaims finds dates, times, prices and availability statements with
+// simple patterns. It favours recall: a spurious claim only costs a lookup.
+func ExtractClaims(text string) []Claim {
+	var claims []Claim
+	start := 0
+	bounds := append(sentenceEnd.FindAllStringIndex(text, -1), []int{len(text), len(text)})
+	for _, b := range bounds {
+		sentence := strings.TrimSpace(text[start:b[1]])
+		start = b[1]
+		for _, p := range claimPatterns {
+			for _, m := range p.pattern.FindAllString(sentence, -1) {
+				claims = append(claims, Claim{Kind: p.kind, Text: m, Sentence: sentence})
+			}
+		}
+	}
+	return claims
+}
+
+// applyFactCheck returns the text to speak. A non-nil info means verification
+// failed and the fallback replaced the response.
+func (o *Orchestrator) applyFactCheck(ctx context.Context, session *ConversationSession, response string) (string, *FactCheckInfo) {
+	cfg := o.GetConfig().FactCheck
+	if cfg.Source == nil || response == "" {
+		return response, nil
+	}
+
+	extract := cfg.Extract
+	if extract == nil {
+		extract = ExtractClaims
+	}
+	var claims []Claim
+	for _, c := range extract(response) {
+		if len(cfg.Kinds) == 0 || containsKind(cfg.Kinds, c.Kind) {
+			claims = append(claims, c)
+		}
+	}
+	if len(claims) == 0 {
+		return response, nil
+	}
+
+	fallback := cfg.Fallback
+	if fallback == "" {
+		fallback = defaultFactCheckFallback
+	}
+	info := &FactCheckInfo{Source: cfg.Source.Name(), Fallback: fallback}
+
+	unverified, err := cfg.Source.Verify(ctx, response, claims)
+	if err != nil {
+		if cfg.FailOpen {
+			o.logger.Warn("fact check failed, allowing response", "sessionID", session.ID, "source", cfg.Source.Name(), "error", err)
+			return response, nil
+		}
+		o.logger.Error("fact check failed, holding back response", "sessionID", session.ID, "source", cfg.Source.Name(), "error", err)
+		info.Unverified = claims
+		info.Reason = "fact source unavailable: " + err.Error()
+		return fallback, info
+	}
+	if len(unverified) == 0 {
+		return response, nil
+	}
+
+	o.logger.Warn("response failed fact check", "sessionID", session.ID, "source", cfg.Source.Name(), "unverified", len(unverified))
+	info.Unverified = unverified
+	return fallback, info
+}
+
+func containsKind(kinds []ClaimKind, kind ClaimKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// priceList confirms prices it knows and rejects everything else.
+type priceList struct {
+	prices map[string]bool
+	err    error
+	calls  int
+}
+
+func (p *priceList) Verify(ctx context.Context, response string, claims []Claim) ([]Claim, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var unverified []Claim
+	for _, c := range claims {
+		if !p.prices[c.Text] {
+			unverified = append(unverified, c)
+		}
+	}
+	return unverified, nil
+}
+
+func (p *priceList) Name() string { return "prices" }
+
+func TestExtractClaims(t *testing.T) {
+	claims := ExtractClaims("The room is $129 per night. We have availability on March 3rd at 4pm! Anything else?")
+	want := []Claim{
+		{Kind: ClaimPrice, Text: "$129", Sentence: "The room is $129 per night."},
+		{Kind: ClaimDate, Text: "March 3rd", Sentence: "We have availability on March 3rd at 4pm!"},
+		{Kind: ClaimDate, Text: "4pm", Sentence: "We have availability on March 3rd at 4pm!"},
+		{Kind: ClaimAvailability, Text: "availability", Sentence: "We have availability on March 3rd at 4pm!"},
+	}
+	if len(claims) != len(want) {
+		t.Fatalf("got %+v", claims)
+	}
+	for i := range want {
+		if claims[i] != want[i] {
+			t.Errorf("claim %d = %+v, want %+v", i, claims[i], want[i])
+		}
+	}
+	if claims := ExtractClaims("Happy to help with that."); len(claims) != 0 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestProcessAudio_FactCheck(t *testing.T) {
+	source := &priceList{prices: map[string]bool{"$129": true}}
+	cfg := DefaultConfig()
+	cfg.FactCheck = FactCheckConfig{Source: source}
+	llm := &MockLLMProvider{completeResult: "It costs $99."}
+	orch := New(&MockSTTProvider{transcribeResult: "how much"}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, cfg)
+	session := orch.NewSessionWithDefaults("facts")
+
+	if _, _, err := orch.ProcessAudio(context.Background(), session, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if session.LastAssistant != defaultFactCheckFallback {
+		t.Errorf("unverified price must not be spoken, got %q", session.LastAssistant)
+	}
+
+	llm.completeResult = "It costs $129."
+	orch.ProcessAudio(context.Background(), session, []byte{1})
+	if session.LastAssistant != "It costs $129." {
+		t.Errorf("verified price must be spoken, got %q", session.LastAssistant)
+	}
+
+	llm.completeResult = "Sure, happy to help."
+	orch.ProcessAudio(context.Background(), session, []byte{1})
+	if source.calls != 2 {
+		t.Errorf("responses without claims must skip the source, %d calls", source.calls)
+	}
+}
+
+func TestFactCheck_SourceErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FactCheck = FactCheckConfig{Source: &priceList{err: errors.New("down")}, Kinds: []ClaimKind{ClaimPrice}}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	session := NewConversationSession("facts")
+
+	if text, info := orch.applyFactCheck(context.Background(), session, "We open on Monday."); info != nil || text != "We open on Monday." {
+		t.Errorf("unchecked kinds must pass, got %q %+v", text, info)
+	}
+	text, info := orch.applyFactCheck(context.Background(), session, "That's $5.")
+	if info == nil || text != defaultFactCheckFallback || len(info.Unverified) != 1 {
+		t.Errorf("source errors must fail closed, got %q %+v", text, info)
+	}
+
+	cfg.FactCheck.FailOpen = true
+	orch.UpdateConfig(cfg)
+	if text, info := orch.applyFactCheck(context.Background(), session, "That's $5."); info != nil || text != "That's $5." {
+		t.Errorf("FailOpen must allow the response, got %q %+v", text, info)
+	}
+}
+
+func TestManagedStream_FactCheckFailedEvent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.FactCheck = FactCheckConfig{Source: &priceList{}, Fallback: "Let me check that price."}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "It's $40 today."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, cfg)
+	session := orch.NewSessionWithDefaults("facts")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	go stream.runLLMAndTTS(stream.ctx, "price?")
+
+	var failed *FactCheckInfo
+	var response string
+	deadline := time.After(time.Second)
+	for failed == nil || response == "" {
+		select {
+		case ev := <-stream.Events():
+			switch ev.Type {
+			case FactCheckFailed:
+				info := ev.Data.(FactCheckInfo)
+				failed = &info
+			case BotResponse:
+				response = ev.Data.(string)
+			}
+		case <-deadline:
+			t.Fatalf("timed out: failed=%v response=%q", failed, response)
+		}
+	}
+
+	if failed.Source != "prices" || len(failed.Unverified) != 1 || failed.Unverified[0].Text != "$40" {
+		t.Errorf("unexpected info %+v", failed)
+	}
+	if response != "Let me check that price." {
+		t.Errorf("expected fallback to be spoken, got %q", response)
+	}
+}
@@ -0,0 +1,228 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const defaultFillerCrossfade = 60 * time.Millisecond
+
+// FillerConfig plays a filler while the LLM works on a reply, masking the
+// latency of slow models. It starts Delay after the final transcript (right
+// away by default) and crossfades into the reply over Crossfade (60ms by
+// default) when the reply's first audio arrives. Audio is played as is (PCM
+// at the playback rate, e.g. FillerTone); otherwise Text, such as "Let me
+// check that…", is synthesized in the caller's voice and cached. With Loop
+// the filler repeats until the reply starts. Fillers are not added to the
+// LLM context.
+type FillerConfig struct {
+	Enabled   bool
+	Text      string
+	Audio     []byte
+	Loop      bool
+	Delay     time.Duration
+	Crossfade time.Duration
+}
+
+// FillerTone returns a soft two-note chime at sampleRate, for use as
+// FillerConfig.Audio.
+func FillerTone(sampleRate int) []byte {
+	const noteMs = 180
+	notes := []float64{523.25, 659.25}
+	n := sampleRate * noteMs / 1000
+	pcm := make([]byte, 0, len(notes)*n*2)
+	for _, freq := range notes {
+		for i := 0; i < n; i++ {
+			// Raised-cosine envelope so the notes neither click nor overlap.
+			env := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+			s := int16(2500 * env * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+			pcm = append(pcm, byte(s), byte(s>>8))
+		}
+	}
+	return pcm
+}
+
+// fillerPlayback is a filler being played. ownsSpeaking is guarded by ms.mu
+// and cleared once the reply takes over isSpeaking.
+type fillerPlayback struct {
+	cancel       context.CancelFunc
+	done         chan struct{}
+	tail         []byte
+	ownsSpeaking bool
+}
+
+// startFiller plays the configured filler for turnID until ctx is done or
+// stopFiller is called.
+func (ms *ManagedStream) startFiller(ctx context.Context, turnID string) {
+	if ms.orch == nil {
+		return
+	}
+	cfg := ms.orch.GetConfig().Filler
+	if !cfg.Enabled || (cfg.Text == "" && len(cfg.Audio) == 0) {
+		return
+	}
+
+	fCtx, cancel := context.WithCancel(ctx)
+	f := &fillerPlayback{cancel: cancel, done: make(chan struct{})}
+	ms.mu.Lock()
+	prev := ms.filler
+	ms.filler = f
+	ms.mu.Unlock()
+	if prev != nil {
+		prev.cancel()
+	}
+
+	ms.spawn(func() {
+		defer close(f.done)
+		defer cancel()
+		f.tail = ms.playFiller(fCtx, f, cfg, turnID)
+		ms.mu.Lock()
+		if f.ownsSpeaking {
+			ms.isSpeaking = false
+		}
+		if ms.filler == f {
+			ms.filler = nil
+		}
+		ms.mu.Unlock()
+	})
+}
+
+// playFiller emits the filler in real time, 20ms at a time, so little of it
+// is buffered when the reply arrives. It returns the filler audio that would
+// have come next, for the crossfade.
+func (ms *ManagedStream) playFiller(ctx context.Context, f *fillerPlayback, cfg FillerConfig, turnID string) []byte {
+	if cfg.Delay > 0 {
+		t := time.NewTimer(cfg.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	pcm := cfg.Audio
+	if len(pcm) == 0 {
+		var err error
+		if pcm, err = ms.fillerAudio(ctx, cfg.Text); err != nil || len(pcm) == 0 {
+			return nil
+		}
+	}
+
+	playbackRate, _ := ms.sampleRates()
+	frameSize := playbackRate / 50 * 2
+	fade := cfg.Crossfade
+	if fade <= 0 {
+		fade = defaultFillerCrossfade
+	}
+	tailSize := int(fade.Seconds()*float64(playbackRate)) * 2
+
+	ms.mu.Lock()
+	if ms.isSpeaking || ctx.Err() != nil {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.isSpeaking = true
+	f.ownsSpeaking = true
+	ms.mu.Unlock()
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	pos := 0
+	for {
+		if pos >= len(pcm) {
+			if !cfg.Loop {
+				return nil
+			}
+			pos = 0
+		}
+		end := min(pos+frameSize, len(pcm))
+		frame := pcm[pos:end]
+		pos = end
+
+		ms.mu.Lock()
+		ms.lastAudioSentAt = time.Now()
+		ms.lastAudioEmittedAt = ms.lastAudioSentAt
+		gen := ms.payloadGen
+		ms.mu.Unlock()
+		ms.trackBotAudio(len(frame))
+		if marker, ok := ms.activeEcho().(EchoMarker); ok {
+			frame = marker.Mark(frame)
+		}
+		ms.emitTurn(AudioChunk, frame, gen, turnID)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			tail := pcm[pos:min(pos+tailSize, len(pcm))]
+			if len(tail) < tailSize && cfg.Loop {
+				tail = append(append([]byte{}, tail...), pcm[:min(tailSize-len(tail), len(pcm))]...)
+			}
+			return tail
+		}
+	}
+}
+
+// fillerAudio synthesizes text in the session's voice, once per voice and
+// language.
+func (ms *ManagedStream) fillerAudio(ctx context.Context, text string) ([]byte, error) {
+	voice, lang := ms.session.GetCurrentVoice(), ms.session.OutputLanguage()
+	key := string(voice) + "|" + string(lang) + "|" + text
+	ms.mu.Lock()
+	pcm, ok := ms.fillerCache[key]
+	ms.mu.Unlock()
+	if ok {
+		return pcm, nil
+	}
+
+	pcm, err := ms.orch.Synthesize(ctx, text, voice, lang)
+	if err != nil {
+		return nil, err
+	}
+	ms.mu.Lock()
+	if ms.fillerCache == nil {
+		ms.fillerCache = make(map[string][]byte)
+	}
+	ms.fillerCache[key] = pcm
+	ms.mu.Unlock()
+	return pcm, nil
+}
+
+// handOverFillerLocked lets the reply take over isSpeaking from a playing
+// filler. It must be called with ms.mu held.
+func (ms *ManagedStream) handOverFillerLocked() {
+	if ms.filler != nil {
+		ms.filler.ownsSpeaking = false
+	}
+}
+
+// stopFiller stops the filler, if any, and returns the audio to fade out.
+func (ms *ManagedStream) stopFiller() []byte {
+	ms.mu.Lock()
+	f := ms.filler
+	ms.filler = nil
+	ms.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	f.cancel()
+	<-f.done
+	return f.tail
+}
+
+// crossfade returns chunk with tail fading out underneath it as chunk fades
+// in, over their overlap.
+func crossfade(chunk, tail []byte) []byte {
+	out := append([]byte(nil), chunk...)
+	n := min(len(out), len(tail)) &^ 1
+	for i := 0; i < n; i += 2 {
+		w := float64(i) / float64(n)
+		a := float64(int16(uint16(out[i]) | uint16(out[i+1])<<8))
+		b := float64(int16(uint16(tail[i]) | uint16(tail[i+1])<<8))
+		s := int16(math.Max(-32768, math.Min(32767, a*w+b*(1-w))))
+		out[i] = byte(s)
+		out[i+1] = byte(s >> 8)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type slowLLM struct {
+	delay time.Duration
+	reply string
+}
+
+func (l *slowLLM) Complete(ctx context.Context, messages []Message) (string, error) {
+	select {
+	case <-time.After(l.delay):
+		return l.reply, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+func (l *slowLLM) Name() string { return "slow" }
+
+func flatPCM(n int, v int16) []byte {
+	pcm := make([]byte, n)
+	for i := 0; i+1 < n; i += 2 {
+		pcm[i], pcm[i+1] = byte(v), byte(v>>8)
+	}
+	return pcm
+}
+
+func sample(pcm []byte, i int) int16 {
+	return int16(uint16(pcm[2*i]) | uint16(pcm[2*i+1])<<8)
+}
+
+// replyAudio drains the events, returning the audio sent before and after
+// BotResponse.
+func replyAudio(ms *ManagedStream) (filler, reply [][]byte) {
+	responded := false
+	for {
+		select {
+		case ev := <-ms.Events():
+			switch {
+			case ev.Type == BotResponse:
+				responded = true
+			case ev.Type == AudioChunk && responded:
+				reply = append(reply, ev.Data.([]byte))
+			case ev.Type == AudioChunk:
+				filler = append(filler, ev.Data.([]byte))
+			}
+		default:
+			return filler, reply
+		}
+	}
+}
+
+func TestFiller_PlaysUntilReplyAndCrossfades(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Filler = FillerConfig{Enabled: true, Audio: flatPCM(1764, 1000), Loop: true}
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 9600)}
+	orch := New(&MockSTTProvider{}, &slowLLM{delay: 150 * time.Millisecond, reply: "Found it."}, tts, cfg)
+	session := NewConversationSession("filler")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	ms.runLLMAndTTS(ms.ctx, "where is my order")
+
+	filler, reply := replyAudio(ms)
+	if len(filler) < 3 {
+		t.Fatalf("expected the filler to loop while the LLM worked, got %d frames", len(filler))
+	}
+	if len(reply) == 0 {
+		t.Fatal("expected the reply to be spoken")
+	}
+	first := reply[0]
+	if s := sample(first, 0); s != 1000 {
+		t.Errorf("expected the reply to start at the filler's level, got %d", s)
+	}
+	if s := sample(first, 400); s <= 0 || s >= 1000 {
+		t.Errorf("expected the filler to be fading out, got %d", s)
+	}
+	// 60ms at 44.1kHz is 2646 samples.
+	if s := sample(reply[3], 2646-3*882+10); s != 0 {
+		t.Errorf("expected the crossfade to be over, got %d", s)
+	}
+	if ms.State() != StreamIdle {
+		t.Errorf("expected the stream to be idle after the reply, got %s", ms.State())
+	}
+	for _, m := range session.GetContextCopy() {
+		if m.Role == "assistant" && m.Content != "Found it." {
+			t.Errorf("the filler must not reach the LLM context: %+v", m)
+		}
+	}
+}
+
+func TestFiller_DelaySkipsFastReplies(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Filler = FillerConfig{Enabled: true, Text: "Let me check that.", Delay: 200 * time.Millisecond}
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 1764)}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Sure."}, tts, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("fast"))
+	defer ms.Close()
+
+	ms.runLLMAndTTS(ms.ctx, "hi")
+
+	if filler, _ := replyAudio(ms); len(filler) != 0 {
+		t.Errorf("expected no filler before a fast reply, got %d frames", len(filler))
+	}
+}
@@ -0,0 +1,361 @@
+package orchestrator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Reasons in FlowChange.
+const (
+	FlowStart    = "start"
+	FlowLLM      = "llm"
+	FlowAuto     = "auto"
+	FlowFallback = "fallback"
+)
+
+// Flow is a declarative dialog script for regulated conversations, set as
+// Config.Flow. It sits between a free-form agent and an IVR: the LLM still
+// words every reply, but only with the current state's prompt, only
+// collects that state's slots and only moves along its transitions. Flows
+// are usually loaded from JSON with ParseFlow.
+type Flow struct {
+	Start  string               `json:"start"`
+	States map[string]FlowState `json:"states"`
+	// Fallback is where states without a Fallback of their own go.
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// FlowState is one step of a Flow.
+type FlowState struct {
+	// Prompt instructs the LLM while the conversation is in this state.
+	Prompt string `json:"prompt"`
+	// Slots are asked for, one at a time, until they are filled.
+	Slots []FlowSlot `json:"slots,omitempty"`
+	// Transitions are the only ways out of the state.
+	Transitions []FlowTransition `json:"transitions,omitempty"`
+	// Tools the application may use in this state; see FlowAllows.
+	Tools []string `json:"tools,omitempty"`
+	// After MaxTurns replies without a slot filled or a transition taken,
+	// the conversation moves to Fallback. Zero never falls back.
+	MaxTurns int    `json:"max_turns,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+	// End marks the end of the script: with EndConversation enabled, the
+	// call ends once the reply is spoken, as with its LLM marker.
+	End bool `json:"end,omitempty"`
+}
+
+// FlowSlot is a value to collect from the caller. A value that doesn't
+// match Pattern, when set, is rejected.
+type FlowSlot struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Pattern     string `json:"pattern,omitempty"`
+}
+
+// FlowTransition leads to state To. The LLM takes it when When describes
+// what was said; without When, it is taken as soon as Requires are filled.
+type FlowTransition struct {
+	To       string   `json:"to"`
+	When     string   `json:"when,omitempty"`
+	Requires []string `json:"requires,omitempty"`
+}
+
+// FlowStatus is where a session is in its flow.
+type FlowStatus struct {
+	State string            `json:"state"`
+	Slots map[string]string `json:"slots,omitempty"`
+	// Turns counts replies in State without progress.
+	Turns int `json:"turns,omitempty"`
+}
+
+// FlowChange is the data of a FlowStateChanged event.
+type FlowChange struct {
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// FlowSlotValue is the data of a FlowSlotFilled event.
+type FlowSlotValue struct {
+	State string `json:"state"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ParseFlow reads a Flow from JSON and validates it.
+func ParseFlow(data []byte) (*Flow, error) {
+	var f Flow
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid flow: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate checks that every state a flow refers to exists, that slots are
+// named and that their patterns compile.
+func (f *Flow) Validate() error {
+	if _, ok := f.States[f.Start]; !ok {
+		return fmt.Errorf("invalid flow: start state %q not defined", f.Start)
+	}
+	if f.Fallback != "" {
+		if _, ok := f.States[f.Fallback]; !ok {
+			return fmt.Errorf("invalid flow: fallback state %q not defined", f.Fallback)
+		}
+	}
+	for name, s := range f.States {
+		if s.Fallback != "" {
+			if _, ok := f.States[s.Fallback]; !ok {
+				return fmt.Errorf("invalid flow: state %q falls back to undefined %q", name, s.Fallback)
+			}
+		}
+		for _, slot := range s.Slots {
+			if slot.Name == "" {
+				return fmt.Errorf("invalid flow: state %q has an unnamed slot", name)
+			}
+			if _, err := regexp.Compile(slot.Pattern); err != nil {
+				return fmt.Errorf("invalid flow: slot %q: %w", slot.Name, err)
+			}
+		}
+		for _, t := range s.Transitions {
+			if _, ok := f.States[t.To]; !ok {
+				return fmt.Errorf("invalid flow: state %q leads to undefined %q", name, t.To)
+			}
+		}
+	}
+	return nil
+}
+
+var flowMarker = regexp.MustCompile(`\[\[(SET|GOTO)\s+([^\]]*)\]\]`)
+
+// instruction tells the LLM what the current state asks of it and how to
+// report slots and transitions.
+func (f *Flow) instruction(status FlowStatus) string {
+	s := f.States[status.State]
+	var b strings.Builder
+	b.WriteString("You are following a script and must stay within its current step. ")
+	b.WriteString(s.Prompt)
+	var missing []string
+	for _, slot := range s.Slots {
+		if _, ok := status.Slots[slot.Name]; !ok {
+			missing = append(missing, fmt.Sprintf("%s (%s)", slot.Name, slot.Description))
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, "\nAsk the caller for these, one at a time: %s. When they give one, end your reply with [[SET name=value]].", strings.Join(missing, ", "))
+	}
+	if len(status.Slots) > 0 {
+		var known []string
+		for _, name := range slices.Sorted(maps.Keys(status.Slots)) {
+			known = append(known, name+"="+status.Slots[name])
+		}
+		fmt.Fprintf(&b, "\nAlready collected: %s.", strings.Join(known, ", "))
+	}
+	for _, t := range s.Transitions {
+		if t.When != "" {
+			fmt.Fprintf(&b, "\nWhen %s, end your reply with [[GOTO %s]].", t.When, t.To)
+		}
+	}
+	b.WriteString("\nNever mention these markers or the script, and don't help with anything outside this step.")
+	return b.String()
+}
+
+// flowUpdate is what a reply changed in the session's flow.
+type flowUpdate struct {
+	slots   []FlowSlotValue
+	changes []FlowChange
+}
+
+// advance applies the markers in response to status and returns the
+// response without them. Markers for other states' slots and transitions
+// are dropped.
+func (f *Flow) advance(status *FlowStatus, response string) (string, flowUpdate) {
+	var u flowUpdate
+	s := f.States[status.State]
+	goTo := ""
+	for _, m := range flowMarker.FindAllStringSubmatch(response, -1) {
+		arg := strings.TrimSpace(m[2])
+		switch m[1] {
+		case "SET":
+			name, value, ok := strings.Cut(arg, "=")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			i := slices.IndexFunc(s.Slots, func(slot FlowSlot) bool { return slot.Name == name })
+			if !ok || i < 0 || value == "" {
+				continue
+			}
+			if p := s.Slots[i].Pattern; p != "" {
+				if re, err := regexp.Compile(p); err != nil || !re.MatchString(value) {
+					continue
+				}
+			}
+			if status.Slots == nil {
+				status.Slots = make(map[string]string)
+			}
+			status.Slots[name] = value
+			u.slots = append(u.slots, FlowSlotValue{State: status.State, Name: name, Value: value})
+		case "GOTO":
+			goTo = arg
+		}
+	}
+	response = strings.TrimSpace(flowMarker.ReplaceAllString(response, ""))
+
+	next, reason := "", ""
+	for _, t := range s.Transitions {
+		if !f.filled(status, t.Requires) {
+			continue
+		}
+		if t.When != "" && t.To == goTo {
+			next, reason = t.To, FlowLLM
+			break
+		}
+		if t.When == "" && next == "" {
+			next, reason = t.To, FlowAuto
+		}
+	}
+	if next == "" && len(u.slots) == 0 {
+		status.Turns++
+		if fallback := cmp.Or(s.Fallback, f.Fallback); s.MaxTurns > 0 && status.Turns >= s.MaxTurns && fallback != "" {
+			next, reason = fallback, FlowFallback
+		}
+	}
+	if next != "" {
+		u.changes = append(u.changes, FlowChange{From: status.State, To: next, Reason: reason})
+		status.State = next
+		status.Turns = 0
+	} else if len(u.slots) > 0 {
+		status.Turns = 0
+	}
+	return response, u
+}
+
+func (f *Flow) filled(status *FlowStatus, slots []string) bool {
+	for _, name := range slots {
+		if _, ok := status.Slots[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// FlowStatus returns where the session is in Config.Flow, and false before
+// the flow has started.
+func (s *ConversationSession) FlowStatus() (FlowStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.flow == nil {
+		return FlowStatus{}, false
+	}
+	return s.flow.clone(), true
+}
+
+// flowSnapshot must be called with s.mu held.
+func (s *ConversationSession) flowSnapshot() *FlowStatus {
+	if s.flow == nil {
+		return nil
+	}
+	st := s.flow.clone()
+	return &st
+}
+
+func (st FlowStatus) clone() FlowStatus {
+	if st.Slots != nil {
+		slots := make(map[string]string, len(st.Slots))
+		for k, v := range st.Slots {
+			slots[k] = v
+		}
+		st.Slots = slots
+	}
+	return st
+}
+
+// FlowAllows reports whether the application may use tool in the session's
+// current flow state. Without a flow, every tool is allowed.
+func (o *Orchestrator) FlowAllows(session *ConversationSession, tool string) bool {
+	flow := o.GetConfig().Flow
+	status, ok := session.FlowStatus()
+	if flow == nil || !ok {
+		return true
+	}
+	return slices.Contains(flow.States[status.State].Tools, tool)
+}
+
+// flowInstruction starts the session's flow if needed and returns the
+// instruction for its state.
+func (o *Orchestrator) flowInstruction(session *ConversationSession) string {
+	flow := o.GetConfig().Flow
+	if flow == nil {
+		return ""
+	}
+	session.mu.Lock()
+	// Updates no stream took are stale.
+	session.pendingFlow = flowUpdate{}
+	if _, ok := flow.States[session.stateOrEmpty()]; !ok {
+		session.flow = &FlowStatus{State: flow.Start}
+		session.pendingFlow.changes = append(session.pendingFlow.changes, FlowChange{To: flow.Start, Reason: FlowStart})
+	}
+	status := session.flow.clone()
+	session.mu.Unlock()
+	return flow.instruction(status)
+}
+
+// stateOrEmpty returns the flow state. It must be called with s.mu held.
+func (s *ConversationSession) stateOrEmpty() string {
+	if s.flow == nil {
+		return ""
+	}
+	return s.flow.State
+}
+
+// advanceFlow applies the flow markers of a reply and returns the reply to
+// speak, and whether the flow reached an End state.
+func (o *Orchestrator) advanceFlow(session *ConversationSession, response string) (string, bool) {
+	flow := o.GetConfig().Flow
+	if flow == nil {
+		return response, false
+	}
+	session.mu.Lock()
+	if session.flow == nil {
+		session.mu.Unlock()
+		return response, false
+	}
+	response, u := flow.advance(session.flow, response)
+	session.pendingFlow.slots = append(session.pendingFlow.slots, u.slots...)
+	session.pendingFlow.changes = append(session.pendingFlow.changes, u.changes...)
+	end := flow.States[session.flow.State].End
+	session.mu.Unlock()
+	if len(u.slots) > 0 || len(u.changes) > 0 {
+		session.changed()
+	}
+	return response, end
+}
+
+// takeFlowUpdate returns the flow changes since the last call.
+func (s *ConversationSession) takeFlowUpdate() flowUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.pendingFlow
+	s.pendingFlow = flowUpdate{}
+	return u
+}
+
+// emitFlowUpdate reports the flow changes made by the turn's reply and
+// returns the states it entered.
+func (ms *ManagedStream) emitFlowUpdate(turnID string) []string {
+	u := ms.session.takeFlowUpdate()
+	for _, slot := range u.slots {
+		ms.emitForTurn(FlowSlotFilled, slot, turnID)
+	}
+	var entered []string
+	for _, c := range u.changes {
+		ms.emitForTurn(FlowStateChanged, c, turnID)
+		entered = append(entered, c.To)
+	}
+	return entered
+}
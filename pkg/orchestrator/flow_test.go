@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testFlow = `{
+	"start": "identify",
+	"fallback": "agent",
+	"states": {
+		"identify": {
+			"prompt": "Verify the caller.",
+			"slots": [
+				{"name": "name", "description": "full name"},
+				{"name": "dob", "description": "date of birth", "pattern": "^\\d{4}-\\d{2}-\\d{2}$"}
+			],
+			"transitions": [{"to": "balance", "requires": ["name", "dob"]}],
+			"max_turns": 2
+		},
+		"balance": {
+			"prompt": "Read out the balance.",
+			"transitions": [{"to": "done", "when": "the caller has no more questions"}],
+			"tools": ["get_balance"]
+		},
+		"agent": {"prompt": "Say a colleague will take over.", "end": true},
+		"done": {"prompt": "Say goodbye.", "end": true}
+	}
+}`
+
+func TestParseFlow_Validates(t *testing.T) {
+	if _, err := ParseFlow([]byte(testFlow)); err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"start":      `{"start": "nowhere", "states": {"a": {}}}`,
+		"fallback":   `{"start": "a", "fallback": "b", "states": {"a": {}}}`,
+		"transition": `{"start": "a", "states": {"a": {"transitions": [{"to": "b"}]}}}`,
+		"slot":       `{"start": "a", "states": {"a": {"slots": [{"description": "x"}]}}}`,
+		"pattern":    `{"start": "a", "states": {"a": {"slots": [{"name": "x", "pattern": "("}]}}}`,
+		"json":       `{"start": `,
+	}
+	for name, data := range cases {
+		if _, err := ParseFlow([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFlow_Advance(t *testing.T) {
+	flow, _ := ParseFlow([]byte(testFlow))
+	status := &FlowStatus{State: "identify"}
+
+	reply, u := flow.advance(status, "Thanks, Ann. And your date of birth? [[SET name=Ann]] [[SET dob=yesterday]] [[GOTO done]]")
+	if reply != "Thanks, Ann. And your date of birth?" {
+		t.Errorf("expected the markers to be stripped, got %q", reply)
+	}
+	if len(u.slots) != 1 || u.slots[0].Name != "name" || len(u.changes) != 0 || status.State != "identify" {
+		t.Errorf("expected only the valid slot, got %+v in %+v", u, status)
+	}
+
+	_, u = flow.advance(status, "Thank you. [[SET dob=1990-04-01]]")
+	if len(u.changes) != 1 || u.changes[0] != (FlowChange{From: "identify", To: "balance", Reason: FlowAuto}) {
+		t.Errorf("expected an automatic transition, got %+v", u.changes)
+	}
+
+	_, u = flow.advance(status, "Anything else? [[GOTO agent]]")
+	if len(u.changes) != 0 || status.Turns != 1 {
+		t.Errorf("expected a GOTO without a transition to be ignored, got %+v in %+v", u, status)
+	}
+	_, u = flow.advance(status, "Goodbye! [[GOTO done]]")
+	if len(u.changes) != 1 || u.changes[0].Reason != FlowLLM || status.State != "done" || status.Turns != 0 {
+		t.Errorf("expected the LLM transition, got %+v in %+v", u, status)
+	}
+}
+
+func TestFlow_FallsBack(t *testing.T) {
+	flow, _ := ParseFlow([]byte(testFlow))
+	status := &FlowStatus{State: "identify"}
+
+	flow.advance(status, "Could you tell me your name?")
+	if _, u := flow.advance(status, "Sorry, your name?"); len(u.changes) != 1 || u.changes[0].Reason != FlowFallback || status.State != "agent" {
+		t.Errorf("expected a fallback after max_turns, got %+v in %+v", u, status)
+	}
+}
+
+func TestFlow_DrivesStream(t *testing.T) {
+	flow, _ := ParseFlow([]byte(testFlow))
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Flow = flow
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Thanks, Ann. [[SET name=Ann]]"}, &MockTTSProvider{synthesizeResult: make([]byte, 64)}, cfg)
+	session := NewConversationSession("flow")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	if !orch.FlowAllows(session, "get_balance") {
+		t.Error("expected every tool to be allowed before the flow starts")
+	}
+	ms.InjectUserText("Hi, I'm Ann.")
+
+	if ev := waitForEvent(t, ms, FlowSlotFilled); ev.Data != (FlowSlotValue{State: "identify", Name: "name", Value: "Ann"}) {
+		t.Errorf("unexpected slot %+v", ev.Data)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "Thanks, Ann." {
+		t.Errorf("expected the marker to be stripped, got %q", ev.Data)
+	}
+	status, ok := session.FlowStatus()
+	if !ok || status.State != "identify" || status.Slots["name"] != "Ann" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if orch.FlowAllows(session, "get_balance") {
+		t.Error("expected get_balance to be off limits while identifying")
+	}
+
+	restored := RestoreSession(session.Snapshot())
+	if got, _ := restored.FlowStatus(); got.State != "identify" || got.Slots["name"] != "Ann" {
+		t.Errorf("expected the flow to survive a transfer, got %+v", got)
+	}
+}
+
+func TestFlow_Instruction(t *testing.T) {
+	flow, _ := ParseFlow([]byte(testFlow))
+	got := flow.instruction(FlowStatus{State: "identify", Slots: map[string]string{"name": "Ann"}})
+	for _, want := range []string{"Verify the caller.", "dob (date of birth)", "Already collected: name=Ann."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "name (full name)") {
+		t.Error("expected filled slots not to be asked for again")
+	}
+	if got := flow.instruction(FlowStatus{State: "balance"}); !strings.Contains(got, "[[GOTO done]]") {
+		t.Errorf("expected the transition in %q", got)
+	}
+}
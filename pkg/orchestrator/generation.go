@@ -0,0 +1,136 @@
+package orchestrator
+
+import (
+	"context"
+	"math/rand"
+)
+
+type GenerationParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int64   `json:"seed,omitempty"`
+}
+
+type ParameterizedLLMProvider interface {
+	LLMProvider
+	CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error)
+}
+
+// SeededLLMProvider is implemented by providers that honour
+// GenerationParams.Seed; the others ignore it.
+type SeededLLMProvider interface {
+	SupportsSeed() bool
+}
+
+// DeterminismConfig gives every LLM turn a random seed when the provider
+// supports seeds and none is set. The seed and the other parameters used are
+// recorded on the Turn and in the transcript, so a turn can be replayed with
+// the same parameters.
+type DeterminismConfig struct {
+	Enabled bool
+}
+
+func Float(v float64) *float64 {
+	return &v
+}
+
+func Int64(v int64) *int64 {
+	return &v
+}
+
+// Merge returns p with every field that is set in override replaced.
+func (p GenerationParams) Merge(override GenerationParams) GenerationParams {
+	out := p
+	if override.Temperature != nil {
+		out.Temperature = override.Temperature
+	}
+	if override.MaxTokens > 0 {
+		out.MaxTokens = override.MaxTokens
+	}
+	if override.TopP != nil {
+		out.TopP = override.TopP
+	}
+	if len(override.Stop) > 0 {
+		out.Stop = append([]string(nil), override.Stop...)
+	}
+	if override.Seed != nil {
+		out.Seed = override.Seed
+	}
+	return out
+}
+
+func (p GenerationParams) IsZero() bool {
+	return p.Temperature == nil && p.MaxTokens == 0 && p.TopP == nil && len(p.Stop) == 0 && p.Seed == nil
+}
+
+// seedParams assigns a turn seed under Config.Determinism.
+func (o *Orchestrator) seedParams(llm LLMProvider, params GenerationParams) GenerationParams {
+	if !o.GetConfig().Determinism.Enabled || params.Seed != nil {
+		return params
+	}
+	if p, ok := llm.(SeededLLMProvider); ok && p.SupportsSeed() {
+		params.Seed = Int64(rand.Int63())
+	}
+	return params
+}
+
+func (s *ConversationSession) SetGenerationParams(params GenerationParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Generation = params
+}
+
+// setPendingParams records the parameters of a reply about to be added.
+func (s *ConversationSession) setPendingParams(params GenerationParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingParams = &params
+}
+
+// ReplayGenerations queues the parameters of recorded turns, seeds
+// included: each of the session's next LLM turns is generated with the next
+// of them, so a recorded conversation can be reproduced.
+func (s *ConversationSession) ReplayGenerations(params ...GenerationParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replayParams = append(s.replayParams, params...)
+}
+
+func (s *ConversationSession) nextReplayParams() (GenerationParams, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.replayParams) == 0 {
+		return GenerationParams{}, false
+	}
+	params := s.replayParams[0]
+	s.replayParams = s.replayParams[1:]
+	return params, true
+}
+
+func (s *ConversationSession) GetGenerationParams() GenerationParams {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Generation
+}
+
+func (o *Orchestrator) generationParams(session *ConversationSession) GenerationParams {
+	return o.GetConfig().Generation.Merge(session.GetGenerationParams())
+}
+
+func (o *Orchestrator) complete(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error) {
+	return o.completeWith(ctx, o.llm, messages, params)
+}
+
+func (o *Orchestrator) completeWith(ctx context.Context, llm LLMProvider, messages []Message, params GenerationParams) (string, Usage, error) {
+	if p, ok := llm.(UsageLLMProvider); ok {
+		return p.CompleteWithUsage(ctx, messages, params)
+	}
+	if p, ok := llm.(ParameterizedLLMProvider); ok {
+		text, err := p.CompleteWithParams(ctx, messages, params)
+		return text, Usage{}, err
+	}
+	text, err := llm.Complete(ctx, messages)
+	return text, Usage{}, err
+}
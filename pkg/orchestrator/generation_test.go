@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+// seededLLM supports seeds and records the parameters of each call.
+type seededLLM struct {
+	params []GenerationParams
+}
+
+func (l *seededLLM) Complete(ctx context.Context, messages []Message) (string, error) {
+	return l.CompleteWithParams(ctx, messages, GenerationParams{})
+}
+
+func (l *seededLLM) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *seededLLM) CompleteWithUsage(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error) {
+	l.params = append(l.params, params)
+	return "Sure.", Usage{}, nil
+}
+
+func (l *seededLLM) SupportsSeed() bool { return true }
+func (l *seededLLM) Name() string       { return "seeded" }
+
+func TestDeterminism_SeedsRecordedPerTurn(t *testing.T) {
+	llm := &seededLLM{}
+	cfg := DefaultConfig()
+	cfg.Determinism.Enabled = true
+	cfg.Generation.Temperature = Float(0.7)
+	orch := New(&MockSTTProvider{transcribeResult: "hi"}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, cfg)
+	session := orch.NewSessionWithDefaults("seeded")
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := orch.ProcessAudio(context.Background(), session, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(llm.params) != 2 || llm.params[0].Seed == nil || llm.params[1].Seed == nil {
+		t.Fatalf("expected a seed per turn, got %+v", llm.params)
+	}
+
+	var recorded []*GenerationParams
+	for _, e := range session.Transcript() {
+		if e.Role == "assistant" {
+			recorded = append(recorded, e.Generation)
+		}
+	}
+	if len(recorded) != 2 || recorded[0] == nil || *recorded[0].Seed != *llm.params[0].Seed || *recorded[0].Temperature != 0.7 {
+		t.Fatalf("transcript must carry the turn's parameters, got %+v", recorded)
+	}
+
+	// Replaying with the recorded parameters sends the same seed.
+	session.SetGenerationParams(*recorded[1])
+	orch.GenerateResponse(context.Background(), session)
+	if *llm.params[2].Seed != *llm.params[1].Seed {
+		t.Error("a recorded seed must be reused, not replaced")
+	}
+}
+
+func TestDeterminism_UnsupportedProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Determinism.Enabled = true
+	orch := New(&MockSTTProvider{transcribeResult: "hi"}, &MockLLMProvider{completeResult: "Sure."}, &MockTTSProvider{synthesizeResult: []byte{1}}, cfg)
+	session := orch.NewSessionWithDefaults("unseeded")
+
+	orch.ProcessAudio(context.Background(), session, []byte{1})
+	for _, e := range session.Transcript() {
+		if e.Generation != nil {
+			t.Errorf("providers without seeds get none, got %+v", e.Generation)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"regexp"
+)
+
+type GuardrailAction string
+
+const (
+	GuardrailAllow   GuardrailAction = "allow"
+	GuardrailRewrite GuardrailAction = "rewrite"
+	GuardrailBlock   GuardrailAction = "block"
+)
+
+type GuardrailVerdict struct {
+	Action GuardrailAction
+	// Text replaces the response when Action is GuardrailRewrite.
+	Text     string
+	Reason   string
+	Category string
+}
+
+// GuardrailProvider inspects an LLM response before it is spoken.
+type GuardrailProvider interface {
+	Check(ctx context.Context, text string) (GuardrailVerdict, error)
+	Name() string
+}
+
+// GuardrailConfig attaches a guardrail to the response path. Fallback is spoken
+// instead of a blocked response; when empty the turn stays silent. Errors from
+// the provider block the response unless FailOpen is set.
+type GuardrailConfig struct {
+	Provider GuardrailProvider
+	Fallback string
+	FailOpen bool
+}
+
+type GuardrailBlockedInfo struct {
+	Provider string `json:"provider"`
+	Reason   string `json:"reason,omitempty"`
+	Category string `json:"category,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// applyGuardrail returns the text to speak. A non-nil info means the response
+// was blocked and the caller should report it.
+func (o *Orchestrator) applyGuardrail(ctx context.Context, session *ConversationSession, response string) (string, *GuardrailBlockedInfo) {
+	cfg := o.GetConfig().Guardrail
+	if cfg.Provider == nil {
+		return response, nil
+	}
+
+	verdict, err := cfg.Provider.Check(ctx, response)
+	if err != nil {
+		if cfg.FailOpen {
+			o.logger.Warn("guardrail check failed, allowing response", "sessionID", session.ID, "guardrail", cfg.Provider.Name(), "error", err)
+			return response, nil
+		}
+		o.logger.Error("guardrail check failed, blocking response", "sessionID", session.ID, "guardrail", cfg.Provider.Name(), "error", err)
+		verdict = GuardrailVerdict{Action: GuardrailBlock, Reason: "guardrail unavailable: " + err.Error()}
+	}
+
+	switch verdict.Action {
+	case GuardrailRewrite:
+		o.logger.Info("guardrail rewrote response", "sessionID", session.ID, "guardrail", cfg.Provider.Name(), "reason", verdict.Reason)
+		return verdict.Text, nil
+	case GuardrailBlock:
+		o.logger.Warn("guardrail blocked response", "sessionID", session.ID, "guardrail", cfg.Provider.Name(), "reason", verdict.Reason)
+		return cfg.Fallback, &GuardrailBlockedInfo{
+			Provider: cfg.Provider.Name(),
+			Reason:   verdict.Reason,
+			Category: verdict.Category,
+			Fallback: cfg.Fallback,
+		}
+	}
+	return response, nil
+}
+
+type RegexRule struct {
+	Pattern *regexp.Regexp
+	Action  GuardrailAction
+	// Replacement is used for GuardrailRewrite; it may reference capture
+	// groups as in regexp.ReplaceAllString.
+	Replacement string
+	Reason      string
+}
+
+// RegexGuardrail applies rules in order. Rewrite rules are cumulative; the
+// first matching block rule wins.
+type RegexGuardrail struct {
+	rules []RegexRule
+}
+
+func NewRegexGuardrail(rules ...RegexRule) *RegexGuardrail {
+	return &RegexGuardrail{rules: rules}
+}
+
+func (g *RegexGuardrail) Check(ctx context.Context, text string) (GuardrailVerdict, error) {
+	out := text
+	var reason string
+	for _, rule := range g.rules {
+		if !rule.Pattern.MatchString(out) {
+			continue
+		}
+		switch rule.Action {
+		case GuardrailBlock:
+			return GuardrailVerdict{Action: GuardrailBlock, Reason: rule.Reason, Category: "regex"}, nil
+		case GuardrailRewrite:
+			out = rule.Pattern.ReplaceAllString(out, rule.Replacement)
+			reason = rule.Reason
+		}
+	}
+	if out != text {
+		return GuardrailVerdict{Action: GuardrailRewrite, Text: out, Reason: reason, Category: "regex"}, nil
+	}
+	return GuardrailVerdict{Action: GuardrailAllow}, nil
+}
+
+func (g *RegexGuardrail) Name() string {
+	return "regex"
+}
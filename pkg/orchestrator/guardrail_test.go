@@ -0,0 +1,120 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type stubGuardrail struct {
+	verdict GuardrailVerdict
+	err     error
+}
+
+func (g *stubGuardrail) Check(ctx context.Context, text string) (GuardrailVerdict, error) {
+	return g.verdict, g.err
+}
+
+func (g *stubGuardrail) Name() string { return "stub" }
+
+func TestRegexGuardrail(t *testing.T) {
+	g := NewRegexGuardrail(
+		RegexRule{Pattern: regexp.MustCompile(`\b\d{16}\b`), Action: GuardrailRewrite, Replacement: "[redacted]", Reason: "card number"},
+		RegexRule{Pattern: regexp.MustCompile(`(?i)competitor`), Action: GuardrailBlock, Reason: "off-brand"},
+	)
+
+	v, _ := g.Check(context.Background(), "your card 4111111111111111 is active")
+	if v.Action != GuardrailRewrite || v.Text != "your card [redacted] is active" {
+		t.Errorf("unexpected rewrite: %+v", v)
+	}
+
+	v, _ = g.Check(context.Background(), "Try our Competitor instead")
+	if v.Action != GuardrailBlock || v.Reason != "off-brand" {
+		t.Errorf("expected block, got %+v", v)
+	}
+
+	v, _ = g.Check(context.Background(), "all good")
+	if v.Action != GuardrailAllow {
+		t.Errorf("expected allow, got %+v", v)
+	}
+}
+
+func TestProcessAudio_GuardrailRewrite(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Guardrail = GuardrailConfig{Provider: &stubGuardrail{verdict: GuardrailVerdict{Action: GuardrailRewrite, Text: "safe text"}}}
+	tts := &MockTTSProvider{synthesizeResult: []byte{1}}
+	orch := New(&MockSTTProvider{transcribeResult: "hi"}, &MockLLMProvider{completeResult: "unsafe text"}, tts, cfg)
+	session := orch.NewSessionWithDefaults("guard")
+
+	if _, _, err := orch.ProcessAudio(context.Background(), session, []byte{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.LastAssistant != "safe text" {
+		t.Errorf("expected rewritten text in history, got %q", session.LastAssistant)
+	}
+}
+
+func TestProcessAudio_GuardrailBlock(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Guardrail = GuardrailConfig{Provider: &stubGuardrail{err: errors.New("timeout")}}
+	orch := New(&MockSTTProvider{transcribeResult: "hi"}, &MockLLMProvider{completeResult: "text"}, &MockTTSProvider{}, cfg)
+
+	_, _, err := orch.ProcessAudio(context.Background(), orch.NewSessionWithDefaults("guard"), []byte{1})
+	if !errors.Is(err, ErrGuardrailBlocked) {
+		t.Fatalf("expected guardrail errors to fail closed, got %v", err)
+	}
+
+	cfg.Guardrail.FailOpen = true
+	orch.UpdateConfig(cfg)
+	if _, _, err := orch.ProcessAudio(context.Background(), orch.NewSessionWithDefaults("guard"), []byte{1}); err != nil {
+		t.Fatalf("expected fail-open to allow the response, got %v", err)
+	}
+}
+
+func TestManagedStream_GuardrailBlockedEvent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Guardrail = GuardrailConfig{
+		Provider: &stubGuardrail{verdict: GuardrailVerdict{Action: GuardrailBlock, Reason: "policy"}},
+		Fallback: "Sorry, I can't help with that.",
+	}
+	tts := &MockTTSProvider{synthesizeResult: []byte{1, 2}}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "forbidden"}, tts, cfg)
+	session := orch.NewSessionWithDefaults("guard")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	go stream.runLLMAndTTS(stream.ctx, "hi")
+
+	var blocked *GuardrailBlockedInfo
+	var response string
+	deadline := time.After(time.Second)
+	for blocked == nil || response == "" {
+		select {
+		case ev := <-stream.Events():
+			switch ev.Type {
+			case GuardrailBlocked:
+				info := ev.Data.(GuardrailBlockedInfo)
+				blocked = &info
+			case BotResponse:
+				response = ev.Data.(string)
+			}
+		case <-deadline:
+			t.Fatalf("timed out: blocked=%v response=%q", blocked, response)
+		}
+	}
+
+	if blocked.Reason != "policy" || blocked.Provider != "stub" {
+		t.Errorf("unexpected blocked info: %+v", blocked)
+	}
+	if response != cfg.Guardrail.Fallback {
+		t.Errorf("expected fallback to be spoken, got %q", response)
+	}
+	for _, m := range session.GetContextCopy() {
+		if m.Content == "forbidden" {
+			t.Error("blocked response must not enter the history")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package orchestrator
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHallucinationRMS      = 0.01
+	defaultHallucinationDuration = 250 * time.Millisecond
+)
+
+// DefaultHallucinations are phrases Whisper-style models are known to emit
+// on silence and noise, per language. English ones are checked for every
+// language.
+var DefaultHallucinations = map[Language][]string{
+	LanguageEn: {
+		"thanks for watching", "thank you for watching", "thank you so much for watching",
+		"please subscribe", "like and subscribe", "subscribe to my channel",
+		"don't forget to like and subscribe", "see you in the next video",
+		"subtitles by the amara.org community", "transcription by castingwords",
+	},
+	LanguageEs: {
+		"gracias por ver", "gracias por ver el video", "suscríbete al canal",
+		"no olvides suscribirte", "subtítulos realizados por la comunidad de amara.org",
+	},
+	LanguageFr: {
+		"merci d'avoir regardé", "abonnez-vous", "n'oubliez pas de vous abonner",
+		"sous-titres réalisés par la communauté d'amara.org",
+	},
+	LanguageDe: {
+		"danke fürs zuschauen", "vielen dank fürs zuschauen", "abonniert den kanal",
+		"untertitel im auftrag des zdf", "untertitel der amara.org-community",
+	},
+	LanguageIt: {
+		"grazie per la visione", "iscriviti al canale",
+		"sottotitoli creati dalla comunità amara.org",
+	},
+	LanguagePt: {
+		"obrigado por assistir", "inscreva-se no canal",
+		"legendas pela comunidade amara.org",
+	},
+}
+
+// HallucinationFilterConfig drops final transcripts that are likely STT
+// hallucinations before they reach the LLM: those of utterances quieter than
+// MinRMS (0.01 by default, the loudest 20ms counts) or shorter than
+// MinDuration (250ms by default), and those made up of known phrases
+// (DefaultHallucinations when Phrases is nil). Dropped transcripts are
+// reported as TranscriptDropped and handled like noise.
+type HallucinationFilterConfig struct {
+	Enabled     bool
+	MinRMS      float64
+	MinDuration time.Duration
+	Phrases     map[Language][]string
+}
+
+// DroppedTranscript is the data of a TranscriptDropped event. Reason is
+// "energy", "duration" or "phrase", or "trust" (see TrustConfig).
+type DroppedTranscript struct {
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// check returns why transcript of audio should be dropped, or "". The
+// audio checks are skipped when there is no audio, e.g. for typed input.
+func (c HallucinationFilterConfig) check(transcript string, audio []byte, sampleRate int, lang Language) string {
+	if len(audio) > 0 && sampleRate > 0 {
+		minDuration := c.MinDuration
+		if minDuration <= 0 {
+			minDuration = defaultHallucinationDuration
+		}
+		if time.Duration(len(audio)/2)*time.Second/time.Duration(sampleRate) < minDuration {
+			return "duration"
+		}
+		minRMS := c.MinRMS
+		if minRMS <= 0 {
+			minRMS = defaultHallucinationRMS
+		}
+		if peakRMS(audio, sampleRate/50*2) < minRMS {
+			return "energy"
+		}
+	}
+
+	phrases := c.Phrases
+	if phrases == nil {
+		phrases = DefaultHallucinations
+	}
+	known := phrases[LanguageEn]
+	if lang != LanguageEn {
+		known = append(append([]string(nil), phrases[lang]...), known...)
+	}
+	if onlyPhrases(transcript, known) {
+		return "phrase"
+	}
+	return ""
+}
+
+// peakRMS is the RMS of the loudest frame of audio.
+func peakRMS(audio []byte, frameBytes int) float64 {
+	if frameBytes <= 0 {
+		frameBytes = len(audio)
+	}
+	var peak float64
+	for i := 0; i < len(audio); i += frameBytes {
+		if rms := pcmRMS(audio[i:min(i+frameBytes, len(audio))]); rms > peak {
+			peak = rms
+		}
+	}
+	return peak
+}
+
+// onlyPhrases reports whether transcript consists of nothing but phrases,
+// ignoring case and punctuation: "Thanks for watching! Please subscribe."
+func onlyPhrases(transcript string, phrases []string) bool {
+	rest := " " + strings.Join(backchannelWords(transcript), " ") + " "
+	if strings.TrimSpace(rest) == "" {
+		return false
+	}
+	normalized := make([]string, 0, len(phrases))
+	for _, p := range phrases {
+		if n := strings.Join(backchannelWords(p), " "); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+	// Longest first, so a phrase is not left half-removed by a shorter one.
+	sort.Slice(normalized, func(i, j int) bool { return len(normalized[i]) > len(normalized[j]) })
+	for _, p := range normalized {
+		// Adjacent matches share a space, so one pass may leave some.
+		for strings.Contains(rest, " "+p+" ") {
+			rest = strings.ReplaceAll(rest, " "+p+" ", " ")
+		}
+	}
+	return strings.TrimSpace(rest) == ""
+}
+
+// hallucinated reports, and drops, a final transcript the filter rejects.
+func (ms *ManagedStream) hallucinated(transcript, turnID string) bool {
+	if ms.orch == nil {
+		return false
+	}
+	cfg := ms.orch.GetConfig().HallucinationFilter
+	if !cfg.Enabled {
+		return false
+	}
+	ms.mu.Lock()
+	audio := ms.lastUserAudio
+	ms.mu.Unlock()
+	_, inputRate := ms.sampleRates()
+
+	reason := cfg.check(transcript, audio, inputRate, ms.session.InputLanguage())
+	if reason == "" {
+		return false
+	}
+	ms.mu.Lock()
+	ms.stats.TranscriptsDropped++
+	ms.mu.Unlock()
+	ms.emitForTurn(TranscriptDropped, DroppedTranscript{Text: transcript, Reason: reason}, turnID)
+	return true
+}
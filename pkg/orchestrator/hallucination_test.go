@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHallucinationFilter_Check(t *testing.T) {
+	loud := flatPCM(44100, 3000) // 500ms
+	cfg := HallucinationFilterConfig{Enabled: true}
+	cases := []struct {
+		transcript string
+		audio      []byte
+		lang       Language
+		want       string
+	}{
+		{"Thanks for watching!", loud, LanguageEn, "phrase"},
+		{"Thanks for watching! Please subscribe. Please subscribe.", loud, LanguageEn, "phrase"},
+		{"Thanks for watching the kids.", loud, LanguageEn, ""},
+		{"Gracias por ver el video.", loud, LanguageEs, "phrase"},
+		{"Thank you for watching.", loud, LanguageEs, "phrase"},
+		{"Gracias por ver el video.", loud, LanguageEn, ""},
+		{"I'd like a refund.", flatPCM(44100, 50), LanguageEn, "energy"},
+		{"I'd like a refund.", flatPCM(8820, 3000), LanguageEn, "duration"},
+		{"I'd like a refund.", nil, LanguageEn, ""},
+	}
+	for _, c := range cases {
+		if got := cfg.check(c.transcript, c.audio, 44100, c.lang); got != c.want {
+			t.Errorf("check(%q, %s) = %q, want %q", c.transcript, c.lang, got, c.want)
+		}
+	}
+
+	custom := HallucinationFilterConfig{Phrases: map[Language][]string{LanguageEn: {"beep"}}}
+	if custom.check("Thanks for watching!", nil, 44100, LanguageEn) != "" || custom.check("Beep.", nil, 44100, LanguageEn) != "phrase" {
+		t.Error("expected custom phrases to replace the defaults")
+	}
+}
+
+func TestHallucinationFilter_DropsBeforeLLM(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.HallucinationFilter.Enabled = true
+	orch := New(&MockSTTProvider{transcribeResult: "Thanks for watching."}, &MockLLMProvider{completeResult: "You're welcome."}, &MockTTSProvider{}, cfg)
+	session := NewConversationSession("whisper")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	stream.runBatchPipeline(flatPCM(44100, 3000))
+
+	ev := waitForEvent(t, stream, TranscriptDropped)
+	if d, ok := ev.Data.(DroppedTranscript); !ok || d.Reason != "phrase" || d.Text != "Thanks for watching." {
+		t.Errorf("unexpected drop %#v", ev.Data)
+	}
+	select {
+	case ev := <-stream.Events():
+		if ev.Type == TranscriptFinal || ev.Type == BotResponse {
+			t.Errorf("a dropped transcript must not be answered, got %s", ev.Type)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(session.GetContextCopy()) != 0 {
+		t.Errorf("expected an empty context, got %+v", session.GetContextCopy())
+	}
+	if n := stream.Status().Stats.TranscriptsDropped; n != 1 {
+		t.Errorf("expected 1 dropped transcript, got %d", n)
+	}
+}
@@ -0,0 +1,74 @@
+package orchestrator
+
+import "time"
+
+// CallEndIdle is the CallEndInfo reason when IdleConfig.MaxTimeouts is
+// reached.
+const CallEndIdle = "idle"
+
+// IdleConfig handles callers who go quiet. After Timeout of silence on an
+// idle stream (the bot done talking, nothing paused or in progress) the
+// stream emits Idle and, if set, speaks Prompt, e.g. "Are you still
+// there?". After MaxTimeouts timeouts in a row without the caller speaking,
+// the call ends with CallEnded (reason "idle"); 0 never ends it.
+type IdleConfig struct {
+	Enabled     bool
+	Timeout     time.Duration
+	Prompt      string
+	MaxTimeouts int
+}
+
+// IdleInfo is the data of an Idle event. Count is the number of timeouts in
+// a row, this one included.
+type IdleInfo struct {
+	Count   int           `json:"count"`
+	Silence time.Duration `json:"silence"`
+}
+
+// watchIdle times the caller's silence while the stream is idle.
+func (ms *ManagedStream) watchIdle(cfg IdleConfig) {
+	ticker := time.NewTicker(min(cfg.Timeout/10, 100*time.Millisecond))
+	defer ticker.Stop()
+	var since time.Time
+	for {
+		select {
+		case <-ticker.C:
+		case <-ms.ctx.Done():
+			return
+		}
+		if ms.State() != StreamIdle {
+			since = time.Time{}
+			continue
+		}
+		if since.IsZero() {
+			// The clock starts once the caller has heard the bot out.
+			since = time.Now().Add(ms.unplayed())
+			continue
+		}
+		silence := time.Since(since)
+		if silence < cfg.Timeout {
+			continue
+		}
+
+		ms.mu.Lock()
+		ms.idleTimeouts++
+		count := ms.idleTimeouts
+		ms.mu.Unlock()
+		ms.emit(Idle, IdleInfo{Count: count, Silence: silence})
+		if cfg.MaxTimeouts > 0 && count >= cfg.MaxTimeouts {
+			ms.endCall(CallEndIdle)
+			return
+		}
+		if cfg.Prompt != "" {
+			ms.speakText(ms.ctx, cfg.Prompt)
+		}
+		since = time.Time{}
+	}
+}
+
+// resetIdle restarts the count of timeouts once the caller speaks.
+func (ms *ManagedStream) resetIdle() {
+	ms.mu.Lock()
+	ms.idleTimeouts = 0
+	ms.mu.Unlock()
+}
@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func newIdleStream(t *testing.T, idle IdleConfig) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Idle = idle
+	return newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{completeResult: "Sure."})
+}
+
+func TestIdle_PromptsAfterSilence(t *testing.T) {
+	stream := newIdleStream(t, IdleConfig{Enabled: true, Timeout: 100 * time.Millisecond, Prompt: "Are you still there?"})
+
+	ev := waitForEvent(t, stream, Idle)
+	if info := ev.Data.(IdleInfo); info.Count != 1 || info.Silence < 100*time.Millisecond {
+		t.Errorf("unexpected idle %+v", info)
+	}
+	if ev := waitForEvent(t, stream, BotResponse); ev.Data != "Are you still there?" {
+		t.Errorf("expected the re-engagement prompt, got %v", ev.Data)
+	}
+	if ev := waitForEvent(t, stream, Idle); ev.Data.(IdleInfo).Count != 2 {
+		t.Errorf("expected a second timeout, got %+v", ev.Data)
+	}
+
+	stream.InjectUserText("Sorry, I'm here.")
+	if ev := waitForEvent(t, stream, Idle); ev.Data.(IdleInfo).Count != 1 {
+		t.Errorf("expected the count to restart once the caller spoke, got %+v", ev.Data)
+	}
+}
+
+func TestIdle_EndsCallAfterMaxTimeouts(t *testing.T) {
+	stream := newIdleStream(t, IdleConfig{Enabled: true, Timeout: 50 * time.Millisecond, MaxTimeouts: 2})
+
+	waitForEvent(t, stream, Idle)
+	ev := waitForEvent(t, stream, CallEnded)
+	if info, ok := ev.Data.(CallEndInfo); !ok || info.Reason != CallEndIdle {
+		t.Errorf("unexpected call end %#v", ev.Data)
+	}
+	waitForClose(t, stream)
+}
+
+func TestIdle_NotWhilePaused(t *testing.T) {
+	stream := newIdleStream(t, IdleConfig{Enabled: true, Timeout: 50 * time.Millisecond})
+	stream.Pause()
+
+	deadline := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type == Idle {
+				t.Fatal("expected no timeouts while paused")
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+func TestIdle_LiveSilence(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Idle = IdleConfig{Enabled: true, Timeout: 100 * time.Millisecond}
+	stream := newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+
+	// A phone line keeps sending silence while the caller says nothing.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				stream.Write(make([]byte, 882))
+			}
+		}
+	}()
+	waitForEvent(t, stream, Idle)
+}
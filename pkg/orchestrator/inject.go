@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// InjectUserText handles text typed by the user as if it were a final
+// transcript: it interrupts any reply in progress, starts a turn, emits
+// TranscriptFinal, adds the user message and answers it with LLM and TTS.
+// A chat UI and the voice channel can share one stream this way.
+func (ms *ManagedStream) InjectUserText(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	ms.mu.Lock()
+	closed := ms.isClosed
+	ms.mu.Unlock()
+	if closed {
+		return ErrStreamClosed
+	}
+
+	ms.cancelScheduledOnActivity()
+	ms.resetIdle()
+	ms.internalInterrupt()
+
+	ms.mu.Lock()
+	turnID := ms.beginTurnLocked()
+	ms.turns[turnID].Transcript = text
+	// Latency runs from the message; nothing was transcribed or heard.
+	now := time.Now()
+	ms.userSpeechEndTime = now
+	ms.sttStartTime, ms.sttEndTime = now, now
+	ms.lastUserAudio = nil
+	ms.mu.Unlock()
+
+	ms.emitForTurn(TranscriptFinal, text, turnID)
+	text = ms.addUserMessage(text, "", turnID)
+	ms.spawn(func() { ms.runLLMAndTTS(ms.ctx, text) })
+	return nil
+}
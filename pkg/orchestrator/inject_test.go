@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInjectUserText_AnswersLikeATranscript(t *testing.T) {
+	ms := newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{completeResult: "It ships tomorrow."})
+
+	if err := ms.InjectUserText("  when does my order ship?  "); err != nil {
+		t.Fatal(err)
+	}
+	final := waitForEvent(t, ms, TranscriptFinal)
+	if final.Data != "when does my order ship?" {
+		t.Errorf("unexpected transcript %v", final.Data)
+	}
+	response := waitForEvent(t, ms, BotResponse)
+	if response.Data != "It ships tomorrow." || response.TurnID != final.TurnID {
+		t.Errorf("unexpected response %+v for turn %s", response, final.TurnID)
+	}
+	waitForEvent(t, ms, AudioChunk)
+
+	msgs := ms.session.GetContextCopy()
+	if len(msgs) != 2 || msgs[0].Role != "user" || msgs[0].Content != "when does my order ship?" {
+		t.Errorf("unexpected context %+v", msgs)
+	}
+	if turn, _ := ms.GetTurn(final.TurnID); turn.Transcript != "when does my order ship?" {
+		t.Errorf("unexpected turn %+v", turn)
+	}
+
+	ms.Close()
+	if err := ms.InjectUserText("hello?"); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("expected ErrStreamClosed, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// interruptedMarker ends a stored reply the caller cut off, so the LLM knows
+// the rest was never heard.
+const interruptedMarker = "…[interrupted]"
+
+// spokenCharsPerSecond estimates how much text a reply's audio covers while
+// synthesis is still running and its total length is unknown.
+const spokenCharsPerSecond = 15
+
+// InterruptedResponse is the Data of an Interrupted event that cut off a
+// reply: what was generated, and the part the caller heard.
+type InterruptedResponse struct {
+	TurnID    string `json:"turn_id"`
+	Generated string `json:"generated"`
+	Spoken    string `json:"spoken"`
+	PlayedMs  int64  `json:"played_ms"`
+}
+
+// speechProgress tracks the reply being spoken.
+type speechProgress struct {
+	turnID   string
+	heard    string // said before a resume, see resumeInterrupted
+	text     string
+	emitted  int64
+	first    time.Time
+	complete bool  // synthesis finished
+	acked    int64 // bytes the client acked, or -1 without acks
+	words    []wordMark
+}
+
+// wordMark is a word boundary the TTS reported for the reply.
+type wordMark struct {
+	words int // in the mark's text
+	end   time.Duration
+}
+
+// takeSpeechLocked returns the reply in progress and forgets it, so it is
+// reported once. A reply that stopped playing earlier is not in progress. It
+// must be called with ms.mu held.
+func (ms *ManagedStream) takeSpeechLocked(stillPlaying bool) speechProgress {
+	s := ms.speech
+	ms.speech = speechProgress{}
+	if !ms.isSpeaking && !stillPlaying {
+		if s.text != "" {
+			ms.spoken = joinSpoken(s.heard, s.text)
+		}
+		return speechProgress{}
+	}
+	return ms.withAckLocked(s)
+}
+
+// withAckLocked sets how much of s the client acked, if it acks playback.
+func (ms *ManagedStream) withAckLocked(s speechProgress) speechProgress {
+	s.acked = -1
+	if p := &ms.playback; p.enabled && p.turnID == s.turnID {
+		s.acked = p.played
+	}
+	return s
+}
+
+// GetLastSpokenText returns what the caller has heard of the last reply:
+// the words played so far while it is being spoken, the part before the
+// cut if they interrupted it, and all of it once it played in full. This
+// is the text kept in the LLM context for an interrupted reply, and what a
+// resumed reply continues from.
+func (ms *ManagedStream) GetLastSpokenText() string {
+	ms.mu.Lock()
+	s := ms.withAckLocked(ms.speech)
+	spoken := ms.spoken
+	ms.mu.Unlock()
+	if s.text == "" {
+		return spoken
+	}
+	if cut := ms.cutResponse(s); cut != nil {
+		return cut.Spoken
+	}
+	return joinSpoken(s.heard, s.text)
+}
+
+// cutResponse works out how much of an interrupted reply was heard. It
+// returns nil when nothing was being said or all of it was heard.
+func (ms *ManagedStream) cutResponse(s speechProgress) *InterruptedResponse {
+	if s.text == "" {
+		return nil
+	}
+	playbackRate, _ := ms.sampleRates()
+	bytesPerSecond := float64(playbackRate * 2)
+
+	played := s.acked
+	if played < 0 {
+		// Without acks, assume the client plays audio in real time.
+		played = s.emitted
+		if !s.first.IsZero() {
+			played = min(played, int64(time.Since(s.first).Seconds()*bytesPerSecond))
+		}
+	}
+	playedSeconds := float64(played) / bytesPerSecond
+
+	var fraction float64
+	switch {
+	case len(s.words) > 0:
+		// The TTS said when each word ended.
+		return cutAtWords(s, time.Duration(playedSeconds*float64(time.Second)))
+	case s.complete && s.emitted > 0:
+		fraction = float64(played) / float64(s.emitted)
+	default:
+		fraction = playedSeconds * spokenCharsPerSecond / float64(len([]rune(s.text)))
+	}
+	if fraction >= 1 {
+		return nil
+	}
+	return &InterruptedResponse{
+		TurnID:    s.turnID,
+		Generated: joinSpoken(s.heard, s.text),
+		Spoken:    joinSpoken(s.heard, spokenPrefix(s.text, fraction)),
+		PlayedMs:  int64(playedSeconds * 1000),
+	}
+}
+
+// cutAtWords cuts a reply after the last word that finished playing by
+// played.
+func cutAtWords(s speechProgress, played time.Duration) *InterruptedResponse {
+	n := 0
+	for _, w := range s.words {
+		if w.end > played {
+			break
+		}
+		n += w.words
+	}
+	words := strings.Fields(s.text)
+	if n >= len(words) {
+		return nil
+	}
+	return &InterruptedResponse{
+		TurnID:    s.turnID,
+		Generated: joinSpoken(s.heard, s.text),
+		Spoken:    joinSpoken(s.heard, strings.Join(words[:n], " ")),
+		PlayedMs:  played.Milliseconds(),
+	}
+}
+
+func joinSpoken(heard, text string) string {
+	return strings.TrimSpace(heard + " " + text)
+}
+
+func markInterrupted(spoken string) string {
+	return joinSpoken(spoken, interruptedMarker)
+}
+
+// spokenPrefix returns the words of text within the first fraction of it.
+func spokenPrefix(text string, fraction float64) string {
+	runes := []rune(text)
+	n := int(fraction * float64(len(runes)))
+	if n <= 0 {
+		return ""
+	}
+	// A word counts once it has been heard in full.
+	if n < len(runes) && !unicode.IsSpace(runes[n]) {
+		for n > 0 && !unicode.IsSpace(runes[n-1]) {
+			n--
+		}
+	}
+	return strings.TrimSpace(string(runes[:n]))
+}
+
+// replaceLastAssistant rewrites the last assistant message in the LLM
+// context if it still reads old.
+func (s *ConversationSession) replaceLastAssistant(old, content string) {
+	s.mu.Lock()
+	found := false
+	for i := len(s.Context) - 1; i >= 0; i-- {
+		if s.Context[i].Role != "assistant" {
+			continue
+		}
+		if s.Context[i].Content == old {
+			s.Context[i].Content = content
+			s.LastAssistant = content
+			found = true
+		}
+		break
+	}
+	s.mu.Unlock()
+	if found {
+		s.changed()
+	}
+}
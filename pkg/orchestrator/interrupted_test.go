@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tenWords = "one two three four five six seven eight nine ten"
+
+func interruptedStream(t *testing.T) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	// One second of audio at 44.1kHz.
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 88200)}, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("cut"))
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+func lastAssistant(ms *ManagedStream) string {
+	msgs := ms.Session().GetContextCopy()
+	return msgs[len(msgs)-1].Content
+}
+
+func TestInterrupt_AnnotatesHeardPart(t *testing.T) {
+	ms := interruptedStream(t)
+	ms.EnableClientPlayback()
+	ms.speakText(ms.ctx, tenWords)
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 44100})
+
+	ms.Interrupt()
+	cut, ok := waitForEvent(t, ms, Interrupted).Data.(InterruptedResponse)
+	if !ok {
+		t.Fatal("expected the interrupted reply in the event")
+	}
+	if cut.Generated != tenWords || cut.Spoken != "one two three four five" || cut.PlayedMs != 500 {
+		t.Errorf("unexpected split %+v", cut)
+	}
+	if got := lastAssistant(ms); got != "one two three four five …[interrupted]" {
+		t.Errorf("unexpected context message %q", got)
+	}
+}
+
+func TestInterrupt_BeforeAnythingPlayed(t *testing.T) {
+	ms := interruptedStream(t)
+	ms.speakText(ms.ctx, tenWords)
+
+	// Without acks, audio is assumed to play in real time.
+	ms.Interrupt()
+	cut, ok := waitForEvent(t, ms, Interrupted).Data.(InterruptedResponse)
+	if !ok || cut.Spoken != "" {
+		t.Fatalf("unexpected split %+v", cut)
+	}
+	if got := lastAssistant(ms); got != interruptedMarker {
+		t.Errorf("unexpected context message %q", got)
+	}
+}
+
+func TestInterrupt_AfterReplyHeard(t *testing.T) {
+	ms := interruptedStream(t)
+	ms.EnableClientPlayback()
+	ms.speakText(ms.ctx, tenWords)
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 88200})
+
+	ms.Interrupt()
+	if ev := waitForEvent(t, ms, Interrupted); ev.Data != nil {
+		t.Errorf("a reply heard in full is not cut off: %+v", ev.Data)
+	}
+	if got := lastAssistant(ms); got != tenWords {
+		t.Errorf("unexpected context message %q", got)
+	}
+}
+
+// drawlingTTS says "one two three" quickly and drags out "four": 400ms of
+// 16kHz audio with a word boundary for each word.
+type drawlingTTS struct {
+	MockTTSProvider
+}
+
+func (drawlingTTS) StreamSynthesizeMarks(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error, onMark func(SpeechMark) error) error {
+	if err := onChunk(make([]byte, 12800)); err != nil {
+		return err
+	}
+	for i, end := range []time.Duration{50, 100, 150, 380} {
+		start := time.Duration(i) * 50 * time.Millisecond
+		if err := onMark(SpeechMark{Word: strings.Fields(text)[i], Offset: start, Duration: end*time.Millisecond - start}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInterrupt_CutsAtWordBoundaries(t *testing.T) {
+	ms := prosodyStream(t, &drawlingTTS{}, "")
+	ms.EnableClientPlayback()
+	ms.speakText(ms.ctx, "one two three four")
+	// 200ms is half the audio but three of the four words.
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 6400})
+
+	ms.Interrupt()
+	cut, ok := waitForEvent(t, ms, Interrupted).Data.(InterruptedResponse)
+	if !ok || cut.Spoken != "one two three" || cut.PlayedMs != 200 {
+		t.Fatalf("unexpected split %+v", cut)
+	}
+	if got := lastAssistant(ms); got != "one two three …[interrupted]" {
+		t.Errorf("unexpected context message %q", got)
+	}
+}
+
+func TestGetLastSpokenText(t *testing.T) {
+	ms := interruptedStream(t)
+	if got := ms.GetLastSpokenText(); got != "" {
+		t.Errorf("nothing was said yet, got %q", got)
+	}
+	ms.EnableClientPlayback()
+	ms.speakText(ms.ctx, tenWords)
+	turnID := ms.TurnID()
+
+	ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: 17640})
+	if got := ms.GetLastSpokenText(); got != "one two" {
+		t.Errorf("expected the words heard so far, got %q", got)
+	}
+	ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: 44100})
+	ms.Interrupt()
+	if got := ms.GetLastSpokenText(); got != "one two three four five" {
+		t.Errorf("expected the part before the cut, got %q", got)
+	}
+	// Interrupting again changes nothing.
+	ms.Interrupt()
+	if got := ms.GetLastSpokenText(); got != "one two three four five" {
+		t.Errorf("a second interrupt changed the spoken text to %q", got)
+	}
+
+	ms.speakText(ms.ctx, "all done")
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 88200})
+	if got := ms.GetLastSpokenText(); got != "all done" {
+		t.Errorf("expected the reply heard in full, got %q", got)
+	}
+}
@@ -0,0 +1,257 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type StreamState string
+
+const (
+	StreamIdle       StreamState = "idle"
+	StreamListening  StreamState = "listening"
+	StreamThinking   StreamState = "thinking"
+	StreamSpeaking   StreamState = "speaking"
+	StreamClosed     StreamState = "closed"
+	StreamSupervised StreamState = "supervised"
+	StreamQueued     StreamState = "queued"
+	StreamPaused     StreamState = "paused"
+	StreamDormant    StreamState = "dormant"
+)
+
+type StreamStats struct {
+	AudioBytesIn       int64 `json:"audio_bytes_in"`
+	AudioChunksDropped int   `json:"audio_chunks_dropped"`
+	EventsEmitted      int   `json:"events_emitted"`
+	EventsDropped      int   `json:"events_dropped"`
+	Interruptions      int   `json:"interruptions"`
+	// STTAudioSkipped counts bytes kept from streaming STT by the STT gate.
+	STTAudioSkipped   int64 `json:"stt_audio_skipped"`
+	EchoChunks        int   `json:"echo_chunks"`
+	EchoChunksDropped int   `json:"echo_chunks_dropped"`
+	BargeInsArmed     int   `json:"barge_ins_armed"`
+	BargeInsRestored  int   `json:"barge_ins_restored"`
+	Backchannels      int   `json:"backchannels"`
+	// TranscriptsDropped counts finals the hallucination filter or the trust
+	// score rejected.
+	TranscriptsDropped int `json:"transcripts_dropped"`
+	// ProcessingTime is time spent on inbound audio (echo check, VAD): the
+	// per-stream CPU cost that grows with concurrency.
+	ProcessingTime time.Duration `json:"processing_time"`
+	Goroutines     int           `json:"goroutines"`
+
+	// SubscriberEventsDropped counts events a Subscribe channel had no room
+	// for.
+	SubscriberEventsDropped int `json:"subscriber_events_dropped"`
+
+	// EventOverflows counts events that found Events() full. Of the events
+	// dropped as a result, AudioEventsDropped were AudioChunks.
+	EventOverflows       int `json:"event_overflows"`
+	AudioEventsDropped   int `json:"audio_events_dropped"`
+	ControlEventsDropped int `json:"control_events_dropped"`
+
+	// LowPowerChunks counts chunks only the power saving energy gate saw.
+	LowPowerChunks int `json:"low_power_chunks"`
+
+	// GenerationsCanceled counts LLM calls a barge-in canceled.
+	GenerationsCanceled int `json:"generations_canceled"`
+
+	// Continuations counts utterances merged into the one before them.
+	Continuations int `json:"continuations"`
+}
+
+type StreamStatus struct {
+	SessionID string           `json:"session_id"`
+	State     StreamState      `json:"state"`
+	StartedAt time.Time        `json:"started_at"`
+	Uptime    time.Duration    `json:"uptime"`
+	Messages  int              `json:"messages"`
+	Stats     StreamStats      `json:"stats"`
+	Latency   LatencyBreakdown `json:"latency"`
+	Usage     SessionUsage     `json:"usage"`
+	Pace      PaceMetrics      `json:"pace"`
+	Priority  Priority         `json:"priority"`
+	Degraded  bool             `json:"degraded"`
+	MicMuted  bool             `json:"mic_muted,omitempty"`
+	LowPower  bool             `json:"low_power,omitempty"`
+}
+
+func (ms *ManagedStream) State() StreamState {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.stateLocked()
+}
+
+func (ms *ManagedStream) stateLocked() StreamState {
+	switch {
+	case ms.isClosed:
+		return StreamClosed
+	case ms.queued:
+		return StreamQueued
+	case ms.supervised:
+		return StreamSupervised
+	case ms.isSpeaking:
+		return StreamSpeaking
+	case ms.isThinking:
+		return StreamThinking
+	case ms.paused:
+		return StreamPaused
+	case ms.dormant:
+		return StreamDormant
+	case ms.userInterrupting || ms.userTurnOpen || ms.hearingLocked():
+		return StreamListening
+	}
+	return StreamIdle
+}
+
+// hearingLocked reports whether the caller is in the middle of an
+// utterance. The lead-in audio kept between utterances doesn't count.
+func (ms *ManagedStream) hearingLocked() bool {
+	if rmsVAD, ok := ms.vad.(*RMSVAD); ok {
+		return rmsVAD.IsSpeaking()
+	}
+	return ms.audioBuf.Len() > 0
+}
+
+func (ms *ManagedStream) SessionID() string {
+	return ms.session.ID
+}
+
+func (ms *ManagedStream) Session() *ConversationSession {
+	return ms.session
+}
+
+func (ms *ManagedStream) Status() StreamStatus {
+	ms.mu.Lock()
+	status := StreamStatus{
+		SessionID: ms.session.ID,
+		State:     ms.stateLocked(),
+		StartedAt: ms.startedAt,
+		Stats:     ms.stats,
+		MicMuted:  ms.micMuted,
+		LowPower:  ms.lowPower,
+	}
+	ms.mu.Unlock()
+	status.Stats.Goroutines = int(ms.goroutines.Load())
+
+	if !status.StartedAt.IsZero() {
+		status.Uptime = time.Since(status.StartedAt)
+	}
+	status.Messages = len(ms.session.GetContextCopy())
+	status.Latency = ms.GetLatencyBreakdown()
+	status.Usage = ms.session.GetUsage()
+	status.Pace = ms.session.GetPace()
+	status.Priority = ms.session.GetPriority()
+	if ms.orch != nil {
+		status.Degraded = ms.orch.isDegraded(ms.session)
+	}
+	return status
+}
+
+func (o *Orchestrator) registerStream(ms *ManagedStream) {
+	o.streamsMu.Lock()
+	defer o.streamsMu.Unlock()
+	if o.streams == nil {
+		o.streams = make(map[string]*ManagedStream)
+	}
+	o.streams[ms.session.ID] = ms
+}
+
+func (o *Orchestrator) unregisterStream(ms *ManagedStream) {
+	o.streamsMu.Lock()
+	defer o.streamsMu.Unlock()
+	// A newer stream may have taken over the session id.
+	if o.streams[ms.session.ID] == ms {
+		delete(o.streams, ms.session.ID)
+	}
+}
+
+// Streams returns the live managed streams ordered by session id.
+func (o *Orchestrator) Streams() []*ManagedStream {
+	o.streamsMu.Lock()
+	streams := make([]*ManagedStream, 0, len(o.streams))
+	for _, ms := range o.streams {
+		streams = append(streams, ms)
+	}
+	o.streamsMu.Unlock()
+
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].session.ID < streams[j].session.ID
+	})
+	return streams
+}
+
+func (o *Orchestrator) Stream(sessionID string) (*ManagedStream, bool) {
+	o.streamsMu.Lock()
+	defer o.streamsMu.Unlock()
+	ms, ok := o.streams[sessionID]
+	return ms, ok
+}
+
+// HealthChecker is implemented by providers that can report whether their
+// backend is reachable.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+type ProviderHealth struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (o *Orchestrator) CheckProviders(ctx context.Context) []ProviderHealth {
+	providers := []struct {
+		kind     string
+		provider interface{ Name() string }
+	}{
+		{"stt", o.stt},
+		{"llm", o.llm},
+		{"tts", o.tts},
+	}
+
+	var results []ProviderHealth
+	for _, p := range providers {
+		if p.provider == nil {
+			continue
+		}
+		h := ProviderHealth{Kind: p.kind, Name: p.provider.Name(), Status: "unknown"}
+		if checker, ok := p.provider.(HealthChecker); ok {
+			if err := checker.HealthCheck(ctx); err != nil {
+				h.Status = "unhealthy"
+				h.Error = err.Error()
+			} else {
+				h.Status = "ok"
+			}
+		}
+		results = append(results, h)
+	}
+	return results
+}
+
+type AudioHealth struct {
+	SampleRate int    `json:"sample_rate"`
+	VAD        string `json:"vad"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckAudio reports whether managed streams can detect turns: without a VAD
+// or a valid sample rate, audio is accepted but never answered.
+func (o *Orchestrator) CheckAudio() AudioHealth {
+	h := AudioHealth{SampleRate: o.GetConfig().SampleRate, Status: "ok"}
+	if o.vad != nil {
+		h.VAD = o.vad.Name()
+	}
+	switch {
+	case h.SampleRate <= 0:
+		h.Status = "unhealthy"
+		h.Error = "sample rate is not configured"
+	case o.vad == nil:
+		h.Status = "unhealthy"
+		h.Error = "no VAD configured"
+	}
+	return h
+}
@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type healthyTTS struct {
+	MockTTSProvider
+	err error
+}
+
+func (h *healthyTTS) HealthCheck(ctx context.Context) error { return h.err }
+
+func TestOrchestrator_StreamRegistry(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	a := orch.NewManagedStream(context.Background(), NewConversationSession("b-session"))
+	b := orch.NewManagedStream(context.Background(), NewConversationSession("a-session"))
+
+	streams := orch.Streams()
+	if len(streams) != 2 || streams[0] != b || streams[1] != a {
+		t.Fatalf("expected both streams ordered by session id, got %d", len(streams))
+	}
+
+	a.Close()
+	if _, ok := orch.Stream("b-session"); ok {
+		t.Error("closed stream must be unregistered")
+	}
+	if ms, ok := orch.Stream("a-session"); !ok || ms != b {
+		t.Error("expected remaining stream to be found")
+	}
+	b.Close()
+}
+
+func TestManagedStream_Status(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	session := NewConversationSession("status")
+	session.AddMessage("user", "hi")
+	stream := orch.NewManagedStream(context.Background(), session)
+
+	status := stream.Status()
+	if status.SessionID != "status" || status.State != StreamIdle || status.Messages != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	stream.mu.Lock()
+	stream.isThinking = true
+	stream.mu.Unlock()
+	if stream.State() != StreamThinking {
+		t.Errorf("expected thinking, got %s", stream.State())
+	}
+
+	stream.Close()
+	if stream.State() != StreamClosed {
+		t.Errorf("expected closed, got %s", stream.State())
+	}
+	if stream.Status().Stats.Interruptions != 1 {
+		t.Errorf("expected close to interrupt the pending response")
+	}
+}
+
+func TestOrchestrator_CheckProviders(t *testing.T) {
+	tts := &healthyTTS{err: errors.New("connection refused")}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, Config{})
+
+	results := orch.CheckProviders(context.Background())
+	if len(results) != 3 {
+		t.Fatalf("expected 3 providers, got %d", len(results))
+	}
+	if results[0].Status != "unknown" {
+		t.Errorf("providers without health checks should be unknown, got %s", results[0].Status)
+	}
+	if results[2].Status != "unhealthy" || results[2].Error != "connection refused" {
+		t.Errorf("unexpected tts health: %+v", results[2])
+	}
+
+	tts.err = nil
+	if orch.CheckProviders(context.Background())[2].Status != "ok" {
+		t.Error("expected healthy tts")
+	}
+}
+
+func TestOrchestrator_CheckAudio(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, DefaultConfig())
+	if h := orch.CheckAudio(); h.Status != "unhealthy" || h.Error != "no VAD configured" {
+		t.Errorf("expected missing VAD to be unhealthy, got %+v", h)
+	}
+
+	orch = NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 0), DefaultConfig())
+	if h := orch.CheckAudio(); h.Status != "ok" || h.VAD == "" || h.SampleRate != DefaultConfig().SampleRate {
+		t.Errorf("unexpected audio health: %+v", h)
+	}
+
+	orch = NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 0), Config{})
+	if h := orch.CheckAudio(); h.Status != "unhealthy" {
+		t.Errorf("expected zero sample rate to be unhealthy, got %+v", h)
+	}
+}
@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"context"
+	"slices"
+)
+
+// supportedLanguages are the languages sessions follow by default.
+var supportedLanguages = []Language{LanguageEn, LanguageEs, LanguageFr, LanguageDe, LanguageIt, LanguagePt, LanguageJa, LanguageZh}
+
+// LanguageDetector names the language of an utterance, e.g. a wrapper around
+// a language identification model. It runs before the reply, so it should
+// be fast.
+type LanguageDetector interface {
+	DetectLanguage(ctx context.Context, pcm []byte, sampleRate int, transcript string) (Language, error)
+}
+
+// LanguageDetectionConfig makes the session's CurrentLanguage, and so the
+// language of the STT and of the spoken reply, follow the caller. The
+// language of each final transcript comes from Detector when set. Otherwise
+// it comes from the STT: utterances are transcribed without a fixed language
+// and the provider's detected TranscriptionResult.Language is used, which
+// Whisper-based providers report. Streaming STT providers need a Detector.
+// Only Languages (every supported one when nil) are switched to, and
+// Voices picks the TTS voice for a language.
+type LanguageDetectionConfig struct {
+	Enabled   bool
+	Detector  LanguageDetector
+	Languages []Language
+	Voices    map[Language]Voice
+}
+
+// LanguageChange is the data of a LanguageChanged event.
+type LanguageChange struct {
+	From Language `json:"from"`
+	To   Language `json:"to"`
+}
+
+func (c LanguageDetectionConfig) allows(lang Language) bool {
+	languages := c.Languages
+	if languages == nil {
+		languages = supportedLanguages
+	}
+	return slices.Contains(languages, lang)
+}
+
+// SetLanguage switches the session to lang, and to voice unless it is empty.
+func (s *ConversationSession) SetLanguage(lang Language, voice Voice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CurrentLanguage = lang
+	if voice != "" {
+		s.CurrentVoice = voice
+	}
+}
+
+// sttLanguage is the language utterances are transcribed in: none while the
+// STT detects it.
+func (ms *ManagedStream) sttLanguage() Language {
+	if cfg := ms.orch.GetConfig().LanguageDetection; cfg.Enabled && cfg.Detector == nil {
+		return ""
+	}
+	return ms.session.InputLanguage()
+}
+
+// followLanguage switches the session to the language of a final transcript,
+// detected is what the STT reported, if anything.
+func (ms *ManagedStream) followLanguage(transcript string, detected Language, turnID string) {
+	cfg := ms.orch.GetConfig().LanguageDetection
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.Detector != nil {
+		_, inputRate := ms.sampleRates()
+		lang, err := cfg.Detector.DetectLanguage(ms.ctx, ms.lastUtterance(), inputRate, transcript)
+		if err != nil {
+			ms.orch.logger.Warn("language detection failed", "sessionID", ms.session.ID, "error", err)
+			return
+		}
+		detected = lang
+	}
+
+	from := ms.session.GetCurrentLanguage()
+	if detected == "" || detected == from || !cfg.allows(detected) {
+		return
+	}
+	ms.session.SetLanguage(detected, cfg.Voices[detected])
+	ms.emitForTurn(LanguageChanged, LanguageChange{From: from, To: detected}, turnID)
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// detectingSTT reports every utterance as Spanish and records the language
+// it was asked for.
+type detectingSTT struct {
+	MockSTTProvider
+	mu    sync.Mutex
+	asked []Language
+}
+
+func (s *detectingSTT) TranscribeDetailed(ctx context.Context, audio []byte, lang Language) (*TranscriptionResult, error) {
+	s.mu.Lock()
+	s.asked = append(s.asked, lang)
+	s.mu.Unlock()
+	return &TranscriptionResult{Text: s.transcribeResult, Language: LanguageEs}, nil
+}
+
+type fixedLanguage Language
+
+func (l fixedLanguage) DetectLanguage(ctx context.Context, pcm []byte, sampleRate int, transcript string) (Language, error) {
+	return Language(l), nil
+}
+
+func newLanguageStream(t *testing.T, stt STTProvider, detection LanguageDetectionConfig) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.LanguageDetection = detection
+	return newTestStream(t, cfg, stt, &MockLLMProvider{completeResult: "¡Claro!"})
+}
+
+func TestLanguageDetection_FollowsSTT(t *testing.T) {
+	stt := &detectingSTT{MockSTTProvider: MockSTTProvider{transcribeResult: "hola, ¿me ayudas?"}}
+	ms := newLanguageStream(t, stt, LanguageDetectionConfig{Enabled: true, Voices: map[Language]Voice{LanguageEs: VoiceM2}})
+
+	speakTo(ms)
+	ev := waitForEvent(t, ms, LanguageChanged)
+	if change := ev.Data.(LanguageChange); change.From != LanguageEn || change.To != LanguageEs {
+		t.Errorf("unexpected change %+v", change)
+	}
+	waitForEvent(t, ms, TranscriptFinal)
+	if lang, voice := ms.session.GetCurrentLanguage(), ms.session.GetCurrentVoice(); lang != LanguageEs || voice != VoiceM2 {
+		t.Errorf("expected es with M2, got %s with %s", lang, voice)
+	}
+
+	stt.mu.Lock()
+	defer stt.mu.Unlock()
+	if len(stt.asked) != 1 || stt.asked[0] != "" {
+		t.Errorf("expected transcription without a fixed language, got %q", stt.asked)
+	}
+}
+
+func TestLanguageDetection_Detector(t *testing.T) {
+	stt := &detectingSTT{MockSTTProvider: MockSTTProvider{transcribeResult: "bonjour"}}
+	ms := newLanguageStream(t, stt, LanguageDetectionConfig{Enabled: true, Detector: fixedLanguage(LanguageFr)})
+
+	speakTo(ms)
+	if ev := waitForEvent(t, ms, LanguageChanged); ev.Data.(LanguageChange).To != LanguageFr {
+		t.Errorf("expected the detector to win, got %+v", ev.Data)
+	}
+	if voice := ms.session.GetCurrentVoice(); voice != VoiceF1 {
+		t.Errorf("expected the voice to stay, got %s", voice)
+	}
+
+	stt.mu.Lock()
+	defer stt.mu.Unlock()
+	if len(stt.asked) != 1 || stt.asked[0] != LanguageEn {
+		t.Errorf("expected transcription in the session's language, got %q", stt.asked)
+	}
+}
+
+func TestLanguageDetection_OnlyAllowedLanguages(t *testing.T) {
+	stt := &detectingSTT{MockSTTProvider: MockSTTProvider{transcribeResult: "hola"}}
+	ms := newLanguageStream(t, stt, LanguageDetectionConfig{Enabled: true, Languages: []Language{LanguageEn, LanguageFr}})
+
+	speakTo(ms)
+	deadline := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == LanguageChanged {
+				t.Fatalf("unexpected change %+v", ev.Data)
+			}
+			done = ev.Type == BotResponse
+		case <-deadline:
+			t.Fatal("timed out waiting for BOT_RESPONSE")
+		}
+	}
+	if lang := ms.session.GetCurrentLanguage(); lang != LanguageEn {
+		t.Errorf("expected to stay in en, got %s", lang)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/agc"
 )
 
 type ManagedStream struct {
@@ -24,6 +27,7 @@ type ManagedStream struct {
 	pipelineCancel    context.CancelFunc
 	sttChan           chan<- []byte
 	sttGeneration     int
+	sttDeferred       bool // detected speech is waiting on the STT gate
 	isSpeaking        bool
 	isThinking        bool
 	lastInterruptedAt time.Time
@@ -33,6 +37,11 @@ type ManagedStream struct {
 
 	lastUserAudio []byte
 
+	// inputAlign and playedAlign keep caller and played audio aligned to
+	// whole samples across writes.
+	inputAlign  sampleAligner
+	playedAlign sampleAligner
+
 	sttStartTime      time.Time
 	sttEndTime        time.Time
 	llmStartTime      time.Time
@@ -43,17 +52,96 @@ type ManagedStream struct {
 
 	responseCancel     context.CancelFunc
 	ttsCancel          context.CancelFunc
+	speakSeq           int // numbers speakResponseAfter calls
 	userInterrupting   bool
 	echoSuppressor     *EchoSuppressor
+	echoProcessor      EchoProcessor // nil means correlation via echoSuppressor
+	echoConfig         *EchoConfig
+	recorder           *Recorder
+	sinks              []EventSink
+	playback           playbackState
+	checkpointSeq      int
+	lastCheckpoint     []byte
 	lastAudioEmittedAt time.Time
 	closeOnce          sync.Once
 
+	filler      *fillerPlayback
+	fillerCache map[string][]byte
+
+	idleTimeouts int
+
+	wakeWord WakeWordDetector
+	dormant  bool
+	wokeAt   time.Time
+
+	commandTurn string // the turn whose command was carried out
+
+	lowPower      bool
+	powerActiveAt time.Time
+	powerLoud     time.Duration
+	powerPreroll  [][]byte
+
+	speaker       string
+	speakerResult *SpeakerResult
+	lastSpeaker   string // who said the last diarized transcript
+
 	payloadGen int
+	turnID     string
+	turnSeq    int
+	turns      map[string]*Turn
+	turnOrder  []string
 	writeChan  chan []byte
 	isClosed   bool
+
+	timers   map[string]*scheduledEntry
+	timerSeq int
+
+	startedAt time.Time
+	stats     StreamStats
+	monitors  map[*streamMonitor]struct{}
+
+	subscribers map[<-chan OrchestratorEvent]*subscriber
+
+	supervised bool
+
+	ducked    bool // a two-stage barge-in is waiting for STT
+	duckSeq   int
+	duckTimer *time.Timer
+
+	playbackRate int
+	inputRate    int
+
+	// queued is set while waiting for a capacity slot; holdsSlot once one
+	// has to be released on Close.
+	queued     bool
+	holdsSlot  bool
+	goroutines atomic.Int32
+
+	paused       bool
+	micMuted     bool
+	userTurnOpen bool // push-to-talk
+	speech       speechProgress
+	resumable    *resumeState
+	partial      string             // latest partial transcript
+	holdCancel   context.CancelFunc // ends an endpoint hold
+	tuning       Tuning             // set by Tune
+	spoken       string             // heard of the last reply once it stopped
+	thinkingOn   string             // transcript the LLM is answering
+	unanswered   string             // transcript whose reply a barge-in canceled
+	utterance    *utterance         // the last, while it can be continued
+	continued    *utterance         // the one the current utterance continues
+
+	agc     *agc.AGC
+	agcRate int
+
+	eventOverflow EventOverflow
 }
 
 func NewManagedStream(ctx context.Context, o *Orchestrator, session *ConversationSession) *ManagedStream {
+	return newManagedStream(ctx, o, session, true)
+}
+
+func newManagedStream(ctx context.Context, o *Orchestrator, session *ConversationSession, greet bool) *ManagedStream {
 	mCtx, mCancel := context.WithCancel(ctx)
 
 	var streamVAD VADProvider
@@ -71,25 +159,91 @@ func NewManagedStream(ctx context.Context, o *Orchestrator, session *Conversatio
 		session:        session,
 		ctx:            mCtx,
 		cancel:         mCancel,
-		events:         make(chan OrchestratorEvent, 1024),
+		events:         make(chan OrchestratorEvent, config.Events.capacity()),
+		eventOverflow:  config.Events.EventOverflow,
 		audioBuf:       new(bytes.Buffer),
 		vad:            streamVAD,
 		echoSuppressor: NewEchoSuppressorWithConfig(config),
 		writeChan:      make(chan []byte, 1024),
+		startedAt:      time.Now(),
+	}
+	if config.WakeWord.Detector != nil {
+		ms.wakeWord = config.WakeWord.Detector.Clone()
+		ms.dormant = true
+	}
+	if config.PowerSave.Enabled {
+		ms.lowPower = true
+	}
+	if config.Echo.Strategy != "" && config.Echo.Strategy != EchoStrategyCorrelation {
+		ms.echoProcessor = NewEchoProcessor(config.Echo.Strategy, config)
+	}
+	if o != nil {
+		ms.recorder = o.newRecorder(session, config)
+		ms.sinks = config.EventSinks
+		o.registerStream(ms)
 	}
 
-	go ms.processBackgroundAudio()
+	ms.spawn(ms.processBackgroundAudio)
+	if interval := config.CheckpointEvents.Interval; interval > 0 {
+		ms.spawn(func() { ms.runCheckpoints(interval) })
+	}
+	if idle := config.Idle; idle.Enabled && idle.Timeout > 0 {
+		ms.spawn(func() { ms.watchIdle(idle) })
+	}
 
-	if o != nil && o.config.FirstSpeaker == FirstSpeakerBot {
-		go func() {
-			time.Sleep(500 * time.Millisecond) // Give audio some time to stabilize
-			ms.runLLMAndTTS(ms.ctx, "Hello!")  // Trigger initial greeting
-		}()
+	if o == nil {
+		return ms
+	}
+	w, position, err := o.reserveSlot(session.GetPriority())
+	switch {
+	case err != nil:
+		ms.emit(ServerBusy, o.capacityInfo(ServerBusyRejected, 0))
+		ms.Close()
+	case w != nil:
+		ms.mu.Lock()
+		ms.queued = true
+		ms.mu.Unlock()
+		ms.emit(ServerBusy, o.capacityInfo(ServerBusyQueued, position))
+		ms.spawn(func() { ms.awaitSlot(w, o.GetConfig().Capacity.QueueTimeout, greet) })
+	default:
+		ms.mu.Lock()
+		ms.holdsSlot = true
+		ms.mu.Unlock()
+		if greet {
+			ms.greet()
+		}
 	}
 
 	return ms
 }
 
+func (ms *ManagedStream) greet() {
+	cfg := ms.orch.GetConfig()
+	disclose := len(cfg.Compliance.at(DisclosureCallStart)) > 0
+	if cfg.FirstSpeaker != FirstSpeakerBot && !disclose {
+		return
+	}
+	ms.spawn(func() {
+		time.Sleep(500 * time.Millisecond) // Give audio some time to stabilize
+		if disclose && ms.Disclose(ms.ctx, DisclosureCallStart) != nil {
+			// The caller spoke up and is answered instead.
+			return
+		}
+		if cfg.FirstSpeaker == FirstSpeakerBot {
+			ms.runLLMAndTTS(ms.ctx, "Hello!") // Trigger initial greeting
+		}
+	})
+}
+
+// spawn runs fn on a goroutine counted against this stream.
+func (ms *ManagedStream) spawn(fn func()) {
+	ms.goroutines.Add(1)
+	go func() {
+		defer ms.goroutines.Add(-1)
+		fn()
+	}()
+}
+
 func (ms *ManagedStream) processBackgroundAudio() {
 	for {
 		select {
@@ -122,16 +276,47 @@ func (ms *ManagedStream) IsUserSpeaking() bool {
 }
 
 func (ms *ManagedStream) SetEchoSampleRates(playbackRate, inputRate int) {
-	if ms.echoSuppressor != nil {
-		ms.echoSuppressor.SetSampleRates(playbackRate, inputRate)
+	ms.mu.Lock()
+	ms.playbackRate = playbackRate
+	ms.inputRate = inputRate
+	ms.mu.Unlock()
+	if p, ok := ms.activeEcho().(echoRateSetter); ok {
+		p.SetSampleRates(playbackRate, inputRate)
+	}
+	if ms.recorder != nil {
+		ms.recorder.SetSampleRates(playbackRate, inputRate)
 	}
 }
 
+// sampleRates returns the rates of bot and caller audio, which default to
+// Config.SampleRate unless set with SetEchoSampleRates.
+func (ms *ManagedStream) sampleRates() (playbackRate, inputRate int) {
+	ms.mu.Lock()
+	playbackRate, inputRate = ms.playbackRate, ms.inputRate
+	ms.mu.Unlock()
+	rate := DefaultConfig().SampleRate
+	if ms.orch != nil && ms.orch.GetConfig().SampleRate > 0 {
+		rate = ms.orch.GetConfig().SampleRate
+	}
+	if playbackRate <= 0 {
+		playbackRate = rate
+	}
+	if inputRate <= 0 {
+		inputRate = rate
+	}
+	return playbackRate, inputRate
+}
+
 func (ms *ManagedStream) Interrupt() {
+	ms.cancelScheduledOnActivity()
 	ms.mu.Lock()
 	ms.userInterrupting = true
 	ms.mu.Unlock()
 	ms.internalInterrupt()
+	// The application stopped the reply on purpose.
+	ms.mu.Lock()
+	ms.resumable = nil
+	ms.mu.Unlock()
 }
 
 func countWords(s string) int {
@@ -144,12 +329,25 @@ func countWords(s string) int {
 
 const speechEndHold = 150 * time.Millisecond
 
+// maxUserAudioSeconds bounds lastUserAudio, which keeps growing with the
+// silence after an utterance until the next one starts.
+const maxUserAudioSeconds = 30
+
+// Write queues a chunk of the caller's 16-bit PCM. Chunks need not hold whole
+// samples: an odd trailing byte is joined to the next chunk. Empty chunks are
+// ignored.
 func (ms *ManagedStream) Write(chunk []byte) error {
+	if len(chunk) == 0 {
+		return nil
+	}
 	select {
 	case ms.writeChan <- chunk:
 		return nil
 	default:
 		// Channel full, drop audio or log warning
+		ms.mu.Lock()
+		ms.stats.AudioChunksDropped++
+		ms.mu.Unlock()
 		return nil
 	}
 }
@@ -160,18 +358,67 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 		ms.mu.Unlock()
 		return ms.ctx.Err()
 	}
+	// Align before anything is dropped, so what follows stays aligned.
+	if chunk = ms.inputAlign.align(chunk); len(chunk) == 0 {
+		ms.mu.Unlock()
+		return nil
+	}
+	if ms.queued || ms.paused {
+		ms.stats.AudioChunksDropped++
+		ms.mu.Unlock()
+		return nil
+	}
+	if ms.micMuted {
+		// A muted mic still produces (silent) audio, so an utterance in
+		// progress ends normally.
+		chunk = make([]byte, len(chunk))
+	}
+	start := time.Now()
+	defer func() {
+		ms.mu.Lock()
+		ms.stats.ProcessingTime += time.Since(start)
+		ms.mu.Unlock()
+	}()
+	ms.stats.AudioBytesIn += int64(len(chunk))
+	if ms.recorder != nil {
+		ms.recorder.WriteInbound(chunk)
+	}
+	if len(ms.monitors) > 0 {
+		ms.publishLocked(MonitorFrame{Kind: MonitorUserAudio, Audio: append([]byte(nil), chunk...)})
+	}
+	if ms.supervised {
+		// A human has the call; the caller is only bridged to monitors.
+		ms.mu.Unlock()
+		return nil
+	}
 	ms.mu.Unlock()
 
+	if ms.writeDormant(chunk) {
+		return nil
+	}
+	if ms.pushToTalk() {
+		return ms.writePushToTalk(chunk)
+	}
+	if saving, err := ms.writeLowPower(chunk); saving {
+		return err
+	}
+	return ms.listen(chunk)
+}
+
+// listen runs chunk through echo processing and the VAD, and on to the STT
+// while the caller talks.
+func (ms *ManagedStream) listen(chunk []byte) error {
 	if ms.vad == nil {
 		return fmt.Errorf("VAD not configured for this stream")
 	}
+	_, inputRate := ms.sampleRates()
+	bytesPerSecond := inputRate * 2
 
 	vadTrailWindow := 1500 * time.Millisecond
-	vadThreshold := 0.0
 	if ms.orch != nil {
 		vadTrailWindow = ms.orch.GetConfig().BargeInVADTrailWindow
-		vadThreshold = ms.orch.GetConfig().BargeInVADThreshold
 	}
+	vadThreshold, minConfirmed := ms.vadTuning()
 
 	if rmsVAD, ok := ms.vad.(*RMSVAD); ok {
 		originalThreshold := rmsVAD.Threshold()
@@ -192,12 +439,12 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 				target = vadThreshold
 			}
 			rmsVAD.SetThreshold(target)
-			rmsVAD.SetMinConfirmed(2)
+			rmsVAD.SetMinConfirmed(minConfirmed)
 			rmsVAD.SetAdaptiveMode(false)
 		} else {
 			// When idle, we use the base sensitivity (0.005).
 			rmsVAD.SetThreshold(vadThreshold)
-			rmsVAD.SetMinConfirmed(2)
+			rmsVAD.SetMinConfirmed(minConfirmed)
 			rmsVAD.SetAdaptiveMode(true)
 		}
 
@@ -208,141 +455,197 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 		}()
 	}
 
-	// Restore passive echo detection solely for debugging/logging purposes.
-	// It does NOT gate VAD events anymore.
+	// Echo detection never gates VAD events; Config.Echo decides whether
+	// echo still reaches STT.
 	isEcho := false
-	if ms.echoSuppressor != nil {
+	if echo := ms.activeEcho(); echo != nil {
+		leadBytes := bytesPerSecond / 10
 		ms.mu.Lock()
 		lead := ms.audioBuf.Bytes()
-		ms.mu.Unlock()
-
-		leadBytes := 8820
 		if len(lead) > leadBytes {
 			lead = lead[len(lead)-leadBytes:]
 		}
-		checkBuf := make([]byte, 0, len(lead)+len(chunk))
-		checkBuf = append(checkBuf, lead...)
-		checkBuf = append(checkBuf, chunk...)
+		lead = append([]byte(nil), lead...)
+		ms.mu.Unlock()
 
-		if ms.echoSuppressor.IsEchoFast(checkBuf) {
-			isEcho = true
-		}
+		chunk, isEcho = echo.Process(chunk, lead)
 	}
+	chunk = ms.levelInput(chunk, isEcho)
 
-	event, err := ms.vad.Process(chunk)
+	dropEcho := ms.classifyChunk(chunk, isEcho)
+	gated := ms.sttGated(chunk, dropEcho)
+
+	events, err := ms.vadEvents(chunk)
 	if err != nil {
 		return err
 	}
+	for _, event := range events {
+		ms.handleVADEvent(event, isEcho, gated)
+	}
+	ended := len(events) > 0 && events[len(events)-1].Type == VADSpeechEnd
 
-	if event != nil && event.Type != VADSilence {
-
-		switch event.Type {
-		case VADSpeechStart:
-			if !isEcho {
-				ms.internalInterrupt()
-			}
-			ms.emit(UserSpeaking, nil)
-
-			ms.mu.Lock()
-			ms.sttGeneration++
-			pipelineCancel := ms.pipelineCancel
-			sttChan := ms.sttChan
-			ms.pipelineCancel = nil
-			ms.sttChan = nil
-
-			ms.sttStartTime = time.Now()
-			ms.sttEndTime = time.Time{}
-			ms.llmStartTime = time.Time{}
-			ms.llmEndTime = time.Time{}
-			ms.ttsStartTime = time.Time{}
-			ms.ttsFirstChunkTime = time.Time{}
-			ms.ttsEndTime = time.Time{}
-			ms.lastUserAudio = nil
-			ms.mu.Unlock()
-
-			if pipelineCancel != nil {
-				pipelineCancel()
-			}
-			if sttChan != nil {
-				close(sttChan)
-			}
-
-			if sProvider, ok := ms.orch.stt.(StreamingSTTProvider); ok {
-				ms.startStreamingSTT(sProvider)
-			}
-		case VADSpeechEnd:
-			ms.mu.Lock()
-			ms.userSpeechEndTime = time.Now()
-			ms.mu.Unlock()
-			ms.emit(UserStopped, nil)
-
-			ms.mu.Lock()
-			sttChan := ms.sttChan
-			if sttChan != nil {
-				ms.sttChan = nil
-				ms.mu.Unlock()
-				close(sttChan)
-			} else {
-				audioData := make([]byte, ms.audioBuf.Len())
-				copy(audioData, ms.audioBuf.Bytes())
-				ms.audioBuf.Reset()
-				ms.mu.Unlock()
-
-				go func(buf []byte) {
-					t := time.NewTimer(speechEndHold)
-					defer t.Stop()
-
-					select {
-					case <-t.C:
-						if rmsVAD, ok := ms.vad.(*RMSVAD); ok {
-							if rmsVAD.IsSpeaking() {
-								ms.mu.Lock()
-								ms.audioBuf.Write(buf)
-								ms.mu.Unlock()
-								return
-							}
-						}
-						ms.runBatchPipeline(buf)
-					case <-ms.ctx.Done():
-						return
-					}
-				}(audioData)
-			}
-
-		case VADSilence:
+	ms.mu.Lock()
+	release := ms.sttDeferred && !gated && !ended
+	if release {
+		ms.sttDeferred = false
+	}
+	ms.mu.Unlock()
+	if release {
+		// The caller is talking over the bot: this is the barge-in.
+		if !ms.armBargeIn() {
+			ms.internalInterrupt()
+		}
+		ms.beginTurn()
+		if sProvider, ok := ms.orch.stt.(StreamingSTTProvider); ok {
+			ms.startStreamingSTT(sProvider)
 		}
 	}
 
+	if dropEcho {
+		// The VAD has seen it for turn timing, but it is not user audio.
+		return nil
+	}
+
 	isUserSpeaking := false
 	if rmsVAD, ok := ms.vad.(*RMSVAD); ok {
 		isUserSpeaking = rmsVAD.IsSpeaking()
 	}
 
 	ms.mu.Lock()
+	ms.recordGapLocked(chunk)
 	ms.audioBuf.Write(chunk)
-	if !isUserSpeaking && ms.audioBuf.Len() > 176400 {
+	if !isUserSpeaking && ms.audioBuf.Len() > 2*bytesPerSecond {
 		data := ms.audioBuf.Bytes()
-		leadIn := data[len(data)-132300:]
+		leadIn := data[len(data)-bytesPerSecond*3/2:]
 		ms.audioBuf.Reset()
 		ms.audioBuf.Write(leadIn)
 	}
 	ms.mu.Unlock()
 
 	ms.mu.Lock()
-	sttChan := ms.sttChan
 	ms.lastUserAudio = append(ms.lastUserAudio, chunk...)
-	ms.mu.Unlock()
-
-	if sttChan != nil {
+	if over := len(ms.lastUserAudio) - maxUserAudioSeconds*bytesPerSecond; over > 0 {
+		ms.lastUserAudio = ms.lastUserAudio[over:]
+	}
+	if ms.sttDeferred {
+		ms.stats.STTAudioSkipped += int64(len(chunk))
+	}
+	if ms.sttChan != nil {
 		select {
-		case sttChan <- chunk:
+		case ms.sttChan <- chunk:
 		default:
 		}
 	}
+	ms.mu.Unlock()
 
 	return nil
 }
 
+// startUtterance resets per-utterance state when the caller starts talking
+// and opens a streaming STT session unless gated.
+func (ms *ManagedStream) startUtterance(gated bool) {
+	ms.emit(UserSpeaking, nil)
+	ms.cancelScheduledOnActivity()
+	ms.resetIdle()
+
+	ms.mu.Lock()
+	ms.sttGeneration++
+	pipelineCancel := ms.pipelineCancel
+	sttChan := ms.sttChan
+	ms.pipelineCancel = nil
+	ms.sttChan = nil
+
+	ms.sttStartTime = time.Now()
+	ms.sttEndTime = time.Time{}
+	ms.llmStartTime = time.Time{}
+	ms.llmEndTime = time.Time{}
+	ms.ttsStartTime = time.Time{}
+	ms.ttsFirstChunkTime = time.Time{}
+	ms.ttsEndTime = time.Time{}
+	ms.lastUserAudio = nil
+	ms.partial = ""
+	ms.sttDeferred = gated
+	ms.mu.Unlock()
+
+	if pipelineCancel != nil {
+		pipelineCancel()
+	}
+	if sttChan != nil {
+		close(sttChan)
+	}
+
+	if sProvider, ok := ms.orch.stt.(StreamingSTTProvider); ok && !gated {
+		ms.startStreamingSTT(sProvider)
+	}
+}
+
+// endUtterance finalizes the caller's utterance. With confirm, batch STT
+// waits for speechEndHold in case the VAD hears the caller resume.
+func (ms *ManagedStream) endUtterance(confirm bool) {
+	endpointing := confirm && ms.orch != nil && ms.endpointing().Enabled
+
+	ms.mu.Lock()
+	ms.userSpeechEndTime = time.Now()
+	ms.mu.Unlock()
+	ms.emit(UserStopped, nil)
+
+	ms.mu.Lock()
+	sttChan := ms.sttChan
+	if ms.sttDeferred {
+		// The gate never opened: the whole utterance was echo or
+		// too quiet to be a barge-in.
+		ms.sttDeferred = false
+		ms.audioBuf.Reset()
+		ms.mu.Unlock()
+	} else if sttChan != nil && endpointing {
+		partial := ms.partial
+		ms.rememberUtteranceLocked(ms.lastUserAudio)
+		ms.mu.Unlock()
+		ms.holdEndpoint(partial)
+	} else if sttChan != nil {
+		ms.sttChan = nil
+		ms.rememberUtteranceLocked(ms.lastUserAudio)
+		ms.mu.Unlock()
+		close(sttChan)
+	} else {
+		audioData := make([]byte, ms.audioBuf.Len())
+		copy(audioData, ms.audioBuf.Bytes())
+		ms.audioBuf.Reset()
+		ms.rememberUtteranceLocked(audioData)
+		u := ms.utterance
+		ms.mu.Unlock()
+		if len(audioData) == 0 {
+			return
+		}
+
+		ms.spawn(func() {
+			buf := audioData
+			if confirm {
+				t := time.NewTimer(speechEndHold)
+				defer t.Stop()
+
+				select {
+				case <-t.C:
+					if rmsVAD, ok := ms.vad.(*RMSVAD); ok {
+						if rmsVAD.IsSpeaking() {
+							ms.mu.Lock()
+							// A continuation already put it back.
+							if u == nil || !u.continued {
+								ms.audioBuf.Write(buf)
+							}
+							ms.mu.Unlock()
+							return
+						}
+					}
+				case <-ms.ctx.Done():
+					return
+				}
+			}
+			ms.runBatchPipeline(buf)
+		})
+	}
+}
+
 func isLikelyNoise(transcript string, audioDuration time.Duration) bool {
 	t := strings.TrimSpace(transcript)
 	if t == "" {
@@ -372,19 +675,39 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 
 	ms.mu.Lock()
 	currentGeneration := ms.sttGeneration
+	turnID := ms.turnID
 	ms.mu.Unlock()
 
-	sttChan, err := provider.StreamTranscribe(ctx, ms.session.GetCurrentLanguage(), func(transcript string, isFinal bool) error {
+	onTranscript := func(transcript string, isFinal bool, speaker string) error {
 		ms.mu.Lock()
 		speaking := ms.isSpeaking
 		thinking := ms.isThinking
 		isStale := ms.sttGeneration != currentGeneration
+		if !isStale && !isFinal {
+			ms.partial = transcript
+		}
 		ms.mu.Unlock()
 
 		if isStale {
 			return nil
 		}
 
+		if isFinal && ms.hallucinated(transcript, turnID) {
+			ms.falseBargeIn()
+			return nil
+		}
+		if ms.isBackchannel(transcript) {
+			if isFinal {
+				ms.backchannel(transcript, turnID)
+			} else {
+				ms.emitForTurn(TranscriptPartial, transcript, turnID)
+			}
+			return nil
+		}
+		if ms.command(transcript, isFinal, turnID) {
+			return nil
+		}
+
 		ms.mu.Lock()
 		minWords := 1
 		if ms.orch != nil {
@@ -398,7 +721,7 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 			if minWords > 1 {
 				if wc < minWords {
 					if !isFinal {
-						ms.emit(TranscriptPartial, transcript)
+						ms.emitForTurn(TranscriptPartial, transcript, turnID)
 					}
 					return nil
 				}
@@ -421,19 +744,39 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 			ms.mu.Unlock()
 
 			if isLikelyNoise(transcript, duration) {
+				ms.falseBargeIn()
 				return nil
 			}
 
-			ms.emit(TranscriptFinal, transcript)
-			ms.session.AddMessage("user", transcript)
+			trust := ms.transcriptTrust(0)
+			if ms.distrusted(transcript, trust, turnID) {
+				ms.falseBargeIn()
+				return nil
+			}
+			ms.followLanguage(transcript, "", turnID)
+			ms.emitFinal(transcript, trust, turnID)
+			transcript = ms.addUserMessage(transcript, speaker, turnID)
 
-			go ms.runLLMAndTTS(ctx, transcript)
+			ms.spawn(func() { ms.runLLMAndTTS(ctx, transcript) })
 		} else {
-			ms.emit(TranscriptPartial, transcript)
+			ms.emitForTurn(TranscriptPartial, transcript, turnID)
 		}
 		return nil
-	})
+	}
+
+	var sttChan chan<- []byte
+	var err error
+	if diarizing, ok := provider.(DiarizingSTTProvider); ok {
+		sttChan, err = diarizing.StreamTranscribeSpeakers(ctx, ms.session.InputLanguage(), onTranscript)
+	} else {
+		sttChan, err = provider.StreamTranscribe(ctx, ms.session.InputLanguage(), func(transcript string, isFinal bool) error {
+			return onTranscript(transcript, isFinal, "")
+		})
+	}
 
+	if err == nil {
+		sttChan = ms.sttStream(ctx, sttChan)
+	}
 	if err != nil {
 		// Just log or emit a warning, do not cancel the whole pipeline
 		// because the orchestrator will gracefully fall back to batch Transcribe.
@@ -477,28 +820,50 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 	ms.sttStartTime = time.Now()
 	ms.lastUserAudio = make([]byte, len(audioData))
 	copy(ms.lastUserAudio, audioData)
+	turnID := ms.turnID
 	ms.mu.Unlock()
 	defer cancel()
 
-	ms.emit(BotThinking, nil)
+	ms.emitForTurn(BotThinking, nil, turnID)
 
-	transcript, err := ms.orch.Transcribe(ctx, audioData, ms.session.GetCurrentLanguage())
+	timed, err := ms.orch.TranscribeDetailed(ctx, audioData, ms.sttLanguage())
+	var transcript string
 	ms.mu.Lock()
 	if err == nil {
+		transcript = timed.Text
 		ms.sttEndTime = time.Now()
 	}
 	ms.mu.Unlock()
 
 	if err != nil {
 		if ctx.Err() == nil {
-			ms.emit(ErrorEvent, fmt.Sprintf("transcription error: %v", err))
+			ms.emitForTurn(ErrorEvent, fmt.Sprintf("transcription error: %v", err), turnID)
 		}
 		return
 	}
 
-	audioDuration := time.Duration(len(audioData)/2) * time.Second / 44100
+	_, inputRate := ms.sampleRates()
+	audioDuration := time.Duration(len(audioData)/2) * time.Second / time.Duration(inputRate)
 
 	if transcript == "" || isLikelyNoise(transcript, audioDuration) {
+		ms.falseBargeIn()
+		return
+	}
+
+	if ms.hallucinated(transcript, turnID) {
+		ms.falseBargeIn()
+		return
+	}
+	if ms.isBackchannel(transcript) {
+		ms.backchannel(transcript, turnID)
+		return
+	}
+	if ms.command(transcript, true, turnID) {
+		return
+	}
+	trust := ms.transcriptTrust(timed.Confidence)
+	if ms.distrusted(transcript, trust, turnID) {
+		ms.falseBargeIn()
 		return
 	}
 
@@ -522,13 +887,21 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 		ms.internalInterrupt()
 	}
 
-	ms.emit(TranscriptFinal, transcript)
-	ms.session.AddMessage("user", transcript)
+	ms.followLanguage(transcript, timed.Language, turnID)
+	ms.emitFinal(transcript, trust, turnID)
+	if len(timed.Segments) > 0 {
+		ms.emitForTurn(TranscriptTimed, timed, turnID)
+	}
+	transcript = ms.addUserMessage(transcript, timed.Speaker, turnID)
 
 	ms.runLLMAndTTS(ctx, transcript)
 }
 
 func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
+	if ms.orch.GetConfig().Translation.Enabled {
+		ms.runTranslation(ctx, transcript)
+		return
+	}
 	ms.mu.Lock()
 
 	if ms.responseCancel != nil {
@@ -538,20 +911,35 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 		ms.ttsCancel()
 	}
 
+	if ms.supervised {
+		ms.mu.Unlock()
+		return
+	}
+
 	rCtx, rCancel := context.WithCancel(ctx)
 	ms.responseCancel = rCancel
 	ms.isThinking = true
+	ms.thinkingOn = transcript
+	ms.resumable = nil
 	ms.mu.Unlock()
 
 	defer rCancel()
 
-	ms.emit(BotThinking, nil)
+	turnID := ms.responseTurn()
+	ms.emitForTurn(BotThinking, nil, turnID)
+	ms.startFiller(rCtx, turnID)
+	defer ms.stopFiller()
+
+	retrieved := ms.orch.retrieve(rCtx, ms.session, transcript)
+	if retrieved != nil {
+		ms.emitForTurn(ContextRetrieved, *retrieved, turnID)
+	}
 
 	ms.mu.Lock()
 	ms.llmStartTime = time.Now()
 	ms.mu.Unlock()
 
-	response, err := ms.orch.GenerateResponse(rCtx, ms.session)
+	response, params, err := ms.orch.generateResponse(rCtx, ms.session, retrieved, ms.turnAudio())
 	ms.mu.Lock()
 	if err == nil {
 		ms.llmEndTime = time.Now()
@@ -560,14 +948,167 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 
 	if err != nil {
 		if rCtx.Err() == nil {
-			ms.emit(ErrorEvent, fmt.Sprintf("LLM error: %v", err))
+			ms.emitForTurn(ErrorEvent, fmt.Sprintf("LLM error: %v", err), turnID)
 		}
+		ms.canceledGeneration(err, turnID, transcript)
 		return
 	}
+	entered := ms.emitFlowUpdate(turnID)
+
+	response, blocked := ms.orch.applyGuardrail(rCtx, ms.session, response)
+	if rCtx.Err() != nil {
+		return
+	}
+	if blocked != nil {
+		ms.emitForTurn(GuardrailBlocked, *blocked, turnID)
+		if response == "" {
+			ms.mu.Lock()
+			ms.isThinking = false
+			ms.mu.Unlock()
+			return
+		}
+	}
+
+	response, unverified := ms.orch.applyFactCheck(rCtx, ms.session, response)
+	if rCtx.Err() != nil {
+		return
+	}
+	if unverified != nil {
+		ms.emitForTurn(FactCheckFailed, *unverified, turnID)
+	}
+
+	said := ms.orch.GetConfig().Prosody.strip(response)
+	ms.session.AddMessage("assistant", said)
+	ms.updateTurn(turnID, func(t *Turn) {
+		t.Response = said
+		t.Generation = params
+	})
+	ms.emitForTurn(BotResponse, said, turnID)
+
+	ms.speakResponse(rCtx, turnID, response)
+	latency, interrupted := ms.GetLatencyBreakdown(), rCtx.Err() != nil
+	ms.session.recordTurn(latency, interrupted)
+	ms.updateTurn(turnID, func(t *Turn) {
+		t.Latency = latency
+		t.Interrupted = interrupted
+		t.EndedAt = time.Now()
+	})
+	if turn, ok := ms.GetTurn(turnID); ok {
+		ms.orch.sampleQA(ms.session, turn)
+	}
+	if interrupted {
+		return
+	}
+	ms.discloseAfterReply(ctx, entered)
+	if reason := ms.endReason(transcript); reason != "" {
+		ms.spawn(func() { ms.endCall(reason) })
+	}
+	ms.sleep()
+}
+
+func (ms *ManagedStream) speakText(ctx context.Context, text string) {
+	ms.say(ctx, text, true)
+}
+
+func (ms *ManagedStream) say(ctx context.Context, text string, addToContext bool) error {
+	ms.mu.Lock()
+	if ms.isClosed {
+		ms.mu.Unlock()
+		return ErrStreamClosed
+	}
+	if ms.supervised {
+		ms.mu.Unlock()
+		return ErrSupervised
+	}
+	if ms.orch == nil {
+		ms.mu.Unlock()
+		return ErrNilProvider
+	}
+	if ms.responseCancel != nil {
+		ms.responseCancel()
+	}
+	if ms.ttsCancel != nil {
+		ms.ttsCancel()
+	}
+	said := ms.orch.GetConfig().Prosody.strip(text)
+	rCtx, rCancel := context.WithCancel(ctx)
+	ms.responseCancel = rCancel
+	turnID := ms.beginTurnLocked()
+	ms.turns[turnID].Response = said
+	ms.mu.Unlock()
+
+	defer rCancel()
+	stop := context.AfterFunc(ms.ctx, rCancel)
+	defer stop()
+
+	if addToContext {
+		ms.session.AddMessage("assistant", said)
+	}
+	ms.emitForTurn(BotResponse, said, turnID)
 
-	ms.session.AddMessage("assistant", response)
-	ms.emit(BotResponse, response)
+	ms.speakResponse(rCtx, turnID, text)
+	interrupted := rCtx.Err() != nil
+	ms.updateTurn(turnID, func(t *Turn) {
+		t.Interrupted = interrupted
+		t.EndedAt = time.Now()
+	})
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case ms.ctx.Err() != nil:
+		return ErrStreamClosed
+	case interrupted:
+		return ErrSpeechInterrupted
+	}
+	return nil
+}
 
+// vadEvents runs chunk through the VAD, keeping every transition when the
+// VAD can report several per chunk.
+func (ms *ManagedStream) vadEvents(chunk []byte) ([]VADEvent, error) {
+	if multi, ok := ms.vad.(MultiEventVAD); ok {
+		return multi.ProcessEvents(chunk)
+	}
+	event, err := ms.vad.Process(chunk)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	return []VADEvent{*event}, nil
+}
+
+func (ms *ManagedStream) handleVADEvent(event VADEvent, isEcho, gated bool) {
+	switch event.Type {
+	case VADSpeechStart:
+		if !isEcho {
+			ms.session.trackPace(func(p *paceTracker) { p.userStarted(time.Now()) })
+		}
+		if ms.continueHeldUtterance() {
+			ms.emit(UserSpeaking, nil)
+			return
+		}
+		if !isEcho && !gated && ms.continueUtterance() {
+			return
+		}
+		if !isEcho && !gated && !ms.armBargeIn() {
+			ms.internalInterrupt()
+		}
+		if !isEcho && !gated {
+			ms.beginTurn()
+		}
+		ms.startUtterance(gated)
+	case VADSpeechEnd:
+		ms.session.trackPace(func(p *paceTracker) { p.userStopped(time.Now()) })
+		ms.endUtterance(true)
+	}
+}
+
+func (ms *ManagedStream) speakResponse(rCtx context.Context, turnID, response string) {
+	ms.speakResponseAfter(rCtx, turnID, "", response)
+}
+
+// speakResponseAfter speaks response as the continuation of heard, the part
+// of the turn's reply already spoken.
+func (ms *ManagedStream) speakResponseAfter(rCtx context.Context, turnID, heard, response string) {
 	ms.mu.Lock()
 	ms.isThinking = false
 	ms.isSpeaking = true
@@ -578,6 +1119,10 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 
 	ttsCtx, ttsCancel := context.WithCancel(rCtx)
 	ms.ttsCancel = ttsCancel
+	ms.speakSeq++
+	seq := ms.speakSeq
+	ms.speech = speechProgress{turnID: turnID, heard: heard, text: ms.orch.GetConfig().Prosody.strip(response)}
+	ms.handOverFillerLocked()
 	ms.mu.Unlock()
 
 	defer ttsCancel()
@@ -586,9 +1131,11 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 	ms.botSpeakStartTime = time.Now()
 	ms.ttsStartTime = ms.botSpeakStartTime
 	ms.mu.Unlock()
-	ms.emit(BotSpeaking, nil)
+	ms.emitForTurn(BotSpeaking, nil, turnID)
 
-	err = ms.orch.SynthesizeStream(ttsCtx, response, ms.session.GetCurrentVoice(), ms.session.GetCurrentLanguage(), func(chunk []byte) error {
+	playbackRate, _ := ms.sampleRates()
+	firstChunk := true
+	err := ms.synthesize(ttsCtx, response, ms.session.GetCurrentVoice(), ms.session.OutputLanguage(), func(chunk []byte) error {
 		select {
 		case <-ttsCtx.Done():
 			return ttsCtx.Err()
@@ -599,11 +1146,28 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 			if ms.ttsFirstChunkTime.IsZero() {
 				ms.ttsFirstChunkTime = time.Now()
 			}
+			if ms.speech.turnID == turnID {
+				if ms.speech.first.IsZero() {
+					ms.speech.first = ms.lastAudioSentAt
+				}
+				ms.speech.emitted += int64(len(chunk))
+			}
 			gen := ms.payloadGen
 			ms.mu.Unlock()
+			ms.trackBotAudio(len(chunk))
+
+			if firstChunk {
+				firstChunk = false
+				if tail := ms.stopFiller(); len(tail) > 0 {
+					chunk = crossfade(chunk, tail)
+				}
+			}
+			if marker, ok := ms.activeEcho().(EchoMarker); ok {
+				chunk = marker.Mark(chunk)
+			}
 
-			// Slice large chunks into ~20ms frames to prevent playback jitter/underflows
-			frameSize := 1764 // 44100Hz * 0.02s * 2 bytes
+			// Slice large chunks into 20ms frames to prevent playback jitter/underflows
+			frameSize := playbackRate / 50 * 2
 			for i := 0; i < len(chunk); i += frameSize {
 				end := i + frameSize
 				if end > len(chunk) {
@@ -611,25 +1175,45 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 				}
 				c := chunk[i:end]
 
-				ms.emitWithGen(AudioChunk, c, gen)
+				ms.emitTurn(AudioChunk, c, gen, turnID)
 			}
 			return nil
 		}
+	}, func(m SpeechMark) error {
+		if err := ttsCtx.Err(); err != nil {
+			return err
+		}
+		ms.mu.Lock()
+		gen := ms.payloadGen
+		if m.Word != "" && ms.speech.turnID == turnID {
+			ms.speech.words = append(ms.speech.words, wordMark{words: len(strings.Fields(m.Word)), end: m.Offset + m.Duration})
+		}
+		ms.mu.Unlock()
+		ms.emitMark(m, gen, turnID)
+		return nil
 	})
 
+	ms.stopFiller()
 	ms.mu.Lock()
 	if !ms.ttsStartTime.IsZero() {
 		ms.ttsEndTime = time.Now()
 	}
+	if err == nil && ms.speech.turnID == turnID {
+		ms.speech.complete = true
+	}
 	ms.mu.Unlock()
 
 	if err != nil && ttsCtx.Err() == nil {
-		ms.emit(ErrorEvent, fmt.Sprintf("TTS error: %v", err))
+		ms.emitForTurn(ErrorEvent, fmt.Sprintf("TTS error: %v", err), turnID)
 	}
 
+	// A newer response may have started speaking while this one wound down,
+	// e.g. Say cancelling the reply in progress; its state is left alone.
 	ms.mu.Lock()
-	ms.isSpeaking = false
-	ms.ttsCancel = nil
+	if ms.speakSeq == seq {
+		ms.isSpeaking = false
+		ms.ttsCancel = nil
+	}
 	ms.mu.Unlock()
 }
 
@@ -640,11 +1224,19 @@ func (ms *ManagedStream) NotifyAudioPlayed() {
 	ms.mu.Unlock()
 }
 
+// RecordPlayedOutput feeds audio the client played to the echo reference.
+// Like Write, it joins an odd trailing byte to the next chunk.
 func (ms *ManagedStream) RecordPlayedOutput(chunk []byte) {
-	if ms.echoSuppressor == nil || len(chunk) == 0 {
+	echo := ms.activeEcho()
+	if echo == nil || len(chunk) == 0 {
 		return
 	}
-	ms.echoSuppressor.RecordPlayedAudio(chunk)
+	ms.mu.Lock()
+	chunk = ms.playedAlign.align(chunk)
+	ms.mu.Unlock()
+	if len(chunk) > 0 {
+		echo.RecordPlayed(chunk)
+	}
 }
 
 func (ms *ManagedStream) GetLatency() int64 {
@@ -664,15 +1256,15 @@ func (ms *ManagedStream) GetLatency() int64 {
 }
 
 type LatencyBreakdown struct {
-	UserToSTT          int64
-	STT                int64
-	UserToLLM          int64
-	LLM                int64
-	UserToTTSFirstByte int64
-	LLMToTTSFirstByte  int64
-	TTSTotal           int64
-	BotStartLatency    int64
-	UserToPlay         int64
+	UserToSTT          int64 `json:"user_to_stt_ms"`
+	STT                int64 `json:"stt_ms"`
+	UserToLLM          int64 `json:"user_to_llm_ms"`
+	LLM                int64 `json:"llm_ms"`
+	UserToTTSFirstByte int64 `json:"user_to_tts_first_byte_ms"`
+	LLMToTTSFirstByte  int64 `json:"llm_to_tts_first_byte_ms"`
+	TTSTotal           int64 `json:"tts_total_ms"`
+	BotStartLatency    int64 `json:"bot_start_latency_ms"`
+	UserToPlay         int64 `json:"user_to_play_ms"`
 }
 
 func (ms *ManagedStream) GetEndToEndLatency() int64 {
@@ -728,7 +1320,11 @@ func (ms *ManagedStream) GetLatencyBreakdown() LatencyBreakdown {
 	if !ms.botSpeakStartTime.IsZero() {
 		bd.BotStartLatency = ms.botSpeakStartTime.Sub(ms.userSpeechEndTime).Milliseconds()
 	}
-	if !ms.lastAudioSentAt.IsZero() {
+	if ms.playback.enabled {
+		if !ms.playback.firstPlayed.IsZero() {
+			bd.UserToPlay = ms.playback.firstPlayed.Sub(ms.userSpeechEndTime).Milliseconds()
+		}
+	} else if !ms.lastAudioSentAt.IsZero() {
 		bd.UserToPlay = ms.lastAudioSentAt.Sub(ms.userSpeechEndTime).Milliseconds()
 	}
 
@@ -736,6 +1332,10 @@ func (ms *ManagedStream) GetLatencyBreakdown() LatencyBreakdown {
 }
 
 func (ms *ManagedStream) ExportLastUserAudio() (raw []byte, processed []byte) {
+	if !ms.session.HasConsent(ConsentDebugAudio) {
+		return nil, nil
+	}
+
 	ms.mu.Lock()
 	if len(ms.lastUserAudio) == 0 {
 		ms.mu.Unlock()
@@ -745,8 +1345,8 @@ func (ms *ManagedStream) ExportLastUserAudio() (raw []byte, processed []byte) {
 	copy(rawCopy, ms.lastUserAudio)
 	ms.mu.Unlock()
 
-	if ms.echoSuppressor != nil {
-		processed = ms.echoSuppressor.PostProcess(rawCopy)
+	if p, ok := ms.activeEcho().(echoPostProcessor); ok {
+		processed = p.PostProcess(rawCopy)
 	} else {
 		processed = rawCopy
 	}
@@ -764,9 +1364,12 @@ func (ms *ManagedStream) Close() {
 		ms.mu.Lock()
 		ms.isClosed = true
 		ms.audioBuf.Reset()
+		ms.cancelAllScheduledLocked()
+		ms.clearDuckLocked()
 		ms.mu.Unlock()
 
-		ms.echoSuppressor.ClearEchoBuffer()
+		ms.resetEcho()
+		ms.session.trackPace(func(p *paceTracker) { p.end(time.Now()) })
 
 		ms.cancel()
 
@@ -774,7 +1377,28 @@ func (ms *ManagedStream) Close() {
 
 		ms.mu.Lock()
 		close(ms.events)
+		ms.closeMonitorsLocked()
+		ms.closeSubscribersLocked()
 		ms.mu.Unlock()
+
+		if ms.recorder != nil {
+			ms.recorder.Close()
+		}
+
+		if ms.orch != nil {
+			ms.orch.unregisterStream(ms)
+			// The session keeps its own usage.
+			if tracker := ms.orch.CostTracker(); tracker != nil {
+				tracker.Forget(ms.session.ID)
+			}
+			ms.mu.Lock()
+			holdsSlot := ms.holdsSlot
+			ms.holdsSlot = false
+			ms.mu.Unlock()
+			if holdsSlot {
+				ms.orch.releaseSlot()
+			}
+		}
 	})
 }
 
@@ -785,7 +1409,27 @@ func (ms *ManagedStream) emit(eventType EventType, data interface{}) {
 	ms.emitWithGen(eventType, data, gen)
 }
 
+// emitForTurn emits an event on behalf of turnID rather than the current
+// turn, for pipelines that may still be running when the next turn starts.
+func (ms *ManagedStream) emitForTurn(eventType EventType, data interface{}, turnID string) {
+	ms.mu.Lock()
+	gen := ms.payloadGen
+	ms.mu.Unlock()
+	ms.emitTurn(eventType, data, gen, turnID)
+}
+
 func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen int) {
+	ms.emitTurn(eventType, data, gen, "")
+}
+
+func (ms *ManagedStream) emitTurn(eventType EventType, data interface{}, gen int, turnID string) {
+	ms.emitEvent(OrchestratorEvent{Type: eventType, TurnID: turnID, Data: data, Generation: gen})
+}
+
+// emitEvent publishes event, filling in the session and, if unset, the
+// current turn.
+func (ms *ManagedStream) emitEvent(event OrchestratorEvent) {
+	eventType, data, turnID := event.Type, event.Data, event.TurnID
 	select {
 	case <-ms.ctx.Done():
 		return
@@ -799,7 +1443,7 @@ func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen
 	}
 
 	if eventType == AudioChunk {
-		speaking := ms.isSpeaking
+		speaking := ms.isSpeaking || ms.supervised
 		userInterrupting := ms.userInterrupting
 		if !speaking || userInterrupting {
 			ms.mu.Unlock()
@@ -807,23 +1451,44 @@ func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen
 		}
 	}
 
-	event := OrchestratorEvent{
-		Type:       eventType,
-		SessionID:  ms.session.ID,
-		Data:       data,
-		Generation: gen,
+	if turnID == "" {
+		turnID = ms.turnID
 	}
+	event.SessionID = ms.session.ID
+	event.TurnID = turnID
 
 	defer func() {
 		if r := recover(); r != nil {
 		}
 	}()
 
-	select {
-	case ms.events <- event:
-	case <-ms.ctx.Done():
-	default:
+	ms.publishEventLocked(event)
+	ms.publishSubscribersLocked(event)
+	if eventType == AudioChunk {
+		if pcm, ok := data.([]byte); ok {
+			ms.trackUnplayedLocked(turnID, pcm)
+		}
+	}
+	if ms.recorder != nil {
+		if eventType == AudioChunk {
+			if pcm, ok := data.([]byte); ok {
+				ms.recorder.WriteOutbound(pcm)
+			}
+		} else if t, ok := ms.turns[turnID]; ok && eventType == BotResponse && !t.Generation.IsZero() {
+			generation := t.Generation
+			ms.recorder.recordEvent(event, &generation)
+		} else {
+			ms.recorder.RecordEvent(event)
+		}
+	}
+	if eventType != AudioChunk && len(ms.sinks) > 0 {
+		redacted := withoutTranscript(ms.session, event)
+		for _, sink := range ms.sinks {
+			sink.Publish(redacted)
+		}
 	}
+
+	ms.sendEventLocked(event)
 	ms.mu.Unlock()
 }
 
@@ -845,6 +1510,10 @@ func (ms *ManagedStream) internalInterrupt() {
 
 	responseCancel := ms.responseCancel
 	ttsCancel := ms.ttsCancel
+	speech := ms.takeSpeechLocked(isStillPlaying)
+	if ms.isThinking {
+		ms.unanswered = ms.thinkingOn
+	}
 
 	ms.responseCancel = nil
 	ms.ttsCancel = nil
@@ -852,11 +1521,14 @@ func (ms *ManagedStream) internalInterrupt() {
 	ms.isSpeaking = false
 	ms.isThinking = false
 	ms.userInterrupting = false
+	ms.clearDuckLocked()
+	ms.stats.Interruptions++
 	ms.payloadGen++
 	gen := ms.payloadGen
 	ms.mu.Unlock()
 
-	ms.echoSuppressor.ClearEchoBuffer()
+	ms.resetEcho()
+	ms.session.trackPace(func(p *paceTracker) { p.botCut(time.Now()) })
 
 	if responseCancel != nil {
 		responseCancel()
@@ -872,7 +1544,20 @@ func (ms *ManagedStream) internalInterrupt() {
 	}
 
 	ms.lastInterruptedAt = time.Now()
-	ms.emitWithGen(Interrupted, nil, gen)
+	var data interface{}
+	spoken := joinSpoken(speech.heard, speech.text)
+	if cut := ms.cutResponse(speech); cut != nil {
+		spoken = cut.Spoken
+		ms.session.replaceLastAssistant(cut.Generated, markInterrupted(cut.Spoken))
+		ms.armResume(*cut)
+		data = *cut
+	}
+	if speech.text != "" {
+		ms.mu.Lock()
+		ms.spoken = spoken
+		ms.mu.Unlock()
+	}
+	ms.emitWithGen(Interrupted, data, gen)
 	ms.drainAudioChunks()
 }
 
@@ -901,6 +1586,9 @@ DrainDone:
 	if ms.isClosed {
 		return
 	}
+	ms.drainSubscriberAudioLocked()
+	// The client drops its queued audio too, so it will never be acked.
+	ms.playback.pending.Reset()
 	for _, ev := range controlEvents {
 		select {
 		case ms.events <- ev:
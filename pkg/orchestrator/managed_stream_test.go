@@ -364,3 +364,76 @@ func TestManagedStream_TranscriptInterruptWhileSpeaking(t *testing.T) {
 		t.Fatal("timed out waiting for Interrupted via transcript")
 	}
 }
+
+func TestManagedStream_48kHzAudioFrames(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 48000
+	cfg.FirstSpeaker = FirstSpeakerUser
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 9600)}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("opus"))
+	defer stream.Close()
+
+	stream.speakText(stream.ctx, "Hello.")
+
+	var frames int
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type != AudioChunk {
+				continue
+			}
+			if n := len(ev.Data.([]byte)); n != 1920 {
+				t.Fatalf("expected 20ms frames at 48kHz (1920 bytes), got %d", n)
+			}
+			frames++
+		default:
+			if frames != 5 {
+				t.Errorf("expected 5 frames, got %d", frames)
+			}
+			return
+		}
+	}
+}
+
+type timedSTT struct{ MockSTTProvider }
+
+func (s *timedSTT) TranscribeDetailed(ctx context.Context, audio []byte, lang Language) (*TranscriptionResult, error) {
+	return &TranscriptionResult{
+		Text:     "book a table",
+		Duration: time.Second,
+		Segments: []TranscriptSegment{{Start: 200 * time.Millisecond, End: time.Second, Text: "book a table"}},
+	}, nil
+}
+
+func TestManagedStream_TranscriptTimed(t *testing.T) {
+	stream := newTestStream(t, DefaultConfig(), &timedSTT{}, &MockLLMProvider{completeResult: "Sure."})
+
+	stream.runBatchPipeline(make([]byte, 32000))
+
+	if ev := waitForEvent(t, stream, TranscriptFinal); ev.Data != "book a table" {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	ev := waitForEvent(t, stream, TranscriptTimed)
+	result, ok := ev.Data.(*TranscriptionResult)
+	if !ok || len(result.Segments) != 1 || result.Segments[0].Start != 200*time.Millisecond {
+		t.Errorf("unexpected timed transcript %#v", ev.Data)
+	}
+}
+
+func TestManagedStream_BoundsLastUserAudio(t *testing.T) {
+	stream := newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{})
+
+	silence := make([]byte, 882)
+	for i := 0; i < (maxUserAudioSeconds+5)*100; i++ {
+		if err := stream.listen(silence); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stream.mu.Lock()
+	n := len(stream.lastUserAudio)
+	stream.mu.Unlock()
+	if want := maxUserAudioSeconds * 44100 * 2; n != want {
+		t.Errorf("lastUserAudio holds %d bytes, want %d", n, want)
+	}
+}
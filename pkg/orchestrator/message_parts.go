@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type PartType string
+
+const (
+	PartText       PartType = "text"
+	PartImage      PartType = "image"
+	PartToolResult PartType = "tool_result"
+)
+
+// MessagePart is a non-text attachment of a Message, sent after its Content.
+// An image is given by URL or as raw bytes in Data with its MimeType. A tool
+// result carries the tool's output in Text; providers without a matching
+// tool call render it as text.
+type MessagePart struct {
+	Type     PartType `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Data     []byte   `json:"data,omitempty"`
+	MimeType string   `json:"mime_type,omitempty"`
+	ToolName string   `json:"tool_name,omitempty"`
+}
+
+func TextPart(text string) MessagePart {
+	return MessagePart{Type: PartText, Text: text}
+}
+
+func ImageURLPart(url string) MessagePart {
+	return MessagePart{Type: PartImage, URL: url}
+}
+
+func ImageDataPart(data []byte, mimeType string) MessagePart {
+	return MessagePart{Type: PartImage, Data: data, MimeType: mimeType}
+}
+
+func ToolResultPart(tool, result string) MessagePart {
+	return MessagePart{Type: PartToolResult, ToolName: tool, Text: result}
+}
+
+// AsText renders a text or tool result part for providers that only take
+// text. It returns "" for images.
+func (p MessagePart) AsText() string {
+	switch p.Type {
+	case PartText:
+		return p.Text
+	case PartToolResult:
+		if p.ToolName == "" {
+			return "Tool result: " + p.Text
+		}
+		return fmt.Sprintf("Tool result (%s): %s", p.ToolName, p.Text)
+	}
+	return ""
+}
+
+func (m Message) equal(o Message) bool {
+	if m.Role != o.Role || m.Content != o.Content || len(m.Parts) != len(o.Parts) {
+		return false
+	}
+	for i, p := range m.Parts {
+		q := o.Parts[i]
+		if p.Type != q.Type || p.Text != q.Text || p.URL != q.URL || p.MimeType != q.MimeType || p.ToolName != q.ToolName || !bytes.Equal(p.Data, q.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// describeParts summarizes attachments for text-only uses such as
+// compaction.
+func describeParts(parts []MessagePart) string {
+	var b strings.Builder
+	for _, p := range parts {
+		if text := p.AsText(); text != "" {
+			b.WriteString(" " + text)
+		} else if p.Type == PartImage {
+			b.WriteString(" [image]")
+		}
+	}
+	return b.String()
+}
+
+// AddMessageWithParts adds a message with attachments, e.g. a camera frame
+// the user is talking about.
+func (s *ConversationSession) AddMessageWithParts(role, content string, parts ...MessagePart) {
+	s.addMessage(Message{Role: role, Content: content, Parts: append([]MessagePart(nil), parts...)})
+}
+
+// Attach queues parts for the next user message, so a kiosk can hand over
+// what its camera sees and the next spoken turn carries it.
+func (s *ConversationSession) Attach(parts ...MessagePart) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingParts = append(s.pendingParts, parts...)
+}
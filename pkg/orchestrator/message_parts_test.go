@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSession_AttachGoesToNextUserMessage(t *testing.T) {
+	s := NewConversationSession("kiosk")
+	s.Attach(ImageDataPart([]byte{0xff, 0xd8}, "image/jpeg"))
+	s.AddMessage("assistant", "Hi there!")
+	s.AddMessage("user", "what am I holding?")
+	s.AddMessage("user", "hello?")
+
+	ctx := s.GetContextCopy()
+	if len(ctx[0].Parts) != 0 || len(ctx[2].Parts) != 0 {
+		t.Errorf("attachments must only go to the next user message: %+v", ctx)
+	}
+	if parts := ctx[1].Parts; len(parts) != 1 || parts[0].Type != PartImage || parts[0].MimeType != "image/jpeg" {
+		t.Errorf("unexpected parts %+v", parts)
+	}
+}
+
+func TestSession_PartsSurviveSnapshot(t *testing.T) {
+	s := NewConversationSession("kiosk")
+	s.AddMessageWithParts("user", "look", ImageURLPart("https://example.com/a.png"), ImageDataPart([]byte{1, 2, 3}, "image/png"), ToolResultPart("weather", "sunny"))
+
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap SessionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	restored := RestoreSession(snap)
+	if got := restored.GetContextCopy()[0]; !got.equal(s.GetContextCopy()[0]) {
+		t.Errorf("parts changed in snapshot: %+v", got.Parts)
+	}
+}
+
+func TestMessagePart_AsText(t *testing.T) {
+	if got := ToolResultPart("weather", "sunny").AsText(); got != "Tool result (weather): sunny" {
+		t.Errorf("got %q", got)
+	}
+	if got := ImageURLPart("https://example.com/a.png").AsText(); got != "" {
+		t.Errorf("images have no text form, got %q", got)
+	}
+}
+
+func TestEstimateTokens_CountsImages(t *testing.T) {
+	text := []Message{{Role: "user", Content: "look"}}
+	withImage := []Message{{Role: "user", Content: "look", Parts: []MessagePart{ImageURLPart("https://example.com/a.png")}}}
+	if diff := EstimateTokens(withImage) - EstimateTokens(text); diff != imageTokens {
+		t.Errorf("image counted as %d tokens", diff)
+	}
+}
@@ -0,0 +1,88 @@
+package orchestrator
+
+type MonitorFrameKind string
+
+const (
+	MonitorEvent     MonitorFrameKind = "event"
+	MonitorUserAudio MonitorFrameKind = "user_audio"
+	MonitorBotAudio  MonitorFrameKind = "bot_audio"
+)
+
+// MonitorFrame is a read-only copy of what flows through a stream: every
+// emitted event plus the caller's and the bot's audio.
+type MonitorFrame struct {
+	Kind  MonitorFrameKind
+	Event OrchestratorEvent
+	Audio []byte
+}
+
+type streamMonitor struct {
+	frames chan MonitorFrame
+}
+
+// Monitor subscribes to the stream without affecting it. Slow subscribers lose
+// frames instead of blocking the pipeline. The channel is closed when either
+// the returned cancel func is called or the stream closes.
+func (ms *ManagedStream) Monitor(buffer int) (<-chan MonitorFrame, func()) {
+	if buffer <= 0 {
+		buffer = 256
+	}
+	m := &streamMonitor{frames: make(chan MonitorFrame, buffer)}
+
+	ms.mu.Lock()
+	if ms.isClosed {
+		ms.mu.Unlock()
+		close(m.frames)
+		return m.frames, func() {}
+	}
+	if ms.monitors == nil {
+		ms.monitors = make(map[*streamMonitor]struct{})
+	}
+	ms.monitors[m] = struct{}{}
+	ms.mu.Unlock()
+
+	return m.frames, func() {
+		ms.mu.Lock()
+		defer ms.mu.Unlock()
+		if _, ok := ms.monitors[m]; ok {
+			delete(ms.monitors, m)
+			close(m.frames)
+		}
+	}
+}
+
+func (ms *ManagedStream) MonitorCount() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.monitors)
+}
+
+// publishLocked must be called with ms.mu held.
+func (ms *ManagedStream) publishLocked(frame MonitorFrame) {
+	for m := range ms.monitors {
+		select {
+		case m.frames <- frame:
+		default:
+		}
+	}
+}
+
+func (ms *ManagedStream) publishEventLocked(event OrchestratorEvent) {
+	if len(ms.monitors) == 0 {
+		return
+	}
+	if event.Type == AudioChunk {
+		if audio, ok := event.Data.([]byte); ok {
+			ms.publishLocked(MonitorFrame{Kind: MonitorBotAudio, Audio: audio})
+		}
+		return
+	}
+	ms.publishLocked(MonitorFrame{Kind: MonitorEvent, Event: event})
+}
+
+func (ms *ManagedStream) closeMonitorsLocked() {
+	for m := range ms.monitors {
+		close(m.frames)
+	}
+	ms.monitors = nil
+}
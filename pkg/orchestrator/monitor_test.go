@@ -0,0 +1,75 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_Monitor(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("monitor"))
+
+	frames, cancel := stream.Monitor(16)
+	if stream.MonitorCount() != 1 {
+		t.Fatalf("expected 1 monitor")
+	}
+
+	stream.emit(TranscriptFinal, "hello")
+	select {
+	case f := <-frames:
+		if f.Kind != MonitorEvent || f.Event.Type != TranscriptFinal {
+			t.Errorf("unexpected frame: %+v", f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for monitor frame")
+	}
+	// The monitored stream must still receive its own copy.
+	if ev := <-stream.Events(); ev.Type != TranscriptFinal {
+		t.Errorf("expected stream event, got %v", ev.Type)
+	}
+
+	stream.mu.Lock()
+	stream.isSpeaking = true
+	stream.mu.Unlock()
+	stream.emit(AudioChunk, []byte{1, 2})
+	if f := <-frames; f.Kind != MonitorBotAudio || len(f.Audio) != 2 {
+		t.Errorf("expected bot audio frame, got %+v", f)
+	}
+
+	cancel()
+	if _, ok := <-frames; ok {
+		t.Error("expected channel to be closed after cancel")
+	}
+	cancel()
+
+	frames2, _ := stream.Monitor(1)
+	stream.Close()
+	for range frames2 {
+	}
+	if stream.MonitorCount() != 0 {
+		t.Error("expected monitors to be released on close")
+	}
+}
+
+func TestManagedStream_MonitorDoesNotBlock(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("monitor"))
+	defer stream.Close()
+
+	_, cancel := stream.Monitor(1)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			stream.emit(BotThinking, nil)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("slow monitor blocked the stream")
+	}
+}
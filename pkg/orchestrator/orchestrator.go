@@ -1,10 +1,12 @@
 package orchestrator
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 
@@ -16,6 +18,25 @@ type Orchestrator struct {
 	config Config
 	logger Logger
 	mu     sync.RWMutex
+
+	costTracker *CostTracker
+
+	streamsMu sync.Mutex
+	streams   map[string]*ManagedStream
+
+	capMu     sync.Mutex
+	capActive int
+	capQueue  []*slotWaiter
+
+	llmGate          priorityGate
+	pressureMu       sync.Mutex
+	rateLimitedUntil time.Time
+
+	checkpoints checkpointer
+	qa          qaState
+	recordings  sync.WaitGroup
+	ttsCache    ttsCache
+	disclosures disclosureCache
 }
 
 
@@ -34,7 +55,8 @@ func NewWithLogger(stt STTProvider, llm LLMProvider, tts TTSProvider, vad VADPro
 	if logger == nil {
 		logger = &NoOpLogger{}
 	}
-	return &Orchestrator{
+	config, vad = applyProfile(config, vad, logger)
+	o := &Orchestrator{
 		stt:    stt,
 		llm:    llm,
 		tts:    tts,
@@ -42,6 +64,23 @@ func NewWithLogger(stt STTProvider, llm LLMProvider, tts TTSProvider, vad VADPro
 		config: config,
 		logger: logger,
 	}
+	o.applySampleRate(config.SampleRate)
+	o.applySTTParams(config.STTParams)
+	if s, ok := config.Checkpoint.Store.(EncryptorSetter); ok && config.Encryptor != nil {
+		s.SetEncryptor(config.Encryptor)
+	}
+	return o
+}
+
+func (o *Orchestrator) applySampleRate(rate int) {
+	if rate <= 0 {
+		return
+	}
+	for _, p := range []interface{}{o.stt, o.tts} {
+		if s, ok := p.(SampleRateSetter); ok {
+			s.SetSampleRate(rate)
+		}
+	}
 }
 
 
@@ -55,7 +94,7 @@ func (o *Orchestrator) PushAudio(sessionID string, chunk []byte) (*VADEvent, err
 
 func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSession, audioData []byte) (string, []byte, error) {
 	
-	transcript, err := o.Transcribe(ctx, audioData, session.GetCurrentLanguage())
+	transcript, err := o.Transcribe(ctx, audioData, session.InputLanguage())
 	if err != nil {
 		return "", nil, fmt.Errorf("transcription failed: %w", err)
 	}
@@ -69,17 +108,22 @@ func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSe
 	session.AddMessage("user", transcript)
 
 	
-	response, err := o.GenerateResponse(ctx, session)
+	response, _, err := o.generateResponse(ctx, session, o.retrieve(ctx, session, transcript), nil)
 	if err != nil {
 		o.logger.Error("LLM generation failed", "sessionID", session.ID, "error", err)
 		return transcript, nil, fmt.Errorf("%w: %v", ErrLLMFailed, err)
 	}
 
 	o.logger.Info("LLM response generated", "sessionID", session.ID, "length", len(response))
+	response, blocked := o.applyGuardrail(ctx, session, response)
+	if blocked != nil && response == "" {
+		return transcript, nil, fmt.Errorf("%w: %s", ErrGuardrailBlocked, blocked.Reason)
+	}
+	response, _ = o.applyFactCheck(ctx, session, response)
 	session.AddMessage("assistant", response)
 
 	
-	audioBytes, err := o.Synthesize(ctx, response, session.GetCurrentVoice(), session.GetCurrentLanguage())
+	audioBytes, err := o.Synthesize(ctx, response, session.GetCurrentVoice(), session.OutputLanguage())
 	if err != nil {
 		o.logger.Error("TTS synthesis failed", "sessionID", session.ID, "error", err)
 		return transcript, nil, fmt.Errorf("%w: %v", ErrTTSFailed, err)
@@ -92,7 +136,7 @@ func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSe
 
 func (o *Orchestrator) ProcessAudioStream(ctx context.Context, session *ConversationSession, audioData []byte, onAudioChunk func([]byte) error) (string, error) {
 	
-	transcript, err := o.Transcribe(ctx, audioData, session.GetCurrentLanguage())
+	transcript, err := o.Transcribe(ctx, audioData, session.InputLanguage())
 	if err != nil {
 		return "", fmt.Errorf("transcription failed: %w", err)
 	}
@@ -106,17 +150,22 @@ func (o *Orchestrator) ProcessAudioStream(ctx context.Context, session *Conversa
 	session.AddMessage("user", transcript)
 
 	
-	response, err := o.GenerateResponse(ctx, session)
+	response, _, err := o.generateResponse(ctx, session, o.retrieve(ctx, session, transcript), nil)
 	if err != nil {
 		o.logger.Error("LLM generation failed", "sessionID", session.ID, "error", err)
 		return transcript, fmt.Errorf("%w: %v", ErrLLMFailed, err)
 	}
 
 	o.logger.Info("LLM response generated", "sessionID", session.ID, "length", len(response))
+	response, blocked := o.applyGuardrail(ctx, session, response)
+	if blocked != nil && response == "" {
+		return transcript, fmt.Errorf("%w: %s", ErrGuardrailBlocked, blocked.Reason)
+	}
+	response, _ = o.applyFactCheck(ctx, session, response)
 	session.AddMessage("assistant", response)
 
 	
-	err = o.SynthesizeStream(ctx, response, session.GetCurrentVoice(), session.GetCurrentLanguage(), onAudioChunk)
+	err = o.SynthesizeStream(ctx, response, session.GetCurrentVoice(), session.OutputLanguage(), onAudioChunk)
 	if err != nil {
 		o.logger.Error("TTS streaming failed", "sessionID", session.ID, "error", err)
 		return transcript, fmt.Errorf("%w: %v", ErrTTSFailed, err)
@@ -128,22 +177,121 @@ func (o *Orchestrator) ProcessAudioStream(ctx context.Context, session *Conversa
 
 
 func (o *Orchestrator) Transcribe(ctx context.Context, audioData []byte, lang Language) (string, error) {
-	return o.stt.Transcribe(ctx, audioData, lang)
+	return o.stt.Transcribe(ctx, o.sttInput(audioData), lang)
+}
+
+// TranscribeDetailed returns segment timestamps when the STT provider
+// implements DetailedTranscriber, and the plain transcript otherwise.
+func (o *Orchestrator) TranscribeDetailed(ctx context.Context, audioData []byte, lang Language) (*TranscriptionResult, error) {
+	audioData = o.sttInput(audioData)
+	if dt, ok := o.stt.(DetailedTranscriber); ok {
+		return dt.TranscribeDetailed(ctx, audioData, lang)
+	}
+	text, err := o.stt.Transcribe(ctx, audioData, lang)
+	if err != nil {
+		return nil, err
+	}
+	return &TranscriptionResult{Text: text}, nil
 }
 
 
 func (o *Orchestrator) GenerateResponse(ctx context.Context, session *ConversationSession) (string, error) {
-	return o.llm.Complete(ctx, session.GetContextCopy())
+	response, _, err := o.generateResponse(ctx, session, nil, nil)
+	return response, err
+}
+
+// generateResponse also returns the parameters the LLM was called with. They
+// are attached to the next assistant message's transcript entry.
+func (o *Orchestrator) generateResponse(ctx context.Context, session *ConversationSession, retrieved *RetrievalResult, audio *TurnAudio) (string, GenerationParams, error) {
+	o.compactIfNeeded(ctx, session)
+
+	cfg := o.GetConfig()
+	messages := withStyle(withSpeakers(session.GetContextCopy()), cfg.ResponseStyle.instruction(session.Channel()))
+	messages = withStyle(messages, cfg.EndConversation.instruction())
+	messages = withStyle(messages, cfg.Prosody.instruction())
+	messages = withStyle(messages, o.flowInstruction(session))
+	if retrieved != nil && retrieved.message != nil {
+		messages = withRetrievedContext(messages, *retrieved.message)
+	}
+
+	release, err := o.acquireLLM(ctx, session)
+	if err != nil {
+		return "", GenerationParams{}, err
+	}
+	defer release()
+
+	llm, params := o.llmFor(session)
+	if replay, ok := session.nextReplayParams(); ok {
+		params = params.Merge(replay)
+	}
+	params = o.seedParams(llm, params)
+	response, usage, err := o.completeWithAudio(ctx, llm, messages, params, audio)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", params, o.canceledLLM(session, llm, messages, response, usage, err)
+		}
+		o.noteLLMError(err)
+		return "", params, err
+	}
+	o.recordUsageFor(session, llm, usage)
+	if !params.IsZero() {
+		session.setPendingParams(params)
+	}
+	response, ended := cfg.EndConversation.takeMarker(response)
+	response, flowEnded := o.advanceFlow(session, response)
+	session.setEnded(ended || flowEnded)
+	return response, params, nil
 }
 
 
 func (o *Orchestrator) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
-	return o.tts.Synthesize(ctx, text, voice, lang)
+	cfg := o.GetConfig().TTSCache
+	if !cfg.caches(text) {
+		pcm, err := o.tts.Synthesize(ctx, text, voice, lang)
+		out, cerr := o.ttsAudio(pcm)
+		if err == nil {
+			err = cerr
+		}
+		return out, err
+	}
+	key := o.ttsCacheKey(text, voice, lang)
+	if pcm, ok := o.cachedSpeech(ctx, cfg, key); ok {
+		return bytes.Clone(pcm), nil
+	}
+	pcm, err := o.tts.Synthesize(ctx, text, voice, lang)
+	if err == nil {
+		pcm, err = o.ttsAudio(pcm)
+	}
+	if err == nil {
+		o.cacheSpeech(ctx, cfg, key, bytes.Clone(pcm))
+	}
+	return pcm, err
 }
 
 
 func (o *Orchestrator) SynthesizeStream(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
-	return o.tts.StreamSynthesize(ctx, text, voice, lang, onChunk)
+	cfg := o.GetConfig().TTSCache
+	if !cfg.caches(text) {
+		return o.convertTTS(onChunk, func(onChunk func([]byte) error) error {
+			return o.tts.StreamSynthesize(ctx, text, voice, lang, onChunk)
+		})
+	}
+	key := o.ttsCacheKey(text, voice, lang)
+	if pcm, ok := o.cachedSpeech(ctx, cfg, key); ok {
+		return onChunk(bytes.Clone(pcm))
+	}
+	var pcm []byte
+	err := o.convertTTS(func(chunk []byte) error {
+		pcm = append(pcm, chunk...)
+		return onChunk(chunk)
+	}, func(onChunk func([]byte) error) error {
+		return o.tts.StreamSynthesize(ctx, text, voice, lang, onChunk)
+	})
+	// Speech cut short, e.g. by barge-in, is not kept.
+	if err == nil {
+		o.cacheSpeech(ctx, cfg, key, pcm)
+	}
+	return err
 }
 
 
@@ -156,6 +304,12 @@ func (o *Orchestrator) HandleInterruption(session *ConversationSession) {
 func (o *Orchestrator) UpdateConfig(cfg Config) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
+	if cfg.SampleRate != o.config.SampleRate {
+		o.applySampleRate(cfg.SampleRate)
+	}
+	if cfg.STTParams != o.config.STTParams {
+		o.applySTTParams(cfg.STTParams)
+	}
 	o.config = cfg
 }
 
@@ -180,8 +334,19 @@ func (o *Orchestrator) GetProviders() map[string]string {
 func (o *Orchestrator) NewSessionWithDefaults(userID string) *ConversationSession {
 	session := NewConversationSession(userID)
 	session.MaxMessages = o.config.MaxContextMessages
+	if o.config.Compaction.Enabled() {
+		// Compaction bounds the context; truncating first would starve it.
+		session.MaxMessages = 0
+	}
+	session.MaxTranscript = o.config.Checkpoint.MaxTranscript
 	session.CurrentVoice = o.config.VoiceStyle
 	session.CurrentLanguage = o.config.Language
+	if o.config.RequireConsent {
+		session.RevokeConsent(AllConsentScopes...)
+	}
+	if o.config.Checkpoint.Store != nil {
+		o.Checkpoint(session)
+	}
 	return session
 }
 
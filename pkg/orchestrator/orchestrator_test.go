@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 type MockSTTProvider struct {
@@ -56,6 +57,18 @@ func (m *MockTTSProvider) Name() string {
 	return "MockTTS"
 }
 
+// newTestStream starts a stream that waits for the user to speak first,
+// with an RMS VAD and a TTS that answers every sentence with 10ms of
+// silence. Tests set cfg for the feature under test.
+func newTestStream(t *testing.T, cfg Config, stt STTProvider, llm LLMProvider) *ManagedStream {
+	t.Helper()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(stt, llm, &MockTTSProvider{synthesizeResult: make([]byte, 882)}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("test"))
+	t.Cleanup(ms.Close)
+	return ms
+}
+
 func TestOrchestratorCreation(t *testing.T) {
 	stt := &MockSTTProvider{}
 	llm := &MockLLMProvider{}
@@ -328,3 +341,92 @@ func isErrorType(err error, target error) bool {
 	}
 	return err.Error() == target.Error()
 }
+
+type MockParamsLLMProvider struct {
+	MockLLMProvider
+	lastParams GenerationParams
+}
+
+func (m *MockParamsLLMProvider) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	m.lastParams = params
+	return m.completeResult, m.completeErr
+}
+
+func TestGenerateResponse_GenerationParams(t *testing.T) {
+	llm := &MockParamsLLMProvider{MockLLMProvider: MockLLMProvider{completeResult: "ok"}}
+	cfg := DefaultConfig()
+	cfg.Generation = GenerationParams{Temperature: Float(0.7), MaxTokens: 200}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, cfg)
+
+	session := orch.NewSessionWithDefaults("params")
+	session.SetGenerationParams(GenerationParams{MaxTokens: 50, Stop: []string{"."}})
+
+	if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if llm.lastParams.Temperature == nil || *llm.lastParams.Temperature != 0.7 {
+		t.Errorf("expected config temperature to carry through, got %v", llm.lastParams.Temperature)
+	}
+	if llm.lastParams.MaxTokens != 50 {
+		t.Errorf("expected session max tokens to override config, got %d", llm.lastParams.MaxTokens)
+	}
+	if len(llm.lastParams.Stop) != 1 {
+		t.Errorf("expected session stop sequences, got %v", llm.lastParams.Stop)
+	}
+}
+
+type rateTTSProvider struct {
+	MockTTSProvider
+	rate int
+}
+
+func (p *rateTTSProvider) SetSampleRate(rate int) {
+	p.rate = rate
+}
+
+func TestSampleRateAppliedToProviders(t *testing.T) {
+	tts := &rateTTSProvider{}
+	cfg := DefaultConfig()
+	cfg.SampleRate = 48000
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+	if tts.rate != 48000 {
+		t.Fatalf("expected the TTS provider at 48000Hz, got %d", tts.rate)
+	}
+
+	cfg.SampleRate = 16000
+	orch.UpdateConfig(cfg)
+	if tts.rate != 16000 {
+		t.Errorf("expected UpdateConfig to apply 16000Hz, got %d", tts.rate)
+	}
+}
+
+type paramsSTTProvider struct {
+	MockSTTProvider
+	params STTParams
+	calls  int
+}
+
+func (p *paramsSTTProvider) SetSTTParams(params STTParams) {
+	p.params = params
+	p.calls++
+}
+
+func TestSTTParamsAppliedToProvider(t *testing.T) {
+	stt := &paramsSTTProvider{}
+	temperature := 0.2
+	cfg := DefaultConfig()
+	cfg.STTParams.Temperature = &temperature
+	orch := New(stt, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	if stt.params.Temperature != &temperature {
+		t.Fatal("expected New to pass the STT params to the provider")
+	}
+
+	orch.UpdateConfig(cfg)
+	threshold := 0.6
+	cfg.STTParams.NoSpeechThreshold = &threshold
+	orch.UpdateConfig(cfg)
+	if stt.calls != 2 || stt.params.NoSpeechThreshold != &threshold {
+		t.Errorf("expected UpdateConfig to apply changed params once, got %d calls", stt.calls)
+	}
+}
@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrSessionOwned = errors.New("session is active on another instance")
+
+// SessionLocker guarantees a session id is only active on one instance at a
+// time. Ownership is a lease that expires unless refreshed, so a crashed
+// instance does not hold its sessions forever.
+type SessionLocker interface {
+	Acquire(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error)
+	Refresh(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, sessionID, owner string) error
+	Owner(ctx context.Context, sessionID string) (string, error)
+}
+
+// SessionLease keeps a session owned while the call is live.
+type SessionLease struct {
+	locker    SessionLocker
+	sessionID string
+	owner     string
+	ttl       time.Duration
+	lost      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+}
+
+// AcquireSessionLease takes ownership of sessionID and refreshes it every
+// ttl/3 until Release. Lost is closed if ownership cannot be kept. The lock is
+// held as owner (the instance) plus a random token, so a second connection
+// for the session is refused even on the same instance.
+func AcquireSessionLease(ctx context.Context, locker SessionLocker, sessionID, owner string, ttl time.Duration) (*SessionLease, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	owner += "/" + hex.EncodeToString(token)
+	ok, err := locker.Acquire(ctx, sessionID, owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSessionOwned
+	}
+
+	l := &SessionLease{
+		locker:    locker,
+		sessionID: sessionID,
+		owner:     owner,
+		ttl:       ttl,
+		lost:      make(chan struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go l.keepAlive()
+	return l, nil
+}
+
+func (l *SessionLease) keepAlive() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			ok, err := l.locker.Refresh(ctx, l.sessionID, l.owner, l.ttl)
+			cancel()
+			if err == nil && ok {
+				failures = 0
+				continue
+			}
+			// Two consecutive errors mean the lease may already have expired.
+			failures++
+			if (err == nil && !ok) || failures >= 2 {
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+func (l *SessionLease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *SessionLease) SessionID() string {
+	return l.sessionID
+}
+
+func (l *SessionLease) Release(ctx context.Context) error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		err = l.locker.Release(ctx, l.sessionID, l.owner)
+	})
+	return err
+}
+
+type memoryLock struct {
+	owner   string
+	expires time.Time
+}
+
+// MemorySessionLocker is a single-process SessionLocker, useful for tests and
+// single-replica deployments.
+type MemorySessionLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+	now   func() time.Time
+}
+
+func NewMemorySessionLocker() *MemorySessionLocker {
+	return &MemorySessionLocker{locks: make(map[string]memoryLock), now: time.Now}
+}
+
+func (m *MemorySessionLocker) Acquire(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if l, ok := m.locks[sessionID]; ok && l.owner != owner && now.Before(l.expires) {
+		return false, nil
+	}
+	m.locks[sessionID] = memoryLock{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (m *MemorySessionLocker) Refresh(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	l, ok := m.locks[sessionID]
+	if !ok || l.owner != owner || !now.Before(l.expires) {
+		return false, nil
+	}
+	m.locks[sessionID] = memoryLock{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (m *MemorySessionLocker) Release(ctx context.Context, sessionID, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[sessionID]; ok && l.owner == owner {
+		delete(m.locks, sessionID)
+	}
+	return nil
+}
+
+func (m *MemorySessionLocker) Owner(ctx context.Context, sessionID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.locks[sessionID]
+	if !ok || !m.now().Before(l.expires) {
+		return "", nil
+	}
+	return l.owner, nil
+}
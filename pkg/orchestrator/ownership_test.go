@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemorySessionLocker(t *testing.T) {
+	locker := NewMemorySessionLocker()
+	now := time.Now()
+	locker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if ok, _ := locker.Acquire(ctx, "s", "a", time.Second); !ok {
+		t.Fatal("expected acquire")
+	}
+	if ok, _ := locker.Acquire(ctx, "s", "b", time.Second); ok {
+		t.Error("expected conflict")
+	}
+	if ok, _ := locker.Acquire(ctx, "s", "a", time.Second); !ok {
+		t.Error("re-acquiring an owned lease must succeed")
+	}
+
+	now = now.Add(2 * time.Second)
+	if ok, _ := locker.Refresh(ctx, "s", "a", time.Second); ok {
+		t.Error("expired lease must not be refreshed")
+	}
+	if ok, _ := locker.Acquire(ctx, "s", "b", time.Second); !ok {
+		t.Error("expected takeover after expiry")
+	}
+}
+
+func TestSessionLease_Lost(t *testing.T) {
+	locker := NewMemorySessionLocker()
+	lease, err := AcquireSessionLease(context.Background(), locker, "s", "a", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lease.Release(context.Background())
+
+	// Simulate another instance stealing the session.
+	locker.mu.Lock()
+	locker.locks["s"] = memoryLock{owner: "b", expires: time.Now().Add(time.Minute)}
+	locker.mu.Unlock()
+
+	select {
+	case <-lease.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("expected the lease to be reported lost")
+	}
+}
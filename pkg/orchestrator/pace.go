@@ -0,0 +1,180 @@
+package orchestrator
+
+import "time"
+
+// monologueGap is the longest pause in bot audio, such as a TTS stall, still
+// counted as one monologue.
+const monologueGap = 500 * time.Millisecond
+
+// PaceMetrics measure how natural a session's turn-taking feels, to compare
+// configurations. The caller's talk is timed by the VAD; the bot's by the
+// audio it was sent, which plays in real time from its first chunk until the
+// caller interrupts it.
+type PaceMetrics struct {
+	// TurnGaps counts replies that followed the caller, and AvgTurnGap is
+	// the mean silence between the caller stopping and the reply starting.
+	TurnGaps     int           `json:"turn_gaps"`
+	TurnGapTotal time.Duration `json:"turn_gap_total"`
+	AvgTurnGap   time.Duration `json:"avg_turn_gap"`
+
+	UserTalk time.Duration `json:"user_talk"`
+	BotTalk  time.Duration `json:"bot_talk"`
+	// Overlap is the time both talked at once, and OverlapRatio its share
+	// of the time either of them was talking.
+	Overlap      time.Duration `json:"overlap"`
+	OverlapRatio float64       `json:"overlap_ratio"`
+
+	// Monologues counts stretches of bot audio without the caller getting
+	// a word in.
+	Monologues       int           `json:"monologues"`
+	AvgMonologue     time.Duration `json:"avg_monologue"`
+	LongestMonologue time.Duration `json:"longest_monologue"`
+}
+
+// Add combines the metrics of another session, to compare pace across
+// many calls.
+func (p *PaceMetrics) Add(other PaceMetrics) {
+	p.TurnGaps += other.TurnGaps
+	p.TurnGapTotal += other.TurnGapTotal
+	p.UserTalk += other.UserTalk
+	p.BotTalk += other.BotTalk
+	p.Overlap += other.Overlap
+	p.Monologues += other.Monologues
+	p.LongestMonologue = max(p.LongestMonologue, other.LongestMonologue)
+	p.derive()
+}
+
+func (p *PaceMetrics) derive() {
+	p.AvgTurnGap, p.AvgMonologue, p.OverlapRatio = 0, 0, 0
+	if p.TurnGaps > 0 {
+		p.AvgTurnGap = p.TurnGapTotal / time.Duration(p.TurnGaps)
+	}
+	if p.Monologues > 0 {
+		p.AvgMonologue = p.BotTalk / time.Duration(p.Monologues)
+	}
+	if talk := p.UserTalk + p.BotTalk - p.Overlap; talk > 0 {
+		p.OverlapRatio = float64(p.Overlap) / float64(talk)
+	}
+}
+
+type span struct{ from, until time.Time }
+
+func (s span) overlap(from, until time.Time) time.Duration {
+	if s.from.IsZero() {
+		return 0
+	}
+	if s.until.Before(until) {
+		until = s.until
+	}
+	if s.from.After(from) {
+		from = s.from
+	}
+	if d := until.Sub(from); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// paceTracker accumulates PaceMetrics from the stream's speech transitions.
+type paceTracker struct {
+	PaceMetrics
+	userFrom  time.Time // the caller is talking since
+	userEnded time.Time // the caller stopped, awaiting a reply
+	bot       span      // the bot's current monologue, until its audio runs out
+	prev      span      // the one before
+}
+
+func (p *paceTracker) userStarted(now time.Time) {
+	p.userFrom = now
+	p.userEnded = time.Time{}
+	p.closeBot(now, false)
+}
+
+func (p *paceTracker) userStopped(now time.Time) {
+	if p.userFrom.IsZero() {
+		return
+	}
+	p.closeBot(now, false)
+	p.UserTalk += now.Sub(p.userFrom)
+	p.Overlap += p.prev.overlap(p.userFrom, now) + p.bot.overlap(p.userFrom, now)
+	p.userFrom = time.Time{}
+	p.userEnded = now
+}
+
+// botAudio extends the bot's monologue by d of audio sent at now.
+func (p *paceTracker) botAudio(now time.Time, d time.Duration) {
+	p.closeBot(now, false)
+	if p.bot.from.IsZero() {
+		p.bot = span{now, now}
+		if !p.userEnded.IsZero() {
+			p.TurnGaps++
+			p.TurnGapTotal += now.Sub(p.userEnded)
+			p.userEnded = time.Time{}
+		}
+	}
+	if p.bot.until.Before(now) {
+		p.bot.until = now
+	}
+	p.bot.until = p.bot.until.Add(d)
+}
+
+// botCut stops the bot's audio at now, when the caller interrupts it.
+func (p *paceTracker) botCut(now time.Time) {
+	if !p.bot.from.IsZero() && p.bot.until.After(now) {
+		p.bot.until = now
+	}
+}
+
+// closeBot ends the bot's monologue once its audio has run out and the
+// caller spoke or monologueGap passed, or with force when it can't go on.
+func (p *paceTracker) closeBot(now time.Time, force bool) {
+	if p.bot.from.IsZero() {
+		return
+	}
+	over := !p.bot.until.After(now) && (!p.userFrom.IsZero() || now.Sub(p.bot.until) >= monologueGap)
+	if !force && !over {
+		return
+	}
+	if force {
+		p.botCut(now)
+	}
+	d := p.bot.until.Sub(p.bot.from)
+	p.BotTalk += d
+	p.Monologues++
+	p.LongestMonologue = max(p.LongestMonologue, d)
+	p.prev, p.bot = p.bot, span{}
+}
+
+// end closes whatever is open when the stream closes.
+func (p *paceTracker) end(now time.Time) {
+	p.userStopped(now)
+	p.closeBot(now, true)
+	p.userEnded = time.Time{}
+}
+
+// metrics returns the metrics as of now, counting talk still under way.
+func (p paceTracker) metrics(now time.Time) PaceMetrics {
+	p.end(now)
+	p.derive()
+	return p.PaceMetrics
+}
+
+// GetPace returns the session's turn-taking metrics.
+func (s *ConversationSession) GetPace() PaceMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pace.metrics(time.Now())
+}
+
+func (s *ConversationSession) trackPace(fn func(*paceTracker)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.pace)
+}
+
+// trackBotAudio times n bytes of bot audio sent now.
+func (ms *ManagedStream) trackBotAudio(n int) {
+	playbackRate, _ := ms.sampleRates()
+	d := time.Duration(n/2) * time.Second / time.Duration(playbackRate)
+	ms.session.trackPace(func(p *paceTracker) { p.botAudio(time.Now(), d) })
+}
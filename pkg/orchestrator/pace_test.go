@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPace_Tracker(t *testing.T) {
+	var p paceTracker
+	t0 := time.Now()
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	// The caller talks for a second; the reply starts 600ms later and is
+	// sent faster than it plays, two seconds of it.
+	p.userStarted(at(0))
+	p.userStopped(at(1000))
+	p.botAudio(at(1600), time.Second)
+	p.botAudio(at(1700), time.Second)
+
+	// The caller barges in half a second before the end, and the bot stops
+	// 200ms later.
+	p.userStarted(at(3100))
+	p.botCut(at(3300))
+	p.userStopped(at(4100))
+
+	// The answer to the barge-in comes 1.9s later, and the session ends.
+	p.botAudio(at(6000), 500*time.Millisecond)
+
+	m := p.metrics(at(7000))
+	if m.TurnGaps != 2 || m.AvgTurnGap != 1250*time.Millisecond {
+		t.Errorf("turn gaps: %d averaging %v", m.TurnGaps, m.AvgTurnGap)
+	}
+	if m.UserTalk != 2*time.Second || m.BotTalk != 2200*time.Millisecond {
+		t.Errorf("talk: user %v, bot %v", m.UserTalk, m.BotTalk)
+	}
+	if m.Overlap != 200*time.Millisecond {
+		t.Errorf("overlap %v", m.Overlap)
+	}
+	if want := 0.2 / 4.0; m.OverlapRatio < want-1e-9 || m.OverlapRatio > want+1e-9 {
+		t.Errorf("overlap ratio %v, want %v", m.OverlapRatio, want)
+	}
+	if m.Monologues != 2 || m.LongestMonologue != 1700*time.Millisecond || m.AvgMonologue != 1100*time.Millisecond {
+		t.Errorf("monologues: %d, longest %v, average %v", m.Monologues, m.LongestMonologue, m.AvgMonologue)
+	}
+}
+
+func TestPace_StallIsOneMonologue(t *testing.T) {
+	var p paceTracker
+	t0 := time.Now()
+	p.botAudio(t0, time.Second)
+	p.botAudio(t0.Add(1200*time.Millisecond), time.Second)
+	if m := p.metrics(t0.Add(5 * time.Second)); m.Monologues != 1 || m.BotTalk != 2200*time.Millisecond {
+		t.Errorf("a 200ms stall split the reply: %+v", m)
+	}
+}
+
+func TestPace_Snapshot(t *testing.T) {
+	s := NewConversationSession("pace")
+	now := time.Now()
+	s.trackPace(func(p *paceTracker) {
+		p.userStarted(now.Add(-3 * time.Second))
+		p.userStopped(now.Add(-2 * time.Second))
+		p.botAudio(now.Add(-1500*time.Millisecond), time.Second)
+	})
+
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap SessionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	restored := RestoreSession(snap).GetPace()
+	if restored != s.GetPace() || restored.TurnGaps != 1 || restored.Monologues != 1 {
+		t.Errorf("restored %+v, had %+v", restored, s.GetPace())
+	}
+
+	var total PaceMetrics
+	total.Add(restored)
+	total.Add(restored)
+	if total.TurnGaps != 2 || total.AvgTurnGap != restored.AvgTurnGap || total.OverlapRatio != 0 {
+		t.Errorf("combined %+v", total)
+	}
+}
@@ -0,0 +1,78 @@
+package orchestrator
+
+// Pause stops listening: inbound audio is dropped (and not recorded) and the
+// utterance in progress, if any, is discarded without being answered. The
+// bot can still speak, e.g. hold announcements. Use it for hold music and
+// compliance pauses.
+func (ms *ManagedStream) Pause() {
+	ms.mu.Lock()
+	if ms.paused || ms.isClosed {
+		ms.mu.Unlock()
+		return
+	}
+	ms.paused = true
+	ms.resetListeningLocked()
+	ms.mu.Unlock()
+
+	ms.emit(ListeningPaused, nil)
+}
+
+// Resume starts listening again after Pause, from a clean VAD and STT state.
+func (ms *ManagedStream) Resume() {
+	ms.mu.Lock()
+	if !ms.paused || ms.isClosed {
+		ms.mu.Unlock()
+		return
+	}
+	ms.paused = false
+	ms.resetListeningLocked()
+	ms.mu.Unlock()
+
+	ms.emit(ListeningResumed, nil)
+}
+
+func (ms *ManagedStream) Paused() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.paused
+}
+
+// SetMicMuted replaces inbound audio with silence while muted, as a muted
+// microphone would: an utterance in progress ends and is answered as usual.
+// It suits push-to-talk UIs.
+func (ms *ManagedStream) SetMicMuted(muted bool) {
+	ms.mu.Lock()
+	ms.micMuted = muted
+	ms.mu.Unlock()
+}
+
+func (ms *ManagedStream) MicMuted() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.micMuted
+}
+
+// resetListeningLocked abandons the current utterance: buffered audio, the
+// streaming STT session and VAD state. It must be called with ms.mu held.
+func (ms *ManagedStream) resetListeningLocked() {
+	ms.sttGeneration++
+	if ms.pipelineCancel != nil {
+		ms.pipelineCancel()
+		ms.pipelineCancel = nil
+	}
+	// Not closed: that would finalize a transcript, and the write loop may
+	// still be sending to it.
+	ms.sttChan = nil
+	if ms.holdCancel != nil {
+		ms.holdCancel()
+		ms.holdCancel = nil
+	}
+	ms.sttDeferred = false
+	ms.userInterrupting = false
+	ms.userTurnOpen = false
+	ms.audioBuf.Reset()
+	ms.lastUserAudio = nil
+	if ms.vad != nil {
+		ms.vad.Reset()
+	}
+}
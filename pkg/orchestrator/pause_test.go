@@ -0,0 +1,75 @@
+package orchestrator
+
+import (
+	"testing"
+)
+
+func pauseStream(t *testing.T) *ManagedStream {
+	t.Helper()
+	return newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{})
+}
+
+// heardSpeech reports whether any of the drained events is UserSpeaking.
+func heardSpeech(ms *ManagedStream) bool {
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == UserSpeaking {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+func TestPause_DropsAudioUntilResumed(t *testing.T) {
+	ms := pauseStream(t)
+	ms.doWrite(toneChunk(1000))
+
+	ms.Pause()
+	waitForEvent(t, ms, ListeningPaused)
+	if ms.State() != StreamPaused || ms.audioBuf.Len() != 0 {
+		t.Fatalf("state %s with %d bytes buffered", ms.State(), ms.audioBuf.Len())
+	}
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	if heardSpeech(ms) {
+		t.Error("a paused stream must not detect speech")
+	}
+	if n := ms.Status().Stats.AudioChunksDropped; n != 5 {
+		t.Errorf("expected 5 dropped chunks, got %d", n)
+	}
+
+	ms.Resume()
+	waitForEvent(t, ms, ListeningResumed)
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	if !heardSpeech(ms) {
+		t.Error("speech must be detected after Resume")
+	}
+}
+
+func TestSetMicMuted_FeedsSilence(t *testing.T) {
+	ms := pauseStream(t)
+	ms.SetMicMuted(true)
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	if heardSpeech(ms) {
+		t.Error("a muted mic must not be heard")
+	}
+	if !ms.Status().MicMuted || ms.Status().Stats.AudioBytesIn == 0 {
+		t.Errorf("unexpected status %+v", ms.Status())
+	}
+
+	ms.SetMicMuted(false)
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	if !heardSpeech(ms) {
+		t.Error("speech must be detected after unmuting")
+	}
+}
@@ -0,0 +1,129 @@
+package orchestrator
+
+import (
+	"bytes"
+	"time"
+)
+
+// maxUnplayedAudio bounds the bot audio held for a client that acks
+// playback, about 45 seconds at 44.1kHz.
+const maxUnplayedAudio = 4 << 20
+
+// PlaybackAck reports how far a remote client has played a turn's audio:
+// Bytes counts the turn's AudioChunk bytes played so far.
+type PlaybackAck struct {
+	TurnID string `json:"turn_id,omitempty"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// playbackState tracks bot audio emitted to a remote client but not yet
+// acknowledged as played.
+type playbackState struct {
+	enabled     bool
+	remote      bool // the client sends its own echo reference
+	turnID      string
+	pending     bytes.Buffer
+	base        int64 // turn offset of the first pending byte
+	played      int64 // turn bytes acked as played
+	firstPlayed time.Time
+}
+
+// EnableClientPlayback makes the stream rely on AckPlayback instead of
+// emission time: bot audio only enters the echo reference once the client
+// reports it played, and UserToPlay is measured to the first ack. Transports
+// call it when the remote client sends acks.
+func (ms *ManagedStream) EnableClientPlayback() {
+	ms.mu.Lock()
+	ms.playback.enabled = true
+	ms.mu.Unlock()
+}
+
+// AckPlayback records a client's playback progress. It enables client
+// playback if needed and, like NotifyAudioPlayed, marks the bot as audible.
+func (ms *ManagedStream) AckPlayback(ack PlaybackAck) {
+	now := time.Now()
+	ms.mu.Lock()
+	p := &ms.playback
+	p.enabled = true
+	ms.lastAudioSentAt = now
+	ms.lastAudioEmittedAt = now
+	// Acks for an earlier turn only say the bot is still audible.
+	if ack.TurnID != "" && ack.TurnID != p.turnID {
+		ms.mu.Unlock()
+		return
+	}
+
+	p.played = max(p.played, ack.Bytes)
+	var played []byte
+	if n := ack.Bytes - p.base; n > 0 {
+		played = bytes.Clone(p.pending.Next(int(min(n, int64(p.pending.Len())))))
+		p.base += int64(len(played))
+	}
+	turnID, userToPlay := p.turnID, int64(-1)
+	started := len(played) > 0 || (p.remote && ack.Bytes > 0)
+	if started && p.firstPlayed.IsZero() {
+		p.firstPlayed = now
+		if !ms.userSpeechEndTime.IsZero() {
+			userToPlay = now.Sub(ms.userSpeechEndTime).Milliseconds()
+		}
+	}
+	ms.mu.Unlock()
+
+	ms.RecordPlayedOutput(played)
+	// The turn's latency may already be final when the first ack arrives.
+	if userToPlay >= 0 {
+		ms.updateTurn(turnID, func(t *Turn) { t.Latency.UserToPlay = userToPlay })
+	}
+}
+
+// trackUnplayedLocked holds an emitted chunk until the client acks it. It
+// must be called with ms.mu held.
+func (ms *ManagedStream) trackUnplayedLocked(turnID string, chunk []byte) {
+	p := &ms.playback
+	if !p.enabled {
+		return
+	}
+	if turnID != p.turnID {
+		p.turnID = turnID
+		p.pending.Reset()
+		p.base = 0
+		p.played = 0
+		p.firstPlayed = time.Time{}
+	}
+	if p.remote {
+		return
+	}
+	p.pending.Write(chunk)
+	if over := p.pending.Len() - maxUnplayedAudio; over > 0 {
+		p.pending.Next(over)
+		p.base += int64(over)
+	}
+}
+
+// RecordRemotePlayback adds audio the client's speaker actually played to the
+// echo reference, for deployments where mic and speaker are on the far end.
+// Clients send it right before the mic audio captured at the same time, so
+// the reference always precedes its echo. Once called, acks and emitted audio
+// no longer feed the reference. Silent frames keep the reference aligned
+// without marking the bot as audible.
+func (ms *ManagedStream) RecordRemotePlayback(pcm []byte) {
+	if len(pcm) == 0 {
+		return
+	}
+	audible := false
+	for _, b := range pcm {
+		if b != 0 {
+			audible = true
+			break
+		}
+	}
+	ms.mu.Lock()
+	ms.playback.remote = true
+	ms.playback.pending.Reset()
+	if audible {
+		ms.lastAudioSentAt = time.Now()
+		ms.lastAudioEmittedAt = ms.lastAudioSentAt
+	}
+	ms.mu.Unlock()
+	ms.RecordPlayedOutput(pcm)
+}
@@ -0,0 +1,103 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// referenceEcho records what enters the echo reference.
+type referenceEcho struct {
+	mu     sync.Mutex
+	played int
+}
+
+func (e *referenceEcho) Name() string { return "reference" }
+func (e *referenceEcho) RecordPlayed(chunk []byte) {
+	e.mu.Lock()
+	e.played += len(chunk)
+	e.mu.Unlock()
+}
+func (e *referenceEcho) Process(chunk, history []byte) ([]byte, bool) { return chunk, false }
+func (e *referenceEcho) Reset()                                       {}
+
+func (e *referenceEcho) total() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.played
+}
+
+func TestAckPlayback_FeedsEchoReferenceAndLatency(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 4000)}, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("remote"))
+	defer ms.Close()
+	echo := &referenceEcho{}
+	ms.SetEchoProcessor(echo)
+	ms.EnableClientPlayback()
+
+	ms.mu.Lock()
+	ms.userSpeechEndTime = time.Now().Add(-time.Second)
+	ms.mu.Unlock()
+	ms.speakText(ms.ctx, "Hello.")
+	if echo.total() != 0 {
+		t.Fatalf("unplayed audio entered the echo reference: %d bytes", echo.total())
+	}
+
+	turnID := ms.TurnID()
+	ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: 1000})
+	ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: 1000})
+	if got := echo.total(); got != 1000 {
+		t.Errorf("expected 1000 played bytes, got %d", got)
+	}
+	ms.AckPlayback(PlaybackAck{TurnID: "stale", Bytes: 4000})
+	ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: 9000})
+	if got := echo.total(); got != 4000 {
+		t.Errorf("expected the whole reply once, got %d bytes", got)
+	}
+
+	if bd := ms.GetLatencyBreakdown(); bd.UserToPlay < 1000 {
+		t.Errorf("UserToPlay must be measured to the first ack, got %dms", bd.UserToPlay)
+	}
+}
+
+func TestRecordRemotePlayback_ReplacesAckReference(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 4000)}, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("far-end"))
+	defer ms.Close()
+	echo := &referenceEcho{}
+	ms.SetEchoProcessor(echo)
+	ms.EnableClientPlayback()
+
+	ms.RecordRemotePlayback(make([]byte, 320))
+	if echo.total() != 320 {
+		t.Fatalf("expected the silent frame in the reference, got %d bytes", echo.total())
+	}
+	ms.mu.Lock()
+	audible := !ms.lastAudioSentAt.IsZero()
+	ms.mu.Unlock()
+	if audible {
+		t.Error("silence must not mark the bot as audible")
+	}
+
+	ms.mu.Lock()
+	ms.userSpeechEndTime = time.Now().Add(-time.Second)
+	ms.mu.Unlock()
+	ms.speakText(ms.ctx, "Hello.")
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 2000})
+	if echo.total() != 320 {
+		t.Errorf("acks must not feed the reference once the client sends it, got %d bytes", echo.total())
+	}
+	if bd := ms.GetLatencyBreakdown(); bd.UserToPlay < 1000 {
+		t.Errorf("UserToPlay must still be measured to the first ack, got %dms", bd.UserToPlay)
+	}
+
+	ms.RecordRemotePlayback([]byte{0, 10, 0, 10})
+	if echo.total() != 324 {
+		t.Errorf("expected the played frame in the reference, got %d bytes", echo.total())
+	}
+}
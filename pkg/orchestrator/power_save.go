@@ -0,0 +1,111 @@
+package orchestrator
+
+import "time"
+
+const (
+	defaultPowerSaveThreshold = 0.02
+	defaultPowerSaveSustain   = 150 * time.Millisecond
+	defaultPowerSaveIdleAfter = 10 * time.Second
+	powerSavePreroll          = 500 * time.Millisecond
+)
+
+// PowerSaveConfig cuts CPU and provider cost on mostly idle streams, such as
+// kiosks. Streams start in low power, and return to it after IdleAfter (10s
+// by default) with nothing going on. In low power only a cheap energy gate
+// looks at the audio: no echo processing, VAD or STT. Once the audio has
+// stayed above Threshold RMS (0.02 by default) for Sustain (150ms), the
+// stream wakes up and processes it from half a second back, so the start of
+// the utterance isn't lost.
+type PowerSaveConfig struct {
+	Enabled   bool
+	Threshold float64
+	Sustain   time.Duration
+	IdleAfter time.Duration
+}
+
+// LowPower reports whether the stream is in low power.
+func (ms *ManagedStream) LowPower() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.lowPower
+}
+
+// writeLowPower gates chunk on its energy while the stream is in low power,
+// and reports whether it did; on waking, the buffered audio is processed as
+// usual. Awake streams go to low power once they have been idle long enough.
+func (ms *ManagedStream) writeLowPower(chunk []byte) (bool, error) {
+	if ms.orch == nil {
+		return false, nil
+	}
+	cfg := ms.orch.GetConfig().PowerSave
+	if !cfg.Enabled {
+		return false, nil
+	}
+	threshold, sustain, idleAfter := cfg.Threshold, cfg.Sustain, cfg.IdleAfter
+	if threshold <= 0 {
+		threshold = defaultPowerSaveThreshold
+	}
+	if sustain <= 0 {
+		sustain = defaultPowerSaveSustain
+	}
+	if idleAfter <= 0 {
+		idleAfter = defaultPowerSaveIdleAfter
+	}
+	_, inputRate := ms.sampleRates()
+	now := time.Now()
+
+	ms.mu.Lock()
+	if !ms.lowPower {
+		if ms.stateLocked() != StreamIdle {
+			ms.powerActiveAt = now
+		}
+		if now.Sub(ms.powerActiveAt) < idleAfter {
+			ms.mu.Unlock()
+			return false, nil
+		}
+		ms.lowPower = true
+		if ms.vad != nil {
+			ms.vad.Reset()
+		}
+		ms.mu.Unlock()
+		ms.emit(PowerSaveStarted, nil)
+		ms.mu.Lock()
+	}
+
+	ms.stats.LowPowerChunks++
+	ms.powerPreroll = append(ms.powerPreroll, append([]byte(nil), chunk...))
+	size := 0
+	for _, c := range ms.powerPreroll {
+		size += len(c)
+	}
+	limit := inputRate * 2 * int(powerSavePreroll/time.Millisecond) / 1000
+	for size-len(ms.powerPreroll[0]) >= limit {
+		size -= len(ms.powerPreroll[0])
+		ms.powerPreroll = ms.powerPreroll[1:]
+	}
+	if pcmRMS(chunk) >= threshold {
+		ms.powerLoud += time.Duration(len(chunk)/2) * time.Second / time.Duration(inputRate)
+	} else {
+		ms.powerLoud = 0
+	}
+	// The bot may start talking on its own, e.g. with Say; barge-ins need
+	// the full pipeline.
+	if ms.powerLoud < sustain && ms.stateLocked() == StreamIdle {
+		ms.mu.Unlock()
+		return true, nil
+	}
+	preroll := ms.powerPreroll
+	ms.lowPower = false
+	ms.powerPreroll = nil
+	ms.powerLoud = 0
+	ms.powerActiveAt = now
+	ms.mu.Unlock()
+
+	ms.emit(PowerSaveEnded, nil)
+	for _, c := range preroll {
+		if err := ms.listen(c); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
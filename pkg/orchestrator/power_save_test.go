@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func newPowerSaveStream(t *testing.T, idleAfter time.Duration) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.PowerSave = PowerSaveConfig{Enabled: true, IdleAfter: idleAfter}
+	return newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "where is the exit"}, &MockLLMProvider{completeResult: "To your left."})
+}
+
+func TestPowerSave_WakesOnSustainedSpeech(t *testing.T) {
+	ms := newPowerSaveStream(t, time.Minute)
+	if !ms.LowPower() {
+		t.Fatal("expected the stream to start in low power")
+	}
+
+	// A knock is too short to wake the stream.
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	for i := 0; i < 10; i++ {
+		ms.doWrite(make([]byte, 882))
+	}
+	if !ms.LowPower() {
+		t.Fatal("expected a short noise to be ignored")
+	}
+	if n := ms.Status().Stats.LowPowerChunks; n != 15 {
+		t.Errorf("expected 15 gated chunks, got %d", n)
+	}
+
+	speakTo(ms)
+	waitForEvent(t, ms, PowerSaveEnded)
+	if ev := waitForEvent(t, ms, TranscriptFinal); ev.Data != "where is the exit" {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	if ms.LowPower() {
+		t.Error("expected the stream to stay awake")
+	}
+}
+
+func TestPowerSave_ReturnsToLowPowerWhenIdle(t *testing.T) {
+	ms := newPowerSaveStream(t, 50*time.Millisecond)
+	speakTo(ms)
+	waitForEvent(t, ms, BotResponse)
+	for ms.State() != StreamIdle {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ms.doWrite(make([]byte, 882))
+	time.Sleep(60 * time.Millisecond)
+	ms.doWrite(make([]byte, 882))
+	waitForEvent(t, ms, PowerSaveStarted)
+	if !ms.Status().LowPower {
+		t.Error("expected the status to report low power")
+	}
+}
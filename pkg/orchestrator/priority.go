@@ -0,0 +1,186 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Priority orders sessions when the orchestrator is under pressure. The zero
+// value is PriorityNormal.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// PriorityConfig controls how sessions are degraded under load. The
+// orchestrator is under pressure when admitted sessions reach LoadThreshold
+// of Capacity.MaxSessions, for RateLimitCooldown after an LLM rate limit, or
+// whenever Pressure reports true (e.g. for CPU load). Sessions below
+// DegradeBelow are then served by DegradedLLM with DegradedParams.
+type PriorityConfig struct {
+	DegradeBelow      Priority
+	DegradedLLM       LLMProvider
+	DegradedParams    GenerationParams
+	LoadThreshold     float64
+	RateLimitCooldown time.Duration
+	Pressure          func() bool
+	// MaxConcurrentLLM bounds in-flight LLM calls. Waiting calls are served
+	// highest priority first. Zero means unbounded.
+	MaxConcurrentLLM int
+}
+
+func (s *ConversationSession) SetPriority(p Priority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priority = p
+}
+
+func (s *ConversationSession) GetPriority() Priority {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.priority
+}
+
+// UnderPressure reports whether lower-priority sessions are being degraded.
+func (o *Orchestrator) UnderPressure() bool {
+	cfg := o.GetConfig()
+	if cfg.Priority.Pressure != nil && cfg.Priority.Pressure() {
+		return true
+	}
+
+	o.pressureMu.Lock()
+	rateLimited := time.Now().Before(o.rateLimitedUntil)
+	o.pressureMu.Unlock()
+	if rateLimited {
+		return true
+	}
+
+	if cfg.Capacity.MaxSessions > 0 {
+		threshold := cfg.Priority.LoadThreshold
+		if threshold <= 0 {
+			threshold = 0.8
+		}
+		c := o.Capacity()
+		return float64(c.Active)/float64(c.MaxSessions) >= threshold || c.Queued > 0
+	}
+	return false
+}
+
+func (o *Orchestrator) isDegraded(session *ConversationSession) bool {
+	return session.GetPriority() < o.GetConfig().Priority.DegradeBelow && o.UnderPressure()
+}
+
+// llmFor picks the provider and parameters for the session's next turn.
+func (o *Orchestrator) llmFor(session *ConversationSession) (LLMProvider, GenerationParams) {
+	params := o.generationParams(session)
+	if !o.isDegraded(session) {
+		return o.llm, params
+	}
+	cfg := o.GetConfig().Priority
+	llm := o.llm
+	if cfg.DegradedLLM != nil {
+		llm = cfg.DegradedLLM
+	}
+	return llm, params.Merge(cfg.DegradedParams)
+}
+
+// noteLLMError starts the rate-limit cooldown when a provider reports 429
+// with a ProviderError.
+func (o *Orchestrator) noteLLMError(err error) {
+	var perr *ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	cooldown := o.GetConfig().Priority.RateLimitCooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	o.pressureMu.Lock()
+	o.rateLimitedUntil = time.Now().Add(cooldown)
+	o.pressureMu.Unlock()
+	o.logger.Warn("LLM rate limited, degrading low-priority sessions", "cooldown", cooldown)
+}
+
+// priorityGate is a semaphore whose waiters are woken highest priority
+// first, then in arrival order.
+type priorityGate struct {
+	mu      sync.Mutex
+	active  int
+	waiters []*gateWaiter
+}
+
+type gateWaiter struct {
+	priority Priority
+	ready    chan struct{}
+}
+
+func (g *priorityGate) acquire(ctx context.Context, limit int, p Priority) error {
+	g.mu.Lock()
+	if g.active < limit && len(g.waiters) == 0 {
+		g.active++
+		g.mu.Unlock()
+		return nil
+	}
+	w := &gateWaiter{priority: p, ready: make(chan struct{})}
+	i := len(g.waiters)
+	for i > 0 && g.waiters[i-1].priority < p {
+		i--
+	}
+	g.waiters = append(g.waiters, nil)
+	copy(g.waiters[i+1:], g.waiters[i:])
+	g.waiters[i] = w
+	g.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for i, queued := range g.waiters {
+			if queued == w {
+				g.waiters = append(g.waiters[:i], g.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// Granted while giving up: pass the slot on.
+		g.releaseLocked()
+		return ctx.Err()
+	}
+}
+
+func (g *priorityGate) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.releaseLocked()
+}
+
+func (g *priorityGate) releaseLocked() {
+	if len(g.waiters) > 0 {
+		w := g.waiters[0]
+		g.waiters = g.waiters[1:]
+		close(w.ready)
+		return
+	}
+	if g.active > 0 {
+		g.active--
+	}
+}
+
+// acquireLLM waits for an LLM slot when MaxConcurrentLLM is set.
+func (o *Orchestrator) acquireLLM(ctx context.Context, session *ConversationSession) (func(), error) {
+	limit := o.GetConfig().Priority.MaxConcurrentLLM
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	if err := o.llmGate.acquire(ctx, limit, session.GetPriority()); err != nil {
+		return nil, err
+	}
+	return o.llmGate.release, nil
+}
@@ -0,0 +1,164 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type paramsLLM struct {
+	MockLLMProvider
+	params GenerationParams
+}
+
+func (p *paramsLLM) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	p.params = params
+	return p.completeResult, p.completeErr
+}
+
+func TestPriority_DegradesLowPrioritySessionsUnderPressure(t *testing.T) {
+	main := &MockLLMProvider{completeResult: "full"}
+	small := &paramsLLM{MockLLMProvider: MockLLMProvider{completeResult: "small"}}
+	pressure := false
+
+	cfg := DefaultConfig()
+	cfg.Priority = PriorityConfig{
+		DegradedLLM:    small,
+		DegradedParams: GenerationParams{MaxTokens: 64},
+		Pressure:       func() bool { return pressure },
+	}
+	orch := New(&MockSTTProvider{}, main, &MockTTSProvider{}, cfg)
+
+	low := orch.NewSessionWithDefaults("low")
+	low.SetPriority(PriorityLow)
+	low.AddMessage("user", "hi")
+	normal := orch.NewSessionWithDefaults("normal")
+	normal.AddMessage("user", "hi")
+
+	if got, _ := orch.GenerateResponse(context.Background(), low); got != "full" {
+		t.Errorf("expected no degradation without pressure, got %q", got)
+	}
+
+	pressure = true
+	if got, _ := orch.GenerateResponse(context.Background(), low); got != "small" || small.params.MaxTokens != 64 {
+		t.Errorf("expected low priority to use the degraded model, got %q %+v", got, small.params)
+	}
+	if got, _ := orch.GenerateResponse(context.Background(), normal); got != "full" {
+		t.Errorf("expected normal priority to keep the full model, got %q", got)
+	}
+}
+
+func TestPriority_RateLimitStartsPressure(t *testing.T) {
+	llm := &MockLLMProvider{completeErr: errors.New("rate limit in the message is not enough")}
+	cfg := DefaultConfig()
+	cfg.Priority.RateLimitCooldown = time.Minute
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, cfg)
+
+	if orch.UnderPressure() {
+		t.Fatal("expected no pressure initially")
+	}
+	session := orch.NewSessionWithDefaults("s")
+	session.AddMessage("user", "hi")
+	orch.GenerateResponse(context.Background(), session)
+	if orch.UnderPressure() {
+		t.Fatal("expected only a typed 429 to put the orchestrator under pressure")
+	}
+
+	llm.completeErr = fmt.Errorf("complete: %w", &ProviderError{Provider: "groq api", StatusCode: http.StatusTooManyRequests})
+	orch.GenerateResponse(context.Background(), session)
+	if !orch.UnderPressure() {
+		t.Error("expected a 429 to put the orchestrator under pressure")
+	}
+}
+
+func TestPriority_LoadThreshold(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 2})
+	cfg := orch.GetConfig()
+	cfg.Priority.LoadThreshold = 0.5
+	orch.UpdateConfig(cfg)
+
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("a"))
+	defer stream.Close()
+	if !orch.UnderPressure() {
+		t.Error("expected half capacity to count as pressure at threshold 0.5")
+	}
+}
+
+func TestPriority_CapacityQueueOrder(t *testing.T) {
+	orch := capacityOrchestrator(CapacityConfig{MaxSessions: 1, QueueTimeout: 5 * time.Second})
+
+	first := orch.NewManagedStream(context.Background(), NewConversationSession("first"))
+
+	lowSession := NewConversationSession("low")
+	lowSession.SetPriority(PriorityLow)
+	low := orch.NewManagedStream(context.Background(), lowSession)
+	defer low.Close()
+	nextBusy(t, low)
+
+	highSession := NewConversationSession("high")
+	highSession.SetPriority(PriorityHigh)
+	high := orch.NewManagedStream(context.Background(), highSession)
+	defer high.Close()
+	if info := nextBusy(t, high); info.Position != 1 {
+		t.Errorf("expected high priority to jump the queue, got position %d", info.Position)
+	}
+
+	first.Close()
+	if info := nextBusy(t, high); info.Status != ServerBusyAdmitted {
+		t.Errorf("expected high priority to be admitted first, got %+v", info)
+	}
+	if low.State() != StreamQueued {
+		t.Errorf("expected low priority to keep waiting, got %s", low.State())
+	}
+}
+
+func TestPriorityGate(t *testing.T) {
+	var g priorityGate
+	ctx := context.Background()
+	if err := g.acquire(ctx, 1, PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan Priority, 2)
+	started := make(chan struct{}, 2)
+	for _, p := range []Priority{PriorityLow, PriorityHigh} {
+		p := p
+		go func() {
+			started <- struct{}{}
+			g.acquire(ctx, 1, p)
+			order <- p
+			g.release()
+		}()
+		<-started
+		// Let the waiter enqueue before the next one arrives.
+		for {
+			g.mu.Lock()
+			n := len(g.waiters)
+			g.mu.Unlock()
+			if n > 0 && (p == PriorityLow || n > 1) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	g.release()
+	if first := <-order; first != PriorityHigh {
+		t.Errorf("expected high priority first, got %d", first)
+	}
+	<-order
+
+	cctx, cancel := context.WithCancel(ctx)
+	g.acquire(ctx, 1, PriorityNormal)
+	cancel()
+	if err := g.acquire(cctx, 1, PriorityHigh); err == nil {
+		t.Error("expected cancelled acquire to fail")
+	}
+	g.release()
+	if g.active != 0 || len(g.waiters) != 0 {
+		t.Errorf("gate leaked: active=%d waiters=%d", g.active, len(g.waiters))
+	}
+}
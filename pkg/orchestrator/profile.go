@@ -0,0 +1,175 @@
+package orchestrator
+
+import "time"
+
+// Names of the built-in tuning profiles.
+const (
+	// ProfileMacOSLaptop is a laptop's built-in mic and speakers: loud echo
+	// arriving up to a second late through CoreAudio.
+	ProfileMacOSLaptop = "macos_laptop"
+	// ProfileUSBHeadset is a wired headset, with no echo to speak of.
+	ProfileUSBHeadset = "usb_headset"
+	// ProfileAndroidWebRTC is an Android app over WebRTC: 48kHz Opus,
+	// with the platform's echo canceller leaving some residue.
+	ProfileAndroidWebRTC = "android_webrtc"
+	// ProfilePSTN is a phone call: 8kHz audio, line noise and network echo.
+	ProfilePSTN = "pstn"
+)
+
+// Profile is a tuning of the VAD, echo handling and endpointing for a kind of
+// device. Select one by name with Config.Profile. A profile only changes
+// settings still at their defaults, so those set explicitly win.
+type Profile struct {
+	Name string
+	// SampleRate is the rate the device runs at; zero keeps Config's.
+	SampleRate int
+	// VADThreshold becomes Config.BargeInVADThreshold.
+	VADThreshold float64
+	// VADSilence is how long the caller must be quiet to end an utterance.
+	VADSilence time.Duration
+	// VADFrame, when set, makes the VAD judge frames of this length
+	// (NewFramedRMSVAD).
+	VADFrame time.Duration
+	// TrailWindow becomes Config.BargeInVADTrailWindow.
+	TrailWindow time.Duration
+	Echo        EchoConfig
+	STTGate     STTGateConfig
+	BargeIn     BargeInConfig
+	Endpointing EndpointingConfig
+}
+
+var profiles = map[string]Profile{
+	ProfileMacOSLaptop: {
+		Name:         ProfileMacOSLaptop,
+		VADThreshold: 0.012,
+		VADSilence:   700 * time.Millisecond,
+		TrailWindow:  2 * time.Second,
+		Echo: EchoConfig{
+			Strategy:        EchoStrategyCorrelation,
+			Action:          EchoDrop,
+			Threshold:       0.75,
+			ReferenceBuffer: 3 * time.Second,
+			SearchWindow:    2 * time.Second,
+		},
+		STTGate:     STTGateConfig{PauseDuringBotSpeech: true, BargeInRMS: 0.05},
+		BargeIn:     BargeInConfig{TwoStage: true, ResumeAfterFalse: true},
+		Endpointing: EndpointingConfig{Enabled: true, MaxHold: 1200 * time.Millisecond},
+	},
+	ProfileUSBHeadset: {
+		Name:         ProfileUSBHeadset,
+		VADThreshold: 0.005,
+		VADSilence:   500 * time.Millisecond,
+		TrailWindow:  300 * time.Millisecond,
+		Echo:         EchoConfig{Strategy: EchoStrategyNone},
+		Endpointing:  EndpointingConfig{Enabled: true, MaxHold: 800 * time.Millisecond},
+	},
+	ProfileAndroidWebRTC: {
+		Name:         ProfileAndroidWebRTC,
+		SampleRate:   48000,
+		VADThreshold: 0.008,
+		VADSilence:   600 * time.Millisecond,
+		VADFrame:     20 * time.Millisecond,
+		TrailWindow:  time.Second,
+		Echo: EchoConfig{
+			Strategy:     EchoStrategyCorrelation,
+			Threshold:    0.85,
+			SearchWindow: time.Second,
+		},
+		BargeIn:     BargeInConfig{TwoStage: true},
+		Endpointing: EndpointingConfig{Enabled: true, MaxHold: time.Second},
+	},
+	ProfilePSTN: {
+		Name:         ProfilePSTN,
+		SampleRate:   8000,
+		VADThreshold: 0.02,
+		VADSilence:   800 * time.Millisecond,
+		VADFrame:     20 * time.Millisecond,
+		TrailWindow:  time.Second,
+		Echo: EchoConfig{
+			Strategy:     EchoStrategyCorrelation,
+			Action:       EchoDrop,
+			Threshold:    0.8,
+			SearchWindow: 800 * time.Millisecond,
+		},
+		BargeIn:     BargeInConfig{TwoStage: true, ResumeAfterFalse: true},
+		Endpointing: EndpointingConfig{Enabled: true, MaxHold: 1500 * time.Millisecond},
+	},
+}
+
+// LookupProfile returns the built-in profile called name.
+func LookupProfile(name string) (Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// Apply returns cfg tuned by the profile, leaving settings that differ from
+// DefaultConfig alone.
+func (p Profile) Apply(cfg Config) Config {
+	def := DefaultConfig()
+	if p.SampleRate > 0 && (cfg.SampleRate == def.SampleRate || cfg.SampleRate == 0) {
+		cfg.SampleRate = p.SampleRate
+	}
+	if p.VADThreshold > 0 && cfg.BargeInVADThreshold == def.BargeInVADThreshold {
+		cfg.BargeInVADThreshold = p.VADThreshold
+	}
+	if p.TrailWindow > 0 && cfg.BargeInVADTrailWindow == def.BargeInVADTrailWindow {
+		cfg.BargeInVADTrailWindow = p.TrailWindow
+	}
+
+	echo := &cfg.Echo
+	if echo.Strategy == "" {
+		echo.Strategy = p.Echo.Strategy
+	}
+	if echo.Action == "" {
+		echo.Action = p.Echo.Action
+	}
+	if echo.Threshold == 0 {
+		echo.Threshold = p.Echo.Threshold
+	}
+	if echo.ReferenceBuffer == 0 {
+		echo.ReferenceBuffer = p.Echo.ReferenceBuffer
+	}
+	if echo.SearchWindow == 0 {
+		echo.SearchWindow = p.Echo.SearchWindow
+	}
+	if cfg.STTGate == (STTGateConfig{}) {
+		cfg.STTGate = p.STTGate
+	}
+	if cfg.BargeIn == (BargeInConfig{}) {
+		cfg.BargeIn = p.BargeIn
+	}
+	if !cfg.Endpointing.Enabled && cfg.Endpointing.MaxHold == 0 && cfg.Endpointing.Detector == nil {
+		cfg.Endpointing = p.Endpointing
+	}
+	return cfg
+}
+
+// NewVAD returns a VAD tuned by the profile for audio at sampleRate.
+func (p Profile) NewVAD(threshold float64, sampleRate int) *RMSVAD {
+	var vad *RMSVAD
+	if p.VADFrame > 0 && sampleRate > 0 {
+		vad = NewFramedRMSVAD(threshold, p.VADSilence, sampleRate, p.VADFrame)
+	} else {
+		vad = NewRMSVAD(threshold, p.VADSilence)
+	}
+	vad.SetMinConfirmed(2)
+	return vad
+}
+
+// applyProfile tunes config by its Profile, and creates a VAD from the
+// profile when none was given.
+func applyProfile(config Config, vad VADProvider, logger Logger) (Config, VADProvider) {
+	if config.Profile == "" {
+		return config, vad
+	}
+	p, ok := LookupProfile(config.Profile)
+	if !ok {
+		logger.Warn("unknown profile", "profile", config.Profile)
+		return config, vad
+	}
+	config = p.Apply(config)
+	if vad == nil {
+		vad = p.NewVAD(config.BargeInVADThreshold, config.SampleRate)
+	}
+	return config, vad
+}
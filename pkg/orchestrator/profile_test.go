@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+type warnLogger struct {
+	NoOpLogger
+	warnings []string
+}
+
+func (l *warnLogger) Warn(msg string, args ...interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+
+func TestProfiles(t *testing.T) {
+	for _, name := range []string{ProfileMacOSLaptop, ProfileUSBHeadset, ProfileAndroidWebRTC, ProfilePSTN} {
+		p, ok := LookupProfile(name)
+		if !ok || p.Name != name {
+			t.Errorf("profile %q is missing", name)
+			continue
+		}
+		if p.VADThreshold <= 0 || p.VADSilence <= 0 || p.TrailWindow <= 0 || p.Echo.Strategy == "" || !p.Endpointing.Enabled {
+			t.Errorf("profile %q leaves settings untuned: %+v", name, p)
+		}
+	}
+}
+
+func TestConfig_Profile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profile = ProfilePSTN
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	got := orch.GetConfig()
+	if got.SampleRate != 8000 || got.BargeInVADThreshold != 0.02 || got.Echo.Action != EchoDrop || !got.Endpointing.Enabled || !got.BargeIn.TwoStage {
+		t.Errorf("the PSTN profile was not applied: %+v", got)
+	}
+	vad, ok := orch.vad.(*RMSVAD)
+	if !ok {
+		t.Fatalf("expected the profile's VAD, got %T", orch.vad)
+	}
+	if vad.frameBytes != 320 || vad.silenceLimit != 800*time.Millisecond {
+		t.Errorf("unexpected VAD framing %d and silence %v", vad.frameBytes, vad.silenceLimit)
+	}
+
+	// Settings made explicitly, and a VAD of the caller's, are kept.
+	cfg = DefaultConfig()
+	cfg.Profile = ProfileMacOSLaptop
+	cfg.BargeInVADThreshold = 0.03
+	cfg.Echo.Action = EchoTag
+	cfg.Endpointing.MaxHold = 2 * time.Second
+	own := NewRMSVAD(0.03, time.Second)
+	orch = NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, own, cfg)
+	got = orch.GetConfig()
+	if got.BargeInVADThreshold != 0.03 || got.Echo.Action != EchoTag || got.Endpointing.MaxHold != 2*time.Second || got.Endpointing.Enabled {
+		t.Errorf("the profile overrode explicit settings: %+v", got)
+	}
+	if got.Echo.Threshold != 0.75 || got.BargeInVADTrailWindow != 2*time.Second {
+		t.Errorf("the profile's other settings were not applied: %+v", got)
+	}
+	if orch.vad != own {
+		t.Error("the profile replaced the caller's VAD")
+	}
+}
+
+func TestConfig_UnknownProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profile = "walkie_talkie"
+	logger := &warnLogger{}
+	orch := NewWithLogger(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, logger)
+	if got := orch.GetConfig(); got.SampleRate != cfg.SampleRate || got.Echo != cfg.Echo {
+		t.Errorf("an unknown profile changed the config: %+v", got)
+	}
+	if len(logger.warnings) != 1 {
+		t.Errorf("expected a warning, got %v", logger.warnings)
+	}
+}
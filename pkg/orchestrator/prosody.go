@@ -0,0 +1,228 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ProsodyConfig lets the LLM shape how its replies are spoken with light
+// markup: [pause] or [pause 800ms], *stressed words*, and [slow], [fast],
+// [low] or [high] spans closed by [/slow] and so on. The markup is removed
+// from the text kept in the context and emitted in events. TTS providers
+// get it as SpeechSegments (ProsodyTTSProvider) or as SSML
+// (SSMLTTSProvider); other providers only get the pauses.
+type ProsodyConfig struct {
+	Enabled bool
+}
+
+// Prosody shapes how a span of text is spoken. Zero values keep the
+// voice's defaults.
+type Prosody struct {
+	// Rate is the speaking rate relative to normal, e.g. 0.85.
+	Rate float64 `json:"rate,omitempty"`
+	// Pitch is in semitones.
+	Pitch    float64 `json:"pitch,omitempty"`
+	Emphasis bool    `json:"emphasis,omitempty"`
+}
+
+// SpeechSegment is a span of a reply with its prosody, or a pause when
+// Pause is set.
+type SpeechSegment struct {
+	Text    string        `json:"text,omitempty"`
+	Prosody Prosody       `json:"prosody"`
+	Pause   time.Duration `json:"pause,omitempty"`
+}
+
+// ProsodyTTSProvider is implemented by TTS providers that vary prosody
+// within a reply.
+type ProsodyTTSProvider interface {
+	StreamSynthesizeProsody(ctx context.Context, segments []SpeechSegment, voice Voice, lang Language, onChunk func([]byte) error) error
+}
+
+// SSMLTTSProvider is implemented by TTS providers that take SSML.
+type SSMLTTSProvider interface {
+	StreamSynthesizeSSML(ctx context.Context, ssml string, voice Voice, lang Language, onChunk func([]byte) error) error
+}
+
+const (
+	defaultPause = 500 * time.Millisecond
+	maxPause     = 3 * time.Second
+)
+
+const prosodyInstruction = "Your replies are spoken aloud, and you may shape how with light markup: [pause] or [pause 800ms] for a pause, *word* to stress a word, and [slow]...[/slow], [fast]...[/fast], [low]...[/low] or [high]...[/high] around a phrase. Use it sparingly, where a person would, and never mention it."
+
+var prosodyMarkup = regexp.MustCompile(`\[(pause(?: +[0-9.]+m?s)?|/?(?:slow|fast|low|high))\]|\*([^*\n]+)\*`)
+
+func (c ProsodyConfig) instruction() string {
+	if !c.Enabled {
+		return ""
+	}
+	return prosodyInstruction
+}
+
+// strip removes the markup from text, leaving what is said.
+func (c ProsodyConfig) strip(text string) string {
+	if !c.Enabled {
+		return text
+	}
+	return StripProsody(text)
+}
+
+// StripProsody removes prosody markup from text.
+func StripProsody(text string) string {
+	var b strings.Builder
+	for _, seg := range ParseProsody(text) {
+		b.WriteString(seg.Text)
+		if seg.Pause > 0 {
+			b.WriteString(" ")
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// ParseProsody splits text with prosody markup into segments. Unknown tags
+// are left in the text.
+func ParseProsody(text string) []SpeechSegment {
+	var segments []SpeechSegment
+	var current Prosody
+	add := func(s string, p Prosody) {
+		if s == "" {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].Pause == 0 && segments[n-1].Prosody == p {
+			segments[n-1].Text += s
+			return
+		}
+		segments = append(segments, SpeechSegment{Text: s, Prosody: p})
+	}
+
+	last := 0
+	for _, m := range prosodyMarkup.FindAllStringSubmatchIndex(text, -1) {
+		add(text[last:m[0]], current)
+		last = m[1]
+		if m[4] >= 0 {
+			stressed := current
+			stressed.Emphasis = true
+			add(text[m[4]:m[5]], stressed)
+			continue
+		}
+		switch tag := text[m[2]:m[3]]; tag {
+		case "slow":
+			current.Rate = 0.85
+		case "fast":
+			current.Rate = 1.15
+		case "/slow", "/fast":
+			current.Rate = 0
+		case "low":
+			current.Pitch = -2
+		case "high":
+			current.Pitch = 2
+		case "/low", "/high":
+			current.Pitch = 0
+		default:
+			segments = append(segments, SpeechSegment{Pause: pauseLength(tag)})
+		}
+	}
+	add(text[last:], current)
+	return segments
+}
+
+// pauseLength parses the length of a [pause] tag.
+func pauseLength(tag string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(tag, "pause")))
+	if err != nil || d <= 0 {
+		return defaultPause
+	}
+	return min(d, maxPause)
+}
+
+// SSML renders segments as an SSML document.
+func SSML(segments []SpeechSegment) string {
+	var b strings.Builder
+	b.WriteString("<speak>")
+	for _, seg := range segments {
+		if seg.Pause > 0 {
+			fmt.Fprintf(&b, `<break time="%dms"/>`, seg.Pause.Milliseconds())
+			continue
+		}
+		text := html.EscapeString(seg.Text)
+		if seg.Prosody.Emphasis {
+			text = "<emphasis>" + text + "</emphasis>"
+		}
+		var attrs string
+		if seg.Prosody.Rate > 0 {
+			attrs += fmt.Sprintf(` rate="%.0f%%"`, seg.Prosody.Rate*100)
+		}
+		if seg.Prosody.Pitch != 0 {
+			attrs += fmt.Sprintf(` pitch="%+gst"`, seg.Prosody.Pitch)
+		}
+		if attrs != "" {
+			text = "<prosody" + attrs + ">" + text + "</prosody>"
+		}
+		b.WriteString(text)
+	}
+	b.WriteString("</speak>")
+	return b.String()
+}
+
+// synthesize streams the speech of response, following its prosody markup
+// when enabled. Providers without prosody support get the text between
+// pauses, and silence for the pauses. onMark, when set, gets the speech
+// marks of providers that report them, offset from the start of the reply.
+func (ms *ManagedStream) synthesize(ctx context.Context, response string, voice Voice, lang Language, onChunk func([]byte) error, onMark func(SpeechMark) error) error {
+	if ok, err := ms.playDisclosure(response, voice, lang, onChunk); ok {
+		return err
+	}
+	playbackRate, _ := ms.sampleRates()
+	var delivered int
+	deliver := func(chunk []byte) error {
+		delivered += len(chunk)
+		return onChunk(chunk)
+	}
+	say := func(text string) error {
+		base := time.Duration(delivered/2) * time.Second / time.Duration(playbackRate)
+		return ms.synthesizeText(ctx, text, voice, lang, base, deliver, onMark)
+	}
+
+	if !ms.orch.GetConfig().Prosody.Enabled {
+		return say(response)
+	}
+	segments := ParseProsody(response)
+	switch tts := ms.orch.tts.(type) {
+	case ProsodyTTSProvider:
+		return ms.orch.convertTTS(onChunk, func(onChunk func([]byte) error) error {
+			return tts.StreamSynthesizeProsody(ctx, segments, voice, lang, onChunk)
+		})
+	case SSMLTTSProvider:
+		return ms.orch.convertTTS(onChunk, func(onChunk func([]byte) error) error {
+			return tts.StreamSynthesizeSSML(ctx, SSML(segments), voice, lang, onChunk)
+		})
+	}
+
+	var text strings.Builder
+	flush := func() error {
+		s := strings.TrimSpace(text.String())
+		text.Reset()
+		if s == "" {
+			return nil
+		}
+		return say(s)
+	}
+	for _, seg := range segments {
+		if seg.Pause == 0 {
+			text.WriteString(seg.Text)
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := deliver(make([]byte, int(seg.Pause.Seconds()*float64(playbackRate))*2)); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,148 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// textTTS records what it is asked to say and answers each request with
+// two bytes of audio.
+type textTTS struct {
+	MockTTSProvider
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *textTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	s.mu.Lock()
+	s.texts = append(s.texts, text)
+	s.mu.Unlock()
+	return onChunk([]byte{1, 1})
+}
+
+type ssmlTTS struct {
+	textTTS
+	ssml string
+}
+
+func (s *ssmlTTS) StreamSynthesizeSSML(ctx context.Context, ssml string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	s.ssml = ssml
+	return onChunk([]byte{1, 1})
+}
+
+func TestParseProsody(t *testing.T) {
+	got := ParseProsody("Well… [pause] it's *really* [slow]quite far[/slow]. [pause 1200ms][high]Ready?[/high] [pause 9s]")
+	want := []SpeechSegment{
+		{Text: "Well… "},
+		{Pause: defaultPause},
+		{Text: " it's "},
+		{Text: "really", Prosody: Prosody{Emphasis: true}},
+		{Text: " "},
+		{Text: "quite far", Prosody: Prosody{Rate: 0.85}},
+		{Text: ". "},
+		{Pause: 1200 * time.Millisecond},
+		{Text: "Ready?", Prosody: Prosody{Pitch: 2}},
+		{Text: " "},
+		{Pause: maxPause},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+
+	if plain := StripProsody("Sure.[pause] It's *ten*, [fast]or so[/fast]. [shrug]"); plain != "Sure. It's ten, or so. [shrug]" {
+		t.Errorf("unexpected plain text %q", plain)
+	}
+}
+
+func TestSSML(t *testing.T) {
+	ssml := SSML(ParseProsody("A & B [pause 300ms][slow][low]*now*[/low][/slow]"))
+	want := `<speak>A &amp; B <break time="300ms"/><prosody rate="85%" pitch="-2st"><emphasis>now</emphasis></prosody></speak>`
+	if ssml != want {
+		t.Errorf("got  %s\nwant %s", ssml, want)
+	}
+}
+
+func prosodyStream(t *testing.T, tts TTSProvider, reply string) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Prosody.Enabled = true
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: reply}, tts, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("prosody"))
+	t.Cleanup(ms.Close)
+	ms.SetEchoSampleRates(16000, 16000)
+	return ms
+}
+
+func TestManagedStream_ProsodyFallback(t *testing.T) {
+	tts := &textTTS{}
+	ms := prosodyStream(t, tts, "Let me check. [pause 100ms] It's *ten*.")
+	ms.session.AddMessage("user", "what time is it")
+	ms.runLLMAndTTS(ms.ctx, "what time is it")
+
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "Let me check. It's ten." {
+		t.Errorf("markup reached the response event: %q", ev.Data)
+	}
+	if last := ms.session.LastAssistant; last != "Let me check. It's ten." {
+		t.Errorf("markup reached the context: %q", last)
+	}
+	var audio []byte
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == AudioChunk {
+				audio = append(audio, ev.Data.([]byte)...)
+			}
+			continue
+		default:
+		}
+		break
+	}
+	if want := 2 + 3200 + 2; len(audio) != want {
+		t.Errorf("expected speech, 100ms of silence and speech (%d bytes), got %d", want, len(audio))
+	}
+	if !reflect.DeepEqual(tts.texts, []string{"Let me check.", "It's ten."}) {
+		t.Errorf("unexpected TTS requests %q", tts.texts)
+	}
+}
+
+func TestManagedStream_ProsodySSML(t *testing.T) {
+	tts := &ssmlTTS{}
+	ms := prosodyStream(t, tts, "It's *ten*.")
+	if err := ms.say(ms.ctx, "[slow]Hello[/slow] there.", true); err != nil {
+		t.Fatal(err)
+	}
+	if tts.ssml != `<speak><prosody rate="85%">Hello</prosody> there.</speak>` {
+		t.Errorf("unexpected SSML %s", tts.ssml)
+	}
+	if len(tts.texts) != 0 {
+		t.Errorf("SSML providers should not get plain text: %q", tts.texts)
+	}
+	if last := ms.session.LastAssistant; last != "Hello there." {
+		t.Errorf("markup reached the context: %q", last)
+	}
+}
+
+func TestProsody_Instruction(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Prosody.Enabled = true
+	llm := &capturingLLM{}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, cfg)
+	session := NewConversationSession("prosody")
+	session.AddMessage("user", "hi")
+	if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	for _, msg := range llm.messages {
+		if msg.Role == "system" && strings.Contains(msg.Content, "[pause]") {
+			return
+		}
+	}
+	t.Errorf("the LLM was not told about the markup: %+v", llm.messages)
+}
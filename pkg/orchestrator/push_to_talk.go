@@ -0,0 +1,85 @@
+package orchestrator
+
+// TurnDetection selects how a stream decides when the caller is talking.
+type TurnDetection string
+
+const (
+	// TurnDetectionVAD, the default, detects turns from the audio.
+	TurnDetectionVAD TurnDetection = "vad"
+	// PushToTalk leaves it to the application: only audio between
+	// BeginUserTurn and EndUserTurn is heard, whatever its level.
+	PushToTalk TurnDetection = "push_to_talk"
+)
+
+func (ms *ManagedStream) pushToTalk() bool {
+	return ms.orch != nil && ms.orch.GetConfig().TurnDetection == PushToTalk
+}
+
+// BeginUserTurn opens the caller's turn, as pressing a talk button would. It
+// interrupts the bot and starts capturing audio. It returns ErrNotPushToTalk
+// unless Config.TurnDetection is PushToTalk.
+func (ms *ManagedStream) BeginUserTurn() error {
+	if !ms.pushToTalk() {
+		return ErrNotPushToTalk
+	}
+	ms.mu.Lock()
+	if ms.isClosed {
+		ms.mu.Unlock()
+		return ErrStreamClosed
+	}
+	if ms.userTurnOpen {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.userTurnOpen = true
+	ms.audioBuf.Reset()
+	ms.mu.Unlock()
+
+	ms.internalInterrupt()
+	ms.beginTurn()
+	ms.startUtterance(false)
+	return nil
+}
+
+// EndUserTurn closes the caller's turn and answers what was captured.
+func (ms *ManagedStream) EndUserTurn() error {
+	if !ms.pushToTalk() {
+		return ErrNotPushToTalk
+	}
+	ms.mu.Lock()
+	if ms.isClosed {
+		ms.mu.Unlock()
+		return ErrStreamClosed
+	}
+	if !ms.userTurnOpen {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.userTurnOpen = false
+	ms.mu.Unlock()
+
+	ms.endUtterance(false)
+	return nil
+}
+
+// writePushToTalk captures inbound audio while the caller's turn is open and
+// ignores it otherwise.
+func (ms *ManagedStream) writePushToTalk(chunk []byte) error {
+	ms.mu.Lock()
+	if !ms.userTurnOpen {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.audioBuf.Write(chunk)
+	ms.lastUserAudio = append(ms.lastUserAudio, chunk...)
+	sttChan := ms.sttChan
+	ms.mu.Unlock()
+
+	if sttChan != nil {
+		select {
+		case sttChan <- chunk:
+		default:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPushToTalk_GatesCapture(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TurnDetection = PushToTalk
+	ms := newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "over"}, &MockLLMProvider{completeResult: "copy"})
+
+	// Loud audio outside a turn is not heard.
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	if heardSpeech(ms) {
+		t.Fatal("audio outside a push-to-talk turn must be ignored")
+	}
+
+	if err := ms.BeginUserTurn(); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvent(t, ms, UserSpeaking)
+	if ms.State() != StreamListening {
+		t.Errorf("expected listening, got %s", ms.State())
+	}
+	// Quiet audio is captured all the same: there is no VAD.
+	for i := 0; i < 20; i++ {
+		ms.doWrite(make([]byte, 882))
+	}
+	if err := ms.EndUserTurn(); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvent(t, ms, UserStopped)
+	if ev := waitForEvent(t, ms, TranscriptFinal); ev.Data != "over" {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "copy" {
+		t.Errorf("unexpected response %v", ev.Data)
+	}
+}
+
+func TestPushToTalk_RequiresMode(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, DefaultConfig())
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("vad"))
+	defer ms.Close()
+
+	if err := ms.BeginUserTurn(); !errors.Is(err, ErrNotPushToTalk) {
+		t.Errorf("expected ErrNotPushToTalk, got %v", err)
+	}
+	if err := ms.EndUserTurn(); !errors.Is(err, ErrNotPushToTalk) {
+		t.Errorf("expected ErrNotPushToTalk, got %v", err)
+	}
+}
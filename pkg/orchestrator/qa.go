@@ -0,0 +1,322 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxFlaggedTurns bounds the flagged turns kept for FlaggedTurns.
+const maxFlaggedTurns = 100
+
+// qaContextMessages is how much of the conversation the judge sees.
+const qaContextMessages = 6
+
+const defaultQAPrompt = "You review replies of a voice assistant. Score the assistant's last reply from 1 (bad) to 5 (excellent) on " +
+	"correctness (accurate and answers the user), tone (polite, concise, suited to speech) and " +
+	"policy (follows the agent instructions and avoids unsafe or off-limits content). " +
+	`Reply with JSON only: {"correctness": n, "tone": n, "policy": n, "notes": "one short sentence"}`
+
+// QAConfig samples spoken replies and scores them with a judge model after
+// the turn, off the response path. SampleRate is the fraction of turns
+// judged; zero disables QA. Judge defaults to the main LLM. Turns whose
+// overall score falls below FlagBelow (default 3) are flagged for review.
+// Sessions without ConsentTranscript are never sent to the judge. At most
+// MaxConcurrent (default 4) judge calls run at once; turns sampled beyond
+// that are dropped.
+type QAConfig struct {
+	Judge         LLMProvider
+	SampleRate    float64
+	Prompt        string
+	FlagBelow     float64
+	OnFlag        func(QAResult)
+	Timeout       time.Duration
+	MaxConcurrent int
+}
+
+// QAScores are on a 1 to 5 scale.
+type QAScores struct {
+	Correctness float64 `json:"correctness"`
+	Tone        float64 `json:"tone"`
+	Policy      float64 `json:"policy"`
+}
+
+func (s QAScores) overall() float64 {
+	return (s.Correctness + s.Tone + s.Policy) / 3
+}
+
+type QAResult struct {
+	SessionID string    `json:"session_id"`
+	TurnID    string    `json:"turn_id"`
+	UserText  string    `json:"user_text"`
+	Response  string    `json:"response"`
+	Scores    QAScores  `json:"scores"`
+	Overall   float64   `json:"overall"`
+	Notes     string    `json:"notes,omitempty"`
+	Flagged   bool      `json:"flagged"`
+	At        time.Time `json:"at"`
+}
+
+// QAStats aggregates every judged turn since the orchestrator started. Means
+// are over judged turns only.
+type QAStats struct {
+	Sampled int      `json:"sampled"`
+	Dropped int      `json:"dropped"`
+	Judged  int      `json:"judged"`
+	Failed  int      `json:"failed"`
+	Flagged int      `json:"flagged"`
+	Mean    QAScores `json:"mean"`
+	Overall float64  `json:"overall"`
+}
+
+type qaState struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	sampled int
+	dropped int
+	judged  int
+	failed  int
+	flagged []QAResult
+	nFlag   int
+	sum     QAScores
+	// active counts running judge calls against MaxConcurrent. ctx is
+	// canceled by Close, after which no turn is judged.
+	active int
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+}
+
+// QAStats returns the aggregated judge scores.
+func (o *Orchestrator) QAStats() QAStats {
+	o.qa.mu.Lock()
+	defer o.qa.mu.Unlock()
+	stats := QAStats{
+		Sampled: o.qa.sampled,
+		Dropped: o.qa.dropped,
+		Judged:  o.qa.judged,
+		Failed:  o.qa.failed,
+		Flagged: o.qa.nFlag,
+	}
+	if n := float64(o.qa.judged); n > 0 {
+		stats.Mean = QAScores{
+			Correctness: o.qa.sum.Correctness / n,
+			Tone:        o.qa.sum.Tone / n,
+			Policy:      o.qa.sum.Policy / n,
+		}
+		stats.Overall = stats.Mean.overall()
+	}
+	return stats
+}
+
+// FlaggedTurns returns the most recent flagged turns, oldest first. Only the
+// last 100 are kept.
+func (o *Orchestrator) FlaggedTurns() []QAResult {
+	o.qa.mu.Lock()
+	defer o.qa.mu.Unlock()
+	return append([]QAResult(nil), o.qa.flagged...)
+}
+
+// FlushQA waits for pending judge calls.
+func (o *Orchestrator) FlushQA(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		o.qa.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops background work: pending judge calls are canceled and no
+// further turns are judged. Live streams are left alone.
+func (o *Orchestrator) Close() {
+	o.qa.mu.Lock()
+	o.qa.closed = true
+	if o.qa.cancel != nil {
+		o.qa.cancel()
+	}
+	o.qa.mu.Unlock()
+	o.qa.wg.Wait()
+}
+
+// sampleQA schedules a judge call for a finished turn when it is sampled.
+// The context is captured now so later turns don't leak into the review.
+func (o *Orchestrator) sampleQA(session *ConversationSession, turn Turn) {
+	cfg := o.GetConfig().QA
+	if cfg.SampleRate <= 0 || turn.Response == "" || !session.HasConsent(ConsentTranscript) {
+		return
+	}
+	if cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate {
+		return
+	}
+	limit := cfg.MaxConcurrent
+	if limit <= 0 {
+		limit = 4
+	}
+
+	o.qa.mu.Lock()
+	if o.qa.closed {
+		o.qa.mu.Unlock()
+		return
+	}
+	o.qa.sampled++
+	if o.qa.active >= limit {
+		o.qa.dropped++
+		o.qa.mu.Unlock()
+		o.logger.Debug("QA judge busy, dropping turn", "sessionID", session.ID, "turnID", turn.ID)
+		return
+	}
+	o.qa.active++
+	if o.qa.ctx == nil {
+		o.qa.ctx, o.qa.cancel = context.WithCancel(context.Background())
+	}
+	parent := o.qa.ctx
+	o.qa.wg.Add(1)
+	o.qa.mu.Unlock()
+
+	history := session.GetContextCopy()
+	go func() {
+		defer func() {
+			o.qa.mu.Lock()
+			o.qa.active--
+			o.qa.mu.Unlock()
+			o.qa.wg.Done()
+		}()
+		o.judgeTurn(parent, cfg, session, turn, history)
+	}()
+}
+
+func (o *Orchestrator) judgeTurn(parent context.Context, cfg QAConfig, session *ConversationSession, turn Turn, history []Message) {
+	// Consent may have been withdrawn since the turn was sampled.
+	if !session.HasConsent(ConsentTranscript) {
+		return
+	}
+	sessionID := session.ID
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	judge := cfg.Judge
+	if judge == nil {
+		judge = o.llm
+	}
+	prompt := cfg.Prompt
+	if prompt == "" {
+		prompt = defaultQAPrompt
+	}
+
+	reply, _, err := o.completeWith(ctx, judge, []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: qaTranscript(history, turn)},
+	}, GenerationParams{})
+	var scores QAScores
+	var notes string
+	if err == nil {
+		scores, notes, err = parseQAReply(reply)
+	}
+	if err != nil {
+		o.logger.Warn("QA judge failed", "sessionID", sessionID, "turnID", turn.ID, "error", err)
+		o.qa.mu.Lock()
+		o.qa.failed++
+		o.qa.mu.Unlock()
+		return
+	}
+
+	flagBelow := cfg.FlagBelow
+	if flagBelow <= 0 {
+		flagBelow = 3
+	}
+	result := QAResult{
+		SessionID: sessionID,
+		TurnID:    turn.ID,
+		UserText:  turn.Transcript,
+		Response:  turn.Response,
+		Scores:    scores,
+		Overall:   scores.overall(),
+		Notes:     notes,
+		At:        time.Now(),
+	}
+	result.Flagged = result.Overall < flagBelow
+
+	o.qa.mu.Lock()
+	o.qa.judged++
+	o.qa.sum.Correctness += scores.Correctness
+	o.qa.sum.Tone += scores.Tone
+	o.qa.sum.Policy += scores.Policy
+	if result.Flagged {
+		o.qa.nFlag++
+		o.qa.flagged = append(o.qa.flagged, result)
+		if len(o.qa.flagged) > maxFlaggedTurns {
+			o.qa.flagged = o.qa.flagged[len(o.qa.flagged)-maxFlaggedTurns:]
+		}
+	}
+	o.qa.mu.Unlock()
+
+	if result.Flagged {
+		o.logger.Warn("QA flagged turn", "sessionID", sessionID, "turnID", turn.ID, "overall", result.Overall, "notes", notes)
+		if cfg.OnFlag != nil {
+			cfg.OnFlag(result)
+		}
+	}
+}
+
+// qaTranscript lays out the agent instructions, the recent conversation and
+// the reply under review for the judge.
+func qaTranscript(history []Message, turn Turn) string {
+	var b strings.Builder
+	var convo []Message
+	for _, m := range history {
+		if m.Role == "system" && !isSummaryMessage(m) {
+			fmt.Fprintf(&b, "Agent instructions: %s\n", m.Content)
+			continue
+		}
+		convo = append(convo, m)
+	}
+	// The reply under review is the last assistant message; drop it from the
+	// conversation so it is shown only once.
+	if n := len(convo); n > 0 && convo[n-1].Role == "assistant" && convo[n-1].Content == turn.Response {
+		convo = convo[:n-1]
+	}
+	if len(convo) > qaContextMessages {
+		convo = convo[len(convo)-qaContextMessages:]
+	}
+	b.WriteString("Conversation:\n")
+	for _, m := range convo {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+	}
+	fmt.Fprintf(&b, "Reply under review: %s\n", turn.Response)
+	return b.String()
+}
+
+func parseQAReply(reply string) (QAScores, string, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return QAScores{}, "", fmt.Errorf("judge reply is not JSON: %q", reply)
+	}
+	var parsed struct {
+		QAScores
+		Notes string `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return QAScores{}, "", fmt.Errorf("judge reply is not JSON: %w", err)
+	}
+	s := parsed.QAScores
+	for _, v := range []float64{s.Correctness, s.Tone, s.Policy} {
+		if v < 1 || v > 5 {
+			return QAScores{}, "", fmt.Errorf("judge score %v out of range", v)
+		}
+	}
+	return s, parsed.Notes, nil
+}
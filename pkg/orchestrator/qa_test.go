@@ -0,0 +1,150 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func flushQA(t *testing.T, orch *Orchestrator) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := orch.FlushQA(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQA_JudgesSpokenTurns(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.QA = QAConfig{Judge: &MockLLMProvider{completeResult: `Sure: {"correctness": 2, "tone": 3, "policy": 1, "notes": "Wrong policy."}`}, SampleRate: 1}
+	var flagged []QAResult
+	cfg.QA.OnFlag = func(r QAResult) { flagged = append(flagged, r) }
+	orch := NewWithVAD(&MockSTTProvider{}, &capturingLLM{}, &MockTTSProvider{synthesizeResult: make([]byte, 3528)}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+
+	session := NewConversationSession("qa")
+	session.AddMessage("system", "Only discuss returns.")
+	ms := orch.NewManagedStream(context.Background(), session)
+	t.Cleanup(ms.Close)
+
+	turnID := ms.beginTurn()
+	ms.updateTurn(turnID, func(t *Turn) { t.Transcript = "can I return shoes" })
+	session.AddMessage("user", "can I return shoes")
+	ms.runLLMAndTTS(ms.ctx, "can I return shoes")
+	flushQA(t, orch)
+
+	stats := orch.QAStats()
+	if stats.Sampled != 1 || stats.Judged != 1 || stats.Flagged != 1 || stats.Failed != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if stats.Mean.Correctness != 2 || stats.Overall != 2 {
+		t.Errorf("mean = %+v overall %v", stats.Mean, stats.Overall)
+	}
+	if len(flagged) != 1 || flagged[0].TurnID != turnID || flagged[0].UserText != "can I return shoes" ||
+		flagged[0].Response != "Returns are accepted within 30 days." || flagged[0].Notes != "Wrong policy." {
+		t.Errorf("flagged = %+v", flagged)
+	}
+	if got := orch.FlaggedTurns(); len(got) != 1 || !got[0].Flagged {
+		t.Errorf("FlaggedTurns = %+v", got)
+	}
+}
+
+func TestQA_TranscriptForJudge(t *testing.T) {
+	history := []Message{
+		{Role: "system", Content: "Only discuss returns."},
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "Hello!"},
+	}
+	got := qaTranscript(history, Turn{Response: "Hello!"})
+	if !strings.Contains(got, "Agent instructions: Only discuss returns.") || !strings.Contains(got, "user: hi") {
+		t.Errorf("missing context:\n%s", got)
+	}
+	if strings.Count(got, "Hello!") != 1 || !strings.HasSuffix(got, "Reply under review: Hello!\n") {
+		t.Errorf("reply must be shown once, last:\n%s", got)
+	}
+}
+
+func TestQA_FailuresAndSampling(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.QA = QAConfig{Judge: &MockLLMProvider{completeResult: "looks fine"}, SampleRate: 1}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	session := NewConversationSession("qa")
+
+	orch.sampleQA(session, Turn{ID: "qa-1", Response: "Hello."})
+	orch.sampleQA(session, Turn{ID: "qa-2"})
+	flushQA(t, orch)
+	if stats := orch.QAStats(); stats.Sampled != 1 || stats.Failed != 1 || stats.Judged != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	cfg.QA.SampleRate = 0
+	orch.UpdateConfig(cfg)
+	orch.sampleQA(session, Turn{ID: "qa-3", Response: "Hello."})
+	if stats := orch.QAStats(); stats.Sampled != 1 {
+		t.Errorf("QA must be off with a zero sample rate, stats = %+v", stats)
+	}
+}
+
+func TestQA_ParseReply(t *testing.T) {
+	scores, notes, err := parseQAReply("```json\n{\"correctness\": 5, \"tone\": 4, \"policy\": 5, \"notes\": \"ok\"}\n```")
+	if err != nil || scores.Tone != 4 || notes != "ok" {
+		t.Errorf("got %+v %q %v", scores, notes, err)
+	}
+	if _, _, err := parseQAReply(`{"correctness": 9, "tone": 4, "policy": 5}`); err == nil {
+		t.Error("out of range score must fail")
+	}
+	if _, _, err := parseQAReply(`{"tone": 4}`); err == nil {
+		t.Error("missing scores must fail")
+	}
+}
+
+// blockingJudge holds every call until its context ends.
+type blockingJudge struct {
+	MockLLMProvider
+	started chan struct{}
+}
+
+func (j *blockingJudge) Complete(ctx context.Context, messages []Message) (string, error) {
+	j.started <- struct{}{}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestQA_ConsentConcurrencyAndClose(t *testing.T) {
+	judge := &blockingJudge{started: make(chan struct{}, 4)}
+	cfg := DefaultConfig()
+	cfg.QA = QAConfig{Judge: judge, SampleRate: 1, MaxConcurrent: 1}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+
+	private := NewConversationSession("private")
+	private.RevokeConsent(ConsentTranscript)
+	orch.sampleQA(private, Turn{ID: "qa-1", Response: "Hello."})
+	if stats := orch.QAStats(); stats.Sampled != 0 {
+		t.Fatalf("expected a session without transcript consent to be skipped, stats = %+v", stats)
+	}
+
+	session := NewConversationSession("qa")
+	orch.sampleQA(session, Turn{ID: "qa-2", Response: "Hello."})
+	<-judge.started
+	orch.sampleQA(session, Turn{ID: "qa-3", Response: "Hello."})
+	if stats := orch.QAStats(); stats.Sampled != 2 || stats.Dropped != 1 {
+		t.Errorf("expected the second turn to be dropped while the judge is busy, stats = %+v", stats)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		orch.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not cancel the pending judge call")
+	}
+	orch.sampleQA(session, Turn{ID: "qa-4", Response: "Hello."})
+	if stats := orch.QAStats(); stats.Sampled != 2 {
+		t.Errorf("expected no sampling after Close, stats = %+v", stats)
+	}
+}
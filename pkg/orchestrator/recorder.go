@@ -0,0 +1,418 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+const defaultRecordingSegment = 10 * time.Minute
+
+// RecordingStorage receives the files of finished recording segments. Names
+// are slash-separated, e.g. "session-1/20261015T093000Z/0001/inbound.wav",
+// under "users/<user>/" for sessions with a UserID. Every BlobStore is one.
+type RecordingStorage interface {
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// StreamingRecordingStorage is storage that can take a file as it is
+// written, such as store.RecordingDir. Unless recordings are encrypted, the
+// recorder then writes audio tracks straight to it, so an hour-long segment
+// isn't held in memory. Delete removes tracks when consent is revoked.
+type StreamingRecordingStorage interface {
+	RecordingStorage
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// RecordingConfig records every ManagedStream when Storage is set. A new
+// segment starts every SegmentDuration (10 minutes by default). Files are
+// sealed with Encryptor, or Config.Encryptor when nil.
+type RecordingConfig struct {
+	Storage         RecordingStorage
+	SegmentDuration time.Duration
+	Encryptor       Encryptor
+}
+
+// RecordingEntry is one line of a segment's timeline.jsonl. Offset is
+// measured from the start of the segment, like the audio tracks.
+type RecordingEntry struct {
+	At     time.Time   `json:"at"`
+	Offset int64       `json:"offset_ms"`
+	Type   EventType   `json:"type"`
+	TurnID string      `json:"turn_id,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	// Generation is set on BotResponse entries: the LLM parameters, seed
+	// included, the reply was generated with.
+	Generation *GenerationParams `json:"generation,omitempty"`
+}
+
+// Recorder captures a session as bundles of inbound.wav (the caller's raw
+// audio), outbound.wav (everything played to the caller) and timeline.jsonl
+// (the stream's events, transcripts included). Audio is only kept while the
+// session grants ConsentRecording, and revoking it discards the current
+// segment's audio; transcripts are left out of the timeline without
+// ConsentTranscript.
+type Recorder struct {
+	storage    RecordingStorage
+	encryptor  Encryptor
+	segmentLen time.Duration
+	session    *ConversationSession
+	prefix     string
+
+	mu           sync.Mutex
+	inboundRate  int
+	outboundRate int
+	segment      int
+	started      time.Time
+	inbound      recordingTrack
+	outbound     recordingTrack
+	timeline     bytes.Buffer
+	closed       bool
+	err          error
+
+	streaming StreamingRecordingStorage // nil to buffer segments
+	opened    bool                      // the segment's tracks were created
+
+	wg    sync.WaitGroup
+	group *sync.WaitGroup
+}
+
+func NewRecorder(session *ConversationSession, sampleRate int, cfg RecordingConfig) *Recorder {
+	segmentLen := cfg.SegmentDuration
+	if segmentLen <= 0 {
+		segmentLen = defaultRecordingSegment
+	}
+	now := time.Now()
+	prefix := session.ID
+	if session.UserID != "" {
+		// Grouped by user, so one user's recordings can be erased together.
+		prefix = "users/" + session.UserID + "/" + session.ID
+	}
+	streaming, _ := cfg.Storage.(StreamingRecordingStorage)
+	if cfg.Encryptor != nil {
+		// Sealing needs the whole file.
+		streaming = nil
+	}
+	return &Recorder{
+		streaming:    streaming,
+		inbound:      recordingTrack{name: "inbound.wav"},
+		outbound:     recordingTrack{name: "outbound.wav"},
+		storage:      cfg.Storage,
+		encryptor:    cfg.Encryptor,
+		segmentLen:   segmentLen,
+		session:      session,
+		prefix:       prefix + "/" + now.UTC().Format("20060102T150405Z"),
+		segment:      1,
+		started:      now,
+		inboundRate:  sampleRate,
+		outboundRate: sampleRate,
+	}
+}
+
+// SetSampleRates sets the rates of both tracks when they differ from the
+// stream's Config.SampleRate. Call it before any audio is recorded.
+func (r *Recorder) SetSampleRates(outboundRate, inboundRate int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if outboundRate > 0 {
+		r.outboundRate = outboundRate
+	}
+	if inboundRate > 0 {
+		r.inboundRate = inboundRate
+	}
+}
+
+// WriteInbound records the caller's audio.
+func (r *Recorder) WriteInbound(pcm []byte) {
+	r.writeAudio(&r.inbound, pcm)
+}
+
+// WriteOutbound records audio played to the caller. Outbound audio arrives in
+// bursts, so the track is padded with silence up to the current time first
+// to stay aligned with the inbound one.
+func (r *Recorder) WriteOutbound(pcm []byte) {
+	r.writeAudio(&r.outbound, pcm)
+}
+
+func (r *Recorder) writeAudio(track *recordingTrack, pcm []byte) {
+	consent := r.session.HasConsent(ConsentRecording)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	if !consent {
+		r.discardAudioLocked()
+		return
+	}
+	r.rotateIfDueLocked()
+	if r.streaming != nil && !r.opened {
+		r.openTracksLocked()
+	}
+	if track == &r.outbound {
+		if pos := int64(time.Since(r.started).Seconds()*float64(r.outboundRate)) * 2; r.outbound.n < pos {
+			r.writeLocked(track, make([]byte, pos-r.outbound.n))
+		}
+	}
+	r.writeLocked(track, pcm)
+}
+
+// recordingTrack is an audio file of the current segment, held in memory or
+// written to streaming storage as it comes.
+type recordingTrack struct {
+	name string
+	buf  bytes.Buffer
+	file io.WriteCloser
+	wav  *audio.WavWriter
+	n    int64
+}
+
+func (r *Recorder) writeLocked(track *recordingTrack, pcm []byte) {
+	track.n += int64(len(pcm))
+	if track.wav == nil {
+		track.buf.Write(pcm)
+		return
+	}
+	if _, err := track.wav.Write(pcm); err != nil && r.err == nil {
+		r.err = fmt.Errorf("recording %s: %w", track.name, err)
+	}
+}
+
+// openTracksLocked creates the segment's audio files in streaming storage.
+// If that fails, the segment is buffered as usual and a file already created
+// for the other track is deleted.
+func (r *Recorder) openTracksLocked() {
+	r.opened = true
+	base := r.baseLocked()
+	for _, t := range []*recordingTrack{&r.inbound, &r.outbound} {
+		rate := r.inboundRate
+		if t == &r.outbound {
+			rate = r.outboundRate
+		}
+		f, err := r.streaming.Create(context.Background(), base+t.name)
+		if err != nil {
+			if r.err == nil {
+				r.err = fmt.Errorf("recording %s: %w", t.name, err)
+			}
+			if r.inbound.file != nil {
+				r.inbound.close()
+				if err := r.streaming.Delete(context.Background(), base+r.inbound.name); err != nil && r.err == nil {
+					r.err = fmt.Errorf("recording %s: %w", r.inbound.name, err)
+				}
+			}
+			return
+		}
+		t.file, t.wav = f, audio.NewWavWriter(f, rate)
+	}
+}
+
+func (t *recordingTrack) close() error {
+	if t.file == nil {
+		return nil
+	}
+	err := errors.Join(t.wav.Close(), t.file.Close())
+	t.file, t.wav = nil, nil
+	return err
+}
+
+// discardAudioLocked drops the segment's audio, deleting any files already
+// created for it.
+func (r *Recorder) discardAudioLocked() {
+	base := r.baseLocked()
+	for _, t := range []*recordingTrack{&r.inbound, &r.outbound} {
+		if t.file != nil {
+			t.close()
+			if err := r.streaming.Delete(context.Background(), base+t.name); err != nil && r.err == nil {
+				r.err = fmt.Errorf("recording %s: %w", t.name, err)
+			}
+		}
+		t.buf.Reset()
+		t.n = 0
+	}
+	r.opened = false
+}
+
+func (r *Recorder) baseLocked() string {
+	return fmt.Sprintf("%s/%04d/", r.prefix, r.segment)
+}
+
+// RecordEvent appends an event to the timeline. Audio payloads are left out;
+// they are in the tracks.
+func (r *Recorder) RecordEvent(ev OrchestratorEvent) {
+	r.recordEvent(ev, nil)
+}
+
+func (r *Recorder) recordEvent(ev OrchestratorEvent, generation *GenerationParams) {
+	ev = withoutTranscript(r.session, ev)
+	entry := RecordingEntry{Type: ev.Type, TurnID: ev.TurnID, At: time.Now(), Generation: generation}
+	if _, isAudio := ev.Data.([]byte); !isAudio {
+		entry.Data = ev.Data
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.rotateIfDueLocked()
+	entry.Offset = entry.At.Sub(r.started).Milliseconds()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		entry.Data = fmt.Sprint(entry.Data)
+		line, _ = json.Marshal(entry)
+	}
+	r.timeline.Write(line)
+	r.timeline.WriteByte('\n')
+}
+
+// Close stores the last segment. It does not wait for storage; use Flush.
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.rotateLocked()
+}
+
+// Flush waits for segments being stored and returns the first storage error.
+func (r *Recorder) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) rotateIfDueLocked() {
+	if time.Since(r.started) >= r.segmentLen {
+		r.rotateLocked()
+	}
+}
+
+// rotateLocked hands the current segment to storage and starts the next.
+func (r *Recorder) rotateLocked() {
+	base := r.baseLocked()
+	var files []recordingFile
+	var streamed []*recordingTrack
+	if r.inbound.file != nil {
+		// Written already; the files only need closing.
+		streamed = []*recordingTrack{
+			{name: r.inbound.name, file: r.inbound.file, wav: r.inbound.wav},
+			{name: r.outbound.name, file: r.outbound.file, wav: r.outbound.wav},
+		}
+	} else if r.inbound.n > 0 || r.outbound.n > 0 {
+		files = append(files,
+			recordingFile{base + "inbound.wav", audio.NewWavBuffer(r.inbound.buf.Bytes(), r.inboundRate)},
+			recordingFile{base + "outbound.wav", audio.NewWavBuffer(r.outbound.buf.Bytes(), r.outboundRate)},
+		)
+	}
+	if r.timeline.Len() > 0 {
+		files = append(files, recordingFile{base + "timeline.jsonl", bytes.Clone(r.timeline.Bytes())})
+	}
+	r.inbound = recordingTrack{name: "inbound.wav"}
+	r.outbound = recordingTrack{name: "outbound.wav"}
+	r.opened = false
+	r.timeline.Reset()
+	r.segment++
+	r.started = time.Now()
+
+	if (len(files) == 0 && len(streamed) == 0) || r.storage == nil {
+		return
+	}
+	r.wg.Add(1)
+	if r.group != nil {
+		r.group.Add(1)
+	}
+	go func() {
+		defer r.wg.Done()
+		if r.group != nil {
+			defer r.group.Done()
+		}
+		var errs []error
+		for _, t := range streamed {
+			if err := t.close(); err != nil {
+				errs = append(errs, fmt.Errorf("recording %s: %w", t.name, err))
+			}
+		}
+		if err := errors.Join(append(errs, r.store(files))...); err != nil {
+			r.mu.Lock()
+			if r.err == nil {
+				r.err = err
+			}
+			r.mu.Unlock()
+		}
+	}()
+}
+
+type recordingFile struct {
+	name string
+	data []byte
+}
+
+func (r *Recorder) store(files []recordingFile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var errs []error
+	for _, f := range files {
+		data, err := SealIfConfigured(ctx, r.encryptor, f.data)
+		if err == nil {
+			err = r.storage.Put(ctx, f.name, data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recording %s: %w", f.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Recorder returns the stream's recorder, or nil when Config.Recording is
+// not set.
+func (ms *ManagedStream) Recorder() *Recorder {
+	return ms.recorder
+}
+
+// FlushRecordings waits for recordings of closed streams to be stored.
+func (o *Orchestrator) FlushRecordings(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		o.recordings.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *Orchestrator) newRecorder(session *ConversationSession, config Config) *Recorder {
+	cfg := config.Recording
+	if cfg.Storage == nil {
+		return nil
+	}
+	if cfg.Encryptor == nil {
+		cfg.Encryptor = config.Encryptor
+	}
+	r := NewRecorder(session, config.SampleRate, cfg)
+	r.group = &o.recordings
+	return r
+}
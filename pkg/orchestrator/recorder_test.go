@@ -0,0 +1,315 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+type memoryRecordings struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memoryRecordings) Put(ctx context.Context, name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[name] = data
+	return nil
+}
+
+// file returns the stored file whose name ends in suffix.
+func (m *memoryRecordings) file(t *testing.T, suffix string) []byte {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, data := range m.files {
+		if strings.HasSuffix(name, suffix) {
+			return data
+		}
+	}
+	t.Fatalf("no recording file %q in %v", suffix, m.names())
+	return nil
+}
+
+func (m *memoryRecordings) names() []string {
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func timelineEntries(t *testing.T, data []byte) []RecordingEntry {
+	t.Helper()
+	var entries []RecordingEntry
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var e RecordingEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatalf("bad timeline line %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func recordedStream(t *testing.T, storage *memoryRecordings) (*Orchestrator, *ManagedStream) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Recording = RecordingConfig{Storage: storage}
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 3528)}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("rec"))
+	return orch, ms
+}
+
+func closeAndFlush(t *testing.T, orch *Orchestrator, ms *ManagedStream) {
+	t.Helper()
+	ms.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := orch.FlushRecordings(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorder_CapturesBothDirections(t *testing.T) {
+	storage := &memoryRecordings{}
+	orch, ms := recordedStream(t, storage)
+	ms.SetEchoSampleRates(24000, 16000)
+
+	in := toneChunk(1000)
+	ms.doWrite(in)
+	ms.speakText(ms.ctx, "Hello there.")
+	closeAndFlush(t, orch, ms)
+
+	pcm, rate, err := audio.DecodeWav(storage.file(t, "/0001/inbound.wav"))
+	if err != nil || rate != 16000 || !bytes.Equal(pcm, in) {
+		t.Errorf("inbound: %d bytes at %dHz, %v", len(pcm), rate, err)
+	}
+	pcm, rate, err = audio.DecodeWav(storage.file(t, "/0001/outbound.wav"))
+	if err != nil || rate != 24000 || len(pcm) < 3528 {
+		t.Errorf("outbound: %d bytes at %dHz, %v", len(pcm), rate, err)
+	}
+
+	var spoke bool
+	for _, e := range timelineEntries(t, storage.file(t, "/0001/timeline.jsonl")) {
+		if e.Type == AudioChunk {
+			t.Error("audio chunks belong in outbound.wav, not the timeline")
+		}
+		if e.Type == BotResponse && e.Data == "Hello there." && e.TurnID != "" {
+			spoke = true
+		}
+	}
+	if !spoke {
+		t.Error("timeline is missing the bot response")
+	}
+	if !strings.HasPrefix(storage.names()[0], "rec/") {
+		t.Errorf("bundle must be keyed by session, got %v", storage.names())
+	}
+}
+
+func TestRecorder_RecordsReplyGeneration(t *testing.T) {
+	storage := &memoryRecordings{}
+	llm := &seededLLM{}
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Determinism.Enabled = true
+	cfg.Recording = RecordingConfig{Storage: storage}
+	orch := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{synthesizeResult: make([]byte, 3528)}, NewRMSVAD(0.02, 50*time.Millisecond), cfg)
+	session := NewConversationSession("rec")
+	session.ReplayGenerations(GenerationParams{Seed: Int64(42)})
+	ms := orch.NewManagedStream(context.Background(), session)
+
+	ms.beginTurn()
+	session.AddMessage("user", "hi")
+	ms.runLLMAndTTS(ms.ctx, "hi")
+	closeAndFlush(t, orch, ms)
+
+	if len(llm.params) != 1 || llm.params[0].Seed == nil || *llm.params[0].Seed != 42 {
+		t.Fatalf("expected the replayed seed to be used, got %+v", llm.params)
+	}
+	var recorded *GenerationParams
+	for _, e := range timelineEntries(t, storage.file(t, "/0001/timeline.jsonl")) {
+		if e.Type == BotResponse {
+			recorded = e.Generation
+		}
+	}
+	if recorded == nil || recorded.Seed == nil || *recorded.Seed != 42 {
+		t.Errorf("expected the reply's seed in the timeline, got %+v", recorded)
+	}
+}
+
+func TestRecorder_HonoursConsent(t *testing.T) {
+	storage := &memoryRecordings{}
+	orch, ms := recordedStream(t, storage)
+
+	ms.doWrite(toneChunk(1000))
+	ms.SetConsent(ConsentRecording, false)
+	ms.SetConsent(ConsentTranscript, false)
+	ms.doWrite(toneChunk(1000))
+	ms.speakText(ms.ctx, "Secret.")
+	closeAndFlush(t, orch, ms)
+
+	for _, name := range storage.names() {
+		if strings.HasSuffix(name, ".wav") {
+			t.Errorf("audio recorded without consent: %s", name)
+		}
+	}
+	for _, e := range timelineEntries(t, storage.file(t, "timeline.jsonl")) {
+		if e.Type == BotResponse && e.Data != nil {
+			t.Errorf("transcript recorded without consent: %+v", e)
+		}
+	}
+}
+
+func TestRecorder_RotatesAndEncrypts(t *testing.T) {
+	storage := &memoryRecordings{}
+	enc, err := NewAESGCMEncryptorFromKey(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRecorder(NewConversationSession("rot"), 16000, RecordingConfig{Storage: storage, SegmentDuration: 20 * time.Millisecond, Encryptor: enc})
+
+	r.WriteInbound([]byte{1, 2})
+	time.Sleep(30 * time.Millisecond)
+	r.WriteInbound([]byte{3, 4})
+	r.Close()
+	r.WriteInbound([]byte{5, 6})
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, seg := range []string{"/0001/inbound.wav", "/0002/inbound.wav"} {
+		sealed := storage.file(t, seg)
+		if !IsEncrypted(sealed) {
+			t.Fatalf("%s stored in plaintext", seg)
+		}
+		plain, err := enc.Decrypt(context.Background(), sealed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pcm, _, _ := audio.DecodeWav(plain); len(pcm) != 2 {
+			t.Errorf("%s holds %d bytes", seg, len(pcm))
+		}
+	}
+	if n := len(storage.names()); n != 4 {
+		t.Errorf("expected two segments of two tracks, got %v", storage.names())
+	}
+}
+
+// streamingRecordings takes files as they are written.
+type streamingRecordings struct {
+	memoryRecordings
+	created, deleted []string
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	name    string
+	storage *streamingRecordings
+}
+
+func (f *memoryFile) Close() error {
+	return f.storage.Put(context.Background(), f.name, f.Bytes())
+}
+
+func (s *streamingRecordings) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, name)
+	return &memoryFile{name: name, storage: s}, nil
+}
+
+func (s *streamingRecordings) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, name)
+	delete(s.files, name)
+	return nil
+}
+
+func TestRecorder_StreamsTracks(t *testing.T) {
+	storage := &streamingRecordings{}
+	session := NewConversationSession("stream")
+	r := NewRecorder(session, 16000, RecordingConfig{Storage: storage})
+
+	r.WriteInbound(toneChunk(1000))
+	session.RevokeConsent(ConsentRecording)
+	r.WriteInbound(toneChunk(1000))
+	if len(storage.deleted) != 2 {
+		t.Errorf("expected both tracks deleted when consent was revoked, got %v", storage.deleted)
+	}
+
+	session.GrantConsent(ConsentRecording)
+	in := toneChunk(2000)
+	r.WriteInbound(in)
+	r.Close()
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(storage.created) != 4 {
+		t.Errorf("expected the tracks to be created twice, got %v", storage.created)
+	}
+	pcm, rate, err := audio.DecodeWav(storage.file(t, "/0001/inbound.wav"))
+	if err != nil || rate != 16000 || !bytes.Equal(pcm, in) {
+		t.Errorf("inbound: %d bytes at %dHz, %v", len(pcm), rate, err)
+	}
+}
+
+// halfStreamingRecordings fails to create the second track.
+type halfStreamingRecordings struct {
+	streamingRecordings
+}
+
+func (s *halfStreamingRecordings) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	if strings.HasSuffix(name, "outbound.wav") {
+		return nil, errors.New("disk full")
+	}
+	return s.streamingRecordings.Create(ctx, name)
+}
+
+func TestRecorder_DeletesOrphanTrack(t *testing.T) {
+	storage := &halfStreamingRecordings{}
+	session := NewConversationSession("half")
+	r := NewRecorder(session, 16000, RecordingConfig{Storage: storage})
+
+	in := toneChunk(1000)
+	r.WriteInbound(in)
+	if len(storage.deleted) != 1 || !strings.HasSuffix(storage.deleted[0], "/0001/inbound.wav") {
+		t.Errorf("expected the inbound file to be deleted, got %v", storage.deleted)
+	}
+	r.Close()
+	r.Flush(context.Background())
+	pcm, _, err := audio.DecodeWav(storage.file(t, "/0001/inbound.wav"))
+	if err != nil || !bytes.Equal(pcm, in) {
+		t.Errorf("expected the segment to be buffered instead, got %d bytes, %v", len(pcm), err)
+	}
+}
+
+func TestRecorder_GroupsByUser(t *testing.T) {
+	storage := &memoryRecordings{}
+	session := NewConversationSession("rec")
+	session.UserID = "u1"
+	r := NewRecorder(session, 16000, RecordingConfig{Storage: storage})
+	r.WriteInbound(toneChunk(100))
+	r.Close()
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if names := storage.names(); len(names) == 0 || !strings.HasPrefix(names[0], "users/u1/rec/") {
+		t.Errorf("bundle must be keyed by user and session, got %v", names)
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const defaultResumeWindow = 5 * time.Second
+
+// resumeState is a reply cut off by a barge-in that may turn out false.
+type resumeState struct {
+	cut InterruptedResponse
+	at  time.Time
+}
+
+// armResume remembers a cut-off reply when Config.BargeIn.ResumeAfterFalse
+// is set.
+func (ms *ManagedStream) armResume(cut InterruptedResponse) {
+	if ms.orch == nil || !ms.orch.GetConfig().BargeIn.ResumeAfterFalse {
+		return
+	}
+	ms.mu.Lock()
+	ms.resumable = &resumeState{cut: cut, at: time.Now()}
+	ms.mu.Unlock()
+}
+
+// resumeInterrupted speaks the rest of a reply the caller cut off without
+// saying anything: their utterance produced no transcript or only noise. It
+// restores the full reply in the LLM context.
+func (ms *ManagedStream) resumeInterrupted() {
+	if ms.orch == nil {
+		return
+	}
+	window := ms.orch.GetConfig().BargeIn.ResumeWindow
+	if window <= 0 {
+		window = defaultResumeWindow
+	}
+
+	ms.mu.Lock()
+	r := ms.resumable
+	ms.resumable = nil
+	if r == nil || ms.isClosed || ms.supervised || ms.isSpeaking || ms.isThinking || time.Since(r.at) > window {
+		ms.mu.Unlock()
+		return
+	}
+	rCtx, rCancel := context.WithCancel(ms.ctx)
+	ms.responseCancel = rCancel
+	ms.mu.Unlock()
+	defer rCancel()
+
+	cut := r.cut
+	rest := strings.TrimSpace(strings.TrimPrefix(cut.Generated, cut.Spoken))
+	ms.session.replaceLastAssistant(markInterrupted(cut.Spoken), cut.Generated)
+	ms.emitForTurn(ResponseResumed, rest, cut.TurnID)
+
+	ms.speakResponseAfter(rCtx, cut.TurnID, cut.Spoken, rest)
+	interrupted := rCtx.Err() != nil
+	ms.updateTurn(cut.TurnID, func(t *Turn) {
+		t.Interrupted = interrupted
+		t.EndedAt = time.Now()
+	})
+}
+
+// falseBargeIn is called when the caller's utterance had nothing to answer.
+func (ms *ManagedStream) falseBargeIn() {
+	ms.mu.Lock()
+	armed := ms.resumable != nil
+	ms.mu.Unlock()
+	if armed {
+		ms.spawn(ms.resumeInterrupted)
+	}
+}
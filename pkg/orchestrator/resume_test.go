@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func resumeStream(t *testing.T) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.BargeIn.ResumeAfterFalse = true
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 88200)}, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("resume"))
+	t.Cleanup(ms.Close)
+	ms.EnableClientPlayback()
+	ms.speakText(ms.ctx, tenWords)
+	ms.AckPlayback(PlaybackAck{TurnID: ms.TurnID(), Bytes: 44100})
+	return ms
+}
+
+func TestResumeAfterFalseBargeIn(t *testing.T) {
+	ms := resumeStream(t)
+	turnID := ms.TurnID()
+
+	// A cough: the VAD interrupts, STT hears nothing.
+	ms.internalInterrupt()
+	ms.runBatchPipeline(make([]byte, 8820))
+
+	ev := waitForEvent(t, ms, ResponseResumed)
+	if ev.Data != "six seven eight nine ten" || ev.TurnID != turnID {
+		t.Errorf("unexpected resume %+v", ev)
+	}
+	waitForEvent(t, ms, AudioChunk)
+	if got := lastAssistant(ms); got != tenWords {
+		t.Errorf("expected the full reply back in context, got %q", got)
+	}
+
+	// Cut off again, the annotation covers the whole reply.
+	ms.mu.Lock()
+	ms.lastAudioSentAt = time.Now()
+	ms.mu.Unlock()
+	ms.internalInterrupt()
+	cut, ok := waitForEvent(t, ms, Interrupted).Data.(InterruptedResponse)
+	if !ok || cut.Generated != tenWords {
+		t.Fatalf("unexpected split %+v", cut)
+	}
+}
+
+func TestResumeAfterFalseBargeIn_NotAfterInterrupt(t *testing.T) {
+	ms := resumeStream(t)
+
+	ms.Interrupt()
+	ms.runBatchPipeline(make([]byte, 8820))
+	time.Sleep(50 * time.Millisecond)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == ResponseResumed {
+				t.Fatal("an explicit Interrupt must not be resumed")
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type ArtifactKind string
+
+const (
+	ArtifactRecording  ArtifactKind = "recording"
+	ArtifactTranscript ArtifactKind = "transcript"
+	ArtifactSession    ArtifactKind = "session"
+)
+
+// RetentionPolicy sets how many days each artifact kind is kept. Zero keeps
+// artifacts of that kind forever.
+type RetentionPolicy struct {
+	RecordingDays  int `json:"recording_days"`
+	TranscriptDays int `json:"transcript_days"`
+	SessionDays    int `json:"session_days"`
+}
+
+func (p RetentionPolicy) MaxAge(kind ArtifactKind) time.Duration {
+	days := 0
+	switch kind {
+	case ArtifactRecording:
+		days = p.RecordingDays
+	case ArtifactTranscript:
+		days = p.TranscriptDays
+	case ArtifactSession:
+		days = p.SessionDays
+	}
+	if days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Purgeable is implemented by anything that stores per-user artifacts.
+type Purgeable interface {
+	PurgeBefore(ctx context.Context, cutoff time.Time) (int, error)
+	DeleteUser(ctx context.Context, userID string) (int, error)
+}
+
+type PurgeReport struct {
+	Deleted map[ArtifactKind]int `json:"deleted"`
+	RanAt   time.Time            `json:"ran_at"`
+}
+
+type purgeTarget struct {
+	kind   ArtifactKind
+	target Purgeable
+}
+
+type PurgeScheduler struct {
+	mu       sync.Mutex
+	policy   RetentionPolicy
+	interval time.Duration
+	targets  []purgeTarget
+	logger   Logger
+	now      func() time.Time
+	cancel   context.CancelFunc
+	done     chan struct{}
+	last     PurgeReport
+}
+
+func NewPurgeScheduler(policy RetentionPolicy, interval time.Duration, logger Logger) *PurgeScheduler {
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &PurgeScheduler{
+		policy:   policy,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (p *PurgeScheduler) Register(kind ArtifactKind, target Purgeable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets = append(p.targets, purgeTarget{kind: kind, target: target})
+}
+
+func (p *PurgeScheduler) SetPolicy(policy RetentionPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policy = policy
+}
+
+func (p *PurgeScheduler) Policy() RetentionPolicy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.policy
+}
+
+func (p *PurgeScheduler) LastReport() PurgeReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+func (p *PurgeScheduler) snapshot() (RetentionPolicy, []purgeTarget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	targets := make([]purgeTarget, len(p.targets))
+	copy(targets, p.targets)
+	return p.policy, targets
+}
+
+func (p *PurgeScheduler) RunOnce(ctx context.Context) (PurgeReport, error) {
+	policy, targets := p.snapshot()
+	now := p.now()
+	report := PurgeReport{Deleted: make(map[ArtifactKind]int), RanAt: now}
+
+	var errs []error
+	for _, t := range targets {
+		maxAge := policy.MaxAge(t.kind)
+		if maxAge == 0 {
+			continue
+		}
+		n, err := t.target.PurgeBefore(ctx, now.Add(-maxAge))
+		report.Deleted[t.kind] += n
+		if err != nil {
+			p.logger.Error("retention purge failed", "kind", t.kind, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	p.mu.Lock()
+	p.last = report
+	p.mu.Unlock()
+
+	return report, errors.Join(errs...)
+}
+
+// DeleteUser removes every artifact belonging to userID regardless of age,
+// for right-to-erasure requests. Artifacts are attributed to a user through
+// ConversationSession.UserID.
+func (p *PurgeScheduler) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, errors.New("no user id")
+	}
+	_, targets := p.snapshot()
+	total := 0
+	var errs []error
+	for _, t := range targets {
+		n, err := t.target.DeleteUser(ctx, userID)
+		total += n
+		if err != nil {
+			p.logger.Error("user deletion failed", "kind", t.kind, "userID", userID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+func (p *PurgeScheduler) Start(ctx context.Context) {
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	interval := p.interval
+	done := p.done
+	p.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		p.RunOnce(runCtx)
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				p.RunOnce(runCtx)
+			}
+		}
+	}()
+}
+
+func (p *PurgeScheduler) Stop() {
+	p.mu.Lock()
+	cancel := p.cancel
+	done := p.done
+	p.cancel = nil
+	p.done = nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
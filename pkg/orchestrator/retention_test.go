@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockPurgeable struct {
+	mu          sync.Mutex
+	items       map[string]time.Time
+	purgeCalls  int
+	lastCutoff  time.Time
+	deleteCalls []string
+}
+
+func (m *mockPurgeable) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeCalls++
+	m.lastCutoff = cutoff
+	n := 0
+	for id, created := range m.items {
+		if created.Before(cutoff) {
+			delete(m.items, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockPurgeable) DeleteUser(ctx context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteCalls = append(m.deleteCalls, userID)
+	if _, ok := m.items[userID]; ok {
+		delete(m.items, userID)
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func TestRetentionPolicyMaxAge(t *testing.T) {
+	p := RetentionPolicy{RecordingDays: 7}
+	if p.MaxAge(ArtifactRecording) != 7*24*time.Hour {
+		t.Errorf("unexpected recording max age %v", p.MaxAge(ArtifactRecording))
+	}
+	if p.MaxAge(ArtifactTranscript) != 0 {
+		t.Errorf("expected transcripts kept forever")
+	}
+}
+
+func TestPurgeScheduler_RunOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	recordings := &mockPurgeable{items: map[string]time.Time{
+		"old": now.Add(-10 * 24 * time.Hour),
+		"new": now.Add(-time.Hour),
+	}}
+	transcripts := &mockPurgeable{items: map[string]time.Time{
+		"old": now.Add(-100 * 24 * time.Hour),
+	}}
+
+	p := NewPurgeScheduler(RetentionPolicy{RecordingDays: 7}, time.Hour, nil)
+	p.now = func() time.Time { return now }
+	p.Register(ArtifactRecording, recordings)
+	p.Register(ArtifactTranscript, transcripts)
+
+	report, err := p.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Deleted[ArtifactRecording] != 1 {
+		t.Errorf("expected 1 recording purged, got %d", report.Deleted[ArtifactRecording])
+	}
+	if transcripts.purgeCalls != 0 {
+		t.Errorf("transcripts without retention should not be purged")
+	}
+	if _, ok := recordings.items["new"]; !ok {
+		t.Errorf("recent recording should be kept")
+	}
+	if p.LastReport().RanAt != now {
+		t.Errorf("expected last report to be recorded")
+	}
+}
+
+func TestPurgeScheduler_DeleteUser(t *testing.T) {
+	a := &mockPurgeable{items: map[string]time.Time{"u1": time.Now()}}
+	b := &mockPurgeable{items: map[string]time.Time{"u1": time.Now(), "u2": time.Now()}}
+
+	p := NewPurgeScheduler(RetentionPolicy{}, time.Hour, nil)
+	p.Register(ArtifactRecording, a)
+	p.Register(ArtifactSession, b)
+
+	n, err := p.DeleteUser(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 artifacts deleted, got %d", n)
+	}
+	if _, ok := b.items["u2"]; !ok {
+		t.Errorf("other users must not be affected")
+	}
+}
+
+func TestPurgeScheduler_StartStop(t *testing.T) {
+	target := &mockPurgeable{items: map[string]time.Time{}}
+	p := NewPurgeScheduler(RetentionPolicy{SessionDays: 1}, 10*time.Millisecond, nil)
+	p.Register(ArtifactSession, target)
+
+	p.Start(context.Background())
+	time.Sleep(35 * time.Millisecond)
+	p.Stop()
+
+	target.mu.Lock()
+	calls := target.purgeCalls
+	target.mu.Unlock()
+	if calls < 2 {
+		t.Errorf("expected scheduler to run repeatedly, got %d runs", calls)
+	}
+}
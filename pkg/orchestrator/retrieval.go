@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultRetrievalHeader = "Use the following reference material if it is relevant to the user's last message. " +
+	"If it does not answer the question, say so rather than guessing."
+
+type Document struct {
+	ID       string            `json:"id,omitempty"`
+	Content  string            `json:"content"`
+	Source   string            `json:"source,omitempty"`
+	Score    float64           `json:"score,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type Retriever interface {
+	Query(ctx context.Context, userText string) ([]Document, error)
+}
+
+// RetrievalConfig injects retrieved documents into the prompt of the turn that
+// triggered them. They are not kept in the session history.
+type RetrievalConfig struct {
+	Retriever Retriever
+	MaxChars  int
+	Timeout   time.Duration
+	Header    string
+}
+
+type RetrievalResult struct {
+	Query         string     `json:"query"`
+	Documents     []Document `json:"documents"`
+	InjectedChars int        `json:"injected_chars"`
+
+	message *Message
+}
+
+// retrieve runs the configured retriever for a user turn. It returns nil when
+// retrieval is disabled or failed, in which case the turn proceeds without it.
+func (o *Orchestrator) retrieve(ctx context.Context, session *ConversationSession, userText string) *RetrievalResult {
+	cfg := o.GetConfig().Retrieval
+	if cfg.Retriever == nil || strings.TrimSpace(userText) == "" {
+		return nil
+	}
+
+	qCtx := ctx
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		qCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	docs, err := cfg.Retriever.Query(qCtx, userText)
+	if err != nil {
+		if ctx.Err() == nil {
+			o.logger.Warn("retrieval failed, continuing without context", "sessionID", session.ID, "error", err)
+		}
+		return nil
+	}
+	result := &RetrievalResult{Query: userText, Documents: docs}
+	if msg, ok := retrievalMessage(cfg, result); ok {
+		result.message = &msg
+	}
+	return result
+}
+
+// retrievalMessage formats the documents into a system message, truncated to
+// maxChars.
+func retrievalMessage(cfg RetrievalConfig, result *RetrievalResult) (Message, bool) {
+	if result == nil || len(result.Documents) == 0 {
+		return Message{}, false
+	}
+	maxChars := cfg.MaxChars
+	if maxChars <= 0 {
+		maxChars = 4000
+	}
+	header := cfg.Header
+	if header == "" {
+		header = defaultRetrievalHeader
+	}
+
+	var b strings.Builder
+	injected := 0
+	for i, doc := range result.Documents {
+		content := strings.TrimSpace(doc.Content)
+		if content == "" {
+			continue
+		}
+		if remaining := maxChars - injected; len(content) > remaining {
+			if remaining <= 0 {
+				break
+			}
+			content = truncateRunes(content, remaining)
+		}
+		injected += len(content)
+		if doc.Source != "" {
+			fmt.Fprintf(&b, "\n[%d] (%s) %s", i+1, doc.Source, content)
+		} else {
+			fmt.Fprintf(&b, "\n[%d] %s", i+1, content)
+		}
+	}
+	result.InjectedChars = injected
+	if injected == 0 {
+		return Message{}, false
+	}
+	return Message{Role: "system", Content: header + "\n" + b.String()}, true
+}
+
+func truncateRunes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	for maxBytes > 0 && !isRuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// withRetrievedContext places the retrieval message right before the last user
+// message so it reads as context for that turn.
+func withRetrievedContext(messages []Message, injected Message) []Message {
+	at := len(messages)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			at = i
+			break
+		}
+	}
+	out := make([]Message, 0, len(messages)+1)
+	out = append(out, messages[:at]...)
+	out = append(out, injected)
+	out = append(out, messages[at:]...)
+	return out
+}
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubRetriever struct {
+	docs []Document
+	err  error
+}
+
+func (r *stubRetriever) Query(ctx context.Context, userText string) ([]Document, error) {
+	return r.docs, r.err
+}
+
+type capturingLLM struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (c *capturingLLM) Complete(ctx context.Context, messages []Message) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = messages
+	return "Returns are accepted within 30 days.", nil
+}
+
+func (c *capturingLLM) Name() string { return "capturing" }
+
+func TestRetrievalMessageTruncates(t *testing.T) {
+	result := &RetrievalResult{Documents: []Document{
+		{Content: "abcdef", Source: "faq"},
+		{Content: "ghijkl"},
+		{Content: "never included"},
+	}}
+	msg, ok := retrievalMessage(RetrievalConfig{MaxChars: 9, Header: "ctx"}, result)
+	if !ok {
+		t.Fatal("expected a message")
+	}
+	if msg.Content != "ctx\n\n[1] (faq) abcdef\n[2] ghi" {
+		t.Errorf("unexpected content: %q", msg.Content)
+	}
+	if result.InjectedChars != 9 {
+		t.Errorf("expected 9 injected chars, got %d", result.InjectedChars)
+	}
+}
+
+func TestTruncateRunesKeepsUTF8Valid(t *testing.T) {
+	if got := truncateRunes("añb", 2); got != "a" {
+		t.Errorf("expected split rune to be dropped, got %q", got)
+	}
+}
+
+func TestProcessAudio_InjectsRetrievedContext(t *testing.T) {
+	llm := &capturingLLM{}
+	cfg := DefaultConfig()
+	cfg.Retrieval = RetrievalConfig{Retriever: &stubRetriever{docs: []Document{{Content: "Return window: 30 days", Source: "policy.md"}}}}
+	orch := New(&MockSTTProvider{transcribeResult: "what is the return policy"}, llm, &MockTTSProvider{}, cfg)
+	session := orch.NewSessionWithDefaults("rag")
+	orch.SetSystemPrompt(session, "You are helpful.")
+
+	if _, _, err := orch.ProcessAudio(context.Background(), session, []byte{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(llm.messages) != 3 {
+		t.Fatalf("expected prompt, retrieved context and user turn, got %+v", llm.messages)
+	}
+	if llm.messages[1].Role != "system" || !strings.Contains(llm.messages[1].Content, "(policy.md) Return window: 30 days") {
+		t.Errorf("expected retrieved context before the user turn, got %+v", llm.messages[1])
+	}
+	for _, m := range session.GetContextCopy() {
+		if strings.Contains(m.Content, "Return window") {
+			t.Error("retrieved context must not be persisted in the session")
+		}
+	}
+}
+
+func TestProcessAudio_RetrievalFailureIsNotFatal(t *testing.T) {
+	llm := &capturingLLM{}
+	cfg := DefaultConfig()
+	cfg.Retrieval = RetrievalConfig{Retriever: &stubRetriever{err: errors.New("index offline")}}
+	orch := New(&MockSTTProvider{transcribeResult: "hi"}, llm, &MockTTSProvider{}, cfg)
+
+	if _, _, err := orch.ProcessAudio(context.Background(), orch.NewSessionWithDefaults("rag"), []byte{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(llm.messages) != 1 {
+		t.Errorf("expected no injected context, got %+v", llm.messages)
+	}
+}
+
+func TestManagedStream_ContextRetrievedEvent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Retrieval = RetrievalConfig{Retriever: &stubRetriever{docs: []Document{{ID: "doc-1", Content: "30 days"}}}}
+	orch := New(&MockSTTProvider{}, &capturingLLM{}, &MockTTSProvider{}, cfg)
+	stream := orch.NewManagedStream(context.Background(), orch.NewSessionWithDefaults("rag"))
+	defer stream.Close()
+
+	go stream.runLLMAndTTS(stream.ctx, "return policy?")
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type != ContextRetrieved {
+				continue
+			}
+			result := ev.Data.(RetrievalResult)
+			if result.Query != "return policy?" || len(result.Documents) != 1 || result.InjectedChars != 7 {
+				t.Errorf("unexpected retrieval result: %+v", result)
+			}
+			return
+		case <-deadline:
+			t.Fatal("timed out waiting for ContextRetrieved")
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package orchestrator
+
+// sampleAligner keeps 16-bit PCM aligned across chunks that split a sample,
+// as transports sometimes do: the odd byte of one chunk is held back and
+// joined to the next.
+type sampleAligner struct {
+	odd []byte
+}
+
+// align returns the whole samples available once chunk is added, holding
+// back a trailing odd byte. The result is empty when there are none.
+func (a *sampleAligner) align(chunk []byte) []byte {
+	if len(a.odd) == 0 && len(chunk)%2 == 0 {
+		return chunk
+	}
+	joined := make([]byte, 0, len(a.odd)+len(chunk))
+	joined = append(append(joined, a.odd...), chunk...)
+	n := len(joined) &^ 1
+	a.odd = append(a.odd[:0], joined[n:]...)
+	return joined[:n]
+}
@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSampleAligner(t *testing.T) {
+	var a sampleAligner
+	steps := []struct {
+		in, out []byte
+	}{
+		{[]byte{1, 2}, []byte{1, 2}},
+		{[]byte{3}, nil},
+		{[]byte{}, nil},
+		{[]byte{4, 5, 6}, []byte{3, 4, 5, 6}},
+		{[]byte{7, 8, 9}, []byte{7, 8}},
+		{[]byte{10}, []byte{9, 10}},
+	}
+	for i, s := range steps {
+		if got := a.align(s.in); !bytes.Equal(got, s.out) {
+			t.Errorf("step %d: align(%v) = %v, want %v", i, s.in, got, s.out)
+		}
+	}
+}
+
+func alignStream(t *testing.T) (*ManagedStream, chan []byte) {
+	t.Helper()
+	ms := newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{})
+	stt := make(chan []byte, 1024)
+	ms.mu.Lock()
+	ms.sttChan = stt
+	ms.mu.Unlock()
+	return ms, stt
+}
+
+func TestManagedStream_OddLengthWrites(t *testing.T) {
+	ms, stt := alignStream(t)
+	// A quiet ramp, so no turn starts, whose samples change when misaligned.
+	audio := make([]byte, 8820)
+	for i := 0; i < len(audio); i += 2 {
+		v := int16(i/2%200 - 100)
+		audio[i], audio[i+1] = byte(v), byte(v>>8)
+	}
+	for rest, i := audio, 0; len(rest) > 0; i++ {
+		n := min(len(rest), []int{1, 441, 3, 882, 5}[i%5])
+		if err := ms.doWrite(rest[:n]); err != nil {
+			t.Fatal(err)
+		}
+		rest = rest[n:]
+	}
+
+	var forwarded []byte
+	for len(stt) > 0 {
+		chunk := <-stt
+		if len(chunk)%2 != 0 {
+			t.Errorf("the STT was sent a %d-byte chunk", len(chunk))
+		}
+		forwarded = append(forwarded, chunk...)
+	}
+	if !bytes.Equal(forwarded, audio) {
+		t.Errorf("the STT got %d bytes that differ from the %d written", len(forwarded), len(audio))
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if !bytes.Equal(ms.lastUserAudio, audio) {
+		t.Error("the user's audio was not kept as written")
+	}
+	if ms.stats.AudioBytesIn != int64(len(audio)) {
+		t.Errorf("counted %d bytes in, want %d", ms.stats.AudioBytesIn, len(audio))
+	}
+}
+
+func TestManagedStream_EmptyWrites(t *testing.T) {
+	ms, stt := alignStream(t)
+	for range 50 {
+		if err := ms.Write(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := ms.doWrite([]byte{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ms.writeChan) != 0 {
+		t.Errorf("%d empty chunks were queued", len(ms.writeChan))
+	}
+	if len(stt) != 0 {
+		t.Errorf("%d empty chunks reached the STT", len(stt))
+	}
+	if stats := ms.Status().Stats; stats.AudioBytesIn != 0 || stats.AudioChunksDropped != 0 {
+		t.Errorf("empty writes were counted: %+v", stats)
+	}
+}
+
+func TestManagedStream_OddLengthPlayedOutput(t *testing.T) {
+	whole, _ := alignStream(t)
+	split, _ := alignStream(t)
+	played := speechLike(2205)
+	whole.RecordPlayedOutput(played)
+	for i := 0; i < len(played); i += 7 {
+		split.RecordPlayedOutput(played[i:min(i+7, len(played))])
+	}
+
+	a, b := whole.echoSuppressor, split.echoSuppressor
+	if a.count != b.count {
+		t.Fatalf("recorded %d samples from split chunks, want %d", b.count, a.count)
+	}
+	for i := 0; i < a.count; i++ {
+		if a.getSampleAt(i) != b.getSampleAt(i) {
+			t.Fatalf("sample %d differs: %v != %v", i, b.getSampleAt(i), a.getSampleAt(i))
+		}
+	}
+}
+
+func TestRMSVAD_IgnoresPartialSamples(t *testing.T) {
+	v := NewRMSVAD(0.02, 0)
+	v.SetMinConfirmed(2)
+	v.SetAdaptiveMode(false)
+	loud := toneChunk(8000)
+	if ev, _ := v.Process(loud); ev != nil {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	for _, chunk := range [][]byte{nil, {0x7f}} {
+		if ev, _ := v.Process(chunk); ev != nil {
+			t.Errorf("a %d-byte chunk gave %+v", len(chunk), ev)
+		}
+	}
+	if ev, _ := v.Process(loud); ev == nil || ev.Type != VADSpeechStart {
+		t.Errorf("partial samples broke speech confirmation: %+v", ev)
+	}
+}
+
+func TestNLMSEchoCanceller_OddLength(t *testing.T) {
+	n := NewEchoProcessor(EchoStrategyNLMS, DefaultConfig())
+	n.RecordPlayed(speechLike(441))
+	chunk := append(speechLike(441), 0x42)
+	out, _ := n.Process(chunk, nil)
+	if len(out) != len(chunk) || out[len(out)-1] != 0x42 {
+		t.Errorf("the odd trailing byte was not passed through: %d bytes, last %#x", len(out), out[len(out)-1])
+	}
+}
@@ -0,0 +1,27 @@
+package orchestrator
+
+import "context"
+
+type SayOptions struct {
+	// AddToContext appends the text to the conversation as an assistant
+	// message, so the LLM knows it was said. Announcements and prompts that
+	// aren't part of the dialogue leave it unset.
+	AddToContext bool
+}
+
+// Say speaks text through the stream as its own turn, for greetings, DTMF
+// prompts and announcements from application code. It interrupts any reply
+// in progress, emits BotResponse and the audio like any reply, and can be
+// barged in on. It returns once the audio has been emitted, with
+// ErrSpeechInterrupted when the caller interrupted it, ErrStreamClosed when
+// the stream closed, or ErrSupervised while a supervisor has taken over.
+func (ms *ManagedStream) Say(ctx context.Context, text string) error {
+	return ms.SayWithOptions(ctx, text, SayOptions{})
+}
+
+func (ms *ManagedStream) SayWithOptions(ctx context.Context, text string, opts SayOptions) error {
+	if text == "" {
+		return nil
+	}
+	return ms.say(ctx, text, opts.AddToContext)
+}
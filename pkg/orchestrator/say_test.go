@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingTTS emits one chunk, then holds the synthesis open until cancelled.
+type blockingTTS struct{ MockTTSProvider }
+
+func (b *blockingTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	if err := onChunk(make([]byte, 64)); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func sayStream(t *testing.T, tts TTSProvider) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("say"))
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+func TestSay_SpeaksWithoutTouchingContext(t *testing.T) {
+	ms := sayStream(t, &MockTTSProvider{synthesizeResult: make([]byte, 64)})
+
+	if err := ms.Say(context.Background(), "Press 1 for sales."); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != "Press 1 for sales." || ev.TurnID == "" {
+		t.Errorf("unexpected response %+v", ev)
+	}
+	waitForEvent(t, ms, AudioChunk)
+	if n := len(ms.session.GetContextCopy()); n != 0 {
+		t.Errorf("announcement added to context: %d messages", n)
+	}
+
+	if err := ms.SayWithOptions(context.Background(), "Welcome back.", SayOptions{AddToContext: true}); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := ms.session.GetContextCopy(); len(msgs) != 1 || msgs[0].Role != "assistant" || msgs[0].Content != "Welcome back." {
+		t.Errorf("unexpected context %+v", msgs)
+	}
+}
+
+func TestSay_Interrupted(t *testing.T) {
+	ms := sayStream(t, &blockingTTS{})
+
+	go func() {
+		for ev := range ms.Events() {
+			if ev.Type == AudioChunk {
+				ms.Interrupt()
+				return
+			}
+		}
+	}()
+	if err := ms.Say(context.Background(), "This is a long announcement."); !errors.Is(err, ErrSpeechInterrupted) {
+		t.Fatalf("expected ErrSpeechInterrupted, got %v", err)
+	}
+	if turn, _ := ms.GetTurn(ms.TurnID()); !turn.Interrupted {
+		t.Error("turn must be marked interrupted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := ms.Say(ctx, "Hold on."); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline, got %v", err)
+	}
+
+	ms.Close()
+	if err := ms.Say(context.Background(), "Anyone there?"); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("expected ErrStreamClosed, got %v", err)
+	}
+}
+
+// lingeringTTS is a blockingTTS that takes a while to wind down once
+// cancelled.
+type lingeringTTS struct{ blockingTTS }
+
+func (l *lingeringTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	err := l.blockingTTS.StreamSynthesize(ctx, text, voice, lang, onChunk)
+	time.Sleep(30 * time.Millisecond)
+	return err
+}
+
+func TestSay_ReplacingSpeechKeepsItsState(t *testing.T) {
+	ms := sayStream(t, &lingeringTTS{})
+
+	first := make(chan error, 1)
+	go func() { first <- ms.Say(context.Background(), "First announcement.") }()
+	waitForEvent(t, ms, AudioChunk)
+	second := make(chan error, 1)
+	go func() { second <- ms.Say(context.Background(), "Second announcement.") }()
+	<-first
+
+	// The first announcement has wound down; the second is still speaking.
+	time.Sleep(50 * time.Millisecond)
+	ms.mu.Lock()
+	speaking, cancelable := ms.isSpeaking, ms.ttsCancel != nil
+	ms.mu.Unlock()
+	if !speaking || !cancelable {
+		t.Fatalf("expected the second announcement to keep speaking, got speaking=%v cancelable=%v", speaking, cancelable)
+	}
+	ms.Interrupt()
+	if err := <-second; !errors.Is(err, ErrSpeechInterrupted) {
+		t.Errorf("expected ErrSpeechInterrupted, got %v", err)
+	}
+
+	if err := (&ManagedStream{}).Say(context.Background(), "Hello."); !errors.Is(err, ErrNilProvider) {
+		t.Errorf("expected ErrNilProvider without an orchestrator, got %v", err)
+	}
+}
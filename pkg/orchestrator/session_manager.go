@@ -0,0 +1,313 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionManagerOptions configure a SessionManager.
+type SessionManagerOptions struct {
+	// MaxSessions caps tracked sessions, live or not. Zero means unlimited.
+	// Concurrent streams are capped separately by Config.Capacity.
+	MaxSessions int
+	// IdleTTL evicts sessions that have had no open stream and no activity
+	// for this long. Zero disables eviction.
+	IdleTTL time.Duration
+	// SweepInterval is how often Run looks for idle sessions, IdleTTL/4 by
+	// default.
+	SweepInterval time.Duration
+	SystemPrompt  string
+	// OnEvict, when set, is called for every session removed by eviction,
+	// e.g. to archive its transcript.
+	OnEvict func(session *ConversationSession)
+}
+
+// SessionManager tracks conversation sessions and their managed streams by
+// id, for servers that keep many conversations alive at once.
+type SessionManager struct {
+	orch *Orchestrator
+	opts SessionManagerOptions
+	now  func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*managedSession
+}
+
+type managedSession struct {
+	session    *ConversationSession
+	stream     *ManagedStream
+	createdAt  time.Time
+	lastActive time.Time
+}
+
+// SessionInfo describes a tracked session.
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Live       bool      `json:"live"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastActive time.Time `json:"last_active"`
+}
+
+func NewSessionManager(o *Orchestrator, opts SessionManagerOptions) *SessionManager {
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = opts.IdleTTL / 4
+	}
+	if opts.SweepInterval < time.Second {
+		opts.SweepInterval = time.Second
+	}
+	return &SessionManager{
+		orch:     o,
+		opts:     opts,
+		now:      time.Now,
+		sessions: make(map[string]*managedSession),
+	}
+}
+
+// Create starts tracking a new session with the orchestrator's defaults.
+func (m *SessionManager) Create(id string) (*ConversationSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; ok {
+		return nil, ErrSessionExists
+	}
+	return m.createLocked(id, false)
+}
+
+// GetOrCreate returns the session with id, creating it if needed. With
+// Config.Checkpoint set, an evicted or pre-restart session is restored from
+// the store before a new one is made.
+func (m *SessionManager) GetOrCreate(id string) (*ConversationSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		s.lastActive = m.now()
+		return s.session, nil
+	}
+	return m.createLocked(id, true)
+}
+
+func (m *SessionManager) createLocked(id string, restore bool) (*ConversationSession, error) {
+	if m.opts.MaxSessions > 0 && len(m.sessions) >= m.opts.MaxSessions {
+		return nil, ErrTooManySessions
+	}
+	var session *ConversationSession
+	if restore {
+		var err error
+		if session, err = m.restore(id); err != nil {
+			return nil, err
+		}
+	}
+	if session == nil {
+		session = m.orch.NewSessionWithDefaults(id)
+		if m.opts.SystemPrompt != "" {
+			m.orch.SetSystemPrompt(session, m.opts.SystemPrompt)
+		}
+	}
+	now := m.now()
+	m.sessions[id] = &managedSession{session: session, createdAt: now, lastActive: now}
+	return session, nil
+}
+
+func (m *SessionManager) restore(id string) (*ConversationSession, error) {
+	cfg := m.orch.GetConfig().Checkpoint
+	if cfg.Store == nil {
+		return nil, nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	session, err := m.orch.LoadSession(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, nil
+	}
+	return session, err
+}
+
+// Get returns a tracked session and marks it active.
+func (m *SessionManager) Get(id string) (*ConversationSession, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	s.lastActive = m.now()
+	return s.session, true
+}
+
+// Touch marks a session active, postponing its eviction.
+func (m *SessionManager) Touch(id string) bool {
+	_, ok := m.Get(id)
+	return ok
+}
+
+// Stream returns the session's live stream, starting one if it has none.
+// Closing the stream leaves the session tracked, so a reconnect continues
+// the conversation.
+func (m *SessionManager) Stream(ctx context.Context, id string) (*ManagedStream, error) {
+	return m.stream(ctx, id, true)
+}
+
+// Open is like Stream but fails with ErrSessionLive instead of sharing a
+// stream that is still running, for servers that give each connection its
+// own stream.
+func (m *SessionManager) Open(ctx context.Context, id string) (*ManagedStream, error) {
+	return m.stream(ctx, id, false)
+}
+
+func (m *SessionManager) stream(ctx context.Context, id string, share bool) (*ManagedStream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		if _, err := m.createLocked(id, true); err != nil {
+			return nil, err
+		}
+	}
+	s := m.sessions[id]
+	s.lastActive = m.now()
+	if s.stream != nil && s.stream.ctx.Err() == nil {
+		if !share {
+			return nil, ErrSessionLive
+		}
+		return s.stream, nil
+	}
+	ms := m.orch.NewManagedStream(ctx, s.session)
+	s.stream = ms
+	go m.watch(id, ms)
+	return ms, nil
+}
+
+// watch starts the idle clock once a stream ends.
+func (m *SessionManager) watch(id string, ms *ManagedStream) {
+	<-ms.ctx.Done()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok && s.stream == ms {
+		s.stream = nil
+		s.lastActive = m.now()
+	}
+}
+
+// Remove stops tracking a session and closes its stream.
+func (m *SessionManager) Remove(id string) bool {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	var stream *ManagedStream
+	if ok {
+		stream = s.stream
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+	if stream != nil {
+		stream.Close()
+	}
+	return ok
+}
+
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Sessions describes every tracked session, ordered by id.
+func (m *SessionManager) Sessions() []SessionInfo {
+	m.mu.Lock()
+	infos := make([]SessionInfo, 0, len(m.sessions))
+	for id, s := range m.sessions {
+		infos = append(infos, SessionInfo{
+			ID:         id,
+			Live:       s.stream != nil && s.stream.ctx.Err() == nil,
+			CreatedAt:  s.createdAt,
+			LastActive: s.lastActive,
+		})
+	}
+	m.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Range calls fn for every tracked session in id order until fn returns
+// false. stream is nil for sessions without a live stream.
+func (m *SessionManager) Range(fn func(session *ConversationSession, stream *ManagedStream) bool) {
+	for _, info := range m.Sessions() {
+		m.mu.Lock()
+		s, ok := m.sessions[info.ID]
+		var session *ConversationSession
+		var stream *ManagedStream
+		if ok {
+			session, stream = s.session, s.stream
+		}
+		m.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if !fn(session, stream) {
+			return
+		}
+	}
+}
+
+// EvictIdle removes sessions idle for longer than IdleTTL and returns them.
+func (m *SessionManager) EvictIdle() []*ConversationSession {
+	if m.opts.IdleTTL <= 0 {
+		return nil
+	}
+	cutoff := m.now().Add(-m.opts.IdleTTL)
+
+	m.mu.Lock()
+	var evicted []*ConversationSession
+	for id, s := range m.sessions {
+		if s.stream != nil || s.lastActive.After(cutoff) {
+			continue
+		}
+		delete(m.sessions, id)
+		evicted = append(evicted, s.session)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(evicted, func(i, j int) bool { return evicted[i].ID < evicted[j].ID })
+	if m.opts.OnEvict != nil {
+		for _, session := range evicted {
+			m.opts.OnEvict(session)
+		}
+	}
+	return evicted
+}
+
+// Run evicts idle sessions until ctx is done, then closes every stream.
+func (m *SessionManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.Close()
+			return
+		case <-ticker.C:
+			m.EvictIdle()
+		}
+	}
+}
+
+// Close closes every live stream. Sessions stay tracked.
+func (m *SessionManager) Close() {
+	m.mu.Lock()
+	var streams []*ManagedStream
+	for _, s := range m.sessions {
+		if s.stream != nil {
+			streams = append(streams, s.stream)
+		}
+	}
+	m.mu.Unlock()
+	for _, ms := range streams {
+		ms.Close()
+	}
+}
@@ -0,0 +1,131 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestSessionManager(opts SessionManagerOptions) (*SessionManager, *time.Time) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, 0), cfg)
+	m := NewSessionManager(orch, opts)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestSessionManager_CreateAndLimit(t *testing.T) {
+	m, _ := newTestSessionManager(SessionManagerOptions{MaxSessions: 2, SystemPrompt: "be kind"})
+
+	s, err := m.Create("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx := s.GetContextCopy(); len(ctx) != 1 || ctx[0].Content != "be kind" {
+		t.Errorf("expected system prompt, got %+v", ctx)
+	}
+	if _, err := m.Create("a"); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("expected ErrSessionExists, got %v", err)
+	}
+	if got, _ := m.GetOrCreate("a"); got != s {
+		t.Error("GetOrCreate must return the tracked session")
+	}
+	m.Create("b")
+	if _, err := m.GetOrCreate("c"); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("expected ErrTooManySessions, got %v", err)
+	}
+
+	var ids []string
+	m.Range(func(session *ConversationSession, stream *ManagedStream) bool {
+		ids = append(ids, session.ID)
+		return true
+	})
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("Range visited %v", ids)
+	}
+
+	if !m.Remove("a") || m.Remove("a") || m.Len() != 1 {
+		t.Error("unexpected Remove behaviour")
+	}
+}
+
+func TestSessionManager_Streams(t *testing.T) {
+	m, _ := newTestSessionManager(SessionManagerOptions{})
+
+	ms, err := m.Stream(context.Background(), "call")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.Stream(context.Background(), "call"); again != ms {
+		t.Error("expected the live stream to be reused")
+	}
+	if _, err := m.Open(context.Background(), "call"); !errors.Is(err, ErrSessionLive) {
+		t.Errorf("expected ErrSessionLive, got %v", err)
+	}
+	if infos := m.Sessions(); len(infos) != 1 || !infos[0].Live {
+		t.Errorf("sessions = %+v", infos)
+	}
+
+	ms.Close()
+	next, err := m.Stream(context.Background(), "call")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.Close()
+	if next == ms || next.Session() != ms.Session() {
+		t.Error("expected a new stream on the same session after close")
+	}
+	opened, err := m.Open(context.Background(), "call")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opened.Close()
+	if opened.Session() != ms.Session() {
+		t.Error("expected Open to continue the session")
+	}
+}
+
+func TestSessionManager_EvictsIdleSessions(t *testing.T) {
+	var evicted []string
+	m, now := newTestSessionManager(SessionManagerOptions{
+		IdleTTL: time.Minute,
+		OnEvict: func(s *ConversationSession) { evicted = append(evicted, s.ID) },
+	})
+
+	m.Create("idle")
+	m.Create("busy")
+	live, _ := m.Stream(context.Background(), "live")
+	defer live.Close()
+
+	*now = now.Add(45 * time.Second)
+	m.Touch("busy")
+	*now = now.Add(30 * time.Second)
+
+	m.EvictIdle()
+	if len(evicted) != 1 || evicted[0] != "idle" {
+		t.Errorf("evicted %v, want [idle]", evicted)
+	}
+	if _, ok := m.Get("live"); !ok {
+		t.Error("sessions with a live stream must not be evicted")
+	}
+
+	live.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if infos := m.Sessions(); len(infos) == 2 && !infos[1].Live {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("closed stream still reported live")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	*now = now.Add(2 * time.Minute)
+	m.EvictIdle()
+	if m.Len() != 0 {
+		t.Errorf("expected every session evicted, %d left", m.Len())
+	}
+}
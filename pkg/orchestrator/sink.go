@@ -0,0 +1,22 @@
+package orchestrator
+
+// EventSink receives a copy of every event a ManagedStream emits, except
+// audio chunks, for delivery to external systems. Publish is called with
+// the stream locked and must not block; sinks queue and deliver on their
+// own goroutines.
+type EventSink interface {
+	Publish(event OrchestratorEvent)
+}
+
+// withoutTranscript clears the text of transcript events while
+// ConsentTranscript is revoked, so it doesn't leave the process.
+func withoutTranscript(session *ConversationSession, ev OrchestratorEvent) OrchestratorEvent {
+	switch ev.Type {
+	case TranscriptPartial, TranscriptFinal, TranscriptTimed, TranscriptDropped, Backchannel, BotResponse, SupervisorWhisper,
+		Checkpoint, FactCheckFailed:
+		if !session.HasConsent(ConsentTranscript) {
+			ev.Data = nil
+		}
+	}
+	return ev
+}
@@ -0,0 +1,206 @@
+//go:build soak
+
+package orchestrator
+
+import (
+	"context"
+	"flag"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The soak test talks to a ManagedStream in real time for -soak.duration and
+// checks that it does not leak: run it with
+//
+//	go test -tags soak -run Soak -timeout 0 ./pkg/orchestrator -soak.duration=4h
+var (
+	soakDuration = flag.Duration("soak.duration", time.Minute, "how long the soak test talks to the stream")
+	soakInterval = flag.Duration("soak.interval", 10*time.Second, "how often the soak test samples the stream")
+	soakHeap     = flag.Int("soak.heap", 16<<20, "heap growth in bytes the soak test allows after the first sample")
+)
+
+// soakSample is the state of the process and of the stream at a point in
+// the soak test.
+type soakSample struct {
+	at            time.Duration
+	heap          uint64
+	goroutines    int
+	streamGoros   int
+	turns         int
+	events        int
+	eventsDropped int
+	lastUserAudio int
+	audioBuf      int
+	unplayed      int
+	echoSamples   int
+	echoCapacity  int
+	replyLatency  time.Duration // median over the interval
+	replies       int
+}
+
+func TestSoak_ManagedStream(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	llm := &slowLLM{delay: 100 * time.Millisecond, reply: "Sure, it is ten past four."}
+	tts := &MockTTSProvider{synthesizeResult: flatPCM(24000, 2000)} // 0.5s at 24kHz
+	orch := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, llm, tts, NewRMSVAD(0.02, 300*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("soak"))
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		stopped   time.Time
+		turnID    string
+		played    int64
+	)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for ev := range ms.Events() {
+			mu.Lock()
+			switch ev.Type {
+			case UserStopped:
+				stopped = time.Now()
+			case BotSpeaking:
+				if !stopped.IsZero() {
+					latencies = append(latencies, time.Since(stopped))
+					stopped = time.Time{}
+				}
+			case AudioChunk:
+				// Play it back like a client would, feeding the echo reference.
+				if ev.TurnID != turnID {
+					turnID, played = ev.TurnID, 0
+				}
+				played += int64(len(ev.Data.([]byte)))
+				ms.AckPlayback(PlaybackAck{TurnID: turnID, Bytes: played})
+			}
+			mu.Unlock()
+		}
+	}()
+
+	start := time.Now()
+	sample := func() soakSample {
+		runtime.GC()
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		mu.Lock()
+		window := latencies
+		latencies = nil
+		mu.Unlock()
+		sort.Slice(window, func(i, j int) bool { return window[i] < window[j] })
+
+		status := ms.Status()
+		s := soakSample{
+			at:            time.Since(start).Round(time.Second),
+			heap:          mem.HeapAlloc,
+			goroutines:    runtime.NumGoroutine(),
+			streamGoros:   status.Stats.Goroutines,
+			turns:         len(ms.Turns()),
+			eventsDropped: status.Stats.EventsDropped,
+			replies:       len(window),
+		}
+		if len(window) > 0 {
+			s.replyLatency = window[len(window)/2]
+		}
+		ms.mu.Lock()
+		s.events = len(ms.events)
+		s.lastUserAudio = len(ms.lastUserAudio)
+		s.audioBuf = ms.audioBuf.Len()
+		s.unplayed = ms.playback.pending.Len()
+		ms.mu.Unlock()
+		ms.echoSuppressor.mu.Lock()
+		s.echoSamples, s.echoCapacity = ms.echoSuppressor.count, ms.echoSuppressor.maxSamples
+		ms.echoSuppressor.mu.Unlock()
+
+		t.Logf("%8s heap %6dKiB goroutines %3d (stream %2d) turns %3d events %3d dropped %d lastUserAudio %7dB audioBuf %6dB unplayed %6dB echo %6d/%d reply p50 %v over %d",
+			s.at, s.heap>>10, s.goroutines, s.streamGoros, s.turns, s.events, s.eventsDropped,
+			s.lastUserAudio, s.audioBuf, s.unplayed, s.echoSamples, s.echoCapacity, s.replyLatency.Round(time.Millisecond), s.replies)
+		return s
+	}
+
+	// The caller asks a question about every three seconds and sometimes
+	// goes quiet for half a minute.
+	speech, silence := toneChunk(8000), make([]byte, 882)
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+	write := func(chunk []byte, n int) {
+		for i := 0; i < n; i++ {
+			<-tick.C
+			if err := ms.Write(chunk); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+	}
+
+	var samples []soakSample
+	next := start.Add(*soakInterval)
+	for cycle := 0; time.Since(start) < *soakDuration; cycle++ {
+		write(speech, 80)
+		if cycle%20 == 19 {
+			write(silence, 3000)
+		} else {
+			write(silence, 220)
+		}
+		if time.Now().After(next) {
+			samples = append(samples, sample())
+			next = next.Add(*soakInterval)
+		}
+	}
+	write(silence, 200)
+	samples = append(samples, sample())
+
+	ms.Close()
+	<-drained
+
+	if len(samples) < 2 {
+		t.Fatalf("only %d samples; run for longer than -soak.interval", len(samples))
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	bytesPerSecond := 44100 * 2
+	for _, s := range samples {
+		if s.lastUserAudio > maxUserAudioSeconds*bytesPerSecond {
+			t.Errorf("at %v lastUserAudio holds %d bytes", s.at, s.lastUserAudio)
+		}
+		if s.audioBuf > 2*bytesPerSecond+len(speech) {
+			t.Errorf("at %v audioBuf holds %d bytes", s.at, s.audioBuf)
+		}
+		if s.unplayed > maxUnplayedAudio {
+			t.Errorf("at %v %d bytes are awaiting playback", s.at, s.unplayed)
+		}
+		if s.echoSamples > s.echoCapacity {
+			t.Errorf("at %v the echo reference holds %d samples, more than its %d", s.at, s.echoSamples, s.echoCapacity)
+		}
+		if s.turns > maxTurns {
+			t.Errorf("at %v %d turns are kept", s.at, s.turns)
+		}
+	}
+	if last.eventsDropped > 0 {
+		t.Errorf("%d events were dropped", last.eventsDropped)
+	}
+	if last.heap > first.heap+uint64(*soakHeap) {
+		t.Errorf("heap grew from %dKiB to %dKiB", first.heap>>10, last.heap>>10)
+	}
+	if last.goroutines > first.goroutines+5 {
+		t.Errorf("goroutines grew from %d to %d", first.goroutines, last.goroutines)
+	}
+
+	var early, late time.Duration
+	for _, s := range samples {
+		if s.replies > 0 {
+			if early == 0 {
+				early = s.replyLatency
+			}
+			late = s.replyLatency
+		}
+	}
+	if early == 0 {
+		t.Fatal("the stream never replied")
+	}
+	if late > 2*early+100*time.Millisecond {
+		t.Errorf("reply latency grew from %v to %v", early, late)
+	}
+}
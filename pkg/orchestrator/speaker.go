@@ -0,0 +1,171 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+)
+
+const defaultSpeakerThreshold = 0.7
+
+// SpeakerVerifier turns caller audio into voiceprints and scores how likely
+// it is that audio was spoken by a voiceprint's owner, e.g. a wrapper around
+// a speaker recognition service. Voiceprints are opaque to the orchestrator.
+type SpeakerVerifier interface {
+	Enroll(ctx context.Context, pcm []byte, sampleRate int) ([]byte, error)
+	Verify(ctx context.Context, voiceprint, pcm []byte, sampleRate int) (float64, error)
+}
+
+// SpeakerVerificationConfig checks that callers are who they claim to be
+// (ManagedStream.SetSpeaker) by their voice. Voiceprints enrolled with
+// EnrollSpeaker are kept in Voiceprints as "voiceprints/<speaker>", sealed
+// with Config.Encryptor when set. A score of at least Threshold (0.7 by
+// default) is a match. With EveryTurn, the audio behind every final
+// transcript is verified in the background; otherwise applications call
+// VerifySpeaker themselves, e.g. before a sensitive tool call.
+type SpeakerVerificationConfig struct {
+	Verifier    SpeakerVerifier
+	Voiceprints BlobStore
+	Threshold   float64
+	EveryTurn   bool
+}
+
+// SpeakerResult is the data of SpeakerVerified and SpeakerMismatch events.
+type SpeakerResult struct {
+	Speaker  string  `json:"speaker"`
+	Score    float64 `json:"score"`
+	Verified bool    `json:"verified"`
+}
+
+func voiceprintName(speaker string) string {
+	return "voiceprints/" + speaker
+}
+
+// SetSpeaker sets who the caller claims to be, e.g. from caller ID or an
+// account lookup. Earlier verification results no longer count.
+func (ms *ManagedStream) SetSpeaker(speaker string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.speaker = speaker
+	ms.speakerResult = nil
+}
+
+// SpeakerVerified reports whether the caller's last verification matched
+// the claimed speaker.
+func (ms *ManagedStream) SpeakerVerified() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.speakerResult != nil && ms.speakerResult.Verified && ms.speakerResult.Speaker == ms.speaker
+}
+
+// EnrollSpeaker stores a voiceprint of the caller's last utterance as
+// speaker's and makes speaker the claimed one.
+func (ms *ManagedStream) EnrollSpeaker(ctx context.Context, speaker string) error {
+	cfg := ms.orch.GetConfig()
+	sv := cfg.SpeakerVerification
+	if sv.Verifier == nil || sv.Voiceprints == nil {
+		return ErrNilProvider
+	}
+	pcm := ms.lastUtterance()
+	if len(pcm) == 0 {
+		return ErrNoSpeakerAudio
+	}
+	_, inputRate := ms.sampleRates()
+	voiceprint, err := sv.Verifier.Enroll(ctx, pcm, inputRate)
+	if err != nil {
+		return err
+	}
+	sealed, err := SealIfConfigured(ctx, cfg.Encryptor, voiceprint)
+	if err != nil {
+		return err
+	}
+	if err := sv.Voiceprints.Put(ctx, voiceprintName(speaker), sealed); err != nil {
+		return err
+	}
+	ms.SetSpeaker(speaker)
+	return nil
+}
+
+// VerifySpeaker checks the caller's last utterance against the claimed
+// speaker's voiceprint and emits SpeakerVerified or SpeakerMismatch.
+func (ms *ManagedStream) VerifySpeaker(ctx context.Context) (SpeakerResult, error) {
+	return ms.verifySpeaker(ctx, ms.lastUtterance())
+}
+
+func (ms *ManagedStream) verifySpeaker(ctx context.Context, pcm []byte) (SpeakerResult, error) {
+	cfg := ms.orch.GetConfig()
+	sv := cfg.SpeakerVerification
+	if sv.Verifier == nil || sv.Voiceprints == nil {
+		return SpeakerResult{}, ErrNilProvider
+	}
+	ms.mu.Lock()
+	speaker := ms.speaker
+	ms.mu.Unlock()
+	if speaker == "" {
+		return SpeakerResult{}, ErrNotEnrolled
+	}
+	if len(pcm) == 0 {
+		return SpeakerResult{}, ErrNoSpeakerAudio
+	}
+
+	sealed, err := sv.Voiceprints.Get(ctx, voiceprintName(speaker))
+	if errors.Is(err, ErrBlobNotFound) {
+		return SpeakerResult{}, ErrNotEnrolled
+	}
+	if err != nil {
+		return SpeakerResult{}, err
+	}
+	voiceprint, err := OpenIfConfigured(ctx, cfg.Encryptor, sealed)
+	if err != nil {
+		return SpeakerResult{}, err
+	}
+	_, inputRate := ms.sampleRates()
+	score, err := sv.Verifier.Verify(ctx, voiceprint, pcm, inputRate)
+	if err != nil {
+		return SpeakerResult{}, err
+	}
+
+	threshold := sv.Threshold
+	if threshold <= 0 {
+		threshold = defaultSpeakerThreshold
+	}
+	result := SpeakerResult{Speaker: speaker, Score: score, Verified: score >= threshold}
+	ms.mu.Lock()
+	if ms.speaker == speaker {
+		ms.speakerResult = &result
+	}
+	ms.mu.Unlock()
+
+	if result.Verified {
+		ms.emit(SpeakerVerified, result)
+	} else {
+		ms.emit(SpeakerMismatch, result)
+	}
+	return result, nil
+}
+
+// verifyTurn verifies the utterance behind a final transcript in the
+// background when EveryTurn is set.
+func (ms *ManagedStream) verifyTurn() {
+	if ms.orch == nil || !ms.orch.GetConfig().SpeakerVerification.EveryTurn {
+		return
+	}
+	ms.mu.Lock()
+	claimed := ms.speaker != ""
+	ms.mu.Unlock()
+	if !claimed {
+		return
+	}
+	pcm := ms.lastUtterance()
+	ms.spawn(func() {
+		if _, err := ms.verifySpeaker(ms.ctx, pcm); err != nil && !errors.Is(err, ErrNotEnrolled) {
+			ms.orch.logger.Warn("speaker verification failed", "sessionID", ms.session.ID, "error", err)
+		}
+	})
+}
+
+// lastUtterance returns a copy of the caller's last utterance.
+func (ms *ManagedStream) lastUtterance() []byte {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return append([]byte(nil), ms.lastUserAudio...)
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// amplitudeVerifier's voiceprint is the first sample of the enrolled audio;
+// audio starting with the same sample matches.
+type amplitudeVerifier struct{}
+
+func (amplitudeVerifier) Enroll(ctx context.Context, pcm []byte, sampleRate int) ([]byte, error) {
+	return append([]byte(nil), pcm[:2]...), nil
+}
+
+func (amplitudeVerifier) Verify(ctx context.Context, voiceprint, pcm []byte, sampleRate int) (float64, error) {
+	if bytes.Equal(voiceprint, pcm[:2]) {
+		return 0.95, nil
+	}
+	return 0.2, nil
+}
+
+func newSpeakerStream(t *testing.T, everyTurn bool) (*ManagedStream, *memoryBlobs) {
+	t.Helper()
+	enc, err := NewAESGCMEncryptorFromKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := &memoryBlobs{}
+	cfg := DefaultConfig()
+	cfg.Encryptor = enc
+	cfg.SpeakerVerification = SpeakerVerificationConfig{Verifier: amplitudeVerifier{}, Voiceprints: blobs, EveryTurn: everyTurn}
+	ms := newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "transfer the money"}, &MockLLMProvider{completeResult: "Done."})
+	return ms, blobs
+}
+
+func setUtterance(ms *ManagedStream, pcm []byte) {
+	ms.mu.Lock()
+	ms.lastUserAudio = pcm
+	ms.mu.Unlock()
+}
+
+func TestSpeaker_EnrollAndVerify(t *testing.T) {
+	ms, blobs := newSpeakerStream(t, false)
+	ctx := context.Background()
+
+	if _, err := ms.VerifySpeaker(ctx); !errors.Is(err, ErrNotEnrolled) {
+		t.Fatalf("expected ErrNotEnrolled without a speaker, got %v", err)
+	}
+	if err := ms.EnrollSpeaker(ctx, "alice"); !errors.Is(err, ErrNoSpeakerAudio) {
+		t.Fatalf("expected ErrNoSpeakerAudio, got %v", err)
+	}
+
+	setUtterance(ms, toneChunk(1000))
+	if err := ms.EnrollSpeaker(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := blobs.Get(ctx, "voiceprints/alice"); err != nil || !IsEncrypted(stored) {
+		t.Fatalf("expected a sealed voiceprint, got %v", err)
+	}
+
+	result, err := ms.VerifySpeaker(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified || result.Speaker != "alice" || !ms.SpeakerVerified() {
+		t.Errorf("expected a match, got %+v", result)
+	}
+	waitForEvent(t, ms, SpeakerVerified)
+
+	setUtterance(ms, toneChunk(3000))
+	if result, _ := ms.VerifySpeaker(ctx); result.Verified || ms.SpeakerVerified() {
+		t.Errorf("expected a mismatch, got %+v", result)
+	}
+	if ev := waitForEvent(t, ms, SpeakerMismatch); ev.Data.(SpeakerResult).Score != 0.2 {
+		t.Errorf("unexpected mismatch %+v", ev.Data)
+	}
+
+	ms.SetSpeaker("bob")
+	if _, err := ms.VerifySpeaker(ctx); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("expected ErrNotEnrolled for bob, got %v", err)
+	}
+}
+
+func TestSpeaker_EveryTurn(t *testing.T) {
+	ms, _ := newSpeakerStream(t, true)
+	setUtterance(ms, toneChunk(1000))
+	if err := ms.EnrollSpeaker(context.Background(), "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	speakTo(ms)
+	if ev := waitForEvent(t, ms, SpeakerVerified); ev.Data.(SpeakerResult).Speaker != "alice" {
+		t.Errorf("unexpected result %+v", ev.Data)
+	}
+}
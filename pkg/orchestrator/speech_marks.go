@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// SpeechMark is a viseme or a word boundary in synthesized speech, for
+// lip-synced avatars and live captions.
+type SpeechMark struct {
+	// Viseme is the mouth shape, e.g. "AA", for viseme marks.
+	Viseme string
+	// Word is the word spoken, for word boundary marks.
+	Word string
+	// Offset is from the start of the audio of the call that reported the
+	// mark.
+	Offset   time.Duration
+	Duration time.Duration
+}
+
+// MarkingTTSProvider is implemented by TTS providers that report visemes
+// and word boundaries alongside the audio.
+type MarkingTTSProvider interface {
+	StreamSynthesizeMarks(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error, onMark func(SpeechMark) error) error
+}
+
+// VisemeEvent is the data of a Viseme event. OffsetMs is from the start of
+// the reply's audio, as delivered in AudioChunk events.
+type VisemeEvent struct {
+	Viseme   string `json:"viseme"`
+	OffsetMs int64  `json:"offset_ms"`
+}
+
+// WordBoundaryEvent is the data of a WordBoundary event.
+type WordBoundaryEvent struct {
+	Word       string `json:"word"`
+	OffsetMs   int64  `json:"offset_ms"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+// synthesizeText streams the speech of text, passing its marks to onMark
+// when the provider reports them. base is the reply audio already delivered,
+// which mark offsets are moved by.
+func (ms *ManagedStream) synthesizeText(ctx context.Context, text string, voice Voice, lang Language, base time.Duration, onChunk func([]byte) error, onMark func(SpeechMark) error) error {
+	tts, ok := ms.orch.tts.(MarkingTTSProvider)
+	if !ok || onMark == nil {
+		return ms.orch.SynthesizeStream(ctx, text, voice, lang, onChunk)
+	}
+	return ms.orch.convertTTS(onChunk, func(onChunk func([]byte) error) error {
+		return tts.StreamSynthesizeMarks(ctx, text, voice, lang, onChunk, func(m SpeechMark) error {
+			m.Offset += base
+			return onMark(m)
+		})
+	})
+}
+
+// emitMark emits a mark of the reply of turnID.
+func (ms *ManagedStream) emitMark(m SpeechMark, gen int, turnID string) {
+	switch {
+	case m.Viseme != "":
+		ms.emitTurn(Viseme, VisemeEvent{Viseme: m.Viseme, OffsetMs: m.Offset.Milliseconds()}, gen, turnID)
+	case m.Word != "":
+		ms.emitTurn(WordBoundary, WordBoundaryEvent{Word: m.Word, OffsetMs: m.Offset.Milliseconds(), DurationMs: m.Duration.Milliseconds()}, gen, turnID)
+	}
+}
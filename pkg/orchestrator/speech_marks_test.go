@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// markingTTS answers each request with 100ms of audio at 16kHz, a viseme at
+// its start and a word boundary at 50ms.
+type markingTTS struct {
+	MockTTSProvider
+}
+
+func (m *markingTTS) StreamSynthesizeMarks(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error, onMark func(SpeechMark) error) error {
+	if err := onMark(SpeechMark{Viseme: "AA"}); err != nil {
+		return err
+	}
+	if err := onChunk(make([]byte, 3200)); err != nil {
+		return err
+	}
+	return onMark(SpeechMark{Word: text, Offset: 50 * time.Millisecond, Duration: 40 * time.Millisecond})
+}
+
+func TestManagedStream_SpeechMarks(t *testing.T) {
+	ms := prosodyStream(t, &markingTTS{}, "")
+	if err := ms.say(ms.ctx, "Hi. [pause 100ms] Bye.", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var visemes []VisemeEvent
+	var words []WordBoundaryEvent
+	var turnID string
+	for {
+		select {
+		case ev := <-ms.Events():
+			switch data := ev.Data.(type) {
+			case VisemeEvent:
+				visemes = append(visemes, data)
+			case WordBoundaryEvent:
+				words = append(words, data)
+			default:
+				continue
+			}
+			if turnID == "" {
+				turnID = ev.TurnID
+			} else if ev.TurnID != turnID {
+				t.Errorf("marks of turn %s in turn %s", ev.TurnID, turnID)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	// The second request follows 100ms of speech and a 100ms pause.
+	wantVisemes := []VisemeEvent{{Viseme: "AA"}, {Viseme: "AA", OffsetMs: 200}}
+	wantWords := []WordBoundaryEvent{{Word: "Hi.", OffsetMs: 50, DurationMs: 40}, {Word: "Bye.", OffsetMs: 250, DurationMs: 40}}
+	if len(visemes) != len(wantVisemes) || len(words) != len(wantWords) {
+		t.Fatalf("got visemes %+v and words %+v", visemes, words)
+	}
+	for i := range wantVisemes {
+		if visemes[i] != wantVisemes[i] {
+			t.Errorf("viseme %d: got %+v, want %+v", i, visemes[i], wantVisemes[i])
+		}
+	}
+	for i := range wantWords {
+		if words[i] != wantWords[i] {
+			t.Errorf("word %d: got %+v, want %+v", i, words[i], wantWords[i])
+		}
+	}
+}
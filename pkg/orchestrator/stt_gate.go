@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"math"
+	"time"
+)
+
+const defaultBargeInRMS = 0.03
+
+// STTGateConfig saves streaming STT minutes on talkative agents. With
+// PauseDuringBotSpeech, speech detected while the bot's audio is playing does
+// not open a streaming STT session until it clears the local BargeInRMS energy
+// gate (0.03 by default); quieter audio, which is mostly echo, is only
+// buffered. Once the gate opens the buffer is sent as lead-in, so the start of
+// a real barge-in is not lost. Batch STT providers are not affected.
+type STTGateConfig struct {
+	PauseDuringBotSpeech bool
+	BargeInRMS           float64
+}
+
+// sttGated reports whether chunk must not open streaming STT. dropEcho is
+// set for echo that Config.Echo keeps away from STT.
+func (ms *ManagedStream) sttGated(chunk []byte, dropEcho bool) bool {
+	if ms.orch == nil {
+		return false
+	}
+	cfg := ms.orch.GetConfig().STTGate
+	if !cfg.PauseDuringBotSpeech {
+		return false
+	}
+	if _, ok := ms.orch.stt.(StreamingSTTProvider); !ok {
+		return false
+	}
+
+	ms.mu.Lock()
+	botAudible := ms.isSpeaking || time.Since(ms.lastAudioSentAt) < time.Second
+	ms.mu.Unlock()
+	if !botAudible {
+		return false
+	}
+
+	threshold := cfg.BargeInRMS
+	if threshold <= 0 {
+		threshold = defaultBargeInRMS
+	}
+	return dropEcho || pcmRMS(chunk) < threshold
+}
+
+func pcmRMS(chunk []byte) float64 {
+	if len(chunk) < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i+1 < len(chunk); i += 2 {
+		f := float64(int16(chunk[i])|int16(chunk[i+1])<<8) / 32768.0
+		sum += f * f
+	}
+	return math.Sqrt(sum / float64(len(chunk)/2))
+}
@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingStreamingSTT struct {
+	mu     sync.Mutex
+	opened int
+	bytes  int
+}
+
+func (c *countingStreamingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (string, error) {
+	return "", nil
+}
+func (c *countingStreamingSTT) Name() string { return "counting" }
+func (c *countingStreamingSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	c.mu.Lock()
+	c.opened++
+	c.mu.Unlock()
+	ch := make(chan []byte, 64)
+	go func() {
+		for chunk := range ch {
+			c.mu.Lock()
+			c.bytes += len(chunk)
+			c.mu.Unlock()
+		}
+	}()
+	return ch, nil
+}
+
+func (c *countingStreamingSTT) stats() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.opened, c.bytes
+}
+
+func toneChunk(amplitude int16) []byte {
+	chunk := make([]byte, 882)
+	for i := 0; i < len(chunk); i += 2 {
+		v := amplitude
+		if (i/2)%2 == 1 {
+			v = -amplitude
+		}
+		chunk[i] = byte(v)
+		chunk[i+1] = byte(v >> 8)
+	}
+	return chunk
+}
+
+func TestManagedStream_STTGatePausesDuringBotSpeech(t *testing.T) {
+	stt := &countingStreamingSTT{}
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.STTGate.PauseDuringBotSpeech = true
+	orch := NewWithVAD(stt, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.01, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("gate"))
+	defer ms.Close()
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.lastAudioSentAt = time.Now()
+	ms.mu.Unlock()
+
+	// Above the VAD's bot-speaking threshold, below the barge-in gate.
+	quiet := toneChunk(655) // RMS 0.02
+	for i := 0; i < 5; i++ {
+		ms.doWrite(quiet)
+	}
+	if opened, _ := stt.stats(); opened != 0 {
+		t.Fatalf("streaming STT opened %d times during bot speech", opened)
+	}
+	if ms.Status().Stats.STTAudioSkipped == 0 {
+		t.Error("expected skipped audio to be counted")
+	}
+	if !ms.isSpeaking {
+		t.Error("quiet audio must not interrupt the bot")
+	}
+
+	ms.doWrite(toneChunk(6553)) // RMS 0.2
+	opened, _ := stt.stats()
+	if opened != 1 {
+		t.Fatalf("expected barge-in to open streaming STT, opened %d", opened)
+	}
+	if ms.isSpeaking {
+		t.Error("expected barge-in to interrupt the bot")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		// Buffered lead-in plus the loud chunk.
+		if _, n := stt.stats(); n > len(quiet) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("lead-in audio was not sent to STT")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestManagedStream_STTGateDisabled(t *testing.T) {
+	stt := &countingStreamingSTT{}
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(stt, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.01, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("nogate"))
+	defer ms.Close()
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.lastAudioSentAt = time.Now()
+	ms.mu.Unlock()
+
+	for i := 0; i < 5; i++ {
+		ms.doWrite(toneChunk(655))
+	}
+	if opened, _ := stt.stats(); opened != 1 {
+		t.Errorf("expected streaming STT to open without the gate, opened %d", opened)
+	}
+}
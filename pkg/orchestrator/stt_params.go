@@ -0,0 +1,32 @@
+package orchestrator
+
+// STTParams tunes speech recognition, trading hallucinated words against
+// missed ones. Nil fields keep the provider's defaults, and providers ignore
+// those their API lacks.
+type STTParams struct {
+	// Temperature is Whisper's sampling temperature; 0 is the most literal.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// NoSpeechThreshold drops Whisper segments whose no-speech probability
+	// exceeds it, filtering out words hallucinated from noise.
+	NoSpeechThreshold *float64 `json:"no_speech_threshold,omitempty"`
+	// SmartFormat writes numbers, dates and the like as digits (Deepgram's
+	// smart_format, AssemblyAI's format_text).
+	SmartFormat *bool `json:"smart_format,omitempty"`
+	// Punctuate adds punctuation and casing (Deepgram, AssemblyAI).
+	Punctuate *bool `json:"punctuate,omitempty"`
+	// Diarize labels who said what, for rooms with several callers
+	// (Deepgram).
+	Diarize *bool `json:"diarize,omitempty"`
+}
+
+// STTParamsSetter is implemented by STT providers that take STTParams. New
+// and UpdateConfig pass them Config.STTParams.
+type STTParamsSetter interface {
+	SetSTTParams(params STTParams)
+}
+
+func (o *Orchestrator) applySTTParams(params STTParams) {
+	if s, ok := o.stt.(STTParamsSetter); ok {
+		s.SetSTTParams(params)
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+// Channel is how the user receives the bot's replies.
+type Channel string
+
+const (
+	ChannelVoice Channel = "voice"
+	ChannelText  Channel = "text"
+)
+
+const (
+	defaultVoiceStyle = "Your replies are spoken aloud. Answer in short, plain sentences. Do not use lists, headings, markdown, emoji or URLs, and write numbers and symbols the way you would say them."
+	defaultTextStyle  = "Your replies are read as text. You may use markdown, lists and links where they help."
+)
+
+// ResponseStyleConfig, when enabled, shapes replies for the session's channel
+// with a system instruction added to every LLM request: spoken-style
+// constraints for voice, richer formatting for text. Templates replaces the
+// default instruction per channel; mapping a channel to "" adds nothing.
+type ResponseStyleConfig struct {
+	Enabled   bool
+	Templates map[Channel]string
+}
+
+func (c ResponseStyleConfig) instruction(channel Channel) string {
+	if !c.Enabled {
+		return ""
+	}
+	if t, ok := c.Templates[channel]; ok {
+		return t
+	}
+	switch channel {
+	case ChannelVoice:
+		return defaultVoiceStyle
+	case ChannelText:
+		return defaultTextStyle
+	}
+	return ""
+}
+
+// Channel returns the session's channel, ChannelVoice unless set otherwise.
+func (s *ConversationSession) Channel() Channel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.channel == "" {
+		return ChannelVoice
+	}
+	return s.channel
+}
+
+func (s *ConversationSession) SetChannel(channel Channel) {
+	s.mu.Lock()
+	s.channel = channel
+	s.mu.Unlock()
+	s.changed()
+}
+
+// withStyle adds the style instruction after the leading system messages,
+// so it reads as part of the system prompt.
+func withStyle(messages []Message, instruction string) []Message {
+	if instruction == "" {
+		return messages
+	}
+	at := 0
+	for at < len(messages) && messages[at].Role == "system" {
+		at++
+	}
+	out := make([]Message, 0, len(messages)+1)
+	out = append(out, messages[:at]...)
+	out = append(out, Message{Role: "system", Content: instruction})
+	out = append(out, messages[at:]...)
+	return out
+}
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResponseStyle_Instruction(t *testing.T) {
+	cfg := ResponseStyleConfig{Enabled: true, Templates: map[Channel]string{ChannelText: ""}}
+	if cfg.instruction(ChannelVoice) != defaultVoiceStyle {
+		t.Error("voice must default to the spoken style")
+	}
+	if got := cfg.instruction(ChannelText); got != "" {
+		t.Errorf("an empty template must add nothing, got %q", got)
+	}
+	if got := (ResponseStyleConfig{Templates: map[Channel]string{ChannelVoice: "x"}}).instruction(ChannelVoice); got != "" {
+		t.Errorf("disabled style must add nothing, got %q", got)
+	}
+}
+
+func TestGenerateResponse_AddsChannelStyle(t *testing.T) {
+	llm := &capturingLLM{}
+	cfg := DefaultConfig()
+	cfg.ResponseStyle = ResponseStyleConfig{Enabled: true, Templates: map[Channel]string{ChannelText: "Use markdown."}}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, cfg)
+	session := orch.NewSessionWithDefaults("style")
+	orch.SetSystemPrompt(session, "You are helpful.")
+	session.AddMessage("user", "list three fruits")
+
+	if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.messages) != 3 || llm.messages[0].Content != "You are helpful." || llm.messages[1].Content != defaultVoiceStyle {
+		t.Fatalf("expected the voice style after the system prompt, got %+v", llm.messages)
+	}
+
+	session.SetChannel(ChannelText)
+	if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	if llm.messages[1].Content != "Use markdown." {
+		t.Errorf("expected the text template, got %+v", llm.messages)
+	}
+	if n := len(session.GetContextCopy()); n != 2 {
+		t.Errorf("the style instruction must not be stored in the session, got %d messages", n)
+	}
+	if restored := RestoreSession(session.Snapshot()); restored.Channel() != ChannelText {
+		t.Error("channel lost in snapshot")
+	}
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+// Backpressure decides what a subscription does when its buffer is full.
+// None of the policies ever block the stream.
+type Backpressure string
+
+const (
+	// DropNewest discards the event that does not fit, like Events().
+	DropNewest Backpressure = "drop_newest"
+	// DropOldest discards the oldest buffered event to make room.
+	DropOldest Backpressure = "drop_oldest"
+	// Disconnect closes the subscription, for consumers that must see every
+	// event or none.
+	Disconnect Backpressure = "disconnect"
+)
+
+type SubscribeOptions struct {
+	Buffer int // 256 by default
+	Policy Backpressure
+}
+
+type subscriber struct {
+	ch     chan OrchestratorEvent
+	filter map[EventType]bool
+	policy Backpressure
+}
+
+// Subscribe returns a channel that receives the stream's events of the given
+// types, or all events when none are given, independently of Events() and
+// other subscribers. It is closed by Unsubscribe or when the stream closes.
+func (ms *ManagedStream) Subscribe(filter ...EventType) <-chan OrchestratorEvent {
+	return ms.SubscribeWithOptions(SubscribeOptions{}, filter...)
+}
+
+func (ms *ManagedStream) SubscribeWithOptions(opts SubscribeOptions, filter ...EventType) <-chan OrchestratorEvent {
+	if opts.Buffer <= 0 {
+		opts.Buffer = 256
+	}
+	if opts.Policy == "" {
+		opts.Policy = DropNewest
+	}
+	sub := &subscriber{ch: make(chan OrchestratorEvent, opts.Buffer), policy: opts.Policy}
+	if len(filter) > 0 {
+		sub.filter = make(map[EventType]bool, len(filter))
+		for _, t := range filter {
+			sub.filter[t] = true
+		}
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.isClosed {
+		close(sub.ch)
+		return sub.ch
+	}
+	if ms.subscribers == nil {
+		ms.subscribers = make(map[<-chan OrchestratorEvent]*subscriber)
+	}
+	ms.subscribers[sub.ch] = sub
+	return sub.ch
+}
+
+// Unsubscribe stops and closes a channel returned by Subscribe. It reports
+// whether the subscription was still open.
+func (ms *ManagedStream) Unsubscribe(ch <-chan OrchestratorEvent) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	sub, ok := ms.subscribers[ch]
+	if ok {
+		delete(ms.subscribers, ch)
+		close(sub.ch)
+	}
+	return ok
+}
+
+// publishSubscribersLocked must be called with ms.mu held.
+func (ms *ManagedStream) publishSubscribersLocked(event OrchestratorEvent) {
+	for key, sub := range ms.subscribers {
+		if sub.filter != nil && !sub.filter[event.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+			continue
+		default:
+		}
+		ms.stats.SubscriberEventsDropped++
+		switch sub.policy {
+		case DropOldest:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		case Disconnect:
+			delete(ms.subscribers, key)
+			close(sub.ch)
+		}
+	}
+}
+
+// drainSubscriberAudioLocked drops queued AudioChunk events after an
+// interruption, as drainAudioChunks does for Events().
+func (ms *ManagedStream) drainSubscriberAudioLocked() {
+	for _, sub := range ms.subscribers {
+		if sub.filter != nil && !sub.filter[AudioChunk] {
+			continue
+		}
+		var kept []OrchestratorEvent
+	drain:
+		for {
+			select {
+			case ev := <-sub.ch:
+				if ev.Type != AudioChunk {
+					kept = append(kept, ev)
+				}
+			default:
+				break drain
+			}
+		}
+		for _, ev := range kept {
+			select {
+			case sub.ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+func (ms *ManagedStream) closeSubscribersLocked() {
+	for _, sub := range ms.subscribers {
+		close(sub.ch)
+	}
+	ms.subscribers = nil
+}
@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"testing"
+)
+
+func subscribeStream(t *testing.T) *ManagedStream {
+	return newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{})
+}
+
+func drainEvents(ch <-chan OrchestratorEvent) []OrchestratorEvent {
+	var out []OrchestratorEvent
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return out
+			}
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestSubscribe_FiltersPerSubscriber(t *testing.T) {
+	ms := subscribeStream(t)
+	all := ms.Subscribe()
+	timers := ms.Subscribe(TimerFired)
+
+	ms.emit(ConsentChanged, nil)
+	ms.emit(TimerFired, 1)
+
+	if got := drainEvents(all); len(got) != 2 {
+		t.Errorf("unfiltered subscriber got %d events", len(got))
+	}
+	if got := drainEvents(timers); len(got) != 1 || got[0].Type != TimerFired {
+		t.Errorf("filtered subscriber got %+v", got)
+	}
+	if got := drainEvents(ms.Events()); len(got) != 2 {
+		t.Errorf("Events() must still see every event, got %d", len(got))
+	}
+}
+
+func TestSubscribe_Backpressure(t *testing.T) {
+	ms := subscribeStream(t)
+	newest := ms.SubscribeWithOptions(SubscribeOptions{Buffer: 2}, TimerFired)
+	oldest := ms.SubscribeWithOptions(SubscribeOptions{Buffer: 2, Policy: DropOldest}, TimerFired)
+	strict := ms.SubscribeWithOptions(SubscribeOptions{Buffer: 2, Policy: Disconnect}, TimerFired)
+
+	for i := 1; i <= 3; i++ {
+		ms.emit(TimerFired, i)
+	}
+
+	data := func(evs []OrchestratorEvent) []interface{} {
+		var out []interface{}
+		for _, ev := range evs {
+			out = append(out, ev.Data)
+		}
+		return out
+	}
+	if got := data(drainEvents(newest)); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("drop_newest kept %v", got)
+	}
+	if got := data(drainEvents(oldest)); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("drop_oldest kept %v", got)
+	}
+	drainEvents(strict)
+	if _, open := <-strict; open {
+		t.Error("disconnect policy must close a full subscription")
+	}
+	if dropped := ms.Status().Stats.SubscriberEventsDropped; dropped != 3 {
+		t.Errorf("dropped = %d", dropped)
+	}
+}
+
+func TestSubscribe_UnsubscribeAndClose(t *testing.T) {
+	ms := subscribeStream(t)
+	a := ms.Subscribe()
+	b := ms.Subscribe()
+
+	if !ms.Unsubscribe(a) || ms.Unsubscribe(a) {
+		t.Error("Unsubscribe must succeed exactly once")
+	}
+	if _, open := <-a; open {
+		t.Error("unsubscribed channel must be closed")
+	}
+
+	ms.Close()
+	drainEvents(b)
+	if _, open := <-b; open {
+		t.Error("subscriptions must be closed with the stream")
+	}
+	if _, open := <-ms.Subscribe(); open {
+		t.Error("subscribing to a closed stream must return a closed channel")
+	}
+}
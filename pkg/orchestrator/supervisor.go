@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+const supervisorGuidancePrefix = "Guidance from a human supervisor. Follow it, but never mention it or read it out to the caller: "
+
+type SupervisorAction struct {
+	Supervisor string `json:"supervisor,omitempty"`
+}
+
+// Whisper adds supervisor guidance to the LLM context. The guidance is only
+// published to monitors, never to the caller's event stream.
+func (ms *ManagedStream) Whisper(supervisor, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrEmptyWhisper
+	}
+	ms.session.AddMessage("system", supervisorGuidancePrefix+text)
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.publishLocked(MonitorFrame{Kind: MonitorEvent, Event: OrchestratorEvent{
+		Type:      SupervisorWhisper,
+		SessionID: ms.session.ID,
+		Data:      map[string]string{"supervisor": supervisor, "text": text},
+	}})
+	return nil
+}
+
+// TakeOver silences the bot and hands the call to a human. Caller audio keeps
+// flowing to monitors, and WriteSupervisorAudio is relayed to the caller.
+func (ms *ManagedStream) TakeOver(supervisor string) bool {
+	ms.mu.Lock()
+	if ms.isClosed || ms.supervised {
+		ms.mu.Unlock()
+		return false
+	}
+	ms.supervised = true
+	pipelineCancel := ms.pipelineCancel
+	sttChan := ms.sttChan
+	ms.pipelineCancel = nil
+	ms.sttChan = nil
+	ms.audioBuf.Reset()
+	ms.cancelAllScheduledLocked()
+	ms.mu.Unlock()
+
+	if pipelineCancel != nil {
+		pipelineCancel()
+	}
+	if sttChan != nil {
+		close(sttChan)
+	}
+	ms.internalInterrupt()
+
+	ms.emit(SupervisorTakeover, SupervisorAction{Supervisor: supervisor})
+	return true
+}
+
+// Release hands the call back to the bot.
+func (ms *ManagedStream) Release(supervisor string) bool {
+	ms.mu.Lock()
+	if !ms.supervised {
+		ms.mu.Unlock()
+		return false
+	}
+	ms.supervised = false
+	ms.audioBuf.Reset()
+	if ms.vad != nil {
+		ms.vad.Reset()
+	}
+	ms.mu.Unlock()
+
+	ms.emit(SupervisorReleased, SupervisorAction{Supervisor: supervisor})
+	return true
+}
+
+func (ms *ManagedStream) IsSupervised() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.supervised
+}
+
+// WriteSupervisorAudio relays the supervisor's PCM to the caller as AudioChunk
+// events. It only works while the session is taken over.
+func (ms *ManagedStream) WriteSupervisorAudio(chunk []byte) error {
+	ms.mu.Lock()
+	if !ms.supervised {
+		ms.mu.Unlock()
+		return ErrNotSupervised
+	}
+	ms.lastAudioSentAt = time.Now()
+	ms.lastAudioEmittedAt = ms.lastAudioSentAt
+	gen := ms.payloadGen
+	ms.mu.Unlock()
+
+	ms.emitWithGen(AudioChunk, append([]byte(nil), chunk...), gen)
+	return nil
+}
@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_Whisper(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	session := NewConversationSession("whisper")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	frames, cancel := stream.Monitor(4)
+	defer cancel()
+
+	if err := stream.Whisper("alice", "  "); !errors.Is(err, ErrEmptyWhisper) {
+		t.Errorf("expected ErrEmptyWhisper, got %v", err)
+	}
+	if err := stream.Whisper("alice", "Offer the premium plan"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := session.GetContextCopy()
+	if len(ctx) != 1 || ctx[0].Role != "system" || !strings.HasSuffix(ctx[0].Content, "Offer the premium plan") {
+		t.Errorf("expected guidance in context, got %+v", ctx)
+	}
+
+	if f := <-frames; f.Event.Type != SupervisorWhisper {
+		t.Errorf("expected monitors to see the whisper, got %+v", f)
+	}
+	select {
+	case ev := <-stream.Events():
+		t.Errorf("whisper must not reach the caller, got %v", ev.Type)
+	default:
+	}
+}
+
+func TestManagedStream_TakeOver(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "bot reply"}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("takeover"))
+	defer stream.Close()
+
+	if err := stream.WriteSupervisorAudio([]byte{1}); !errors.Is(err, ErrNotSupervised) {
+		t.Errorf("expected ErrNotSupervised, got %v", err)
+	}
+
+	if !stream.TakeOver("alice") {
+		t.Fatal("expected takeover to succeed")
+	}
+	if stream.TakeOver("bob") {
+		t.Error("second takeover must fail")
+	}
+	if ev := <-stream.Events(); ev.Type != SupervisorTakeover {
+		t.Fatalf("expected SupervisorTakeover, got %v", ev.Type)
+	}
+	if stream.State() != StreamSupervised {
+		t.Errorf("expected supervised state, got %s", stream.State())
+	}
+
+	// The bot stays silent while a human has the call.
+	stream.runLLMAndTTS(stream.ctx, "hello?")
+	if err := stream.WriteSupervisorAudio([]byte{9, 9}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ev := <-stream.Events()
+	if ev.Type != AudioChunk || len(ev.Data.([]byte)) != 2 {
+		t.Fatalf("expected relayed supervisor audio, got %v", ev.Type)
+	}
+
+	if !stream.Release("alice") {
+		t.Fatal("expected release to succeed")
+	}
+	select {
+	case ev := <-stream.Events():
+		if ev.Type != SupervisorReleased {
+			t.Errorf("expected SupervisorReleased, got %v", ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for SupervisorReleased")
+	}
+	if stream.IsSupervised() {
+		t.Error("expected bot to be back in control")
+	}
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+)
+
+type ScheduledTimer struct {
+	ID    string      `json:"id"`
+	Text  string      `json:"text,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Delay int64       `json:"delay_ms"`
+}
+
+type scheduledEntry struct {
+	timer *time.Timer
+	info  ScheduledTimer
+	due   time.Time
+}
+
+// ScheduleSpeech speaks text after delay unless the user becomes active first.
+// A timer that comes due while the caller is talking or the bot is thinking
+// or speaking waits until the stream is idle, so it never cuts off a reply.
+func (ms *ManagedStream) ScheduleSpeech(delay time.Duration, text string) string {
+	return ms.schedule(delay, ScheduledTimer{Text: text}, true, func(info ScheduledTimer) {
+		ms.emit(TimerFired, info)
+		ms.speakText(ms.ctx, info.Text)
+	})
+}
+
+// ScheduleEvent emits a TimerFired event carrying data after delay unless the
+// user becomes active first.
+func (ms *ManagedStream) ScheduleEvent(delay time.Duration, data interface{}) string {
+	return ms.schedule(delay, ScheduledTimer{Data: data}, false, func(info ScheduledTimer) {
+		ms.emit(TimerFired, info)
+	})
+}
+
+// scheduledRetry is how often a speech timer that came due while the stream
+// was busy checks again.
+const scheduledRetry = 200 * time.Millisecond
+
+func (ms *ManagedStream) schedule(delay time.Duration, info ScheduledTimer, waitIdle bool, fire func(ScheduledTimer)) string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.timers == nil {
+		ms.timers = make(map[string]*scheduledEntry)
+	}
+	ms.timerSeq++
+	info.ID = fmt.Sprintf("timer_%d", ms.timerSeq)
+	info.Delay = delay.Milliseconds()
+
+	entry := &scheduledEntry{info: info, due: time.Now().Add(delay)}
+	entry.timer = time.AfterFunc(delay, func() {
+		ms.mu.Lock()
+		current, ok := ms.timers[info.ID]
+		if ok && current == entry && waitIdle && !ms.isClosed && ms.busyLocked() {
+			entry.timer.Reset(scheduledRetry)
+			ms.mu.Unlock()
+			return
+		}
+		if ok && current == entry {
+			delete(ms.timers, info.ID)
+		}
+		closed := ms.isClosed
+		ms.mu.Unlock()
+
+		if !ok || closed || ms.ctx.Err() != nil {
+			return
+		}
+		fire(info)
+	})
+	ms.timers[info.ID] = entry
+	return info.ID
+}
+
+// busyLocked reports whether the caller or the bot has the floor.
+func (ms *ManagedStream) busyLocked() bool {
+	switch ms.stateLocked() {
+	case StreamListening, StreamThinking, StreamSpeaking:
+		return true
+	}
+	return false
+}
+
+func (ms *ManagedStream) CancelScheduled(id string) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	entry, ok := ms.timers[id]
+	if !ok {
+		return false
+	}
+	entry.timer.Stop()
+	delete(ms.timers, id)
+	return true
+}
+
+func (ms *ManagedStream) CancelAllScheduled() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.cancelAllScheduledLocked()
+}
+
+func (ms *ManagedStream) cancelAllScheduledLocked() int {
+	n := 0
+	for id, entry := range ms.timers {
+		if entry.timer.Stop() {
+			n++
+		}
+		delete(ms.timers, id)
+	}
+	return n
+}
+
+func (ms *ManagedStream) PendingScheduled() []ScheduledTimer {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	out := make([]ScheduledTimer, 0, len(ms.timers))
+	for _, entry := range ms.timers {
+		out = append(out, entry.info)
+	}
+	return out
+}
+
+// cancelScheduledOnActivity drops pending timers because the user did
+// something: spoke, typed, pressed talk, interrupted or woke the stream.
+func (ms *ManagedStream) cancelScheduledOnActivity() {
+	ms.mu.Lock()
+	n := ms.cancelAllScheduledLocked()
+	ms.mu.Unlock()
+	if n > 0 && ms.orch != nil {
+		ms.orch.logger.Debug("scheduled timers cancelled by user activity", "sessionID", ms.session.ID, "count", n)
+	}
+}
@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_ScheduleEvent(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("timer"))
+	defer stream.Close()
+
+	id := stream.ScheduleEvent(20*time.Millisecond, "follow-up")
+	if len(stream.PendingScheduled()) != 1 {
+		t.Fatalf("expected 1 pending timer")
+	}
+
+	select {
+	case ev := <-stream.Events():
+		if ev.Type != TimerFired {
+			t.Fatalf("expected TimerFired, got %v", ev.Type)
+		}
+		info := ev.Data.(ScheduledTimer)
+		if info.ID != id || info.Data != "follow-up" {
+			t.Errorf("unexpected timer payload: %+v", info)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out waiting for TimerFired")
+	}
+
+	if len(stream.PendingScheduled()) != 0 {
+		t.Errorf("expected fired timer to be removed")
+	}
+}
+
+func TestManagedStream_ScheduleSpeech(t *testing.T) {
+	tts := &MockTTSProvider{synthesizeResult: []byte{1, 2, 3, 4}}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, Config{})
+	session := NewConversationSession("timer")
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	stream.ScheduleSpeech(10*time.Millisecond, "Are you still there?")
+
+	var sawResponse, sawAudio bool
+	deadline := time.After(time.Second)
+	for !(sawResponse && sawAudio) {
+		select {
+		case ev := <-stream.Events():
+			switch ev.Type {
+			case BotResponse:
+				sawResponse = ev.Data == "Are you still there?"
+			case AudioChunk:
+				sawAudio = true
+			}
+		case <-deadline:
+			t.Fatalf("timed out: response=%v audio=%v", sawResponse, sawAudio)
+		}
+	}
+
+	if session.LastAssistant != "Are you still there?" {
+		t.Errorf("expected scheduled speech in context, got %q", session.LastAssistant)
+	}
+}
+
+func TestManagedStream_CancelScheduled(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("timer"))
+	defer stream.Close()
+
+	id := stream.ScheduleEvent(30*time.Millisecond, nil)
+	if !stream.CancelScheduled(id) {
+		t.Fatal("expected cancel to succeed")
+	}
+	if stream.CancelScheduled(id) {
+		t.Error("expected second cancel to report false")
+	}
+
+	select {
+	case ev := <-stream.Events():
+		t.Fatalf("unexpected event after cancel: %v", ev.Type)
+	case <-time.After(80 * time.Millisecond):
+	}
+}
+
+func TestManagedStream_ScheduledCancelledOnUserSpeech(t *testing.T) {
+	vad := NewRMSVAD(0.1, 100*time.Millisecond)
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, vad, Config{})
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("timer"))
+	defer stream.Close()
+
+	stream.ScheduleSpeech(time.Second, "I'll wait")
+	stream.ScheduleEvent(time.Second, nil)
+
+	loudChunk := make([]byte, 100)
+	for i := 0; i < 100; i += 2 {
+		loudChunk[i] = 0xFF
+		loudChunk[i+1] = 0x7F
+	}
+	for i := 0; i < 20; i++ {
+		stream.Write(loudChunk)
+	}
+
+	deadline := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type != UserSpeaking {
+				continue
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for UserSpeaking")
+		}
+		break
+	}
+
+	if n := len(stream.PendingScheduled()); n != 0 {
+		t.Errorf("expected timers cancelled on user speech, %d pending", n)
+	}
+}
+
+func TestManagedStream_ScheduledCancelledOnUserInput(t *testing.T) {
+	inputs := map[string]func(*ManagedStream){
+		"typed text":   func(ms *ManagedStream) { ms.InjectUserText("hello") },
+		"push to talk": func(ms *ManagedStream) { ms.BeginUserTurn() },
+		"interrupt":    func(ms *ManagedStream) { ms.Interrupt() },
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.TurnDetection = PushToTalk
+			stream := newTestStream(t, cfg, &MockSTTProvider{}, &MockLLMProvider{})
+
+			stream.ScheduleSpeech(time.Second, "I'll wait")
+			input(stream)
+			if n := len(stream.PendingScheduled()); n != 0 {
+				t.Errorf("expected timers cancelled, %d pending", n)
+			}
+		})
+	}
+}
+
+func TestManagedStream_ScheduledSpeechWaitsForReply(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	tts := &MockTTSProvider{synthesizeResult: []byte{1, 2, 3, 4}}
+	orch := New(&MockSTTProvider{}, &slowLLM{delay: 200 * time.Millisecond, reply: "the answer"}, tts, cfg)
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("timer"))
+	defer stream.Close()
+
+	stream.InjectUserText("question")
+	stream.ScheduleSpeech(20*time.Millisecond, "anything else?")
+
+	var responses []interface{}
+	deadline := time.After(2 * time.Second)
+	for len(responses) < 2 {
+		select {
+		case ev := <-stream.Events():
+			switch ev.Type {
+			case BotResponse:
+				responses = append(responses, ev.Data)
+			case Interrupted:
+				t.Fatal("the timer cut off the reply")
+			}
+		case <-deadline:
+			t.Fatalf("timed out with responses %v", responses)
+		}
+	}
+	if responses[0] != "the answer" || responses[1] != "anything else?" {
+		t.Errorf("expected the timer to speak after the reply, got %v", responses)
+	}
+}
@@ -0,0 +1,320 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+const snapshotVersion = 1
+
+var ErrSessionNotFound = errors.New("session not found in store")
+
+// ErrResumeDenied is returned by ResumeManagedStream for a snapshot that was
+// not detached for transfer, or a resume token that doesn't match it.
+var ErrResumeDenied = errors.New("resume token does not match the session")
+
+// SessionStore persists session snapshots so another instance can pick them
+// up. Implementations must be safe for concurrent use.
+type SessionStore interface {
+	Save(ctx context.Context, snapshot SessionSnapshot) error
+	Load(ctx context.Context, sessionID string) (SessionSnapshot, error)
+	Delete(ctx context.Context, sessionID string) error
+	List(ctx context.Context) ([]string, error)
+}
+
+// EncryptorSetter is implemented by session stores that can seal snapshots
+// at rest, as those in pkg/store can. The orchestrator sets it to
+// Config.Encryptor on Config.Checkpoint.Store.
+type EncryptorSetter interface {
+	SetEncryptor(enc Encryptor)
+}
+
+type StreamOptions struct {
+	PlaybackSampleRate int              `json:"playback_sample_rate,omitempty"`
+	InputSampleRate    int              `json:"input_sample_rate,omitempty"`
+	Timers             []ScheduledTimer `json:"timers,omitempty"`
+}
+
+type SessionSnapshot struct {
+	Version        int                  `json:"version"`
+	ID             string               `json:"id"`
+	UserID         string               `json:"user_id,omitempty"`
+	Context        []Message            `json:"context"`
+	LastUser       string               `json:"last_user,omitempty"`
+	LastAssistant  string               `json:"last_assistant,omitempty"`
+	MaxMessages    int                  `json:"max_messages"`
+	MaxTranscript  int                  `json:"max_transcript,omitempty"`
+	Voice          Voice                `json:"voice"`
+	Language       Language             `json:"language"`
+	InputLanguage  Language             `json:"input_language,omitempty"`
+	OutputLanguage Language             `json:"output_language,omitempty"`
+	Generation     GenerationParams     `json:"generation,omitempty"`
+	Priority       Priority             `json:"priority,omitempty"`
+	ConsentDenied  []ConsentScope       `json:"consent_denied,omitempty"`
+	Usage          SessionUsage         `json:"usage"`
+	Pace           PaceMetrics          `json:"pace"`
+	Stream         *StreamOptions       `json:"stream,omitempty"`
+	Transcript     []TranscriptEntry    `json:"transcript,omitempty"`
+	Flow           *FlowStatus          `json:"flow,omitempty"`
+	Disclosed      map[string]time.Time `json:"disclosed,omitempty"`
+	Channel        Channel              `json:"channel,omitempty"`
+	SavedAt        time.Time            `json:"saved_at"`
+	// ResumeTokenHash is the SHA-256 of the token Detach handed to the
+	// client, set only on handoff snapshots.
+	ResumeTokenHash string `json:"resume_token_hash,omitempty"`
+}
+
+func (s *ConversationSession) Snapshot() SessionSnapshot {
+	return s.snapshot(-1)
+}
+
+// snapshot keeps the last maxTranscript transcript entries, or all of them
+// when maxTranscript is negative.
+func (s *ConversationSession) snapshot(maxTranscript int) SessionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transcript := s.transcript
+	if maxTranscript >= 0 && len(transcript) > maxTranscript {
+		transcript = transcript[len(transcript)-maxTranscript:]
+	}
+
+	snap := SessionSnapshot{
+		Version:        snapshotVersion,
+		ID:             s.ID,
+		UserID:         s.UserID,
+		Context:        append([]Message{}, s.Context...),
+		LastUser:       s.LastUser,
+		LastAssistant:  s.LastAssistant,
+		MaxMessages:    s.MaxMessages,
+		MaxTranscript:  s.MaxTranscript,
+		Voice:          s.CurrentVoice,
+		Language:       s.CurrentLanguage,
+		InputLanguage:  s.inputLanguage,
+		OutputLanguage: s.outputLanguage,
+		Generation:     s.Generation,
+		Priority:       s.priority,
+		Usage:          s.usage,
+		Pace:           s.pace.metrics(time.Now()),
+		Transcript:     append([]TranscriptEntry(nil), transcript...),
+		Flow:           s.flowSnapshot(),
+		Disclosed:      maps.Clone(s.disclosed),
+		Channel:        s.channel,
+		SavedAt:        time.Now(),
+	}
+	for scope, denied := range s.consentDenied {
+		if denied {
+			snap.ConsentDenied = append(snap.ConsentDenied, scope)
+		}
+	}
+	sort.Slice(snap.ConsentDenied, func(i, j int) bool { return snap.ConsentDenied[i] < snap.ConsentDenied[j] })
+	return snap
+}
+
+func RestoreSession(snap SessionSnapshot) *ConversationSession {
+	s := NewConversationSession(snap.ID)
+	s.UserID = snap.UserID
+	s.Context = append([]Message{}, snap.Context...)
+	s.LastUser = snap.LastUser
+	s.LastAssistant = snap.LastAssistant
+	// Zero is kept: the session had no cap, e.g. because it is compacted.
+	s.MaxMessages = snap.MaxMessages
+	s.MaxTranscript = snap.MaxTranscript
+	if snap.Voice != "" {
+		s.CurrentVoice = snap.Voice
+	}
+	if snap.Language != "" {
+		s.CurrentLanguage = snap.Language
+	}
+	s.inputLanguage = snap.InputLanguage
+	s.outputLanguage = snap.OutputLanguage
+	s.Generation = snap.Generation
+	s.priority = snap.Priority
+	s.usage = snap.Usage
+	s.pace.PaceMetrics = snap.Pace
+	s.transcript = append([]TranscriptEntry(nil), snap.Transcript...)
+	s.trimTranscriptLocked()
+	s.channel = snap.Channel
+	s.disclosed = maps.Clone(snap.Disclosed)
+	if snap.Flow != nil {
+		flow := snap.Flow.clone()
+		s.flow = &flow
+	}
+	s.RevokeConsent(snap.ConsentDenied...)
+	return s
+}
+
+func (ms *ManagedStream) streamOptions() StreamOptions {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	opts := StreamOptions{
+		PlaybackSampleRate: ms.playbackRate,
+		InputSampleRate:    ms.inputRate,
+	}
+	now := time.Now()
+	for _, entry := range ms.timers {
+		info := entry.info
+		info.Delay = entry.due.Sub(now).Milliseconds()
+		if info.Delay < 0 {
+			info.Delay = 0
+		}
+		opts.Timers = append(opts.Timers, info)
+	}
+	sort.Slice(opts.Timers, func(i, j int) bool { return opts.Timers[i].Delay < opts.Timers[j].Delay })
+	return opts
+}
+
+func (ms *ManagedStream) applyOptions(opts StreamOptions) {
+	if opts.PlaybackSampleRate > 0 && opts.InputSampleRate > 0 {
+		ms.SetEchoSampleRates(opts.PlaybackSampleRate, opts.InputSampleRate)
+	}
+	for _, t := range opts.Timers {
+		delay := time.Duration(t.Delay) * time.Millisecond
+		if t.Text != "" {
+			ms.ScheduleSpeech(delay, t.Text)
+		} else {
+			ms.ScheduleEvent(delay, t.Data)
+		}
+	}
+}
+
+// TransferInfo is the data of SessionTransferred. ResumeToken is only sent
+// to the detached client, which passes it to ResumeManagedStream.
+type TransferInfo struct {
+	Reason      string `json:"reason,omitempty"`
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// Detach saves the stream's session to store and closes the stream, so the
+// call can be resumed elsewhere. The in-flight response, if any, is dropped.
+func (ms *ManagedStream) Detach(ctx context.Context, store SessionStore, reason string) error {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	resumeToken := hex.EncodeToString(token)
+	ms.internalInterrupt()
+	// Stop checkpointing so a late save can't replace the handoff snapshot.
+	ms.session.mu.Lock()
+	ms.session.onChange = nil
+	ms.session.mu.Unlock()
+
+	snap := ms.session.Snapshot()
+	opts := ms.streamOptions()
+	snap.Stream = &opts
+	snap.ResumeTokenHash = hashResumeToken(resumeToken)
+	if err := store.Save(ctx, snap); err != nil {
+		return err
+	}
+
+	ms.emit(SessionTransferred, TransferInfo{Reason: reason, ResumeToken: resumeToken})
+	ms.Close()
+	return nil
+}
+
+// Drain detaches every live stream into store. It keeps going after a failed
+// save and returns the joined errors.
+func (o *Orchestrator) Drain(ctx context.Context, store SessionStore) error {
+	var errs []error
+	for _, ms := range o.Streams() {
+		if err := ms.Detach(ctx, store, "draining"); err != nil {
+			o.logger.Error("failed to detach session", "sessionID", ms.session.ID, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		o.logger.Info("session detached for transfer", "sessionID", ms.session.ID)
+	}
+	return errors.Join(errs...)
+}
+
+// ResumeManagedStream restores a session detached into store and starts a new
+// stream for it. token is the ResumeToken the detached client was sent;
+// snapshots saved any other way, such as checkpoints, are never resumed.
+// The snapshot is removed so the session is only resumed once.
+func (o *Orchestrator) ResumeManagedStream(ctx context.Context, store SessionStore, sessionID, token string) (*ManagedStream, error) {
+	if _, live := o.Stream(sessionID); live {
+		return nil, ErrSessionOwned
+	}
+	snap, err := store.Load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	want, _ := hex.DecodeString(snap.ResumeTokenHash)
+	got, _ := hex.DecodeString(hashResumeToken(token))
+	if token == "" || len(want) == 0 || subtle.ConstantTimeCompare(want, got) != 1 {
+		return nil, ErrResumeDenied
+	}
+	if err := store.Delete(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	session := RestoreSession(snap)
+	if o.GetConfig().Checkpoint.Store != nil {
+		// The handoff snapshot is gone; checkpoint again straight away.
+		o.Checkpoint(session)
+	}
+	ms := newManagedStream(ctx, o, session, false)
+	if snap.Stream != nil {
+		ms.applyOptions(*snap.Stream)
+	}
+	o.logger.Info("session resumed", "sessionID", sessionID, "messages", len(snap.Context))
+	return ms, nil
+}
+
+func hashResumeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]SessionSnapshot
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]SessionSnapshot)}
+}
+
+func (m *MemorySessionStore) Save(ctx context.Context, snapshot SessionSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[snapshot.ID] = snapshot
+	return nil
+}
+
+func (m *MemorySessionStore) Load(ctx context.Context, sessionID string) (SessionSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.sessions[sessionID]
+	if !ok {
+		return SessionSnapshot{}, ErrSessionNotFound
+	}
+	return snap, nil
+}
+
+func (m *MemorySessionStore) Delete(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *MemorySessionStore) List(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
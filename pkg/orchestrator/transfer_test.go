@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionSnapshotRoundTrip(t *testing.T) {
+	session := NewConversationSession("snap")
+	session.AddMessage("system", "prompt")
+	session.AddMessage("user", "hi")
+	session.AddMessage("assistant", "hello")
+	session.CurrentVoice = VoiceM2
+	session.CurrentLanguage = LanguageFr
+	session.SetGenerationParams(GenerationParams{MaxTokens: 50})
+	session.RevokeConsent(ConsentRecording)
+
+	restored := RestoreSession(session.Snapshot())
+	if len(restored.GetContextCopy()) != 3 || restored.LastAssistant != "hello" {
+		t.Errorf("context not restored: %+v", restored.GetContextCopy())
+	}
+	if restored.GetCurrentVoice() != VoiceM2 || restored.GetCurrentLanguage() != LanguageFr {
+		t.Errorf("voice/language not restored")
+	}
+	if restored.GetGenerationParams().MaxTokens != 50 {
+		t.Errorf("generation params not restored")
+	}
+	if restored.HasConsent(ConsentRecording) || !restored.HasConsent(ConsentTranscript) {
+		t.Errorf("consent not restored: %v", restored.GetConsent())
+	}
+}
+
+func TestOrchestrator_DrainAndResume(t *testing.T) {
+	store := NewMemorySessionStore()
+	source := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, DefaultConfig())
+	session := source.NewSessionWithDefaults("call-1")
+	session.AddMessage("user", "my order is 42")
+	stream := source.NewManagedStream(context.Background(), session)
+	stream.SetEchoSampleRates(48000, 16000)
+	stream.ScheduleEvent(time.Hour, "reminder")
+
+	if err := source.Drain(context.Background(), store); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if len(source.Streams()) != 0 {
+		t.Error("expected drained instance to have no live streams")
+	}
+
+	var token string
+	for ev := range stream.Events() {
+		if ev.Type == SessionTransferred {
+			token = ev.Data.(TransferInfo).ResumeToken
+		}
+	}
+	if token == "" {
+		t.Fatal("expected SessionTransferred with a resume token before the stream closed")
+	}
+
+	target := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, DefaultConfig())
+	if _, err := target.ResumeManagedStream(context.Background(), store, "call-1", "guess"); !errors.Is(err, ErrResumeDenied) {
+		t.Fatalf("expected a wrong token to be refused, got %v", err)
+	}
+	resumed, err := target.ResumeManagedStream(context.Background(), store, "call-1", token)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	defer resumed.Close()
+
+	ctx := resumed.Session().GetContextCopy()
+	if len(ctx) != 1 || ctx[0].Content != "my order is 42" {
+		t.Errorf("expected context to survive the transfer, got %+v", ctx)
+	}
+	pending := resumed.PendingScheduled()
+	if len(pending) != 1 || pending[0].Data != "reminder" {
+		t.Errorf("expected pending timer to be carried over, got %+v", pending)
+	}
+	opts := resumed.streamOptions()
+	if opts.PlaybackSampleRate != 48000 || opts.InputSampleRate != 16000 {
+		t.Errorf("expected echo sample rates to be carried over, got %+v", opts)
+	}
+
+	if _, err := target.ResumeManagedStream(context.Background(), store, "call-1", token); !errors.Is(err, ErrSessionOwned) {
+		t.Errorf("expected a live session not to be resumed again, got %v", err)
+	}
+	resumed.Close()
+	if _, err := target.ResumeManagedStream(context.Background(), store, "call-1", token); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("a session must only be resumed once, got %v", err)
+	}
+}
+
+func TestResumeManagedStream_RefusesCheckpoints(t *testing.T) {
+	store := NewMemorySessionStore()
+	cfg := DefaultConfig()
+	cfg.Checkpoint.Store = store
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	session := orch.NewSessionWithDefaults("call-2")
+	session.AddMessage("user", "secret")
+	orch.FlushCheckpoints(context.Background())
+
+	if _, err := orch.ResumeManagedStream(context.Background(), store, "call-2", ""); !errors.Is(err, ErrResumeDenied) {
+		t.Errorf("expected a checkpoint not to be resumable, got %v", err)
+	}
+	if _, err := store.Load(context.Background(), "call-2"); err != nil {
+		t.Errorf("expected the checkpoint to be kept, got %v", err)
+	}
+}
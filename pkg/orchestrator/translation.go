@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TranslationProvider translates text, e.g. a machine translation API.
+type TranslationProvider interface {
+	Translate(ctx context.Context, text string, from, to Language) (string, error)
+	Name() string
+}
+
+// TranslationConfig turns streams into interpreters: instead of replying,
+// they speak each final transcript translated from the session's input
+// language into its output language (see SetInputLanguage). Provider does
+// the translation, or the LLM when nil. Both texts are emitted as a
+// Translation event before the translation is spoken.
+type TranslationConfig struct {
+	Enabled  bool
+	Provider TranslationProvider
+}
+
+// TranslationInfo is the data of a Translation event.
+type TranslationInfo struct {
+	Source string   `json:"source"`
+	Text   string   `json:"text"`
+	From   Language `json:"from"`
+	To     Language `json:"to"`
+}
+
+const translationPrompt = "You are an interpreter. Translate the user's message from %s to %s. Reply with the translation only, keeping its tone, and never answer the message itself."
+
+var languageNames = map[Language]string{
+	LanguageEn: "English", LanguageEs: "Spanish", LanguageFr: "French", LanguageDe: "German",
+	LanguageIt: "Italian", LanguagePt: "Portuguese", LanguageJa: "Japanese", LanguageZh: "Chinese",
+}
+
+func languageName(lang Language) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+	return string(lang)
+}
+
+// SetInputLanguage sets the language the caller speaks, which the STT
+// listens for. Empty falls back to CurrentLanguage.
+func (s *ConversationSession) SetInputLanguage(lang Language) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputLanguage = lang
+}
+
+// SetOutputLanguage sets the language the bot speaks. Empty falls back to
+// CurrentLanguage.
+func (s *ConversationSession) SetOutputLanguage(lang Language) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputLanguage = lang
+}
+
+func (s *ConversationSession) InputLanguage() Language {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.inputLanguage != "" {
+		return s.inputLanguage
+	}
+	return s.CurrentLanguage
+}
+
+func (s *ConversationSession) OutputLanguage() Language {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.outputLanguage != "" {
+		return s.outputLanguage
+	}
+	return s.CurrentLanguage
+}
+
+// Translate translates text with Config.Translation.Provider, or with the
+// LLM when there is none. Text already in the target language is returned
+// as is.
+func (o *Orchestrator) Translate(ctx context.Context, session *ConversationSession, text string, from, to Language) (string, error) {
+	if from == to {
+		return text, nil
+	}
+	if p := o.GetConfig().Translation.Provider; p != nil {
+		return p.Translate(ctx, text, from, to)
+	}
+	translated, usage, err := o.complete(ctx, []Message{
+		{Role: "system", Content: fmt.Sprintf(translationPrompt, languageName(from), languageName(to))},
+		{Role: "user", Content: text},
+	}, GenerationParams{})
+	if err != nil {
+		return "", err
+	}
+	o.recordUsage(session, usage)
+	return strings.TrimSpace(translated), nil
+}
+
+// runTranslation speaks transcript translated, in place of a reply.
+func (ms *ManagedStream) runTranslation(ctx context.Context, transcript string) {
+	ms.mu.Lock()
+	if ms.responseCancel != nil {
+		ms.responseCancel()
+	}
+	if ms.ttsCancel != nil {
+		ms.ttsCancel()
+	}
+	if ms.supervised {
+		ms.mu.Unlock()
+		return
+	}
+	rCtx, rCancel := context.WithCancel(ctx)
+	ms.responseCancel = rCancel
+	ms.isThinking = true
+	ms.resumable = nil
+	ms.llmStartTime = time.Now()
+	ms.mu.Unlock()
+
+	defer rCancel()
+
+	turnID := ms.responseTurn()
+	ms.emitForTurn(BotThinking, nil, turnID)
+
+	from, to := ms.session.InputLanguage(), ms.session.OutputLanguage()
+	translated, err := ms.orch.Translate(rCtx, ms.session, transcript, from, to)
+	if err != nil {
+		if rCtx.Err() == nil {
+			ms.emitForTurn(ErrorEvent, fmt.Sprintf("translation error: %v", err), turnID)
+		}
+		ms.mu.Lock()
+		ms.isThinking = false
+		ms.mu.Unlock()
+		return
+	}
+	ms.mu.Lock()
+	ms.llmEndTime = time.Now()
+	ms.mu.Unlock()
+
+	ms.session.AddMessage("assistant", translated)
+	ms.updateTurn(turnID, func(t *Turn) { t.Response = translated })
+	ms.emitForTurn(Translation, TranslationInfo{Source: transcript, Text: translated, From: from, To: to}, turnID)
+	ms.emitForTurn(BotResponse, translated, turnID)
+
+	ms.speakResponse(rCtx, turnID, translated)
+	latency, interrupted := ms.GetLatencyBreakdown(), rCtx.Err() != nil
+	ms.session.recordTurn(latency, interrupted)
+	ms.updateTurn(turnID, func(t *Turn) {
+		t.Latency = latency
+		t.Interrupted = interrupted
+		t.EndedAt = time.Now()
+	})
+	if !interrupted {
+		ms.sleep()
+	}
+}
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+type prefixTranslator struct{}
+
+func (prefixTranslator) Translate(ctx context.Context, text string, from, to Language) (string, error) {
+	return string(from) + "->" + string(to) + ": " + text, nil
+}
+func (prefixTranslator) Name() string { return "prefix" }
+
+func newInterpreterStream(t *testing.T, stt STTProvider, llm LLMProvider, provider TranslationProvider) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Translation = TranslationConfig{Enabled: true, Provider: provider}
+	ms := newTestStream(t, cfg, stt, llm)
+	ms.session.SetInputLanguage(LanguageEs)
+	ms.session.SetOutputLanguage(LanguageEn)
+	return ms
+}
+
+func TestTranslation_SpeaksTranslatedTranscript(t *testing.T) {
+	stt := &detectingSTT{MockSTTProvider: MockSTTProvider{transcribeResult: "¿dónde está la estación?"}}
+	ms := newInterpreterStream(t, stt, &MockLLMProvider{completeResult: "an answer"}, prefixTranslator{})
+
+	speakTo(ms)
+	ev := waitForEvent(t, ms, Translation)
+	info := ev.Data.(TranslationInfo)
+	if info.Source != "¿dónde está la estación?" || info.Text != "es->en: ¿dónde está la estación?" || info.From != LanguageEs || info.To != LanguageEn {
+		t.Errorf("unexpected translation %+v", info)
+	}
+	if ev := waitForEvent(t, ms, BotResponse); ev.Data != info.Text {
+		t.Errorf("expected the translation to be spoken instead of a reply, got %v", ev.Data)
+	}
+
+	stt.mu.Lock()
+	defer stt.mu.Unlock()
+	if len(stt.asked) != 1 || stt.asked[0] != LanguageEs {
+		t.Errorf("expected the STT to listen for es, got %q", stt.asked)
+	}
+}
+
+func TestTranslation_FallsBackToLLM(t *testing.T) {
+	ms := newInterpreterStream(t, &MockSTTProvider{transcribeResult: "buenos días"}, &MockLLMProvider{completeResult: " Good morning. "}, nil)
+
+	speakTo(ms)
+	if ev := waitForEvent(t, ms, Translation); ev.Data.(TranslationInfo).Text != "Good morning." {
+		t.Errorf("unexpected translation %+v", ev.Data)
+	}
+}
+
+func TestSession_InputAndOutputLanguages(t *testing.T) {
+	s := NewConversationSession("languages")
+	if s.InputLanguage() != LanguageEn || s.OutputLanguage() != LanguageEn {
+		t.Fatal("expected both to follow CurrentLanguage by default")
+	}
+	s.SetInputLanguage(LanguageFr)
+	s.SetOutputLanguage(LanguageJa)
+
+	restored := RestoreSession(s.Snapshot())
+	if restored.InputLanguage() != LanguageFr || restored.OutputLanguage() != LanguageJa {
+		t.Errorf("expected the languages to survive a snapshot, got %s and %s", restored.InputLanguage(), restored.OutputLanguage())
+	}
+}
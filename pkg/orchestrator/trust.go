@@ -0,0 +1,120 @@
+package orchestrator
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Levels at which a trust component is fully trusted.
+const (
+	trustFullRMS      = 0.05
+	trustFullSNR      = 30.0 // dB
+	trustFullDuration = time.Second
+	trustMaxSNR       = 60.0 // dB, for digitally silent noise floors
+)
+
+// TrustConfig scores each final transcript by how much it can be trusted:
+// the STT's confidence, when the provider reports one, weighed with the
+// utterance's loudness, signal-to-noise ratio and length. The score is
+// attached to TranscriptFinal events (OrchestratorEvent.Trust) and kept on
+// the turn. With MinScore, transcripts scoring lower are dropped, reported as
+// TranscriptDropped with reason "trust" and handled like noise.
+type TrustConfig struct {
+	Enabled  bool
+	MinScore float64
+}
+
+// TrustScore is the trust in a transcript, Score, between 0 and 1, and what
+// it was computed from. Confidence is 0 when the STT provider doesn't report
+// one; it is then left out of Score. Energy is the RMS of the loudest 20ms
+// and SNR its ratio in dB to the quietest tenth of the utterance.
+type TrustScore struct {
+	Score      float64       `json:"score"`
+	Confidence float64       `json:"confidence,omitempty"`
+	Energy     float64       `json:"energy"`
+	SNR        float64       `json:"snr_db"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// scoreTrust scores a transcript of audio, 16-bit PCM at sampleRate.
+func scoreTrust(audio []byte, sampleRate int, confidence float64) TrustScore {
+	frameBytes := sampleRate / 50 * 2
+	var levels []float64
+	for i := 0; i+frameBytes <= len(audio); i += frameBytes {
+		levels = append(levels, pcmRMS(audio[i:i+frameBytes]))
+	}
+	if len(levels) == 0 {
+		levels = append(levels, pcmRMS(audio))
+	}
+	sort.Float64s(levels)
+	peak, floor := levels[len(levels)-1], levels[len(levels)/10]
+
+	t := TrustScore{
+		Confidence: confidence,
+		Energy:     peak,
+		SNR:        trustMaxSNR,
+		Duration:   time.Duration(len(audio)/2) * time.Second / time.Duration(sampleRate),
+	}
+	if floor > 0 {
+		t.SNR = math.Max(0, math.Min(trustMaxSNR, 20*math.Log10(peak/floor)))
+	}
+	if peak == 0 {
+		t.SNR = 0
+	}
+
+	score := 0.2*math.Min(1, t.Energy/trustFullRMS) +
+		0.25*math.Min(1, t.SNR/trustFullSNR) +
+		0.15*math.Min(1, float64(t.Duration)/float64(trustFullDuration))
+	weight := 0.6
+	if confidence > 0 {
+		score += 0.4 * math.Min(1, confidence)
+		weight = 1
+	}
+	t.Score = score / weight
+	return t
+}
+
+// transcriptTrust scores a final transcript against the utterance's audio,
+// or returns nil when scoring is off or there is no audio.
+func (ms *ManagedStream) transcriptTrust(confidence float64) *TrustScore {
+	if ms.orch == nil || !ms.orch.GetConfig().Trust.Enabled {
+		return nil
+	}
+	ms.mu.Lock()
+	audio := ms.lastUserAudio
+	ms.mu.Unlock()
+	if len(audio) < 2 {
+		return nil
+	}
+	_, inputRate := ms.sampleRates()
+	t := scoreTrust(audio, inputRate, confidence)
+	return &t
+}
+
+// distrusted reports, and drops, a final transcript scored below MinScore.
+func (ms *ManagedStream) distrusted(transcript string, trust *TrustScore, turnID string) bool {
+	if trust == nil || trust.Score >= ms.orch.GetConfig().Trust.MinScore {
+		return false
+	}
+	ms.mu.Lock()
+	ms.stats.TranscriptsDropped++
+	ms.mu.Unlock()
+	ms.emitForTurn(TranscriptDropped, DroppedTranscript{Text: transcript, Reason: "trust"}, turnID)
+	return true
+}
+
+// emitFinal records transcript on its turn, emits TranscriptFinal and saves
+// the turn's audio.
+func (ms *ManagedStream) emitFinal(transcript string, trust *TrustScore, turnID string) {
+	ms.updateTurn(turnID, func(t *Turn) {
+		t.Transcript = transcript
+		t.Trust = trust
+	})
+	ms.mu.Lock()
+	gen := ms.payloadGen
+	ms.mu.Unlock()
+	ms.emitEvent(OrchestratorEvent{Type: TranscriptFinal, TurnID: turnID, Data: transcript, Generation: gen, Trust: trust})
+	ms.saveTurnAudio(turnID)
+	ms.verifyTurn()
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScoreTrust(t *testing.T) {
+	clean := append(flatPCM(88200, 8000), flatPCM(17640, 30)...) // 1s of speech, 200ms of quiet
+	if got := scoreTrust(clean, 44100, 0); got.Score < 0.99 || got.SNR < 40 || got.Duration != 1200*time.Millisecond {
+		t.Errorf("expected full trust in a clean utterance, got %+v", got)
+	}
+	if got := scoreTrust(clean, 44100, 0.5); got.Score < 0.79 || got.Score > 0.81 {
+		t.Errorf("expected the STT's doubt to lower the score, got %+v", got)
+	}
+	if got := scoreTrust(flatPCM(44100, 300), 44100, 0); got.Score > 0.3 || got.SNR != 0 {
+		t.Errorf("expected little trust in a quiet, noisy utterance, got %+v", got)
+	}
+	if got := scoreTrust(flatPCM(44100, 0), 44100, 0); got.Score > 0.15 {
+		t.Errorf("expected little trust in silence, got %+v", got)
+	}
+}
+
+type confidentSTT struct{ MockSTTProvider }
+
+func (s *confidentSTT) TranscribeDetailed(ctx context.Context, audio []byte, lang Language) (*TranscriptionResult, error) {
+	return &TranscriptionResult{Text: s.transcribeResult, Confidence: 0.9}, nil
+}
+
+func TestTrust_AttachedToTranscriptFinal(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Trust.Enabled = true
+	stream := newTestStream(t, cfg, &confidentSTT{MockSTTProvider{transcribeResult: "check my balance"}}, &MockLLMProvider{completeResult: "Sure."})
+	turnID := stream.beginTurn()
+
+	stream.runBatchPipeline(append(flatPCM(88200, 8000), flatPCM(17640, 30)...))
+
+	ev := waitForEvent(t, stream, TranscriptFinal)
+	if ev.Data != "check my balance" || ev.Trust == nil || ev.Trust.Confidence != 0.9 || ev.Trust.Score < 0.95 {
+		t.Fatalf("unexpected final %+v, trust %+v", ev, ev.Trust)
+	}
+	if turn, _ := stream.GetTurn(turnID); turn.Trust == nil || *turn.Trust != *ev.Trust {
+		t.Errorf("expected the score on the turn, got %+v", turn.Trust)
+	}
+}
+
+func TestTrust_MinScoreDrops(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Trust = TrustConfig{Enabled: true, MinScore: 0.5}
+	stream := newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "uh check"}, &MockLLMProvider{completeResult: "Sure."})
+
+	stream.runBatchPipeline(flatPCM(44100, 300))
+
+	ev := waitForEvent(t, stream, TranscriptDropped)
+	if d, ok := ev.Data.(DroppedTranscript); !ok || d.Reason != "trust" {
+		t.Errorf("unexpected drop %#v", ev.Data)
+	}
+	if n := stream.Status().Stats.TranscriptsDropped; n != 1 {
+		t.Errorf("expected 1 dropped transcript, got %d", n)
+	}
+}
+
+func TestTrust_OffByDefault(t *testing.T) {
+	stream := newTestStream(t, DefaultConfig(), &MockSTTProvider{transcribeResult: "check my balance"}, &MockLLMProvider{completeResult: "Sure."})
+
+	stream.runBatchPipeline(flatPCM(44100, 300))
+
+	if ev := waitForEvent(t, stream, TranscriptFinal); ev.Trust != nil {
+		t.Errorf("expected no score, got %+v", ev.Trust)
+	}
+}
@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TTSCacheConfig caches synthesized speech by text, voice and language, so
+// phrases that come up again ("How can I help?", error messages, IVR menus)
+// are played without calling the TTS provider. Speech is kept in memory,
+// least recently used first out once MaxBytes (32MiB by default) is
+// exceeded. Texts longer than MaxTextLength (200 bytes by default) rarely
+// repeat and are not cached.
+//
+// Store, when set, backs the memory cache, e.g. store.RecordingDir on disk
+// or store.RedisBlobStore, so cached speech survives restarts and is shared
+// between instances.
+type TTSCacheConfig struct {
+	Enabled       bool
+	MaxBytes      int
+	MaxTextLength int
+	Store         BlobStore
+}
+
+const (
+	defaultTTSCacheBytes   = 32 << 20
+	defaultTTSCacheTextLen = 200
+)
+
+func (c TTSCacheConfig) caches(text string) bool {
+	maxLen := c.MaxTextLength
+	if maxLen <= 0 {
+		maxLen = defaultTTSCacheTextLen
+	}
+	return c.Enabled && text != "" && len(text) <= maxLen
+}
+
+// ttsCache is the in-memory tier of the TTS cache.
+type ttsCache struct {
+	mu      sync.Mutex
+	order   *list.List // of *ttsCacheEntry, most recently used first
+	entries map[string]*list.Element
+	bytes   int
+}
+
+type ttsCacheEntry struct {
+	key string
+	pcm []byte
+}
+
+func (c *ttsCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*ttsCacheEntry).pcm, true
+}
+
+func (c *ttsCache) put(key string, pcm []byte, maxBytes int) {
+	if maxBytes <= 0 {
+		maxBytes = defaultTTSCacheBytes
+	}
+	if len(pcm) > maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.order = list.New()
+	}
+	if el, ok := c.entries[key]; ok {
+		c.bytes -= len(el.Value.(*ttsCacheEntry).pcm)
+		c.order.Remove(el)
+	}
+	c.entries[key] = c.order.PushFront(&ttsCacheEntry{key: key, pcm: pcm})
+	c.bytes += len(pcm)
+	for c.bytes > maxBytes {
+		oldest := c.order.Remove(c.order.Back()).(*ttsCacheEntry)
+		delete(c.entries, oldest.key)
+		c.bytes -= len(oldest.pcm)
+	}
+}
+
+// ttsCacheKey identifies speech by everything that shapes the audio: the
+// provider, the output rate, the voice, the language and the text.
+func (o *Orchestrator) ttsCacheKey(text string, voice Voice, lang Language) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s", o.tts.Name(), o.GetConfig().SampleRate, voice, lang, text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedSpeech looks speech up in memory, then in the cache's Store.
+func (o *Orchestrator) cachedSpeech(ctx context.Context, cfg TTSCacheConfig, key string) ([]byte, bool) {
+	if pcm, ok := o.ttsCache.get(key); ok {
+		return pcm, true
+	}
+	if cfg.Store == nil {
+		return nil, false
+	}
+	pcm, err := cfg.Store.Get(ctx, "tts/"+key+".pcm")
+	if err != nil {
+		if !errors.Is(err, ErrBlobNotFound) {
+			o.logger.Warn("tts cache lookup failed", "error", err)
+		}
+		return nil, false
+	}
+	if len(pcm) == 0 {
+		return nil, false
+	}
+	o.ttsCache.put(key, pcm, cfg.MaxBytes)
+	return pcm, true
+}
+
+func (o *Orchestrator) cacheSpeech(ctx context.Context, cfg TTSCacheConfig, key string, pcm []byte) {
+	if len(pcm) == 0 {
+		return
+	}
+	o.ttsCache.put(key, pcm, cfg.MaxBytes)
+	if cfg.Store == nil {
+		return
+	}
+	if err := cfg.Store.Put(ctx, "tts/"+key+".pcm", pcm); err != nil {
+		o.logger.Warn("tts cache store failed", "error", err)
+	}
+}
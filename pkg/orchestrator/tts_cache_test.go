@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countingTTS speaks each text as two chunks derived from it, and counts
+// its calls.
+type countingTTS struct {
+	MockTTSProvider
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if err := onChunk([]byte(text)); err != nil {
+		return err
+	}
+	return onChunk([]byte(voice))
+}
+
+func (c *countingTTS) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func cachedSay(t *testing.T, o *Orchestrator, text string, voice Voice) []byte {
+	t.Helper()
+	var pcm []byte
+	if err := o.SynthesizeStream(context.Background(), text, voice, LanguageEn, func(chunk []byte) error {
+		pcm = append(pcm, chunk...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return pcm
+}
+
+func TestTTSCache(t *testing.T) {
+	tts := &countingTTS{}
+	cfg := DefaultConfig()
+	cfg.TTSCache.Enabled = true
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+
+	first := cachedSay(t, o, "How can I help?", VoiceF1)
+	again := cachedSay(t, o, "How can I help?", VoiceF1)
+	if !bytes.Equal(first, again) || tts.count() != 1 {
+		t.Errorf("expected the repeat from the cache: %q, %q after %d calls", first, again, tts.count())
+	}
+	again[0] = 'X'
+	if cached := cachedSay(t, o, "How can I help?", VoiceF1); !bytes.Equal(cached, first) {
+		t.Errorf("a caller's changes reached the cache: %q", cached)
+	}
+
+	cachedSay(t, o, "How can I help?", VoiceM1)
+	if tts.count() != 2 {
+		t.Errorf("another voice should be synthesized, got %d calls", tts.count())
+	}
+	long := strings.Repeat("a", defaultTTSCacheTextLen+1)
+	cachedSay(t, o, long, VoiceF1)
+	cachedSay(t, o, long, VoiceF1)
+	if tts.count() != 4 {
+		t.Errorf("long texts should not be cached, got %d calls", tts.count())
+	}
+
+	// Speech cut short is not kept.
+	stop := errors.New("barge-in")
+	err := o.SynthesizeStream(context.Background(), "Sorry, I didn't catch that.", VoiceF1, LanguageEn, func([]byte) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the callback's error, got %v", err)
+	}
+	cachedSay(t, o, "Sorry, I didn't catch that.", VoiceF1)
+	if tts.count() != 6 {
+		t.Errorf("interrupted speech was cached, got %d calls", tts.count())
+	}
+}
+
+func TestTTSCache_Disabled(t *testing.T) {
+	tts := &countingTTS{}
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, DefaultConfig())
+	cachedSay(t, o, "Hello", VoiceF1)
+	cachedSay(t, o, "Hello", VoiceF1)
+	if tts.count() != 2 {
+		t.Errorf("expected no caching by default, got %d calls", tts.count())
+	}
+}
+
+func TestTTSCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	var c ttsCache
+	c.put("a", make([]byte, 40), 100)
+	c.put("b", make([]byte, 40), 100)
+	c.get("a")
+	c.put("c", make([]byte, 40), 100)
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("expected a, used recently, to be kept")
+	}
+	c.put("huge", make([]byte, 101), 100)
+	if _, ok := c.get("huge"); ok || c.bytes != 80 {
+		t.Errorf("an entry over the limit was cached, %d bytes held", c.bytes)
+	}
+}
+
+func TestTTSCache_Store(t *testing.T) {
+	blobs := &memoryBlobs{}
+	cfg := DefaultConfig()
+	cfg.TTSCache = TTSCacheConfig{Enabled: true, Store: blobs}
+
+	tts := &countingTTS{}
+	first := cachedSay(t, New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg), "Press one for sales.", VoiceF1)
+
+	// Another instance finds it in the store.
+	other := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg)
+	if got := cachedSay(t, other, "Press one for sales.", VoiceF1); !bytes.Equal(got, first) {
+		t.Errorf("got %q from the store, want %q", got, first)
+	}
+	if pcm, err := other.Synthesize(context.Background(), "Press one for sales.", VoiceF1, LanguageEn); err != nil || !bytes.Equal(pcm, first) {
+		t.Errorf("Synthesize got %q, %v", pcm, err)
+	}
+	if tts.count() != 1 {
+		t.Errorf("expected one synthesis, got %d", tts.count())
+	}
+
+	// Speech at another rate is different audio.
+	cfg.SampleRate = 16000
+	cachedSay(t, New(&MockSTTProvider{}, &MockLLMProvider{}, tts, cfg), "Press one for sales.", VoiceF1)
+	if tts.count() != 2 {
+		t.Errorf("speech at another rate came from the cache")
+	}
+}
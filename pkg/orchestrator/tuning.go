@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultMinConfirmed is how many loud frames the VAD needs before it
+// reports speech on a stream that has not been tuned.
+const defaultMinConfirmed = 2
+
+// Tuning adjusts a live stream's detection settings, e.g. from the admin API
+// when a call keeps cutting the bot off. nil fields are left as they are.
+type Tuning struct {
+	// VADThreshold is the idle RMS threshold; Config.BargeInVADThreshold by
+	// default. While the bot talks the VAD still uses at least 0.015.
+	VADThreshold *float64 `json:"vad_threshold,omitempty"`
+	// MinConfirmed is how many loud frames make speech.
+	MinConfirmed   *int     `json:"min_confirmed,omitempty"`
+	Endpointing    *bool    `json:"endpointing,omitempty"`
+	EndpointHoldMs *int     `json:"endpoint_hold_ms,omitempty"`
+	EchoThreshold  *float64 `json:"echo_threshold,omitempty"`
+}
+
+func (t Tuning) validate() error {
+	if t.VADThreshold != nil && (*t.VADThreshold < 0 || *t.VADThreshold > 1) {
+		return fmt.Errorf("vad_threshold must be between 0 and 1")
+	}
+	if t.MinConfirmed != nil && *t.MinConfirmed < 1 {
+		return fmt.Errorf("min_confirmed must be at least 1")
+	}
+	if t.EndpointHoldMs != nil && *t.EndpointHoldMs < 0 {
+		return fmt.Errorf("endpoint_hold_ms must not be negative")
+	}
+	if t.EchoThreshold != nil && (*t.EchoThreshold <= 0 || *t.EchoThreshold > 1) {
+		return fmt.Errorf("echo_threshold must be above 0 and at most 1")
+	}
+	return nil
+}
+
+// Tune changes this stream's detection settings. They apply from the next
+// audio chunk and last until the stream closes; the orchestrator's Config is
+// not touched. Nothing changes if any value is out of range.
+func (ms *ManagedStream) Tune(t Tuning) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	if t.VADThreshold != nil {
+		v := *t.VADThreshold
+		ms.tuning.VADThreshold = &v
+	}
+	if t.MinConfirmed != nil {
+		v := *t.MinConfirmed
+		ms.tuning.MinConfirmed = &v
+	}
+	if t.Endpointing != nil {
+		v := *t.Endpointing
+		ms.tuning.Endpointing = &v
+	}
+	if t.EndpointHoldMs != nil {
+		v := *t.EndpointHoldMs
+		ms.tuning.EndpointHoldMs = &v
+	}
+	ms.mu.Unlock()
+
+	if t.EchoThreshold != nil {
+		ms.SetEchoThreshold(*t.EchoThreshold)
+	}
+	if ms.orch != nil {
+		now := ms.CurrentTuning()
+		ms.orch.logger.Info("stream tuned", "sessionID", ms.session.ID,
+			"vadThreshold", *now.VADThreshold, "minConfirmed", *now.MinConfirmed,
+			"endpointing", *now.Endpointing, "endpointHoldMs", *now.EndpointHoldMs,
+			"echoThreshold", *now.EchoThreshold)
+	}
+	return nil
+}
+
+// CurrentTuning returns the settings the stream is running with, tuned or
+// not. Every field is set.
+func (ms *ManagedStream) CurrentTuning() Tuning {
+	threshold, minConfirmed := ms.vadTuning()
+	endpointing := ms.endpointing()
+	hold := int(endpointing.MaxHold / time.Millisecond)
+	echo := ms.echoThreshold()
+	return Tuning{
+		VADThreshold:   &threshold,
+		MinConfirmed:   &minConfirmed,
+		Endpointing:    &endpointing.Enabled,
+		EndpointHoldMs: &hold,
+		EchoThreshold:  &echo,
+	}
+}
+
+// vadTuning returns the idle VAD threshold and confirmation count.
+func (ms *ManagedStream) vadTuning() (float64, int) {
+	threshold := 0.0
+	if ms.orch != nil {
+		threshold = ms.orch.GetConfig().BargeInVADThreshold
+	}
+	minConfirmed := defaultMinConfirmed
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.tuning.VADThreshold != nil {
+		threshold = *ms.tuning.VADThreshold
+	}
+	if ms.tuning.MinConfirmed != nil {
+		minConfirmed = *ms.tuning.MinConfirmed
+	}
+	return threshold, minConfirmed
+}
+
+// endpointing returns Config.Endpointing with the stream's tuning applied
+// and MaxHold defaulted.
+func (ms *ManagedStream) endpointing() EndpointingConfig {
+	var cfg EndpointingConfig
+	if ms.orch != nil {
+		cfg = ms.orch.GetConfig().Endpointing
+	}
+	ms.mu.Lock()
+	if ms.tuning.Endpointing != nil {
+		cfg.Enabled = *ms.tuning.Endpointing
+	}
+	if ms.tuning.EndpointHoldMs != nil {
+		cfg.MaxHold = time.Duration(*ms.tuning.EndpointHoldMs) * time.Millisecond
+	}
+	ms.mu.Unlock()
+	if cfg.MaxHold <= 0 {
+		cfg.MaxHold = defaultEndpointHold
+	}
+	return cfg
+}
+
+func (ms *ManagedStream) echoThreshold() float64 {
+	if threshold := ms.EchoConfig().Threshold; threshold > 0 {
+		return threshold
+	}
+	if ms.echoSuppressor != nil {
+		return ms.echoSuppressor.Threshold()
+	}
+	return 0
+}
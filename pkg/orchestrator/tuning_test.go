@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_TuneLive(t *testing.T) {
+	ms := newTestStream(t, DefaultConfig(), &MockSTTProvider{}, &MockLLMProvider{})
+
+	threshold := 0.05
+	if err := ms.Tune(Tuning{VADThreshold: &threshold}); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		ms.doWrite(toneChunk(1000))
+	}
+	select {
+	case ev := <-ms.Events():
+		if ev.Type == UserSpeaking {
+			t.Fatal("speech below the tuned threshold started a turn")
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	threshold = 0.01
+	minConfirmed := 3
+	if err := ms.Tune(Tuning{VADThreshold: &threshold, MinConfirmed: &minConfirmed}); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		ms.doWrite(toneChunk(1000))
+	}
+	waitForEvent(t, ms, UserSpeaking)
+}
+
+func TestManagedStream_CurrentTuning(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BargeInVADThreshold = 0.01
+	cfg.Endpointing = EndpointingConfig{Enabled: true}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("tune"))
+	defer ms.Close()
+
+	now := ms.CurrentTuning()
+	if *now.VADThreshold != 0.01 || *now.MinConfirmed != defaultMinConfirmed || !*now.Endpointing ||
+		*now.EndpointHoldMs != int(defaultEndpointHold/time.Millisecond) || *now.EchoThreshold <= 0 {
+		t.Fatalf("unexpected untuned settings %+v", now)
+	}
+
+	off, hold, echo := false, 500, 0.9
+	if err := ms.Tune(Tuning{Endpointing: &off, EndpointHoldMs: &hold, EchoThreshold: &echo}); err != nil {
+		t.Fatal(err)
+	}
+	now = ms.CurrentTuning()
+	if *now.Endpointing || *now.EndpointHoldMs != 500 || *now.EchoThreshold != 0.9 || *now.VADThreshold != 0.01 {
+		t.Errorf("unexpected tuned settings %+v", now)
+	}
+	if ms.orch.GetConfig().Endpointing.Enabled != true {
+		t.Error("tuning a stream changed the orchestrator's config")
+	}
+
+	bad, threshold := 0, 0.5
+	if err := ms.Tune(Tuning{VADThreshold: &threshold, MinConfirmed: &bad}); err == nil {
+		t.Error("expected an error for min_confirmed 0")
+	}
+	if *ms.CurrentTuning().VADThreshold != 0.01 {
+		t.Error("a rejected tuning was partly applied")
+	}
+}
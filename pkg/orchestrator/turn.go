@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTurns bounds the turn history a stream keeps for GetTurn.
+const maxTurns = 256
+
+// Turn records one exchange: what the user said and how the bot answered.
+// A turn starts when the user starts speaking, or when the bot speaks
+// unprompted (the greeting, scheduled speech). Every event emitted on its
+// behalf carries its ID in OrchestratorEvent.TurnID.
+type Turn struct {
+	ID          string           `json:"id"`
+	Transcript  string           `json:"transcript,omitempty"`
+	Response    string           `json:"response,omitempty"`
+	StartedAt   time.Time        `json:"started_at"`
+	EndedAt     time.Time        `json:"ended_at,omitempty"`
+	Latency     LatencyBreakdown `json:"latency"`
+	Interrupted bool             `json:"interrupted,omitempty"`
+	Generation  GenerationParams `json:"generation"`
+	Trust       *TrustScore      `json:"trust,omitempty"`
+	// Speaker is who spoke the turn, when the STT diarizes.
+	Speaker string `json:"speaker,omitempty"`
+	// Canceled is set when a barge-in canceled the LLM call for the reply.
+	Canceled *CanceledGeneration `json:"canceled,omitempty"`
+}
+
+// TurnID returns the ID of the stream's current turn, or "" before the first.
+func (ms *ManagedStream) TurnID() string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.turnID
+}
+
+// GetTurn returns a turn of this stream by ID. Only the last 256 turns are
+// kept.
+func (ms *ManagedStream) GetTurn(id string) (Turn, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	t, ok := ms.turns[id]
+	if !ok {
+		return Turn{}, false
+	}
+	return *t, true
+}
+
+// Turns returns the kept turns, oldest first.
+func (ms *ManagedStream) Turns() []Turn {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	turns := make([]Turn, 0, len(ms.turnOrder))
+	for _, id := range ms.turnOrder {
+		turns = append(turns, *ms.turns[id])
+	}
+	return turns
+}
+
+func (ms *ManagedStream) beginTurn() string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.beginTurnLocked()
+}
+
+func (ms *ManagedStream) beginTurnLocked() string {
+	if ms.turns == nil {
+		ms.turns = make(map[string]*Turn)
+	}
+	ms.turnSeq++
+	id := fmt.Sprintf("%s-%d", ms.session.ID, ms.turnSeq)
+	ms.turns[id] = &Turn{ID: id, StartedAt: time.Now()}
+	ms.turnOrder = append(ms.turnOrder, id)
+	if len(ms.turnOrder) > maxTurns {
+		delete(ms.turns, ms.turnOrder[0])
+		ms.turnOrder = ms.turnOrder[1:]
+	}
+	ms.turnID = id
+	return id
+}
+
+// responseTurn returns the turn a reply answers, starting one if the bot
+// speaks first.
+func (ms *ManagedStream) responseTurn() string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.turnID == "" {
+		return ms.beginTurnLocked()
+	}
+	return ms.turnID
+}
+
+func (ms *ManagedStream) updateTurn(id string, fn func(*Turn)) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if t, ok := ms.turns[id]; ok {
+		fn(t)
+	}
+}
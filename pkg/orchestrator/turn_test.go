@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func waitForTurnEnd(t *testing.T, ms *ManagedStream, id string) Turn {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if turn, ok := ms.GetTurn(id); ok && !turn.EndedAt.IsZero() {
+			return turn
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("turn %q did not end", id)
+	return Turn{}
+}
+
+func TestManagedStream_TurnRecords(t *testing.T) {
+	stt := &scriptedSTT{}
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(stt, &MockLLMProvider{completeResult: "It's noon."}, &MockTTSProvider{synthesizeResult: make([]byte, 3528)}, NewRMSVAD(0.01, 50*time.Millisecond), cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("turns"))
+	t.Cleanup(ms.Close)
+
+	for i := 0; i < 3; i++ {
+		ms.doWrite(toneChunk(6553))
+	}
+	speaking := waitForEvent(t, ms, UserSpeaking)
+	if speaking.TurnID == "" || speaking.TurnID != ms.TurnID() {
+		t.Fatalf("UserSpeaking turn = %q, current = %q", speaking.TurnID, ms.TurnID())
+	}
+
+	time.Sleep(120 * time.Millisecond) // long enough not to count as noise
+	ms.doWrite(toneChunk(0))
+	time.Sleep(60 * time.Millisecond)
+	ms.doWrite(toneChunk(0))
+	waitForEvent(t, ms, UserStopped)
+	stt.say("what time is it", true)
+	for _, want := range []EventType{TranscriptFinal, BotResponse, AudioChunk} {
+		if ev := waitForEvent(t, ms, want); ev.TurnID != speaking.TurnID {
+			t.Errorf("%s carries turn %q, want %q", want, ev.TurnID, speaking.TurnID)
+		}
+	}
+
+	turn := waitForTurnEnd(t, ms, speaking.TurnID)
+	if turn.Transcript != "what time is it" || turn.Response != "It's noon." || turn.Interrupted {
+		t.Errorf("unexpected turn %+v", turn)
+	}
+	if turn.Latency.STT < 100 {
+		t.Errorf("latency not recorded: %+v", turn.Latency)
+	}
+
+	ms.speakText(ms.ctx, "Anything else?")
+	if ms.TurnID() == speaking.TurnID {
+		t.Fatal("bot-initiated speech must start a new turn")
+	}
+	if turns := ms.Turns(); len(turns) != 2 || turns[1].Response != "Anything else?" || turns[1].Transcript != "" {
+		t.Errorf("unexpected turns %+v", turns)
+	}
+	if _, ok := ms.GetTurn("missing"); ok {
+		t.Error("unknown turn found")
+	}
+}
+
+func TestManagedStream_TurnHistoryBounded(t *testing.T) {
+	ms := &ManagedStream{session: NewConversationSession("bounded")}
+	first := ms.beginTurn()
+	for i := 0; i < maxTurns; i++ {
+		ms.beginTurn()
+	}
+	if _, ok := ms.GetTurn(first); ok {
+		t.Error("oldest turn must be evicted")
+	}
+	if n := len(ms.Turns()); n != maxTurns {
+		t.Errorf("kept %d turns", n)
+	}
+}
@@ -33,6 +33,33 @@ type StreamingSTTProvider interface {
 	StreamTranscribe(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error)
 }
 
+// TranscriptionResult is a transcript with timing. Segment times are offsets
+// into the transcribed audio. Confidence is between 0 and 1, or 0 if the
+// provider doesn't report one. Language is the detected language, for
+// providers that report it. Speaker is the provider's label for whoever said
+// most of it, for providers that diarize; segments then carry their own.
+type TranscriptionResult struct {
+	Text       string              `json:"text"`
+	Duration   time.Duration       `json:"duration"`
+	Segments   []TranscriptSegment `json:"segments,omitempty"`
+	Confidence float64             `json:"confidence,omitempty"`
+	Language   Language            `json:"language,omitempty"`
+	Speaker    string              `json:"speaker,omitempty"`
+}
+
+type TranscriptSegment struct {
+	Start   time.Duration `json:"start"`
+	End     time.Duration `json:"end"`
+	Text    string        `json:"text"`
+	Speaker string        `json:"speaker,omitempty"`
+}
+
+// DetailedTranscriber is implemented by STT providers that can report
+// segment timestamps.
+type DetailedTranscriber interface {
+	TranscribeDetailed(ctx context.Context, audio []byte, lang Language) (*TranscriptionResult, error)
+}
+
 type LLMProvider interface {
 	Complete(ctx context.Context, messages []Message) (string, error)
 	Name() string
@@ -45,6 +72,12 @@ type TTSProvider interface {
 	Name() string
 }
 
+// SampleRateSetter is implemented by providers whose audio format depends on
+// the sample rate. The orchestrator sets it to Config.SampleRate.
+type SampleRateSetter interface {
+	SetSampleRate(rate int)
+}
+
 type VADProvider interface {
 	Process(chunk []byte) (*VADEvent, error)
 	Reset()
@@ -52,6 +85,13 @@ type VADProvider interface {
 	Name() string
 }
 
+// MultiEventVAD is implemented by VADs that can see several speech
+// transitions in one chunk, such as a framed RMSVAD given a large chunk.
+// ManagedStream then handles each of them in order.
+type MultiEventVAD interface {
+	ProcessEvents(chunk []byte) ([]VADEvent, error)
+}
+
 type VADEventType string
 
 const (
@@ -72,19 +112,61 @@ const (
 	UserStopped       EventType = "USER_STOPPED"
 	TranscriptPartial EventType = "TRANSCRIPT_PARTIAL"
 	TranscriptFinal   EventType = "TRANSCRIPT_FINAL"
+	TranscriptTimed   EventType = "TRANSCRIPT_TIMED"
+	Backchannel       EventType = "BACKCHANNEL"
+	TranscriptDropped EventType = "TRANSCRIPT_DROPPED"
+	CallEnded         EventType = "CALL_ENDED"
+	Idle              EventType = "IDLE"
+	WakeWordDetected  EventType = "WAKE_WORD_DETECTED"
+	Dormant           EventType = "DORMANT"
+	CommandDetected   EventType = "COMMAND_DETECTED"
+	SpeakerVerified   EventType = "SPEAKER_VERIFIED"
+	SpeakerMismatch   EventType = "SPEAKER_MISMATCH"
+	LanguageChanged   EventType = "LANGUAGE_CHANGED"
+	PowerSaveStarted  EventType = "POWER_SAVE_STARTED"
+	PowerSaveEnded    EventType = "POWER_SAVE_ENDED"
+	Translation       EventType = "TRANSLATION"
+	SpeakerChanged    EventType = "SPEAKER_CHANGED"
+	Viseme            EventType = "VISEME"
+	WordBoundary      EventType = "WORD_BOUNDARY"
 	BotThinking       EventType = "BOT_THINKING"
 	BotResponse       EventType = "BOT_RESPONSE"
 	BotSpeaking       EventType = "BOT_SPEAKING"
 	Interrupted       EventType = "INTERRUPTED"
 	AudioChunk        EventType = "AUDIO_CHUNK"
 	ErrorEvent        EventType = "ERROR"
+	TimerFired        EventType = "TIMER_FIRED"
+	ConsentChanged    EventType = "CONSENT_CHANGED"
+	GuardrailBlocked  EventType = "GUARDRAIL_BLOCKED"
+	ContextRetrieved  EventType = "CONTEXT_RETRIEVED"
+
+	SupervisorWhisper  EventType = "SUPERVISOR_WHISPER"
+	SupervisorTakeover EventType = "SUPERVISOR_TAKEOVER"
+	SupervisorReleased EventType = "SUPERVISOR_RELEASED"
+	SessionTransferred EventType = "SESSION_TRANSFERRED"
+	ServerBusy         EventType = "SERVER_BUSY"
+	AudioClassified    EventType = "AUDIO_CLASSIFIED"
+	AudioDucked        EventType = "AUDIO_DUCKED"
+	AudioRestored      EventType = "AUDIO_RESTORED"
+	FactCheckFailed    EventType = "FACT_CHECK_FAILED"
+	Checkpoint         EventType = "CHECKPOINT"
+	ListeningPaused    EventType = "LISTENING_PAUSED"
+	ListeningResumed   EventType = "LISTENING_RESUMED"
+	ResponseResumed    EventType = "RESPONSE_RESUMED"
+	GenerationCanceled EventType = "GENERATION_CANCELED"
+	UtteranceContinued EventType = "UTTERANCE_CONTINUED"
+	FlowStateChanged   EventType = "FLOW_STATE_CHANGED"
+	FlowSlotFilled     EventType = "FLOW_SLOT_FILLED"
+	DisclosureSpoken   EventType = "DISCLOSURE_SPOKEN"
 )
 
 type OrchestratorEvent struct {
 	Type       EventType   `json:"type"`
 	SessionID  string      `json:"session_id"`
+	TurnID     string      `json:"turn_id,omitempty"`
 	Data       interface{} `json:"data,omitempty"`
 	Generation int         `json:"generation,omitempty"`
+	Trust      *TrustScore `json:"trust,omitempty"`
 }
 
 type Voice string
@@ -116,8 +198,12 @@ const (
 )
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string        `json:"role"`
+	Content string        `json:"content"`
+	Parts   []MessagePart `json:"parts,omitempty"`
+	// Speaker is who said a user message when a diarizing STT tells the
+	// callers apart, e.g. "user_2".
+	Speaker string `json:"speaker,omitempty"`
 }
 
 type FirstSpeaker string
@@ -142,6 +228,53 @@ type Config struct {
 	BargeInVADTrailWindow    time.Duration
 	EchoSuppressionThreshold float64
 	FirstSpeaker             FirstSpeaker
+	RequireConsent           bool
+	Generation               GenerationParams
+	Compaction               CompactionConfig
+	Encryptor                Encryptor
+	Guardrail                GuardrailConfig
+	Retrieval                RetrievalConfig
+	Capacity                 CapacityConfig
+	Priority                 PriorityConfig
+	STTGate                  STTGateConfig
+	Echo                     EchoConfig
+	Checkpoint               CheckpointConfig
+	BargeIn                  BargeInConfig
+	AudioInput               AudioInputConfig
+	ResponseStyle            ResponseStyleConfig
+	QA                       QAConfig
+	FactCheck                FactCheckConfig
+	Recording                RecordingConfig
+	Determinism              DeterminismConfig
+	CheckpointEvents         CheckpointEventsConfig
+	EventSinks               []EventSink
+	TurnDetection            TurnDetection
+	Endpointing              EndpointingConfig
+	Backchannel              BackchannelConfig
+	STTParams                STTParams
+	Filler                   FillerConfig
+	HallucinationFilter      HallucinationFilterConfig
+	EndConversation          EndConversationConfig
+	Trust                    TrustConfig
+	Idle                     IdleConfig
+	TurnArtifacts            TurnArtifactsConfig
+	WakeWord                 WakeWordConfig
+	Commands                 CommandsConfig
+	SpeakerVerification      SpeakerVerificationConfig
+	LanguageDetection        LanguageDetectionConfig
+	PowerSave                PowerSaveConfig
+	Translation              TranslationConfig
+	Prosody                  ProsodyConfig
+	TTSCache                 TTSCacheConfig
+	Events                   EventsConfig
+	Continuation             ContinuationConfig
+	AGC                      AGCConfig
+	// Flow, when set, scripts the conversation; see Flow.
+	Flow       *Flow
+	Compliance ComplianceConfig
+	// Profile names a built-in tuning profile, e.g. ProfilePSTN, applied
+	// when the orchestrator is created.
+	Profile string
 }
 
 func DefaultConfig() Config {
@@ -172,11 +305,45 @@ type ConversationSession struct {
 	MaxMessages     int
 	CurrentVoice    Voice
 	CurrentLanguage Language
+	Generation      GenerationParams
+
+	// MaxTranscript caps the transcript kept in memory to the most recent
+	// entries, 200 by default. Negative keeps them all.
+	MaxTranscript int
+
+	// UserID is who the conversation is with, if known. Snapshots and
+	// recordings keep it so a user's data can be erased.
+	UserID string
+
+	consentDenied map[ConsentScope]bool
+	usage         SessionUsage
+	priority      Priority
+	onChange      func(*ConversationSession)
+	transcript    []TranscriptEntry
+	pendingParts  []MessagePart
+	pendingParams *GenerationParams
+	replayParams  []GenerationParams
+	channel       Channel
+	ended         bool
+
+	inputLanguage  Language
+	outputLanguage Language
+
+	// speakers maps STT speaker labels to user_1, user_2, ...
+	speakers map[string]string
+
+	pace paceTracker
+
+	flow        *FlowStatus
+	pendingFlow flowUpdate
+
+	// disclosed maps disclosure IDs to when they were spoken.
+	disclosed map[string]time.Time
 }
 
-func NewConversationSession(userID string) *ConversationSession {
+func NewConversationSession(id string) *ConversationSession {
 	return &ConversationSession{
-		ID:              userID,
+		ID:              id,
 		Context:         []Message{},
 		MaxMessages:     20,
 		CurrentVoice:    VoiceF1,
@@ -185,25 +352,91 @@ func NewConversationSession(userID string) *ConversationSession {
 }
 
 func (s *ConversationSession) AddMessage(role, content string) {
+	s.addMessage(Message{Role: role, Content: content})
+}
+
+// trimContextLocked drops the oldest turns beyond MaxMessages. System
+// messages, such as the prompt and compaction summaries, are kept. Zero
+// MaxMessages keeps everything.
+func (s *ConversationSession) trimContextLocked() {
+	excess := len(s.Context) - s.MaxMessages
+	if s.MaxMessages <= 0 || excess <= 0 {
+		return
+	}
+	kept := s.Context[:0]
+	for _, m := range s.Context {
+		if excess > 0 && m.Role != "system" {
+			excess--
+			continue
+		}
+		kept = append(kept, m)
+	}
+	clear(s.Context[len(kept):])
+	s.Context = kept
+}
+
+// defaultMaxTranscript is the transcript cap when MaxTranscript is zero.
+const defaultMaxTranscript = 200
+
+// appendTranscriptLocked records entry, dropping the oldest entries beyond
+// MaxTranscript so a long session doesn't keep growing in memory.
+func (s *ConversationSession) appendTranscriptLocked(entry TranscriptEntry) {
+	s.transcript = append(s.transcript, entry)
+	s.trimTranscriptLocked()
+}
+
+func (s *ConversationSession) trimTranscriptLocked() {
+	limit := s.MaxTranscript
+	if limit == 0 {
+		limit = defaultMaxTranscript
+	}
+	excess := len(s.transcript) - limit
+	if limit < 0 || excess <= 0 {
+		return
+	}
+	clear(s.transcript[:excess])
+	s.transcript = s.transcript[excess:]
+}
+
+func (s *ConversationSession) addMessage(msg Message) {
+	defer s.changed()
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Context = append(s.Context, Message{Role: role, Content: content})
-	if len(s.Context) > s.MaxMessages {
-		s.Context = s.Context[len(s.Context)-s.MaxMessages:]
+	role, content := msg.Role, msg.Content
+	if role == "user" && len(s.pendingParts) > 0 {
+		msg.Parts = append(msg.Parts, s.pendingParts...)
+		s.pendingParts = nil
 	}
+	s.Context = append(s.Context, msg)
+	s.trimContextLocked()
 	if role == "user" {
 		s.LastUser = content
 	} else if role == "assistant" {
 		s.LastAssistant = content
 	}
+	if !s.consentDenied[ConsentTranscript] {
+		entry := TranscriptEntry{Role: role, Content: content, At: time.Now(), Language: s.CurrentLanguage, Speaker: msg.Speaker}
+		if role == "assistant" {
+			entry.Voice = s.CurrentVoice
+			entry.Generation = s.pendingParams
+		}
+		s.appendTranscriptLocked(entry)
+	}
+	if role == "assistant" {
+		s.pendingParams = nil
+	}
 }
 
 func (s *ConversationSession) ClearContext() {
+	defer s.changed()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Context = []Message{}
 	s.LastUser = ""
 	s.LastAssistant = ""
+	s.transcript = nil
+	s.pendingParts = nil
+	s.pendingParams = nil
 }
 
 func (s *ConversationSession) GetContextCopy() []Message {
@@ -40,6 +40,26 @@ func TestAddMessage(t *testing.T) {
 	}
 }
 
+func TestAddMessage_KeepsSystemMessages(t *testing.T) {
+	session := NewConversationSession("user_456")
+	session.MaxMessages = 3
+	session.AddMessage("system", "You are helpful.")
+	session.AddMessage("system", summaryPrefix+"they ordered 42")
+	for _, text := range []string{"one", "two", "three"} {
+		session.AddMessage("user", text)
+	}
+	ctx := session.GetContextCopy()
+	if len(ctx) != 3 || ctx[0].Role != "system" || ctx[1].Role != "system" || ctx[2].Content != "three" {
+		t.Errorf("expected the prompt and summary to outlive older turns, got %+v", ctx)
+	}
+
+	session.MaxMessages = 0
+	session.AddMessage("user", "four")
+	if len(session.GetContextCopy()) != 4 {
+		t.Error("expected no cap with zero MaxMessages")
+	}
+}
+
 func TestClearContext(t *testing.T) {
 	session := NewConversationSession("user_789")
 	session.AddMessage("user", "Test")
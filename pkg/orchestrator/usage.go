@@ -0,0 +1,206 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+)
+
+type Usage struct {
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+}
+
+func (u Usage) Total() int {
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.PromptTokens + u.CompletionTokens
+}
+
+type UsageLLMProvider interface {
+	ParameterizedLLMProvider
+	CompleteWithUsage(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error)
+}
+
+// ModelPricing is expressed in US dollars per million tokens.
+type ModelPricing struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+func (p ModelPricing) Cost(u Usage) float64 {
+	return float64(u.PromptTokens)*p.PromptPerMillion/1e6 + float64(u.CompletionTokens)*p.CompletionPerMillion/1e6
+}
+
+type SessionUsage struct {
+	Turns            int     `json:"turns"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+	LastTurn         Usage   `json:"last_turn"`
+
+	// CanceledTurns counts LLM calls a barge-in canceled. Their tokens,
+	// CanceledTokens of them, are included in the totals above.
+	CanceledTurns  int `json:"canceled_turns,omitempty"`
+	CanceledTokens int `json:"canceled_tokens,omitempty"`
+}
+
+func (s *SessionUsage) add(u Usage, cost float64) {
+	s.Turns++
+	s.PromptTokens += u.PromptTokens
+	s.CompletionTokens += u.CompletionTokens
+	s.TotalTokens += u.Total()
+	s.EstimatedCostUSD += cost
+	s.LastTurn = u
+}
+
+func (s *SessionUsage) addCanceled(u Usage, cost float64) {
+	s.CanceledTurns++
+	s.CanceledTokens += u.Total()
+	s.PromptTokens += u.PromptTokens
+	s.CompletionTokens += u.CompletionTokens
+	s.TotalTokens += u.Total()
+	s.EstimatedCostUSD += cost
+}
+
+type CostTracker struct {
+	mu       sync.RWMutex
+	pricing  map[string]ModelPricing
+	sessions map[string]*SessionUsage
+}
+
+// NewCostTracker prices usage by model name, falling back to the provider
+// name when the provider did not report a model.
+func NewCostTracker(pricing map[string]ModelPricing) *CostTracker {
+	p := make(map[string]ModelPricing, len(pricing))
+	for k, v := range pricing {
+		p[k] = v
+	}
+	return &CostTracker{
+		pricing:  p,
+		sessions: make(map[string]*SessionUsage),
+	}
+}
+
+func (c *CostTracker) SetPricing(model string, pricing ModelPricing) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pricing[model] = pricing
+}
+
+func (c *CostTracker) Estimate(provider string, u Usage) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if p, ok := c.pricing[u.Model]; ok && u.Model != "" {
+		return p.Cost(u)
+	}
+	if p, ok := c.pricing[provider]; ok {
+		return p.Cost(u)
+	}
+	return 0
+}
+
+func (c *CostTracker) Record(sessionID, provider string, u Usage) float64 {
+	cost := c.Estimate(provider, u)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	su, ok := c.sessions[sessionID]
+	if !ok {
+		su = &SessionUsage{}
+		c.sessions[sessionID] = su
+	}
+	su.add(u, cost)
+	return cost
+}
+
+func (c *CostTracker) recordCanceled(sessionID, provider string, u Usage) float64 {
+	cost := c.Estimate(provider, u)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	su, ok := c.sessions[sessionID]
+	if !ok {
+		su = &SessionUsage{}
+		c.sessions[sessionID] = su
+	}
+	su.addCanceled(u, cost)
+	return cost
+}
+
+func (c *CostTracker) SessionUsage(sessionID string) SessionUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if su, ok := c.sessions[sessionID]; ok {
+		return *su
+	}
+	return SessionUsage{}
+}
+
+func (c *CostTracker) Total() SessionUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var total SessionUsage
+	for _, su := range c.sessions {
+		total.Turns += su.Turns
+		total.PromptTokens += su.PromptTokens
+		total.CompletionTokens += su.CompletionTokens
+		total.TotalTokens += su.TotalTokens
+		total.EstimatedCostUSD += su.EstimatedCostUSD
+		total.CanceledTurns += su.CanceledTurns
+		total.CanceledTokens += su.CanceledTokens
+	}
+	return total
+}
+
+func (c *CostTracker) Forget(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, sessionID)
+}
+
+func (s *ConversationSession) GetUsage() SessionUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage
+}
+
+func (s *ConversationSession) recordUsage(u Usage, cost float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.add(u, cost)
+}
+
+func (s *ConversationSession) recordCanceledUsage(u Usage, cost float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.addCanceled(u, cost)
+}
+
+func (o *Orchestrator) SetCostTracker(tracker *CostTracker) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.costTracker = tracker
+}
+
+func (o *Orchestrator) CostTracker() *CostTracker {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.costTracker
+}
+
+func (o *Orchestrator) recordUsage(session *ConversationSession, u Usage) {
+	o.recordUsageFor(session, o.llm, u)
+}
+
+func (o *Orchestrator) recordUsageFor(session *ConversationSession, llm LLMProvider, u Usage) {
+	if u.Total() == 0 {
+		return
+	}
+	cost := 0.0
+	if tracker := o.CostTracker(); tracker != nil {
+		cost = tracker.Record(session.ID, llm.Name(), u)
+	}
+	session.recordUsage(u, cost)
+}
@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+type MockUsageLLMProvider struct {
+	MockLLMProvider
+	usage Usage
+}
+
+func (m *MockUsageLLMProvider) CompleteWithParams(ctx context.Context, messages []Message, params GenerationParams) (string, error) {
+	return m.completeResult, m.completeErr
+}
+
+func (m *MockUsageLLMProvider) CompleteWithUsage(ctx context.Context, messages []Message, params GenerationParams) (string, Usage, error) {
+	return m.completeResult, m.usage, m.completeErr
+}
+
+func TestModelPricingCost(t *testing.T) {
+	p := ModelPricing{PromptPerMillion: 3, CompletionPerMillion: 15}
+	cost := p.Cost(Usage{PromptTokens: 1000, CompletionTokens: 100})
+	if math.Abs(cost-0.0045) > 1e-9 {
+		t.Errorf("expected 0.0045, got %v", cost)
+	}
+}
+
+func TestCostTrackerAggregation(t *testing.T) {
+	tracker := NewCostTracker(map[string]ModelPricing{
+		"gpt-4o":  {PromptPerMillion: 2.5, CompletionPerMillion: 10},
+		"MockLLM": {PromptPerMillion: 1, CompletionPerMillion: 1},
+	})
+
+	tracker.Record("a", "openai-llm", Usage{Model: "gpt-4o", PromptTokens: 1_000_000})
+	tracker.Record("a", "openai-llm", Usage{Model: "gpt-4o", CompletionTokens: 1_000_000})
+	tracker.Record("b", "MockLLM", Usage{PromptTokens: 500_000, CompletionTokens: 500_000})
+
+	a := tracker.SessionUsage("a")
+	if a.Turns != 2 || a.TotalTokens != 2_000_000 {
+		t.Errorf("unexpected session usage: %+v", a)
+	}
+	if math.Abs(a.EstimatedCostUSD-12.5) > 1e-9 {
+		t.Errorf("expected $12.5, got %v", a.EstimatedCostUSD)
+	}
+
+	if b := tracker.SessionUsage("b"); math.Abs(b.EstimatedCostUSD-1) > 1e-9 {
+		t.Errorf("expected provider-name pricing fallback, got %v", b.EstimatedCostUSD)
+	}
+
+	if total := tracker.Total(); total.Turns != 3 {
+		t.Errorf("expected 3 total turns, got %d", total.Turns)
+	}
+
+	tracker.Forget("a")
+	if tracker.SessionUsage("a").Turns != 0 {
+		t.Error("expected forgotten session to be cleared")
+	}
+}
+
+func TestGenerateResponse_RecordsUsage(t *testing.T) {
+	llm := &MockUsageLLMProvider{
+		MockLLMProvider: MockLLMProvider{completeResult: "hi"},
+		usage:           Usage{Model: "m", PromptTokens: 100, CompletionTokens: 20},
+	}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, DefaultConfig())
+	orch.SetCostTracker(NewCostTracker(map[string]ModelPricing{"m": {PromptPerMillion: 1e6, CompletionPerMillion: 1e6}}))
+	session := orch.NewSessionWithDefaults("usage")
+
+	for i := 0; i < 2; i++ {
+		if _, err := orch.GenerateResponse(context.Background(), session); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	usage := session.GetUsage()
+	if usage.Turns != 2 || usage.PromptTokens != 200 || usage.CompletionTokens != 40 || usage.TotalTokens != 240 {
+		t.Errorf("unexpected session usage: %+v", usage)
+	}
+	if usage.EstimatedCostUSD != 240 {
+		t.Errorf("expected cost 240, got %v", usage.EstimatedCostUSD)
+	}
+	if usage.LastTurn.Model != "m" {
+		t.Errorf("expected last turn usage, got %+v", usage.LastTurn)
+	}
+	if orch.CostTracker().SessionUsage("usage").Turns != 2 {
+		t.Error("expected tracker to aggregate the session")
+	}
+}
+
+func TestManagedStream_ForgetsUsageOnClose(t *testing.T) {
+	llm := &MockUsageLLMProvider{
+		MockLLMProvider: MockLLMProvider{completeResult: "hi"},
+		usage:           Usage{Model: "m", PromptTokens: 100, CompletionTokens: 20},
+	}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, DefaultConfig())
+	tracker := NewCostTracker(nil)
+	orch.SetCostTracker(tracker)
+	session := orch.NewSessionWithDefaults("usage")
+	ms := orch.NewManagedStream(context.Background(), session)
+
+	waitForEvent(t, ms, BotResponse)
+	if tracker.SessionUsage("usage").Turns != 1 {
+		t.Fatalf("expected the greeting to be tracked, got %+v", tracker.SessionUsage("usage"))
+	}
+	ms.Close()
+	if tracker.SessionUsage("usage").Turns != 0 {
+		t.Error("expected the tracker to forget the session when its stream closes")
+	}
+	if session.GetUsage().Turns != 1 {
+		t.Errorf("expected the session to keep its usage, got %+v", session.GetUsage())
+	}
+}
@@ -20,6 +20,11 @@ type RMSVAD struct {
 	minConfirmed      int
 	lastRMS           float64
 	mu                sync.Mutex
+
+	// frameBytes, when set, makes Process judge fixed-size frames instead of
+	// whatever chunks it is given; pending holds the partial frame.
+	frameBytes int
+	pending    []byte
 }
 
 func NewRMSVAD(threshold float64, silenceLimit time.Duration) *RMSVAD {
@@ -33,6 +38,23 @@ func NewRMSVAD(threshold float64, silenceLimit time.Duration) *RMSVAD {
 	}
 }
 
+// NewFramedRMSVAD returns an RMSVAD that evaluates audio in frames of the
+// given duration at sampleRate, so speech confirmation (MinConfirmed frames)
+// takes the same time however the transport packetizes audio.
+func NewFramedRMSVAD(threshold float64, silenceLimit time.Duration, sampleRate int, frame time.Duration) *RMSVAD {
+	v := NewRMSVAD(threshold, silenceLimit)
+	v.frameBytes = int(int64(sampleRate)*int64(frame)/int64(time.Second)) * 2
+	return v
+}
+
+// NewOpusVAD returns an RMSVAD framed for decoded Opus: 20ms frames at
+// 48kHz. Run the stream at 48kHz (Config.SampleRate = 48000) and WebRTC and
+// LiveKit transports pass decoded packets through unresampled, one frame
+// each.
+func NewOpusVAD(threshold float64, silenceLimit time.Duration) *RMSVAD {
+	return NewFramedRMSVAD(threshold, silenceLimit, 48000, 20*time.Millisecond)
+}
+
 func (v *RMSVAD) SetAdaptiveMode(enabled bool) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -75,10 +97,59 @@ func (v *RMSVAD) IsSpeaking() bool {
 	return v.isSpeaking
 }
 
+// Process reports the first speech transition in chunk, or the last
+// silence. A framed VAD can see several transitions in one chunk; use
+// ProcessEvents for all of them.
 func (v *RMSVAD) Process(chunk []byte) (*VADEvent, error) {
+	events, err := v.ProcessEvents(chunk)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// ProcessEvents reports every speech transition in chunk in order, or the
+// last silence when there is none.
+func (v *RMSVAD) ProcessEvents(chunk []byte) ([]VADEvent, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
+	if v.frameBytes <= 0 {
+		if len(chunk) < 2 {
+			// Not a whole sample: nothing to judge, and no silence either.
+			return nil, nil
+		}
+		ev, err := v.processLocked(chunk)
+		if err != nil || ev == nil {
+			return nil, err
+		}
+		return []VADEvent{*ev}, nil
+	}
+
+	v.pending = append(v.pending, chunk...)
+	var events []VADEvent
+	var silence *VADEvent
+	off := 0
+	for ; len(v.pending)-off >= v.frameBytes; off += v.frameBytes {
+		ev, err := v.processLocked(v.pending[off : off+v.frameBytes])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ev == nil:
+		case ev.Type == VADSilence:
+			silence = ev
+		default:
+			events = append(events, *ev)
+		}
+	}
+	v.pending = v.pending[:copy(v.pending, v.pending[off:])]
+	if len(events) == 0 && silence != nil {
+		events = append(events, *silence)
+	}
+	return events, nil
+}
 
+func (v *RMSVAD) processLocked(chunk []byte) (*VADEvent, error) {
 	rms := v.calculateRMS(chunk)
 	v.lastRMS = rms
 	now := time.Now()
@@ -144,6 +215,7 @@ func (v *RMSVAD) Reset() {
 	v.isSpeaking = false
 	v.silenceStart = time.Time{}
 	v.consecutiveFrames = 0
+	v.pending = v.pending[:0]
 }
 
 func (v *RMSVAD) Clone() VADProvider {
@@ -153,11 +225,12 @@ func (v *RMSVAD) Clone() VADProvider {
 		threshold:    v.threshold,
 		silenceLimit: v.silenceLimit,
 		minConfirmed: v.minConfirmed,
+		frameBytes:   v.frameBytes,
 	}
 }
 
 func (v *RMSVAD) calculateRMS(chunk []byte) float64 {
-	if len(chunk) == 0 {
+	if len(chunk) < 2 {
 		return 0
 	}
 
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+// square returns d of a full-scale-ish square wave at 48kHz.
+func square(d time.Duration, amplitude int16) []byte {
+	n := int(d.Seconds() * 48000)
+	pcm := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		v := amplitude
+		if i%2 == 1 {
+			v = -amplitude
+		}
+		pcm[2*i] = byte(v)
+		pcm[2*i+1] = byte(v >> 8)
+	}
+	return pcm
+}
+
+func TestOpusVAD_TimingIndependentOfPacketization(t *testing.T) {
+	// Seven 20ms frames confirm speech, however the audio is split.
+	for _, packet := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 60 * time.Millisecond} {
+		v := NewOpusVAD(0.02, 100*time.Millisecond)
+		speech := bytes.NewReader(square(200*time.Millisecond, 8000))
+		buf := make([]byte, int(packet.Seconds()*48000)*2)
+		var fed time.Duration
+		for {
+			n, _ := speech.Read(buf)
+			if n == 0 {
+				t.Fatalf("%v packets: speech never started", packet)
+			}
+			fed += packet
+			ev, err := v.Process(buf[:n])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ev != nil && ev.Type == VADSpeechStart {
+				break
+			}
+		}
+		if fed < 140*time.Millisecond || fed >= 140*time.Millisecond+packet {
+			t.Errorf("%v packets: speech started after %v, want 140ms", packet, fed)
+		}
+	}
+}
+
+func TestOpusVAD_KeepsPartialFrames(t *testing.T) {
+	v := NewOpusVAD(0.02, 100*time.Millisecond)
+	if ev, _ := v.Process(square(5*time.Millisecond, 0)); ev != nil {
+		t.Errorf("a partial frame must not be judged, got %+v", ev)
+	}
+	if ev, _ := v.Process(square(15*time.Millisecond, 0)); ev == nil || ev.Type != VADSilence {
+		t.Errorf("the completed frame must be judged, got %+v", ev)
+	}
+
+	clone := v.Clone().(*RMSVAD)
+	if clone.frameBytes != 1920 {
+		t.Errorf("clone lost its framing: %d", clone.frameBytes)
+	}
+	v.Process(square(5*time.Millisecond, 8000))
+	v.Reset()
+	if len(v.pending) != 0 {
+		t.Error("Reset must drop the partial frame")
+	}
+}
+
+func TestOpusVAD_ReportsEveryTransition(t *testing.T) {
+	v := NewOpusVAD(0.02, 0)
+	var chunk []byte
+	chunk = append(chunk, square(160*time.Millisecond, 8000)...)
+	chunk = append(chunk, square(40*time.Millisecond, 0)...)
+	chunk = append(chunk, square(160*time.Millisecond, 8000)...)
+
+	var _ MultiEventVAD = v
+	events, err := v.ProcessEvents(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []VADEventType
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	if len(types) != 3 || types[0] != VADSpeechStart || types[1] != VADSpeechEnd || types[2] != VADSpeechStart {
+		t.Errorf("expected start, end and start again, got %v", types)
+	}
+
+	if events, _ := v.ProcessEvents(square(20*time.Millisecond, 8000)); len(events) != 0 {
+		t.Errorf("continued speech has no transition, got %+v", events)
+	}
+}
+
+func FuzzRMSVAD_Process(f *testing.F) {
+	f.Add(square(20*time.Millisecond, 8000), []byte{1, 3, 255})
+	f.Add([]byte{0x7f}, []byte{1})
+	f.Add(square(5*time.Millisecond, -32768), []byte{2, 1, 7})
+
+	f.Fuzz(func(t *testing.T, pcm, sizes []byte) {
+		plain := NewRMSVAD(0.02, 0)
+		plain.SetAdaptiveMode(true)
+		framed := NewOpusVAD(0.02, 0)
+		for _, v := range []*RMSVAD{plain, framed} {
+			rest := pcm
+			// Chunks of any length, odd ones included, as the sizes say.
+			for i := 0; len(rest) > 0; i++ {
+				n := len(rest)
+				if len(sizes) > 0 {
+					n = min(n, int(sizes[i%len(sizes)])+1)
+				}
+				if _, err := v.Process(rest[:n]); err != nil {
+					t.Fatal(err)
+				}
+				if rms := v.LastRMS(); math.IsNaN(rms) || rms < 0 || rms > 1 {
+					t.Fatalf("RMS %v after a %d-byte chunk", rms, n)
+				}
+				rest = rest[n:]
+			}
+		}
+	})
+}
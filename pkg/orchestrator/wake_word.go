@@ -0,0 +1,112 @@
+package orchestrator
+
+import "time"
+
+const defaultWakeWordTimeout = 5 * time.Second
+
+// WakeWordDetector spots a wake word in inbound audio, e.g. a wrapper around
+// Porcupine or openWakeWord. Process gets every chunk while the stream is
+// dormant and reports whether the wake word ended in it. Detectors keep
+// state, so each stream gets its own Clone.
+type WakeWordDetector interface {
+	Process(chunk []byte) (bool, error)
+	Reset()
+	Clone() WakeWordDetector
+	Name() string
+}
+
+// WakeWordConfig keeps streams dormant, hearing nothing but the wake word,
+// until Detector fires. The stream then handles one interaction and goes
+// dormant again once the reply has been spoken, or after Timeout (5s by
+// default) if the caller doesn't speak.
+type WakeWordConfig struct {
+	Detector WakeWordDetector
+	Timeout  time.Duration
+}
+
+// WakeInfo is the data of a WakeWordDetected event.
+type WakeInfo struct {
+	Detector string `json:"detector"`
+}
+
+// Dormant reports whether the stream is waiting for the wake word.
+func (ms *ManagedStream) Dormant() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.dormant
+}
+
+// Wake wakes a dormant stream as the wake word would, e.g. from a button.
+func (ms *ManagedStream) Wake() {
+	ms.wake("manual")
+}
+
+func (ms *ManagedStream) wake(detector string) {
+	ms.mu.Lock()
+	if !ms.dormant || ms.isClosed {
+		ms.mu.Unlock()
+		return
+	}
+	ms.dormant = false
+	ms.wokeAt = time.Now()
+	ms.resetListeningLocked()
+	ms.mu.Unlock()
+
+	ms.cancelScheduledOnActivity()
+	ms.emit(WakeWordDetected, WakeInfo{Detector: detector})
+}
+
+// sleep makes the stream dormant again.
+func (ms *ManagedStream) sleep() {
+	if ms.wakeWord == nil {
+		return
+	}
+	ms.mu.Lock()
+	if ms.dormant || ms.isClosed {
+		ms.mu.Unlock()
+		return
+	}
+	ms.dormant = true
+	ms.resetListeningLocked()
+	ms.mu.Unlock()
+
+	ms.emit(Dormant, nil)
+}
+
+// writeDormant feeds chunk to the wake word detector and reports whether the
+// stream was dormant, in which case nothing else hears it. An awake stream
+// whose caller stays quiet past the timeout goes back to sleep.
+func (ms *ManagedStream) writeDormant(chunk []byte) bool {
+	if ms.wakeWord == nil {
+		return false
+	}
+	ms.mu.Lock()
+	dormant := ms.dormant
+	expired := !dormant && ms.stateLocked() == StreamIdle && time.Since(ms.wokeAt) > ms.wakeWordTimeout()
+	ms.mu.Unlock()
+	if expired {
+		ms.sleep()
+		dormant = true
+	}
+	if !dormant {
+		return false
+	}
+
+	detected, err := ms.wakeWord.Process(chunk)
+	if err != nil {
+		ms.orch.logger.Warn("wake word detection failed", "sessionID", ms.session.ID, "error", err)
+		return true
+	}
+	if detected {
+		ms.wakeWord.Reset()
+		ms.wake(ms.wakeWord.Name())
+	}
+	return true
+}
+
+func (ms *ManagedStream) wakeWordTimeout() time.Duration {
+	if timeout := ms.orch.GetConfig().WakeWord.Timeout; timeout > 0 {
+		return timeout
+	}
+	return defaultWakeWordTimeout
+}
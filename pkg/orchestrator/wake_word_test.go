@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+// markerWakeWord fires on a chunk of flatPCM(_, wakeSample).
+type markerWakeWord struct{}
+
+const wakeSample = 12345
+
+func (d *markerWakeWord) Process(chunk []byte) (bool, error) {
+	return len(chunk) >= 2 && sample(chunk, 0) == wakeSample, nil
+}
+func (d *markerWakeWord) Reset()                  {}
+func (d *markerWakeWord) Clone() WakeWordDetector { return &markerWakeWord{} }
+func (d *markerWakeWord) Name() string            { return "marker" }
+
+func newWakeWordStream(t *testing.T, timeout time.Duration) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.WakeWord = WakeWordConfig{Detector: &markerWakeWord{}, Timeout: timeout}
+	return newTestStream(t, cfg, &MockSTTProvider{transcribeResult: "what's the weather"}, &MockLLMProvider{completeResult: "Sunny."})
+}
+
+func speakTo(ms *ManagedStream) {
+	for i := 0; i < 20; i++ {
+		ms.doWrite(toneChunk(1000))
+	}
+	ms.doWrite(make([]byte, 882))
+	time.Sleep(60 * time.Millisecond) // past the VAD silence limit
+	ms.doWrite(make([]byte, 882))
+}
+
+func TestWakeWord_DormantUntilDetected(t *testing.T) {
+	ms := newWakeWordStream(t, time.Second)
+	if !ms.Dormant() || ms.State() != StreamDormant {
+		t.Fatalf("expected a dormant stream, got %s", ms.State())
+	}
+
+	speakTo(ms)
+	select {
+	case ev := <-ms.Events():
+		t.Fatalf("a dormant stream must not hear speech, got %s", ev.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ms.doWrite(flatPCM(882, wakeSample))
+	if ev := waitForEvent(t, ms, WakeWordDetected); ev.Data.(WakeInfo).Detector != "marker" {
+		t.Errorf("unexpected wake %+v", ev.Data)
+	}
+	speakTo(ms)
+	if ev := waitForEvent(t, ms, TranscriptFinal); ev.Data != "what's the weather" {
+		t.Errorf("unexpected transcript %v", ev.Data)
+	}
+	waitForEvent(t, ms, BotResponse)
+	waitForEvent(t, ms, Dormant)
+	if !ms.Dormant() {
+		t.Error("expected the stream to go dormant after the reply")
+	}
+}
+
+func TestWakeWord_TimesOutWithoutSpeech(t *testing.T) {
+	ms := newWakeWordStream(t, 50*time.Millisecond)
+	ms.Wake()
+	waitForEvent(t, ms, WakeWordDetected)
+
+	time.Sleep(80 * time.Millisecond)
+	ms.doWrite(make([]byte, 882))
+	waitForEvent(t, ms, Dormant)
+}
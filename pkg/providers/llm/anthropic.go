@@ -3,15 +3,20 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
+const defaultAnthropicMaxTokens = 1024
+
 type AnthropicLLM struct {
 	apiKey string
+	keyRef secrets.KeyRef
 	url    string
 	model  string
 }
@@ -27,73 +32,148 @@ func NewAnthropicLLM(apiKey string, model string) *AnthropicLLM {
 	}
 }
 
+func (l *AnthropicLLM) SetSecretProvider(provider secrets.Provider, name string) {
+	l.keyRef.Set(provider, name)
+}
+
 func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
-	
+	return l.CompleteWithParams(ctx, messages, orchestrator.GenerationParams{})
+}
+
+func (l *AnthropicLLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *AnthropicLLM) CompleteWithUsage(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return "", orchestrator.Usage{}, err
+	}
+
+
 	var system string
-	var anthropicMessages []map[string]string
+	var anthropicMessages []map[string]interface{}
 
 	for _, msg := range messages {
 		if msg.Role == "system" {
-			system = msg.Content
+			if system != "" {
+				system += "\n\n"
+			}
+			system += partsText(msg)
+		} else if len(msg.Parts) > 0 {
+			anthropicMessages = append(anthropicMessages, map[string]interface{}{
+				"role":    msg.Role,
+				"content": anthropicContent(msg),
+			})
 		} else {
-			anthropicMessages = append(anthropicMessages, map[string]string{
+			anthropicMessages = append(anthropicMessages, map[string]interface{}{
 				"role":    msg.Role,
 				"content": msg.Content,
 			})
 		}
 	}
 
+	maxTokens := defaultAnthropicMaxTokens
+	if params.MaxTokens > 0 {
+		maxTokens = params.MaxTokens
+	}
+
 	payload := map[string]interface{}{
 		"model":      l.model,
 		"messages":   anthropicMessages,
-		"max_tokens": 1024,
+		"max_tokens": maxTokens,
 	}
 	if system != "" {
 		payload["system"] = system
 	}
+	if params.Temperature != nil {
+		payload["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		payload["top_p"] = *params.TopP
+	}
+	if len(params.Stop) > 0 {
+		payload["stop_sequences"] = params.Stop
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", l.url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", l.apiKey)
+	req.Header.Set("x-api-key", apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return "", fmt.Errorf("anthropic llm error (status %d): %v", resp.StatusCode, errResp)
+		return "", orchestrator.Usage{}, &orchestrator.ProviderError{Provider: "anthropic llm", StatusCode: resp.StatusCode, Body: errResp}
 	}
 
 	var result struct {
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	if len(result.Content) == 0 {
-		return "", fmt.Errorf("no content returned from anthropic")
+		return "", orchestrator.Usage{}, fmt.Errorf("no content returned from anthropic")
 	}
 
-	return result.Content[0].Text, nil
+	usage := orchestrator.Usage{
+		Model:            result.Model,
+		PromptTokens:     result.Usage.InputTokens,
+		CompletionTokens: result.Usage.OutputTokens,
+	}
+	return result.Content[0].Text, usage, nil
 }
 
 func (l *AnthropicLLM) Name() string {
 	return "anthropic-llm"
 }
+
+func anthropicContent(m orchestrator.Message) []map[string]interface{} {
+	var content []map[string]interface{}
+	if m.Content != "" {
+		content = append(content, map[string]interface{}{"type": "text", "text": m.Content})
+	}
+	for _, p := range m.Parts {
+		if p.Type != orchestrator.PartImage {
+			if text := p.AsText(); text != "" {
+				content = append(content, map[string]interface{}{"type": "text", "text": text})
+			}
+			continue
+		}
+		source := map[string]string{"type": "url", "url": p.URL}
+		if p.URL == "" {
+			source = map[string]string{
+				"type":       "base64",
+				"media_type": p.MimeType,
+				"data":       base64.StdEncoding.EncodeToString(p.Data),
+			}
+		}
+		content = append(content, map[string]interface{}{"type": "image", "source": source})
+	}
+	return content
+}
@@ -67,3 +67,111 @@ func TestAnthropicLLM(t *testing.T) {
 		t.Errorf("expected 'hello from anthropic', got '%s'", resp)
 	}
 }
+
+func TestAnthropicLLM_GenerationParams(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude-3"}
+	messages := []orchestrator.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := l.Complete(context.Background(), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["max_tokens"] != float64(defaultAnthropicMaxTokens) {
+		t.Errorf("expected default max_tokens, got %v", got["max_tokens"])
+	}
+
+	params := orchestrator.GenerationParams{MaxTokens: 256, Temperature: orchestrator.Float(0.5), Stop: []string{"END"}}
+	if _, err := l.CompleteWithParams(context.Background(), messages, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["max_tokens"] != float64(256) || got["temperature"] != 0.5 {
+		t.Errorf("params not applied: %v", got)
+	}
+	if _, ok := got["stop_sequences"]; !ok {
+		t.Errorf("expected stop_sequences, got %v", got)
+	}
+}
+
+func TestAnthropicLLM_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"claude-3","content":[{"text":"ok"}],"usage":{"input_tokens":20,"output_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude-3"}
+	_, usage, err := l.CompleteWithUsage(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, orchestrator.GenerationParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.PromptTokens != 20 || usage.CompletionTokens != 5 || usage.Total() != 25 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAnthropicLLM_JoinsSystemMessages(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude-3"}
+	messages := []orchestrator.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "system", Content: "summary"},
+		{Role: "user", Content: "hi"},
+	}
+	if _, err := l.Complete(context.Background(), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["system"] != "be brief\n\nsummary" {
+		t.Errorf("expected joined system prompt, got %q", got["system"])
+	}
+}
+
+func TestAnthropicLLM_MessageParts(t *testing.T) {
+	var got struct {
+		Messages []struct {
+			Role    string            `json:"role"`
+			Content []json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"text":"a mug"}]}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude"}
+	messages := []orchestrator.Message{{Role: "user", Content: "what is this?", Parts: []orchestrator.MessagePart{
+		orchestrator.ImageDataPart([]byte{1, 2}, "image/png"),
+		orchestrator.ImageURLPart("https://example.com/b.jpg"),
+	}}}
+	if _, err := l.Complete(context.Background(), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.Messages) != 1 || len(got.Messages[0].Content) != 3 {
+		t.Fatalf("unexpected messages %+v", got.Messages)
+	}
+	var inline, linked struct {
+		Type   string            `json:"type"`
+		Source map[string]string `json:"source"`
+	}
+	json.Unmarshal(got.Messages[0].Content[1], &inline)
+	json.Unmarshal(got.Messages[0].Content[2], &linked)
+	if inline.Type != "image" || inline.Source["type"] != "base64" || inline.Source["media_type"] != "image/png" || inline.Source["data"] != "AQI=" {
+		t.Errorf("unexpected inline image %+v", inline)
+	}
+	if linked.Source["type"] != "url" || linked.Source["url"] != "https://example.com/b.jpg" {
+		t.Errorf("unexpected linked image %+v", linked)
+	}
+}
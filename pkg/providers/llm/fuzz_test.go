@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// FuzzResponses feeds every provider the same malformed response body; a
+// bad reply must come back as an error, never a panic.
+func FuzzResponses(f *testing.F) {
+	f.Add([]byte(`{"choices":[{"message":{"content":"hi"}}],"model":"gpt-4o","usage":{"prompt_tokens":3}}`))
+	f.Add([]byte(`{"content":[{"text":"hi"}],"usage":{"input_tokens":3,"output_tokens":1}}`))
+	f.Add([]byte(`{"candidates":[{"content":{"parts":[{"text":"hi"}]}}],"usageMetadata":{"promptTokenCount":3}}`))
+	f.Add([]byte(`{"choices":[],"content":null,"candidates":[{}]}`))
+	f.Add([]byte(`{"choices":[{"message":null}]`))
+
+	var (
+		mu   sync.Mutex
+		body []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	providers := []orchestrator.LLMProvider{
+		&OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"},
+		&GroqLLM{apiKey: "test-key", url: server.URL, model: "llama"},
+		&AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude"},
+		&GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"},
+	}
+	messages := []orchestrator.Message{{Role: "user", Content: "hello"}}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		mu.Lock()
+		body = data
+		mu.Unlock()
+		for _, p := range providers {
+			p.Complete(context.Background(), messages)
+		}
+	})
+}
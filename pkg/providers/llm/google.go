@@ -3,15 +3,19 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type GoogleLLM struct {
 	apiKey string
+	keyRef secrets.KeyRef
 	url    string
 	model  string
 }
@@ -27,16 +31,71 @@ func NewGoogleLLM(apiKey string, model string) *GoogleLLM {
 	}
 }
 
+func (l *GoogleLLM) SetSecretProvider(provider secrets.Provider, name string) {
+	l.keyRef.Set(provider, name)
+}
+
 func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return l.CompleteWithParams(ctx, messages, orchestrator.GenerationParams{})
+}
+
+func (l *GoogleLLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *GoogleLLM) CompleteWithUsage(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	return l.generate(ctx, messages, nil, params)
+}
+
+func (l *GoogleLLM) SupportsSeed() bool {
+	return true
+}
+
+// AcceptsAudio is always true: every Gemini model takes audio input.
+func (l *GoogleLLM) AcceptsAudio() bool {
+	return true
+}
+
+// CompleteWithAudio attaches the caller's audio as WAV to the last user
+// message, next to its transcript.
+func (l *GoogleLLM) CompleteWithAudio(ctx context.Context, messages []orchestrator.Message, turn orchestrator.TurnAudio, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	return l.generate(ctx, messages, &turn, params)
+}
+
+type googlePart struct {
+	Text       string          `json:"text,omitempty"`
+	InlineData *googleBlob     `json:"inline_data,omitempty"`
+	FileData   *googleFileData `json:"file_data,omitempty"`
+}
+
+type googleFileData struct {
+	MimeType string `json:"mime_type,omitempty"`
+	FileURI  string `json:"file_uri"`
+}
+
+type googleBlob struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+func (l *GoogleLLM) generate(ctx context.Context, messages []orchestrator.Message, turn *orchestrator.TurnAudio, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return "", orchestrator.Usage{}, err
+	}
+
 	type GoogleMessage struct {
-		Role  string `json:"role"`
-		Parts []struct {
-			Text string `json:"text"`
-		} `json:"parts"`
+		Role  string       `json:"role"`
+		Parts []googlePart `json:"parts"`
 	}
 
+	withAudio := -1
+	if turn != nil {
+		withAudio = lastUserMessage(messages)
+	}
 	var googleMessages []GoogleMessage
-	for _, m := range messages {
+	for i, m := range messages {
 		role := m.Role
 		if role == "system" {
 			role = "user" 
@@ -45,9 +104,26 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 			role = "model"
 		}
 		msg := GoogleMessage{Role: role}
-		msg.Parts = append(msg.Parts, struct {
-			Text string `json:"text"`
-		}{Text: m.Content})
+		msg.Parts = append(msg.Parts, googlePart{Text: m.Content})
+		for _, p := range m.Parts {
+			switch {
+			case p.Type != orchestrator.PartImage:
+				if text := p.AsText(); text != "" {
+					msg.Parts = append(msg.Parts, googlePart{Text: text})
+				}
+			case p.URL != "":
+				// Gemini only fetches File API and Cloud Storage URIs.
+				msg.Parts = append(msg.Parts, googlePart{FileData: &googleFileData{MimeType: p.MimeType, FileURI: p.URL}})
+			default:
+				msg.Parts = append(msg.Parts, googlePart{InlineData: &googleBlob{MimeType: p.MimeType, Data: base64.StdEncoding.EncodeToString(p.Data)}})
+			}
+		}
+		if i == withAudio {
+			msg.Parts = append(msg.Parts, googlePart{InlineData: &googleBlob{
+				MimeType: "audio/wav",
+				Data:     base64.StdEncoding.EncodeToString(audio.NewWavBuffer(turn.PCM, turn.SampleRate)),
+			}})
+		}
 		googleMessages = append(googleMessages, msg)
 	}
 
@@ -55,27 +131,47 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 		"contents": googleMessages,
 	}
 
+	generationConfig := map[string]interface{}{}
+	if params.Temperature != nil {
+		generationConfig["temperature"] = *params.Temperature
+	}
+	if params.MaxTokens > 0 {
+		generationConfig["maxOutputTokens"] = params.MaxTokens
+	}
+	if params.TopP != nil {
+		generationConfig["topP"] = *params.TopP
+	}
+	if len(params.Stop) > 0 {
+		generationConfig["stopSequences"] = params.Stop
+	}
+	if params.Seed != nil {
+		generationConfig["seed"] = *params.Seed
+	}
+	if len(generationConfig) > 0 {
+		payload["generationConfig"] = generationConfig
+	}
+
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", l.url+"?key="+l.apiKey, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", l.url+"?key="+apiKey, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return "", fmt.Errorf("google llm error (status %d): %v", resp.StatusCode, errResp)
+		return "", orchestrator.Usage{}, &orchestrator.ProviderError{Provider: "google llm", StatusCode: resp.StatusCode, Body: errResp}
 	}
 
 	var result struct {
@@ -86,16 +182,32 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 				} `json:"parts"`
 			} `json:"content"`
 		} `json:"candidates"`
+		ModelVersion  string `json:"modelVersion"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
+		} `json:"usageMetadata"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response from google llm")
+		return "", orchestrator.Usage{}, fmt.Errorf("no response from google llm")
 	}
 
-	return result.Candidates[0].Content.Parts[0].Text, nil
+	model := result.ModelVersion
+	if model == "" {
+		model = l.model
+	}
+	usage := orchestrator.Usage{
+		Model:            model,
+		PromptTokens:     result.UsageMetadata.PromptTokenCount,
+		CompletionTokens: result.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      result.UsageMetadata.TotalTokenCount,
+	}
+	return result.Candidates[0].Content.Parts[0].Text, usage, nil
 }
 
 func (l *GoogleLLM) Name() string {
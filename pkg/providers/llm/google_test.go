@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -72,3 +73,119 @@ func TestGoogleLLM(t *testing.T) {
 		t.Errorf("expected 'hello from google', got '%s'", resp)
 	}
 }
+
+func TestGoogleLLM_GenerationParams(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`))
+	}))
+	defer server.Close()
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"}
+	params := orchestrator.GenerationParams{MaxTokens: 128, TopP: orchestrator.Float(0.8)}
+
+	if _, err := l.CompleteWithParams(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, ok := got["generationConfig"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected generationConfig, got %v", got)
+	}
+	if cfg["maxOutputTokens"] != float64(128) || cfg["topP"] != 0.8 {
+		t.Errorf("unexpected generationConfig: %v", cfg)
+	}
+}
+
+func TestGoogleLLM_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}],"usageMetadata":{"promptTokenCount":8,"candidatesTokenCount":2,"totalTokenCount":10}}`))
+	}))
+	defer server.Close()
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini-1.5-flash"}
+	_, usage, err := l.CompleteWithUsage(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, orchestrator.GenerationParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Model != "gemini-1.5-flash" || usage.TotalTokens != 10 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestGoogleLLM_Audio(t *testing.T) {
+	var got struct {
+		Contents []struct {
+			Role  string `json:"role"`
+			Parts []struct {
+				Text       string `json:"text"`
+				InlineData *struct {
+					MimeType string `json:"mime_type"`
+					Data     string `json:"data"`
+				} `json:"inline_data"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`))
+	}))
+	defer server.Close()
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"}
+	messages := []orchestrator.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}, {Role: "user", Content: "are you sure"}}
+	turn := orchestrator.TurnAudio{PCM: make([]byte, 320), SampleRate: 16000}
+	if _, _, err := l.CompleteWithAudio(context.Background(), messages, turn, orchestrator.GenerationParams{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.Contents) != 3 || len(got.Contents[0].Parts) != 1 {
+		t.Fatalf("audio must only be attached to the last user message: %+v", got.Contents)
+	}
+	parts := got.Contents[2].Parts
+	if len(parts) != 2 || parts[0].Text != "are you sure" || parts[1].InlineData == nil || parts[1].InlineData.MimeType != "audio/wav" {
+		t.Fatalf("unexpected parts: %+v", parts)
+	}
+	wav, _ := base64.StdEncoding.DecodeString(parts[1].InlineData.Data)
+	if len(wav) != 44+320 || string(wav[:4]) != "RIFF" {
+		t.Errorf("expected a WAV of the turn audio, got %d bytes", len(wav))
+	}
+}
+
+func TestGoogleLLM_MessageParts(t *testing.T) {
+	var got struct {
+		Contents []struct {
+			Parts []map[string]interface{} `json:"parts"`
+		} `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"a mug"}]}}]}`))
+	}))
+	defer server.Close()
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"}
+	messages := []orchestrator.Message{{Role: "user", Content: "what is this?", Parts: []orchestrator.MessagePart{
+		orchestrator.ImageDataPart([]byte{1, 2}, "image/png"),
+		{Type: orchestrator.PartImage, URL: "gs://bucket/b.jpg", MimeType: "image/jpeg"},
+		orchestrator.ToolResultPart("lookup", "ceramic"),
+	}}}
+	if _, err := l.Complete(context.Background(), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parts := got.Contents[0].Parts
+	if len(parts) != 4 {
+		t.Fatalf("unexpected parts %v", parts)
+	}
+	if inline := parts[1]["inline_data"].(map[string]interface{}); inline["data"] != "AQI=" || inline["mime_type"] != "image/png" {
+		t.Errorf("unexpected inline data %v", inline)
+	}
+	if file := parts[2]["file_data"].(map[string]interface{}); file["file_uri"] != "gs://bucket/b.jpg" {
+		t.Errorf("unexpected file data %v", file)
+	}
+	if parts[3]["text"] != "Tool result (lookup): ceramic" {
+		t.Errorf("unexpected tool result %v", parts[3])
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"net/http"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type GroqLLM struct {
 	apiKey string
+	keyRef secrets.KeyRef
 	url    string
 	model  string
 }
@@ -27,35 +29,58 @@ func NewGroqLLM(apiKey string, model string) *GroqLLM {
 	}
 }
 
+func (l *GroqLLM) SetSecretProvider(provider secrets.Provider, name string) {
+	l.keyRef.Set(provider, name)
+}
+
 func (l *GroqLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return l.CompleteWithParams(ctx, messages, orchestrator.GenerationParams{})
+}
+
+func (l *GroqLLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *GroqLLM) SupportsSeed() bool {
+	return true
+}
+
+func (l *GroqLLM) CompleteWithUsage(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return "", orchestrator.Usage{}, err
+	}
+
 	payload := map[string]interface{}{
 		"model":    l.model,
-		"messages": messages,
+		"messages": chatMessages(messages),
 	}
+	applyChatParams(payload, params)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", l.url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return "", fmt.Errorf("groq api error: %v", errResp)
+		return "", orchestrator.Usage{}, &orchestrator.ProviderError{Provider: "groq api", StatusCode: resp.StatusCode, Body: errResp}
 	}
 
 	var result struct {
@@ -64,16 +89,28 @@ func (l *GroqLLM) Complete(ctx context.Context, messages []orchestrator.Message)
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Model string `json:"model"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("no response from groq")
+		return "", orchestrator.Usage{}, fmt.Errorf("no response from groq")
 	}
 
-	return result.Choices[0].Message.Content, nil
+	usage := orchestrator.Usage{
+		Model:            result.Model,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+	}
+	return result.Choices[0].Message.Content, usage, nil
 }
 
 func (l *GroqLLM) Name() string {
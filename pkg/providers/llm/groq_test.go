@@ -63,3 +63,22 @@ func TestGroqLLM(t *testing.T) {
 		t.Errorf("expected groq-llm, got %s", l.Name())
 	}
 }
+
+func TestGroqLLM_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"llama-3.3-70b-versatile","choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`))
+	}))
+	defer server.Close()
+
+	l := &GroqLLM{apiKey: "test-key", url: server.URL, model: "llama"}
+	text, usage, err := l.CompleteWithUsage(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, orchestrator.GenerationParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "ok" {
+		t.Errorf("expected 'ok', got %q", text)
+	}
+	if usage.Model != "llama-3.3-70b-versatile" || usage.PromptTokens != 12 || usage.CompletionTokens != 3 || usage.TotalTokens != 15 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HealthCheck looks the model up, which checks the key as well.
+func (l *OpenAILLM) HealthCheck(ctx context.Context) error {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(l.url, "/chat/completions")+"/models/"+l.model, http.Header{"Authorization": {"Bearer " + apiKey}})
+}
+
+// HealthCheck looks the model up, which checks the key as well.
+func (l *GroqLLM) HealthCheck(ctx context.Context) error {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(l.url, "/chat/completions")+"/models/"+l.model, http.Header{"Authorization": {"Bearer " + apiKey}})
+}
+
+// HealthCheck looks the model up, which checks the key as well.
+func (l *AnthropicLLM) HealthCheck(ctx context.Context) error {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(l.url, "/messages")+"/models/"+l.model, http.Header{
+		"X-Api-Key":         {apiKey},
+		"Anthropic-Version": {"2023-06-01"},
+	})
+}
+
+// HealthCheck looks the model up, which checks the key as well.
+func (l *GoogleLLM) HealthCheck(ctx context.Context) error {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(l.url, ":generateContent"), http.Header{"X-Goog-Api-Key": {apiKey}})
+}
+
+// checkEndpoint fails unless a GET of url with header answers 200.
+func checkEndpoint(ctx context.Context, url string, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed (status %d)", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,15 +3,20 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type OpenAILLM struct {
 	apiKey string
+	keyRef secrets.KeyRef
 	url    string
 	model  string
 }
@@ -27,35 +32,87 @@ func NewOpenAILLM(apiKey string, model string) *OpenAILLM {
 	}
 }
 
+func (l *OpenAILLM) SetSecretProvider(provider secrets.Provider, name string) {
+	l.keyRef.Set(provider, name)
+}
+
 func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return l.CompleteWithParams(ctx, messages, orchestrator.GenerationParams{})
+}
+
+func (l *OpenAILLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	text, _, err := l.CompleteWithUsage(ctx, messages, params)
+	return text, err
+}
+
+func (l *OpenAILLM) CompleteWithUsage(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	return l.chat(ctx, chatMessages(messages), params)
+}
+
+func (l *OpenAILLM) SupportsSeed() bool {
+	return true
+}
+
+// AcceptsAudio reports whether the model takes audio input, which OpenAI
+// only offers on its audio models such as gpt-4o-audio-preview.
+func (l *OpenAILLM) AcceptsAudio() bool {
+	return strings.Contains(l.model, "audio")
+}
+
+// CompleteWithAudio attaches the caller's audio as WAV to the last user
+// message, next to its transcript.
+func (l *OpenAILLM) CompleteWithAudio(ctx context.Context, messages []orchestrator.Message, turn orchestrator.TurnAudio, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	chat := chatMessages(messages)
+	last := lastUserMessage(messages)
+	if last < 0 {
+		return l.chat(ctx, chat, params)
+	}
+	content := append(chatContent(messages[last]), map[string]interface{}{
+		"type": "input_audio",
+		"input_audio": map[string]string{
+			"data":   base64.StdEncoding.EncodeToString(audio.NewWavBuffer(turn.PCM, turn.SampleRate)),
+			"format": "wav",
+		},
+	})
+	chat[last] = map[string]interface{}{"role": "user", "content": content}
+	return l.chat(ctx, chat, params)
+}
+
+func (l *OpenAILLM) chat(ctx context.Context, messages []interface{}, params orchestrator.GenerationParams) (string, orchestrator.Usage, error) {
+	apiKey, err := l.keyRef.Resolve(ctx, l.apiKey)
+	if err != nil {
+		return "", orchestrator.Usage{}, err
+	}
+
 	payload := map[string]interface{}{
 		"model":    l.model,
 		"messages": messages,
 	}
+	applyChatParams(payload, params)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", l.url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return "", fmt.Errorf("openai llm error (status %d): %v", resp.StatusCode, errResp)
+		return "", orchestrator.Usage{}, &orchestrator.ProviderError{Provider: "openai llm", StatusCode: resp.StatusCode, Body: errResp}
 	}
 
 	var result struct {
@@ -64,16 +121,28 @@ func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Messag
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Model string `json:"model"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", orchestrator.Usage{}, err
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("no choices returned from openai")
+		return "", orchestrator.Usage{}, fmt.Errorf("no choices returned from openai")
 	}
 
-	return result.Choices[0].Message.Content, nil
+	usage := orchestrator.Usage{
+		Model:            result.Model,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+	}
+	return result.Choices[0].Message.Content, usage, nil
 }
 
 func (l *OpenAILLM) Name() string {
@@ -2,12 +2,15 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 func TestOpenAILLM(t *testing.T) {
@@ -72,3 +75,224 @@ func TestOpenAILLM(t *testing.T) {
 		t.Errorf("expected openai-llm, got %s", l.Name())
 	}
 }
+
+func TestOpenAILLM_GenerationParams(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+	params := orchestrator.GenerationParams{
+		Temperature: orchestrator.Float(0.2),
+		MaxTokens:   64,
+		TopP:        orchestrator.Float(0.9),
+		Stop:        []string{"\n\n"},
+		Seed:        orchestrator.Int64(42),
+	}
+
+	if _, err := l.CompleteWithParams(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got["temperature"] != 0.2 || got["max_tokens"] != float64(64) || got["top_p"] != 0.9 {
+		t.Errorf("sampling params not sent: %v", got)
+	}
+	if stop, ok := got["stop"].([]interface{}); !ok || len(stop) != 1 {
+		t.Errorf("expected stop sequences, got %v", got["stop"])
+	}
+	if got["seed"] != float64(42) {
+		t.Errorf("expected seed, got %v", got["seed"])
+	}
+
+	got = nil
+	if _, err := l.Complete(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got["temperature"]; ok {
+		t.Errorf("expected no temperature when unset, got %v", got)
+	}
+}
+
+type rotatingSecrets struct {
+	key string
+}
+
+func (r *rotatingSecrets) GetSecret(ctx context.Context, name string) (string, error) {
+	if name != "OPENAI_API_KEY" {
+		return "", secrets.ErrNotFound
+	}
+	return r.key, nil
+}
+
+func (r *rotatingSecrets) Name() string { return "rotating" }
+
+func TestOpenAILLM_SecretProvider(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "static-key", url: server.URL, model: "gpt-4o"}
+	store := &rotatingSecrets{key: "key-v1"}
+	l.SetSecretProvider(store, "OPENAI_API_KEY")
+
+	msgs := []orchestrator.Message{{Role: "user", Content: "hi"}}
+	if _, err := l.Complete(context.Background(), msgs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "Bearer key-v1" {
+		t.Errorf("expected key from secret provider, got %q", auth)
+	}
+
+	store.key = "key-v2"
+	if _, err := l.Complete(context.Background(), msgs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "Bearer key-v2" {
+		t.Errorf("expected rotated key, got %q", auth)
+	}
+
+	l.SetSecretProvider(store, "MISSING")
+	if _, err := l.Complete(context.Background(), msgs); err == nil {
+		t.Error("expected error when the secret cannot be resolved")
+	}
+}
+
+func TestOpenAILLM_Audio(t *testing.T) {
+	var got struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	if (&OpenAILLM{model: "gpt-4o"}).AcceptsAudio() {
+		t.Error("gpt-4o does not take audio input")
+	}
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o-audio-preview"}
+	if !l.AcceptsAudio() {
+		t.Fatal("audio model must accept audio")
+	}
+
+	messages := []orchestrator.Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "really?"}}
+	turn := orchestrator.TurnAudio{PCM: make([]byte, 320), SampleRate: 24000}
+	if _, _, err := l.CompleteWithAudio(context.Background(), messages, turn, orchestrator.GenerationParams{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var system orchestrator.Message
+	if len(got.Messages) != 2 || json.Unmarshal(got.Messages[0], &system) != nil || system.Content != "be brief" {
+		t.Fatalf("unexpected messages: %s", got.Messages)
+	}
+	var user struct {
+		Role    string `json:"role"`
+		Content []struct {
+			Type       string `json:"type"`
+			Text       string `json:"text"`
+			InputAudio struct {
+				Data   string `json:"data"`
+				Format string `json:"format"`
+			} `json:"input_audio"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(got.Messages[1], &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != "user" || len(user.Content) != 2 || user.Content[0].Text != "really?" || user.Content[1].Type != "input_audio" || user.Content[1].InputAudio.Format != "wav" {
+		t.Fatalf("unexpected user message: %+v", user)
+	}
+	if wav, _ := base64.StdEncoding.DecodeString(user.Content[1].InputAudio.Data); len(wav) != 44+320 {
+		t.Errorf("expected a WAV of the turn audio, got %d bytes", len(wav))
+	}
+}
+
+func TestOpenAILLM_MessageParts(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"content":"a mug"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+	messages := []orchestrator.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "what is this?", Parts: []orchestrator.MessagePart{
+			orchestrator.ImageDataPart([]byte{1, 2}, "image/png"),
+			orchestrator.ImageURLPart("https://example.com/b.jpg"),
+			orchestrator.ToolResultPart("lookup", "ceramic"),
+		}},
+	}
+	if _, err := l.Complete(context.Background(), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := got["messages"].([]interface{})
+	if system := sent[0].(map[string]interface{}); system["content"] != "be brief" {
+		t.Errorf("text-only messages must keep string content: %v", system)
+	}
+	content := sent[1].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 4 {
+		t.Fatalf("unexpected content %v", content)
+	}
+	url := func(i int) interface{} {
+		return content[i].(map[string]interface{})["image_url"].(map[string]interface{})["url"]
+	}
+	if url(1) != "data:image/png;base64,AQI=" || url(2) != "https://example.com/b.jpg" {
+		t.Errorf("unexpected image urls %v, %v", url(1), url(2))
+	}
+	if text := content[3].(map[string]interface{})["text"]; text != "Tool result (lookup): ceramic" {
+		t.Errorf("unexpected tool result %v", text)
+	}
+}
+
+func TestOpenAILLM_HealthCheck(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":"gpt-4o"}`))
+	}))
+	defer server.Close()
+
+	l := NewOpenAILLM("test-key", "gpt-4o")
+	l.url = server.URL + "/v1/chat/completions"
+	var _ orchestrator.HealthChecker = l
+	if err := l.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/v1/models/gpt-4o" {
+		t.Errorf("expected the model to be looked up, got %s", path)
+	}
+
+	l.apiKey = "revoked"
+	if err := l.HealthCheck(context.Background()); err == nil {
+		t.Error("expected a rejected key to fail the check")
+	}
+}
+
+func TestOpenAILLM_RateLimitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	l := NewOpenAILLM("test-key", "gpt-4o")
+	l.url = server.URL
+	_, err := l.Complete(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}})
+	var perr *orchestrator.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected a ProviderError with status 429, got %v", err)
+	}
+}
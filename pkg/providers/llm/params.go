@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// applyChatParams sets OpenAI-compatible chat completion sampling fields.
+func applyChatParams(payload map[string]interface{}, params orchestrator.GenerationParams) {
+	if params.Temperature != nil {
+		payload["temperature"] = *params.Temperature
+	}
+	if params.MaxTokens > 0 {
+		payload["max_tokens"] = params.MaxTokens
+	}
+	if params.TopP != nil {
+		payload["top_p"] = *params.TopP
+	}
+	if len(params.Stop) > 0 {
+		payload["stop"] = params.Stop
+	}
+	if params.Seed != nil {
+		payload["seed"] = *params.Seed
+	}
+}
+
+// lastUserMessage returns the index of the last user message, or -1.
+func lastUserMessage(messages []orchestrator.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// chatMessages converts messages to the OpenAI chat format. Messages with
+// parts get a content array; the rest keep plain string content.
+func chatMessages(messages []orchestrator.Message) []interface{} {
+	out := make([]interface{}, len(messages))
+	for i, m := range messages {
+		if len(m.Parts) == 0 {
+			out[i] = map[string]interface{}{"role": m.Role, "content": m.Content}
+			continue
+		}
+		out[i] = map[string]interface{}{"role": m.Role, "content": chatContent(m)}
+	}
+	return out
+}
+
+func chatContent(m orchestrator.Message) []map[string]interface{} {
+	var content []map[string]interface{}
+	if m.Content != "" {
+		content = append(content, map[string]interface{}{"type": "text", "text": m.Content})
+	}
+	for _, p := range m.Parts {
+		if p.Type == orchestrator.PartImage {
+			content = append(content, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": imageURL(p)},
+			})
+		} else if text := p.AsText(); text != "" {
+			content = append(content, map[string]interface{}{"type": "text", "text": text})
+		}
+	}
+	return content
+}
+
+// imageURL returns the part's URL, or its data as a data: URL.
+func imageURL(p orchestrator.MessagePart) string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return "data:" + p.MimeType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// partsText joins a message's content with its text-renderable parts.
+func partsText(m orchestrator.Message) string {
+	texts := []string{}
+	if m.Content != "" {
+		texts = append(texts, m.Content)
+	}
+	for _, p := range m.Parts {
+		if text := p.AsText(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
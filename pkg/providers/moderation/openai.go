@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
+)
+
+type OpenAIModeration struct {
+	apiKey string
+	keyRef secrets.KeyRef
+	url    string
+	model  string
+}
+
+func NewOpenAIModeration(apiKey string, model string) *OpenAIModeration {
+	if model == "" {
+		model = "omni-moderation-latest"
+	}
+	return &OpenAIModeration{
+		apiKey: apiKey,
+		url:    "https://api.openai.com/v1/moderations",
+		model:  model,
+	}
+}
+
+func (m *OpenAIModeration) SetSecretProvider(provider secrets.Provider, name string) {
+	m.keyRef.Set(provider, name)
+}
+
+func (m *OpenAIModeration) Check(ctx context.Context, text string) (orchestrator.GuardrailVerdict, error) {
+	apiKey, err := m.keyRef.Resolve(ctx, m.apiKey)
+	if err != nil {
+		return orchestrator.GuardrailVerdict{}, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model": m.model,
+		"input": text,
+	})
+	if err != nil {
+		return orchestrator.GuardrailVerdict{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.url, bytes.NewReader(body))
+	if err != nil {
+		return orchestrator.GuardrailVerdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return orchestrator.GuardrailVerdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp interface{}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return orchestrator.GuardrailVerdict{}, fmt.Errorf("openai moderation error (status %d): %v", resp.StatusCode, errResp)
+	}
+
+	var result struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return orchestrator.GuardrailVerdict{}, err
+	}
+
+	for _, r := range result.Results {
+		if !r.Flagged {
+			continue
+		}
+		var flagged []string
+		for category, hit := range r.Categories {
+			if hit {
+				flagged = append(flagged, category)
+			}
+		}
+		sort.Strings(flagged)
+		return orchestrator.GuardrailVerdict{
+			Action:   orchestrator.GuardrailBlock,
+			Reason:   "flagged by openai moderation",
+			Category: strings.Join(flagged, ","),
+		}, nil
+	}
+	return orchestrator.GuardrailVerdict{Action: orchestrator.GuardrailAllow}, nil
+}
+
+func (m *OpenAIModeration) Name() string {
+	return "openai-moderation"
+}
@@ -0,0 +1,53 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestOpenAIModeration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Input string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Input == "bad" {
+			w.Write([]byte(`{"results":[{"flagged":true,"categories":{"violence":true,"hate":false,"harassment":true}}]}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"flagged":false,"categories":{}}]}`))
+	}))
+	defer server.Close()
+
+	m := &OpenAIModeration{apiKey: "test-key", url: server.URL, model: "omni-moderation-latest"}
+
+	verdict, err := m.Check(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Action != orchestrator.GuardrailAllow {
+		t.Errorf("expected allow, got %s", verdict.Action)
+	}
+
+	verdict, err = m.Check(context.Background(), "bad")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Action != orchestrator.GuardrailBlock || verdict.Category != "harassment,violence" {
+		t.Errorf("unexpected verdict: %+v", verdict)
+	}
+
+	m.apiKey = "wrong"
+	if _, err := m.Check(context.Background(), "hello"); err == nil {
+		t.Error("expected error on unauthorized response")
+	}
+}
@@ -8,32 +8,55 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type AssemblyAISTT struct {
-	apiKey string
+	apiKey     string
+	keyRef     secrets.KeyRef
+	sampleRate int
+	params     orchestrator.STTParams
 }
 
 func NewAssemblyAISTT(apiKey string) *AssemblyAISTT {
 	return &AssemblyAISTT{
-		apiKey: apiKey,
+		apiKey:     apiKey,
+		sampleRate: 44100,
 	}
 }
 
+func (s *AssemblyAISTT) SetSampleRate(rate int) {
+	s.sampleRate = rate
+}
+
+func (s *AssemblyAISTT) SetSTTParams(params orchestrator.STTParams) {
+	s.params = params
+}
+
+func (s *AssemblyAISTT) SetSecretProvider(provider secrets.Provider, name string) {
+	s.keyRef.Set(provider, name)
+}
+
 func (s *AssemblyAISTT) Name() string {
 	return "assemblyai-stt"
 }
 
 func (s *AssemblyAISTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (string, error) {
-	
-	uploadURL, err := s.upload(ctx, audioPCM)
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Raw PCM carries no rate; a WAV header does.
+	uploadURL, err := s.upload(ctx, apiKey, audio.NewWavBuffer(audioPCM, s.sampleRate))
 	if err != nil {
 		return "", err
 	}
 
 	
-	transcriptID, err := s.submit(ctx, uploadURL, lang)
+	transcriptID, err := s.submit(ctx, apiKey, uploadURL, lang)
 	if err != nil {
 		return "", err
 	}
@@ -44,7 +67,7 @@ func (s *AssemblyAISTT) Transcribe(ctx context.Context, audioPCM []byte, lang or
 		case <-ctx.Done():
 			return "", ctx.Err()
 		case <-time.After(500 * time.Millisecond):
-			text, status, err := s.getTranscript(ctx, transcriptID)
+			text, status, err := s.getTranscript(ctx, apiKey, transcriptID)
 			if err != nil {
 				return "", err
 			}
@@ -58,12 +81,12 @@ func (s *AssemblyAISTT) Transcribe(ctx context.Context, audioPCM []byte, lang or
 	}
 }
 
-func (s *AssemblyAISTT) upload(ctx context.Context, audioPCM []byte) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.assemblyai.com/v2/upload", bytes.NewReader(audioPCM))
+func (s *AssemblyAISTT) upload(ctx context.Context, apiKey string, data []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.assemblyai.com/v2/upload", bytes.NewReader(data))
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("Authorization", s.apiKey)
+	req.Header.Set("Authorization", apiKey)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -78,17 +101,23 @@ func (s *AssemblyAISTT) upload(ctx context.Context, audioPCM []byte) (string, er
 	return result.UploadURL, nil
 }
 
-func (s *AssemblyAISTT) submit(ctx context.Context, uploadURL string, lang orchestrator.Language) (string, error) {
+func (s *AssemblyAISTT) submit(ctx context.Context, apiKey string, uploadURL string, lang orchestrator.Language) (string, error) {
 	payload := map[string]interface{}{
 		"audio_url": uploadURL,
 	}
 	if lang != "" {
 		payload["language_code"] = string(lang)
 	}
+	if s.params.Punctuate != nil {
+		payload["punctuate"] = *s.params.Punctuate
+	}
+	if s.params.SmartFormat != nil {
+		payload["format_text"] = *s.params.SmartFormat
+	}
 
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequestWithContext(ctx, "POST", "https://api.assemblyai.com/v2/transcript", bytes.NewReader(body))
-	req.Header.Set("Authorization", s.apiKey)
+	req.Header.Set("Authorization", apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
@@ -104,9 +133,9 @@ func (s *AssemblyAISTT) submit(ctx context.Context, uploadURL string, lang orche
 	return result.ID, nil
 }
 
-func (s *AssemblyAISTT) getTranscript(ctx context.Context, id string) (string, string, error) {
+func (s *AssemblyAISTT) getTranscript(ctx context.Context, apiKey string, id string) (string, string, error) {
 	req, _ := http.NewRequestWithContext(ctx, "GET", "https://api.assemblyai.com/v2/transcript/"+id, nil)
-	req.Header.Set("Authorization", s.apiKey)
+	req.Header.Set("Authorization", apiKey)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
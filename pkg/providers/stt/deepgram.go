@@ -8,35 +8,78 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type DeepgramSTT struct {
-	apiKey string
-	url    string
+	apiKey     string
+	keyRef     secrets.KeyRef
+	url        string
+	sampleRate int
+	params     orchestrator.STTParams
 }
 
 func NewDeepgramSTT(apiKey string) *DeepgramSTT {
 	return &DeepgramSTT{
-		apiKey: apiKey,
-		url:    "https://api.deepgram.com/v1/listen",
+		apiKey:     apiKey,
+		url:        "https://api.deepgram.com/v1/listen",
+		sampleRate: 44100,
 	}
 }
 
+func (s *DeepgramSTT) SetSampleRate(rate int) {
+	s.sampleRate = rate
+}
+
+func (s *DeepgramSTT) SetSTTParams(params orchestrator.STTParams) {
+	s.params = params
+}
+
+func (s *DeepgramSTT) SetSecretProvider(provider secrets.Provider, name string) {
+	s.keyRef.Set(provider, name)
+}
+
 func (s *DeepgramSTT) Name() string {
 	return "deepgram-stt"
 }
 
 func (s *DeepgramSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (string, error) {
-	u, err := url.Parse(s.url)
+	result, err := s.TranscribeDetailed(ctx, audioPCM, lang)
 	if err != nil {
 		return "", err
 	}
+	return result.Text, nil
+}
+
+// TranscribeDetailed reports the confidence and, with STTParams.Diarize, a
+// segment per change of speaker.
+func (s *DeepgramSTT) TranscribeDetailed(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (*orchestrator.TranscriptionResult, error) {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return nil, err
+	}
 
 	params := u.Query()
 	params.Set("model", "nova-2")
 	params.Set("smart_format", "true")
+	if s.params.SmartFormat != nil {
+		params.Set("smart_format", strconv.FormatBool(*s.params.SmartFormat))
+	}
+	if s.params.Punctuate != nil {
+		params.Set("punctuate", strconv.FormatBool(*s.params.Punctuate))
+	}
+	diarize := s.params.Diarize != nil && *s.params.Diarize
+	if diarize {
+		params.Set("diarize", "true")
+	}
 	if lang != "" {
 		params.Set("language", string(lang))
 	}
@@ -44,40 +87,88 @@ func (s *DeepgramSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orch
 
 	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(audioPCM))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Token "+s.apiKey)
-	req.Header.Set("Content-Type", "audio/l16; rate=44100; channels=1") 
+	req.Header.Set("Authorization", "Token "+apiKey)
+	req.Header.Set("Content-Type", fmt.Sprintf("audio/l16; rate=%d; channels=1", s.sampleRate))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("deepgram error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("deepgram error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
 	var result struct {
+		Metadata struct {
+			Duration float64 `json:"duration"`
+		} `json:"metadata"`
 		Results struct {
 			Channels []struct {
 				Alternatives []struct {
-					Transcript string `json:"transcript"`
+					Transcript string         `json:"transcript"`
+					Confidence float64        `json:"confidence"`
+					Words      []deepgramWord `json:"words"`
 				} `json:"alternatives"`
 			} `json:"channels"`
 		} `json:"results"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return nil, err
 	}
 
+	detailed := &orchestrator.TranscriptionResult{Duration: seconds(result.Metadata.Duration)}
 	if len(result.Results.Channels) == 0 || len(result.Results.Channels[0].Alternatives) == 0 {
-		return "", nil
+		return detailed, nil
+	}
+	alt := result.Results.Channels[0].Alternatives[0]
+	detailed.Text = alt.Transcript
+	detailed.Confidence = alt.Confidence
+	if diarize {
+		detailed.Segments, detailed.Speaker = speakerSegments(alt.Words)
 	}
+	return detailed, nil
+}
 
-	return result.Results.Channels[0].Alternatives[0].Transcript, nil
+type deepgramWord struct {
+	Word           string  `json:"word"`
+	PunctuatedWord string  `json:"punctuated_word"`
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Speaker        *int    `json:"speaker"`
+}
+
+// speakerSegments groups diarized words into a segment per run of one
+// speaker, and returns the speaker who said the most words.
+func speakerSegments(words []deepgramWord) ([]orchestrator.TranscriptSegment, string) {
+	var segments []orchestrator.TranscriptSegment
+	counts := make(map[string]int)
+	var top string
+	for _, w := range words {
+		if w.Speaker == nil {
+			continue
+		}
+		speaker := strconv.Itoa(*w.Speaker)
+		text := w.PunctuatedWord
+		if text == "" {
+			text = w.Word
+		}
+		if n := len(segments); n > 0 && segments[n-1].Speaker == speaker {
+			segments[n-1].Text += " " + text
+			segments[n-1].End = seconds(w.End)
+		} else {
+			segments = append(segments, orchestrator.TranscriptSegment{Start: seconds(w.Start), End: seconds(w.End), Text: text, Speaker: speaker})
+		}
+		counts[speaker]++
+		if counts[speaker] > counts[top] {
+			top = speaker
+		}
+	}
+	return segments, top
 }
@@ -0,0 +1,124 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestDeepgramSTT_SampleRate(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.Write([]byte(`{"results":{"channels":[{"alternatives":[{"transcript":"hola"}]}]}}`))
+	}))
+	defer server.Close()
+
+	s := NewDeepgramSTT("test-key")
+	s.url = server.URL
+	s.SetSampleRate(48000)
+
+	result, err := s.Transcribe(context.Background(), []byte{0, 0, 0, 0}, orchestrator.LanguageEs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "hola" {
+		t.Errorf("expected 'hola', got '%s'", result)
+	}
+	if contentType != "audio/l16; rate=48000; channels=1" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+}
+
+func TestDeepgramSTT_Params(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results":{"channels":[{"alternatives":[{"transcript":"ok"}]}]}}`))
+	}))
+	defer server.Close()
+
+	s := NewDeepgramSTT("test-key")
+	s.url = server.URL
+	if _, err := s.Transcribe(context.Background(), []byte{0, 0}, orchestrator.LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("smart_format") != "true" || query.Has("punctuate") {
+		t.Errorf("unexpected default query %v", query)
+	}
+
+	off, on := false, true
+	s.SetSTTParams(orchestrator.STTParams{SmartFormat: &off, Punctuate: &on})
+	if _, err := s.Transcribe(context.Background(), []byte{0, 0}, orchestrator.LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("smart_format") != "false" || query.Get("punctuate") != "true" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestDeepgramSTT_Diarize(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"metadata":{"duration":2.5},"results":{"channels":[{"alternatives":[{"transcript":"is it open yes it is","confidence":0.9,"words":[
+			{"word":"is","start":0.1,"end":0.2,"speaker":0},
+			{"word":"it","start":0.2,"end":0.3,"speaker":0},
+			{"word":"open","punctuated_word":"open?","start":0.3,"end":0.6,"speaker":0},
+			{"word":"yes","punctuated_word":"Yes,","start":1.0,"end":1.2,"speaker":1},
+			{"word":"it","start":1.2,"end":1.3,"speaker":1},
+			{"word":"is","punctuated_word":"is.","start":1.3,"end":1.5,"speaker":1},
+			{"word":"thanks","start":1.8,"end":2.1,"speaker":1}]}]}]}}`))
+	}))
+	defer server.Close()
+
+	s := NewDeepgramSTT("test-key")
+	s.url = server.URL
+	on := true
+	s.SetSTTParams(orchestrator.STTParams{Diarize: &on})
+
+	result, err := s.TranscribeDetailed(context.Background(), []byte{0, 0}, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("diarize") != "true" {
+		t.Errorf("diarization was not requested: %v", query)
+	}
+	if result.Speaker != "1" || result.Confidence != 0.9 || result.Duration != 2500*time.Millisecond {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(result.Segments) != 2 {
+		t.Fatalf("expected a segment per speaker, got %+v", result.Segments)
+	}
+	first := result.Segments[0]
+	if first.Speaker != "0" || first.Text != "is it open?" || first.Start != 100*time.Millisecond || first.End != 600*time.Millisecond {
+		t.Errorf("unexpected first segment %+v", first)
+	}
+	if result.Segments[1].Speaker != "1" || result.Segments[1].Text != "Yes, it is. thanks" {
+		t.Errorf("unexpected second segment %+v", result.Segments[1])
+	}
+}
+
+func TestDeepgramSTT_HealthCheck(t *testing.T) {
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer server.Close()
+
+	s := NewDeepgramSTT("test-key")
+	s.url = server.URL + "/v1/listen"
+	var _ orchestrator.HealthChecker = s
+	if err := s.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/v1/projects" || auth != "Token test-key" {
+		t.Errorf("unexpected request to %s with %q", path, auth)
+	}
+}
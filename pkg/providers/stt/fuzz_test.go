@@ -0,0 +1,51 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// FuzzResponses feeds every provider the same malformed response body; a
+// bad reply must come back as an error, never a panic.
+func FuzzResponses(f *testing.F) {
+	f.Add([]byte(`{"text":"hi","duration":1.5,"language":"english","segments":[{"start":0,"end":1.5,"text":"hi","avg_logprob":-0.2,"no_speech_prob":0.9}]}`))
+	f.Add([]byte(`{"metadata":{"duration":1},"results":{"channels":[{"alternatives":[{"transcript":"hi","words":[{"word":"hi","speaker":0},{"word":"there"}]}]}]}}`))
+	f.Add([]byte(`{"results":{"channels":[{"alternatives":[]}]},"segments":[null]}`))
+	f.Add([]byte(`{"duration":1e308,"segments":[{"start":-1e308,"avg_logprob":1e308}]}`))
+
+	var (
+		mu   sync.Mutex
+		body []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	diarize, noSpeech := true, 0.5
+	deepgram := NewDeepgramSTT("test-key")
+	deepgram.url = server.URL
+	deepgram.SetSTTParams(orchestrator.STTParams{Diarize: &diarize})
+	openai := NewOpenAISTT("test-key", "whisper-1")
+	openai.url = server.URL
+	openai.SetSTTParams(orchestrator.STTParams{NoSpeechThreshold: &noSpeech})
+	groq := NewGroqSTT("test-key", "whisper-large-v3")
+	groq.url = server.URL
+	providers := []orchestrator.DetailedTranscriber{deepgram, openai, groq}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		mu.Lock()
+		body = data
+		mu.Unlock()
+		for _, p := range providers {
+			p.TranscribeDetailed(context.Background(), []byte{0, 0, 1, 0}, orchestrator.LanguageEn)
+		}
+	})
+}
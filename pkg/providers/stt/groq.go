@@ -11,13 +11,16 @@ import (
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
 type GroqSTT struct {
 	apiKey     string
+	keyRef     secrets.KeyRef
 	url        string
 	model      string
 	sampleRate int
+	params     orchestrator.STTParams
 }
 
 func NewGroqSTT(apiKey string, model string) *GroqSTT {
@@ -32,66 +35,113 @@ func NewGroqSTT(apiKey string, model string) *GroqSTT {
 	}
 }
 
+func (s *GroqSTT) SetSecretProvider(provider secrets.Provider, name string) {
+	s.keyRef.Set(provider, name)
+}
+
 func (s *GroqSTT) SetSampleRate(rate int) {
 	s.sampleRate = rate
 }
 
+func (s *GroqSTT) SetSTTParams(params orchestrator.STTParams) {
+	s.params = params
+}
+
 func (s *GroqSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (string, error) {
+	if s.params.NoSpeechThreshold != nil {
+		// No-speech probabilities only come with verbose_json.
+		result, err := s.TranscribeDetailed(ctx, audioPCM, lang)
+		if err != nil {
+			return "", err
+		}
+		return result.Text, nil
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := s.transcribe(ctx, audioPCM, lang, false, &result); err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// TranscribeDetailed requests verbose_json, which adds segment timestamps.
+func (s *GroqSTT) TranscribeDetailed(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (*orchestrator.TranscriptionResult, error) {
+	var result verboseTranscription
+	if err := s.transcribe(ctx, audioPCM, lang, true, &result); err != nil {
+		return nil, err
+	}
+	return result.toResult(s.params.NoSpeechThreshold), nil
+}
+
+func (s *GroqSTT) transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language, verbose bool, out interface{}) error {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return err
+	}
+
 	wavData := audio.NewWavBuffer(audioPCM, s.sampleRate)
 
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	if err := writer.WriteField("model", s.model); err != nil {
-		return "", err
+		return err
+	}
+
+	if verbose {
+		if err := writer.WriteField("response_format", "verbose_json"); err != nil {
+			return err
+		}
+		if err := writer.WriteField("timestamp_granularities[]", "segment"); err != nil {
+			return err
+		}
+	}
+
+	if err := writeWhisperParams(writer, s.params); err != nil {
+		return err
 	}
 
 	if lang != "" {
 		if err := writer.WriteField("language", string(lang)); err != nil {
-			return "", err
+			return err
 		}
 	}
 
 	part, err := writer.CreateFormFile("file", "audio.wav")
 	if err != nil {
-		return "", err
+		return err
 	}
 	if _, err := io.Copy(part, bytes.NewReader(wavData)); err != nil {
-		return "", err
+		return err
 	}
 
 	if err := writer.Close(); err != nil {
-		return "", err
+		return err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", s.url, body)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return "", fmt.Errorf("groq stt error (status %d): %v", resp.StatusCode, errResp)
-	}
-
-	var result struct {
-		Text string `json:"text"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return fmt.Errorf("groq stt error (status %d): %v", resp.StatusCode, errResp)
 	}
 
-	return result.Text, nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
 
 func (s *GroqSTT) Name() string {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 )
@@ -51,3 +52,25 @@ func TestGroqSTT(t *testing.T) {
 		t.Errorf("expected groq-stt, got %s", s.Name())
 	}
 }
+
+func TestGroqSTT_TranscribeDetailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("response_format") != "verbose_json" {
+			t.Errorf("expected verbose_json, got %q", r.FormValue("response_format"))
+		}
+		w.Write([]byte(`{"text":"hi","duration":0.8,"segments":[{"start":0.1,"end":0.8,"text":" hi","avg_logprob":-0.1}]}`))
+	}))
+	defer server.Close()
+
+	s := &GroqSTT{apiKey: "test-key", url: server.URL, model: "whisper-large-v3", sampleRate: 16000}
+	result, err := s.TranscribeDetailed(context.Background(), []byte{0, 0}, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Segments) != 1 || result.Segments[0].Start != 100*time.Millisecond || result.Segments[0].Text != "hi" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Confidence < 0.90 || result.Confidence > 0.91 {
+		t.Errorf("expected a confidence of about 0.905, got %v", result.Confidence)
+	}
+}
@@ -0,0 +1,64 @@
+package stt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HealthCheck looks the model up, which checks the key as well.
+func (s *OpenAISTT) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(s.url, "/audio/transcriptions")+"/models/"+s.model, http.Header{"Authorization": {"Bearer " + apiKey}})
+}
+
+// HealthCheck looks the model up, which checks the key as well.
+func (s *GroqSTT) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(s.url, "/audio/transcriptions")+"/models/"+s.model, http.Header{"Authorization": {"Bearer " + apiKey}})
+}
+
+// HealthCheck lists the key's projects, which needs nothing but a valid key.
+func (s *DeepgramSTT) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, strings.TrimSuffix(s.url, "/listen")+"/projects", http.Header{"Authorization": {"Token " + apiKey}})
+}
+
+// HealthCheck lists the most recent transcript, which checks the key.
+func (s *AssemblyAISTT) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.keyRef.Resolve(ctx, s.apiKey)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(ctx, "https://api.assemblyai.com/v2/transcript?limit=1", http.Header{"Authorization": {apiKey}})
+}
+
+// checkEndpoint fails unless a GET of url with header answers 200.
+func checkEndpoint(ctx context.Context, url string, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed (status %d)", resp.StatusCode)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 )
@@ -46,3 +47,69 @@ func TestOpenAISTT(t *testing.T) {
 		t.Errorf("expected openai_stt, got %s", s.Name())
 	}
 }
+
+func TestOpenAISTT_TranscribeDetailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("response_format") != "verbose_json" || r.FormValue("timestamp_granularities[]") != "segment" {
+			t.Errorf("unexpected form %v", r.MultipartForm.Value)
+		}
+		w.Write([]byte(`{"text":"Hello there. How are you?","duration":2.5,"segments":[` +
+			`{"start":0,"end":1.2,"text":" Hello there."},{"start":1.2,"end":2.5,"text":" How are you?"}]}`))
+	}))
+	defer server.Close()
+
+	s := &OpenAISTT{apiKey: "test-key", url: server.URL, model: "whisper-1", sampleRate: 16000}
+	result, err := s.TranscribeDetailed(context.Background(), []byte{0, 0}, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "Hello there. How are you?" || result.Duration != 2500*time.Millisecond || len(result.Segments) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if seg := result.Segments[1]; seg.Start != 1200*time.Millisecond || seg.End != 2500*time.Millisecond || seg.Text != "How are you?" {
+		t.Errorf("unexpected segment %+v", seg)
+	}
+}
+
+func TestOpenAISTT_DetectsLanguage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("language") != "" {
+			t.Error("expected no language when detecting")
+		}
+		w.Write([]byte(`{"text":"Hola, ¿qué tal?","duration":1.1,"language":"spanish"}`))
+	}))
+	defer server.Close()
+
+	s := &OpenAISTT{apiKey: "test-key", url: server.URL, model: "whisper-1", sampleRate: 16000}
+	result, err := s.TranscribeDetailed(context.Background(), []byte{0, 0}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Language != orchestrator.LanguageEs {
+		t.Errorf("expected es, got %q", result.Language)
+	}
+}
+
+func TestOpenAISTT_Params(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("temperature") != "0" || r.FormValue("response_format") != "verbose_json" {
+			t.Errorf("unexpected form %v", r.MultipartForm.Value)
+		}
+		w.Write([]byte(`{"text":"Book it. Thanks for watching!","segments":[` +
+			`{"start":0,"end":1,"text":" Book it.","no_speech_prob":0.05},` +
+			`{"start":1,"end":3,"text":" Thanks for watching!","no_speech_prob":0.9}]}`))
+	}))
+	defer server.Close()
+
+	temperature, threshold := 0.0, 0.6
+	s := &OpenAISTT{apiKey: "test-key", url: server.URL, model: "whisper-1", sampleRate: 16000}
+	s.SetSTTParams(orchestrator.STTParams{Temperature: &temperature, NoSpeechThreshold: &threshold})
+
+	text, err := s.Transcribe(context.Background(), []byte{0, 0}, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Book it." {
+		t.Errorf("expected the no-speech segment to be dropped, got %q", text)
+	}
+}
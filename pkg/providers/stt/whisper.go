@@ -0,0 +1,96 @@
+package stt
+
+import (
+	"math"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// verboseTranscription is the verbose_json response of Whisper-compatible
+// transcription APIs. Times are in seconds.
+type verboseTranscription struct {
+	Text     string  `json:"text"`
+	Duration float64 `json:"duration"`
+	Language string  `json:"language"`
+	Segments []struct {
+		Start        float64 `json:"start"`
+		End          float64 `json:"end"`
+		Text         string  `json:"text"`
+		AvgLogprob   float64 `json:"avg_logprob"`
+		NoSpeechProb float64 `json:"no_speech_prob"`
+	} `json:"segments"`
+}
+
+// toResult drops segments more likely than noSpeech to be silence, when set,
+// and then rebuilds the text from the remaining ones. Confidence is the mean
+// token probability of the remaining segments.
+func (v *verboseTranscription) toResult(noSpeech *float64) *orchestrator.TranscriptionResult {
+	result := &orchestrator.TranscriptionResult{
+		Text:     v.Text,
+		Duration: seconds(v.Duration),
+		Language: whisperLanguage(v.Language),
+	}
+	dropped := false
+	var logprob float64
+	for _, seg := range v.Segments {
+		if noSpeech != nil && seg.NoSpeechProb > *noSpeech {
+			dropped = true
+			continue
+		}
+		logprob += seg.AvgLogprob
+		result.Segments = append(result.Segments, orchestrator.TranscriptSegment{
+			Start: seconds(seg.Start),
+			End:   seconds(seg.End),
+			Text:  strings.TrimSpace(seg.Text),
+		})
+	}
+	if n := len(result.Segments); n > 0 {
+		result.Confidence = math.Exp(logprob / float64(n))
+	}
+	if dropped {
+		texts := make([]string, len(result.Segments))
+		for i, seg := range result.Segments {
+			texts[i] = seg.Text
+		}
+		result.Text = strings.Join(texts, " ")
+	}
+	return result
+}
+
+// whisperLanguages maps the language names Whisper reports to codes.
+var whisperLanguages = map[string]orchestrator.Language{
+	"english":    orchestrator.LanguageEn,
+	"spanish":    orchestrator.LanguageEs,
+	"french":     orchestrator.LanguageFr,
+	"german":     orchestrator.LanguageDe,
+	"italian":    orchestrator.LanguageIt,
+	"portuguese": orchestrator.LanguagePt,
+	"japanese":   orchestrator.LanguageJa,
+	"chinese":    orchestrator.LanguageZh,
+}
+
+// whisperLanguage returns the code of a detected language, which Whisper
+// reports by name and some compatible APIs by code.
+func whisperLanguage(name string) orchestrator.Language {
+	name = strings.ToLower(name)
+	if lang, ok := whisperLanguages[name]; ok {
+		return lang
+	}
+	return orchestrator.Language(name)
+}
+
+// writeWhisperParams adds the STTParams Whisper takes as form fields.
+func writeWhisperParams(w *multipart.Writer, params orchestrator.STTParams) error {
+	if params.Temperature == nil {
+		return nil
+	}
+	return w.WriteField("temperature", strconv.FormatFloat(*params.Temperature, 'f', -1, 64))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -1,32 +1,108 @@
 package tts
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
 )
 
+// lokutorSampleRate is the rate of the PCM Lokutor streams back.
+const lokutorSampleRate = 44100
+
+var errDial = errors.New("failed to connect to lokutor")
+
+// LokutorOptions points LokutorTTS at a self-hosted or staging deployment.
+// Zero fields keep the defaults for api.lokutor.com.
+type LokutorOptions struct {
+	Host string
+	// Scheme is "wss" (default) or "ws". The HTTP fallback uses https or
+	// http to match.
+	Scheme string
+	// Path is the WebSocket endpoint, "/ws" by default.
+	Path string
+	// HTTPPath is the endpoint of the HTTP fallback, "/synthesize" by
+	// default.
+	HTTPPath string
+	// Header is sent with every request, e.g. for an auth proxy.
+	Header http.Header
+	// Visemes asks for visemes and word boundaries with the audio, reported
+	// by StreamSynthesizeMarks. The HTTP fallback does not carry them.
+	Visemes bool
+}
+
 type LokutorTTS struct {
-	apiKey string
-	host   string
-	scheme string
-	mu     sync.Mutex
-	conn   *websocket.Conn
+	apiKey     string
+	keyRef     secrets.KeyRef
+	host       string
+	scheme     string
+	path       string
+	httpPath   string
+	header     http.Header
+	visemes    bool
+	mu         sync.Mutex
+	conn       *websocket.Conn
+	sampleRate int
+	// Once the WebSocket dial failed and HTTP worked, e.g. behind a proxy
+	// that blocks WebSockets, requests go over HTTP until wsRetryAt. Each
+	// failed retry doubles wsBackoff.
+	wsRetryAt time.Time
+	wsBackoff time.Duration
 }
 
+const (
+	wsRetryMin = 30 * time.Second
+	wsRetryMax = 10 * time.Minute
+)
+
 func NewLokutorTTS(apiKey string) *LokutorTTS {
+	return NewLokutorTTSWithOptions(apiKey, LokutorOptions{})
+}
+
+func NewLokutorTTSWithOptions(apiKey string, opts LokutorOptions) *LokutorTTS {
+	if opts.Host == "" {
+		opts.Host = "api.lokutor.com"
+	}
+	if opts.Scheme == "" {
+		opts.Scheme = "wss"
+	}
 	return &LokutorTTS{
-		apiKey: apiKey,
-		host:   "api.lokutor.com",
-		scheme: "wss",
+		apiKey:   apiKey,
+		host:     opts.Host,
+		scheme:   opts.Scheme,
+		path:     opts.Path,
+		httpPath: opts.HTTPPath,
+		header:   opts.Header.Clone(),
+		visemes:  opts.Visemes,
 	}
 }
 
+func (t *LokutorTTS) SetSecretProvider(provider secrets.Provider, name string) {
+	t.keyRef.Set(provider, name)
+}
+
+// SetSampleRate makes StreamSynthesize deliver audio at rate, resampling
+// Lokutor's 44.1kHz output when they differ.
+func (t *LokutorTTS) SetSampleRate(rate int) {
+	t.mu.Lock()
+	t.sampleRate = rate
+	t.mu.Unlock()
+}
+
 func (t *LokutorTTS) getConn(ctx context.Context) (*websocket.Conn, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -35,15 +111,25 @@ func (t *LokutorTTS) getConn(ctx context.Context) (*websocket.Conn, error) {
 		return t.conn, nil
 	}
 
-	u := url.URL{Scheme: t.scheme, Host: t.host, Path: "/ws", RawQuery: "api_key=" + t.apiKey}
-	conn, _, err := websocket.Dial(ctx, u.String(), nil)
+	apiKey, err := t.keyRef.Resolve(ctx, t.apiKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to lokutor: %w", err)
+		return nil, err
+	}
+
+	path := t.path
+	if path == "" {
+		path = "/ws"
+	}
+	u := url.URL{Scheme: t.scheme, Host: t.host, Path: path, RawQuery: "api_key=" + apiKey}
+	conn, _, err := websocket.Dial(ctx, u.String(), &websocket.DialOptions{HTTPHeader: t.header})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errDial, err)
 	}
 
 	conn.SetReadLimit(10 * 1024 * 1024)
 
 	t.conn = conn
+	t.wsBackoff = 0
 	return conn, nil
 }
 
@@ -60,22 +146,107 @@ func (t *LokutorTTS) Synthesize(ctx context.Context, text string, voice orchestr
 }
 
 func (t *LokutorTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
-	conn, err := t.getConn(ctx)
-	if err != nil {
-		return err
-	}
+	return t.stream(ctx, text, voice, lang, 1.0, onChunk, nil)
+}
 
+// StreamSynthesizeMarks is StreamSynthesize, also reporting visemes and word
+// boundaries when the TTS was created with LokutorOptions.Visemes.
+func (t *LokutorTTS) StreamSynthesizeMarks(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error, onMark func(orchestrator.SpeechMark) error) error {
+	return t.stream(ctx, text, voice, lang, 1.0, onChunk, onMark)
+}
+
+// StreamSynthesizeProsody speaks each segment at its rate, and pauses in
+// silence. Lokutor has no pitch or emphasis controls.
+func (t *LokutorTTS) StreamSynthesizeProsody(ctx context.Context, segments []orchestrator.SpeechSegment, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
 	t.mu.Lock()
-	defer t.mu.Unlock()
+	rate := t.sampleRate
+	t.mu.Unlock()
+	if rate <= 0 {
+		rate = lokutorSampleRate
+	}
+
+	var text strings.Builder
+	speed := 1.0
+	flush := func() error {
+		s := strings.TrimSpace(text.String())
+		text.Reset()
+		// Punctuation left over after a span is not worth a request.
+		if strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			return nil
+		}
+		return t.stream(ctx, s, voice, lang, speed, onChunk, nil)
+	}
+	for _, seg := range segments {
+		segSpeed := seg.Prosody.Rate
+		if segSpeed <= 0 {
+			segSpeed = 1.0
+		}
+		if seg.Pause > 0 || segSpeed != speed {
+			if err := flush(); err != nil {
+				return err
+			}
+			speed = segSpeed
+		}
+		if seg.Pause > 0 {
+			if err := onChunk(make([]byte, int(seg.Pause.Seconds()*float64(rate))*2)); err != nil {
+				return err
+			}
+			continue
+		}
+		text.WriteString(seg.Text)
+	}
+	return flush()
+}
+
+// lokutorMark is a viseme or word boundary message, sent as text between
+// the audio when visemes are requested.
+type lokutorMark struct {
+	Type       string  `json:"type"`
+	Viseme     string  `json:"viseme"`
+	Word       string  `json:"word"`
+	OffsetMs   float64 `json:"offset_ms"`
+	DurationMs float64 `json:"duration_ms"`
+}
 
+func (t *LokutorTTS) stream(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, speed float64, onChunk func([]byte) error, onMark func(orchestrator.SpeechMark) error) error {
+	visemes := t.visemes && onMark != nil
 	req := map[string]interface{}{
 		"text":    text,
 		"voice":   string(voice),
 		"lang":    string(lang),
-		"speed":   1.0,
+		"speed":   speed,
 		"steps":   6,
-		"visemes": false,
+		"visemes": visemes,
+	}
+
+	t.mu.Lock()
+	onChunk, flush := t.pcmChunks(onChunk)
+	httpOnly := time.Now().Before(t.wsRetryAt)
+	t.mu.Unlock()
+	if httpOnly {
+		if err := t.streamHTTP(ctx, req, onChunk); err != nil {
+			return err
+		}
+		return flush()
+	}
+
+	conn, err := t.getConn(ctx)
+	if errors.Is(err, errDial) && ctx.Err() == nil {
+		if err := t.streamHTTP(ctx, req, onChunk); err != nil {
+			return err
+		}
+		t.mu.Lock()
+		t.wsBackoff = min(max(2*t.wsBackoff, wsRetryMin), wsRetryMax)
+		t.wsRetryAt = time.Now().Add(t.wsBackoff)
+		t.mu.Unlock()
+		return flush()
 	}
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
 	if err := wsjson.Write(ctx, conn, req); err != nil {
 		t.conn = nil
@@ -93,20 +264,156 @@ func (t *LokutorTTS) StreamSynthesize(ctx context.Context, text string, voice or
 
 		switch messageType {
 		case websocket.MessageBinary:
-
 			if err := onChunk(payload); err != nil {
 				return err
 			}
 		case websocket.MessageText:
 			msg := string(payload)
 			if msg == "EOS" {
-				return nil
+				return flush()
 			}
 			if len(msg) >= 4 && msg[:4] == "ERR:" {
 				return fmt.Errorf("lokutor error: %s", msg)
 			}
+			if visemes && strings.HasPrefix(msg, "{") {
+				if err := reportMark(payload, onMark); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// reportMark passes a viseme or word boundary message to onMark. Messages it
+// doesn't understand are ignored.
+func reportMark(payload []byte, onMark func(orchestrator.SpeechMark) error) error {
+	var m lokutorMark
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return nil
+	}
+	mark := orchestrator.SpeechMark{
+		Offset:   time.Duration(m.OffsetMs * float64(time.Millisecond)),
+		Duration: time.Duration(m.DurationMs * float64(time.Millisecond)),
+	}
+	switch {
+	case m.Type == "viseme" && m.Viseme != "":
+		mark.Viseme = m.Viseme
+	case m.Type == "word" && m.Word != "":
+		mark.Word = m.Word
+	default:
+		return nil
+	}
+	return onMark(mark)
+}
+
+// streamHTTP synthesizes over a plain HTTP(S) request, for networks where
+// the WebSocket dial fails. Audio is delivered as the chunked response
+// arrives.
+func (t *LokutorTTS) streamHTTP(ctx context.Context, req map[string]interface{}, onChunk func([]byte) error) error {
+	apiKey, err := t.keyRef.Resolve(ctx, t.apiKey)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	scheme := "https"
+	if t.scheme == "ws" {
+		scheme = "http"
+	}
+	path := t.httpPath
+	if path == "" {
+		path = "/synthesize"
+	}
+	u := url.URL{Scheme: scheme, Host: t.host, Path: path, RawQuery: "api_key=" + apiKey}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range t.header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to connect to lokutor over http: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("lokutor error (status %d): %s", resp.StatusCode, respBody)
+	}
+
+	buf := make([]byte, 8192)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if err := onChunk(bytes.Clone(buf[:n])); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read from lokutor: %w", err)
+		}
+	}
+}
+
+// pcmChunks wraps onChunk to deliver whole samples at the configured sample
+// rate. flush delivers what the resampler holds back once the synthesis is
+// complete. It must be called with t.mu held.
+func (t *LokutorTTS) pcmChunks(onChunk func([]byte) error) (chunk func([]byte) error, flush func() error) {
+	var rs *resample.Resampler
+	if t.sampleRate > 0 && t.sampleRate != lokutorSampleRate {
+		rs = resample.NewResampler(lokutorSampleRate, t.sampleRate)
+	}
+	var carry []byte
+	chunk = func(payload []byte) error {
+		// Chunks may split a sample; hold the odd byte for the next one.
+		if len(carry) > 0 || len(payload)%2 == 1 {
+			payload = append(carry, payload...)
+			carry = nil
+			if len(payload)%2 == 1 {
+				carry = []byte{payload[len(payload)-1]}
+				payload = payload[:len(payload)-1]
+			}
+		}
+		if rs != nil {
+			payload = rs.Process(payload)
+		}
+		if len(payload) == 0 {
+			return nil
+		}
+		return onChunk(payload)
+	}
+	flush = func() error {
+		if rs == nil {
+			return nil
 		}
+		if tail := rs.Flush(); len(tail) > 0 {
+			return onChunk(tail)
+		}
+		return nil
+	}
+	return chunk, flush
+}
+
+// HealthCheck opens the WebSocket, which the next synthesis then reuses.
+// Behind a proxy that only lets the HTTP fallback through, it checks nothing.
+func (t *LokutorTTS) HealthCheck(ctx context.Context) error {
+	t.mu.Lock()
+	httpOnly := time.Now().Before(t.wsRetryAt)
+	t.mu.Unlock()
+	if httpOnly {
+		return nil
 	}
+	_, err := t.getConn(ctx)
+	return err
 }
 
 func (t *LokutorTTS) Name() string {
@@ -2,10 +2,13 @@ package tts
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
@@ -57,3 +60,344 @@ func TestLokutorTTS(t *testing.T) {
 
 	tts.Close()
 }
+
+func TestLokutorTTS_Resamples(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "closing")
+
+		var req map[string]interface{}
+		if err := wsjson.Read(r.Context(), conn, &req); err != nil {
+			return
+		}
+		// 100ms at 44.1kHz, split mid-sample.
+		conn.Write(r.Context(), websocket.MessageBinary, make([]byte, 1001))
+		conn.Write(r.Context(), websocket.MessageBinary, make([]byte, 7819))
+		conn.Write(r.Context(), websocket.MessageText, []byte("EOS"))
+	}))
+	defer server.Close()
+
+	tts := &LokutorTTS{
+		apiKey: "test-key",
+		host:   strings.TrimPrefix(server.URL, "http://"),
+		scheme: "ws",
+	}
+	tts.SetSampleRate(48000)
+	defer tts.Close()
+
+	var n int
+	err := tts.StreamSynthesize(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn, func(chunk []byte) error {
+		if len(chunk)%2 != 0 {
+			t.Errorf("chunk of %d bytes splits a sample", len(chunk))
+		}
+		n += len(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n < 9590 || n > 9610 {
+		t.Errorf("expected about 100ms at 48kHz (9600 bytes), got %d", n)
+	}
+}
+
+func TestLokutorTTS_HTTPFallback(t *testing.T) {
+	var dials int
+	var req map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws":
+			// A proxy that refuses the upgrade.
+			dials++
+			w.WriteHeader(http.StatusForbidden)
+		case "/synthesize":
+			if r.URL.Query().Get("api_key") != "test-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			w.Write([]byte{1, 2, 3})
+			w.(http.Flusher).Flush()
+			w.Write([]byte{4, 5, 6})
+		}
+	}))
+	defer server.Close()
+
+	tts := &LokutorTTS{
+		apiKey: "test-key",
+		host:   strings.TrimPrefix(server.URL, "http://"),
+		scheme: "ws",
+	}
+	for i := 0; i < 2; i++ {
+		var audio []byte
+		err := tts.StreamSynthesize(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn, func(chunk []byte) error {
+			if len(chunk)%2 != 0 {
+				t.Errorf("chunk of %d bytes splits a sample", len(chunk))
+			}
+			audio = append(audio, chunk...)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(audio) != 6 {
+			t.Errorf("expected 6 bytes, got %d", len(audio))
+		}
+	}
+	if req["text"] != "hello" || req["voice"] != string(orchestrator.VoiceF1) {
+		t.Errorf("unexpected request %v", req)
+	}
+	if dials != 1 {
+		t.Errorf("expected the WebSocket to be tried once, got %d dials", dials)
+	}
+
+	// Once the backoff has passed the WebSocket is tried again, and another
+	// failure waits longer.
+	tts.wsRetryAt = time.Now().Add(-time.Second)
+	if _, err := tts.Synthesize(context.Background(), "again", orchestrator.VoiceF1, orchestrator.LanguageEn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dials != 2 {
+		t.Errorf("expected the WebSocket to be retried, got %d dials", dials)
+	}
+	if tts.wsBackoff != 2*wsRetryMin || !tts.wsRetryAt.After(time.Now()) {
+		t.Errorf("expected the backoff to double, got %v", tts.wsBackoff)
+	}
+}
+
+func TestLokutorTTS_Options(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/lokutor/stream":
+			conn, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close(websocket.StatusNormalClosure, "closing")
+			var req map[string]interface{}
+			if err := wsjson.Read(r.Context(), conn, &req); err != nil {
+				return
+			}
+			conn.Write(r.Context(), websocket.MessageBinary, []byte{1, 2})
+			conn.Write(r.Context(), websocket.MessageText, []byte("EOS"))
+		case "/lokutor/http":
+			w.Write([]byte{1, 2, 3, 4})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	opts := LokutorOptions{
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Scheme:   "ws",
+		Path:     "/lokutor/stream",
+		HTTPPath: "/lokutor/http",
+		Header:   http.Header{"X-Tenant": {"acme"}},
+	}
+	audio, err := NewLokutorTTSWithOptions("test-key", opts).Synthesize(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn)
+	if err != nil || len(audio) != 2 {
+		t.Fatalf("websocket synthesis = %v, %v", audio, err)
+	}
+
+	opts.Path = "/blocked"
+	audio, err = NewLokutorTTSWithOptions("test-key", opts).Synthesize(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn)
+	if err != nil || len(audio) != 4 {
+		t.Fatalf("http synthesis = %v, %v", audio, err)
+	}
+
+	want := []string{"/lokutor/stream", "/blocked", "/lokutor/http"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("expected requests to %v, got %v", want, paths)
+	}
+}
+
+func FuzzLokutorTTS_PCMChunks(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 4, 5}, uint8(3), false)
+	f.Add([]byte{1}, uint8(0), true)
+	f.Add(make([]byte, 1001), uint8(7), true)
+
+	f.Fuzz(func(t *testing.T, audio []byte, split uint8, resample bool) {
+		tts := NewLokutorTTS("test-key")
+		if resample {
+			tts.SetSampleRate(16000)
+		}
+		var out []byte
+		onChunk, flush := tts.pcmChunks(func(chunk []byte) error {
+			if len(chunk)%2 != 0 {
+				t.Fatalf("delivered a %d-byte chunk", len(chunk))
+			}
+			out = append(out, chunk...)
+			return nil
+		})
+		// The server's frames may split samples anywhere.
+		n := min(int(split), len(audio))
+		onChunk(audio[:n])
+		onChunk(audio[n:])
+		flush()
+		if !resample && string(out) != string(audio[:len(audio)&^1]) {
+			t.Errorf("got %d bytes back from %d", len(out), len(audio))
+		}
+	})
+}
+
+func TestLokutorTTS_Prosody(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "closing")
+		for {
+			var req map[string]interface{}
+			if err := wsjson.Read(r.Context(), conn, &req); err != nil {
+				return
+			}
+			mu.Lock()
+			requests = append(requests, req)
+			mu.Unlock()
+			conn.Write(r.Context(), websocket.MessageBinary, []byte{1, 2})
+			conn.Write(r.Context(), websocket.MessageText, []byte("EOS"))
+		}
+	}))
+	defer server.Close()
+
+	tts := &LokutorTTS{apiKey: "test-key", host: strings.TrimPrefix(server.URL, "http://"), scheme: "ws", sampleRate: lokutorSampleRate}
+	defer tts.Close()
+
+	segments := orchestrator.ParseProsody("Let me see. [pause 100ms] It's *four* [slow]forty five[/slow].")
+	var audio []byte
+	err := tts.StreamSynthesizeProsody(context.Background(), segments, orchestrator.VoiceF1, orchestrator.LanguageEn, func(chunk []byte) error {
+		audio = append(audio, chunk...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []struct {
+		text  string
+		speed float64
+	}{{"Let me see.", 1}, {"It's four", 1}, {"forty five", 0.85}}
+	if len(requests) != len(want) {
+		t.Fatalf("expected %d requests, got %v", len(want), requests)
+	}
+	for i, w := range want {
+		if requests[i]["text"] != w.text || requests[i]["speed"] != w.speed {
+			t.Errorf("request %d: got %v, want %q at %v", i, requests[i], w.text, w.speed)
+		}
+	}
+	if silence := 4410 * 2; len(audio) != silence+len(want)*2 {
+		t.Errorf("expected %d bytes of speech and silence, got %d", silence+len(want)*2, len(audio))
+	}
+}
+
+func TestLokutorTTS_Visemes(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		visemes []interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "closing")
+		for {
+			var req map[string]interface{}
+			if err := wsjson.Read(r.Context(), conn, &req); err != nil {
+				return
+			}
+			mu.Lock()
+			visemes = append(visemes, req["visemes"])
+			mu.Unlock()
+			if req["visemes"] == true {
+				conn.Write(r.Context(), websocket.MessageText, []byte(`{"type":"word","word":"hello","offset_ms":0,"duration_ms":320.5}`))
+				conn.Write(r.Context(), websocket.MessageText, []byte(`{"type":"viseme","viseme":"HH","offset_ms":12}`))
+				conn.Write(r.Context(), websocket.MessageText, []byte(`{"type":"phoneme","phoneme":"h"}`))
+			}
+			conn.Write(r.Context(), websocket.MessageBinary, []byte{1, 2})
+			conn.Write(r.Context(), websocket.MessageText, []byte("EOS"))
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	tts := NewLokutorTTSWithOptions("test-key", LokutorOptions{Host: host, Scheme: "ws", Visemes: true})
+	defer tts.Close()
+
+	var marks []orchestrator.SpeechMark
+	var audio []byte
+	err := tts.StreamSynthesizeMarks(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn, func(chunk []byte) error {
+		audio = append(audio, chunk...)
+		return nil
+	}, func(m orchestrator.SpeechMark) error {
+		marks = append(marks, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []orchestrator.SpeechMark{
+		{Word: "hello", Duration: 320500 * time.Microsecond},
+		{Viseme: "HH", Offset: 12 * time.Millisecond},
+	}
+	if len(marks) != len(want) || marks[0] != want[0] || marks[1] != want[1] {
+		t.Errorf("got marks %+v, want %+v", marks, want)
+	}
+	if len(audio) != 2 {
+		t.Errorf("expected 2 bytes of audio, got %d", len(audio))
+	}
+
+	// Plain synthesis doesn't ask for visemes.
+	if err := tts.StreamSynthesize(context.Background(), "hello", orchestrator.VoiceF1, orchestrator.LanguageEn, func([]byte) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(visemes) != 2 || visemes[0] != true || visemes[1] != false {
+		t.Errorf("unexpected visemes flags %v", visemes)
+	}
+}
+
+func TestLokutorTTS_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Read(r.Context())
+	}))
+	defer server.Close()
+
+	tts := NewLokutorTTSWithOptions("test-key", LokutorOptions{Host: strings.TrimPrefix(server.URL, "http://"), Scheme: "ws"})
+	var _ orchestrator.HealthChecker = tts
+	if err := tts.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tts.Close()
+
+	tts = NewLokutorTTSWithOptions("revoked", LokutorOptions{Host: strings.TrimPrefix(server.URL, "http://"), Scheme: "ws"})
+	if err := tts.HealthCheck(context.Background()); err == nil {
+		t.Error("expected a rejected key to fail the check")
+	}
+}
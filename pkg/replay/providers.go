@@ -0,0 +1,90 @@
+package replay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// ScriptedSTT answers each Transcribe call with the next of its transcripts,
+// then with empty text. NewScriptedSTT scripts the bundle's final
+// transcripts, so a replay doesn't depend on a live STT provider.
+type ScriptedSTT struct {
+	mu          sync.Mutex
+	transcripts []string
+}
+
+func NewScriptedSTT(b *Bundle) *ScriptedSTT {
+	return &ScriptedSTT{transcripts: b.Texts(orchestrator.TranscriptFinal)}
+}
+
+func (s *ScriptedSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.transcripts) == 0 {
+		return "", nil
+	}
+	text := s.transcripts[0]
+	s.transcripts = s.transcripts[1:]
+	return text, nil
+}
+
+func (s *ScriptedSTT) Name() string { return "replay" }
+
+// ScriptedLLM replies with the bundle's recorded responses in order, then
+// repeats the last one.
+type ScriptedLLM struct {
+	mu        sync.Mutex
+	responses []string
+}
+
+func NewScriptedLLM(b *Bundle) *ScriptedLLM {
+	return &ScriptedLLM{responses: b.Texts(orchestrator.BotResponse)}
+}
+
+func (l *ScriptedLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.responses) == 0 {
+		return "", nil
+	}
+	text := l.responses[0]
+	if len(l.responses) > 1 {
+		l.responses = l.responses[1:]
+	}
+	return text, nil
+}
+
+func (l *ScriptedLLM) Name() string { return "replay" }
+
+// SilentTTS synthesizes silence, 60ms per word of text at SampleRate
+// (44.1kHz when zero), so replies take roughly as long as spoken ones.
+type SilentTTS struct {
+	SampleRate int
+}
+
+func (s SilentTTS) pcm(text string) []byte {
+	rate := s.SampleRate
+	if rate <= 0 {
+		rate = 44100
+	}
+	words := 1
+	for i := 1; i < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' {
+			words++
+		}
+	}
+	return make([]byte, words*rate*60/1000*2)
+}
+
+func (s SilentTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return s.pcm(text), nil
+}
+
+func (s SilentTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(s.pcm(text))
+}
+
+func (s SilentTTS) Abort() error { return nil }
+func (s SilentTTS) Name() string { return "replay" }
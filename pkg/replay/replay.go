@@ -0,0 +1,309 @@
+// Package replay plays recorded sessions back through a ManagedStream, so
+// VAD, echo and barge-in behaviour can be regression-tested against real
+// calls.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Bundle is a recording as written by orchestrator.Recorder, with its
+// segments joined. Timeline offsets are from the start of the recording.
+type Bundle struct {
+	Inbound      []byte
+	InboundRate  int
+	Outbound     []byte
+	OutboundRate int
+	Timeline     []orchestrator.RecordingEntry
+}
+
+// LoadDir reads the bundle in dir, the directory holding the numbered
+// segment directories. Sealed files are opened with dec, which may be nil
+// for plaintext recordings.
+func LoadDir(dir string, dec orchestrator.Encryptor) (*Bundle, error) {
+	return Load(os.DirFS(dir), ".", dec)
+}
+
+// Load reads the bundle under dir in fsys.
+func Load(fsys fs.FS, dir string, dec orchestrator.Encryptor) (*Bundle, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, e := range entries {
+		if e.IsDir() {
+			segments = append(segments, e.Name())
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("replay: no segments in %s", dir)
+	}
+	sort.Strings(segments)
+
+	b := &Bundle{}
+	var start time.Time
+	for _, seg := range segments {
+		read := func(name string) ([]byte, error) {
+			data, err := fs.ReadFile(fsys, path.Join(dir, seg, name))
+			if err != nil {
+				return nil, err
+			}
+			return orchestrator.OpenIfConfigured(context.Background(), dec, data)
+		}
+
+		if data, err := read("inbound.wav"); err == nil {
+			pcm, rate, err := audio.DecodeWav(data)
+			if err != nil {
+				return nil, fmt.Errorf("replay: %s/inbound.wav: %w", seg, err)
+			}
+			b.Inbound, b.InboundRate = append(b.Inbound, pcm...), rate
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if data, err := read("outbound.wav"); err == nil {
+			pcm, rate, err := audio.DecodeWav(data)
+			if err != nil {
+				return nil, fmt.Errorf("replay: %s/outbound.wav: %w", seg, err)
+			}
+			b.Outbound, b.OutboundRate = append(b.Outbound, pcm...), rate
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		data, err := read("timeline.jsonl")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var e orchestrator.RecordingEntry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				return nil, fmt.Errorf("replay: %s/timeline.jsonl: %w", seg, err)
+			}
+			// Segments restart their offsets; rebase on the recording's start.
+			if start.IsZero() {
+				start = e.At.Add(-time.Duration(e.Offset) * time.Millisecond)
+			}
+			e.Offset = e.At.Sub(start).Milliseconds()
+			b.Timeline = append(b.Timeline, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Events returns the recorded event types, in order.
+func (b *Bundle) Events() []orchestrator.EventType {
+	types := make([]orchestrator.EventType, 0, len(b.Timeline))
+	for _, e := range b.Timeline {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// Texts returns the string payloads of the recorded events of type t, such
+// as every final transcript.
+func (b *Bundle) Texts(t orchestrator.EventType) []string {
+	var out []string
+	for _, e := range b.Timeline {
+		if s, ok := e.Data.(string); ok && e.Type == t {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Generations returns the LLM parameters recorded with each reply, in
+// order. Replies recorded without parameters get zero ones, so the list
+// lines up with Texts(BotResponse).
+func (b *Bundle) Generations() []orchestrator.GenerationParams {
+	var out []orchestrator.GenerationParams
+	for _, e := range b.Timeline {
+		if e.Type != orchestrator.BotResponse {
+			continue
+		}
+		var params orchestrator.GenerationParams
+		if e.Generation != nil {
+			params = *e.Generation
+		}
+		out = append(out, params)
+	}
+	return out
+}
+
+type Options struct {
+	// Session defaults to a new session named "replay".
+	Session *orchestrator.ConversationSession
+	// Frame is the size of the chunks written to the stream, 20ms by default.
+	Frame time.Duration
+	// Speed scales the original timing; 1 (the default) replays in real
+	// time. VAD and barge-in decisions depend on wall-clock time, so other
+	// speeds only suit coarse checks.
+	Speed float64
+	// Settle is how long to keep collecting events after the last frame,
+	// 2s by default.
+	Settle time.Duration
+	// SkipOutbound stops the recorded outbound audio from being fed to the
+	// stream as the echo reference.
+	SkipOutbound bool
+	// NewSeeds generates replies with fresh parameters instead of the
+	// recorded ones (see Bundle.Generations).
+	NewSeeds bool
+}
+
+// Result holds what the stream emitted during a replay. Audio chunks are
+// left out.
+type Result struct {
+	Events []orchestrator.OrchestratorEvent
+}
+
+// Types returns the emitted event types, in order.
+func (r *Result) Types() []orchestrator.EventType {
+	types := make([]orchestrator.EventType, 0, len(r.Events))
+	for _, ev := range r.Events {
+		types = append(types, ev.Type)
+	}
+	return types
+}
+
+// Match reports whether want occurs in the emitted events in order, other
+// events allowed in between.
+func (r *Result) Match(want ...orchestrator.EventType) error {
+	got := r.Types()
+	i := 0
+	for _, t := range got {
+		if i < len(want) && t == want[i] {
+			i++
+		}
+	}
+	if i < len(want) {
+		return fmt.Errorf("replay: missing %s after %v in %v", want[i], want[:i], got)
+	}
+	return nil
+}
+
+// Run writes the bundle's inbound audio to a new ManagedStream of orch with
+// its original timing and collects the events the stream emits. The
+// recorded outbound audio is played into the echo reference at the same
+// offsets, as the caller's speaker did.
+func Run(ctx context.Context, orch *orchestrator.Orchestrator, b *Bundle, opts Options) (*Result, error) {
+	if b.InboundRate <= 0 {
+		return nil, fmt.Errorf("replay: bundle has no inbound audio")
+	}
+	frame := opts.Frame
+	if frame <= 0 {
+		frame = 20 * time.Millisecond
+	}
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	settle := opts.Settle
+	if settle <= 0 {
+		settle = 2 * time.Second
+	}
+	session := opts.Session
+	if session == nil {
+		session = orchestrator.NewConversationSession("replay")
+	}
+	if !opts.NewSeeds {
+		session.ReplayGenerations(b.Generations()...)
+	}
+
+	ms := orch.NewManagedStream(ctx, session)
+	defer ms.Close()
+	outboundRate := b.OutboundRate
+	if outboundRate <= 0 {
+		outboundRate = b.InboundRate
+	}
+	ms.SetEchoSampleRates(outboundRate, b.InboundRate)
+
+	var (
+		mu     sync.Mutex
+		events []orchestrator.OrchestratorEvent
+	)
+	go func() {
+		for ev := range ms.Events() {
+			if ev.Type == orchestrator.AudioChunk {
+				continue
+			}
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}
+	}()
+
+	inStep := frameBytes(frame, b.InboundRate)
+	outStep := frameBytes(frame, outboundRate)
+	start := time.Now()
+	for i, o := 0, 0; i < len(b.Inbound); i, o = i+inStep, o+outStep {
+		// The outbound track is padded with silence between replies; only
+		// frames that were actually played count as playback.
+		if played := outboundFrame(b.Outbound, o, outStep); !opts.SkipOutbound && played != nil {
+			ms.RecordPlayedOutput(played)
+			ms.NotifyAudioPlayed()
+		}
+		if err := ms.Write(b.Inbound[i:min(i+inStep, len(b.Inbound))]); err != nil {
+			return nil, err
+		}
+		// Pace against the start rather than per frame so sleeps don't drift.
+		next := start.Add(time.Duration(float64(time.Duration(i/inStep+1)*frame) / speed))
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case <-time.After(settle):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// Taken before Close, which interrupts whatever is still playing.
+	mu.Lock()
+	defer mu.Unlock()
+	return &Result{Events: append([]orchestrator.OrchestratorEvent(nil), events...)}, nil
+}
+
+func frameBytes(frame time.Duration, rate int) int {
+	n := int(frame.Seconds()*float64(rate)) * 2
+	if n < 2 {
+		return 2
+	}
+	return n
+}
+
+func outboundFrame(pcm []byte, offset, n int) []byte {
+	if offset >= len(pcm) {
+		return nil
+	}
+	frame := pcm[offset:min(offset+n, len(pcm))]
+	for _, v := range frame {
+		if v != 0 {
+			return frame
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/store"
+)
+
+const rate = 16000
+
+func tone(d time.Duration, amp float64) []byte {
+	n := int(d.Seconds() * rate)
+	pcm := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		v := int16(amp * math.Sin(2*math.Pi*440*float64(i)/rate))
+		pcm[2*i] = byte(v)
+		pcm[2*i+1] = byte(v >> 8)
+	}
+	return pcm
+}
+
+// recordBundle writes a one-utterance call with Recorder and returns the
+// bundle directory.
+func recordBundle(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	storage, err := store.NewRecordingDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := orchestrator.NewRecorder(orchestrator.NewConversationSession("call"), rate, orchestrator.RecordingConfig{Storage: storage})
+	rec.WriteInbound(tone(300*time.Millisecond, 0))
+	rec.WriteInbound(tone(600*time.Millisecond, 8000))
+	rec.RecordEvent(orchestrator.OrchestratorEvent{Type: orchestrator.TranscriptFinal, Data: "what are your hours"})
+	rec.RecordEvent(orchestrator.OrchestratorEvent{Type: orchestrator.BotResponse, Data: "We open at nine."})
+	rec.WriteInbound(tone(1200*time.Millisecond, 0))
+	rec.Close()
+	if err := rec.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	starts, err := os.ReadDir(filepath.Join(dir, "call"))
+	if err != nil || len(starts) != 1 {
+		t.Fatalf("expected one bundle, got %v %v", starts, err)
+	}
+	return filepath.Join(dir, "call", starts[0].Name())
+}
+
+func TestReplay_RecordedCall(t *testing.T) {
+	b, err := LoadDir(recordBundle(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.InboundRate != rate || len(b.Inbound) != 2*rate*21/10 {
+		t.Fatalf("inbound: %d bytes at %dHz", len(b.Inbound), b.InboundRate)
+	}
+	if got := b.Texts(orchestrator.TranscriptFinal); len(got) != 1 || got[0] != "what are your hours" {
+		t.Fatalf("transcripts = %v", got)
+	}
+
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	cfg.SampleRate = rate
+	orch := orchestrator.NewWithVAD(NewScriptedSTT(b), NewScriptedLLM(b), SilentTTS{SampleRate: rate}, orchestrator.NewRMSVAD(0.02, 300*time.Millisecond), cfg)
+
+	result, err := Run(context.Background(), orch, b, Options{Settle: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Match(orchestrator.UserSpeaking, orchestrator.UserStopped, orchestrator.TranscriptFinal, orchestrator.BotResponse, orchestrator.BotSpeaking); err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range result.Events {
+		if ev.Type == orchestrator.BotResponse && ev.Data != "We open at nine." {
+			t.Errorf("unexpected response %v", ev.Data)
+		}
+		if ev.Type == orchestrator.Interrupted {
+			t.Errorf("a single utterance must not interrupt: %v", result.Types())
+		}
+	}
+}
+
+// paramsLLM replies with the bundle's responses and keeps the parameters
+// it was called with.
+type paramsLLM struct {
+	*ScriptedLLM
+	params []orchestrator.GenerationParams
+}
+
+func (l *paramsLLM) CompleteWithParams(ctx context.Context, messages []orchestrator.Message, params orchestrator.GenerationParams) (string, error) {
+	l.params = append(l.params, params)
+	return l.Complete(ctx, messages)
+}
+
+func TestReplay_RecordedSeeds(t *testing.T) {
+	b, err := LoadDir(recordBundle(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range b.Timeline {
+		if b.Timeline[i].Type == orchestrator.BotResponse {
+			b.Timeline[i].Generation = &orchestrator.GenerationParams{Seed: orchestrator.Int64(42)}
+		}
+	}
+	if got := b.Generations(); len(got) != 1 || *got[0].Seed != 42 {
+		t.Fatalf("generations = %+v", got)
+	}
+
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	cfg.SampleRate = rate
+	llm := &paramsLLM{ScriptedLLM: NewScriptedLLM(b)}
+	orch := orchestrator.NewWithVAD(NewScriptedSTT(b), llm, SilentTTS{SampleRate: rate}, orchestrator.NewRMSVAD(0.02, 300*time.Millisecond), cfg)
+	if _, err := Run(context.Background(), orch, b, Options{Settle: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.params) != 1 || llm.params[0].Seed == nil || *llm.params[0].Seed != 42 {
+		t.Errorf("expected the reply to be generated with the recorded seed, got %+v", llm.params)
+	}
+}
+
+func TestResult_Match(t *testing.T) {
+	r := &Result{Events: []orchestrator.OrchestratorEvent{
+		{Type: orchestrator.UserSpeaking}, {Type: orchestrator.UserStopped}, {Type: orchestrator.BotResponse},
+	}}
+	if err := r.Match(orchestrator.UserSpeaking, orchestrator.BotResponse); err != nil {
+		t.Error(err)
+	}
+	if err := r.Match(orchestrator.BotResponse, orchestrator.UserSpeaking); err == nil {
+		t.Error("order must matter")
+	}
+}
@@ -0,0 +1,566 @@
+// Package rest exposes the orchestrator's request/response pipeline over
+// plain HTTP, for integrations that upload a recorded utterance and want the
+// transcript and spoken reply back rather than holding a live stream open.
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const ndjson = "application/x-ndjson"
+
+// Options configure a Server.
+type Options struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token        string
+	SystemPrompt string
+	// Store, when set, persists sessions after every change so they survive
+	// restarts and can be served by any replica sharing the store.
+	Store orchestrator.SessionStore
+	// MaxAudioBytes caps uploads, 25MB by default.
+	MaxAudioBytes int64
+	// IdleTimeout is how long a session stays in memory after its last
+	// request, 30 minutes by default. Sessions in Store are loaded again on
+	// their next request; without a Store they are gone.
+	IdleTimeout time.Duration
+	Logger      orchestrator.Logger
+}
+
+// Server serves session CRUD under /v1/sessions and batch turns at
+// POST /v1/sessions/{id}/audio.
+type Server struct {
+	orch *orchestrator.Orchestrator
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	sessions map[string]*entry
+}
+
+// entry serializes turns on one session: a conversation can't take two
+// utterances at once.
+type entry struct {
+	mu       sync.Mutex
+	session  *orchestrator.ConversationSession
+	lastUsed time.Time
+}
+
+func NewServer(orch *orchestrator.Orchestrator, opts Options) *Server {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.MaxAudioBytes <= 0 {
+		opts.MaxAudioBytes = 25 << 20
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 30 * time.Minute
+	}
+	s := &Server{
+		orch:     orch,
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		sessions: make(map[string]*entry),
+	}
+	s.mux.HandleFunc("POST /v1/sessions", s.createSession)
+	s.mux.HandleFunc("GET /v1/sessions", s.listSessions)
+	s.mux.HandleFunc("GET /v1/sessions/{id}", s.getSession)
+	s.mux.HandleFunc("PATCH /v1/sessions/{id}", s.updateSession)
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.deleteSession)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/audio", s.processAudio)
+	s.mux.HandleFunc("GET /v1/sessions/{id}/export", s.exportSession)
+	s.mux.HandleFunc("POST /v1/sessions/import", s.importSession)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Token != "" {
+		want := "Bearer " + s.opts.Token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+type sessionRequest struct {
+	ID           string `json:"id,omitempty"`
+	UserID       string `json:"user_id,omitempty"`
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	Voice        string `json:"voice,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Channel      string `json:"channel,omitempty"`
+}
+
+type sessionView struct {
+	ID       string                    `json:"id"`
+	Voice    orchestrator.Voice        `json:"voice"`
+	Language orchestrator.Language     `json:"language"`
+	Channel  orchestrator.Channel      `json:"channel"`
+	Messages []orchestrator.Message    `json:"messages"`
+	Usage    orchestrator.SessionUsage `json:"usage"`
+	Pace     orchestrator.PaceMetrics  `json:"pace"`
+}
+
+func view(session *orchestrator.ConversationSession) sessionView {
+	return sessionView{
+		ID:       session.ID,
+		Voice:    session.GetCurrentVoice(),
+		Language: session.GetCurrentLanguage(),
+		Channel:  session.Channel(),
+		Messages: session.GetContextCopy(),
+		Usage:    session.GetUsage(),
+		Pace:     session.GetPace(),
+	}
+}
+
+// lookup returns the session's entry, loading it from the store if this
+// instance hasn't seen it yet.
+func (s *Server) lookup(ctx context.Context, id string) (*entry, error) {
+	s.mu.Lock()
+	s.evictIdleLocked(time.Now())
+	e, ok := s.sessions[id]
+	if ok {
+		e.lastUsed = time.Now()
+	}
+	s.mu.Unlock()
+	if ok {
+		return e, nil
+	}
+	if s.opts.Store == nil {
+		return nil, orchestrator.ErrSessionNotFound
+	}
+	snap, err := s.opts.Store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.sessions[id]; ok {
+		return e, nil
+	}
+	e = &entry{session: orchestrator.RestoreSession(snap), lastUsed: time.Now()}
+	s.sessions[id] = e
+	return e, nil
+}
+
+// evictIdleLocked drops sessions nobody has used for IdleTimeout. A session
+// with a turn in progress is kept.
+func (s *Server) evictIdleLocked(now time.Time) {
+	for id, e := range s.sessions {
+		if now.Sub(e.lastUsed) < s.opts.IdleTimeout || !e.mu.TryLock() {
+			continue
+		}
+		e.mu.Unlock()
+		delete(s.sessions, id)
+	}
+}
+
+// save persists e's session unless it was deleted meanwhile. Callers hold
+// e.mu, which deleteSession waits for before deleting the stored snapshot, so
+// a turn finishing after the delete can't store the session again.
+func (s *Server) save(ctx context.Context, e *entry) {
+	if s.opts.Store == nil {
+		return
+	}
+	s.mu.Lock()
+	live := s.sessions[e.session.ID] == e
+	s.mu.Unlock()
+	if !live {
+		return
+	}
+	if err := s.opts.Store.Save(ctx, e.session.Snapshot()); err != nil {
+		s.opts.Logger.Warn("failed to persist session", "sessionID", e.session.ID, "error", err)
+	}
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var req sessionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if req.ID == "" {
+		req.ID = newSessionID()
+	}
+	if _, err := s.lookup(r.Context(), req.ID); err == nil {
+		writeError(w, http.StatusConflict, "session already exists")
+		return
+	}
+
+	session := s.orch.NewSessionWithDefaults(req.ID)
+	session.UserID = req.UserID
+	prompt := req.SystemPrompt
+	if prompt == "" {
+		prompt = s.opts.SystemPrompt
+	}
+	if prompt != "" {
+		s.orch.SetSystemPrompt(session, prompt)
+	}
+	s.applySettings(session, req)
+
+	e := &entry{session: session, lastUsed: time.Now()}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s.mu.Lock()
+	if _, ok := s.sessions[req.ID]; ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "session already exists")
+		return
+	}
+	s.sessions[req.ID] = e
+	s.mu.Unlock()
+
+	s.save(r.Context(), e)
+	writeJSON(w, http.StatusCreated, view(session))
+}
+
+func (s *Server) applySettings(session *orchestrator.ConversationSession, req sessionRequest) {
+	if req.Voice != "" {
+		s.orch.SetVoice(session, orchestrator.Voice(req.Voice))
+	}
+	if req.Language != "" {
+		s.orch.SetLanguage(session, orchestrator.Language(req.Language))
+	}
+	if req.Channel != "" {
+		session.SetChannel(orchestrator.Channel(req.Channel))
+	}
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	ids := map[string]bool{}
+	s.mu.Lock()
+	for id := range s.sessions {
+		ids[id] = true
+	}
+	s.mu.Unlock()
+	if s.opts.Store != nil {
+		stored, err := s.opts.Store.List(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, id := range stored {
+			ids[id] = true
+		}
+	}
+
+	list := make([]string, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	sort.Strings(list)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": list})
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.find(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, view(e.session))
+}
+
+func (s *Server) exportSession(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.find(w, r)
+	if !ok {
+		return
+	}
+	data, err := e.session.Export()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) importSession(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, s.opts.MaxAudioBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	session, err := orchestrator.ImportSession(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.lookup(r.Context(), session.ID); err == nil {
+		writeError(w, http.StatusConflict, "session already exists")
+		return
+	}
+	s.orch.Checkpoint(session)
+
+	e := &entry{session: session, lastUsed: time.Now()}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s.mu.Lock()
+	if _, ok := s.sessions[session.ID]; ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "session already exists")
+		return
+	}
+	s.sessions[session.ID] = e
+	s.mu.Unlock()
+
+	s.save(r.Context(), e)
+	writeJSON(w, http.StatusCreated, view(session))
+}
+
+func (s *Server) updateSession(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.find(w, r)
+	if !ok {
+		return
+	}
+	var req sessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s.applySettings(e.session, req)
+	if req.SystemPrompt != "" {
+		s.orch.SetSystemPrompt(e.session, req.SystemPrompt)
+	}
+	s.save(r.Context(), e)
+	writeJSON(w, http.StatusOK, view(e.session))
+}
+
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	e, known := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	if known {
+		// Let a turn in progress finish; it won't save the session now.
+		e.mu.Lock()
+		e.mu.Unlock()
+	}
+
+	if s.opts.Store != nil {
+		err := s.opts.Store.Delete(r.Context(), id)
+		if err == nil {
+			known = true
+		} else if !errors.Is(err, orchestrator.ErrSessionNotFound) {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if !known {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) find(w http.ResponseWriter, r *http.Request) (*entry, bool) {
+	e, err := s.lookup(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, orchestrator.ErrSessionNotFound):
+		writeError(w, http.StatusNotFound, "session not found")
+		return nil, false
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return e, true
+}
+
+type turnResponse struct {
+	Transcript string `json:"transcript"`
+	Response   string `json:"response"`
+	// Audio is a base64 WAV file of the spoken response.
+	Audio      string `json:"audio,omitempty"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+type streamFrame struct {
+	Type       string `json:"type"`
+	Transcript string `json:"transcript,omitempty"`
+	Response   string `json:"response,omitempty"`
+	Audio      string `json:"audio,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// processAudio runs one turn. The upload is a WAV file, either as the "audio"
+// field of a multipart form or as the raw request body. By default the reply
+// is JSON with the whole response as a base64 WAV; clients that accept
+// application/x-ndjson instead get the audio as base64 PCM frames while it is
+// synthesized.
+func (s *Server) processAudio(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.find(w, r)
+	if !ok {
+		return
+	}
+	wav, err := readUpload(w, r, s.opts.MaxAudioBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pcm, rate, err := audio.DecodeWav(wav)
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	outRate := s.orch.GetConfig().SampleRate
+	if rate != outRate {
+		pcm = resample.Resample(pcm, rate, outRate)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer s.save(context.WithoutCancel(r.Context()), e)
+
+	if strings.Contains(r.Header.Get("Accept"), ndjson) {
+		s.streamTurn(w, r, e.session, pcm, outRate)
+		return
+	}
+
+	var out []byte
+	transcript, err := s.orch.ProcessAudioStream(r.Context(), e.session, pcm, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		writeError(w, turnStatus(err), err.Error())
+		return
+	}
+	resp := turnResponse{
+		Transcript: transcript,
+		Response:   e.session.LastAssistant,
+		SampleRate: outRate,
+	}
+	if len(out) > 0 {
+		resp.Audio = base64.StdEncoding.EncodeToString(audio.NewWavBuffer(out, outRate))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) streamTurn(w http.ResponseWriter, r *http.Request, session *orchestrator.ConversationSession, pcm []byte, rate int) {
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	started := false
+	send := func(f streamFrame) error {
+		if !started {
+			w.Header().Set("Content-Type", ndjson)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	// The transcript and reply text are both in the session by the time the
+	// first chunk is synthesized.
+	transcript, err := s.orch.ProcessAudioStream(r.Context(), session, pcm, func(chunk []byte) error {
+		if !started {
+			if err := send(streamFrame{
+				Type:       "transcript",
+				Transcript: session.LastUser,
+				Response:   session.LastAssistant,
+				SampleRate: rate,
+			}); err != nil {
+				return err
+			}
+		}
+		return send(streamFrame{Type: "audio", Audio: base64.StdEncoding.EncodeToString(chunk)})
+	})
+	switch {
+	case err != nil && !started:
+		writeError(w, turnStatus(err), err.Error())
+	case err != nil:
+		send(streamFrame{Type: "error", Error: err.Error()})
+	default:
+		if !started {
+			send(streamFrame{Type: "transcript", Transcript: transcript, Response: session.LastAssistant, SampleRate: rate})
+		}
+		send(streamFrame{Type: "done"})
+	}
+}
+
+func turnStatus(err error) int {
+	switch {
+	case errors.Is(err, orchestrator.ErrEmptyTranscription), errors.Is(err, orchestrator.ErrGuardrailBlocked):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func readUpload(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return io.ReadAll(r.Body)
+	}
+	if err := r.ParseMultipartForm(limit); err != nil {
+		return nil, err
+	}
+	file, _, err := r.FormFile("audio")
+	if err != nil {
+		return nil, errors.New(`multipart upload needs an "audio" file field`)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "sess_" + hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,338 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/store"
+)
+
+type stubSTT struct{ text string }
+
+func (s stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return s.text, nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "you said " + messages[len(messages)-1].Content, nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return []byte{1, 2, 3, 4}, nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	if err := onChunk([]byte{1, 2}); err != nil {
+		return err
+	}
+	return onChunk([]byte{3, 4})
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+func newTestServer(t *testing.T, transcript string, opts Options) *httptest.Server {
+	orch := orchestrator.New(stubSTT{text: transcript}, stubLLM{}, stubTTS{}, orchestrator.DefaultConfig())
+	srv := httptest.NewServer(NewServer(orch, opts))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, method, url, contentType string, body []byte, header ...string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func multipartWav(t *testing.T) (string, []byte) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, _ := mw.CreateFormFile("audio", "turn.wav")
+	fw.Write(audio.NewWavBuffer(make([]byte, 1600), 16000))
+	mw.Close()
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+func TestServer_SessionLifecycle(t *testing.T) {
+	srv := newTestServer(t, "hello", Options{SystemPrompt: "be brief"})
+
+	resp := do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1","language":"es"}`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d", resp.StatusCode)
+	}
+	var created sessionView
+	json.NewDecoder(resp.Body).Decode(&created)
+	if created.ID != "s1" || created.Language != "es" || len(created.Messages) != 1 {
+		t.Errorf("unexpected session %+v", created)
+	}
+	if resp := do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`)); resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate create: %d", resp.StatusCode)
+	}
+
+	if created.Channel != orchestrator.ChannelVoice {
+		t.Errorf("sessions must default to voice, got %q", created.Channel)
+	}
+
+	resp = do(t, "PATCH", srv.URL+"/v1/sessions/s1", "application/json", []byte(`{"voice":"M1","channel":"text"}`))
+	var updated sessionView
+	json.NewDecoder(resp.Body).Decode(&updated)
+	if updated.Voice != "M1" || updated.Channel != orchestrator.ChannelText {
+		t.Errorf("voice not updated: %+v", updated)
+	}
+
+	if resp := do(t, "DELETE", srv.URL+"/v1/sessions/s1", "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: %d", resp.StatusCode)
+	}
+	if resp := do(t, "GET", srv.URL+"/v1/sessions/s1", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete: %d", resp.StatusCode)
+	}
+}
+
+func TestServer_ProcessAudio(t *testing.T) {
+	srv := newTestServer(t, "hello", Options{})
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+
+	contentType, body := multipartWav(t)
+	resp := do(t, "POST", srv.URL+"/v1/sessions/s1/audio", contentType, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var turn turnResponse
+	json.NewDecoder(resp.Body).Decode(&turn)
+	if turn.Transcript != "hello" || turn.Response != "you said hello" {
+		t.Errorf("unexpected turn %+v", turn)
+	}
+	wav, _ := base64.StdEncoding.DecodeString(turn.Audio)
+	pcm, rate, err := audio.DecodeWav(wav)
+	if err != nil || rate != turn.SampleRate || !bytes.Equal(pcm, []byte{1, 2, 3, 4}) {
+		t.Errorf("audio = %v at %d: %v", pcm, rate, err)
+	}
+
+	if resp := do(t, "POST", srv.URL+"/v1/sessions/s1/audio", "audio/wav", []byte("junk")); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("junk upload: %d", resp.StatusCode)
+	}
+	if resp := do(t, "POST", srv.URL+"/v1/sessions/nope/audio", contentType, body); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session: %d", resp.StatusCode)
+	}
+}
+
+func TestServer_ProcessAudioStreaming(t *testing.T) {
+	srv := newTestServer(t, "hello", Options{})
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+
+	wav := audio.NewWavBuffer(make([]byte, 1600), 44100)
+	resp := do(t, "POST", srv.URL+"/v1/sessions/s1/audio", "audio/wav", wav, "Accept", ndjson)
+	if ct := resp.Header.Get("Content-Type"); ct != ndjson {
+		t.Fatalf("content type %q", ct)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var f streamFrame
+		json.Unmarshal(scanner.Bytes(), &f)
+		types = append(types, f.Type)
+		if f.Type == "transcript" && f.Transcript != "hello" {
+			t.Errorf("transcript frame %+v", f)
+		}
+	}
+	if got := strings.Join(types, ","); got != "transcript,audio,audio,done" {
+		t.Errorf("frames = %s", got)
+	}
+}
+
+func TestServer_EmptyTranscript(t *testing.T) {
+	srv := newTestServer(t, "  ", Options{})
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+
+	resp := do(t, "POST", srv.URL+"/v1/sessions/s1/audio", "audio/wav", audio.NewWavBuffer(make([]byte, 160), 8000))
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_StoreBackedSessions(t *testing.T) {
+	fs, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := newTestServer(t, "hello", Options{Store: fs})
+	do(t, "POST", first.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+	do(t, "POST", first.URL+"/v1/sessions/s1/audio", "audio/wav", audio.NewWavBuffer(make([]byte, 160), 8000))
+
+	// A second instance sharing the store picks the conversation up.
+	second := newTestServer(t, "hello", Options{Store: fs})
+	resp := do(t, "GET", second.URL+"/v1/sessions/s1", "", nil)
+	var got sessionView
+	json.NewDecoder(resp.Body).Decode(&got)
+	if len(got.Messages) != 2 || got.Messages[1].Content != "you said hello" {
+		t.Errorf("session not restored from store: %+v", got)
+	}
+
+	resp = do(t, "GET", second.URL+"/v1/sessions", "", nil)
+	var list struct{ Sessions []string }
+	json.NewDecoder(resp.Body).Decode(&list)
+	if len(list.Sessions) != 1 || list.Sessions[0] != "s1" {
+		t.Errorf("list = %v", list.Sessions)
+	}
+}
+
+func TestServer_EvictsIdleSessions(t *testing.T) {
+	srv := newTestServer(t, "hello", Options{IdleTimeout: 20 * time.Millisecond})
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+	if resp := do(t, "GET", srv.URL+"/v1/sessions/s1", "", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if resp := do(t, "GET", srv.URL+"/v1/sessions/s1", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the idle session to be evicted, got status %d", resp.StatusCode)
+	}
+
+	// Stored sessions come back from the store.
+	fs, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv = newTestServer(t, "hello", Options{Store: fs, IdleTimeout: 20 * time.Millisecond})
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+	time.Sleep(50 * time.Millisecond)
+	if resp := do(t, "GET", srv.URL+"/v1/sessions/s1", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the session to be loaded again, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	srv := newTestServer(t, "hello", Options{Token: "secret"})
+	if resp := do(t, "GET", srv.URL+"/v1/sessions", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %d", resp.StatusCode)
+	}
+	if resp := do(t, "GET", srv.URL+"/v1/sessions", "", nil, "Authorization", "Bearer secret"); resp.StatusCode != http.StatusOK {
+		t.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_ExportImport(t *testing.T) {
+	first := newTestServer(t, "hello", Options{})
+	do(t, "POST", first.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+	ct, body := multipartWav(t)
+	do(t, "POST", first.URL+"/v1/sessions/s1/audio", ct, body)
+
+	resp := do(t, "GET", first.URL+"/v1/sessions/s1/export", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export: %d", resp.StatusCode)
+	}
+	exported, _ := io.ReadAll(resp.Body)
+	var doc orchestrator.SessionExport
+	if err := json.Unmarshal(exported, &doc); err != nil || doc.Version != 1 || len(doc.Session.Transcript) != 2 {
+		t.Fatalf("unexpected export %s (%v)", exported, err)
+	}
+
+	second := newTestServer(t, "hello", Options{})
+	if resp := do(t, "POST", second.URL+"/v1/sessions/import", "application/json", exported); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("import: %d", resp.StatusCode)
+	}
+	if resp := do(t, "POST", second.URL+"/v1/sessions/import", "application/json", exported); resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate import: %d", resp.StatusCode)
+	}
+	resp = do(t, "GET", second.URL+"/v1/sessions/s1", "", nil)
+	var got sessionView
+	json.NewDecoder(resp.Body).Decode(&got)
+	if len(got.Messages) != 2 {
+		t.Errorf("expected imported conversation, got %+v", got)
+	}
+	if resp := do(t, "POST", second.URL+"/v1/sessions/import", "application/json", []byte(`{"version":99,"session":{"id":"x"}}`)); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unsupported version: %d", resp.StatusCode)
+	}
+}
+
+type blockingLLM struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (l blockingLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	close(l.started)
+	<-l.release
+	return "too late", nil
+}
+func (blockingLLM) Name() string { return "blocking-llm" }
+
+func TestServer_DeleteDuringTurn(t *testing.T) {
+	fs, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := blockingLLM{started: make(chan struct{}), release: make(chan struct{})}
+	orch := orchestrator.New(stubSTT{text: "hello"}, llm, stubTTS{}, orchestrator.DefaultConfig())
+	rs := NewServer(orch, Options{Store: fs})
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+	do(t, "POST", srv.URL+"/v1/sessions", "application/json", []byte(`{"id":"s1"}`))
+
+	turn := make(chan struct{})
+	go func() {
+		defer close(turn)
+		req, _ := http.NewRequest("POST", srv.URL+"/v1/sessions/s1/audio", bytes.NewReader(audio.NewWavBuffer(make([]byte, 160), 8000)))
+		req.Header.Set("Content-Type", "audio/wav")
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-llm.started
+
+	deleted := make(chan int)
+	go func() {
+		req, _ := http.NewRequest("DELETE", srv.URL+"/v1/sessions/s1", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			deleted <- 0
+			return
+		}
+		resp.Body.Close()
+		deleted <- resp.StatusCode
+	}()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		rs.mu.Lock()
+		_, live := rs.sessions["s1"]
+		rs.mu.Unlock()
+		if !live {
+			break
+		}
+	}
+	close(llm.release)
+	<-turn
+
+	if status := <-deleted; status != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", status)
+	}
+	if _, err := fs.Load(context.Background(), "s1"); err != orchestrator.ErrSessionNotFound {
+		t.Errorf("expected the deleted session to stay deleted, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/awsv4"
+)
+
+// AWSSecretsManagerProvider calls GetSecretValue directly over HTTPS. Names are
+// either a secret id, or "secret-id#field" for JSON secrets.
+type AWSSecretsManagerProvider struct {
+	region   string
+	endpoint string
+	creds    awsv4.Credentials
+	client   *http.Client
+}
+
+func NewAWSSecretsManagerProvider(region string) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{
+		region:   region,
+		endpoint: "https://secretsmanager." + region + ".amazonaws.com/",
+		creds:    awsv4.CredentialsFromEnv(),
+		client:   http.DefaultClient,
+	}
+}
+
+func (p *AWSSecretsManagerProvider) SetCredentials(accessKeyID, secretAccessKey, sessionToken string) {
+	p.creds = awsv4.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}
+}
+
+func (p *AWSSecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	id, field := name, ""
+	if i := strings.Index(name, "#"); i >= 0 {
+		id, field = name[:i], name[i+1:]
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if err := awsv4.Sign(req, p.creds, p.region, "secretsmanager", time.Now()); err != nil {
+		return "", err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(respBody), "ResourceNotFoundException") {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", fmt.Errorf("aws secrets manager error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		if result.SecretString == "" {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return result.SecretString, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(result.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+	v, ok := fields[field].(string)
+	if !ok || v == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+func (p *AWSSecretsManagerProvider) Name() string {
+	return "aws-secrets-manager"
+}
@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.SecretId {
+		case "plain":
+			w.Write([]byte(`{"SecretString":"raw-value"}`))
+		case "bundle":
+			w.Write([]byte(`{"SecretString":"{\"LOKUTOR_API_KEY\":\"lk_1\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+		}
+	}))
+	defer server.Close()
+
+	p := NewAWSSecretsManagerProvider("us-east-1")
+	p.endpoint = server.URL
+	p.SetCredentials("AKID", "SECRET", "")
+	ctx := context.Background()
+
+	if v, err := p.GetSecret(ctx, "plain"); err != nil || v != "raw-value" {
+		t.Fatalf("expected raw-value, got %q %v", v, err)
+	}
+	if v, err := p.GetSecret(ctx, "bundle#LOKUTOR_API_KEY"); err != nil || v != "lk_1" {
+		t.Errorf("expected JSON field lookup, got %q %v", v, err)
+	}
+	if _, err := p.GetSecret(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
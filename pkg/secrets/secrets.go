@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+	Name() string
+}
+
+type EnvProvider struct {
+	prefix string
+}
+
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+func (p *EnvProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	v := os.Getenv(p.prefix + name)
+	if v == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+// FileProvider reads one secret per file from dir, which matches how
+// Kubernetes and Docker mount secrets. Files are re-read on every call so a
+// rotated secret is picked up without a restart.
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", err
+	}
+	v := strings.TrimSpace(string(data))
+	if v == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// ChainProvider returns the first secret found across providers.
+type ChainProvider struct {
+	providers []Provider
+}
+
+func NewChainProvider(providers ...Provider) *ChainProvider {
+	return &ChainProvider{providers: providers}
+}
+
+func (c *ChainProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	var errs []error
+	for _, p := range c.providers {
+		v, err := p.GetSecret(ctx, name)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+func (c *ChainProvider) Name() string {
+	return "chain"
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// CachedProvider memoizes secrets for ttl, so remote backends are not hit on
+// every request while rotated values still propagate within ttl.
+type CachedProvider struct {
+	mu       sync.Mutex
+	provider Provider
+	ttl      time.Duration
+	cache    map[string]cachedSecret
+	now      func() time.Time
+}
+
+func NewCachedProvider(provider Provider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		provider: provider,
+		ttl:      ttl,
+		cache:    make(map[string]cachedSecret),
+		now:      time.Now,
+	}
+}
+
+func (c *CachedProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[name]
+	c.mu.Unlock()
+	if ok && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.value, nil
+	}
+
+	v, err := c.provider.GetSecret(ctx, name)
+	if err != nil {
+		// Keep serving the last known value if the backend is briefly unavailable.
+		if ok && !errors.Is(err, ErrNotFound) {
+			return entry.value, nil
+		}
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.cache[name] = cachedSecret{value: v, fetchedAt: c.now()}
+	c.mu.Unlock()
+	return v, nil
+}
+
+func (c *CachedProvider) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name == "" {
+		c.cache = make(map[string]cachedSecret)
+		return
+	}
+	delete(c.cache, name)
+}
+
+func (c *CachedProvider) Name() string {
+	return "cached-" + c.provider.Name()
+}
+
+// KeyRef lets API clients resolve their key on every request, either from a
+// fixed value or from a Provider.
+type KeyRef struct {
+	mu       sync.RWMutex
+	provider Provider
+	name     string
+}
+
+func (r *KeyRef) Set(provider Provider, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.provider = provider
+	r.name = name
+}
+
+func (r *KeyRef) Resolve(ctx context.Context, fallback string) (string, error) {
+	r.mu.RLock()
+	provider, name := r.provider, r.name
+	r.mu.RUnlock()
+	if provider == nil {
+		return fallback, nil
+	}
+	return provider.GetSecret(ctx, name)
+}
@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("LK_GROQ_API_KEY", "from-env")
+	p := NewEnvProvider("LK_")
+
+	v, err := p.GetSecret(context.Background(), "GROQ_API_KEY")
+	if err != nil || v != "from-env" {
+		t.Fatalf("expected from-env, got %q %v", v, err)
+	}
+	if _, err := p.GetSecret(context.Background(), "MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFileProviderPicksUpRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "OPENAI_API_KEY")
+	os.WriteFile(path, []byte("first\n"), 0600)
+
+	p := NewFileProvider(dir)
+	if v, _ := p.GetSecret(context.Background(), "OPENAI_API_KEY"); v != "first" {
+		t.Fatalf("expected trimmed value, got %q", v)
+	}
+
+	os.WriteFile(path, []byte("second"), 0600)
+	if v, _ := p.GetSecret(context.Background(), "OPENAI_API_KEY"); v != "second" {
+		t.Errorf("expected rotated value, got %q", v)
+	}
+
+	if _, err := p.GetSecret(context.Background(), "../etc/passwd"); err == nil {
+		t.Error("expected path traversal to be rejected")
+	}
+}
+
+type countingProvider struct {
+	value string
+	err   error
+	calls int
+}
+
+func (c *countingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	c.calls++
+	return c.value, c.err
+}
+
+func (c *countingProvider) Name() string { return "counting" }
+
+func TestChainProvider(t *testing.T) {
+	missing := &countingProvider{err: ErrNotFound}
+	found := &countingProvider{value: "v"}
+	chain := NewChainProvider(missing, found)
+
+	v, err := chain.GetSecret(context.Background(), "K")
+	if err != nil || v != "v" {
+		t.Fatalf("expected fallback value, got %q %v", v, err)
+	}
+
+	if _, err := NewChainProvider(missing).GetSecret(context.Background(), "K"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCachedProvider(t *testing.T) {
+	backend := &countingProvider{value: "v1"}
+	now := time.Now()
+	c := NewCachedProvider(backend, time.Minute)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.GetSecret(ctx, "K")
+	c.GetSecret(ctx, "K")
+	if backend.calls != 1 {
+		t.Errorf("expected cached lookup, backend called %d times", backend.calls)
+	}
+
+	backend.value = "v2"
+	now = now.Add(2 * time.Minute)
+	if v, _ := c.GetSecret(ctx, "K"); v != "v2" {
+		t.Errorf("expected refreshed value after ttl, got %q", v)
+	}
+
+	backend.err = errors.New("backend down")
+	now = now.Add(2 * time.Minute)
+	if v, err := c.GetSecret(ctx, "K"); err != nil || v != "v2" {
+		t.Errorf("expected stale value while backend is down, got %q %v", v, err)
+	}
+}
+
+func TestKeyRef(t *testing.T) {
+	var ref KeyRef
+	if v, _ := ref.Resolve(context.Background(), "static"); v != "static" {
+		t.Errorf("expected fallback without provider, got %q", v)
+	}
+	ref.Set(&countingProvider{value: "dynamic"}, "K")
+	if v, _ := ref.Resolve(context.Background(), "static"); v != "dynamic" {
+		t.Errorf("expected provider value, got %q", v)
+	}
+}
@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// VaultProvider reads from a HashiCorp Vault KV v2 engine. Names are either a
+// field of the default path, or "path#field".
+type VaultProvider struct {
+	addr   string
+	token  string
+	mount  string
+	path   string
+	client *http.Client
+}
+
+func NewVaultProvider(addr, token, mount, path string) *VaultProvider {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		path:   strings.Trim(path, "/"),
+		client: http.DefaultClient,
+	}
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	path, field := p.path, name
+	if i := strings.Index(name, "#"); i >= 0 {
+		path, field = strings.Trim(name[:i], "/"), name[i+1:]
+	}
+	if path == "" || field == "" {
+		return "", fmt.Errorf("invalid vault secret reference %q", name)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, path)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("vault error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	v, ok := result.Data.Data[field].(string)
+	if !ok || v == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
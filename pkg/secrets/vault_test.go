@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/voice-agent":
+			w.Write([]byte(`{"data":{"data":{"GROQ_API_KEY":"gsk_1"}}}`))
+		case "/v1/secret/data/other":
+			w.Write([]byte(`{"data":{"data":{"key":"other"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewVaultProvider(server.URL, "root", "", "voice-agent")
+	ctx := context.Background()
+
+	if v, err := p.GetSecret(ctx, "GROQ_API_KEY"); err != nil || v != "gsk_1" {
+		t.Fatalf("expected gsk_1, got %q %v", v, err)
+	}
+	if v, err := p.GetSecret(ctx, "other#key"); err != nil || v != "other" {
+		t.Errorf("expected path#field lookup, got %q %v", v, err)
+	}
+	if _, err := p.GetSecret(ctx, "missing#key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := p.GetSecret(ctx, "NOPE"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing field, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/opus"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+)
+
+// codecFlushDelay is how long the bot's audio has to pause before what a
+// codec holds back, such as a partial Opus frame, is sent.
+const codecFlushDelay = 100 * time.Millisecond
+
+// clientCodec converts between the audio a client sends and receives, one
+// packet per binary message, and the stream's PCM. decode is only called
+// by the read loop and the rest by the write loop.
+type clientCodec interface {
+	decode(data []byte) ([]byte, error)
+	encode(pcm []byte) ([][]byte, error)
+	// flush returns what encode held back, at the end of an utterance.
+	flush() ([][]byte, error)
+	// reset drops what encode held back, on barge-in.
+	reset()
+}
+
+// newClientCodec returns the codec a client asked for with codec=..., or
+// nil for raw PCM.
+func (h *Handler) newClientCodec(name string) (clientCodec, error) {
+	outRate := h.orch.GetConfig().SampleRate
+	inRate := outRate
+	if h.opts.InputSampleRate > 0 {
+		inRate = h.opts.InputSampleRate
+	}
+	if name == "opus" {
+		enc, err := opus.NewEncoder(h.opts.OpusCodec, outRate)
+		if err != nil {
+			return nil, err
+		}
+		dec, err := opus.NewDecoder(h.opts.OpusCodec, inRate)
+		if err != nil {
+			return nil, err
+		}
+		return &opusCodec{enc: enc, dec: dec}, nil
+	}
+	enc, err := audio.ParseEncoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported codec %q", name)
+	}
+	if enc == audio.EncodingPCM {
+		return nil, nil
+	}
+	return &g711Codec{
+		enc: enc,
+		in:  resample.NewResampler(audio.G711SampleRate, inRate),
+		out: resample.NewResampler(outRate, audio.G711SampleRate),
+	}, nil
+}
+
+type opusCodec struct {
+	enc *opus.Encoder
+	dec *opus.Decoder
+}
+
+func (c *opusCodec) decode(data []byte) ([]byte, error)  { return c.dec.Decode(data) }
+func (c *opusCodec) encode(pcm []byte) ([][]byte, error) { return c.enc.Encode(pcm) }
+func (c *opusCodec) flush() ([][]byte, error)            { return c.enc.Flush() }
+func (c *opusCodec) reset()                              { c.enc.Reset() }
+
+// g711Codec carries 8kHz mu-law or a-law, as telephony bridges do.
+type g711Codec struct {
+	enc audio.Encoding
+	in  *resample.Resampler
+	out *resample.Resampler
+}
+
+func (c *g711Codec) decode(data []byte) ([]byte, error) {
+	return c.in.Process(c.enc.Decode(data)), nil
+}
+
+func (c *g711Codec) encode(pcm []byte) ([][]byte, error) {
+	return packet(c.enc.Encode(c.out.Process(pcm))), nil
+}
+
+func (c *g711Codec) flush() ([][]byte, error) {
+	return packet(c.enc.Encode(c.out.Flush())), nil
+}
+
+func (c *g711Codec) reset() { c.out.Reset() }
+
+func packet(data []byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return [][]byte{data}
+}
+
+func writePackets(ctx context.Context, conn *websocket.Conn, packets [][]byte) error {
+	for _, p := range packets {
+		wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := conn.Write(wctx, websocket.MessageBinary, p)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Readiness is the body served by /readyz.
+type Readiness struct {
+	Ready     bool                          `json:"ready"`
+	Draining  bool                          `json:"draining"`
+	Sessions  int                           `json:"sessions"`
+	Capacity  int                           `json:"capacity,omitempty"`
+	Providers []orchestrator.ProviderHealth `json:"providers"`
+	Audio     orchestrator.AudioHealth      `json:"audio"`
+	CheckedAt time.Time                     `json:"checked_at"`
+	Reasons   []string                      `json:"reasons,omitempty"`
+}
+
+// providerCache keeps the last preflight result so probes every few seconds
+// don't turn into provider API calls.
+type providerCache struct {
+	mu        sync.Mutex
+	results   []orchestrator.ProviderHealth
+	checkedAt time.Time
+}
+
+// Preflight checks every provider now and caches the result for /readyz.
+func (h *Handler) Preflight(ctx context.Context) []orchestrator.ProviderHealth {
+	results := h.orch.CheckProviders(ctx)
+
+	h.health.mu.Lock()
+	h.health.results = results
+	h.health.checkedAt = time.Now()
+	h.health.mu.Unlock()
+	return results
+}
+
+func (h *Handler) providerHealth(ctx context.Context) ([]orchestrator.ProviderHealth, time.Time) {
+	h.health.mu.Lock()
+	results, checkedAt := h.health.results, h.health.checkedAt
+	h.health.mu.Unlock()
+
+	if checkedAt.IsZero() || time.Since(checkedAt) > h.opts.HealthCacheTTL {
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		results = h.Preflight(cctx)
+		checkedAt = time.Now()
+	}
+	return results, checkedAt
+}
+
+// atCapacity reports whether the orchestrator's session slots
+// (Config.Capacity) are all taken.
+func (h *Handler) atCapacity() bool {
+	c := h.orch.Capacity()
+	return c.MaxSessions > 0 && c.Active >= c.MaxSessions
+}
+
+// Readiness reports whether this instance should receive new sessions.
+func (h *Handler) Readiness(ctx context.Context) Readiness {
+	r := Readiness{
+		Draining: h.draining.Load(),
+		Sessions: len(h.orch.Streams()),
+		Capacity: h.orch.Capacity().MaxSessions,
+		Audio:    h.orch.CheckAudio(),
+	}
+	r.Providers, r.CheckedAt = h.providerHealth(ctx)
+
+	if r.Draining {
+		r.Reasons = append(r.Reasons, "draining")
+	}
+	// A full orchestrator still queues new streams (see CapacityConfig), but
+	// the load balancer should prefer other replicas.
+	if h.atCapacity() {
+		r.Reasons = append(r.Reasons, "at capacity")
+	}
+	for _, p := range r.Providers {
+		if p.Status == "unhealthy" {
+			r.Reasons = append(r.Reasons, p.Kind+" provider unhealthy: "+p.Error)
+		}
+	}
+	if r.Audio.Status != "ok" {
+		r.Reasons = append(r.Reasons, "audio: "+r.Audio.Error)
+	}
+	r.Ready = len(r.Reasons) == 0
+	return r
+}
+
+// Healthz is a liveness probe: it only fails when the process can't serve
+// HTTP at all, so a slow provider never gets the pod restarted.
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, map[string]interface{}{
+		"status":   "ok",
+		"sessions": len(h.orch.Streams()),
+	})
+}
+
+// Readyz is a readiness probe: it fails while draining, at capacity, or when a
+// provider or the audio pipeline is unhealthy.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	readiness := h.Readiness(r.Context())
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, status, readiness)
+}
+
+func writeHealth(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
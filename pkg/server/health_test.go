@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type checkedTTS struct {
+	stubTTS
+	err   error
+	calls int
+}
+
+func (c *checkedTTS) HealthCheck(ctx context.Context) error {
+	c.calls++
+	return c.err
+}
+
+func readyz(t *testing.T, h *Handler) (int, Readiness) {
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+	var r Readiness
+	if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+		t.Fatalf("invalid readiness body: %s", rec.Body.String())
+	}
+	return rec.Code, r
+}
+
+func TestHandler_Readyz(t *testing.T) {
+	tts := &checkedTTS{}
+	vad := orchestrator.NewRMSVAD(0.02, 500*time.Millisecond)
+	config := orchestrator.DefaultConfig()
+	config.Capacity.MaxSessions = 1
+	orch := orchestrator.NewWithVAD(stubSTT{}, stubLLM{}, tts, vad, config)
+	h := NewHandler(orch, Options{})
+
+	code, r := readyz(t, h)
+	if code != http.StatusOK || !r.Ready || r.Capacity != 1 || r.Audio.Status != "ok" {
+		t.Fatalf("expected ready, got %d %+v", code, r)
+	}
+
+	// Preflight results are cached between probes.
+	readyz(t, h)
+	if tts.calls != 1 {
+		t.Errorf("expected cached provider health, got %d checks", tts.calls)
+	}
+
+	tts.err = errors.New("503 from upstream")
+	h.Preflight(context.Background())
+	if code, r := readyz(t, h); code != http.StatusServiceUnavailable || r.Ready || len(r.Reasons) != 1 {
+		t.Errorf("expected unhealthy provider to fail readiness, got %d %+v", code, r)
+	}
+	tts.err = nil
+	h.Preflight(context.Background())
+
+	stream := orch.NewManagedStream(context.Background(), orch.NewSessionWithDefaults("busy"))
+	if code, r := readyz(t, h); code != http.StatusServiceUnavailable || r.Sessions != 1 || r.Reasons[0] != "at capacity" {
+		t.Errorf("expected capacity to fail readiness, got %d %+v", code, r)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected new connections to be rejected at capacity, got %d", rec.Code)
+	}
+	stream.Close()
+
+	h.draining.Store(true)
+	if code, r := readyz(t, h); code != http.StatusServiceUnavailable || !r.Draining {
+		t.Errorf("expected draining to fail readiness, got %d %+v", code, r)
+	}
+}
+
+func TestHandler_HealthzIgnoresProviders(t *testing.T) {
+	tts := &checkedTTS{err: errors.New("down")}
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, tts, orchestrator.DefaultConfig())
+	h := NewHandler(orch, Options{})
+
+	rec := httptest.NewRecorder()
+	h.Healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected liveness to pass, got %d", rec.Code)
+	}
+	if tts.calls != 0 {
+		t.Error("liveness must not call providers")
+	}
+
+	if code, r := readyz(t, h); code != http.StatusServiceUnavailable || r.Audio.Status != "unhealthy" {
+		t.Errorf("expected missing VAD to fail readiness, got %d %+v", code, r)
+	}
+}
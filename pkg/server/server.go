@@ -0,0 +1,438 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Options configure a Handler. Authenticate, when set, is called before the
+// websocket upgrade and returns the id used for the new session; returning an
+// error rejects the connection with 401.
+type Options struct {
+	Authenticate     func(r *http.Request) (string, error)
+	SystemPrompt     string
+	InputSampleRate  int
+	OutputSampleRate int
+	OriginPatterns   []string
+	Logger           orchestrator.Logger
+	// Store, when set, lets clients reconnect to a session that another
+	// instance detached while draining.
+	Store orchestrator.SessionStore
+	// Locker, when set, makes sure a session is only live on one replica.
+	// Connections for a session owned elsewhere are rejected with 409.
+	Locker     orchestrator.SessionLocker
+	InstanceID string
+	LeaseTTL   time.Duration
+	// HealthCacheTTL is how long /readyz reuses provider preflight results.
+	HealthCacheTTL time.Duration
+	// Priority, when set, assigns the priority class of new sessions, e.g.
+	// from the caller's plan. Clients can't choose it themselves.
+	Priority func(r *http.Request) orchestrator.Priority
+	// OpusCodec is used for clients connecting with codec=opus. It
+	// defaults to audio.DefaultOpusCodec.
+	OpusCodec audio.OpusCodec
+	// Sessions, when set, keeps sessions in memory between connections: a
+	// client reconnecting with the same session_id continues where it left
+	// off. Its MaxSessions and IdleTTL bound how many are kept and for how
+	// long. Clients choose their session_id, so only use it with
+	// Authenticate.
+	Sessions *orchestrator.SessionManager
+}
+
+// Handler serves remote voice clients over a websocket. Clients send raw
+// 16-bit mono PCM as binary messages and JSON control messages as text. The
+// server replies with OrchestratorEvents as JSON text messages, except
+// AudioChunk which is sent as binary PCM. Clients connecting with
+// codec=opus, mulaw or alaw send and receive that instead, one packet per
+// binary message.
+type Handler struct {
+	orch     *orchestrator.Orchestrator
+	opts     Options
+	draining atomic.Bool
+	health   providerCache
+}
+
+func NewHandler(orch *orchestrator.Orchestrator, opts Options) *Handler {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.InstanceID == "" {
+		opts.InstanceID = newSessionID()
+	}
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = 15 * time.Second
+	}
+	if opts.HealthCacheTTL <= 0 {
+		opts.HealthCacheTTL = 30 * time.Second
+	}
+	return &Handler{orch: orch, opts: opts}
+}
+
+type controlMessage struct {
+	Type     string `json:"type"`
+	Voice    string `json:"voice,omitempty"`
+	Language string `json:"language,omitempty"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	TurnID   string `json:"turn_id,omitempty"`
+	Bytes    int64  `json:"bytes,omitempty"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		http.Error(w, "server is draining", http.StatusServiceUnavailable)
+		return
+	}
+	// Streams over Config.Capacity wait for a slot when the orchestrator
+	// queues them; otherwise there is no point upgrading.
+	if h.atCapacity() && h.orch.GetConfig().Capacity.QueueTimeout <= 0 {
+		http.Error(w, "server is at capacity", http.StatusServiceUnavailable)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	if h.opts.Authenticate != nil {
+		id, err := h.opts.Authenticate(r)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		sessionID = id
+	}
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+
+	var lease *orchestrator.SessionLease
+	if h.opts.Locker != nil {
+		var err error
+		lease, err = orchestrator.AcquireSessionLease(r.Context(), h.opts.Locker, sessionID, h.opts.InstanceID, h.opts.LeaseTTL)
+		if errors.Is(err, orchestrator.ErrSessionOwned) {
+			http.Error(w, "session is active on another instance", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			h.opts.Logger.Error("failed to acquire session lease", "sessionID", sessionID, "error", err)
+			http.Error(w, "session coordination unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		// Released after the stream is closed (and, when draining, saved).
+		defer lease.Release(context.Background())
+	}
+
+	codec, err := h.newClientCodec(r.URL.Query().Get("codec"))
+	if errors.Is(err, audio.ErrNoOpusCodec) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream, err := h.resume(ctx, sessionID, r.URL.Query().Get("resume_token"))
+	switch {
+	case errors.Is(err, orchestrator.ErrResumeDenied):
+		http.Error(w, "invalid resume token", http.StatusForbidden)
+		return
+	case errors.Is(err, orchestrator.ErrSessionOwned):
+		http.Error(w, "session is still active", http.StatusConflict)
+		return
+	case stream == nil && h.opts.Sessions != nil:
+		stream, err = h.openManaged(ctx, r, sessionID)
+		switch {
+		case errors.Is(err, orchestrator.ErrSessionLive):
+			http.Error(w, "session is still active", http.StatusConflict)
+			return
+		case errors.Is(err, orchestrator.ErrTooManySessions):
+			http.Error(w, "server is at capacity", http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			h.opts.Logger.Error("failed to open session", "sessionID", sessionID, "error", err)
+			http.Error(w, "failed to open session", http.StatusInternalServerError)
+			return
+		}
+	case stream == nil:
+		session := h.orch.NewSessionWithDefaults(sessionID)
+		h.configure(session, r)
+		stream = h.orch.NewManagedStream(ctx, session)
+	}
+	defer stream.Close()
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.opts.OriginPatterns})
+	if err != nil {
+		h.opts.Logger.Warn("websocket accept failed", "error", err)
+		return
+	}
+	defer conn.CloseNow()
+	// A second of 44.1kHz PCM is ~88KB; allow generous frames.
+	conn.SetReadLimit(1 << 20)
+	if h.opts.OutputSampleRate > 0 && h.opts.InputSampleRate > 0 {
+		stream.SetEchoSampleRates(h.opts.OutputSampleRate, h.opts.InputSampleRate)
+	}
+	if r.URL.Query().Get("playback_acks") == "true" {
+		stream.EnableClientPlayback()
+	}
+
+	h.opts.Logger.Info("voice client connected", "sessionID", sessionID, "remote", r.RemoteAddr)
+	defer h.opts.Logger.Info("voice client disconnected", "sessionID", sessionID)
+
+	if lease != nil {
+		go func() {
+			select {
+			case <-lease.Lost():
+				h.opts.Logger.Warn("session lease lost, disconnecting", "sessionID", sessionID)
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	go h.readLoop(ctx, cancel, conn, stream, codec)
+	h.writeLoop(ctx, conn, stream, codec)
+}
+
+// resume restores a session detached by another instance when the client
+// presents the resume token it was sent with SESSION_TRANSFERRED. It returns
+// nil when there is nothing to resume, and an error when the client may not
+// resume the session.
+func (h *Handler) resume(ctx context.Context, sessionID, token string) (*orchestrator.ManagedStream, error) {
+	if h.opts.Store == nil || token == "" {
+		return nil, nil
+	}
+	stream, err := h.orch.ResumeManagedStream(ctx, h.opts.Store, sessionID, token)
+	switch {
+	case err == nil:
+		return stream, nil
+	case errors.Is(err, orchestrator.ErrResumeDenied), errors.Is(err, orchestrator.ErrSessionOwned):
+		h.opts.Logger.Warn("refused to resume session", "sessionID", sessionID, "error", err)
+		return nil, err
+	case !errors.Is(err, orchestrator.ErrSessionNotFound):
+		h.opts.Logger.Warn("failed to resume session, starting fresh", "sessionID", sessionID, "error", err)
+	}
+	return nil, nil
+}
+
+// openManaged starts a stream on the session Options.Sessions keeps for
+// sessionID, setting up the session from the request if it is new.
+func (h *Handler) openManaged(ctx context.Context, r *http.Request, sessionID string) (*orchestrator.ManagedStream, error) {
+	session, err := h.opts.Sessions.Create(sessionID)
+	switch {
+	case err == nil:
+		h.configure(session, r)
+	case !errors.Is(err, orchestrator.ErrSessionExists):
+		return nil, err
+	}
+	return h.opts.Sessions.Open(ctx, sessionID)
+}
+
+func (h *Handler) configure(session *orchestrator.ConversationSession, r *http.Request) {
+	session.UserID = r.URL.Query().Get("user_id")
+	if lang := r.URL.Query().Get("language"); lang != "" {
+		h.orch.SetLanguage(session, orchestrator.Language(lang))
+	}
+	if voice := r.URL.Query().Get("voice"); voice != "" {
+		h.orch.SetVoice(session, orchestrator.Voice(voice))
+	}
+	if h.opts.SystemPrompt != "" {
+		h.orch.SetSystemPrompt(session, h.opts.SystemPrompt)
+	}
+	if h.opts.Priority != nil {
+		session.SetPriority(h.opts.Priority(r))
+	}
+}
+
+// Drain stops accepting connections and hands every live session to the
+// store. Connected clients receive SESSION_TRANSFERRED and should reconnect
+// with the same session_id, which a load balancer routes to another instance.
+func (h *Handler) Drain(ctx context.Context) error {
+	h.draining.Store(true)
+	if h.opts.Store == nil {
+		return errors.New("no session store configured")
+	}
+	return h.orch.Drain(ctx, h.opts.Store)
+}
+
+func (h *Handler) readLoop(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, stream *orchestrator.ManagedStream, codec clientCodec) {
+	defer cancel()
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ == websocket.MessageBinary {
+			if codec != nil {
+				if data, err = codec.decode(data); err != nil {
+					h.opts.Logger.Debug("ignoring undecodable audio packet", "error", err)
+					continue
+				}
+			}
+			stream.Write(data)
+			continue
+		}
+
+		var msg controlMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			h.opts.Logger.Debug("ignoring malformed control message", "error", err)
+			continue
+		}
+		h.handleControl(stream, msg)
+	}
+}
+
+func (h *Handler) handleControl(stream *orchestrator.ManagedStream, msg controlMessage) {
+	switch msg.Type {
+	case "interrupt":
+		stream.Interrupt()
+	case "audio_played":
+		if msg.Bytes > 0 {
+			stream.AckPlayback(orchestrator.PlaybackAck{TurnID: msg.TurnID, Bytes: msg.Bytes})
+		} else {
+			stream.NotifyAudioPlayed()
+		}
+	case "played_audio":
+		stream.RecordRemotePlayback(msg.Data)
+	case "checkpoint":
+		stream.EmitCheckpoint()
+	case "user_text":
+		stream.InjectUserText(msg.Text)
+	case "pause":
+		stream.Pause()
+	case "resume":
+		stream.Resume()
+	case "mute":
+		stream.SetMicMuted(true)
+	case "unmute":
+		stream.SetMicMuted(false)
+	case "begin_user_turn":
+		stream.BeginUserTurn()
+	case "end_user_turn":
+		stream.EndUserTurn()
+	case "end_conversation":
+		stream.EndConversation()
+	case "wake":
+		stream.Wake()
+	case "set_voice":
+		h.orch.SetVoice(stream.Session(), orchestrator.Voice(msg.Voice))
+	case "set_language":
+		h.orch.SetLanguage(stream.Session(), orchestrator.Language(msg.Language))
+	case "set_input_language":
+		stream.Session().SetInputLanguage(orchestrator.Language(msg.Language))
+	case "set_output_language":
+		stream.Session().SetOutputLanguage(orchestrator.Language(msg.Language))
+	case "say":
+		if msg.Text != "" {
+			stream.ScheduleSpeech(0, msg.Text)
+		}
+	case "attach_image":
+		if msg.URL != "" {
+			stream.Session().Attach(orchestrator.ImageURLPart(msg.URL))
+		} else if len(msg.Data) > 0 {
+			stream.Session().Attach(orchestrator.ImageDataPart(msg.Data, msg.MimeType))
+		}
+	}
+}
+
+func (h *Handler) writeLoop(ctx context.Context, conn *websocket.Conn, stream *orchestrator.ManagedStream, codec clientCodec) {
+	// Fires once the bot's audio pauses, to send what the codec held back.
+	flush := time.NewTimer(time.Hour)
+	flush.Stop()
+	defer flush.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		case <-flush.C:
+			packets, err := codec.flush()
+			if err != nil {
+				h.opts.Logger.Warn("audio encode failed", "error", err)
+			}
+			if err := writePackets(ctx, conn, packets); err != nil {
+				return
+			}
+		case ev, ok := <-stream.Events():
+			if !ok {
+				conn.Close(websocket.StatusNormalClosure, "session closed")
+				return
+			}
+			if codec != nil {
+				switch ev.Type {
+				case orchestrator.AudioChunk:
+					pcm, _ := ev.Data.([]byte)
+					packets, err := codec.encode(pcm)
+					if err != nil {
+						h.opts.Logger.Warn("audio encode failed", "error", err)
+					}
+					if err := writePackets(ctx, conn, packets); err != nil {
+						return
+					}
+					flush.Reset(codecFlushDelay)
+					continue
+				case orchestrator.Interrupted:
+					flush.Stop()
+					codec.reset()
+				}
+			}
+			if err := writeEvent(ctx, conn, ev); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(ctx context.Context, conn *websocket.Conn, ev orchestrator.OrchestratorEvent) error {
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if ev.Type == orchestrator.AudioChunk {
+		if audio, ok := ev.Data.([]byte); ok {
+			return conn.Write(wctx, websocket.MessageBinary, audio)
+		}
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return nil
+	}
+	return conn.Write(wctx, websocket.MessageText, data)
+}
+
+// TokenAuth returns an Options.Authenticate that admits clients bearing
+// token, as "Authorization: Bearer <token>" or, since browsers can't set
+// headers on a websocket, a token query parameter. Clients keep choosing
+// their session_id.
+func TokenAuth(token string) func(r *http.Request) (string, error) {
+	return func(r *http.Request) (string, error) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return "", errors.New("invalid token")
+		}
+		return r.URL.Query().Get("session_id"), nil
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "ws_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,622 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "hi there", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return []byte{1, 2, 3, 4}, nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk([]byte{1, 2, 3, 4})
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+func newTestServer(t *testing.T, opts Options) (*orchestrator.Orchestrator, string) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	srv := httptest.NewServer(NewHandler(orch, opts))
+	t.Cleanup(srv.Close)
+	return orch, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestHandler_SpeaksAndStreamsAudio(t *testing.T) {
+	orch, url := newTestServer(t, Options{SystemPrompt: "be nice"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url+"?session_id=call-1&language=es", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.CloseNow()
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte(`{"type":"say","text":"hola"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var sawResponse, sawAudio bool
+	for !(sawResponse && sawAudio) {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("read failed: response=%v audio=%v err=%v", sawResponse, sawAudio, err)
+		}
+		if typ == websocket.MessageBinary {
+			sawAudio = len(data) == 4
+			continue
+		}
+		var ev orchestrator.OrchestratorEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			t.Fatalf("invalid event: %s", data)
+		}
+		if ev.Type == orchestrator.BotResponse {
+			sawResponse = ev.Data == "hola" && ev.SessionID == "call-1"
+		}
+	}
+
+	ms, ok := orch.Stream("call-1")
+	if !ok {
+		t.Fatal("expected stream to be registered")
+	}
+	if ms.Session().GetCurrentLanguage() != orchestrator.LanguageEs {
+		t.Errorf("expected language from query string")
+	}
+	if ms.Session().GetContextCopy()[0].Content != "be nice" {
+		t.Errorf("expected system prompt to be set")
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := orch.Stream("call-1"); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected stream to close when the client disconnects")
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	_, url := newTestServer(t, Options{
+		Authenticate: func(r *http.Request) (string, error) {
+			if r.Header.Get("Authorization") != "Bearer ok" {
+				return "", errors.New("denied")
+			}
+			return "user-7", nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, resp, err := websocket.Dial(ctx, url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", err)
+	}
+
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer ok"}},
+	})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+}
+
+func TestTokenAuth(t *testing.T) {
+	auth := TokenAuth("secret")
+	for _, tc := range []struct {
+		header, query string
+		ok            bool
+	}{
+		{header: "Bearer secret", query: "session_id=call-1", ok: true},
+		{query: "session_id=call-1&token=secret", ok: true},
+		{header: "Bearer wrong", query: "session_id=call-1"},
+		{query: "session_id=call-1"},
+	} {
+		r := httptest.NewRequest("GET", "/ws?"+tc.query, nil)
+		if tc.header != "" {
+			r.Header.Set("Authorization", tc.header)
+		}
+		id, err := auth(r)
+		if tc.ok && (err != nil || id != "call-1") {
+			t.Errorf("%+v: expected call-1, got %q, %v", tc, id, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%+v: expected the request to be refused", tc)
+		}
+	}
+	if _, err := TokenAuth("")(httptest.NewRequest("GET", "/ws", nil)); err == nil {
+		t.Error("expected an empty token to admit nobody")
+	}
+}
+
+func TestHandler_DrainAndResume(t *testing.T) {
+	store := orchestrator.NewMemorySessionStore()
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+
+	oldOrch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	oldHandler := NewHandler(oldOrch, Options{Store: store})
+	oldSrv := httptest.NewServer(oldHandler)
+	defer oldSrv.Close()
+
+	newOrch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	newSrv := httptest.NewServer(NewHandler(newOrch, Options{Store: store}))
+	defer newSrv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(oldSrv.URL, "http")+"?session_id=call-9", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	for {
+		if ms, ok := oldOrch.Stream("call-9"); ok {
+			ms.Session().AddMessage("user", "remember me")
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := oldHandler.Drain(ctx); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+
+	var token string
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			break
+		}
+		var ev struct {
+			Type orchestrator.EventType    `json:"type"`
+			Data orchestrator.TransferInfo `json:"data"`
+		}
+		if json.Unmarshal(data, &ev) == nil && ev.Type == orchestrator.SessionTransferred {
+			token = ev.Data.ResumeToken
+		}
+	}
+	if token == "" {
+		t.Fatal("expected client to be told about the transfer, with a resume token")
+	}
+
+	if _, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(oldSrv.URL, "http"), nil); err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected draining instance to refuse connections")
+	}
+
+	if _, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(newSrv.URL, "http")+"?session_id=call-9&resume_token=guess", nil); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a wrong resume token to be refused")
+	}
+	conn2, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(newSrv.URL, "http")+"?session_id=call-9&resume_token="+token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.CloseNow()
+
+	for {
+		if ms, ok := newOrch.Stream("call-9"); ok {
+			if ctx := ms.Session().GetContextCopy(); len(ctx) != 1 || ctx[0].Content != "remember me" {
+				t.Errorf("expected resumed context, got %+v", ctx)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("session was not resumed")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestHandler_SessionOwnedElsewhere(t *testing.T) {
+	locker := orchestrator.NewMemorySessionLocker()
+	_, urlA := newTestServer(t, Options{Locker: locker, InstanceID: "pod-a"})
+	_, urlB := newTestServer(t, Options{Locker: locker, InstanceID: "pod-b"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, urlA+"?session_id=call-5", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp, err := websocket.Dial(ctx, urlB+"?session_id=call-5", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 from the second replica, got %v", err)
+	}
+	_, resp, err = websocket.Dial(ctx, urlA+"?session_id=call-5", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for a second connection to the same replica, got %v", err)
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+	for {
+		if owner, _ := locker.Owner(ctx, "call-5"); owner == "" {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("expected the lease to be released on disconnect")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	conn, _, err = websocket.Dial(ctx, urlB+"?session_id=call-5", nil)
+	if err != nil {
+		t.Fatalf("expected pod-b to take the session once released: %v", err)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+}
+
+func TestHandler_SessionManager(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	manager := orchestrator.NewSessionManager(orch, orchestrator.SessionManagerOptions{MaxSessions: 1})
+	defer manager.Close()
+	srv := httptest.NewServer(NewHandler(orch, Options{Sessions: manager, SystemPrompt: "be nice"}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url+"?session_id=call-3&language=es", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, ok := manager.Get("call-3")
+	if !ok {
+		t.Fatal("expected the manager to track the session")
+	}
+	session.AddMessage("user", "remember me")
+
+	if _, resp, err := websocket.Dial(ctx, url+"?session_id=call-3", nil); err == nil || resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for a second connection to a live session")
+	}
+	if _, resp, err := websocket.Dial(ctx, url+"?session_id=call-4", nil); err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 beyond MaxSessions")
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+	for {
+		if infos := manager.Sessions(); len(infos) == 1 && !infos[0].Live {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("expected the stream to end on disconnect")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	conn, _, err = websocket.Dial(ctx, url+"?session_id=call-3&language=en", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	if got, _ := manager.Get("call-3"); got != session || len(got.GetContextCopy()) != 2 || got.GetCurrentLanguage() != orchestrator.LanguageEs {
+		t.Errorf("expected the reconnect to continue the same session unchanged")
+	}
+}
+
+func TestHandler_AttachImage(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("kiosk"))
+	defer stream.Close()
+
+	var msg controlMessage
+	if err := json.Unmarshal([]byte(`{"type":"attach_image","data":"/9j/","mime_type":"image/jpeg"}`), &msg); err != nil {
+		t.Fatal(err)
+	}
+	h.handleControl(stream, msg)
+	stream.Session().AddMessage("user", "what is this?")
+
+	parts := stream.Session().GetContextCopy()[0].Parts
+	if len(parts) != 1 || parts[0].MimeType != "image/jpeg" || len(parts[0].Data) != 3 {
+		t.Errorf("unexpected parts %+v", parts)
+	}
+}
+
+func TestHandler_CheckpointOnRequest(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("resync"))
+	defer stream.Close()
+	stream.Session().AddMessage("user", "where was I?")
+
+	h.handleControl(stream, controlMessage{Type: "checkpoint"})
+
+	select {
+	case ev := <-stream.Events():
+		state, ok := ev.Data.(orchestrator.ConversationState)
+		if ev.Type != orchestrator.Checkpoint || !ok || len(state.Messages) != 1 {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no checkpoint emitted")
+	}
+}
+
+type countingEcho struct{ played atomic.Int64 }
+
+func (e *countingEcho) Name() string                                 { return "counting" }
+func (e *countingEcho) RecordPlayed(chunk []byte)                    { e.played.Add(int64(len(chunk))) }
+func (e *countingEcho) Process(chunk, history []byte) ([]byte, bool) { return chunk, false }
+func (e *countingEcho) Reset()                                       {}
+
+func TestHandler_PlaybackAck(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("acks"))
+	defer stream.Close()
+	echo := &countingEcho{}
+	stream.SetEchoProcessor(echo)
+	stream.EnableClientPlayback()
+
+	stream.ScheduleSpeech(0, "hola")
+	deadline := time.After(time.Second)
+	for audio := false; !audio; {
+		select {
+		case ev := <-stream.Events():
+			audio = ev.Type == orchestrator.AudioChunk
+		case <-deadline:
+			t.Fatal("no audio emitted")
+		}
+	}
+	if echo.played.Load() != 0 {
+		t.Fatal("audio entered the echo reference before the client played it")
+	}
+
+	var msg controlMessage
+	ack := fmt.Sprintf(`{"type":"audio_played","turn_id":%q,"bytes":4}`, stream.TurnID())
+	if err := json.Unmarshal([]byte(ack), &msg); err != nil {
+		t.Fatal(err)
+	}
+	h.handleControl(stream, msg)
+	if got := echo.played.Load(); got != 4 {
+		t.Errorf("expected the acked 4 bytes in the echo reference, got %d", got)
+	}
+}
+
+func TestHandler_UserText(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("chat"))
+	defer stream.Close()
+
+	h.handleControl(stream, controlMessage{Type: "user_text", Text: "hi there"})
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type == orchestrator.TranscriptFinal {
+				if ev.Data != "hi there" {
+					t.Errorf("unexpected transcript %v", ev.Data)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("typed message was not handled")
+		}
+	}
+}
+
+func TestHandler_PauseAndMute(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("hold"))
+	defer stream.Close()
+
+	h.handleControl(stream, controlMessage{Type: "pause"})
+	h.handleControl(stream, controlMessage{Type: "mute"})
+	if !stream.Paused() || !stream.MicMuted() || stream.State() != orchestrator.StreamPaused {
+		t.Fatalf("expected a paused, muted stream, got %+v", stream.Status())
+	}
+	h.handleControl(stream, controlMessage{Type: "resume"})
+	h.handleControl(stream, controlMessage{Type: "unmute"})
+	if stream.Paused() || stream.MicMuted() {
+		t.Errorf("expected a listening stream, got %+v", stream.Status())
+	}
+}
+
+func TestHandler_PushToTalk(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	cfg.TurnDetection = orchestrator.PushToTalk
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("ptt"))
+	defer stream.Close()
+
+	h.handleControl(stream, controlMessage{Type: "begin_user_turn"})
+	if stream.State() != orchestrator.StreamListening {
+		t.Fatalf("expected listening, got %s", stream.State())
+	}
+	h.handleControl(stream, controlMessage{Type: "end_user_turn"})
+	if stream.State() == orchestrator.StreamListening {
+		t.Error("expected the turn to be closed")
+	}
+}
+
+func TestHandler_EndConversation(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("hangup"))
+	defer stream.Close()
+
+	h.handleControl(stream, controlMessage{Type: "end_conversation"})
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev := <-stream.Events():
+			if ev.Type == orchestrator.CallEnded {
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected the call to end")
+		}
+	}
+}
+
+func TestHandler_TranslationLanguages(t *testing.T) {
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, orchestrator.DefaultConfig())
+	h := NewHandler(orch, Options{})
+	stream := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("interpreter"))
+	defer stream.Close()
+
+	h.handleControl(stream, controlMessage{Type: "set_input_language", Language: "es"})
+	h.handleControl(stream, controlMessage{Type: "set_output_language", Language: "de"})
+	if in, out := stream.Session().InputLanguage(), stream.Session().OutputLanguage(); in != orchestrator.LanguageEs || out != orchestrator.LanguageDe {
+		t.Errorf("expected es to de, got %s to %s", in, out)
+	}
+}
+
+// passthroughOpus "encodes" PCM as itself.
+type passthroughOpus struct{}
+
+func (passthroughOpus) NewEncoder(sampleRate, channels int) (audio.OpusEncoder, error) {
+	return passthroughOpus{}, nil
+}
+func (passthroughOpus) NewDecoder(sampleRate, channels int) (audio.OpusDecoder, error) {
+	return passthroughOpus{}, nil
+}
+func (passthroughOpus) Encode(pcm []byte) ([]byte, error)    { return pcm, nil }
+func (passthroughOpus) Decode(packet []byte) ([]byte, error) { return packet, nil }
+
+func TestHandler_Opus(t *testing.T) {
+	_, url := newTestServer(t, Options{OpusCodec: passthroughOpus{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url+"?codec=opus", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	conn.Write(ctx, websocket.MessageText, []byte(`{"type":"say","text":"hi"}`))
+
+	// The reply's few bytes at 44.1kHz go out as one padded 20ms frame at
+	// 48kHz once the audio pauses.
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ == websocket.MessageBinary {
+			if len(data) != 1920 {
+				t.Errorf("expected a 20ms frame at 48kHz, got %d bytes", len(data))
+			}
+			return
+		}
+	}
+}
+
+func TestHandler_OpusUnavailable(t *testing.T) {
+	if audio.DefaultOpusCodec != nil {
+		t.Skip("built with a default codec")
+	}
+	_, url := newTestServer(t, Options{})
+	_, resp, err := websocket.Dial(context.Background(), url+"?codec=opus", nil)
+	if err == nil || resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("expected 501 without an opus codec, got %v", err)
+	}
+}
+
+// toneTTS replies with 100ms of audio at 44.1kHz.
+type toneTTS struct{ stubTTS }
+
+func (toneTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(make([]byte, 8820))
+}
+
+func TestHandler_Mulaw(t *testing.T) {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	srv := httptest.NewServer(NewHandler(orchestrator.New(stubSTT{}, stubLLM{}, toneTTS{}, cfg), Options{}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"?codec=mulaw", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	conn.Write(ctx, websocket.MessageText, []byte(`{"type":"say","text":"hi"}`))
+
+	// 100ms at 8kHz is 800 one-byte samples.
+	var got int
+	for got < 800 {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("got %d bytes of mu-law: %v", got, err)
+		}
+		if typ == websocket.MessageBinary {
+			got += len(data)
+		}
+	}
+	if got != 800 {
+		t.Errorf("expected 800 bytes of mu-law, got %d", got)
+	}
+}
+
+func TestHandler_UnknownCodec(t *testing.T) {
+	_, url := newTestServer(t, Options{})
+	_, resp, err := websocket.Dial(context.Background(), url+"?codec=mp3", nil)
+	if err == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown codec, got %v", err)
+	}
+}
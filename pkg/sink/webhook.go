@@ -0,0 +1,268 @@
+// Package sink delivers orchestrator events to external systems.
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// DefaultWebhookEvents are delivered when WebhookOptions.Events is empty.
+var DefaultWebhookEvents = []orchestrator.EventType{
+	orchestrator.TranscriptFinal,
+	orchestrator.BotResponse,
+	orchestrator.Interrupted,
+	orchestrator.ErrorEvent,
+}
+
+const (
+	SignatureHeader = "X-Lokutor-Signature"
+	TimestampHeader = "X-Lokutor-Timestamp"
+	DeliveryHeader  = "X-Lokutor-Delivery"
+)
+
+type WebhookOptions struct {
+	// Events selects the event types to deliver, DefaultWebhookEvents when
+	// empty.
+	Events []orchestrator.EventType
+	// Secret, when set, signs every payload; see Sign.
+	Secret []byte
+	// MaxAttempts bounds deliveries of one event, 5 by default. Failed
+	// attempts are retried with exponential backoff from RetryDelay (500ms
+	// by default) on network errors, 429 and 5xx responses.
+	MaxAttempts int
+	RetryDelay  time.Duration
+	// Timeout bounds each request, 10 seconds by default.
+	Timeout time.Duration
+	// QueueSize is the number of events waiting for delivery, 1024 by
+	// default. Events beyond it are dropped rather than blocking streams.
+	QueueSize int
+	Client    *http.Client
+	Logger    orchestrator.Logger
+}
+
+// WebhookPayload is the JSON body POSTed for each event. ID is unique per
+// event and stays the same across retries, so receivers can deduplicate.
+type WebhookPayload struct {
+	ID string    `json:"id"`
+	At time.Time `json:"at"`
+	orchestrator.OrchestratorEvent
+}
+
+type WebhookStats struct {
+	Delivered int64 `json:"delivered"`
+	Retried   int64 `json:"retried"`
+	Failed    int64 `json:"failed"`
+	Dropped   int64 `json:"dropped"`
+}
+
+// WebhookSink POSTs selected events as JSON to a URL, in order, from a
+// background goroutine. Add it to Config.EventSinks.
+type WebhookSink struct {
+	url    string
+	opts   WebhookOptions
+	events map[orchestrator.EventType]bool
+	queue  chan WebhookPayload
+
+	mu      sync.RWMutex
+	closed  bool
+	done    chan struct{}
+	stop    context.CancelFunc
+	stopCtx context.Context
+
+	delivered, retried, failed, dropped atomic.Int64
+}
+
+func NewWebhookSink(url string, opts WebhookOptions) *WebhookSink {
+	if len(opts.Events) == 0 {
+		opts.Events = DefaultWebhookEvents
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 500 * time.Millisecond
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	s := &WebhookSink{
+		url:    url,
+		opts:   opts,
+		events: make(map[orchestrator.EventType]bool, len(opts.Events)),
+		queue:  make(chan WebhookPayload, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	for _, t := range opts.Events {
+		s.events[t] = true
+	}
+	s.stopCtx, s.stop = context.WithCancel(context.Background())
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Publish(ev orchestrator.OrchestratorEvent) {
+	if !s.events[ev.Type] {
+		return
+	}
+	payload := WebhookPayload{ID: newDeliveryID(), At: time.Now().UTC(), OrchestratorEvent: ev}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return
+	}
+	select {
+	case s.queue <- payload:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *WebhookSink) Stats() WebhookStats {
+	return WebhookStats{
+		Delivered: s.delivered.Load(),
+		Retried:   s.retried.Load(),
+		Failed:    s.failed.Load(),
+		Dropped:   s.dropped.Load(),
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// delivered. When ctx expires first, pending deliveries are abandoned and
+// ctx's error is returned.
+func (s *WebhookSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		s.stop()
+		<-s.done
+		return ctx.Err()
+	}
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	for payload := range s.queue {
+		if s.stopCtx.Err() != nil {
+			s.failed.Add(1)
+			continue
+		}
+		s.deliver(payload)
+	}
+}
+
+func (s *WebhookSink) deliver(payload WebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		payload.Data = fmt.Sprint(payload.Data)
+		body, _ = json.Marshal(payload)
+	}
+
+	delay := s.opts.RetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(payload.ID, body)
+		if err == nil {
+			s.delivered.Add(1)
+			return
+		}
+		if !retry || attempt >= s.opts.MaxAttempts {
+			s.failed.Add(1)
+			s.opts.Logger.Warn("webhook delivery failed", "sessionID", payload.SessionID, "event", payload.Type, "attempts", attempt, "error", err)
+			return
+		}
+		s.retried.Add(1)
+		select {
+		case <-time.After(delay):
+		case <-s.stopCtx.Done():
+			s.failed.Add(1)
+			return
+		}
+		delay *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying.
+func (s *WebhookSink) post(id string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(s.stopCtx, s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, id)
+	if len(s.opts.Secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, Sign(s.opts.Secret, ts, body))
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+// Sign returns the signature header value for a payload sent at timestamp
+// (Unix seconds): "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>". Receivers recompute it to authenticate a delivery
+// and reject stale timestamps to prevent replays.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body and timestamp.
+func Verify(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,158 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return make([]byte, 4), nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(make([]byte, 4))
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+type receiver struct {
+	mu       sync.Mutex
+	payloads []WebhookPayload
+	failures int // answered with 503 before accepting
+}
+
+func (rc *receiver) handler(t *testing.T, secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if secret != nil && !Verify(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("bad signature %q", r.Header.Get(SignatureHeader))
+		}
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		if rc.failures > 0 {
+			rc.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var p WebhookPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("invalid payload %s", body)
+		}
+		if p.ID != r.Header.Get(DeliveryHeader) {
+			t.Errorf("delivery header %q does not match id %q", r.Header.Get(DeliveryHeader), p.ID)
+		}
+		rc.payloads = append(rc.payloads, p)
+	}
+}
+
+func closeSink(t *testing.T, s *WebhookSink) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookSink_RetriesAndSigns(t *testing.T) {
+	secret := []byte("shh")
+	rc := &receiver{failures: 2}
+	srv := httptest.NewServer(rc.handler(t, secret))
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, WebhookOptions{Secret: secret, RetryDelay: time.Millisecond})
+	s.Publish(orchestrator.OrchestratorEvent{Type: orchestrator.UserSpeaking, SessionID: "a"})
+	s.Publish(orchestrator.OrchestratorEvent{Type: orchestrator.TranscriptFinal, SessionID: "a", Data: "hello"})
+	s.Publish(orchestrator.OrchestratorEvent{Type: orchestrator.BotResponse, SessionID: "a", Data: "hi"})
+	closeSink(t, s)
+
+	if len(rc.payloads) != 2 || rc.payloads[0].Data != "hello" || rc.payloads[1].Type != orchestrator.BotResponse {
+		t.Fatalf("unexpected deliveries %+v", rc.payloads)
+	}
+	if stats := s.Stats(); stats.Delivered != 2 || stats.Retried != 2 || stats.Failed != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestWebhookSink_GivesUp(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, WebhookOptions{RetryDelay: time.Millisecond})
+	s.Publish(orchestrator.OrchestratorEvent{Type: orchestrator.ErrorEvent, Data: "boom"})
+	closeSink(t, s)
+
+	if calls != 1 || s.Stats().Failed != 1 {
+		t.Errorf("client errors must not be retried: %d calls, %+v", calls, s.Stats())
+	}
+	s.Publish(orchestrator.OrchestratorEvent{Type: orchestrator.ErrorEvent})
+	if s.Stats().Dropped != 1 {
+		t.Error("events after Close must be dropped")
+	}
+}
+
+func TestWebhookSink_FromStream(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc.handler(t, nil))
+	defer srv.Close()
+	s := NewWebhookSink(srv.URL, WebhookOptions{})
+
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	cfg.EventSinks = []orchestrator.EventSink{s}
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+	ms := orch.NewManagedStream(context.Background(), orchestrator.NewConversationSession("crm"))
+	ms.SetConsent(orchestrator.ConsentTranscript, false)
+	ms.ScheduleSpeech(0, "Your order has shipped.")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && s.Stats().Delivered == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ms.Close()
+	closeSink(t, s)
+
+	if len(rc.payloads) == 0 {
+		t.Fatal("no events delivered")
+	}
+	var responded bool
+	for _, p := range rc.payloads {
+		responded = responded || p.Type == orchestrator.BotResponse
+		if p.SessionID != "crm" {
+			t.Errorf("unexpected session %q", p.SessionID)
+		}
+		if p.Type == orchestrator.BotResponse && p.Data != nil {
+			t.Errorf("transcript delivered without consent: %+v", p)
+		}
+	}
+	if !responded {
+		t.Errorf("bot response not delivered: %+v", rc.payloads)
+	}
+}
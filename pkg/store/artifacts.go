@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArtifactDirOptions bounds an ArtifactDir. Zero values leave that limit off.
+type ArtifactDirOptions struct {
+	// MaxBytes is the total size kept; the oldest files go first.
+	MaxBytes int64
+	// MaxAge removes files older than this.
+	MaxAge time.Duration
+}
+
+// ArtifactDir is a RecordingDir that enforces its limits after every Put,
+// so debugging captures can't fill the disk.
+type ArtifactDir struct {
+	*RecordingDir
+	opts ArtifactDirOptions
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+func NewArtifactDir(dir string, opts ArtifactDirOptions) (*ArtifactDir, error) {
+	d, err := NewRecordingDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &ArtifactDir{RecordingDir: d, opts: opts, now: time.Now}, nil
+}
+
+func (d *ArtifactDir) Put(ctx context.Context, name string, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.RecordingDir.Put(ctx, name, data); err != nil {
+		return err
+	}
+	_, err := d.prune()
+	return err
+}
+
+// Create opens name for writing. Limits are enforced when it is closed.
+func (d *ArtifactDir) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	f, err := d.RecordingDir.Create(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactFile{File: f.(*os.File), dir: d}, nil
+}
+
+// artifactFile prunes its ArtifactDir once written. It stays an
+// io.WriteSeeker.
+type artifactFile struct {
+	*os.File
+	dir *ArtifactDir
+}
+
+func (f *artifactFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	_, err := f.dir.Prune()
+	return err
+}
+
+func (d *ArtifactDir) Delete(ctx context.Context, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.RecordingDir.Delete(ctx, name)
+}
+
+// Prune removes files beyond the limits and returns how many it removed.
+func (d *ArtifactDir) Prune() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prune()
+}
+
+func (d *ArtifactDir) prune() (int, error) {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	err := filepath.WalkDir(d.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path, info.Size(), info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	removed := 0
+	for _, f := range files {
+		expired := d.opts.MaxAge > 0 && d.now().Sub(f.modTime) > d.opts.MaxAge
+		over := d.opts.MaxBytes > 0 && total > d.opts.MaxBytes
+		if !expired && !over {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			return removed, err
+		}
+		// Empty session and turn directories go with their last file.
+		for dir := filepath.Dir(f.path); dir != d.dir && os.Remove(dir) == nil; dir = filepath.Dir(dir) {
+		}
+		total -= f.size
+		removed++
+	}
+	return removed, nil
+}
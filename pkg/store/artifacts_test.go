@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArtifactDir_MaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewArtifactDir(dir, ArtifactDirOptions{MaxBytes: 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"s1/t1/user.wav", "s1/t2/user.wav"} {
+		if err := d.Put(ctx, name, make([]byte, 100)); err != nil {
+			t.Fatal(err)
+		}
+		mod := old.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), mod, mod)
+	}
+	if err := d.Put(ctx, "s2/t1/user.wav", make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "s1", "t1")); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest file and its directory to go, got %v", err)
+	}
+	for _, name := range []string{"s1/t2/user.wav", "s2/t1/user.wav"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %s to be kept: %v", name, err)
+		}
+	}
+}
+
+func TestArtifactDir_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewArtifactDir(dir, ArtifactDirOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := d.Put(ctx, "s1/t1/user.wav", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join(dir, "s1", "t1", "user.wav"), old, old)
+
+	if n, err := d.Prune(); err != nil || n != 1 {
+		t.Errorf("expected 1 file pruned, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "s1")); !os.IsNotExist(err) {
+		t.Errorf("expected the session directory to go, got %v", err)
+	}
+}
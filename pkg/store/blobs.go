@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// BlobInfo describes a stored object. Name is relative to the store's prefix.
+type BlobInfo struct {
+	Name     string
+	Modified time.Time
+}
+
+type blobLister interface {
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// blobObjects is retention for the objects of a bucket whose base name
+// matches pattern, laid out like RecordingDir.
+type blobObjects struct {
+	store   blobLister
+	pattern string
+}
+
+func (b blobObjects) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return b.remove(ctx, "", func(info BlobInfo) bool {
+		return info.Modified.Before(cutoff)
+	})
+}
+
+func (b blobObjects) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" || userID == "." || userID == ".." || strings.ContainsAny(userID, `/\`) {
+		return 0, fmt.Errorf("invalid user id %q", userID)
+	}
+	return b.remove(ctx, "users/"+userID+"/", func(BlobInfo) bool { return true })
+}
+
+func (b blobObjects) remove(ctx context.Context, prefix string, expired func(BlobInfo) bool) (int, error) {
+	blobs, err := b.store.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, info := range blobs {
+		if ok, _ := path.Match(b.pattern, path.Base(info.Name)); !ok || !expired(info) {
+			continue
+		}
+		if err := b.store.Delete(ctx, info.Name); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+var errNoUserID = errors.New("no user id")
+
+// FileStore keeps one JSON document per session in dir. Pointing several
+// instances at a shared volume is enough to hand sessions between them.
+type FileStore struct {
+	dir       string
+	encryptor orchestrator.Encryptor
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// SetEncryptor seals every snapshot written from now on. Plaintext snapshots
+// written earlier remain readable.
+func (f *FileStore) SetEncryptor(enc orchestrator.Encryptor) {
+	f.encryptor = enc
+}
+
+func (f *FileStore) path(sessionID string) (string, error) {
+	if sessionID == "" || strings.ContainsAny(sessionID, `/\`) || sessionID == "." || sessionID == ".." {
+		return "", fmt.Errorf("invalid session id %q", sessionID)
+	}
+	return filepath.Join(f.dir, sessionID+".json"), nil
+}
+
+func (f *FileStore) Save(ctx context.Context, snapshot orchestrator.SessionSnapshot) error {
+	path, err := f.path(snapshot.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	data, err = orchestrator.SealIfConfigured(ctx, f.encryptor, data)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial snapshot.
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileStore) Load(ctx context.Context, sessionID string) (orchestrator.SessionSnapshot, error) {
+	path, err := f.path(sessionID)
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return orchestrator.SessionSnapshot{}, orchestrator.ErrSessionNotFound
+		}
+		return orchestrator.SessionSnapshot{}, err
+	}
+	data, err = orchestrator.OpenIfConfigured(ctx, f.encryptor, data)
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+
+	var snap orchestrator.SessionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return orchestrator.SessionSnapshot{}, fmt.Errorf("corrupt session snapshot %s: %w", sessionID, err)
+	}
+	return snap, nil
+}
+
+func (f *FileStore) Delete(ctx context.Context, sessionID string) error {
+	path, err := f.path(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// PurgeBefore removes snapshots that were last saved before cutoff.
+func (f *FileStore) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	ids, err := f.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, id := range ids {
+		path, _ := f.path(id)
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := f.Delete(ctx, id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DeleteUser removes every snapshot of a session with userID.
+func (f *FileStore) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, errNoUserID
+	}
+	ids, err := f.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, id := range ids {
+		snap, err := f.Load(ctx, id)
+		if errors.Is(err, orchestrator.ErrSessionNotFound) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		if snap.UserID != userID {
+			continue
+		}
+		if err := f.Delete(ctx, id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
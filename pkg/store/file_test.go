@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	snap := orchestrator.SessionSnapshot{ID: "call-1", Context: []orchestrator.Message{{Role: "user", Content: "hi"}}}
+	if err := s.Save(ctx, snap); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-2"})
+
+	got, err := s.Load(ctx, "call-1")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(got.Context) != 1 || got.Context[0].Content != "hi" {
+		t.Errorf("unexpected snapshot: %+v", got)
+	}
+
+	ids, _ := s.List(ctx)
+	if len(ids) != 2 || ids[0] != "call-1" || ids[1] != "call-2" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	if err := s.Delete(ctx, "call-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(ctx, "call-1"); !errors.Is(err, orchestrator.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := s.Save(ctx, orchestrator.SessionSnapshot{ID: "../escape"}); err == nil {
+		t.Error("expected path traversal to be rejected")
+	}
+}
+
+func TestFileStore_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewFileStore(dir)
+	enc, err := orchestrator.NewAESGCMEncryptorFromKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetEncryptor(enc)
+
+	ctx := context.Background()
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "secret", LastUser: "my card number"})
+
+	raw, _ := os.ReadFile(filepath.Join(dir, "secret.json"))
+	if !orchestrator.IsEncrypted(raw) {
+		t.Fatal("expected snapshot to be encrypted at rest")
+	}
+	got, err := s.Load(ctx, "secret")
+	if err != nil || got.LastUser != "my card number" {
+		t.Errorf("expected decrypted snapshot, got %+v (%v)", got, err)
+	}
+}
+
+func TestFileStore_Purge(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewFileStore(dir)
+	ctx := context.Background()
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "old"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "new", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "newer", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "other", UserID: "u2"})
+
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(dir, "old.json"), past, past)
+
+	n, err := s.PurgeBefore(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 purged snapshot, got %d (%v)", n, err)
+	}
+	if n, _ := s.DeleteUser(ctx, "u1"); n != 2 {
+		t.Errorf("expected both of the user's snapshots to be deleted, got %d", n)
+	}
+	if ids, _ := s.List(ctx); len(ids) != 1 || ids[0] != "other" {
+		t.Errorf("expected only the other user's snapshot, got %v", ids)
+	}
+}
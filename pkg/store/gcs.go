@@ -0,0 +1,163 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/secrets"
+)
+
+// GCSStorage is a BlobStore of objects in a Google Cloud Storage bucket,
+// using the JSON API with an OAuth access token. Tokens expire, so long-lived
+// deployments resolve them through SetSecretProvider. Retention can be left
+// to a lifecycle rule on the bucket, or applied through Matching.
+type GCSStorage struct {
+	bucket   string
+	endpoint string
+	prefix   string
+	token    string
+	tokenRef secrets.KeyRef
+	client   *http.Client
+}
+
+func NewGCSStorage(bucket, accessToken string) *GCSStorage {
+	return &GCSStorage{
+		bucket:   bucket,
+		endpoint: "https://storage.googleapis.com",
+		token:    accessToken,
+		client:   http.DefaultClient,
+	}
+}
+
+func (s *GCSStorage) SetSecretProvider(provider secrets.Provider, name string) {
+	s.tokenRef.Set(provider, name)
+}
+
+// SetEndpoint points the storage at an emulator or a private endpoint.
+func (s *GCSStorage) SetEndpoint(endpoint string) {
+	s.endpoint = strings.TrimSuffix(endpoint, "/")
+}
+
+// SetPrefix puts every object under prefix, e.g. "debug/".
+func (s *GCSStorage) SetPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+func (s *GCSStorage) Put(ctx context.Context, name string, data []byte) error {
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", s.endpoint, url.PathEscape(s.bucket), url.QueryEscape(s.prefix+name))
+	resp, err := s.do(ctx, http.MethodPost, u, name, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *GCSStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(name)+"?alt=media", name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(name), name, nil)
+	if errors.Is(err, orchestrator.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List returns the objects whose names start with prefix, which is relative
+// to the storage's own prefix like every name.
+func (s *GCSStorage) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+	q := url.Values{"prefix": {s.prefix + prefix}}
+	for {
+		u := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", s.endpoint, url.PathEscape(s.bucket), q.Encode())
+		resp, err := s.do(ctx, http.MethodGet, u, "list "+prefix, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items []struct {
+				Name    string    `json:"name"`
+				Updated time.Time `json:"updated"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gcs list %s: %w", prefix, err)
+		}
+		for _, obj := range page.Items {
+			blobs = append(blobs, BlobInfo{Name: strings.TrimPrefix(obj.Name, s.prefix), Modified: obj.Updated})
+		}
+		if page.NextPageToken == "" {
+			return blobs, nil
+		}
+		q.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// Matching narrows retention to objects whose base name matches pattern
+// (see path.Match), like RecordingDir.Matching.
+func (s *GCSStorage) Matching(pattern string) orchestrator.Purgeable {
+	return blobObjects{store: s, pattern: pattern}
+}
+
+func (s *GCSStorage) objectURL(name string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", s.endpoint, url.PathEscape(s.bucket), url.PathEscape(s.prefix+name))
+}
+
+// do sends an authorized request about the object name. Other error statuses
+// are returned as errors, 404 as ErrBlobNotFound.
+func (s *GCSStorage) do(ctx context.Context, method, u, name string, data []byte) (*http.Response, error) {
+	token, err := s.tokenRef.Resolve(ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, orchestrator.ErrBlobNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("gcs %s %s (status %d): %s", strings.ToLower(method), name, resp.StatusCode, string(respBody))
+	}
+	return resp, nil
+}
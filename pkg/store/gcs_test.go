@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// fakeGCS serves the parts of the GCS JSON API that GCSStorage uses.
+func fakeGCS(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		const objectPath = "/storage/v1/b/captures/o/"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/captures/o":
+			type item struct {
+				Name    string    `json:"name"`
+				Updated time.Time `json:"updated"`
+			}
+			var page struct {
+				Items []item `json:"items"`
+			}
+			for name := range objects {
+				if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+					page.Items = append(page.Items, item{Name: name, Updated: time.Now()})
+				}
+			}
+			json.NewEncoder(w).Encode(page)
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/captures/o":
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Query().Get("name")] = data
+		case strings.HasPrefix(r.URL.Path, objectPath):
+			name := strings.TrimPrefix(r.URL.Path, objectPath)
+			data, ok := objects[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				delete(objects, name)
+				return
+			}
+			w.Write(data)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+}
+
+func TestGCSStorage(t *testing.T) {
+	server := fakeGCS(t)
+	defer server.Close()
+	s := NewGCSStorage("captures", "token")
+	s.SetEndpoint(server.URL)
+	s.SetPrefix("debug/")
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "s1/t1/user.wav", []byte("RIFF")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.Get(ctx, "s1/t1/user.wav"); err != nil || string(data) != "RIFF" {
+		t.Errorf("got %q, %v", data, err)
+	}
+	if err := s.Delete(ctx, "s1/t1/user.wav"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "s1/t1/user.wav"); !errors.Is(err, orchestrator.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+	if err := s.Delete(ctx, "s1/t1/user.wav"); err != nil {
+		t.Errorf("expected deleting a missing object to succeed, got %v", err)
+	}
+
+	s = NewGCSStorage("captures", "expired")
+	s.SetEndpoint(server.URL)
+	if err := s.Put(ctx, "x.wav", nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected a 401 error, got %v", err)
+	}
+}
+
+func TestGCSStorage_Matching(t *testing.T) {
+	server := fakeGCS(t)
+	defer server.Close()
+	s := NewGCSStorage("captures", "token")
+	s.SetEndpoint(server.URL)
+	s.SetPrefix("recordings/")
+	ctx := context.Background()
+	for _, name := range []string{"users/u1/s1/caller.wav", "users/u1/s1/timeline.jsonl", "users/u2/s2/caller.wav", "s3/caller.wav"} {
+		if err := s.Put(ctx, name, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := s.Matching("*.wav").DeleteUser(ctx, "u1"); err != nil || n != 1 {
+		t.Errorf("expected u1's recording deleted, got %d (%v)", n, err)
+	}
+	if _, err := s.Get(ctx, "users/u1/s1/timeline.jsonl"); err != nil {
+		t.Errorf("expected the timeline to be left to its own retention, got %v", err)
+	}
+	if n, err := s.Matching("*.wav").PurgeBefore(ctx, time.Now().Add(time.Hour)); err != nil || n != 2 {
+		t.Errorf("expected the 2 other recordings purged, got %d (%v)", n, err)
+	}
+	if _, err := s.Matching("*").DeleteUser(ctx, "../u1"); err == nil {
+		t.Error("expected an invalid user id to be refused")
+	}
+}
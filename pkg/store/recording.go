@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// RecordingDir is a BlobStore of files under dir, e.g. recordings with one
+// directory per session and segment.
+type RecordingDir struct {
+	dir string
+}
+
+func NewRecordingDir(dir string) (*RecordingDir, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &RecordingDir{dir: dir}, nil
+}
+
+func (d *RecordingDir) Put(ctx context.Context, name string, data []byte) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Create opens name for writing, so recordings can be written as they
+// come. The file is an *os.File, which lets WAV headers be patched on close.
+func (d *RecordingDir) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+func (d *RecordingDir) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, orchestrator.ErrBlobNotFound
+	}
+	return data, err
+}
+
+func (d *RecordingDir) Delete(ctx context.Context, name string) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PurgeBefore removes files last written before cutoff.
+func (d *RecordingDir) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return d.Matching("*").PurgeBefore(ctx, cutoff)
+}
+
+// DeleteUser removes everything recorded for userID, which the recorder
+// keeps under users/<userID>.
+func (d *RecordingDir) DeleteUser(ctx context.Context, userID string) (int, error) {
+	return d.Matching("*").DeleteUser(ctx, userID)
+}
+
+// Matching narrows retention to files whose base name matches pattern (see
+// filepath.Match), so a recording's timeline.jsonl can be kept for a
+// different time than its audio.
+func (d *RecordingDir) Matching(pattern string) orchestrator.Purgeable {
+	return recordingFiles{dir: d.dir, pattern: pattern}
+}
+
+type recordingFiles struct {
+	dir     string
+	pattern string
+}
+
+func (f recordingFiles) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return f.remove(ctx, f.dir, func(info fs.FileInfo) bool {
+		return info.ModTime().Before(cutoff)
+	})
+}
+
+func (f recordingFiles) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" || userID == "." || userID == ".." || strings.ContainsAny(userID, `/\`) {
+		return 0, fmt.Errorf("invalid user id %q", userID)
+	}
+	return f.remove(ctx, filepath.Join(f.dir, "users", userID), func(fs.FileInfo) bool { return true })
+}
+
+// remove deletes the matching files under root that are expired, and any
+// directories left empty.
+func (f recordingFiles) remove(ctx context.Context, root string, expired func(fs.FileInfo) bool) (int, error) {
+	deleted := 0
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != f.dir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if ok, _ := filepath.Match(f.pattern, entry.Name()); !ok {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || !expired(info) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		deleted++
+		return nil
+	})
+	// Deepest first; directories still holding files stay.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return deleted, err
+}
+
+func (d *RecordingDir) path(name string) (string, error) {
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." || strings.Contains(part, `\`) {
+			return "", fmt.Errorf("invalid blob name %q", name)
+		}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
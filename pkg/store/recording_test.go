@@ -0,0 +1,127 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestRecordingDir(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecordingDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := rec.Put(ctx, "s1/20261015T093000Z/0001/timeline.jsonl", []byte("{}\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "s1", "20261015T093000Z", "0001", "timeline.jsonl"))
+	if err != nil || string(data) != "{}\n" {
+		t.Errorf("got %q, %v", data, err)
+	}
+
+	if data, err := rec.Get(ctx, "s1/20261015T093000Z/0001/timeline.jsonl"); err != nil || string(data) != "{}\n" {
+		t.Errorf("got %q, %v", data, err)
+	}
+	if err := rec.Delete(ctx, "s1/20261015T093000Z/0001/timeline.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.Get(ctx, "s1/20261015T093000Z/0001/timeline.jsonl"); !errors.Is(err, orchestrator.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+
+	for _, name := range []string{"../escape.wav", "s1//x.wav", "/abs.wav", `s1\..\x.wav`} {
+		if err := rec.Put(ctx, name, nil); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestRecordingDir_StreamsRecordings(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecordingDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := orchestrator.NewRecorder(orchestrator.NewConversationSession("s1"), 16000, orchestrator.RecordingConfig{Storage: rec})
+	pcm := make([]byte, 3200)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+	r.WriteInbound(pcm)
+	r.WriteInbound(pcm)
+
+	// The track is on disk before the segment ends.
+	matches, _ := filepath.Glob(filepath.Join(dir, "s1", "*", "0001", "inbound.wav"))
+	if len(matches) != 1 {
+		t.Fatalf("expected inbound.wav to be written as it comes, found %v", matches)
+	}
+
+	r.Close()
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, audio.NewWavBuffer(append(pcm, pcm...), 16000)) {
+		t.Errorf("expected a complete WAV file with its sizes patched, got %d bytes", len(data))
+	}
+}
+
+func TestRecordingDir_Purges(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecordingDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, name := range []string{
+		"s1/20261015T093000Z/0001/inbound.wav",
+		"s1/20261015T093000Z/0001/timeline.jsonl",
+		"users/u1/s2/20261015T093000Z/0001/inbound.wav",
+		"users/u1/s3/20261015T093000Z/0001/timeline.jsonl",
+	} {
+		if err := rec.Put(ctx, name, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"inbound.wav", "timeline.jsonl"} {
+		os.Chtimes(filepath.Join(dir, "s1", "20261015T093000Z", "0001", name), old, old)
+	}
+
+	// Audio and transcripts can be kept for different times.
+	if n, err := rec.Matching("*.wav").PurgeBefore(ctx, time.Now().Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected the old track to be purged, got %d, %v", n, err)
+	}
+	if _, err := rec.Get(ctx, "s1/20261015T093000Z/0001/timeline.jsonl"); err != nil {
+		t.Errorf("expected the old timeline to be kept, got %v", err)
+	}
+	if n, err := rec.PurgeBefore(ctx, time.Now().Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected the old timeline to be purged, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "s1")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the emptied session directory to be removed, got %v", err)
+	}
+
+	if n, err := rec.DeleteUser(ctx, "u1"); err != nil || n != 2 {
+		t.Errorf("expected the files of both of u1's sessions to be deleted, got %d, %v", n, err)
+	}
+	if _, err := rec.DeleteUser(ctx, "../u1"); err == nil {
+		t.Error("expected a path to be rejected as a user id")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected the recording directory itself to stay, got %v", err)
+	}
+}
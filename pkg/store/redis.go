@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/resp"
+)
+
+const ownerKeyPrefix = "lokutor:session-owner:"
+
+const (
+	acquireScript = `local cur = redis.call('GET', KEYS[1])
+if cur == false or cur == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0`
+
+	refreshScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+
+	releaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+)
+
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+	// KeyPrefix namespaces keys when several deployments share one Redis.
+	KeyPrefix string
+}
+
+// RedisLocker implements orchestrator.SessionLocker on a single Redis
+// instance. Compare-and-set is done in Lua so an instance can never refresh or
+// release a lease that another instance took over after expiry.
+type RedisLocker struct {
+	client *resp.Client
+	prefix string
+}
+
+func NewRedisLocker(opts RedisOptions) *RedisLocker {
+	return &RedisLocker{
+		client: resp.NewClient(resp.Options{Addr: opts.Addr, Password: opts.Password, DB: opts.DB}),
+		prefix: opts.KeyPrefix + ownerKeyPrefix,
+	}
+}
+
+func (r *RedisLocker) eval(ctx context.Context, script, sessionID string, args ...string) (bool, error) {
+	cmd := append([]string{"EVAL", script, "1", r.prefix + sessionID}, args...)
+	reply, err := r.client.Do(ctx, cmd...)
+	if err != nil {
+		return false, err
+	}
+	n, _ := reply.(int64)
+	return n == 1, nil
+}
+
+func (r *RedisLocker) Acquire(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	return r.eval(ctx, acquireScript, sessionID, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+}
+
+func (r *RedisLocker) Refresh(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	return r.eval(ctx, refreshScript, sessionID, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+}
+
+func (r *RedisLocker) Release(ctx context.Context, sessionID, owner string) error {
+	_, err := r.eval(ctx, releaseScript, sessionID, owner)
+	return err
+}
+
+func (r *RedisLocker) Owner(ctx context.Context, sessionID string) (string, error) {
+	reply, err := r.client.Do(ctx, "GET", r.prefix+sessionID)
+	if errors.Is(err, resp.ErrNil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	owner, _ := reply.(string)
+	return owner, nil
+}
+
+func (r *RedisLocker) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/resp"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const blobKeyPrefix = "lokutor:blob:"
+
+type RedisBlobStoreOptions struct {
+	RedisOptions
+	// TTL expires blobs that long after they were written. Zero keeps them
+	// until deleted.
+	TTL time.Duration
+}
+
+// RedisBlobStore implements orchestrator.BlobStore on a single Redis
+// instance, e.g. as the shared tier of Config.TTSCache.
+type RedisBlobStore struct {
+	client *resp.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisBlobStore(opts RedisBlobStoreOptions) *RedisBlobStore {
+	return &RedisBlobStore{
+		client: resp.NewClient(resp.Options{Addr: opts.Addr, Password: opts.Password, DB: opts.DB}),
+		prefix: opts.KeyPrefix + blobKeyPrefix,
+		ttl:    opts.TTL,
+	}
+}
+
+func (r *RedisBlobStore) Put(ctx context.Context, name string, data []byte) error {
+	cmd := []string{"SET", r.prefix + name, string(data)}
+	if r.ttl > 0 {
+		cmd = append(cmd, "PX", strconv.FormatInt(r.ttl.Milliseconds(), 10))
+	}
+	_, err := r.client.Do(ctx, cmd...)
+	return err
+}
+
+func (r *RedisBlobStore) Get(ctx context.Context, name string) ([]byte, error) {
+	reply, err := r.client.Do(ctx, "GET", r.prefix+name)
+	if errors.Is(err, resp.ErrNil) {
+		return nil, orchestrator.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := reply.(string)
+	return []byte(raw), nil
+}
+
+func (r *RedisBlobStore) Delete(ctx context.Context, name string) error {
+	_, err := r.client.Do(ctx, "DEL", r.prefix+name)
+	return err
+}
+
+func (r *RedisBlobStore) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestRedisBlobStore(t *testing.T) {
+	s := NewRedisBlobStore(RedisBlobStoreOptions{RedisOptions: RedisOptions{Addr: startFakeRedis(t), KeyPrefix: "test:"}, TTL: 30 * time.Millisecond})
+	defer s.Close()
+	ctx := context.Background()
+
+	pcm := []byte{0, 1, 0xff, '\r', '\n', 0x80}
+	if err := s.Put(ctx, "tts/abc.pcm", pcm); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(ctx, "tts/abc.pcm")
+	if err != nil || !bytes.Equal(got, pcm) {
+		t.Errorf("got %v, %v; want %v", got, err, pcm)
+	}
+	if err := s.Delete(ctx, "tts/abc.pcm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "tts/abc.pcm"); !errors.Is(err, orchestrator.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound after delete, got %v", err)
+	}
+
+	s.Put(ctx, "short-lived", pcm)
+	time.Sleep(50 * time.Millisecond)
+	if _, err := s.Get(ctx, "short-lived"); !errors.Is(err, orchestrator.ErrBlobNotFound) {
+		t.Errorf("expected the blob to expire, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/resp"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const (
+	sessionKeyPrefix = "lokutor:session:"
+	sessionIndexKey  = "lokutor:sessions"
+	userKeyPrefix    = "lokutor:user-sessions:"
+)
+
+type RedisStoreOptions struct {
+	RedisOptions
+	// TTL expires snapshots that haven't been saved for that long. Zero keeps
+	// them until deleted.
+	TTL time.Duration
+}
+
+// RedisStore implements orchestrator.SessionStore on a single Redis instance.
+// Each snapshot is one JSON value; a sorted set scored by save time indexes
+// them for List and PurgeBefore, and a set per user for DeleteUser.
+type RedisStore struct {
+	client    *resp.Client
+	prefix    string
+	index     string
+	users     string
+	ttl       time.Duration
+	encryptor orchestrator.Encryptor
+}
+
+func NewRedisStore(opts RedisStoreOptions) *RedisStore {
+	return &RedisStore{
+		client: resp.NewClient(resp.Options{Addr: opts.Addr, Password: opts.Password, DB: opts.DB}),
+		prefix: opts.KeyPrefix + sessionKeyPrefix,
+		index:  opts.KeyPrefix + sessionIndexKey,
+		users:  opts.KeyPrefix + userKeyPrefix,
+		ttl:    opts.TTL,
+	}
+}
+
+// SetEncryptor seals every snapshot written from now on. Plaintext snapshots
+// written earlier remain readable.
+func (r *RedisStore) SetEncryptor(enc orchestrator.Encryptor) {
+	r.encryptor = enc
+}
+
+func (r *RedisStore) Save(ctx context.Context, snapshot orchestrator.SessionSnapshot) error {
+	if snapshot.ID == "" {
+		return errors.New("session snapshot has no id")
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	data, err = orchestrator.SealIfConfigured(ctx, r.encryptor, data)
+	if err != nil {
+		return err
+	}
+
+	cmd := []string{"SET", r.prefix + snapshot.ID, string(data)}
+	if r.ttl > 0 {
+		cmd = append(cmd, "PX", strconv.FormatInt(r.ttl.Milliseconds(), 10))
+	}
+	if _, err := r.client.Do(ctx, cmd...); err != nil {
+		return err
+	}
+	if snapshot.UserID != "" {
+		if _, err := r.client.Do(ctx, "SADD", r.users+snapshot.UserID, snapshot.ID); err != nil {
+			return err
+		}
+	}
+	score := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	_, err = r.client.Do(ctx, "ZADD", r.index, score, snapshot.ID)
+	return err
+}
+
+func (r *RedisStore) Load(ctx context.Context, sessionID string) (orchestrator.SessionSnapshot, error) {
+	reply, err := r.client.Do(ctx, "GET", r.prefix+sessionID)
+	if errors.Is(err, resp.ErrNil) {
+		return orchestrator.SessionSnapshot{}, orchestrator.ErrSessionNotFound
+	}
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+	raw, _ := reply.(string)
+	data, err := orchestrator.OpenIfConfigured(ctx, r.encryptor, []byte(raw))
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+
+	var snap orchestrator.SessionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return orchestrator.SessionSnapshot{}, fmt.Errorf("corrupt session snapshot %s: %w", sessionID, err)
+	}
+	return snap, nil
+}
+
+func (r *RedisStore) Delete(ctx context.Context, sessionID string) error {
+	if _, err := r.client.Do(ctx, "DEL", r.prefix+sessionID); err != nil {
+		return err
+	}
+	_, err := r.client.Do(ctx, "ZREM", r.index, sessionID)
+	return err
+}
+
+// List returns the stored session ids, oldest save first. Index entries for
+// snapshots that expired are dropped on the way.
+func (r *RedisStore) List(ctx context.Context) ([]string, error) {
+	if r.ttl > 0 {
+		cutoff := strconv.FormatInt(time.Now().Add(-r.ttl).UnixMilli(), 10)
+		if _, err := r.client.Do(ctx, "ZREMRANGEBYSCORE", r.index, "-inf", "("+cutoff); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := r.client.Do(ctx, "ZRANGE", r.index, "0", "-1")
+	if err != nil {
+		return nil, err
+	}
+	return stringList(reply), nil
+}
+
+// PurgeBefore removes snapshots that were last saved before cutoff.
+func (r *RedisStore) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	reply, err := r.client.Do(ctx, "ZRANGEBYSCORE", r.index, "-inf", "("+strconv.FormatInt(cutoff.UnixMilli(), 10))
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, id := range stringList(reply) {
+		if err := r.Delete(ctx, id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DeleteUser removes every snapshot of a session with userID. The user's set
+// can still list sessions since deleted or saved for someone else; those are
+// left alone.
+func (r *RedisStore) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, errNoUserID
+	}
+	reply, err := r.client.Do(ctx, "SMEMBERS", r.users+userID)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, id := range stringList(reply) {
+		snap, err := r.Load(ctx, id)
+		if errors.Is(err, orchestrator.ErrSessionNotFound) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		if snap.UserID != userID {
+			continue
+		}
+		if err := r.Delete(ctx, id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	_, err = r.client.Do(ctx, "DEL", r.users+userID)
+	return deleted, err
+}
+
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
+
+func stringList(reply interface{}) []string {
+	items, _ := reply.([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestRedisStore(t *testing.T) {
+	s := NewRedisStore(RedisStoreOptions{RedisOptions: RedisOptions{Addr: startFakeRedis(t), KeyPrefix: "test:"}})
+	defer s.Close()
+	ctx := context.Background()
+
+	snap := orchestrator.SessionSnapshot{ID: "call-1", Context: []orchestrator.Message{{Role: "user", Content: "hi"}}}
+	if err := s.Save(ctx, snap); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-2", UserID: "u1"})
+
+	got, err := s.Load(ctx, "call-1")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(got.Context) != 1 || got.Context[0].Content != "hi" {
+		t.Errorf("unexpected snapshot: %+v", got)
+	}
+
+	ids, _ := s.List(ctx)
+	if len(ids) != 2 || ids[0] != "call-1" || ids[1] != "call-2" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	if err := s.Delete(ctx, "call-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(ctx, "call-1"); !errors.Is(err, orchestrator.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-3", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-4", UserID: "u2"})
+	// Saved again for someone else; u1's set still lists it.
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-4", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-4", UserID: "u2"})
+	if n, _ := s.DeleteUser(ctx, "u1"); n != 2 {
+		t.Errorf("expected both of u1's snapshots deleted, got %d", n)
+	}
+	if ids, _ := s.List(ctx); len(ids) != 1 || ids[0] != "call-4" {
+		t.Errorf("expected only u2's snapshot left, got %v", ids)
+	}
+	s.Delete(ctx, "call-4")
+	if ids, _ := s.List(ctx); len(ids) != 0 {
+		t.Errorf("expected empty index, got %v", ids)
+	}
+}
+
+func TestRedisStore_TTLAndPurge(t *testing.T) {
+	s := NewRedisStore(RedisStoreOptions{RedisOptions: RedisOptions{Addr: startFakeRedis(t)}, TTL: 30 * time.Millisecond})
+	defer s.Close()
+	ctx := context.Background()
+
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "old"})
+	time.Sleep(50 * time.Millisecond)
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "new"})
+
+	if _, err := s.Load(ctx, "old"); !errors.Is(err, orchestrator.ErrSessionNotFound) {
+		t.Errorf("expected expired snapshot to be gone, got %v", err)
+	}
+	if ids, _ := s.List(ctx); len(ids) != 1 || ids[0] != "new" {
+		t.Errorf("expected only the fresh snapshot listed, got %v", ids)
+	}
+
+	if n, err := s.PurgeBefore(ctx, time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Errorf("expected one snapshot purged, got %d (%v)", n, err)
+	}
+}
+
+func TestRedisStore_Encrypted(t *testing.T) {
+	addr := startFakeRedis(t)
+	s := NewRedisStore(RedisStoreOptions{RedisOptions: RedisOptions{Addr: addr}})
+	defer s.Close()
+	enc, err := orchestrator.NewAESGCMEncryptorFromKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetEncryptor(enc)
+
+	ctx := context.Background()
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "secret", LastUser: "my card number"})
+
+	raw, err := s.client.Do(ctx, "GET", s.prefix+"secret")
+	if err != nil || !orchestrator.IsEncrypted([]byte(raw.(string))) {
+		t.Fatal("expected snapshot to be encrypted at rest")
+	}
+	got, err := s.Load(ctx, "secret")
+	if err != nil || got.LastUser != "my card number" {
+		t.Errorf("expected decrypted snapshot, got %+v (%v)", got, err)
+	}
+}
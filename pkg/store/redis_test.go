@@ -0,0 +1,246 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/resp"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// fakeRedis understands the handful of commands and Lua scripts RedisLocker
+// and RedisStore use, enough to exercise them without a real server.
+type fakeRedis struct {
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	zsets   map[string]map[string]float64
+	sets    map[string]map[string]bool
+}
+
+func startFakeRedis(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeRedis{values: map[string]string{}, expires: map[string]time.Time{}, zsets: map[string]map[string]float64{}, sets: map[string]map[string]bool{}}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (f *fakeRedis) get(key string) (string, bool) {
+	if exp, ok := f.expires[key]; ok && time.Now().After(exp) {
+		delete(f.values, key)
+		delete(f.expires, key)
+	}
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// zrange returns the members in order of score. The score commands only
+// support the "-inf" / "(max" form RedisStore sends.
+func (f *fakeRedis) zrange(cmd, key string, args []string) []string {
+	members := make([]string, 0, len(f.zsets[key]))
+	for m, score := range f.zsets[key] {
+		if cmd != "ZRANGE" {
+			max, _ := strconv.ParseFloat(strings.TrimPrefix(args[1], "("), 64)
+			if score >= max {
+				continue
+			}
+		}
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return f.zsets[key][members[i]] < f.zsets[key][members[j]]
+	})
+	return members
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	rd := bufio.NewReader(c)
+	for {
+		cmd, err := resp.ReadReply(rd)
+		if err != nil {
+			return
+		}
+		raw := cmd.([]interface{})
+		args := make([]string, len(raw))
+		for i, a := range raw {
+			args[i] = a.(string)
+		}
+
+		f.mu.Lock()
+		var reply string
+		switch args[0] {
+		case "GET":
+			if v, ok := f.get(args[1]); ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			f.values[args[1]] = args[2]
+			delete(f.expires, args[1])
+			if len(args) == 5 && args[3] == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			reply = "+OK\r\n"
+		case "DEL":
+			n := 0
+			if _, ok := f.get(args[1]); ok {
+				delete(f.values, args[1])
+				delete(f.expires, args[1])
+				n = 1
+			}
+			if _, ok := f.sets[args[1]]; ok {
+				delete(f.sets, args[1])
+				n = 1
+			}
+			reply = fmt.Sprintf(":%d\r\n", n)
+		case "SADD":
+			if f.sets[args[1]] == nil {
+				f.sets[args[1]] = map[string]bool{}
+			}
+			f.sets[args[1]][args[2]] = true
+			reply = ":1\r\n"
+		case "SMEMBERS":
+			reply = fmt.Sprintf("*%d\r\n", len(f.sets[args[1]]))
+			for m := range f.sets[args[1]] {
+				reply += fmt.Sprintf("$%d\r\n%s\r\n", len(m), m)
+			}
+		case "ZADD":
+			if f.zsets[args[1]] == nil {
+				f.zsets[args[1]] = map[string]float64{}
+			}
+			score, _ := strconv.ParseFloat(args[2], 64)
+			f.zsets[args[1]][args[3]] = score
+			reply = ":1\r\n"
+		case "ZREM":
+			delete(f.zsets[args[1]], args[2])
+			reply = ":1\r\n"
+		case "ZRANGE", "ZRANGEBYSCORE", "ZREMRANGEBYSCORE":
+			members := f.zrange(args[0], args[1], args[2:])
+			if args[0] == "ZREMRANGEBYSCORE" {
+				for _, m := range members {
+					delete(f.zsets[args[1]], m)
+				}
+				reply = fmt.Sprintf(":%d\r\n", len(members))
+				break
+			}
+			reply = fmt.Sprintf("*%d\r\n", len(members))
+			for _, m := range members {
+				reply += fmt.Sprintf("$%d\r\n%s\r\n", len(m), m)
+			}
+		case "EVAL":
+			key, owner := args[3], args[4]
+			cur, exists := f.get(key)
+			result := 0
+			switch args[1] {
+			case acquireScript:
+				if !exists || cur == owner {
+					ms, _ := strconv.Atoi(args[5])
+					f.values[key] = owner
+					f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+					result = 1
+				}
+			case refreshScript:
+				if exists && cur == owner {
+					ms, _ := strconv.Atoi(args[5])
+					f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+					result = 1
+				}
+			case releaseScript:
+				if exists && cur == owner {
+					delete(f.values, key)
+					delete(f.expires, key)
+					result = 1
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", result)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		c.Write([]byte(reply))
+	}
+}
+
+func TestRedisLocker(t *testing.T) {
+	locker := NewRedisLocker(RedisOptions{Addr: startFakeRedis(t)})
+	defer locker.Close()
+	ctx := context.Background()
+
+	if ok, err := locker.Acquire(ctx, "call-1", "pod-a", time.Minute); err != nil || !ok {
+		t.Fatalf("expected pod-a to acquire, got %v (%v)", ok, err)
+	}
+	if ok, _ := locker.Acquire(ctx, "call-1", "pod-b", time.Minute); ok {
+		t.Error("pod-b must not acquire a session owned by pod-a")
+	}
+	if owner, _ := locker.Owner(ctx, "call-1"); owner != "pod-a" {
+		t.Errorf("expected owner pod-a, got %q", owner)
+	}
+	if ok, _ := locker.Refresh(ctx, "call-1", "pod-b", time.Minute); ok {
+		t.Error("pod-b must not refresh pod-a's lease")
+	}
+
+	locker.Release(ctx, "call-1", "pod-b")
+	if owner, _ := locker.Owner(ctx, "call-1"); owner != "pod-a" {
+		t.Error("releasing someone else's lease must be a no-op")
+	}
+	locker.Release(ctx, "call-1", "pod-a")
+	if owner, _ := locker.Owner(ctx, "call-1"); owner != "" {
+		t.Errorf("expected no owner, got %q", owner)
+	}
+
+	locker.Acquire(ctx, "call-2", "pod-a", 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	if ok, _ := locker.Acquire(ctx, "call-2", "pod-b", time.Minute); !ok {
+		t.Error("expected an expired lease to be taken over")
+	}
+}
+
+func TestSessionLeaseWithRedis(t *testing.T) {
+	locker := NewRedisLocker(RedisOptions{Addr: startFakeRedis(t)})
+	defer locker.Close()
+	ctx := context.Background()
+
+	lease, err := orchestrator.AcquireSessionLease(ctx, locker, "call-3", "pod-a", 60*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if owner, _ := locker.Owner(ctx, "call-3"); !strings.HasPrefix(owner, "pod-a/") {
+		t.Fatalf("expected the lease to be kept alive, got owner %q", owner)
+	}
+	if _, err := orchestrator.AcquireSessionLease(ctx, locker, "call-3", "pod-b", time.Minute); err != orchestrator.ErrSessionOwned {
+		t.Errorf("expected ErrSessionOwned, got %v", err)
+	}
+	// Each connection holds its own lease, even on the same instance.
+	if _, err := orchestrator.AcquireSessionLease(ctx, locker, "call-3", "pod-a", time.Minute); err != orchestrator.ErrSessionOwned {
+		t.Errorf("expected a second lease from pod-a to be refused, got %v", err)
+	}
+	lease.Release(ctx)
+	if owner, _ := locker.Owner(ctx, "call-3"); owner != "" {
+		t.Error("expected release to clear ownership")
+	}
+}
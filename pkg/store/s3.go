@@ -0,0 +1,168 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/internal/awsv4"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// S3Storage is a BlobStore of objects in an S3 bucket, or in an
+// S3-compatible service with SetEndpoint. Retention can be left to a
+// lifecycle rule on the bucket, or applied through Matching.
+type S3Storage struct {
+	bucket   string
+	region   string
+	endpoint string
+	prefix   string
+	creds    awsv4.Credentials
+	client   *http.Client
+}
+
+// NewS3Storage stores objects in bucket, signed with the AWS credentials in
+// the environment.
+func NewS3Storage(bucket, region string) *S3Storage {
+	return &S3Storage{
+		bucket:   bucket,
+		region:   region,
+		endpoint: "https://" + bucket + ".s3." + region + ".amazonaws.com",
+		creds:    awsv4.CredentialsFromEnv(),
+		client:   http.DefaultClient,
+	}
+}
+
+func (s *S3Storage) SetCredentials(accessKeyID, secretAccessKey, sessionToken string) {
+	s.creds = awsv4.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}
+}
+
+// SetEndpoint addresses an S3-compatible service by path, as
+// endpoint/bucket/key.
+func (s *S3Storage) SetEndpoint(endpoint string) {
+	s.endpoint = strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(s.bucket)
+}
+
+// SetPrefix puts every object under prefix, e.g. "debug/".
+func (s *S3Storage) SetPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+func (s *S3Storage) Put(ctx context.Context, name string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, name, nil)
+	if errors.Is(err, orchestrator.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List returns the objects whose names start with prefix, which is relative
+// to the storage's own prefix like every name.
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+	q := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+	for {
+		resp, err := s.send(ctx, http.MethodGet, s.endpoint+"?"+q.Encode(), "list "+prefix, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key          string
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list %s: %w", prefix, err)
+		}
+		for _, obj := range page.Contents {
+			blobs = append(blobs, BlobInfo{Name: strings.TrimPrefix(obj.Key, s.prefix), Modified: obj.LastModified})
+		}
+		if !page.IsTruncated {
+			return blobs, nil
+		}
+		q.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+// Matching narrows retention to objects whose base name matches pattern
+// (see path.Match), like RecordingDir.Matching.
+func (s *S3Storage) Matching(pattern string) orchestrator.Purgeable {
+	return blobObjects{store: s, pattern: pattern}
+}
+
+// do sends a signed request for the object name. Other error statuses are
+// returned as errors, 404 as ErrBlobNotFound.
+func (s *S3Storage) do(ctx context.Context, method, name string, data []byte) (*http.Response, error) {
+	parts := strings.Split(s.prefix+name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return s.send(ctx, method, s.endpoint+"/"+strings.Join(parts, "/"), name, data)
+}
+
+func (s *S3Storage) send(ctx context.Context, method, u, name string, data []byte) (*http.Response, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := awsv4.Sign(req, s.creds, s.region, "s3", time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, orchestrator.ErrBlobNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("s3 %s %s (status %d): %s", strings.ToLower(method), name, resp.StatusCode, string(respBody))
+	}
+	return resp, nil
+}
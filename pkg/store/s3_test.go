@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestS3Storage_Put(t *testing.T) {
+	var path, auth, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected PUT, got %s", r.Method)
+		}
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	s := NewS3Storage("captures", "eu-west-1")
+	s.SetCredentials("AKID", "secret", "")
+	s.SetEndpoint(server.URL)
+	s.SetPrefix("debug/")
+	if err := s.Put(context.Background(), "s1/t1/user.wav", []byte("RIFF")); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/captures/debug/s1/t1/user.wav" || body != "RIFF" {
+		t.Errorf("unexpected object %s: %q", path, body)
+	}
+	if !strings.Contains(auth, "Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("unexpected signature %q", auth)
+	}
+}
+
+func TestS3Storage_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s := NewS3Storage("captures", "eu-west-1")
+	s.SetCredentials("AKID", "secret", "")
+	s.SetEndpoint(server.URL)
+	if err := s.Put(context.Background(), "x.wav", nil); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+}
+
+func TestS3Storage_GetAndDelete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/captures/s1/t1/user.wav":
+			http.NotFound(w, r)
+		case r.Method == http.MethodGet:
+			w.Write([]byte("RIFF"))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s := NewS3Storage("captures", "eu-west-1")
+	s.SetCredentials("AKID", "secret", "")
+	s.SetEndpoint(server.URL)
+	ctx := context.Background()
+	if data, err := s.Get(ctx, "s1/t1/user.wav"); err != nil || string(data) != "RIFF" {
+		t.Errorf("got %q, %v", data, err)
+	}
+	if _, err := s.Get(ctx, "missing.wav"); !errors.Is(err, orchestrator.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+	if err := s.Delete(ctx, "s1/t1/user.wav"); err != nil {
+		t.Error(err)
+	}
+	if err := s.Delete(ctx, "missing.wav"); err != nil {
+		t.Errorf("expected deleting a missing object to succeed, got %v", err)
+	}
+}
+
+func TestS3Storage_Matching(t *testing.T) {
+	modified := map[string]time.Time{
+		"recordings/users/u1/s1/caller.wav":     time.Now(),
+		"recordings/users/u1/s1/timeline.jsonl": time.Now(),
+		"recordings/s2/caller.wav":              time.Now().Add(-48 * time.Hour),
+		"recordings/s3/caller.wav":              time.Now(),
+	}
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/captures":
+			// One object per page, to exercise continuation.
+			var keys []string
+			for key := range modified {
+				if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			start := 0
+			if token := r.URL.Query().Get("continuation-token"); token != "" {
+				start, _ = strconv.Atoi(token)
+			}
+			fmt.Fprint(w, "<ListBucketResult>")
+			if start < len(keys) {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><LastModified>%s</LastModified></Contents>", keys[start], modified[keys[start]].UTC().Format(time.RFC3339))
+			}
+			if start+1 < len(keys) {
+				fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", start+1)
+			}
+			fmt.Fprint(w, "</ListBucketResult>")
+		case r.Method == http.MethodDelete:
+			key := strings.TrimPrefix(r.URL.Path, "/captures/")
+			deleted = append(deleted, key)
+			delete(modified, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	s := NewS3Storage("captures", "eu-west-1")
+	s.SetCredentials("AKID", "secret", "")
+	s.SetEndpoint(server.URL)
+	s.SetPrefix("recordings/")
+	ctx := context.Background()
+
+	if n, err := s.Matching("*.wav").DeleteUser(ctx, "u1"); err != nil || n != 1 || deleted[0] != "recordings/users/u1/s1/caller.wav" {
+		t.Errorf("expected u1's recording deleted, got %d %v (%v)", n, deleted, err)
+	}
+	if n, err := s.Matching("*.wav").PurgeBefore(ctx, time.Now().Add(-24*time.Hour)); err != nil || n != 1 || deleted[1] != "recordings/s2/caller.wav" {
+		t.Errorf("expected the old recording purged, got %d %v (%v)", n, deleted, err)
+	}
+	if len(modified) != 2 {
+		t.Errorf("expected the timeline and the recent recording kept, got %v", modified)
+	}
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL,
+	saved_at INTEGER NOT NULL,
+	user_id TEXT NOT NULL DEFAULT ''
+)`
+
+var sqliteTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteStore keeps session snapshots in a SQLite table. It takes an open
+// *sql.DB so callers pick the driver (mattn/go-sqlite3, modernc.org/sqlite).
+type SQLiteStore struct {
+	db        *sql.DB
+	table     string
+	encryptor orchestrator.Encryptor
+}
+
+// NewSQLiteStore creates the lokutor_sessions table if it doesn't exist.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	return NewSQLiteStoreTable(ctx, db, "lokutor_sessions")
+}
+
+// NewSQLiteStoreTable keeps snapshots in table instead, e.g. to keep
+// checkpoints apart from handoff snapshots in one database.
+func NewSQLiteStoreTable(ctx context.Context, db *sql.DB, table string) (*SQLiteStore, error) {
+	if !sqliteTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(sqliteSchema, table)); err != nil {
+		return nil, err
+	}
+	s := &SQLiteStore{db: db, table: table}
+	if err := s.addUserColumn(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addUserColumn upgrades tables created before snapshots carried a user.
+func (s *SQLiteStore) addUserColumn(ctx context.Context) error {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = 'user_id'`, s.table).Scan(&n)
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.query(`ALTER TABLE %s ADD COLUMN user_id TEXT NOT NULL DEFAULT ''`))
+	return err
+}
+
+// query names the store's table in q.
+func (s *SQLiteStore) query(q string) string {
+	return fmt.Sprintf(q, s.table)
+}
+
+// SetEncryptor seals every snapshot written from now on. Plaintext snapshots
+// written earlier remain readable.
+func (s *SQLiteStore) SetEncryptor(enc orchestrator.Encryptor) {
+	s.encryptor = enc
+}
+
+func (s *SQLiteStore) Save(ctx context.Context, snapshot orchestrator.SessionSnapshot) error {
+	if snapshot.ID == "" {
+		return errors.New("session snapshot has no id")
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	data, err = orchestrator.SealIfConfigured(ctx, s.encryptor, data)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		s.query(`INSERT OR REPLACE INTO %s (id, data, saved_at, user_id) VALUES (?, ?, ?, ?)`),
+		snapshot.ID, data, time.Now().UnixMilli(), snapshot.UserID)
+	return err
+}
+
+func (s *SQLiteStore) Load(ctx context.Context, sessionID string) (orchestrator.SessionSnapshot, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, s.query(`SELECT data FROM %s WHERE id = ?`), sessionID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return orchestrator.SessionSnapshot{}, orchestrator.ErrSessionNotFound
+	}
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+	data, err = orchestrator.OpenIfConfigured(ctx, s.encryptor, data)
+	if err != nil {
+		return orchestrator.SessionSnapshot{}, err
+	}
+
+	var snap orchestrator.SessionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return orchestrator.SessionSnapshot{}, fmt.Errorf("corrupt session snapshot %s: %w", sessionID, err)
+	}
+	return snap, nil
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, sessionID string) error {
+	_, err := s.db.ExecContext(ctx, s.query(`DELETE FROM %s WHERE id = ?`), sessionID)
+	return err
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(`SELECT id FROM %s ORDER BY id`))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// PurgeBefore removes snapshots that were last saved before cutoff.
+func (s *SQLiteStore) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, s.query(`DELETE FROM %s WHERE saved_at < ?`), cutoff.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// DeleteUser removes every snapshot of a session with userID.
+func (s *SQLiteStore) DeleteUser(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, errNoUserID
+	}
+	res, err := s.db.ExecContext(ctx, s.query(`DELETE FROM %s WHERE user_id = ?`), userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSQLiteStore(ctx, openSQLite(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap := orchestrator.SessionSnapshot{ID: "call-1", Context: []orchestrator.Message{{Role: "user", Content: "hi"}}}
+	if err := s.Save(ctx, snap); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	snap.Context = append(snap.Context, orchestrator.Message{Role: "assistant", Content: "hello"})
+	if err := s.Save(ctx, snap); err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-2"})
+
+	got, err := s.Load(ctx, "call-1")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(got.Context) != 2 || got.Context[1].Content != "hello" {
+		t.Errorf("unexpected snapshot: %+v", got)
+	}
+
+	ids, _ := s.List(ctx)
+	if len(ids) != 2 || ids[0] != "call-1" || ids[1] != "call-2" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	if err := s.Delete(ctx, "call-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(ctx, "call-1"); !errors.Is(err, orchestrator.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if n, err := s.PurgeBefore(ctx, time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Errorf("expected one snapshot purged, got %d (%v)", n, err)
+	}
+}
+
+func TestSQLiteStore_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	// A table from before snapshots carried a user.
+	if _, err := db.ExecContext(ctx, `CREATE TABLE lokutor_sessions (id TEXT PRIMARY KEY, data BLOB NOT NULL, saved_at INTEGER NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-1", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-2", UserID: "u1"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-3", UserID: "u2"})
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-4"})
+
+	if n, err := s.DeleteUser(ctx, "u1"); err != nil || n != 2 {
+		t.Errorf("expected both of u1's snapshots deleted, got %d (%v)", n, err)
+	}
+	if _, err := s.DeleteUser(ctx, ""); err == nil {
+		t.Error("expected an empty user id to be rejected")
+	}
+	if ids, _ := s.List(ctx); len(ids) != 2 || ids[0] != "call-3" || ids[1] != "call-4" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+}
+
+func TestSQLiteStore_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	db, _ := sql.Open("sqlite", path)
+	s, err := NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Save(ctx, orchestrator.SessionSnapshot{ID: "call-1", LastUser: "still here"})
+	db.Close()
+
+	db, _ = sql.Open("sqlite", path)
+	defer db.Close()
+	s, err = NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load(ctx, "call-1")
+	if err != nil || got.LastUser != "still here" {
+		t.Errorf("expected snapshot after reopen, got %+v (%v)", got, err)
+	}
+}
+
+func TestSQLiteStore_Table(t *testing.T) {
+	ctx := context.Background()
+	db, _ := sql.Open("sqlite", filepath.Join(t.TempDir(), "sessions.db"))
+	defer db.Close()
+
+	sessions, err := NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoints, err := NewSQLiteStoreTable(ctx, db, "lokutor_checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoints.Save(ctx, orchestrator.SessionSnapshot{ID: "call-1"})
+	if _, err := sessions.Load(ctx, "call-1"); !errors.Is(err, orchestrator.ErrSessionNotFound) {
+		t.Errorf("expected the tables to be apart, got %v", err)
+	}
+	if _, err := checkpoints.Load(ctx, "call-1"); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSQLiteStoreTable(ctx, db, "x; DROP TABLE lokutor_sessions"); err == nil {
+		t.Error("expected an invalid table name to be rejected")
+	}
+}
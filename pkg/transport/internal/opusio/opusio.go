@@ -0,0 +1,166 @@
+// Package opusio adapts a ManagedStream's PCM to the 48kHz Opus used by
+// WebRTC based transports.
+package opusio
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+)
+
+const (
+	SampleRate    = 48000
+	FrameDuration = 20 * time.Millisecond
+	frameBytes    = SampleRate / 50 * 2
+)
+
+// Receiver decodes inbound Opus packets to mono PCM at the stream's rate.
+type Receiver struct {
+	dec audio.OpusDecoder
+	rs  *resample.Resampler
+}
+
+func NewReceiver(codec audio.OpusCodec, outRate int) (*Receiver, error) {
+	if codec == nil {
+		return nil, audio.ErrNoOpusCodec
+	}
+	dec, err := codec.NewDecoder(SampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{dec: dec, rs: resample.NewResampler(SampleRate, outRate)}, nil
+}
+
+func (r *Receiver) Decode(packet []byte) ([]byte, error) {
+	pcm, err := r.dec.Decode(packet)
+	if err != nil {
+		return nil, err
+	}
+	return r.rs.Process(pcm), nil
+}
+
+// Sender encodes TTS audio into 20ms Opus frames and writes them in real
+// time. RTP has no backpressure, so bursting a whole response would overflow
+// the receiver's jitter buffer; pacing also lets Clear drop unplayed audio on
+// barge-in.
+type Sender struct {
+	write  func(frame []byte, duration time.Duration) error
+	played func()
+
+	mu     sync.Mutex
+	enc    audio.OpusEncoder
+	rs     *resample.Resampler
+	framer *audio.Framer
+	queue  [][]byte // PCM frames, encoded when sent so SetGain reaches them
+	gain   float64
+	// flush is set while the resampler may hold back audio.
+	flush bool
+}
+
+func NewSender(codec audio.OpusCodec, inRate int, write func(frame []byte, duration time.Duration) error, played func()) (*Sender, error) {
+	if codec == nil {
+		return nil, audio.ErrNoOpusCodec
+	}
+	enc, err := codec.NewEncoder(SampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	if played == nil {
+		played = func() {}
+	}
+	return &Sender{
+		write:  write,
+		played: played,
+		enc:    enc,
+		rs:     resample.NewResampler(inRate, SampleRate),
+		framer: audio.NewFramer(frameBytes),
+		gain:   1,
+	}, nil
+}
+
+// Push queues PCM at the sender's input rate.
+func (s *Sender) Push(pcm []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, s.framer.Push(s.rs.Process(pcm))...)
+	s.flush = true
+	return nil
+}
+
+// SetGain scales audio that has not been sent yet, including what is already
+// queued. It is used to duck the agent during a tentative barge-in.
+func (s *Sender) SetGain(gain float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gain = gain
+}
+
+// Clear drops everything that has not been sent yet and restores full gain.
+func (s *Sender) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = nil
+	s.gain = 1
+	s.framer.Reset()
+	s.rs.Reset()
+	s.flush = false
+}
+
+func (s *Sender) Queued() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(len(s.queue)) * FrameDuration
+}
+
+// next returns the frame due now. Once the queue runs dry what the resampler
+// holds back and the partial frame left in the framer are padded and sent, so
+// responses are not clipped.
+func (s *Sender) next() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 && s.flush {
+		s.queue = s.framer.Push(s.rs.Flush())
+		s.flush = false
+	}
+	if len(s.queue) == 0 {
+		if frame := s.framer.Flush(); frame != nil {
+			s.queue = append(s.queue, frame)
+		}
+	}
+	if len(s.queue) == 0 {
+		return nil, nil
+	}
+	frame := s.queue[0]
+	s.queue = s.queue[1:]
+	if s.gain != 1 {
+		frame = audio.ApplyGain(frame, s.gain)
+	}
+	return s.enc.Encode(frame)
+}
+
+// Run sends one frame every 20ms until ctx is done or a write fails.
+func (s *Sender) Run(ctx context.Context) error {
+	ticker := time.NewTicker(FrameDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			packet, err := s.next()
+			if err != nil {
+				return err
+			}
+			if packet == nil {
+				continue
+			}
+			if err := s.write(packet, FrameDuration); err != nil {
+				return err
+			}
+			s.played()
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package opusio
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// passthroughCodec "encodes" PCM as itself so tests can count frames.
+type passthroughCodec struct{}
+
+func (passthroughCodec) NewEncoder(sampleRate, channels int) (audio.OpusEncoder, error) {
+	return passthrough{}, nil
+}
+func (passthroughCodec) NewDecoder(sampleRate, channels int) (audio.OpusDecoder, error) {
+	return passthrough{}, nil
+}
+
+type passthrough struct{}
+
+func (passthrough) Encode(pcm []byte) ([]byte, error)    { return pcm, nil }
+func (passthrough) Decode(packet []byte) ([]byte, error) { return packet, nil }
+
+func TestSender_PacesAndFlushes(t *testing.T) {
+	var mu sync.Mutex
+	var frames [][]byte
+	played := 0
+	s, err := NewSender(passthroughCodec{}, SampleRate, func(frame []byte, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if d != FrameDuration {
+			t.Errorf("unexpected duration %v", d)
+		}
+		frames = append(frames, frame)
+		return nil
+	}, func() {
+		mu.Lock()
+		played++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two and a half frames: the half is padded once the queue drains.
+	s.Push(make([]byte, frameBytes*5/2))
+	if s.Queued() != 2*FrameDuration {
+		t.Fatalf("expected 2 queued frames, got %v", s.Queued())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	s.Run(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(frames) != 3 || played != 3 {
+		t.Fatalf("expected 3 frames played, got %d (%d)", len(frames), played)
+	}
+	if len(frames[2]) != frameBytes {
+		t.Errorf("expected padded final frame, got %d bytes", len(frames[2]))
+	}
+	if time.Since(start) < 3*FrameDuration {
+		t.Error("frames were not paced")
+	}
+}
+
+func TestSender_Clear(t *testing.T) {
+	s, _ := NewSender(passthroughCodec{}, SampleRate, func([]byte, time.Duration) error { return nil }, nil)
+	s.Push(make([]byte, frameBytes*3+10))
+	s.Clear()
+	if s.Queued() != 0 {
+		t.Errorf("expected empty queue after clear, got %v", s.Queued())
+	}
+	if packet, _ := s.next(); packet != nil {
+		t.Error("expected cleared partial frame not to be flushed")
+	}
+}
+
+func TestSender_StopsOnWriteError(t *testing.T) {
+	boom := errors.New("track closed")
+	s, _ := NewSender(passthroughCodec{}, SampleRate, func([]byte, time.Duration) error { return boom }, nil)
+	s.Push(make([]byte, frameBytes))
+	if err := s.Run(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("expected write error, got %v", err)
+	}
+}
+
+func TestReceiver_Resamples(t *testing.T) {
+	r, err := NewReceiver(passthroughCodec{}, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The filter holds back a millisecond or so of each stream.
+	n := 0
+	for i := 0; i < 5; i++ {
+		pcm, err := r.Decode(make([]byte, frameBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += len(pcm) / 2
+	}
+	if n < 1560 || n > 1600 {
+		t.Errorf("expected ~1600 samples at 16kHz, got %d", n)
+	}
+}
+
+func TestNoCodec(t *testing.T) {
+	if _, err := NewReceiver(nil, 16000); !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec, got %v", err)
+	}
+	if _, err := NewSender(nil, 16000, nil, nil); !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec, got %v", err)
+	}
+}
+
+func TestSender_GainReachesQueuedAudio(t *testing.T) {
+	s, _ := NewSender(passthroughCodec{}, SampleRate, func([]byte, time.Duration) error { return nil }, nil)
+	loud := make([]byte, frameBytes)
+	for i := 0; i < len(loud); i += 2 {
+		loud[i+1] = 0x40 // 16384
+	}
+	s.Push(loud)
+	s.Push(loud)
+
+	s.SetGain(0.5)
+	packet, _ := s.next()
+	if got := int16(uint16(packet[0]) | uint16(packet[1])<<8); got != 8192 {
+		t.Errorf("expected queued frame to be ducked to 8192, got %d", got)
+	}
+	s.Clear()
+	s.Push(loud)
+	packet, _ = s.next()
+	if got := int16(uint16(packet[0]) | uint16(packet[1])<<8); got != 16384 {
+		t.Errorf("expected Clear to restore full gain, got %d", got)
+	}
+}
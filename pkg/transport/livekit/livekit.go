@@ -0,0 +1,252 @@
+package livekit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/transport/internal/opusio"
+)
+
+// Options configure an Agent. Either Token, or APIKey and APISecret together
+// with RoomName, must be set.
+type Options struct {
+	URL       string
+	Token     string
+	APIKey    string
+	APISecret string
+	RoomName  string
+	Identity  string
+
+	SystemPrompt string
+	// Participant limits the agent to one remote identity. When empty, the
+	// agent listens to the first participant that publishes audio and moves
+	// on to the next one when that participant leaves.
+	Participant string
+	// Codec defaults to audio.DefaultOpusCodec.
+	Codec  audio.OpusCodec
+	Logger orchestrator.Logger
+}
+
+// Agent is a LiveKit participant backed by a ManagedStream: remote audio is
+// decoded into the stream and TTS audio is published as the agent's track.
+type Agent struct {
+	orch   *orchestrator.Orchestrator
+	opts   Options
+	room   *lksdk.Room
+	stream *orchestrator.ManagedStream
+	sender *opusio.Sender
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// ready is closed once stream and sender exist; tracks can be
+	// subscribed while Join is still running.
+	ready chan struct{}
+
+	mu       sync.Mutex
+	listenTo string
+}
+
+// Join connects to the room, publishes the agent's audio track and starts
+// the conversation. The agent leaves when ctx is done or Close is called.
+func Join(ctx context.Context, orch *orchestrator.Orchestrator, opts Options) (*Agent, error) {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.Codec == nil {
+		opts.Codec = audio.DefaultOpusCodec
+	}
+	if opts.Codec == nil {
+		return nil, audio.ErrNoOpusCodec
+	}
+	if opts.Identity == "" {
+		opts.Identity = "lokutor-agent"
+	}
+
+	actx, cancel := context.WithCancel(ctx)
+	a := &Agent{orch: orch, opts: opts, ctx: actx, cancel: cancel, ready: make(chan struct{})}
+
+	callback := lksdk.NewRoomCallback()
+	callback.OnTrackSubscribed = a.onTrackSubscribed
+	callback.OnParticipantDisconnected = a.onParticipantDisconnected
+	callback.OnDisconnected = cancel
+
+	var err error
+	if opts.Token != "" {
+		a.room, err = lksdk.ConnectToRoomWithToken(opts.URL, opts.Token, callback)
+	} else {
+		a.room, err = lksdk.ConnectToRoom(opts.URL, lksdk.ConnectInfo{
+			APIKey:              opts.APIKey,
+			APISecret:           opts.APISecret,
+			RoomName:            opts.RoomName,
+			ParticipantIdentity: opts.Identity,
+			ParticipantKind:     lksdk.ParticipantAgent,
+		}, callback)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	track, err := lksdk.NewLocalSampleTrack(webrtc.RTPCodecCapability{
+		MimeType:  webrtc.MimeTypeOpus,
+		ClockRate: opusio.SampleRate,
+		Channels:  2,
+	})
+	if err != nil {
+		a.room.Disconnect()
+		cancel()
+		return nil, err
+	}
+	if _, err := a.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{Name: opts.Identity}); err != nil {
+		a.room.Disconnect()
+		cancel()
+		return nil, err
+	}
+
+	session := orch.NewSessionWithDefaults(a.room.Name())
+	if opts.SystemPrompt != "" {
+		orch.SetSystemPrompt(session, opts.SystemPrompt)
+	}
+	a.stream = orch.NewManagedStream(actx, session)
+
+	a.sender, err = opusio.NewSender(opts.Codec, orch.GetConfig().SampleRate, func(frame []byte, d time.Duration) error {
+		return track.WriteSample(media.Sample{Data: frame, Duration: d}, nil)
+	}, a.stream.NotifyAudioPlayed)
+	if err != nil {
+		a.stream.Close()
+		a.room.Disconnect()
+		cancel()
+		return nil, err
+	}
+	close(a.ready)
+
+	go func() {
+		if err := a.sender.Run(actx); err != nil && actx.Err() == nil {
+			opts.Logger.Error("livekit audio track failed", "room", a.room.Name(), "error", err)
+			cancel()
+		}
+	}()
+	go a.forwardEvents()
+	go func() {
+		<-actx.Done()
+		a.stream.Close()
+		a.room.Disconnect()
+	}()
+
+	opts.Logger.Info("joined livekit room", "room", a.room.Name(), "identity", opts.Identity)
+	return a, nil
+}
+
+func (a *Agent) Stream() *orchestrator.ManagedStream {
+	return a.stream
+}
+
+func (a *Agent) Done() <-chan struct{} {
+	return a.ctx.Done()
+}
+
+func (a *Agent) Close() {
+	a.cancel()
+}
+
+// claim reports whether audio from identity should reach the stream.
+func (a *Agent) claim(identity string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.opts.Participant != "" {
+		return identity == a.opts.Participant
+	}
+	if a.listenTo == "" {
+		a.listenTo = identity
+	}
+	return a.listenTo == identity
+}
+
+func (a *Agent) release(identity string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.listenTo == identity {
+		a.listenTo = ""
+	}
+}
+
+func (a *Agent) onTrackSubscribed(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	if track.Kind() != webrtc.RTPCodecTypeAudio {
+		return
+	}
+	if !a.claim(rp.Identity()) {
+		a.opts.Logger.Debug("ignoring audio from other participant", "identity", rp.Identity())
+		return
+	}
+	go a.receive(track, rp.Identity())
+}
+
+func (a *Agent) onParticipantDisconnected(rp *lksdk.RemoteParticipant) {
+	a.release(rp.Identity())
+}
+
+func (a *Agent) receive(track *webrtc.TrackRemote, identity string) {
+	defer a.release(identity)
+
+	select {
+	case <-a.ready:
+	case <-a.ctx.Done():
+		return
+	}
+	rx, err := opusio.NewReceiver(a.opts.Codec, a.orch.GetConfig().SampleRate)
+	if err != nil {
+		a.opts.Logger.Error("failed to create opus decoder", "error", err)
+		return
+	}
+	for a.ctx.Err() == nil {
+		packet, _, err := track.ReadRTP()
+		if err != nil {
+			return
+		}
+		pcm, err := rx.Decode(packet.Payload)
+		if err != nil {
+			a.opts.Logger.Debug("dropping undecodable opus packet", "identity", identity, "error", err)
+			continue
+		}
+		if len(pcm) > 0 {
+			a.stream.Write(pcm)
+		}
+	}
+}
+
+func (a *Agent) forwardEvents() {
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case ev, ok := <-a.stream.Events():
+			if !ok {
+				a.cancel()
+				return
+			}
+			switch ev.Type {
+			case orchestrator.AudioChunk:
+				if pcm, ok := ev.Data.([]byte); ok {
+					if err := a.sender.Push(pcm); err != nil {
+						a.opts.Logger.Warn("failed to encode agent audio", "error", err)
+					}
+				}
+			case orchestrator.Interrupted:
+				a.sender.Clear()
+			case orchestrator.AudioDucked:
+				if duck, ok := ev.Data.(orchestrator.DuckInfo); ok {
+					a.sender.SetGain(duck.Gain)
+				}
+			case orchestrator.AudioRestored:
+				a.sender.SetGain(1)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package livekit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestJoin_RequiresOpusCodec(t *testing.T) {
+	saved := audio.DefaultOpusCodec
+	audio.DefaultOpusCodec = nil
+	defer func() { audio.DefaultOpusCodec = saved }()
+
+	orch := orchestrator.New(nil, nil, nil, orchestrator.DefaultConfig())
+	if _, err := Join(context.Background(), orch, Options{URL: "ws://localhost:7880"}); !errors.Is(err, audio.ErrNoOpusCodec) {
+		t.Errorf("expected ErrNoOpusCodec, got %v", err)
+	}
+}
+
+func TestAgent_ListensToOneParticipant(t *testing.T) {
+	a := &Agent{}
+	if !a.claim("alice") {
+		t.Fatal("first participant should be claimed")
+	}
+	if a.claim("bob") {
+		t.Error("second participant should be ignored while the first is bound")
+	}
+	a.release("alice")
+	if !a.claim("bob") {
+		t.Error("expected bob to be claimed after alice left")
+	}
+
+	pinned := &Agent{opts: Options{Participant: "carol"}}
+	if pinned.claim("alice") || !pinned.claim("carol") {
+		t.Error("expected only the configured participant to be claimed")
+	}
+}
@@ -0,0 +1,218 @@
+package sip
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type call struct {
+	info     Call
+	invite   *message
+	src      *net.UDPAddr
+	localTag string
+	sdp      []byte
+
+	rtp    *rtpSession
+	stream *orchestrator.ManagedStream
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	acked     chan struct{}
+	ackOnce   sync.Once
+	remoteBye atomic.Bool
+}
+
+// answer builds the 200 OK for req, which is the INVITE or a re-INVITE.
+func (ua *UserAgent) answer(c *call, req *message) *message {
+	resp := newResponse(req, 200, "OK")
+	if headerParam(req.get("To"), "tag") == "" {
+		resp.set("To", req.get("To")+";tag="+c.localTag)
+	}
+	resp.add("Contact", ua.contact())
+	resp.add("Allow", allow)
+	resp.add("Content-Type", "application/sdp")
+	resp.body = c.sdp
+	return resp
+}
+
+func (ua *UserAgent) handleInvite(ctx context.Context, req *message, src *net.UDPAddr) {
+	id := req.get("Call-ID")
+	if c := ua.call(id); c != nil {
+		// A retransmission or a re-INVITE (hold, refresh): the media
+		// parameters stay as negotiated.
+		ua.send(ua.answer(c, req), src)
+		return
+	}
+
+	if ctx.Err() != nil {
+		ua.send(newResponse(req, 503, "Service Unavailable"), src)
+		return
+	}
+
+	offer, err := parseSDP(req.body)
+	if err != nil {
+		ua.send(newResponse(req, 488, "Not Acceptable Here"), src)
+		return
+	}
+	payload, ok := offer.choosePayload()
+	if !ok {
+		ua.send(newResponse(req, 488, "Not Acceptable Here"), src)
+		return
+	}
+	ua.send(newResponse(req, 100, "Trying"), src)
+
+	local := ua.conn.LocalAddr().(*net.UDPAddr)
+	rtpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: local.IP})
+	if err != nil {
+		ua.opts.Logger.Error("failed to open rtp socket", "callID", id, "error", err)
+		ua.send(newResponse(req, 500, "Server Internal Error"), src)
+		return
+	}
+	remote, _ := net.ResolveUDPAddr("udp", net.JoinHostPort(offer.host, strconv.Itoa(offer.port)))
+
+	info := Call{CallID: id, From: req.get("From"), To: req.get("To")}
+	session := ua.orch.NewSessionWithDefaults(id)
+	if ua.opts.SystemPrompt != "" {
+		ua.orch.SetSystemPrompt(session, ua.opts.SystemPrompt)
+	}
+	if ua.opts.OnCall != nil {
+		ua.opts.OnCall(info, session)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	c := &call{
+		info:     info,
+		invite:   req,
+		src:      src,
+		localTag: randomHex(6),
+		sdp:      buildSDP(ua.host, rtpConn.LocalAddr().(*net.UDPAddr).Port, payload),
+		rtp:      newRTPSession(rtpConn, remote, payload, ua.orch.GetConfig().SampleRate),
+		ctx:      cctx,
+		cancel:   cancel,
+		acked:    make(chan struct{}),
+	}
+	c.stream = ua.orch.NewManagedStream(cctx, session)
+
+	ua.mu.Lock()
+	ua.calls[id] = c
+	ua.mu.Unlock()
+
+	ua.send(ua.answer(c, req), src)
+	ua.opts.Logger.Info("sip call answered", "callID", id, "from", headerURI(info.From), "payload", payload)
+
+	ua.wg.Add(1)
+	go ua.run(c)
+}
+
+func (ua *UserAgent) run(c *call) {
+	defer ua.wg.Done()
+	defer ua.endCall(c)
+
+	go c.rtp.readLoop(c.stream.Write)
+	go c.rtp.sendLoop(c.ctx, c.stream.NotifyAudioPlayed)
+	go ua.awaitAck(c)
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case ev, ok := <-c.stream.Events():
+			if !ok {
+				return
+			}
+			switch ev.Type {
+			case orchestrator.AudioChunk:
+				if pcm, ok := ev.Data.([]byte); ok {
+					c.rtp.push(pcm)
+				}
+			case orchestrator.Interrupted:
+				c.rtp.clear()
+			case orchestrator.AudioDucked:
+				if duck, ok := ev.Data.(orchestrator.DuckInfo); ok {
+					c.rtp.setGain(duck.Gain)
+				}
+			case orchestrator.AudioRestored:
+				c.rtp.setGain(1)
+			}
+		}
+	}
+}
+
+// awaitAck retransmits the 200 OK until the caller ACKs it, as a UAS must
+// over UDP, and gives up on the call if the ACK never comes.
+func (ua *UserAgent) awaitAck(c *call) {
+	interval := timerT1
+	deadline := time.After(timerF)
+	for {
+		select {
+		case <-c.acked:
+			return
+		case <-c.ctx.Done():
+			return
+		case <-deadline:
+			ua.opts.Logger.Warn("sip call never acknowledged", "callID", c.info.CallID)
+			c.cancel()
+			return
+		case <-time.After(interval):
+			ua.send(ua.answer(c, c.invite), c.src)
+			interval = min(2*interval, timerT2)
+		}
+	}
+}
+
+func (ua *UserAgent) endCall(c *call) {
+	c.cancel()
+	c.stream.Close()
+	c.rtp.conn.Close()
+
+	if !c.remoteBye.Load() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if _, err := ua.request(ctx, ua.bye(c), c.src); err != nil {
+			ua.opts.Logger.Debug("sip bye failed", "callID", c.info.CallID, "error", err)
+		}
+		cancel()
+	}
+
+	ua.mu.Lock()
+	delete(ua.calls, c.info.CallID)
+	ua.mu.Unlock()
+	ua.opts.Logger.Info("sip call ended", "callID", c.info.CallID)
+}
+
+// bye builds a BYE within the dialog created by the INVITE: the caller's
+// From becomes our To and the request goes to the caller's Contact.
+func (ua *UserAgent) bye(c *call) *message {
+	target := headerURI(c.invite.get("Contact"))
+	if target == "" {
+		target = headerURI(c.info.From)
+	}
+	cseq, _ := c.invite.cseq()
+	req := newRequest("BYE", target)
+	req.add("From", c.info.To+";tag="+c.localTag)
+	req.add("To", c.info.From)
+	req.add("Call-ID", c.info.CallID)
+	// Our own CSeq space is independent of the caller's; starting above
+	// theirs keeps simple PBXs that share one counter happy.
+	req.add("CSeq", strconv.Itoa(cseq+1)+" BYE")
+	req.add("User-Agent", userAgent)
+	for _, route := range reverse(c.invite.getAll("Record-Route")) {
+		req.add("Route", route)
+	}
+	return req
+}
+
+func reverse(values []string) []string {
+	out := make([]string, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		out = append(out, strings.TrimSpace(values[i]))
+	}
+	return out
+}
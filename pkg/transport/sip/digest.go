@@ -0,0 +1,96 @@
+package sip
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type challenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// parseChallenge reads a WWW-Authenticate or Proxy-Authenticate Digest value.
+func parseChallenge(value string) (challenge, bool) {
+	scheme, params, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || !strings.EqualFold(scheme, "Digest") {
+		return challenge{}, false
+	}
+	var c challenge
+	for _, part := range splitParams(params) {
+		k, v, _ := strings.Cut(part, "=")
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "realm":
+			c.realm = v
+		case "nonce":
+			c.nonce = v
+		case "opaque":
+			c.opaque = v
+		case "algorithm":
+			c.algorithm = v
+		case "qop":
+			// Only "auth" is supported; it is offered alongside auth-int.
+			for _, q := range strings.Split(v, ",") {
+				if strings.TrimSpace(q) == "auth" {
+					c.qop = "auth"
+				}
+			}
+		}
+	}
+	return c, c.nonce != ""
+}
+
+// splitParams splits on commas that are not inside quotes.
+func splitParams(s string) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// authorization computes the RFC 2617 Digest credentials for a request.
+func (c challenge) authorization(method, uri, username, password, cnonce string) string {
+	ha1 := md5Hex(username + ":" + c.realm + ":" + password)
+	ha2 := md5Hex(method + ":" + uri)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s"`, username, c.realm, c.nonce, uri)
+	if c.qop != "" {
+		const nc = "00000001"
+		response := md5Hex(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+		fmt.Fprintf(&b, `, response="%s", qop=%s, nc=%s, cnonce="%s"`, response, c.qop, nc, cnonce)
+	} else {
+		fmt.Fprintf(&b, `, response="%s"`, md5Hex(ha1+":"+c.nonce+":"+ha2))
+	}
+	b.WriteString(", algorithm=MD5")
+	if c.opaque != "" {
+		fmt.Fprintf(&b, `, opaque="%s"`, c.opaque)
+	}
+	return b.String()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,188 @@
+package sip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errMalformed = errors.New("malformed sip message")
+
+// compact maps RFC 3261 single-letter header forms to their full names.
+var compact = map[string]string{
+	"v": "Via",
+	"f": "From",
+	"t": "To",
+	"i": "Call-ID",
+	"m": "Contact",
+	"l": "Content-Length",
+	"c": "Content-Type",
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+// message is a SIP request (method set) or response (status set).
+type message struct {
+	method  string
+	uri     string
+	status  int
+	reason  string
+	headers []header
+	body    []byte
+}
+
+func (m *message) isRequest() bool {
+	return m.method != ""
+}
+
+func parseMessage(data []byte) (*message, error) {
+	head, body, _ := bytes.Cut(data, []byte("\r\n\r\n"))
+	lines := strings.Split(string(head), "\r\n")
+	if len(lines) == 0 {
+		return nil, errMalformed
+	}
+
+	m := &message{}
+	start := strings.SplitN(lines[0], " ", 3)
+	if len(start) != 3 {
+		return nil, errMalformed
+	}
+	if strings.HasPrefix(start[0], "SIP/") {
+		status, err := strconv.Atoi(start[1])
+		if err != nil {
+			return nil, errMalformed
+		}
+		m.status, m.reason = status, start[2]
+	} else {
+		if !strings.HasPrefix(start[2], "SIP/") {
+			return nil, errMalformed
+		}
+		m.method, m.uri = start[0], start[1]
+	}
+
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		// Folded continuation lines belong to the previous header.
+		if (line[0] == ' ' || line[0] == '\t') && len(m.headers) > 0 {
+			m.headers[len(m.headers)-1].value += " " + strings.TrimSpace(line)
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, errMalformed
+		}
+		name = strings.TrimSpace(name)
+		if full, ok := compact[strings.ToLower(name)]; ok {
+			name = full
+		}
+		m.headers = append(m.headers, header{name: name, value: strings.TrimSpace(value)})
+	}
+
+	if n, err := strconv.Atoi(m.get("Content-Length")); err == nil && n >= 0 && n < len(body) {
+		body = body[:n]
+	}
+	m.body = body
+	return m, nil
+}
+
+func (m *message) get(name string) string {
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			return h.value
+		}
+	}
+	return ""
+}
+
+func (m *message) getAll(name string) []string {
+	var values []string
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			values = append(values, h.value)
+		}
+	}
+	return values
+}
+
+func (m *message) set(name, value string) {
+	for i, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			m.headers[i].value = value
+			return
+		}
+	}
+	m.add(name, value)
+}
+
+func (m *message) add(name, value string) {
+	m.headers = append(m.headers, header{name: name, value: value})
+}
+
+func (m *message) bytes() []byte {
+	var b bytes.Buffer
+	if m.isRequest() {
+		fmt.Fprintf(&b, "%s %s SIP/2.0\r\n", m.method, m.uri)
+	} else {
+		fmt.Fprintf(&b, "SIP/2.0 %d %s\r\n", m.status, m.reason)
+	}
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, "Content-Length") {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", h.name, h.value)
+	}
+	fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n", len(m.body))
+	b.Write(m.body)
+	return b.Bytes()
+}
+
+// cseq returns the sequence number and method of the CSeq header.
+func (m *message) cseq() (int, string) {
+	num, method, _ := strings.Cut(m.get("CSeq"), " ")
+	n, _ := strconv.Atoi(num)
+	return n, strings.TrimSpace(method)
+}
+
+// newResponse builds a response that mirrors the request's dialog headers.
+func newResponse(req *message, status int, reason string) *message {
+	resp := &message{status: status, reason: reason}
+	for _, via := range req.getAll("Via") {
+		resp.add("Via", via)
+	}
+	resp.add("From", req.get("From"))
+	resp.add("To", req.get("To"))
+	resp.add("Call-ID", req.get("Call-ID"))
+	resp.add("CSeq", req.get("CSeq"))
+	resp.add("User-Agent", userAgent)
+	return resp
+}
+
+// headerParam returns a ;name=value parameter of a header value.
+func headerParam(value, name string) string {
+	for _, part := range strings.Split(value, ";")[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(k, name) {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// headerURI extracts the URI of a From, To or Contact value, with or
+// without angle brackets.
+func headerURI(value string) string {
+	if i := strings.Index(value, "<"); i >= 0 {
+		if j := strings.Index(value[i:], ">"); j >= 0 {
+			return value[i+1 : i+j]
+		}
+	}
+	uri, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(uri)
+}
@@ -0,0 +1,102 @@
+package sip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// registerRetry is how long to wait after a failed registration.
+const registerRetry = 30 * time.Second
+
+// registerLoop keeps the binding fresh until ctx is done, then removes it.
+func (ua *UserAgent) registerLoop(ctx context.Context) {
+	for {
+		wait := registerRetry
+		granted, err := ua.register(ctx, ua.opts.Expires)
+		switch {
+		case err != nil && ctx.Err() != nil:
+		case err != nil:
+			ua.opts.Logger.Warn("sip registration failed", "registrar", ua.opts.Registrar, "error", err)
+		default:
+			ua.opts.Logger.Info("sip registered", "registrar", ua.opts.Registrar, "expires", granted)
+			// Refresh ahead of expiry so there is no window without a binding.
+			wait = granted * 4 / 5
+		}
+
+		select {
+		case <-ctx.Done():
+			uctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			ua.register(uctx, 0)
+			cancel()
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// register sends REGISTER, answering a digest challenge once, and returns
+// the lifetime granted by the registrar.
+func (ua *UserAgent) register(ctx context.Context, expires time.Duration) (time.Duration, error) {
+	dest, err := net.ResolveUDPAddr("udp", ua.opts.Registrar)
+	if err != nil {
+		return 0, err
+	}
+	aor := fmt.Sprintf("<sip:%s@%s>", ua.opts.Username, ua.opts.Domain)
+	uri := "sip:" + ua.opts.Domain
+	seconds := strconv.Itoa(int(expires / time.Second))
+
+	var credentials [2]string
+	for attempt := 0; attempt < 2; attempt++ {
+		ua.regCSeq++
+		req := newRequest("REGISTER", uri)
+		req.add("From", aor+";tag="+ua.regTag)
+		req.add("To", aor)
+		req.add("Call-ID", ua.regCallID)
+		req.add("CSeq", strconv.Itoa(ua.regCSeq)+" REGISTER")
+		req.add("Contact", ua.contact())
+		req.add("Expires", seconds)
+		req.add("User-Agent", userAgent)
+		if credentials[0] != "" {
+			req.add(credentials[0], credentials[1])
+		}
+
+		resp, err := ua.request(ctx, req, dest)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case resp.status >= 200 && resp.status < 300:
+			return grantedExpiry(resp, expires), nil
+		case resp.status == 401 || resp.status == 407:
+			name, reply := "WWW-Authenticate", "Authorization"
+			if resp.status == 407 {
+				name, reply = "Proxy-Authenticate", "Proxy-Authorization"
+			}
+			c, ok := parseChallenge(resp.get(name))
+			if !ok {
+				return 0, fmt.Errorf("register: %d without a digest challenge", resp.status)
+			}
+			credentials = [2]string{reply, c.authorization("REGISTER", uri, ua.opts.Username, ua.opts.Password, randomHex(8))}
+		default:
+			return 0, fmt.Errorf("register: %d %s", resp.status, resp.reason)
+		}
+	}
+	return 0, fmt.Errorf("register: credentials rejected")
+}
+
+// grantedExpiry prefers the expires parameter of our Contact in the
+// response, then the Expires header.
+func grantedExpiry(resp *message, requested time.Duration) time.Duration {
+	for _, contact := range resp.getAll("Contact") {
+		if v, err := strconv.Atoi(headerParam(contact, "expires")); err == nil && v > 0 {
+			return time.Duration(v) * time.Second
+		}
+	}
+	if v, err := strconv.Atoi(resp.get("Expires")); err == nil && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	return requested
+}
@@ -0,0 +1,219 @@
+package sip
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+)
+
+const (
+	g711Rate      = 8000
+	frameDuration = 20 * time.Millisecond
+	// 20ms of 16-bit PCM at 8kHz; one G.711 frame is half that.
+	pcmFrameBytes = g711Rate / 50 * 2
+	rtpHeaderSize = 12
+)
+
+// rtpSession bridges one call's G.711 RTP stream and the orchestrator's PCM.
+type rtpSession struct {
+	conn    *net.UDPConn
+	payload int
+
+	remoteMu sync.Mutex
+	remote   *net.UDPAddr
+	latched  bool
+
+	in *resample.Resampler
+
+	mu     sync.Mutex
+	out    *resample.Resampler
+	framer *audio.Framer
+	queue  [][]byte // PCM frames, encoded when sent so setGain reaches them
+	rate   int
+	gain   float64
+
+	ssrc uint32
+	seq  uint16
+	ts   uint32
+}
+
+func newRTPSession(conn *net.UDPConn, remote *net.UDPAddr, payload, rate int) *rtpSession {
+	return &rtpSession{
+		conn:    conn,
+		payload: payload,
+		remote:  remote,
+		in:      resample.NewResampler(g711Rate, rate),
+		out:     resample.NewResampler(rate, g711Rate),
+		framer:  audio.NewFramer(pcmFrameBytes),
+		rate:    rate,
+		gain:    1,
+		ssrc:    rand.Uint32(),
+		seq:     uint16(rand.Uint32()),
+		ts:      rand.Uint32(),
+	}
+}
+
+func (r *rtpSession) decode(payload []byte) []byte {
+	if r.payload == payloadPCMA {
+		return audio.AlawDecode(payload)
+	}
+	return audio.MulawDecode(payload)
+}
+
+func (r *rtpSession) encode(pcm []byte) []byte {
+	if r.payload == payloadPCMA {
+		return audio.AlawEncode(pcm)
+	}
+	return audio.MulawEncode(pcm)
+}
+
+// readLoop delivers inbound audio to write until the socket is closed.
+func (r *rtpSession) readLoop(write func(pcm []byte) error) {
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n < rtpHeaderSize || buf[0]>>6 != 2 {
+			continue
+		}
+		if int(buf[1]&0x7f) != r.payload {
+			// Comfort noise, telephone events and the like.
+			continue
+		}
+		r.latch(addr)
+
+		offset := rtpHeaderSize + 4*int(buf[0]&0x0f)
+		if buf[0]&0x10 != 0 && n >= offset+4 {
+			offset += 4 + 4*int(binary.BigEndian.Uint16(buf[offset+2:]))
+		}
+		end := n
+		if buf[0]&0x20 != 0 && n > offset {
+			end -= int(buf[n-1])
+		}
+		if offset >= end {
+			continue
+		}
+		if pcm := r.in.Process(r.decode(buf[offset:end])); len(pcm) > 0 {
+			write(pcm)
+		}
+	}
+}
+
+// latch switches the destination to where the caller's media actually comes
+// from (symmetric RTP), which keeps audio flowing through NATs that rewrite
+// the port advertised in the SDP.
+func (r *rtpSession) latch(addr *net.UDPAddr) {
+	r.remoteMu.Lock()
+	defer r.remoteMu.Unlock()
+	if r.latched {
+		return
+	}
+	r.latched = true
+	if r.remote == nil || !r.remote.IP.Equal(addr.IP) || r.remote.Port != addr.Port {
+		r.remote = addr
+	}
+}
+
+func (r *rtpSession) destination() *net.UDPAddr {
+	r.remoteMu.Lock()
+	defer r.remoteMu.Unlock()
+	return r.remote
+}
+
+// push queues PCM at the orchestrator's rate for playback.
+func (r *rtpSession) push(pcm []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue = append(r.queue, r.framer.Push(r.out.Process(pcm))...)
+}
+
+// setGain scales audio that has not been sent yet, queued or not.
+func (r *rtpSession) setGain(gain float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gain = gain
+}
+
+// clear drops everything that has not been sent yet and restores full gain.
+func (r *rtpSession) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue = nil
+	r.gain = 1
+	r.framer.Reset()
+	r.out = resample.NewResampler(r.rate, g711Rate)
+}
+
+// next returns the frame due now, padding and flushing the partial frame
+// once the queue drains so responses are not clipped.
+func (r *rtpSession) next() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queue) == 0 {
+		if frame := r.framer.Flush(); frame != nil {
+			r.queue = append(r.queue, frame)
+		}
+	}
+	if len(r.queue) == 0 {
+		return nil
+	}
+	frame := r.queue[0]
+	r.queue = r.queue[1:]
+	if r.gain != 1 {
+		frame = audio.ApplyGain(frame, r.gain)
+	}
+	return r.encode(frame)
+}
+
+// sendLoop paces queued frames at 20ms. The RTP timestamp keeps advancing
+// through silence so the far end's jitter buffer sees real elapsed time, and
+// the marker bit flags the start of each talkspurt.
+func (r *rtpSession) sendLoop(ctx context.Context, played func()) {
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+
+	packet := make([]byte, rtpHeaderSize+pcmFrameBytes/2)
+	talking := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		frame := r.next()
+		r.ts += uint32(pcmFrameBytes / 2)
+		if frame == nil {
+			talking = false
+			continue
+		}
+		dest := r.destination()
+		if dest == nil {
+			continue
+		}
+
+		packet[0] = 0x80
+		packet[1] = byte(r.payload)
+		if !talking {
+			packet[1] |= 0x80
+			talking = true
+		}
+		binary.BigEndian.PutUint16(packet[2:], r.seq)
+		binary.BigEndian.PutUint32(packet[4:], r.ts)
+		binary.BigEndian.PutUint32(packet[8:], r.ssrc)
+		n := copy(packet[rtpHeaderSize:], frame)
+		r.seq++
+
+		if _, err := r.conn.WriteToUDP(packet[:rtpHeaderSize+n], dest); err != nil {
+			return
+		}
+		played()
+	}
+}
@@ -0,0 +1,86 @@
+package sip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	payloadPCMU = 0
+	payloadPCMA = 8
+)
+
+type mediaOffer struct {
+	host     string
+	port     int
+	payloads []int
+}
+
+func parseSDP(body []byte) (mediaOffer, error) {
+	var offer mediaOffer
+	var sessionHost, mediaHost string
+	inAudio := false
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "c="):
+			fields := strings.Fields(line[2:])
+			if len(fields) == 3 {
+				if inAudio {
+					mediaHost = fields[2]
+				} else {
+					sessionHost = fields[2]
+				}
+			}
+		case strings.HasPrefix(line, "m="):
+			fields := strings.Fields(line[2:])
+			inAudio = len(fields) >= 4 && fields[0] == "audio" && offer.port == 0
+			if !inAudio {
+				continue
+			}
+			offer.port, _ = strconv.Atoi(fields[1])
+			for _, f := range fields[3:] {
+				if pt, err := strconv.Atoi(f); err == nil {
+					offer.payloads = append(offer.payloads, pt)
+				}
+			}
+		}
+	}
+	offer.host = mediaHost
+	if offer.host == "" {
+		offer.host = sessionHost
+	}
+	if offer.host == "" || offer.port == 0 {
+		return offer, fmt.Errorf("sdp has no audio stream")
+	}
+	return offer, nil
+}
+
+// choosePayload picks the first G.711 codec in the caller's preference order.
+func (o mediaOffer) choosePayload() (int, bool) {
+	for _, pt := range o.payloads {
+		if pt == payloadPCMU || pt == payloadPCMA {
+			return pt, true
+		}
+	}
+	return 0, false
+}
+
+func buildSDP(host string, port, payload int) []byte {
+	name := "PCMU"
+	if payload == payloadPCMA {
+		name = "PCMA"
+	}
+	id := time.Now().Unix()
+	return []byte(fmt.Sprintf("v=0\r\n"+
+		"o=lokutor %d %d IN IP4 %s\r\n"+
+		"s=lokutor\r\n"+
+		"c=IN IP4 %s\r\n"+
+		"t=0 0\r\n"+
+		"m=audio %d RTP/AVP %d\r\n"+
+		"a=rtpmap:%d %s/8000\r\n"+
+		"a=ptime:20\r\n"+
+		"a=sendrecv\r\n", id, id, host, host, port, payload, payload, name))
+}
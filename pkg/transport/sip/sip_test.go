@@ -0,0 +1,86 @@
+package sip
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	raw := "INVITE sip:agent@10.0.0.1 SIP/2.0\r\n" +
+		"v: SIP/2.0/UDP 10.0.0.2:5060;branch=z9hG4bK1\r\n" +
+		"Via: SIP/2.0/UDP 10.0.0.3:5060;branch=z9hG4bK2\r\n" +
+		"f: \"Alice\" <sip:alice@pbx>;tag=abc\r\n" +
+		"t: <sip:agent@pbx>\r\n" +
+		"i: call-1\r\n" +
+		"CSeq: 7 INVITE\r\n" +
+		"Subject: a long\r\n subject\r\n" +
+		"l: 4\r\n\r\nbodyEXTRA"
+
+	m, err := parseMessage([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.method != "INVITE" || m.uri != "sip:agent@10.0.0.1" {
+		t.Errorf("bad request line: %q %q", m.method, m.uri)
+	}
+	if len(m.getAll("Via")) != 2 || m.get("Call-ID") != "call-1" {
+		t.Errorf("compact headers not expanded: %+v", m.headers)
+	}
+	if got := m.get("Subject"); got != "a long subject" {
+		t.Errorf("folded header = %q", got)
+	}
+	if string(m.body) != "body" {
+		t.Errorf("body = %q, want Content-Length bytes", m.body)
+	}
+	if n, method := m.cseq(); n != 7 || method != "INVITE" {
+		t.Errorf("cseq = %d %s", n, method)
+	}
+	if headerParam(m.get("From"), "tag") != "abc" || headerURI(m.get("From")) != "sip:alice@pbx" {
+		t.Errorf("bad From parsing")
+	}
+
+	resp, err := parseMessage(newResponse(m, 200, "OK").bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.status != 200 || len(resp.getAll("Via")) != 2 || resp.get("CSeq") != "7 INVITE" {
+		t.Errorf("response does not mirror request: %s", resp.bytes())
+	}
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	// The worked example from RFC 2617 section 3.5.
+	c, ok := parseChallenge(`Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`)
+	if !ok {
+		t.Fatal("challenge not parsed")
+	}
+	auth := c.authorization("GET", "/dir/index.html", "Mufasa", "Circle Of Life", "0a4f113b")
+	for _, want := range []string{
+		`response="6629fae49393a05397450978507c4ef1"`,
+		`qop=auth`,
+		`nc=00000001`,
+		`opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+	} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("authorization %q missing %s", auth, want)
+		}
+	}
+}
+
+func TestParseSDP(t *testing.T) {
+	offer, err := parseSDP([]byte("v=0\r\no=- 1 1 IN IP4 10.0.0.9\r\nc=IN IP4 10.0.0.9\r\nt=0 0\r\n" +
+		"m=audio 4000 RTP/AVP 9 8 0 101\r\nc=IN IP4 10.0.0.10\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer.host != "10.0.0.10" || offer.port != 4000 {
+		t.Errorf("media address = %s:%d", offer.host, offer.port)
+	}
+	if pt, ok := offer.choosePayload(); !ok || pt != payloadPCMA {
+		t.Errorf("chose %d, want PCMA in caller's order", pt)
+	}
+
+	if _, err := parseSDP([]byte("v=0\r\nm=video 4000 RTP/AVP 96\r\n")); err == nil {
+		t.Error("expected an error without audio")
+	}
+}
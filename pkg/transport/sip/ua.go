@@ -0,0 +1,321 @@
+// Package sip answers phone calls from a PBX such as Asterisk or FreeSWITCH.
+// A UserAgent registers over UDP, accepts INVITEs with G.711 audio and bridges
+// each call's RTP stream into a ManagedStream.
+package sip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const (
+	userAgent = "lokutor-orchestrator"
+	allow     = "INVITE, ACK, BYE, CANCEL, OPTIONS"
+
+	// RFC 3261 timers for unreliable transports.
+	timerT1 = 500 * time.Millisecond
+	timerT2 = 4 * time.Second
+	timerF  = 64 * timerT1
+)
+
+var errTimeout = errors.New("sip transaction timed out")
+
+// Call describes an answered INVITE. From and To are the raw header values.
+type Call struct {
+	CallID string
+	From   string
+	To     string
+}
+
+// Options configure a UserAgent. Without a Registrar the agent does not
+// register and simply answers INVITEs sent to ListenAddr, which suits PBX
+// trunks configured by IP.
+type Options struct {
+	// ListenAddr defaults to ":5060".
+	ListenAddr string
+	// Host is the address advertised in Contact headers and SDP. It defaults
+	// to the local address used to reach the registrar.
+	Host      string
+	Registrar string
+	Username  string
+	Password  string
+	// Domain defaults to the registrar's host.
+	Domain string
+	// Expires is the requested registration lifetime, 5 minutes by default.
+	Expires time.Duration
+
+	SystemPrompt string
+	Logger       orchestrator.Logger
+	// OnCall, when set, is called before each call's stream starts so callers
+	// can pick a voice, language or prompt per caller.
+	OnCall func(call Call, session *orchestrator.ConversationSession)
+}
+
+// UserAgent is a minimal SIP UAS over UDP.
+type UserAgent struct {
+	orch *orchestrator.Orchestrator
+	opts Options
+
+	conn *net.UDPConn
+	host string
+	port int
+
+	regCallID string
+	regTag    string
+	regCSeq   int
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	calls   map[string]*call
+	pending map[string]chan *message
+}
+
+func NewUserAgent(orch *orchestrator.Orchestrator, opts Options) *UserAgent {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.ListenAddr == "" {
+		opts.ListenAddr = ":5060"
+	}
+	if opts.Expires <= 0 {
+		opts.Expires = 5 * time.Minute
+	}
+	if opts.Domain == "" && opts.Registrar != "" {
+		opts.Domain, _, _ = net.SplitHostPort(opts.Registrar)
+	}
+	return &UserAgent{
+		orch:      orch,
+		opts:      opts,
+		regCallID: randomHex(12),
+		regTag:    randomHex(6),
+		calls:     make(map[string]*call),
+		pending:   make(map[string]chan *message),
+	}
+}
+
+// ListenAndServe listens on Options.ListenAddr and calls Serve.
+func (ua *UserAgent) ListenAndServe(ctx context.Context) error {
+	addr, err := net.ResolveUDPAddr("udp", ua.opts.ListenAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	return ua.Serve(ctx, conn)
+}
+
+// Serve handles SIP on conn until ctx is done. On shutdown live calls are
+// hung up and the registration is removed before conn is closed.
+func (ua *UserAgent) Serve(ctx context.Context, conn *net.UDPConn) error {
+	ua.conn = conn
+	local := conn.LocalAddr().(*net.UDPAddr)
+	ua.port = local.Port
+	ua.host = ua.opts.Host
+	if ua.host == "" {
+		ua.host = ua.localHost(local)
+	}
+
+	readErr := make(chan error, 1)
+	go func() { readErr <- ua.readLoop(ctx) }()
+
+	registered := make(chan struct{})
+	if ua.opts.Registrar != "" {
+		go func() {
+			defer close(registered)
+			ua.registerLoop(ctx)
+		}()
+	} else {
+		close(registered)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-readErr:
+	}
+	ua.hangupAll()
+	ua.wg.Wait()
+	<-registered
+	conn.Close()
+	return err
+}
+
+func (ua *UserAgent) localHost(local *net.UDPAddr) string {
+	if !local.IP.IsUnspecified() {
+		return local.IP.String()
+	}
+	target := ua.opts.Registrar
+	if target == "" {
+		target = "192.0.2.1:5060"
+	}
+	// Connecting a UDP socket sends nothing; it only picks the route.
+	if c, err := net.Dial("udp", target); err == nil {
+		defer c.Close()
+		return c.LocalAddr().(*net.UDPAddr).IP.String()
+	}
+	return "127.0.0.1"
+}
+
+func (ua *UserAgent) contact() string {
+	user := ua.opts.Username
+	if user == "" {
+		user = "lokutor"
+	}
+	return fmt.Sprintf("<sip:%s@%s>", user, net.JoinHostPort(ua.host, strconv.Itoa(ua.port)))
+}
+
+func (ua *UserAgent) send(msg *message, addr *net.UDPAddr) error {
+	_, err := ua.conn.WriteToUDP(msg.bytes(), addr)
+	return err
+}
+
+func (ua *UserAgent) readLoop(ctx context.Context) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := ua.conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		msg, err := parseMessage(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			// Keep-alives (CRLF) and garbage are dropped silently.
+			continue
+		}
+		if msg.isRequest() {
+			ua.handleRequest(ctx, msg, addr)
+		} else {
+			ua.handleResponse(msg)
+		}
+	}
+}
+
+func (ua *UserAgent) handleResponse(resp *message) {
+	branch := headerParam(resp.get("Via"), "branch")
+	ua.mu.Lock()
+	ch := ua.pending[branch]
+	ua.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- resp:
+	default:
+	}
+}
+
+func (ua *UserAgent) handleRequest(ctx context.Context, req *message, src *net.UDPAddr) {
+	switch req.method {
+	case "INVITE":
+		ua.handleInvite(ctx, req, src)
+	case "ACK":
+		if c := ua.call(req.get("Call-ID")); c != nil {
+			c.ackOnce.Do(func() { close(c.acked) })
+		}
+	case "BYE":
+		c := ua.call(req.get("Call-ID"))
+		if c == nil {
+			ua.send(newResponse(req, 481, "Call/Transaction Does Not Exist"), src)
+			return
+		}
+		ua.send(newResponse(req, 200, "OK"), src)
+		c.remoteBye.Store(true)
+		c.cancel()
+	case "CANCEL":
+		// Calls are answered straight away, so there is never a pending
+		// INVITE left to cancel; the caller follows up with BYE.
+		if ua.call(req.get("Call-ID")) == nil {
+			ua.send(newResponse(req, 481, "Call/Transaction Does Not Exist"), src)
+			return
+		}
+		ua.send(newResponse(req, 200, "OK"), src)
+	case "OPTIONS":
+		resp := newResponse(req, 200, "OK")
+		resp.add("Allow", allow)
+		resp.add("Accept", "application/sdp")
+		ua.send(resp, src)
+	default:
+		resp := newResponse(req, 405, "Method Not Allowed")
+		resp.add("Allow", allow)
+		ua.send(resp, src)
+	}
+}
+
+func (ua *UserAgent) call(id string) *call {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	return ua.calls[id]
+}
+
+func (ua *UserAgent) hangupAll() {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	for _, c := range ua.calls {
+		c.cancel()
+	}
+}
+
+// request sends req to dest, retransmitting per RFC 3261 until a final
+// response arrives. Every attempt gets a fresh branch.
+func (ua *UserAgent) request(ctx context.Context, req *message, dest *net.UDPAddr) (*message, error) {
+	branch := "z9hG4bK" + randomHex(8)
+	req.set("Via", fmt.Sprintf("SIP/2.0/UDP %s;branch=%s;rport", net.JoinHostPort(ua.host, strconv.Itoa(ua.port)), branch))
+
+	ch := make(chan *message, 4)
+	ua.mu.Lock()
+	ua.pending[branch] = ch
+	ua.mu.Unlock()
+	defer func() {
+		ua.mu.Lock()
+		delete(ua.pending, branch)
+		ua.mu.Unlock()
+	}()
+
+	if err := ua.send(req, dest); err != nil {
+		return nil, err
+	}
+	interval := timerT1
+	retransmit := time.NewTimer(interval)
+	defer retransmit.Stop()
+	deadline := time.NewTimer(timerF)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, errTimeout
+		case <-retransmit.C:
+			if err := ua.send(req, dest); err != nil {
+				return nil, err
+			}
+			interval = min(2*interval, timerT2)
+			retransmit.Reset(interval)
+		case resp := <-ch:
+			if resp.status >= 200 {
+				return resp, nil
+			}
+			// A provisional response means the server has it.
+			retransmit.Stop()
+		}
+	}
+}
+
+func newRequest(method, uri string) *message {
+	req := &message{method: method, uri: uri}
+	req.add("Via", "")
+	req.add("Max-Forwards", "70")
+	return req
+}
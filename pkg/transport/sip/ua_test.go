@@ -0,0 +1,278 @@
+package sip
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "hello caller", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return make([]byte, 4410*2), nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(make([]byte, 4410*2))
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+// pbx is the far end of the test: a registrar and caller on one socket.
+type pbx struct {
+	t    *testing.T
+	conn *net.UDPConn
+}
+
+func newPBX(t *testing.T) *pbx {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &pbx{t: t, conn: conn}
+}
+
+func (p *pbx) read() (*message, *net.UDPAddr) {
+	p.t.Helper()
+	buf := make([]byte, 65535)
+	p.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, addr, err := p.conn.ReadFromUDP(buf)
+	if err != nil {
+		p.t.Fatalf("pbx read: %v", err)
+	}
+	m, err := parseMessage(buf[:n])
+	if err != nil {
+		p.t.Fatalf("pbx parse: %v", err)
+	}
+	return m, addr
+}
+
+// expect skips retransmissions until a message matching want arrives.
+func (p *pbx) expect(want func(*message) bool) (*message, *net.UDPAddr) {
+	p.t.Helper()
+	for {
+		m, addr := p.read()
+		if want(m) {
+			return m, addr
+		}
+	}
+}
+
+func (p *pbx) send(m *message, addr *net.UDPAddr) {
+	if _, err := p.conn.WriteToUDP(m.bytes(), addr); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+func status(code int) func(*message) bool {
+	return func(m *message) bool { return m.status == code }
+}
+
+func method(name string) func(*message) bool {
+	return func(m *message) bool { return m.method == name }
+}
+
+func TestUserAgent_RegistersAndAnswersCalls(t *testing.T) {
+	pbx := newPBX(t)
+	rtp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rtp.Close()
+
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, orchestrator.DefaultConfig())
+	answered := make(chan Call, 1)
+	ua := NewUserAgent(orch, Options{
+		Registrar: pbx.conn.LocalAddr().String(),
+		Username:  "agent",
+		Password:  "secret",
+		Domain:    "pbx.test",
+		OnCall: func(call Call, session *orchestrator.ConversationSession) {
+			answered <- call
+		},
+	})
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- ua.Serve(ctx, conn) }()
+
+	// Registration with a digest challenge.
+	reg, uaAddr := pbx.expect(method("REGISTER"))
+	challenge := newResponse(reg, 401, "Unauthorized")
+	challenge.add("WWW-Authenticate", `Digest realm="pbx.test", nonce="n0nce", qop="auth"`)
+	pbx.send(challenge, uaAddr)
+
+	reg, _ = pbx.expect(func(m *message) bool { return m.method == "REGISTER" && m.get("Authorization") != "" })
+	auth := reg.get("Authorization")
+	c, _ := parseChallenge(`Digest realm="pbx.test", nonce="n0nce", qop="auth"`)
+	cnonce := headerParam(strings.ReplaceAll(auth, ",", ";"), "cnonce")
+	if want := c.authorization("REGISTER", "sip:pbx.test", "agent", "secret", cnonce); want != auth {
+		t.Fatalf("authorization = %q, want %q", auth, want)
+	}
+	ok := newResponse(reg, 200, "OK")
+	ok.add("Contact", reg.get("Contact")+";expires=60")
+	pbx.send(ok, uaAddr)
+
+	// An incoming call offering G.711 mu-law.
+	rtpPort := rtp.LocalAddr().(*net.UDPAddr).Port
+	invite := newRequest("INVITE", "sip:agent@127.0.0.1")
+	invite.set("Via", "SIP/2.0/UDP 127.0.0.1;branch=z9hG4bKcall")
+	invite.add("From", "<sip:alice@pbx.test>;tag=caller")
+	invite.add("To", "<sip:agent@pbx.test>")
+	invite.add("Call-ID", "call-1")
+	invite.add("CSeq", "1 INVITE")
+	invite.add("Contact", "<sip:alice@"+pbx.conn.LocalAddr().String()+">")
+	invite.add("Content-Type", "application/sdp")
+	invite.body = []byte("v=0\r\nc=IN IP4 127.0.0.1\r\nt=0 0\r\nm=audio " + strconv.Itoa(rtpPort) + " RTP/AVP 0 101\r\n")
+	pbx.send(invite, uaAddr)
+
+	pbx.expect(status(100))
+	answer, _ := pbx.expect(status(200))
+	if headerParam(answer.get("To"), "tag") == "" {
+		t.Error("200 OK must add a To tag")
+	}
+	offer, err := parseSDP(answer.body)
+	if err != nil || len(offer.payloads) != 1 || offer.payloads[0] != payloadPCMU {
+		t.Fatalf("bad answer sdp %q: %v", answer.body, err)
+	}
+	if call := <-answered; call.CallID != "call-1" {
+		t.Errorf("OnCall got %+v", call)
+	}
+
+	ack := newRequest("ACK", "sip:agent@127.0.0.1")
+	ack.set("Via", "SIP/2.0/UDP 127.0.0.1;branch=z9hG4bKack")
+	ack.add("Call-ID", "call-1")
+	ack.add("CSeq", "1 ACK")
+	pbx.send(ack, uaAddr)
+
+	// Caller audio reaches the stream.
+	agentRTP := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: offer.port}
+	packet := make([]byte, rtpHeaderSize+160)
+	packet[0] = 0x80
+	for i := 0; i < 5; i++ {
+		rtp.WriteToUDP(packet, agentRTP)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stream, ok := orch.Stream("call-1")
+		if ok && stream.Status().Stats.AudioBytesIn > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("caller audio never reached the stream")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The greeting is played back as paced PCMU frames.
+	rtp.SetReadDeadline(time.Now().Add(3 * time.Second))
+	buf := make([]byte, 2048)
+	n, _, err := rtp.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("no agent audio: %v", err)
+	}
+	if n != rtpHeaderSize+160 || buf[1]&0x7f != payloadPCMU {
+		t.Errorf("unexpected rtp packet: %d bytes, pt %d", n, buf[1]&0x7f)
+	}
+
+	// Shutting down hangs up the call and removes the registration.
+	cancel()
+	bye, _ := pbx.expect(method("BYE"))
+	if bye.get("Call-ID") != "call-1" || headerParam(bye.get("To"), "tag") != "caller" {
+		t.Errorf("BYE is outside the dialog: %s", bye.bytes())
+	}
+	pbx.send(newResponse(bye, 200, "OK"), uaAddr)
+
+	unreg, _ := pbx.expect(method("REGISTER"))
+	if unreg.get("Expires") != "0" {
+		t.Errorf("expected unregister, got Expires %q", unreg.get("Expires"))
+	}
+	pbx.send(newResponse(unreg, 200, "OK"), uaAddr)
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+	if _, ok := orch.Stream("call-1"); ok {
+		t.Error("stream still registered after hangup")
+	}
+}
+
+func TestUserAgent_RemoteHangup(t *testing.T) {
+	pbx := newPBX(t)
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, orchestrator.DefaultConfig())
+	ua := NewUserAgent(orch, Options{})
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ua.Serve(ctx, conn)
+	uaAddr := conn.LocalAddr().(*net.UDPAddr)
+
+	invite := newRequest("INVITE", "sip:agent@127.0.0.1")
+	invite.set("Via", "SIP/2.0/UDP 127.0.0.1;branch=z9hG4bKinv")
+	invite.add("From", "<sip:bob@pbx.test>;tag=b")
+	invite.add("To", "<sip:agent@pbx.test>")
+	invite.add("Call-ID", "call-2")
+	invite.add("CSeq", "1 INVITE")
+	invite.body = []byte("v=0\r\nc=IN IP4 127.0.0.1\r\nm=audio 9 RTP/AVP 18\r\n")
+	pbx.send(invite, uaAddr)
+	pbx.expect(status(488))
+
+	invite.body = []byte("v=0\r\nc=IN IP4 127.0.0.1\r\nm=audio 9 RTP/AVP 8\r\n")
+	invite.set("CSeq", "2 INVITE")
+	pbx.send(invite, uaAddr)
+	pbx.expect(status(200))
+	if _, ok := orch.Stream("call-2"); !ok {
+		t.Fatal("expected a stream for the call")
+	}
+
+	bye := newRequest("BYE", "sip:agent@127.0.0.1")
+	bye.set("Via", "SIP/2.0/UDP 127.0.0.1;branch=z9hG4bKbye")
+	bye.add("Call-ID", "call-2")
+	bye.add("CSeq", "3 BYE")
+	pbx.send(bye, uaAddr)
+	pbx.expect(func(m *message) bool { return m.status == 200 && strings.HasSuffix(m.get("CSeq"), "BYE") })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := orch.Stream("call-2"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream not closed after BYE")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pbx.send(bye, uaAddr)
+	pbx.expect(status(481))
+}
@@ -0,0 +1,388 @@
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Twilio Media Streams always carry 8kHz mono mu-law.
+const twilioSampleRate = 8000
+
+// segmentGap is how long outbound audio must pause before the audio sent so
+// far is marked as one segment. TTS streams faster than real time, so gaps
+// this long only fall between sentences and responses.
+const segmentGap = 200 * time.Millisecond
+
+// markPrefix starts the names of the marks sent after each segment.
+const markPrefix = "segment-"
+
+// Call describes the phone call behind a media stream, taken from Twilio's
+// start message. Parameters holds the <Parameter> values from the TwiML.
+type Call struct {
+	AccountSID string
+	CallSID    string
+	StreamSID  string
+	Parameters map[string]string
+}
+
+// Options configure a Handler. When AuthToken is set, the X-Twilio-Signature
+// header of the websocket upgrade is validated against PublicURL (the wss://
+// base URL Twilio was told to connect to) plus the request path.
+type Options struct {
+	SystemPrompt string
+	AuthToken    string
+	PublicURL    string
+	Logger       orchestrator.Logger
+	// OnStart, when set, is called once the call is known and before the
+	// stream starts, so callers can pick a voice, language or prompt per call.
+	OnStart func(call Call, session *orchestrator.ConversationSession)
+	// OnDTMF, when set, receives keypad digits pressed by the caller.
+	OnDTMF func(call Call, stream *orchestrator.ManagedStream, digit string)
+}
+
+// Handler terminates Twilio <Connect><Stream> websockets and binds each call
+// to a ManagedStream, transcoding between mu-law and the orchestrator's PCM.
+type Handler struct {
+	orch *orchestrator.Orchestrator
+	opts Options
+}
+
+func NewHandler(orch *orchestrator.Orchestrator, opts Options) *Handler {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.AuthToken == "" {
+		opts.Logger.Warn("twilio AuthToken is not set; requests are not verified to come from Twilio")
+	}
+	return &Handler{orch: orch, opts: opts}
+}
+
+type inboundMessage struct {
+	Event     string `json:"event"`
+	StreamSID string `json:"streamSid"`
+	Start     struct {
+		AccountSID       string            `json:"accountSid"`
+		CallSID          string            `json:"callSid"`
+		StreamSID        string            `json:"streamSid"`
+		CustomParameters map[string]string `json:"customParameters"`
+	} `json:"start"`
+	Media struct {
+		Track   string `json:"track"`
+		Payload string `json:"payload"`
+	} `json:"media"`
+	Mark struct {
+		Name string `json:"name"`
+	} `json:"mark"`
+	DTMF struct {
+		Digit string `json:"digit"`
+	} `json:"dtmf"`
+}
+
+type outboundMedia struct {
+	Payload string `json:"payload"`
+}
+
+type outboundMark struct {
+	Name string `json:"name"`
+}
+
+type outboundMessage struct {
+	Event     string         `json:"event"`
+	StreamSID string         `json:"streamSid"`
+	Media     *outboundMedia `json:"media,omitempty"`
+	Mark      *outboundMark  `json:"mark,omitempty"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.AuthToken != "" {
+		url := strings.TrimRight(h.opts.PublicURL, "/") + r.URL.RequestURI()
+		if !ValidateSignature(h.opts.AuthToken, url, nil, r.Header.Get("X-Twilio-Signature")) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		h.opts.Logger.Warn("twilio websocket accept failed", "error", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	call, err := waitForStart(ctx, conn)
+	if err != nil {
+		h.opts.Logger.Warn("twilio stream ended before start", "error", err)
+		return
+	}
+
+	session := h.orch.NewSessionWithDefaults(call.CallSID)
+	if h.opts.SystemPrompt != "" {
+		h.orch.SetSystemPrompt(session, h.opts.SystemPrompt)
+	}
+	if h.opts.OnStart != nil {
+		h.opts.OnStart(call, session)
+	}
+	stream := h.orch.NewManagedStream(ctx, session)
+	defer stream.Close()
+
+	h.opts.Logger.Info("twilio call connected", "callSid", call.CallSID, "streamSid", call.StreamSID)
+	defer h.opts.Logger.Info("twilio call disconnected", "callSid", call.CallSID)
+
+	c := &callConn{
+		conn:   conn,
+		call:   call,
+		stream: stream,
+		in:     resample.NewResampler(twilioSampleRate, h.orch.GetConfig().SampleRate),
+		out:    resample.NewResampler(h.orch.GetConfig().SampleRate, twilioSampleRate),
+	}
+	go h.readLoop(ctx, cancel, c)
+	h.writeLoop(ctx, c)
+}
+
+func waitForStart(ctx context.Context, conn *websocket.Conn) (Call, error) {
+	for {
+		var msg inboundMessage
+		if err := readMessage(ctx, conn, &msg); err != nil {
+			return Call{}, err
+		}
+		if msg.Event != "start" {
+			continue
+		}
+		call := Call{
+			AccountSID: msg.Start.AccountSID,
+			CallSID:    msg.Start.CallSID,
+			StreamSID:  msg.Start.StreamSID,
+			Parameters: msg.Start.CustomParameters,
+		}
+		if call.StreamSID == "" {
+			call.StreamSID = msg.StreamSID
+		}
+		return call, nil
+	}
+}
+
+func readMessage(ctx context.Context, conn *websocket.Conn, msg *inboundMessage) error {
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if json.Unmarshal(data, msg) == nil {
+			return nil
+		}
+	}
+}
+
+type callConn struct {
+	conn   *websocket.Conn
+	call   Call
+	stream *orchestrator.ManagedStream
+
+	in  *resample.Resampler
+	out *resample.Resampler
+
+	writeMu sync.Mutex
+	// marks counts the marks sent and unmarked is set while audio sent
+	// since the last one awaits its mark; both belong to the write loop.
+	// Marks up to cleared were flushed by a clear and say nothing about
+	// playback.
+	marks    int
+	unmarked bool
+	cleared  atomic.Int64
+}
+
+// markSegment sends a mark after the audio sent since the last one, so its
+// echo tells when that audio has played.
+func (c *callConn) markSegment(ctx context.Context) error {
+	if !c.unmarked {
+		return nil
+	}
+	c.unmarked = false
+	c.marks++
+	return c.send(ctx, outboundMessage{
+		Event: "mark",
+		Mark:  &outboundMark{Name: markPrefix + strconv.Itoa(c.marks)},
+	})
+}
+
+// resetOutbound drops audio that has not been sent yet and disowns the
+// marks Twilio still holds, which it echoes at once on a clear.
+func (c *callConn) resetOutbound() {
+	c.out.Reset()
+	c.unmarked = false
+	c.cleared.Store(int64(c.marks))
+}
+
+// played reports whether an echoed mark means audio has played.
+func (c *callConn) played(name string) bool {
+	n, err := strconv.ParseInt(strings.TrimPrefix(name, markPrefix), 10, 64)
+	return err == nil && strings.HasPrefix(name, markPrefix) && n > c.cleared.Load()
+}
+
+func (c *callConn) send(ctx context.Context, msg outboundMessage) error {
+	msg.StreamSID = c.call.StreamSID
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.Write(wctx, websocket.MessageText, data)
+}
+
+func (h *Handler) readLoop(ctx context.Context, cancel context.CancelFunc, c *callConn) {
+	defer cancel()
+	for {
+		var msg inboundMessage
+		if err := readMessage(ctx, c.conn, &msg); err != nil {
+			return
+		}
+		switch msg.Event {
+		case "media":
+			if msg.Media.Track != "" && msg.Media.Track != "inbound" {
+				continue
+			}
+			ulaw, err := base64.StdEncoding.DecodeString(msg.Media.Payload)
+			if err != nil {
+				h.opts.Logger.Debug("ignoring malformed twilio media", "error", err)
+				continue
+			}
+			if pcm := c.in.Process(audio.MulawDecode(ulaw)); len(pcm) > 0 {
+				c.stream.Write(pcm)
+			}
+		case "mark":
+			// Twilio echoes our marks once the audio before them has played.
+			if c.played(msg.Mark.Name) {
+				c.stream.NotifyAudioPlayed()
+			}
+		case "dtmf":
+			if h.opts.OnDTMF != nil {
+				h.opts.OnDTMF(c.call, c.stream, msg.DTMF.Digit)
+			}
+		case "stop":
+			return
+		}
+	}
+}
+
+func (h *Handler) writeLoop(ctx context.Context, c *callConn) {
+	segmentEnd := time.NewTimer(segmentGap)
+	segmentEnd.Stop()
+	defer segmentEnd.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.conn.Close(websocket.StatusNormalClosure, "")
+			return
+		case <-segmentEnd.C:
+			if err := c.markSegment(ctx); err != nil {
+				return
+			}
+		case ev, ok := <-c.stream.Events():
+			if !ok {
+				c.conn.Close(websocket.StatusNormalClosure, "session closed")
+				return
+			}
+			if err := h.forward(ctx, c, ev); err != nil {
+				return
+			}
+			if c.unmarked {
+				segmentEnd.Reset(segmentGap)
+			}
+		}
+	}
+}
+
+func (h *Handler) forward(ctx context.Context, c *callConn, ev orchestrator.OrchestratorEvent) error {
+	switch ev.Type {
+	case orchestrator.AudioChunk:
+		pcm, ok := ev.Data.([]byte)
+		if !ok {
+			return nil
+		}
+		ulaw := audio.MulawEncode(c.out.Process(pcm))
+		if len(ulaw) == 0 {
+			return nil
+		}
+		c.unmarked = true
+		return c.send(ctx, outboundMessage{
+			Event: "media",
+			Media: &outboundMedia{Payload: base64.StdEncoding.EncodeToString(ulaw)},
+		})
+	case orchestrator.Interrupted:
+		// Drop whatever Twilio has buffered so the caller hears the barge-in.
+		c.resetOutbound()
+		return c.send(ctx, outboundMessage{Event: "clear"})
+	}
+	return nil
+}
+
+// TwiML returns a response that connects the call to a media stream at
+// streamURL, passing params through as custom parameters.
+func TwiML(streamURL string, params map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><Response><Connect><Stream url="`)
+	xml.EscapeText(&b, []byte(streamURL))
+	b.WriteString(`">`)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(`<Parameter name="`)
+		xml.EscapeText(&b, []byte(name))
+		b.WriteString(`" value="`)
+		xml.EscapeText(&b, []byte(params[name]))
+		b.WriteString(`"/>`)
+	}
+	b.WriteString(`</Stream></Connect></Response>`)
+	return b.String()
+}
+
+// Signature computes Twilio's request signature: HMAC-SHA1 of the full URL
+// followed by the sorted POST parameters, base64 encoded.
+func Signature(authToken, url string, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(url))
+	for _, name := range names {
+		mac.Write([]byte(name + params[name]))
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func ValidateSignature(authToken, url string, params map[string]string, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Signature(authToken, url, params)), []byte(signature))
+}
@@ -0,0 +1,258 @@
+package twilio
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio/resample"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "hello caller", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return make([]byte, 4410*2), nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(make([]byte, 4410*2))
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+// chunkyTTS streams its reply in several chunks, as real providers do.
+type chunkyTTS struct{ stubTTS }
+
+func (chunkyTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	for i := 0; i < 5; i++ {
+		if err := onChunk(make([]byte, 882*2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestServer(t *testing.T, opts Options) string {
+	return newTestServerWithTTS(t, opts, stubTTS{})
+}
+
+func newTestServerWithTTS(t *testing.T, opts Options, tts orchestrator.TTSProvider) string {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	orch := orchestrator.New(stubSTT{}, stubLLM{}, tts, cfg)
+	srv := httptest.NewServer(NewHandler(orch, opts))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestHandler_BindsCallAndTranscodesAudio(t *testing.T) {
+	started := make(chan Call, 1)
+	url := newTestServer(t, Options{
+		OnStart: func(call Call, session *orchestrator.ConversationSession) {
+			started <- call
+		},
+		OnDTMF: func(call Call, stream *orchestrator.ManagedStream, digit string) {
+			stream.ScheduleSpeech(0, "you pressed "+digit)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.CloseNow()
+
+	send := func(msg string) {
+		if err := conn.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send(`{"event":"connected","protocol":"Call","version":"1.0.0"}`)
+	send(`{"event":"start","streamSid":"MZ1","start":{"accountSid":"AC1","callSid":"CA1","streamSid":"MZ1","customParameters":{"tenant":"acme"}}}`)
+
+	select {
+	case call := <-started:
+		if call.CallSID != "CA1" || call.StreamSID != "MZ1" || call.Parameters["tenant"] != "acme" {
+			t.Errorf("unexpected call info: %+v", call)
+		}
+	case <-ctx.Done():
+		t.Fatal("OnStart was not called")
+	}
+
+	send(`{"event":"media","streamSid":"MZ1","media":{"track":"inbound","payload":"` + base64.StdEncoding.EncodeToString(make([]byte, 160)) + `"}}`)
+	send(`{"event":"dtmf","streamSid":"MZ1","dtmf":{"digit":"5"}}`)
+
+	var sawMedia, sawMark bool
+	for !(sawMedia && sawMark) {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("read failed: media=%v mark=%v err=%v", sawMedia, sawMark, err)
+		}
+		var msg struct {
+			Event     string `json:"event"`
+			StreamSID string `json:"streamSid"`
+			Media     struct {
+				Payload string `json:"payload"`
+			} `json:"media"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("invalid message: %s", data)
+		}
+		if msg.StreamSID != "MZ1" {
+			t.Errorf("expected streamSid MZ1, got %q", msg.StreamSID)
+		}
+		switch msg.Event {
+		case "media":
+			ulaw, _ := base64.StdEncoding.DecodeString(msg.Media.Payload)
+			if len(ulaw) == 0 {
+				t.Error("expected a non-empty mu-law payload")
+			}
+			sawMedia = true
+		case "mark":
+			sawMark = true
+		}
+	}
+
+	send(`{"event":"stop","streamSid":"MZ1"}`)
+	for {
+		if _, _, err := conn.Read(ctx); err != nil {
+			if websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+				t.Errorf("expected normal closure after stop, got %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestHandler_MarksSegmentsNotChunks(t *testing.T) {
+	url := newTestServerWithTTS(t, Options{
+		OnDTMF: func(call Call, stream *orchestrator.ManagedStream, digit string) {
+			stream.ScheduleSpeech(0, "you pressed "+digit)
+		},
+	}, chunkyTTS{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.CloseNow()
+
+	conn.Write(ctx, websocket.MessageText, []byte(`{"event":"start","streamSid":"MZ1","start":{"callSid":"CA1","streamSid":"MZ1"}}`))
+	conn.Write(ctx, websocket.MessageText, []byte(`{"event":"dtmf","streamSid":"MZ1","dtmf":{"digit":"5"}}`))
+
+	media, marks := 0, 0
+	for marks == 0 {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("read failed: media=%d err=%v", media, err)
+		}
+		var msg struct {
+			Event string `json:"event"`
+			Mark  struct {
+				Name string `json:"name"`
+			} `json:"mark"`
+		}
+		json.Unmarshal(data, &msg)
+		switch msg.Event {
+		case "media":
+			media++
+		case "mark":
+			marks++
+			if msg.Mark.Name != markPrefix+"1" {
+				t.Errorf("unexpected mark %q", msg.Mark.Name)
+			}
+		}
+	}
+	if media < 2 {
+		t.Errorf("expected the reply in several media messages before its mark, got %d", media)
+	}
+}
+
+func TestCallConn_IgnoresMarksFlushedByClear(t *testing.T) {
+	c := &callConn{out: resample.NewResampler(16000, twilioSampleRate)}
+	c.marks, c.unmarked = 3, true
+	c.resetOutbound()
+	if c.unmarked {
+		t.Error("a clear must drop the pending segment")
+	}
+	if c.played(markPrefix + "3") {
+		t.Error("marks echoed by a clear must not count as played")
+	}
+	if !c.played(markPrefix + "4") {
+		t.Error("marks sent after the clear count as played")
+	}
+	if c.played("chunk-9") {
+		t.Error("foreign marks must be ignored")
+	}
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	url := newTestServer(t, Options{AuthToken: "secret", PublicURL: "wss://example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, resp, err := websocket.Dial(ctx, url+"/media", &websocket.DialOptions{
+		HTTPHeader: http.Header{"X-Twilio-Signature": {"bogus"}},
+	})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got resp=%v err=%v", resp, err)
+	}
+
+	_, _, err = websocket.Dial(ctx, url+"/media", &websocket.DialOptions{
+		HTTPHeader: http.Header{"X-Twilio-Signature": {Signature("secret", "wss://example.com/media", nil)}},
+	})
+	if err != nil {
+		t.Fatalf("expected valid signature to connect, got %v", err)
+	}
+}
+
+func TestSignature_MatchesTwilioExample(t *testing.T) {
+	params := map[string]string{
+		"CallSid": "CA1234567890ABCDE",
+		"Caller":  "+12349013030",
+		"Digits":  "1234",
+		"From":    "+12349013030",
+		"To":      "+18005551212",
+	}
+	url := "https://mycompany.com/myapp.php?foo=1&bar=2"
+	if got := Signature("12345", url, params); got != "0/KCTR6DLpKmkAf8muzZqo1nDgQ=" {
+		t.Errorf("unexpected signature %q", got)
+	}
+	if ValidateSignature("12345", url, params, "") {
+		t.Error("empty signature must not validate")
+	}
+}
+
+func TestTwiML(t *testing.T) {
+	got := TwiML("wss://example.com/twilio?a=1&b=2", map[string]string{"tenant": `a"b`})
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response><Connect><Stream url="wss://example.com/twilio?a=1&amp;b=2"><Parameter name="tenant" value="a&#34;b"/></Stream></Connect></Response>`
+	if got != want {
+		t.Errorf("unexpected TwiML:\n%s", got)
+	}
+}
@@ -0,0 +1,331 @@
+package webrtc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	pion "github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/transport/internal/opusio"
+)
+
+// Options configure a Handler. Authenticate, when set, returns the session id
+// for the offer; returning an error rejects it with 401.
+type Options struct {
+	Authenticate func(r *http.Request) (string, error)
+	SystemPrompt string
+	ICEServers   []pion.ICEServer
+	// Codec defaults to audio.DefaultOpusCodec.
+	Codec  audio.OpusCodec
+	Logger orchestrator.Logger
+	// API allows custom network settings (port ranges, NAT 1:1 IPs).
+	API *pion.API
+}
+
+// Handler accepts SDP offers over HTTP and answers them with a peer
+// connection bound to a ManagedStream. Offers are posted either as
+// application/sdp (WHIP style) or as JSON {"type":"offer","sdp":"..."}; the
+// answer uses the same format and already contains every ICE candidate.
+//
+// If the client opens a data channel, events other than audio are sent over
+// it as JSON.
+type Handler struct {
+	orch *orchestrator.Orchestrator
+	opts Options
+}
+
+func NewHandler(orch *orchestrator.Orchestrator, opts Options) *Handler {
+	if opts.Logger == nil {
+		opts.Logger = &orchestrator.NoOpLogger{}
+	}
+	if opts.Codec == nil {
+		opts.Codec = audio.DefaultOpusCodec
+	}
+	if opts.API == nil {
+		opts.API = pion.NewAPI()
+	}
+	return &Handler{orch: orch, opts: opts}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.opts.Codec == nil {
+		http.Error(w, audio.ErrNoOpusCodec.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	if h.opts.Authenticate != nil {
+		id, err := h.opts.Authenticate(r)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		sessionID = id
+	}
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read offer", http.StatusBadRequest)
+		return
+	}
+	jsonBody := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+	offer := pion.SessionDescription{Type: pion.SDPTypeOffer, SDP: string(body)}
+	if jsonBody {
+		if err := json.Unmarshal(body, &offer); err != nil {
+			http.Error(w, "invalid offer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	answer, err := h.connect(offer, sessionID, r.URL.Query())
+	if err != nil {
+		h.opts.Logger.Warn("webrtc negotiation failed", "sessionID", sessionID, "error", err)
+		http.Error(w, "negotiation failed", http.StatusBadRequest)
+		return
+	}
+
+	if jsonBody {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(answer)
+		return
+	}
+	w.Header().Set("Content-Type", "application/sdp")
+	w.WriteHeader(http.StatusCreated)
+	io.WriteString(w, answer.SDP)
+}
+
+type peer struct {
+	h       *Handler
+	pc      *pion.PeerConnection
+	track   *pion.TrackLocalStaticSample
+	session *orchestrator.ConversationSession
+	stream  *orchestrator.ManagedStream
+	sender  *opusio.Sender
+	ctx     context.Context
+	cancel  context.CancelFunc
+	start   sync.Once
+	// ready is closed once the stream exists. The stream only starts when
+	// the connection is up so the greeting isn't sent into the void.
+	ready  chan struct{}
+	events chan *pion.DataChannel
+}
+
+func (h *Handler) connect(offer pion.SessionDescription, sessionID string, query map[string][]string) (*pion.SessionDescription, error) {
+	pc, err := h.opts.API.NewPeerConnection(pion.Configuration{ICEServers: h.opts.ICEServers})
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := pion.NewTrackLocalStaticSample(pion.RTPCodecCapability{
+		MimeType:  pion.MimeTypeOpus,
+		ClockRate: opusio.SampleRate,
+		Channels:  2,
+	}, "audio", "lokutor")
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	rtpSender, err := pc.AddTrack(track)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	// RTCP must be read for interceptors (NACK, reports) to work.
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			if _, _, err := rtpSender.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	session := h.orch.NewSessionWithDefaults(sessionID)
+	if lang := firstValue(query, "language"); lang != "" {
+		h.orch.SetLanguage(session, orchestrator.Language(lang))
+	}
+	if voice := firstValue(query, "voice"); voice != "" {
+		h.orch.SetVoice(session, orchestrator.Voice(voice))
+	}
+	if h.opts.SystemPrompt != "" {
+		h.orch.SetSystemPrompt(session, h.opts.SystemPrompt)
+	}
+
+	// The peer outlives the HTTP request, so it is not bound to its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &peer{
+		h:       h,
+		pc:      pc,
+		track:   track,
+		session: session,
+		ctx:     ctx,
+		cancel:  cancel,
+		ready:   make(chan struct{}),
+		events:  make(chan *pion.DataChannel, 1),
+	}
+	go func() {
+		<-ctx.Done()
+		pc.Close()
+	}()
+
+	pc.OnTrack(func(remote *pion.TrackRemote, _ *pion.RTPReceiver) {
+		if remote.Kind() == pion.RTPCodecTypeAudio {
+			go p.receive(remote)
+		}
+	})
+	pc.OnDataChannel(func(dc *pion.DataChannel) {
+		dc.OnOpen(func() {
+			select {
+			case p.events <- dc:
+			default:
+			}
+		})
+	})
+	pc.OnConnectionStateChange(func(state pion.PeerConnectionState) {
+		switch state {
+		case pion.PeerConnectionStateConnected:
+			p.start.Do(p.begin)
+		case pion.PeerConnectionStateFailed, pion.PeerConnectionStateClosed, pion.PeerConnectionStateDisconnected:
+			cancel()
+		}
+	})
+
+	if err := pc.SetRemoteDescription(offer); err != nil {
+		cancel()
+		return nil, err
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	gathered := pion.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		cancel()
+		return nil, err
+	}
+	<-gathered
+
+	return pc.LocalDescription(), nil
+}
+
+func (p *peer) begin() {
+	sender, err := opusio.NewSender(p.h.opts.Codec, p.h.orch.GetConfig().SampleRate, func(frame []byte, d time.Duration) error {
+		return p.track.WriteSample(media.Sample{Data: frame, Duration: d})
+	}, func() {
+		p.stream.NotifyAudioPlayed()
+	})
+	if err != nil {
+		p.h.opts.Logger.Error("failed to create opus encoder", "error", err)
+		p.cancel()
+		return
+	}
+	p.sender = sender
+	p.stream = p.h.orch.NewManagedStream(p.ctx, p.session)
+	close(p.ready)
+
+	p.h.opts.Logger.Info("webrtc client connected", "sessionID", p.session.ID)
+	go p.run()
+}
+
+func (p *peer) run() {
+	defer p.h.opts.Logger.Info("webrtc client disconnected", "sessionID", p.session.ID)
+	defer p.cancel()
+	defer p.stream.Close()
+
+	go func() {
+		if err := p.sender.Run(p.ctx); err != nil && p.ctx.Err() == nil {
+			p.cancel()
+		}
+	}()
+
+	var dc *pion.DataChannel
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case dc = <-p.events:
+		case ev, ok := <-p.stream.Events():
+			if !ok {
+				return
+			}
+			switch ev.Type {
+			case orchestrator.AudioChunk:
+				if pcm, ok := ev.Data.([]byte); ok {
+					if err := p.sender.Push(pcm); err != nil {
+						p.h.opts.Logger.Warn("failed to encode agent audio", "error", err)
+					}
+				}
+				continue
+			case orchestrator.Interrupted:
+				p.sender.Clear()
+			case orchestrator.AudioDucked:
+				if duck, ok := ev.Data.(orchestrator.DuckInfo); ok {
+					p.sender.SetGain(duck.Gain)
+				}
+			case orchestrator.AudioRestored:
+				p.sender.SetGain(1)
+			}
+			if dc != nil {
+				if data, err := json.Marshal(ev); err == nil {
+					dc.SendText(string(data))
+				}
+			}
+		}
+	}
+}
+
+func (p *peer) receive(track *pion.TrackRemote) {
+	select {
+	case <-p.ready:
+	case <-p.ctx.Done():
+		return
+	}
+
+	rx, err := opusio.NewReceiver(p.h.opts.Codec, p.h.orch.GetConfig().SampleRate)
+	if err != nil {
+		p.h.opts.Logger.Error("failed to create opus decoder", "error", err)
+		return
+	}
+	for p.ctx.Err() == nil {
+		packet, _, err := track.ReadRTP()
+		if err != nil {
+			return
+		}
+		pcm, err := rx.Decode(packet.Payload)
+		if err != nil || len(pcm) == 0 {
+			continue
+		}
+		p.stream.Write(pcm)
+	}
+}
+
+func firstValue(query map[string][]string, key string) string {
+	if v := query[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "rtc_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,194 @@
+package webrtc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pion "github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (string, error) {
+	return "", nil
+}
+func (stubSTT) Name() string { return "stub-stt" }
+
+type stubLLM struct{}
+
+func (stubLLM) Complete(ctx context.Context, messages []orchestrator.Message) (string, error) {
+	return "hello", nil
+}
+func (stubLLM) Name() string { return "stub-llm" }
+
+type stubTTS struct{}
+
+func (stubTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return make([]byte, 4410*2), nil
+}
+func (stubTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk(make([]byte, 4410*2))
+}
+func (stubTTS) Abort() error { return nil }
+func (stubTTS) Name() string { return "stub-tts" }
+
+// fakeCodec stands in for libopus: packets are short markers and every
+// packet decodes to one 20ms frame of silence.
+type fakeCodec struct{}
+
+func (fakeCodec) NewEncoder(sampleRate, channels int) (audio.OpusEncoder, error) {
+	return fakeOpus{}, nil
+}
+func (fakeCodec) NewDecoder(sampleRate, channels int) (audio.OpusDecoder, error) {
+	return fakeOpus{}, nil
+}
+
+type fakeOpus struct{}
+
+func (fakeOpus) Encode(pcm []byte) ([]byte, error)    { return []byte{0xF8, 0xFF, 0xFE}, nil }
+func (fakeOpus) Decode(packet []byte) ([]byte, error) { return make([]byte, 1920), nil }
+
+func newTestOrchestrator() *orchestrator.Orchestrator {
+	cfg := orchestrator.DefaultConfig()
+	cfg.FirstSpeaker = orchestrator.FirstSpeakerUser
+	return orchestrator.New(stubSTT{}, stubLLM{}, stubTTS{}, cfg)
+}
+
+func TestHandler_DuplexAudio(t *testing.T) {
+	orch := newTestOrchestrator()
+	srv := httptest.NewServer(NewHandler(orch, Options{Codec: fakeCodec{}}))
+	defer srv.Close()
+
+	client, err := pion.NewPeerConnection(pion.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	mic, _ := pion.NewTrackLocalStaticSample(pion.RTPCodecCapability{MimeType: pion.MimeTypeOpus, ClockRate: 48000, Channels: 2}, "mic", "client")
+	if _, err := client.AddTrack(mic); err != nil {
+		t.Fatal(err)
+	}
+	dc, err := client.CreateDataChannel("events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan string, 64)
+	dc.OnMessage(func(msg pion.DataChannelMessage) { events <- string(msg.Data) })
+
+	received := make(chan struct{}, 1)
+	client.OnTrack(func(track *pion.TrackRemote, _ *pion.RTPReceiver) {
+		for {
+			if _, _, err := track.ReadRTP(); err != nil {
+				return
+			}
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		}
+	})
+
+	offer, _ := client.CreateOffer(nil)
+	gathered := pion.GatheringCompletePromise(client)
+	client.SetLocalDescription(offer)
+	<-gathered
+
+	body, _ := json.Marshal(client.LocalDescription())
+	resp, err := http.Post(srv.URL+"?session_id=rtc-1", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var answer pion.SessionDescription
+	json.NewDecoder(resp.Body).Decode(&answer)
+	resp.Body.Close()
+	if err := client.SetRemoteDescription(answer); err != nil {
+		t.Fatalf("invalid answer: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				mic.WriteSample(media.Sample{Data: []byte{0xF8, 0xFF, 0xFE}, Duration: 20 * time.Millisecond})
+			}
+		}
+	}()
+
+	var stream *orchestrator.ManagedStream
+	for stream == nil || stream.Status().Stats.AudioBytesIn == 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("caller audio never reached the stream")
+		case <-time.After(20 * time.Millisecond):
+		}
+		stream, _ = orch.Stream("rtc-1")
+	}
+
+	stream.ScheduleSpeech(0, "hi")
+	select {
+	case <-received:
+	case <-ctx.Done():
+		t.Fatal("agent audio never reached the client")
+	}
+	for sawEvent := false; !sawEvent; {
+		select {
+		case msg := <-events:
+			sawEvent = strings.Contains(msg, string(orchestrator.BotSpeaking))
+		case <-ctx.Done():
+			t.Fatal("expected events on the data channel")
+		}
+	}
+
+	client.Close()
+	for {
+		if _, ok := orch.Stream("rtc-1"); !ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("stream was not closed after the client hung up")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestHandler_Rejections(t *testing.T) {
+	saved := audio.DefaultOpusCodec
+	audio.DefaultOpusCodec = nil
+	defer func() { audio.DefaultOpusCodec = saved }()
+
+	noCodec := httptest.NewServer(NewHandler(newTestOrchestrator(), Options{}))
+	defer noCodec.Close()
+	resp, _ := http.Post(noCodec.URL, "application/sdp", strings.NewReader("v=0"))
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("expected 501 without a codec, got %d", resp.StatusCode)
+	}
+
+	srv := httptest.NewServer(NewHandler(newTestOrchestrator(), Options{Codec: fakeCodec{}}))
+	defer srv.Close()
+	resp, _ = http.Get(srv.URL)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", resp.StatusCode)
+	}
+	resp, _ = http.Post(srv.URL, "application/sdp", strings.NewReader("not sdp"))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed offer, got %d", resp.StatusCode)
+	}
+}